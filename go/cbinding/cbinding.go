@@ -0,0 +1,275 @@
+// Package main exports a C API over Space so that C++ and Python can link against the Go
+// implementation. Build it with:
+//
+//	go build -buildmode=c-shared -o libmilvus_storage.so ./cbinding
+//
+// Record batches cross the boundary through the Arrow C data interface. Every function
+// returns NULL on success or an error message that must be released with
+// milvus_storage_free_error. Panics, e.g. on a handle already closed, are returned as errors
+// instead of crashing the process.
+package main
+
+/*
+#include "cbinding.h"
+*/
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"runtime/cgo"
+	"unsafe"
+
+	"github.com/apache/arrow/go/v12/arrow/cdata"
+	"github.com/milvus-io/milvus-storage/go/storage"
+	"github.com/milvus-io/milvus-storage/go/storage/options/option"
+	"github.com/milvus-io/milvus-storage/go/storage/options/schema_option"
+	"github.com/milvus-io/milvus-storage/go/storage/schema"
+)
+
+var (
+	ErrInvalidHandle = errors.New("invalid space handle")
+	ErrInvalidStream = errors.New("invalid arrow array stream")
+	ErrInvalidSize   = errors.New("invalid size")
+)
+
+func main() {}
+
+func toCError(err error) *C.char {
+	if err == nil {
+		return nil
+	}
+	return C.CString(err.Error())
+}
+
+// recoverError turns a panic of an exported function into the error it returns in out, a
+// panic must not unwind into the C caller.
+func recoverError(out **C.char) {
+	if r := recover(); r != nil {
+		*out = toCError(fmt.Errorf("panic: %v", r))
+	}
+}
+
+// getSpace returns the space of handle, cgo.Handle panics on handles already deleted.
+func getSpace(handle C.SpaceHandle) (space *storage.Space, err error) {
+	if handle == 0 {
+		return nil, ErrInvalidHandle
+	}
+	defer func() {
+		if recover() != nil {
+			space, err = nil, ErrInvalidHandle
+		}
+	}()
+	space, ok := cgo.Handle(handle).Value().(*storage.Space)
+	if !ok {
+		return nil, ErrInvalidHandle
+	}
+	return space, nil
+}
+
+//export milvus_storage_free_error
+func milvus_storage_free_error(err *C.char) {
+	C.free(unsafe.Pointer(err))
+}
+
+// space_open opens or creates the space at uri. The schema and column options are only
+// required when the space does not exist yet, pass NULL otherwise. version -1 opens the
+// latest version.
+//
+//export space_open
+func space_open(uri *C.char, cSchema *C.struct_ArrowSchema, primaryColumn, versionColumn, vectorColumn *C.char,
+	version C.int64_t, out *C.SpaceHandle) (cerr *C.char) {
+	defer recoverError(&cerr)
+	var sc *schema.Schema
+	if cSchema != nil {
+		arrowSchema, err := cdata.ImportCArrowSchema((*cdata.CArrowSchema)(unsafe.Pointer(cSchema)))
+		if err != nil {
+			return toCError(err)
+		}
+		schemaOptions := &schema_option.SchemaOptions{
+			PrimaryColumn: C.GoString(primaryColumn),
+			VersionColumn: C.GoString(versionColumn),
+			VectorColumn:  C.GoString(vectorColumn),
+		}
+		sc = schema.NewSchema(arrowSchema, schemaOptions)
+		if err = sc.Validate(); err != nil {
+			return toCError(err)
+		}
+	}
+
+	space, err := storage.Open(C.GoString(uri), *option.NewOptions(sc, int64(version)))
+	if err != nil {
+		return toCError(err)
+	}
+	*out = C.SpaceHandle(cgo.NewHandle(space))
+	return nil
+}
+
+// space_close closes the space and releases the handle. The handle must not be used
+// afterwards, closing it again fails.
+//
+//export space_close
+func space_close(handle C.SpaceHandle) (cerr *C.char) {
+	defer recoverError(&cerr)
+	space, err := getSpace(handle)
+	if err != nil {
+		return toCError(err)
+	}
+	cgo.Handle(handle).Delete()
+	return toCError(space.Close())
+}
+
+// space_write consumes all batches of stream and commits them as a new version. The stream is
+// released before returning.
+//
+//export space_write
+func space_write(handle C.SpaceHandle, stream *C.struct_ArrowArrayStream, maxRecordPerFile C.int64_t) (cerr *C.char) {
+	defer recoverError(&cerr)
+	// the stream is released even if the handle is invalid
+	reader, err := importStream(stream)
+	if err != nil {
+		return toCError(err)
+	}
+	defer reader.Release()
+	space, err := getSpace(handle)
+	if err != nil {
+		return toCError(err)
+	}
+
+	options := option.NewWriteOption()
+	if maxRecordPerFile > 0 {
+		options.MaxRecordPerFile = int64(maxRecordPerFile)
+	}
+	_, err = space.Write(reader, options)
+	return toCError(err)
+}
+
+// space_delete consumes all batches of stream, which must match the delete schema
+// (primary column and version column), and commits them as a delete fragment. The stream is
+// released before returning.
+//
+//export space_delete
+func space_delete(handle C.SpaceHandle, stream *C.struct_ArrowArrayStream) (cerr *C.char) {
+	defer recoverError(&cerr)
+	// the stream is released even if the handle is invalid
+	reader, err := importStream(stream)
+	if err != nil {
+		return toCError(err)
+	}
+	defer reader.Release()
+	space, err := getSpace(handle)
+	if err != nil {
+		return toCError(err)
+	}
+	_, err = space.Delete(reader)
+	return toCError(err)
+}
+
+// space_read exports the projected columns as an ArrowArrayStream into out. The caller owns
+// out and must call its release callback.
+//
+//export space_read
+func space_read(handle C.SpaceHandle, columns **C.char, numColumns C.int, out *C.struct_ArrowArrayStream) (cerr *C.char) {
+	defer recoverError(&cerr)
+	space, err := getSpace(handle)
+	if err != nil {
+		return toCError(err)
+	}
+
+	readOptions := option.NewReadOptions()
+	if numColumns > 0 {
+		for _, column := range unsafe.Slice(columns, int(numColumns)) {
+			readOptions.AddColumn(C.GoString(column))
+		}
+	}
+
+	reader, err := space.Read(readOptions)
+	if err != nil {
+		return toCError(err)
+	}
+	cdata.ExportRecordReader(reader, (*cdata.CArrowArrayStream)(unsafe.Pointer(out)))
+	return nil
+}
+
+// space_write_blob writes the size bytes of content as the blob name. The content is copied,
+// the caller keeps ownership of it.
+//
+//export space_write_blob
+func space_write_blob(handle C.SpaceHandle, name *C.char, content unsafe.Pointer, size C.int64_t, replace C.bool) (cerr *C.char) {
+	defer recoverError(&cerr)
+	space, err := getSpace(handle)
+	if err != nil {
+		return toCError(err)
+	}
+	if size < 0 || int64(size) > math.MaxInt || (size > 0 && content == nil) {
+		return toCError(fmt.Errorf("write blob of %d bytes: %w", int64(size), ErrInvalidSize))
+	}
+	// C.GoBytes takes an int32 size, blobs of 2 GiB and more are copied from a slice
+	buf := make([]byte, int(size))
+	if size > 0 {
+		copy(buf, unsafe.Slice((*byte)(content), int(size)))
+	}
+	_, err = space.WriteBlob(buf, C.GoString(name), bool(replace))
+	return toCError(err)
+}
+
+// space_read_blob reads at most size bytes of the blob into output and stores the number of
+// bytes read in n.
+//
+//export space_read_blob
+func space_read_blob(handle C.SpaceHandle, name *C.char, output unsafe.Pointer, size C.int64_t, n *C.int64_t) (cerr *C.char) {
+	defer recoverError(&cerr)
+	space, err := getSpace(handle)
+	if err != nil {
+		return toCError(err)
+	}
+	if size < 0 || int64(size) > math.MaxInt || (size > 0 && output == nil) {
+		return toCError(fmt.Errorf("read blob into %d bytes: %w", int64(size), ErrInvalidSize))
+	}
+	var buf []byte
+	if size > 0 {
+		buf = unsafe.Slice((*byte)(output), int(size))
+	}
+	read, err := space.ReadBlob(C.GoString(name), buf)
+	if err != nil && err != io.EOF {
+		return toCError(err)
+	}
+	*n = C.int64_t(read)
+	return nil
+}
+
+//export space_get_blob_byte_size
+func space_get_blob_byte_size(handle C.SpaceHandle, name *C.char, size *C.int64_t) (cerr *C.char) {
+	defer recoverError(&cerr)
+	space, err := getSpace(handle)
+	if err != nil {
+		return toCError(err)
+	}
+	blobSize, err := space.GetBlobByteSize(C.GoString(name))
+	if err != nil {
+		return toCError(err)
+	}
+	*size = C.int64_t(blobSize)
+	return nil
+}
+
+//export space_get_current_version
+func space_get_current_version(handle C.SpaceHandle, version *C.int64_t) (cerr *C.char) {
+	defer recoverError(&cerr)
+	space, err := getSpace(handle)
+	if err != nil {
+		return toCError(err)
+	}
+	*version = C.int64_t(space.GetCurrentVersion())
+	return nil
+}
+
+// importStream takes ownership of stream, it is released once read or if importing it fails.
+func importStream(stream *C.struct_ArrowArrayStream) (*streamReader, error) {
+	if stream == nil || stream.release == nil {
+		return nil, ErrInvalidStream
+	}
+	return newStreamReader(stream)
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testSchema() *arrow.Schema {
+	return arrow.NewSchema([]arrow.Field{
+		{Name: "pk_field", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "vs_field", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "vec_field", Type: &arrow.FixedSizeBinaryType{ByteWidth: 2}},
+	}, nil)
+}
+
+func testReader(t *testing.T, pks []int64) array.RecordReader {
+	b := array.NewRecordBuilder(memory.DefaultAllocator, testSchema())
+	defer b.Release()
+	for _, pk := range pks {
+		b.Field(0).(*array.Int64Builder).Append(pk)
+		b.Field(1).(*array.Int64Builder).Append(pk)
+		b.Field(2).(*array.FixedSizeBinaryBuilder).Append([]byte{byte(pk), 1})
+	}
+	rec := b.NewRecord()
+	defer rec.Release()
+	reader, err := array.NewRecordReader(testSchema(), []arrow.Record{rec})
+	require.NoError(t, err)
+	return reader
+}
+
+func TestSpaceAPI(t *testing.T) {
+	uri := "file://" + t.TempDir()
+	handle, err := goOpen(uri, testSchema(), "pk_field", "vs_field", "vec_field", -1)
+	require.NoError(t, err)
+
+	require.NoError(t, goWrite(handle, testReader(t, []int64{1, 2, 3})))
+	records, err := goRead(handle, "pk_field")
+	require.NoError(t, err)
+	var pks []int64
+	for _, rec := range records {
+		pks = append(pks, rec.Column(0).(*array.Int64).Int64Values()...)
+		rec.Release()
+	}
+	assert.ElementsMatch(t, []int64{1, 2, 3}, pks)
+
+	require.NoError(t, goWriteBlob(handle, "blob", []byte("content")))
+	content, err := goReadBlob(handle, "blob", 16)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("content"), content)
+	require.NoError(t, goClose(handle))
+
+	// stale handles fail instead of panicking, streams are released anyway
+	assert.ErrorContains(t, goClose(handle), ErrInvalidHandle.Error())
+	assert.ErrorContains(t, goWrite(handle, testReader(t, []int64{4})), ErrInvalidHandle.Error())
+
+	reopened, err := goOpen(uri, nil, "", "", "", -1)
+	require.NoError(t, err)
+	defer goClose(reopened)
+	records, err = goRead(reopened, "pk_field")
+	require.NoError(t, err)
+	var rows int64
+	for _, rec := range records {
+		rows += rec.NumRows()
+		rec.Release()
+	}
+	assert.Equal(t, int64(3), rows)
+}
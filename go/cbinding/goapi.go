@@ -0,0 +1,109 @@
+package main
+
+/*
+#include "cbinding.h"
+*/
+import "C"
+
+import (
+	"errors"
+	"unsafe"
+
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/cdata"
+)
+
+// The functions below call the C API from Go the way a C caller does, with C allocated
+// structs, so that it can be tested without a C toolchain around the library.
+
+// goError returns the error message of the C API as an error and frees it.
+func goError(msg *C.char) error {
+	if msg == nil {
+		return nil
+	}
+	defer milvus_storage_free_error(msg)
+	return errors.New(C.GoString(msg))
+}
+
+func goOpen(uri string, sc *arrow.Schema, primaryColumn, versionColumn, vectorColumn string, version int64) (uintptr, error) {
+	cURI := C.CString(uri)
+	defer C.free(unsafe.Pointer(cURI))
+	cPrimary, cVersion, cVector := C.CString(primaryColumn), C.CString(versionColumn), C.CString(vectorColumn)
+	defer C.free(unsafe.Pointer(cPrimary))
+	defer C.free(unsafe.Pointer(cVersion))
+	defer C.free(unsafe.Pointer(cVector))
+	var cSchema *C.struct_ArrowSchema
+	if sc != nil {
+		cSchema = (*C.struct_ArrowSchema)(C.calloc(1, C.sizeof_struct_ArrowSchema))
+		defer C.free(unsafe.Pointer(cSchema))
+		cdata.ExportArrowSchema(sc, (*cdata.CArrowSchema)(unsafe.Pointer(cSchema)))
+	}
+	var handle C.SpaceHandle
+	err := goError(space_open(cURI, cSchema, cPrimary, cVersion, cVector, C.int64_t(version), &handle))
+	return uintptr(handle), err
+}
+
+func goClose(handle uintptr) error {
+	return goError(space_close(C.SpaceHandle(handle)))
+}
+
+func goWrite(handle uintptr, reader array.RecordReader) error {
+	stream := (*C.struct_ArrowArrayStream)(C.calloc(1, C.sizeof_struct_ArrowArrayStream))
+	defer C.free(unsafe.Pointer(stream))
+	cdata.ExportRecordReader(reader, (*cdata.CArrowArrayStream)(unsafe.Pointer(stream)))
+	err := goError(space_write(C.SpaceHandle(handle), stream, 0))
+	if stream.release != nil {
+		return errors.New("stream not released")
+	}
+	return err
+}
+
+// goRead returns the records of the columns, the caller releases them.
+func goRead(handle uintptr, columns ...string) ([]arrow.Record, error) {
+	cColumns := make([]*C.char, len(columns))
+	for i, column := range columns {
+		cColumns[i] = C.CString(column)
+		defer C.free(unsafe.Pointer(cColumns[i]))
+	}
+	cArray := (**C.char)(C.calloc(C.size_t(len(columns)+1), C.size_t(unsafe.Sizeof(cColumns[0]))))
+	defer C.free(unsafe.Pointer(cArray))
+	copy(unsafe.Slice(cArray, len(columns)), cColumns)
+
+	stream := (*C.struct_ArrowArrayStream)(C.calloc(1, C.sizeof_struct_ArrowArrayStream))
+	defer C.free(unsafe.Pointer(stream))
+	if err := goError(space_read(C.SpaceHandle(handle), cArray, C.int(len(columns)), stream)); err != nil {
+		return nil, err
+	}
+	reader, err := newStreamReader(stream)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Release()
+	var records []arrow.Record
+	for reader.Next() {
+		reader.Record().Retain()
+		records = append(records, reader.Record())
+	}
+	return records, reader.Err()
+}
+
+func goWriteBlob(handle uintptr, name string, content []byte) error {
+	cName := C.CString(name)
+	defer C.free(unsafe.Pointer(cName))
+	cContent := C.CBytes(content)
+	defer C.free(cContent)
+	return goError(space_write_blob(C.SpaceHandle(handle), cName, cContent, C.int64_t(len(content)), false))
+}
+
+func goReadBlob(handle uintptr, name string, size int) ([]byte, error) {
+	cName := C.CString(name)
+	defer C.free(unsafe.Pointer(cName))
+	output := C.malloc(C.size_t(size))
+	defer C.free(output)
+	var n C.int64_t
+	if err := goError(space_read_blob(C.SpaceHandle(handle), cName, output, C.int64_t(size), &n)); err != nil {
+		return nil, err
+	}
+	return C.GoBytes(output, C.int(n)), nil
+}
@@ -0,0 +1,153 @@
+package main
+
+/*
+#include "cbinding.h"
+
+// the callbacks are called from C, cgo cannot call function pointers
+
+static int stream_get_schema(struct ArrowArrayStream* stream, struct ArrowSchema* out) {
+  return stream->get_schema(stream, out);
+}
+
+static int stream_get_next(struct ArrowArrayStream* stream, struct ArrowArray* out) {
+  return stream->get_next(stream, out);
+}
+
+static const char* stream_get_last_error(struct ArrowArrayStream* stream) {
+  return stream->get_last_error(stream);
+}
+
+static void stream_release(struct ArrowArrayStream* stream) {
+  if (stream->release != NULL) {
+    stream->release(stream);
+  }
+}
+
+static void array_release(struct ArrowArray* array) {
+  if (array->release != NULL) {
+    array->release(array);
+  }
+}
+*/
+import "C"
+
+import (
+	"fmt"
+	"io"
+	"sync/atomic"
+	"unsafe"
+
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/cdata"
+)
+
+// streamReader reads the batches of a C stream it owns. The stream is released as soon as it
+// is exhausted or fails, or when the reader is released, rather than by a finalizer of the Go
+// garbage collector like the readers of cdata.ImportCArrayStream.
+type streamReader struct {
+	stream *C.struct_ArrowArrayStream
+	schema *arrow.Schema
+	cur    arrow.Record
+	err    error
+	refs   int64
+}
+
+var _ array.RecordReader = (*streamReader)(nil)
+
+// newStreamReader takes ownership of stream and reads its schema. The stream is released if it
+// fails.
+func newStreamReader(stream *C.struct_ArrowArrayStream) (*streamReader, error) {
+	r := &streamReader{stream: stream, refs: 1}
+	var cSchema C.struct_ArrowSchema
+	if errno := C.stream_get_schema(stream, &cSchema); errno != 0 {
+		err := r.streamError("get schema", errno)
+		r.close()
+		return nil, err
+	}
+	schema, err := cdata.ImportCArrowSchema((*cdata.CArrowSchema)(unsafe.Pointer(&cSchema)))
+	if err != nil {
+		r.close()
+		return nil, err
+	}
+	r.schema = schema
+	return r, nil
+}
+
+func (r *streamReader) streamError(op string, errno C.int) error {
+	if msg := C.stream_get_last_error(r.stream); msg != nil {
+		return fmt.Errorf("%s of arrow array stream: %s", op, C.GoString(msg))
+	}
+	return fmt.Errorf("%s of arrow array stream: errno %d", op, int(errno))
+}
+
+func (r *streamReader) Schema() *arrow.Schema { return r.schema }
+
+func (r *streamReader) Record() arrow.Record { return r.cur }
+
+func (r *streamReader) Err() error { return r.err }
+
+func (r *streamReader) Next() bool {
+	if r.cur != nil {
+		r.cur.Release()
+		r.cur = nil
+	}
+	if r.stream == nil {
+		return false
+	}
+	cArray := (*C.struct_ArrowArray)(C.calloc(1, C.sizeof_struct_ArrowArray))
+	defer C.free(unsafe.Pointer(cArray))
+	if errno := C.stream_get_next(r.stream, cArray); errno != 0 {
+		r.err = r.streamError("get next", errno)
+		r.close()
+		return false
+	}
+	// a released array marks the end of the stream
+	if cArray.release == nil {
+		r.close()
+		return false
+	}
+	rec, err := cdata.ImportCRecordBatchWithSchema((*cdata.CArrowArray)(unsafe.Pointer(cArray)), r.schema)
+	if err != nil {
+		C.array_release(cArray)
+		r.err = err
+		r.close()
+		return false
+	}
+	r.cur = rec
+	return true
+}
+
+// Read returns the next batch, io.EOF once the stream is exhausted.
+func (r *streamReader) Read() (arrow.Record, error) {
+	if !r.Next() {
+		if r.err != nil {
+			return nil, r.err
+		}
+		return nil, io.EOF
+	}
+	return r.cur, nil
+}
+
+func (r *streamReader) Retain() {
+	atomic.AddInt64(&r.refs, 1)
+}
+
+func (r *streamReader) Release() {
+	if atomic.AddInt64(&r.refs, -1) > 0 {
+		return
+	}
+	if r.cur != nil {
+		r.cur.Release()
+		r.cur = nil
+	}
+	r.close()
+}
+
+// close releases the stream, the struct itself belongs to the caller of the C API.
+func (r *streamReader) close() {
+	if r.stream != nil {
+		C.stream_release(r.stream)
+		r.stream = nil
+	}
+}
@@ -8,7 +8,21 @@ const (
 	BlobDir                = "blobs"
 	ParquetDataFileSuffix  = ".parquet"
 	OffsetFieldName        = "__offset"
+	DeletedFieldName       = "__deleted"
 	VectorDataDir          = "vector"
 	ScalarDataDir          = "scalar"
 	DeleteDataDir          = "delete"
+	ColdDataDir            = "cold"
+	LeaseFileName          = "_writer.lease"
+	LatestFileName         = "_latest"
+	PingFilePrefix         = "_ping-"
+	LeaseTempFileSuffix    = ".tmp"
+	AuditDir               = "audit"
+	AuditFileSuffix        = ".json"
+	BitmapDir              = "bitmap"
+	BitmapFileSuffix       = ".json"
+	StatsPropertyPrefix    = "__stats."
+	// ReservedColumnPrefix starts the names of the columns added by the storage, user columns
+	// cannot use it.
+	ReservedColumnPrefix = "__"
 )
@@ -0,0 +1,78 @@
+// Package errors defines the error kinds shared by the fs and storage layers. Callers should
+// test for a kind with errors.Is rather than matching messages, e.g.
+//
+//	if errors.Is(err, errors.ErrThrottled) { retry later }
+//
+// The package re-exports the helpers of the standard errors package so that it can be
+// imported in its place.
+package errors
+
+import (
+	stderrors "errors"
+)
+
+var (
+	// ErrNotFound reports a missing file, manifest, blob or space.
+	ErrNotFound = New("not found")
+	// ErrPermissionDenied reports rejected credentials or missing permissions.
+	ErrPermissionDenied = New("permission denied")
+	// ErrThrottled reports that the backend asked to slow down, the request can be retried later.
+	ErrThrottled = New("throttled")
+	// ErrChecksumMismatch reports data that does not match its recorded checksum.
+	ErrChecksumMismatch = New("checksum mismatch")
+	// ErrConflict reports a concurrent modification, e.g. another writer committed the same version.
+	ErrConflict = New("conflict")
+	// ErrUnavailable reports a backend failing too often to be used, the request was not sent
+	// and can be retried later.
+	ErrUnavailable = New("unavailable")
+	// ErrResourceExhausted reports a request exceeding a limit, e.g. a quota, it fails again
+	// until usage drops.
+	ErrResourceExhausted = New("resource exhausted")
+)
+
+func New(text string) error {
+	return stderrors.New(text)
+}
+
+func Is(err, target error) bool {
+	return stderrors.Is(err, target)
+}
+
+func As(err error, target any) bool {
+	return stderrors.As(err, target)
+}
+
+func Unwrap(err error) error {
+	return stderrors.Unwrap(err)
+}
+
+type kindError struct {
+	kind error
+	err  error
+}
+
+func (e *kindError) Error() string {
+	return e.err.Error()
+}
+
+func (e *kindError) Unwrap() error {
+	return e.err
+}
+
+func (e *kindError) Is(target error) bool {
+	return target == e.kind
+}
+
+// WithKind returns err classified as kind: errors.Is matches both kind and the errors in
+// err's chain, and the message is err's. It returns nil if err is nil.
+func WithKind(kind error, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &kindError{kind: kind, err: err}
+}
+
+// NewWithKind returns a new error with the given text classified as kind.
+func NewWithKind(kind error, text string) error {
+	return WithKind(kind, New(text))
+}
@@ -0,0 +1,23 @@
+package errors
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithKind(t *testing.T) {
+	cause := New("no such key")
+	err := fmt.Errorf("open file: %w", WithKind(ErrNotFound, cause))
+
+	assert.True(t, Is(err, ErrNotFound))
+	assert.True(t, Is(err, cause))
+	assert.False(t, Is(err, ErrConflict))
+	assert.Equal(t, "open file: no such key", err.Error())
+	assert.Nil(t, WithKind(ErrNotFound, nil))
+
+	blobNotExist := NewWithKind(ErrNotFound, "blob not exist")
+	assert.True(t, Is(fmt.Errorf("read blob: %w", blobNotExist), ErrNotFound))
+	assert.True(t, Is(blobNotExist, blobNotExist))
+}
@@ -1,14 +1,17 @@
 package utils
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/apache/arrow/go/v12/arrow"
 	"github.com/apache/arrow/go/v12/arrow/endian"
+	"github.com/apache/arrow/go/v12/arrow/ipc"
 	"github.com/google/uuid"
 	"github.com/milvus-io/milvus-storage/go/common/constant"
 	"github.com/milvus-io/milvus-storage/go/common/log"
@@ -110,6 +113,10 @@ func SetTypeValues(protoType *schema_proto.DataType, dataType arrow.DataType) er
 		protoType = &schema_proto.DataType{LogicType: int64Type}
 		break
 
+	case arrow.BOOL, arrow.INT8, arrow.INT16, arrow.INT32, arrow.UINT8, arrow.UINT16, arrow.UINT32, arrow.UINT64,
+		arrow.FLOAT32, arrow.FLOAT64, arrow.BINARY:
+		// the logic type is enough
+
 	case arrow.FIXED_SIZE_BINARY:
 		realType, ok := dataType.(*arrow.FixedSizeBinaryType)
 		if !ok {
@@ -195,6 +202,26 @@ func ToProtobufSchema(schema *arrow.Schema) (*schema_proto.ArrowSchema, error) {
 	return protoSchema, nil
 }
 
+// SerializeSchema encodes schema as an Arrow IPC stream without record batches, which keeps
+// every type and all metadata.
+func SerializeSchema(schema *arrow.Schema) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := ipc.NewWriter(&buf, ipc.WithSchema(schema))
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("serialize schema: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func DeserializeSchema(data []byte) (*arrow.Schema, error) {
+	reader, err := ipc.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("deserialize schema: %w", err)
+	}
+	defer reader.Release()
+	return reader.Schema(), nil
+}
+
 func FromProtobufSchema(schema *schema_proto.ArrowSchema) (*arrow.Schema, error) {
 	fields := make([]arrow.Field, 0, len(schema.Fields))
 	for _, field := range schema.Fields {
@@ -337,6 +364,42 @@ func GetNewParquetFilePath(path string) string {
 	return path
 }
 
+// GetNamedParquetFilePath returns the path of the parquet data file name in dir.
+func GetNamedParquetFilePath(dir string, name string) string {
+	return filepath.Join(dir, name+constant.ParquetDataFileSuffix)
+}
+
+// NewTimeSortedID returns a new UUIDv7, whose first 48 bits are the unix milliseconds of its
+// creation followed by random bits, so that the ids sort by the time they were made.
+func NewTimeSortedID() string {
+	id := uuid.New()
+	ms := time.Now().UnixMilli()
+	for i := 0; i < 6; i++ {
+		id[i] = byte(ms >> (40 - 8*i))
+	}
+	// version 7, the variant bits of the random UUID are kept
+	id[6] = id[6]&0x0f | 0x70
+	return id.String()
+}
+
+// TimeOfFile returns when the file at path named after a UUIDv7 was named, see
+// NewTimeSortedID, and false for other names.
+func TimeOfFile(path string) (time.Time, bool) {
+	name := strings.TrimSuffix(filepath.Base(path), constant.ParquetDataFileSuffix)
+	if len(name) < 36 {
+		return time.Time{}, false
+	}
+	id, err := uuid.Parse(name[len(name)-36:])
+	if err != nil || id.Version() != 7 {
+		return time.Time{}, false
+	}
+	var ms int64
+	for i := 0; i < 6; i++ {
+		ms = ms<<8 | int64(id[i])
+	}
+	return time.UnixMilli(ms), true
+}
+
 func GetManifestFilePath(path string, version int64) string {
 	path = filepath.Join(path, constant.ManifestDir, strconv.FormatInt(version, 10)+constant.ManifestFileSuffix)
 	return path
@@ -352,11 +415,22 @@ func GetBlobFilePath(path string) string {
 	return filepath.Join(GetBlobDir(path), blobId.String())
 }
 
+// GetBlobChunkFilePath returns the path of chunk index of the chunked blob upload uploadID.
+func GetBlobChunkFilePath(path string, uploadID string, index int) string {
+	return filepath.Join(GetBlobDir(path), fmt.Sprintf("%s-%08d", uploadID, index))
+}
+
 func GetManifestDir(path string) string {
 	path = filepath.Join(path, constant.ManifestDir)
 	return path
 }
 
+// GetLegacyManifestDir returns the directory early releases saved manifests in by mistake, the
+// manifest directory nested in itself.
+func GetLegacyManifestDir(path string) string {
+	return GetManifestDir(GetManifestDir(path))
+}
+
 func GetVectorDataDir(path string) string {
 	return filepath.Join(path, constant.VectorDataDir)
 }
@@ -365,6 +439,12 @@ func GetScalarDataDir(path string) string {
 	return filepath.Join(path, constant.ScalarDataDir)
 }
 
+// GetColdDataDir returns the directory of the cold tier, holding scalar and vector data
+// directories like the space.
+func GetColdDataDir(path string) string {
+	return filepath.Join(path, constant.ColdDataDir)
+}
+
 func GetBlobDir(path string) string {
 	return filepath.Join(path, constant.BlobDir)
 }
@@ -373,6 +453,38 @@ func GetDeleteDataDir(path string) string {
 	return filepath.Join(path, constant.DeleteDataDir)
 }
 
+func GetLeaseFilePath(path string) string {
+	return filepath.Join(path, constant.LeaseFileName)
+}
+
+// GetLatestFilePath returns the path of the hint holding the latest version of the space.
+func GetLatestFilePath(path string) string {
+	return filepath.Join(path, constant.LatestFileName)
+}
+
+// GetPingFilePath returns a new path for a probe file written and deleted by health checks.
+func GetPingFilePath(path string) string {
+	return filepath.Join(path, constant.PingFilePrefix+uuid.New().String())
+}
+
+func GetAuditDir(path string) string {
+	return filepath.Join(path, constant.AuditDir)
+}
+
+func GetAuditFilePath(path string, version int64) string {
+	return filepath.Join(GetAuditDir(path), strconv.FormatInt(version, 10)+constant.AuditFileSuffix)
+}
+
+func GetBitmapDir(path string) string {
+	return filepath.Join(path, constant.BitmapDir)
+}
+
+// GetBitmapFilePath returns the path of the deleted rows of scalar fragment fragmentID, key
+// identifies the files of the fragment and the delete fragments applied to them.
+func GetBitmapFilePath(path string, fragmentID int64, key uint64) string {
+	return filepath.Join(GetBitmapDir(path), fmt.Sprintf("%d_%016x%s", fragmentID, key, constant.BitmapFileSuffix))
+}
+
 func ParseVersionFromFileName(path string) int64 {
 	pos := strings.Index(path, constant.ManifestFileSuffix)
 	if pos == -1 || !strings.HasSuffix(path, constant.ManifestFileSuffix) {
@@ -1,11 +1,97 @@
 package blob
 
-import "github.com/milvus-io/milvus-storage/go/proto/manifest_proto"
+import (
+	"bytes"
+	"crypto/sha256"
+
+	"github.com/milvus-io/milvus-storage/go/proto/manifest_proto"
+)
 
 type Blob struct {
 	Name string
 	Size int64
+	// File holds the content, empty for chunked blobs.
 	File string
+	// Metadata is set by the writer, e.g. the version and parameters of an index.
+	Metadata map[string]string
+	// Checksum is the sha256 of the content, nil for blobs written before checksums were
+	// recorded and for chunked blobs.
+	Checksum []byte
+	// Chunks hold the content of large blobs in order, see storage.BlobWriter.
+	Chunks []Chunk
+	// Codec is the compression of File, Size is the size before compression and
+	// StoredSize the size of File.
+	Codec      Codec
+	StoredSize int64
+	// Coverage is the data an index blob was built from, nil for other blobs.
+	Coverage *Coverage
+}
+
+// Coverage is the data an index blob was built from: the data fragments Fragments as read at
+// Version. Stale is set once compaction rewrote one of them, so that the index no longer
+// matches the rows of the space.
+type Coverage struct {
+	Version   int64
+	Fragments []int64
+	Stale     bool
+}
+
+// Covers reports whether data fragment id is one the index was built from.
+func (c *Coverage) Covers(id int64) bool {
+	for _, fragment := range c.Fragments {
+		if fragment == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Chunk is a part of a chunked blob stored in its own file.
+type Chunk struct {
+	File     string
+	Size     int64
+	Checksum []byte
+}
+
+// Checksum returns the checksum of content as recorded in Blob.Checksum.
+func Checksum(content []byte) []byte {
+	sum := sha256.Sum256(content)
+	return sum[:]
+}
+
+// Verify reports whether content matches the checksum of b, blobs without a checksum match
+// any content.
+func (b Blob) Verify(content []byte) bool {
+	return b.Checksum == nil || bytes.Equal(b.Checksum, Checksum(content))
+}
+
+// Verify reports whether content matches the checksum of c.
+func (c Chunk) Verify(content []byte) bool {
+	return c.Checksum == nil || bytes.Equal(c.Checksum, Checksum(content))
+}
+
+// StoredBytes returns the bytes the blob takes in storage.
+func (b Blob) StoredBytes() int64 {
+	if b.Codec == CodecNone {
+		return b.Size
+	}
+	return b.StoredSize
+}
+
+func (b Blob) Chunked() bool {
+	return len(b.Chunks) > 0
+}
+
+// Files returns the files holding the content of b.
+func (b Blob) Files() []string {
+	if !b.Chunked() {
+		return []string{b.File}
+	}
+	files := make([]string, 0, len(b.Chunks))
+	for _, c := range b.Chunks {
+		files = append(files, c.File)
+	}
+	return files
 }
 
 func (b Blob) ToProtobuf() *manifest_proto.Blob {
@@ -13,13 +99,40 @@ func (b Blob) ToProtobuf() *manifest_proto.Blob {
 	blob.Name = b.Name
 	blob.Size = b.Size
 	blob.File = b.File
+	blob.Metadata = b.Metadata
+	blob.Checksum = b.Checksum
+	blob.Codec = manifest_proto.BlobCodec(b.Codec)
+	blob.StoredSize = b.StoredSize
+	for _, c := range b.Chunks {
+		blob.Chunks = append(blob.Chunks, &manifest_proto.BlobChunk{File: c.File, Size: c.Size, Checksum: c.Checksum})
+	}
+	if b.Coverage != nil {
+		blob.Coverage = &manifest_proto.IndexCoverage{Version: b.Coverage.Version, Fragments: b.Coverage.Fragments, Stale: b.Coverage.Stale}
+	}
 	return blob
 }
 
 func FromProtobuf(blob *manifest_proto.Blob) Blob {
-	return Blob{
-		Name: blob.Name,
-		Size: blob.Size,
-		File: blob.File,
+	b := Blob{
+		Name:       blob.Name,
+		Size:       blob.Size,
+		File:       blob.File,
+		Metadata:   blob.Metadata,
+		Codec:      Codec(blob.Codec),
+		StoredSize: blob.StoredSize,
+	}
+	if len(blob.Checksum) > 0 {
+		b.Checksum = blob.Checksum
+	}
+	for _, c := range blob.Chunks {
+		chunk := Chunk{File: c.File, Size: c.Size}
+		if len(c.Checksum) > 0 {
+			chunk.Checksum = c.Checksum
+		}
+		b.Chunks = append(b.Chunks, chunk)
+	}
+	if c := blob.Coverage; c != nil {
+		b.Coverage = &Coverage{Version: c.Version, Fragments: c.Fragments, Stale: c.Stale}
 	}
+	return b
 }
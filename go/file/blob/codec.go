@@ -0,0 +1,53 @@
+package blob
+
+import (
+	"fmt"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/milvus-io/milvus-storage/go/proto/manifest_proto"
+)
+
+// Codec is the compression of the file of a blob.
+type Codec int32
+
+const (
+	CodecNone Codec = Codec(manifest_proto.BlobCodec_CODEC_NONE)
+	CodecZstd Codec = Codec(manifest_proto.BlobCodec_CODEC_ZSTD)
+)
+
+var (
+	// the encoder and decoder are safe for concurrent EncodeAll and DecodeAll calls
+	zstdEncoder, _ = zstd.NewWriter(nil)
+	zstdDecoder, _ = zstd.NewReader(nil)
+)
+
+func (c Codec) String() string {
+	return manifest_proto.BlobCodec(c).String()
+}
+
+func (c Codec) Compress(content []byte) ([]byte, error) {
+	switch c {
+	case CodecNone:
+		return content, nil
+	case CodecZstd:
+		return zstdEncoder.EncodeAll(content, make([]byte, 0, len(content)/2)), nil
+	default:
+		return nil, fmt.Errorf("compress with codec %d: unknown codec", c)
+	}
+}
+
+// Decompress returns the content of stored, size is the size of the content.
+func (c Codec) Decompress(stored []byte, size int64) ([]byte, error) {
+	switch c {
+	case CodecNone:
+		return stored, nil
+	case CodecZstd:
+		content, err := zstdDecoder.DecodeAll(stored, make([]byte, 0, size))
+		if err != nil {
+			return nil, fmt.Errorf("decompress with %s: %w", c, err)
+		}
+		return content, nil
+	default:
+		return nil, fmt.Errorf("decompress with codec %d: unknown codec", c)
+	}
+}
@@ -0,0 +1,27 @@
+package fragment
+
+import "github.com/bits-and-blooms/bitset"
+
+// DeletedRows holds the rows of scalar data files removed by the delete fragments, by file
+// path. A set bit is the offset of a deleted row in its file. Reads that have the deleted rows
+// of all their files skip them by offset instead of looking up every row in the deletes.
+type DeletedRows map[string]*bitset.BitSet
+
+// MemorySize returns the bytes held by the bitmaps.
+func (d DeletedRows) MemorySize() int64 {
+	var size int64
+	for file, rows := range d {
+		size += int64(len(file)) + 8*int64(len(rows.Bytes()))
+	}
+	return size
+}
+
+// Covers reports whether the deleted rows of every file are known.
+func (d DeletedRows) Covers(files []string) bool {
+	for _, file := range files {
+		if _, ok := d[file]; !ok {
+			return false
+		}
+	}
+	return true
+}
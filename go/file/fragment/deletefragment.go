@@ -1,12 +1,24 @@
 package fragment
 
 import (
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/milvus-io/milvus-storage/go/io/format/parquet"
 	"github.com/milvus-io/milvus-storage/go/io/fs"
+	"github.com/milvus-io/milvus-storage/go/storage/options/option"
 	"github.com/milvus-io/milvus-storage/go/storage/schema"
 )
 
 type pkType any
 type DeleteFragmentVector []DeleteFragment
+
+// DeleteFragment holds the primary keys deleted by a delete fragment and, for each key, the
+// versions of the deletes. A delete removes the rows of its key with a version less than or
+// equal to its own. Without a version column every delete has version math.MaxInt64.
 type DeleteFragment struct {
 	id     int64
 	schema *schema.Schema
@@ -23,7 +35,139 @@ func NewDeleteFragment(id int64, schema *schema.Schema, fs fs.Fs) *DeleteFragmen
 	}
 }
 
-func Make(f fs.Fs, s *schema.Schema, frag Fragment) DeleteFragment {
-	// TODO: implement
-	panic("implement me")
+// Make loads the deletes stored in the files of frag.
+func Make(f fs.Fs, s *schema.Schema, frag Fragment) (DeleteFragment, error) {
+	deleteFragment := NewDeleteFragment(frag.FragmentId(), s, f)
+	readOptions := option.NewReadOptions()
+	for _, field := range s.DeleteSchema().Fields() {
+		readOptions.AddColumn(field.Name)
+	}
+	for _, path := range frag.Files() {
+		reader, err := parquet.NewFileReader(f, path, readOptions)
+		if err != nil {
+			return DeleteFragment{}, fmt.Errorf("make delete fragment: %w", err)
+		}
+		for {
+			rec, err := reader.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				reader.Close()
+				return DeleteFragment{}, fmt.Errorf("make delete fragment: %w", err)
+			}
+			deleteFragment.AddRecord(rec)
+			rec.Release()
+		}
+		reader.Close()
+	}
+	return *deleteFragment, nil
+}
+
+// AddRecord adds the deletes of a record with the delete schema.
+func (d *DeleteFragment) AddRecord(rec arrow.Record) {
+	options := d.schema.Options()
+	pkColumn := rec.Column(rec.Schema().FieldIndices(options.PrimaryColumn)[0])
+	var versionColumn *array.Int64
+	if options.HasVersionColumn() {
+		versionColumn = rec.Column(rec.Schema().FieldIndices(options.VersionColumn)[0]).(*array.Int64)
+	}
+	for i := 0; i < int(rec.NumRows()); i++ {
+		version := int64(math.MaxInt64)
+		if versionColumn != nil {
+			version = versionColumn.Value(i)
+		}
+		pk := PkValue(pkColumn, i)
+		d.data[pk] = append(d.data[pk], version)
+	}
+}
+
+// Merge adds the deletes of other.
+func (d *DeleteFragment) Merge(other *DeleteFragment) {
+	for pk, versions := range other.data {
+		d.data[pk] = append(d.data[pk], versions...)
+	}
+}
+
+// IsDeleted reports whether the row with primary key pk and version is removed by a delete.
+func (d *DeleteFragment) IsDeleted(pk any, version int64) bool {
+	for _, deleteVersion := range d.data[pk] {
+		if deleteVersion >= version {
+			return true
+		}
+	}
+	return false
+}
+
+// IsDeleted reports whether the row with primary key pk and version is removed by a delete
+// of any of the fragments.
+func (v DeleteFragmentVector) IsDeleted(pk any, version int64) bool {
+	for i := range v {
+		if v[i].IsDeleted(pk, version) {
+			return true
+		}
+	}
+	return false
+}
+
+// Keys returns the number of distinct primary keys deleted by the fragments.
+func (v DeleteFragmentVector) Keys() int {
+	keys := make(map[pkType]struct{})
+	for i := range v {
+		for pk := range v[i].data {
+			keys[pk] = struct{}{}
+		}
+	}
+	return len(keys)
+}
+
+// Latest returns the newest delete version of every key, it removes every row that the
+// older deletes of the key remove.
+func (d *DeleteFragment) Latest() map[any]int64 {
+	latest := make(map[any]int64, len(d.data))
+	for pk, versions := range d.data {
+		for _, version := range versions {
+			if current, ok := latest[pk]; !ok || version > current {
+				latest[pk] = version
+			}
+		}
+	}
+	return latest
+}
+
+// Len returns the number of deletes.
+func (d *DeleteFragment) Len() int {
+	n := 0
+	for _, versions := range d.data {
+		n += len(versions)
+	}
+	return n
+}
+
+// deleteEntryOverhead estimates the bytes of a key in memory besides its versions and string
+// content: the interface, the slice header and its share of the map.
+const deleteEntryOverhead = 64
+
+// MemorySize estimates the bytes held by the deletes in memory.
+func (d *DeleteFragment) MemorySize() int64 {
+	var size int64
+	for pk, versions := range d.data {
+		size += deleteEntryOverhead + 8*int64(cap(versions))
+		if s, ok := pk.(string); ok {
+			size += int64(len(s))
+		}
+	}
+	return size
+}
+
+// PkValue returns the primary key at row i of an int64 or string column.
+func PkValue(column arrow.Array, i int) any {
+	switch c := column.(type) {
+	case *array.Int64:
+		return c.Value(i)
+	case *array.String:
+		return c.Value(i)
+	default:
+		panic(fmt.Sprintf("unsupported primary key type %s", column.DataType()))
+	}
 }
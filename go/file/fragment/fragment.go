@@ -13,6 +13,24 @@ const (
 type Fragment struct {
 	fragmentId int64
 	files      []string
+	stats      []FileStats
+	// txn is the uri of the marker of the transaction that wrote the fragment, which is only
+	// visible once the marker exists. Empty for fragments committed on their own.
+	txn string
+}
+
+// FileStats is the number of rows and bytes of a file, recorded when it is written so that
+// sizes are known without opening the file. Both are -1 for files written before stats were
+// recorded.
+type FileStats struct {
+	Rows  int64
+	Bytes int64
+}
+
+var UnknownFileStats = FileStats{Rows: -1, Bytes: -1}
+
+func (s FileStats) Known() bool {
+	return s.Rows >= 0 && s.Bytes >= 0
 }
 
 type FragmentVector []Fragment
@@ -33,8 +51,40 @@ func NewFragment(fragmentId int64) *Fragment {
 	}
 }
 
+// AddFile adds a file whose stats are set by SetFileStats once it is written.
 func (f *Fragment) AddFile(file string) {
+	f.AddFileWithStats(file, UnknownFileStats)
+}
+
+func (f *Fragment) AddFileWithStats(file string, stats FileStats) {
 	f.files = append(f.files, file)
+	f.stats = append(f.stats, stats)
+}
+
+func (f *Fragment) SetFileStats(file string, stats FileStats) {
+	for i := range f.files {
+		if f.files[i] == file {
+			f.stats[i] = stats
+		}
+	}
+}
+
+// FileStats returns the stats of the files by position.
+func (f *Fragment) FileStats() []FileStats {
+	return f.stats
+}
+
+// Stats returns the total rows and bytes of the fragment, unknown if any file is.
+func (f *Fragment) Stats() FileStats {
+	var total FileStats
+	for _, stats := range f.stats {
+		if !stats.Known() {
+			return UnknownFileStats
+		}
+		total.Rows += stats.Rows
+		total.Bytes += stats.Bytes
+	}
+	return total
 }
 
 func (f *Fragment) Files() []string {
@@ -49,19 +99,44 @@ func (f *Fragment) SetFragmentId(fragmentId int64) {
 	f.fragmentId = fragmentId
 }
 
+// Txn returns the uri of the marker of the transaction that wrote the fragment, empty if it
+// was committed on its own.
+func (f *Fragment) Txn() string {
+	return f.txn
+}
+
+func (f *Fragment) SetTxn(txn string) {
+	f.txn = txn
+}
+
 func (f *Fragment) ToProtobuf() *manifest_proto.Fragment {
 	fragment := &manifest_proto.Fragment{}
 	fragment.Id = f.fragmentId
+	fragment.Txn = f.txn
 	for _, file := range f.files {
 		fragment.Files = append(fragment.Files, file)
 	}
+	for _, stats := range f.stats {
+		if !stats.Known() {
+			// stats are stored for all files or none
+			fragment.FileStats = nil
+			break
+		}
+		fragment.FileStats = append(fragment.FileStats, &manifest_proto.FileStats{Rows: stats.Rows, Bytes: stats.Bytes})
+	}
 	return fragment
 }
 
 func FromProtobuf(fragment *manifest_proto.Fragment) *Fragment {
 	newFragment := NewFragment(fragment.Id)
-	for _, file := range fragment.Files {
-		newFragment.files = append(newFragment.files, file)
+	newFragment.txn = fragment.Txn
+	hasStats := len(fragment.FileStats) == len(fragment.Files)
+	for i, file := range fragment.Files {
+		stats := UnknownFileStats
+		if hasStats {
+			stats = FileStats{Rows: fragment.FileStats[i].Rows, Bytes: fragment.FileStats[i].Bytes}
+		}
+		newFragment.AddFileWithStats(file, stats)
 	}
 	return newFragment
 }
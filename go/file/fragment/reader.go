@@ -0,0 +1,140 @@
+package fragment
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync/atomic"
+
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/milvus-io/milvus-storage/go/common/utils"
+	"github.com/milvus-io/milvus-storage/go/io/format"
+	"github.com/milvus-io/milvus-storage/go/io/format/parquet"
+	"github.com/milvus-io/milvus-storage/go/io/fs"
+	"github.com/milvus-io/milvus-storage/go/storage/options/option"
+	"github.com/milvus-io/milvus-storage/go/storage/schema"
+)
+
+var ErrColumnNotInFragment = errors.New("column not in fragment files")
+
+// Reader reads the files of a single fragment in order, e.g. to build an index over a
+// fragment on its own machine. It reads the fragment as written: deletes are not applied and
+// the files of a scalar fragment are not joined with those of its vector fragment.
+type Reader struct {
+	ref       int64
+	fs        fs.Fs
+	files     []string
+	options   *option.ReadOptions
+	output    *arrow.Schema
+	curReader format.Reader
+	nextPos   int
+	rec       arrow.Record
+	err       error
+}
+
+// NewReader returns a reader of the columns of options in the files of fragment, which is a
+// scalar or vector fragment of a space with schema s. The columns, and those of the filters of
+// options, must all be scalar columns or all be vector columns; the primary and version
+// columns are in both. Expression filters are not applied.
+func NewReader(f fs.Fs, fragment Fragment, s *schema.Schema, options *option.ReadOptions) (*Reader, error) {
+	if len(options.Columns) == 0 {
+		return nil, fmt.Errorf("read fragment %d without columns: %w", fragment.FragmentId(), ErrColumnNotInFragment)
+	}
+	readOptions := options.Clone()
+	for column := range options.Filters {
+		if !readOptions.HasColumn(column) {
+			readOptions.AddColumn(column)
+		}
+	}
+	if !hasColumns(s.ScalarSchema(), readOptions.Columns) && !hasColumns(s.VectorSchema(), readOptions.Columns) {
+		return nil, fmt.Errorf("read fragment %d with columns %v: %w", fragment.FragmentId(), readOptions.Columns, ErrColumnNotInFragment)
+	}
+	return &Reader{
+		ref:     1,
+		fs:      f,
+		files:   fragment.Files(),
+		options: readOptions,
+		output:  utils.ProjectSchema(s.Schema(), options.Columns),
+	}, nil
+}
+
+func hasColumns(sc *arrow.Schema, columns []string) bool {
+	for _, column := range columns {
+		if !sc.HasField(column) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *Reader) Schema() *arrow.Schema {
+	return r.output
+}
+
+func (r *Reader) Retain() {
+	atomic.AddInt64(&r.ref, 1)
+}
+
+func (r *Reader) Release() {
+	if atomic.AddInt64(&r.ref, -1) == 0 {
+		if r.rec != nil {
+			r.rec.Release()
+			r.rec = nil
+		}
+		if r.curReader != nil {
+			r.curReader.Close()
+			r.curReader = nil
+		}
+	}
+}
+
+func (r *Reader) Next() bool {
+	if r.rec != nil {
+		r.rec.Release()
+		r.rec = nil
+	}
+	if r.err != nil {
+		return false
+	}
+	for {
+		if r.curReader == nil {
+			if r.nextPos >= len(r.files) {
+				return false
+			}
+			reader, err := parquet.NewFileReader(r.fs, r.files[r.nextPos], r.options)
+			if err != nil {
+				r.err = err
+				return false
+			}
+			r.nextPos++
+			r.curReader = reader
+		}
+
+		rec, err := r.curReader.Read()
+		if err != nil {
+			r.curReader.Close()
+			r.curReader = nil
+			if err == io.EOF {
+				continue
+			}
+			r.err = err
+			return false
+		}
+		// the record of the file reader may hold filter columns and be in another order
+		columns := make([]arrow.Array, 0, len(r.output.Fields()))
+		for _, field := range r.output.Fields() {
+			columns = append(columns, rec.Column(rec.Schema().FieldIndices(field.Name)[0]))
+		}
+		r.rec = array.NewRecord(r.output, columns, rec.NumRows())
+		return true
+	}
+}
+
+func (r *Reader) Record() arrow.Record {
+	return r.rec
+}
+
+func (r *Reader) Err() error {
+	return r.err
+}
@@ -0,0 +1,76 @@
+package layout
+
+import (
+	"fmt"
+	"hash/fnv"
+	"path/filepath"
+	"time"
+
+	"github.com/milvus-io/milvus-storage/go/common/errors"
+	"github.com/milvus-io/milvus-storage/go/proto/manifest_proto"
+)
+
+// ErrUnknownScheme is returned for schemes this build does not know, e.g. recorded by a newer
+// writer.
+var ErrUnknownScheme = errors.New("unknown layout scheme")
+
+// Scheme is how a space places new data files in its data directories.
+type Scheme int32
+
+const (
+	// SchemeFlat places the files directly in the data directories.
+	SchemeFlat Scheme = Scheme(manifest_proto.PathLayoutScheme_LAYOUT_FLAT)
+	// SchemeDate places the files in a subdirectory per UTC day they are written, e.g.
+	// scalar/2024/05/31, so that the files of a period can be found and expired together.
+	SchemeDate Scheme = Scheme(manifest_proto.PathLayoutScheme_LAYOUT_DATE)
+	// SchemeHash places the files in one of 256 subdirectories chosen by a hash of their
+	// name, e.g. scalar/3f, spreading the keys of an object store over prefixes that are
+	// throttled separately.
+	SchemeHash Scheme = Scheme(manifest_proto.PathLayoutScheme_LAYOUT_HASH)
+)
+
+func (s Scheme) String() string {
+	return manifest_proto.PathLayoutScheme(s).String()
+}
+
+// Check fails with ErrUnknownScheme if s is not a scheme of this build.
+func (s Scheme) Check() error {
+	if _, ok := manifest_proto.PathLayoutScheme_name[int32(s)]; !ok {
+		return fmt.Errorf("layout scheme %d: %w", s, ErrUnknownScheme)
+	}
+	return nil
+}
+
+// Layout is the directory layout of the data files of a space, recorded in its manifest so
+// that every writer places files the same way. Files already written keep their paths, the
+// manifest references them by path, so changing the layout only moves later files.
+type Layout struct {
+	// Version is incremented whenever the scheme of the space changes, 0 for spaces that never
+	// set one
+	Version int64
+	Scheme  Scheme
+}
+
+// Dir returns the directory in dir of a new file named name, written at now.
+func (l Layout) Dir(dir string, name string, now time.Time) (string, error) {
+	switch l.Scheme {
+	case SchemeFlat:
+		return dir, nil
+	case SchemeDate:
+		return filepath.Join(dir, now.UTC().Format("2006/01/02")), nil
+	case SchemeHash:
+		h := fnv.New32a()
+		h.Write([]byte(name))
+		return filepath.Join(dir, fmt.Sprintf("%02x", byte(h.Sum32()))), nil
+	default:
+		return "", fmt.Errorf("place file %s: %w", name, l.Scheme.Check())
+	}
+}
+
+func (l Layout) ToProtobuf() *manifest_proto.PathLayout {
+	return &manifest_proto.PathLayout{Version: l.Version, Scheme: manifest_proto.PathLayoutScheme(l.Scheme)}
+}
+
+func FromProtobuf(layout *manifest_proto.PathLayout) Layout {
+	return Layout{Version: layout.GetVersion(), Scheme: Scheme(layout.GetScheme())}
+}
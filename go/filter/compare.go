@@ -0,0 +1,374 @@
+package filter
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/memory"
+	"github.com/apache/arrow/go/v12/parquet/metadata"
+	"github.com/apache/arrow/go/v12/parquet/schema"
+)
+
+var ErrIncomparableValue = errors.New("value can not be compared with column")
+
+// compareFunc returns the comparison of row i of column with a constant: negative if the row
+// is smaller, zero if equal and positive if larger.
+type compareFunc func(i int) int
+
+// CheckType returns an error if f compares a column of type dataType with a value of another
+// type, such filters remove every row.
+func CheckType(f Filter, dataType arrow.DataType) error {
+	switch f := f.(type) {
+	case *ConstantFilter:
+		column := array.MakeArrayOfNull(memory.DefaultAllocator, dataType, 0)
+		defer column.Release()
+		if _, ok := comparator(column, f.value); !ok {
+			return fmt.Errorf("compare %s column %s with %T: %w", dataType, f.columnName, f.value, ErrIncomparableValue)
+		}
+	case *ConjunctionAndFilter:
+		for _, child := range f.filters {
+			if err := CheckType(child, dataType); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// comparator compares the rows of column with value, converting value to the type of the
+// column: integers and floating point numbers compare with any numeric column, strings and
+// byte slices with string and binary columns, time.Time with timestamp and date columns
+// and time.Duration with time columns. Temporal columns also compare with integers in
+// their own unit.
+func comparator(column arrow.Array, value any) (compareFunc, bool) {
+	switch c := column.(type) {
+	case *array.Int8:
+		return signedComparator(func(i int) int64 { return int64(c.Value(i)) }, value)
+	case *array.Int16:
+		return signedComparator(func(i int) int64 { return int64(c.Value(i)) }, value)
+	case *array.Int32:
+		return signedComparator(func(i int) int64 { return int64(c.Value(i)) }, value)
+	case *array.Int64:
+		return signedComparator(func(i int) int64 { return c.Value(i) }, value)
+	case *array.Uint8:
+		return unsignedComparator(func(i int) uint64 { return uint64(c.Value(i)) }, value)
+	case *array.Uint16:
+		return unsignedComparator(func(i int) uint64 { return uint64(c.Value(i)) }, value)
+	case *array.Uint32:
+		return unsignedComparator(func(i int) uint64 { return uint64(c.Value(i)) }, value)
+	case *array.Uint64:
+		return unsignedComparator(func(i int) uint64 { return c.Value(i) }, value)
+	case *array.Float32:
+		return floatComparator(func(i int) float64 { return float64(c.Value(i)) }, value)
+	case *array.Float64:
+		return floatComparator(func(i int) float64 { return c.Value(i) }, value)
+	case *array.Boolean:
+		return boolComparator(c.Value, value)
+	case *array.String:
+		return bytesComparator(func(i int) []byte { return []byte(c.Value(i)) }, value)
+	case *array.LargeString:
+		return bytesComparator(func(i int) []byte { return []byte(c.Value(i)) }, value)
+	case *array.Binary:
+		return bytesComparator(c.Value, value)
+	case *array.LargeBinary:
+		return bytesComparator(c.Value, value)
+	case *array.Timestamp:
+		unit := c.DataType().(*arrow.TimestampType).Unit
+		return temporalComparator(func(i int) int64 { return int64(c.Value(i)) }, value, timestampUnit(unit))
+	case *array.Date32:
+		return temporalComparator(func(i int) int64 { return int64(c.Value(i)) }, value, dateDays)
+	case *array.Date64:
+		return temporalComparator(func(i int) int64 { return int64(c.Value(i)) }, value, timestampUnit(arrow.Millisecond))
+	case *array.Time32:
+		unit := c.DataType().(*arrow.Time32Type).Unit
+		return temporalComparator(func(i int) int64 { return int64(c.Value(i)) }, value, timeOfDayUnit(unit))
+	case *array.Time64:
+		unit := c.DataType().(*arrow.Time64Type).Unit
+		return temporalComparator(func(i int) int64 { return int64(c.Value(i)) }, value, timeOfDayUnit(unit))
+	default:
+		return nil, false
+	}
+}
+
+// statsComparator compares the minimum and maximum of parquet statistics with value, see
+// comparator. ok is false if the statistics have no minimum and maximum or can not be
+// compared with value.
+func statsComparator(stats metadata.TypedStatistics, value any) (compareFunc, bool) {
+	if !stats.HasMinMax() {
+		return nil, false
+	}
+	logicalType := stats.Descr().LogicalType()
+	switch s := stats.(type) {
+	case *metadata.Int32Statistics:
+		values := [2]int32{s.Min(), s.Max()}
+		if intType, ok := logicalType.(*schema.IntLogicalType); ok && !intType.IsSigned() {
+			return unsignedComparator(func(i int) uint64 { return uint64(uint32(values[i])) }, value)
+		}
+		return statsTemporalComparator(logicalType, func(i int) int64 { return int64(values[i]) }, value)
+	case *metadata.Int64Statistics:
+		values := [2]int64{s.Min(), s.Max()}
+		if intType, ok := logicalType.(*schema.IntLogicalType); ok && !intType.IsSigned() {
+			return unsignedComparator(func(i int) uint64 { return uint64(values[i]) }, value)
+		}
+		return statsTemporalComparator(logicalType, func(i int) int64 { return values[i] }, value)
+	case *metadata.Float32Statistics:
+		values := [2]float32{s.Min(), s.Max()}
+		return floatComparator(func(i int) float64 { return float64(values[i]) }, value)
+	case *metadata.Float64Statistics:
+		values := [2]float64{s.Min(), s.Max()}
+		return floatComparator(func(i int) float64 { return values[i] }, value)
+	case *metadata.BooleanStatistics:
+		values := [2]bool{s.Min(), s.Max()}
+		return boolComparator(func(i int) bool { return values[i] }, value)
+	case *metadata.ByteArrayStatistics:
+		values := [2][]byte{s.Min(), s.Max()}
+		return bytesComparator(func(i int) []byte { return values[i] }, value)
+	default:
+		return nil, false
+	}
+}
+
+func statsTemporalComparator(logicalType schema.LogicalType, get func(i int) int64, value any) (compareFunc, bool) {
+	unit := func(u schema.TimeUnitType) time.Duration {
+		switch u {
+		case schema.TimeUnitMillis:
+			return time.Millisecond
+		case schema.TimeUnitMicros:
+			return time.Microsecond
+		default:
+			return time.Nanosecond
+		}
+	}
+	switch t := logicalType.(type) {
+	case schema.DateLogicalType:
+		return temporalComparator(get, value, dateDays)
+	case *schema.TimestampLogicalType:
+		return temporalComparator(get, value, temporalUnit{unit: unit(t.TimeUnit())})
+	case *schema.TimeLogicalType:
+		return temporalComparator(get, value, temporalUnit{unit: unit(t.TimeUnit()), timeOfDay: true})
+	default:
+		return signedComparator(get, value)
+	}
+}
+
+// temporalUnit is what an integer of a temporal column counts: days since the epoch, units
+// since the epoch or units since midnight.
+type temporalUnit struct {
+	unit      time.Duration
+	days      bool
+	timeOfDay bool
+}
+
+var dateDays = temporalUnit{days: true}
+
+func timestampUnit(unit arrow.TimeUnit) temporalUnit {
+	return temporalUnit{unit: unit.Multiplier()}
+}
+
+func timeOfDayUnit(unit arrow.TimeUnit) temporalUnit {
+	return temporalUnit{unit: unit.Multiplier(), timeOfDay: true}
+}
+
+// temporalComparator converts time.Time, or time.Duration for times of day, to the unit of
+// the column, values between two units compare with the earlier one and are never equal.
+func temporalComparator(get func(i int) int64, value any, unit temporalUnit) (compareFunc, bool) {
+	var nanos int64
+	switch v := value.(type) {
+	case time.Time:
+		if unit.timeOfDay {
+			return nil, false
+		}
+		if unit.days {
+			// dates compare with the day of the time in UTC
+			return signedComparator(get, floorDiv(v.Unix(), 24*3600))
+		}
+		nanos = v.UnixNano()
+	case time.Duration:
+		if !unit.timeOfDay {
+			return nil, false
+		}
+		nanos = int64(v)
+	default:
+		return signedComparator(get, value)
+	}
+	n := int64(unit.unit)
+	converted, exact := floorDiv(nanos, n), nanos%n == 0
+	return func(i int) int {
+		c := compareOrdered(get(i), converted)
+		if c == 0 && !exact {
+			// the row is before the value that falls between two units
+			return -1
+		}
+		return c
+	}, true
+}
+
+func floorDiv(a, b int64) int64 {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
+
+func signedComparator(get func(i int) int64, value any) (compareFunc, bool) {
+	if v, ok := asInt64(value); ok {
+		return func(i int) int { return compareOrdered(get(i), v) }, true
+	}
+	if _, ok := asUint64(value); ok {
+		// larger than any int64
+		return func(int) int { return -1 }, true
+	}
+	if v, ok := asFloat64(value); ok {
+		return func(i int) int { return compareOrdered(float64(get(i)), v) }, true
+	}
+	return nil, false
+}
+
+func unsignedComparator(get func(i int) uint64, value any) (compareFunc, bool) {
+	if v, ok := asUint64(value); ok {
+		return func(i int) int { return compareOrdered(get(i), v) }, true
+	}
+	if _, ok := asInt64(value); ok {
+		// negative
+		return func(int) int { return 1 }, true
+	}
+	if v, ok := asFloat64(value); ok {
+		return func(i int) int { return compareOrdered(float64(get(i)), v) }, true
+	}
+	return nil, false
+}
+
+func floatComparator(get func(i int) float64, value any) (compareFunc, bool) {
+	v, ok := asFloat64(value)
+	if !ok {
+		return nil, false
+	}
+	return func(i int) int { return compareOrdered(get(i), v) }, true
+}
+
+func boolComparator(get func(i int) bool, value any) (compareFunc, bool) {
+	v, ok := value.(bool)
+	if !ok {
+		return nil, false
+	}
+	return func(i int) int {
+		switch x := get(i); {
+		case x == v:
+			return 0
+		case !x:
+			return -1
+		default:
+			return 1
+		}
+	}, true
+}
+
+func bytesComparator(get func(i int) []byte, value any) (compareFunc, bool) {
+	var v []byte
+	switch value := value.(type) {
+	case string:
+		v = []byte(value)
+	case []byte:
+		v = value
+	default:
+		return nil, false
+	}
+	return func(i int) int { return bytes.Compare(get(i), v) }, true
+}
+
+// asInt64 returns an integer value that fits in an int64.
+func asInt64(value any) (int64, bool) {
+	switch v := value.(type) {
+	case int:
+		return int64(v), true
+	case int8:
+		return int64(v), true
+	case int16:
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	case uint:
+		return int64(v), v <= math.MaxInt64
+	case uint8:
+		return int64(v), true
+	case uint16:
+		return int64(v), true
+	case uint32:
+		return int64(v), true
+	case uint64:
+		return int64(v), v <= math.MaxInt64
+	default:
+		return 0, false
+	}
+}
+
+// asUint64 returns a non negative integer value.
+func asUint64(value any) (uint64, bool) {
+	switch v := value.(type) {
+	case uint:
+		return uint64(v), true
+	case uint64:
+		return v, true
+	}
+	v, ok := asInt64(value)
+	return uint64(v), ok && v >= 0
+}
+
+// asFloat64 returns a numeric value as a float64.
+func asFloat64(value any) (float64, bool) {
+	switch v := value.(type) {
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	case uint:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	}
+	v, ok := asInt64(value)
+	return float64(v), ok
+}
+
+type ordered interface {
+	~int64 | ~uint64 | ~float64
+}
+
+func compareOrdered[T ordered](x, y T) int {
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// matches reports whether a row whose comparison with the constant is c satisfies cmpType.
+func matches(c int, cmpType ComparisonType) bool {
+	switch cmpType {
+	case Equal:
+		return c == 0
+	case NotEqual:
+		return c != 0
+	case LessThan:
+		return c < 0
+	case LessThanOrEqual:
+		return c <= 0
+	case GreaterThan:
+		return c > 0
+	case GreaterThanOrEqual:
+		return c >= 0
+	default:
+		return true
+	}
+}
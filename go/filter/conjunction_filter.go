@@ -38,6 +38,11 @@ func (f *ConjunctionAndFilter) Apply(colData arrow.Array, filterBitSet *bitset.B
 	}
 }
 
+// MarshalJSON encodes the filter as the JSON mapping of its protobuf, see ParseJSON.
+func (f *ConjunctionAndFilter) MarshalJSON() ([]byte, error) {
+	return marshalJSON(f)
+}
+
 type ConjunctionOrFilter struct {
 	filters []Filter
 }
@@ -65,6 +70,11 @@ func (f *ConjunctionOrFilter) Type() FilterType {
 	return Or
 }
 
+// NewConjunctionAndFilter combines filters on the same column.
 func NewConjunctionAndFilter(filters ...Filter) *ConjunctionAndFilter {
-	return &ConjunctionAndFilter{filters: filters}
+	f := &ConjunctionAndFilter{filters: filters}
+	if len(filters) > 0 {
+		f.columnName = filters[0].GetColumnName()
+	}
+	return f
 }
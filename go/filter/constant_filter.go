@@ -2,8 +2,6 @@ package filter
 
 import (
 	"github.com/apache/arrow/go/v12/arrow"
-	"github.com/apache/arrow/go/v12/arrow/array"
-	"github.com/apache/arrow/go/v12/parquet"
 	"github.com/apache/arrow/go/v12/parquet/metadata"
 	"github.com/bits-and-blooms/bitset"
 )
@@ -18,116 +16,52 @@ func (f *ConstantFilter) GetColumnName() string {
 	return f.columnName
 }
 
+// CheckStatistics returns true if no row of a row group with stats can pass the filter, the value
+// is converted to the type of the column as in Apply.
 func (f *ConstantFilter) CheckStatistics(stats metadata.TypedStatistics) bool {
-	// FIXME: value may be int8/uint8/...., we should encapsulate the value type, now we just do type assertion for prototype
-	switch stats.Type() {
-	case parquet.Types.Int32:
-		i32stats := stats.(*metadata.Int32Statistics)
-		if i32stats.HasMinMax() {
-			return checkStats(f.value.(int32), i32stats.Min(), i32stats.Max(), f.cmpType)
-		}
-	case parquet.Types.Int64:
-		i64stats := stats.(*metadata.Int64Statistics)
-		if i64stats.HasMinMax() {
-			return checkStats(f.value.(int64), i64stats.Min(), i64stats.Max(), f.cmpType)
-		}
-	case parquet.Types.Float:
-		floatstats := stats.(*metadata.Float32Statistics)
-		if floatstats.HasMinMax() {
-			return checkStats(f.value.(float32), floatstats.Min(), floatstats.Max(), f.cmpType)
-		}
-	case parquet.Types.Double:
-		doublestats := stats.(*metadata.Float64Statistics)
-		if doublestats.HasMinMax() {
-			return checkStats(f.value.(float64), doublestats.Min(), doublestats.Max(), f.cmpType)
-		}
+	cmp, ok := statsComparator(stats, f.value)
+	if !ok {
+		return false
 	}
-	return false
-}
-
-type comparableValue interface {
-	int32 | int64 | float32 | float64
-}
-
-func checkStats[T comparableValue](value, min, max T, cmpType ComparisonType) bool {
-	switch cmpType {
+	min, max := cmp(0), cmp(1)
+	switch f.cmpType {
 	case Equal:
-		return value < min || value > max
+		return min > 0 || max < 0
 	case NotEqual:
-		return value == min && value == max
+		return min == 0 && max == 0
 	case LessThan:
-		return value <= min
+		return min >= 0
 	case LessThanOrEqual:
-		return value < min
+		return min > 0
 	case GreaterThan:
-		return value >= max
+		return max <= 0
 	case GreaterThanOrEqual:
-		return value > max
+		return max < 0
 	default:
 		return false
 	}
 }
 
+// Apply sets the bits of the rows of colData that do not pass the filter. Nulls never pass, and
+// neither does any row if the value can not be compared with the column, see CheckType.
 func (f *ConstantFilter) Apply(colData arrow.Array, filterBitSet *bitset.BitSet) {
-	switch data := colData.(type) {
-	case *array.Int8:
-		filterColumn(f.value.(int8), data.Int8Values(), f.cmpType, filterBitSet)
-	case *array.Uint8:
-		filterColumn(f.value.(uint8), data.Uint8Values(), f.cmpType, filterBitSet)
-	case *array.Int16:
-		filterColumn(f.value.(int16), data.Int16Values(), f.cmpType, filterBitSet)
-	case *array.Uint16:
-		filterColumn(f.value.(uint16), data.Uint16Values(), f.cmpType, filterBitSet)
-	case *array.Int32:
-		filterColumn(f.value.(int32), data.Int32Values(), f.cmpType, filterBitSet)
-	case *array.Uint32:
-		filterColumn(f.value.(uint32), data.Uint32Values(), f.cmpType, filterBitSet)
-	case *array.Int64:
-		filterColumn(f.value.(int64), data.Int64Values(), f.cmpType, filterBitSet)
-	case *array.Uint64:
-		filterColumn(f.value.(uint64), data.Uint64Values(), f.cmpType, filterBitSet)
-	case *array.Float32:
-		filterColumn(f.value.(float32), data.Float32Values(), f.cmpType, filterBitSet)
-	case *array.Float64:
-		filterColumn(f.value.(float64), data.Float64Values(), f.cmpType, filterBitSet)
-	}
-}
-
-type comparableColumnType interface {
-	int8 | uint8 | int16 | uint16 | int32 | uint32 | int64 | uint64 | float32 | float64
-}
-
-func filterColumn[T comparableColumnType](value T, targets []T, cmpType ComparisonType, filterBitSet *bitset.BitSet) {
-	for i, target := range targets {
-		if checkColumn(value, target, cmpType) {
+	cmp, ok := comparator(colData, f.value)
+	for i := 0; i < colData.Len(); i++ {
+		if !ok || colData.IsNull(i) || !matches(cmp(i), f.cmpType) {
 			filterBitSet.Set(uint(i))
 		}
 	}
 }
 
-func checkColumn[T comparableColumnType](value, target T, cmpType ComparisonType) bool {
-	switch cmpType {
-	case Equal:
-		return value != target
-	case NotEqual:
-		return value == target
-	case LessThan:
-		return value <= target
-	case LessThanOrEqual:
-		return value < target
-	case GreaterThan:
-		return value >= target
-	case GreaterThanOrEqual:
-		return value > target
-	default:
-		return false
-	}
-}
-
 func (f *ConstantFilter) Type() FilterType {
 	return Constant
 }
 
+// MarshalJSON encodes the filter as the JSON mapping of its protobuf, see ParseJSON.
+func (f *ConstantFilter) MarshalJSON() ([]byte, error) {
+	return marshalJSON(f)
+}
+
 func NewConstantFilter(cmpType ComparisonType, columnName string, value interface{}) *ConstantFilter {
 	return &ConstantFilter{
 		cmpType:    cmpType,
@@ -0,0 +1,103 @@
+package filter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/memory"
+	"github.com/bits-and-blooms/bitset"
+	"github.com/stretchr/testify/assert"
+)
+
+// passed returns the rows of column that pass f.
+func passed(f Filter, column arrow.Array) []int {
+	filtered := bitset.New(uint(column.Len()))
+	f.Apply(column, filtered)
+	rows := []int{}
+	for i := 0; i < column.Len(); i++ {
+		if !filtered.Test(uint(i)) {
+			rows = append(rows, i)
+		}
+	}
+	return rows
+}
+
+func TestConstantFilterApply(t *testing.T) {
+	mem := memory.DefaultAllocator
+
+	ints := array.NewInt32Builder(mem)
+	ints.AppendValues([]int32{-1, 0, 1}, nil)
+	ints.AppendNull()
+	intColumn := ints.NewArray()
+	defer intColumn.Release()
+	assert.Equal(t, []int{1, 2}, passed(NewConstantFilter(GreaterThanOrEqual, "", int64(0)), intColumn))
+	assert.Equal(t, []int{0, 1}, passed(NewConstantFilter(LessThan, "", 0.5), intColumn))
+	assert.Equal(t, []int{0, 1, 2}, passed(NewConstantFilter(LessThan, "", uint64(1<<63)), intColumn))
+
+	uints := array.NewUint8Builder(mem)
+	uints.AppendValues([]uint8{0, 200}, nil)
+	uintColumn := uints.NewArray()
+	defer uintColumn.Release()
+	assert.Equal(t, []int{0, 1}, passed(NewConstantFilter(GreaterThan, "", -1), uintColumn))
+	assert.Equal(t, []int{1}, passed(NewConstantFilter(Equal, "", int64(200)), uintColumn))
+
+	floats := array.NewFloat32Builder(mem)
+	floats.AppendValues([]float32{0.5, 1.5, 2.5}, nil)
+	floatColumn := floats.NewArray()
+	defer floatColumn.Release()
+	assert.Equal(t, []int{1, 2}, passed(NewConstantFilter(GreaterThan, "", 1), floatColumn))
+	assert.Equal(t, []int{1}, passed(NewConstantFilter(Equal, "", 1.5), floatColumn))
+
+	bools := array.NewBooleanBuilder(mem)
+	bools.AppendValues([]bool{true, false, true}, nil)
+	boolColumn := bools.NewArray()
+	defer boolColumn.Release()
+	assert.Equal(t, []int{0, 2}, passed(NewConstantFilter(Equal, "", true), boolColumn))
+	assert.Equal(t, []int{1}, passed(NewConstantFilter(LessThan, "", true), boolColumn))
+
+	strs := array.NewStringBuilder(mem)
+	strs.AppendValues([]string{"apple", "banana", "cherry"}, nil)
+	strColumn := strs.NewArray()
+	defer strColumn.Release()
+	assert.Equal(t, []int{1, 2}, passed(NewConstantFilter(GreaterThanOrEqual, "", "b"), strColumn))
+	assert.Equal(t, []int{0, 2}, passed(NewConstantFilter(NotEqual, "", []byte("banana")), strColumn))
+	// an incomparable value filters every row
+	assert.Equal(t, []int{}, passed(NewConstantFilter(NotEqual, "", 1), strColumn))
+
+	epoch := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	timestamps := array.NewTimestampBuilder(mem, &arrow.TimestampType{Unit: arrow.Second})
+	timestamps.AppendValues([]arrow.Timestamp{arrow.Timestamp(epoch.Unix()), arrow.Timestamp(epoch.Unix() + 1)}, nil)
+	timestampColumn := timestamps.NewArray()
+	defer timestampColumn.Release()
+	assert.Equal(t, []int{0}, passed(NewConstantFilter(Equal, "", epoch), timestampColumn))
+	// a value between two seconds is after the first one
+	assert.Equal(t, []int{0}, passed(NewConstantFilter(LessThanOrEqual, "", epoch.Add(time.Millisecond)), timestampColumn))
+	assert.Equal(t, []int{}, passed(NewConstantFilter(Equal, "", epoch.Add(time.Millisecond)), timestampColumn))
+	assert.Equal(t, []int{1}, passed(NewConstantFilter(GreaterThan, "", epoch.Unix()), timestampColumn))
+
+	dates := array.NewDate32Builder(mem)
+	dates.AppendValues([]arrow.Date32{arrow.Date32FromTime(epoch), arrow.Date32FromTime(epoch.AddDate(0, 0, 1))}, nil)
+	dateColumn := dates.NewArray()
+	defer dateColumn.Release()
+	assert.Equal(t, []int{1}, passed(NewConstantFilter(Equal, "", epoch.Add(36*time.Hour)), dateColumn))
+
+	times := array.NewTime32Builder(mem, &arrow.Time32Type{Unit: arrow.Millisecond})
+	times.AppendValues([]arrow.Time32{1000, 2000}, nil)
+	timeColumn := times.NewArray()
+	defer timeColumn.Release()
+	assert.Equal(t, []int{1}, passed(NewConstantFilter(GreaterThan, "", time.Second), timeColumn))
+	assert.Equal(t, []int{}, passed(NewConstantFilter(GreaterThan, "", epoch), timeColumn))
+}
+
+func TestCheckType(t *testing.T) {
+	assert.NoError(t, CheckType(NewConstantFilter(Equal, "a", 1), arrow.PrimitiveTypes.Float64))
+	assert.NoError(t, CheckType(NewConstantFilter(Equal, "a", time.Now()), arrow.FixedWidthTypes.Date64))
+	assert.ErrorIs(t, CheckType(NewConstantFilter(Equal, "a", "1"), arrow.PrimitiveTypes.Int64), ErrIncomparableValue)
+	assert.ErrorIs(t, CheckType(NewConjunctionAndFilter(
+		NewConstantFilter(GreaterThan, "a", 1),
+		NewConstantFilter(LessThan, "a", true),
+	), arrow.PrimitiveTypes.Int64), ErrIncomparableValue)
+	assert.ErrorIs(t, CheckType(NewConstantFilter(Equal, "a", 1), &arrow.FixedSizeBinaryType{ByteWidth: 4}), ErrIncomparableValue)
+}
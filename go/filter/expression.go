@@ -0,0 +1,311 @@
+package filter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/compute"
+	"github.com/apache/arrow/go/v12/arrow/memory"
+	"github.com/apache/arrow/go/v12/arrow/scalar"
+)
+
+var ErrInvalidExpression = errors.New("invalid expression")
+
+// Expr is a value computed from the columns of a record, see ExpressionFilter.
+type Expr interface {
+	// Columns returns the columns read by the expression.
+	Columns() []string
+	// Eval returns an array with the value of every row of rec, or a scalar if the value does
+	// not depend on the row.
+	Eval(ctx context.Context, rec arrow.Record) (compute.Datum, error)
+	String() string
+}
+
+type columnExpr string
+
+// Column is the value of a column.
+func Column(name string) Expr {
+	return columnExpr(name)
+}
+
+func (e columnExpr) Columns() []string {
+	return []string{string(e)}
+}
+
+func (e columnExpr) Eval(ctx context.Context, rec arrow.Record) (compute.Datum, error) {
+	indices := rec.Schema().FieldIndices(string(e))
+	if len(indices) == 0 {
+		return nil, fmt.Errorf("evaluate column %s: %w", string(e), ErrInvalidExpression)
+	}
+	return compute.NewDatum(rec.Column(indices[0])), nil
+}
+
+func (e columnExpr) String() string {
+	return string(e)
+}
+
+type literalExpr struct {
+	value any
+}
+
+// Literal is a constant, value is a bool, integer, floating point number, string or []byte.
+func Literal(value any) Expr {
+	return literalExpr{value: value}
+}
+
+func (e literalExpr) Columns() []string {
+	return nil
+}
+
+func (e literalExpr) Eval(ctx context.Context, rec arrow.Record) (datum compute.Datum, err error) {
+	// MakeScalar panics on values it does not support
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("evaluate literal %v: %w", e.value, ErrInvalidExpression)
+		}
+	}()
+	return compute.NewDatum(scalar.MakeScalar(e.value)), nil
+}
+
+func (e literalExpr) String() string {
+	if s, ok := e.value.(string); ok {
+		return strconv.Quote(s)
+	}
+	return fmt.Sprint(e.value)
+}
+
+type callExpr struct {
+	function string
+	args     []Expr
+}
+
+// Add, Subtract, Multiply and Divide are the checked Arrow arithmetic functions, which fail on
+// overflow and division by zero. Integers are divided with truncation.
+func Add(a, b Expr) Expr {
+	return callExpr{function: "add", args: []Expr{a, b}}
+}
+
+func Subtract(a, b Expr) Expr {
+	return callExpr{function: "sub", args: []Expr{a, b}}
+}
+
+func Multiply(a, b Expr) Expr {
+	return callExpr{function: "multiply", args: []Expr{a, b}}
+}
+
+func Divide(a, b Expr) Expr {
+	return callExpr{function: "divide", args: []Expr{a, b}}
+}
+
+// Lower and Upper change the case of a string expression.
+func Lower(e Expr) Expr {
+	return callExpr{function: "lower", args: []Expr{e}}
+}
+
+func Upper(e Expr) Expr {
+	return callExpr{function: "upper", args: []Expr{e}}
+}
+
+func (e callExpr) Columns() []string {
+	var columns []string
+	for _, arg := range e.args {
+		columns = append(columns, arg.Columns()...)
+	}
+	return columns
+}
+
+func (e callExpr) Eval(ctx context.Context, rec arrow.Record) (compute.Datum, error) {
+	args := make([]compute.Datum, 0, len(e.args))
+	defer func() {
+		for _, arg := range args {
+			arg.Release()
+		}
+	}()
+	for _, arg := range e.args {
+		datum, err := arg.Eval(ctx, rec)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, datum)
+	}
+	var (
+		result compute.Datum
+		err    error
+	)
+	switch e.function {
+	case "lower":
+		result, err = mapStrings(args[0], strings.ToLower)
+	case "upper":
+		result, err = mapStrings(args[0], strings.ToUpper)
+	default:
+		result, err = compute.CallFunction(ctx, e.function, nil, args...)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("evaluate %s: %w", e, err)
+	}
+	return result, nil
+}
+
+func (e callExpr) String() string {
+	args := make([]string, 0, len(e.args))
+	for _, arg := range e.args {
+		args = append(args, arg.String())
+	}
+	return fmt.Sprintf("%s(%s)", e.function, strings.Join(args, ", "))
+}
+
+// mapStrings applies fn to a string array or scalar, which Arrow compute does not support yet.
+func mapStrings(datum compute.Datum, fn func(string) string) (compute.Datum, error) {
+	switch d := datum.(type) {
+	case *compute.ScalarDatum:
+		s, ok := d.Value.(*scalar.String)
+		if !ok {
+			return nil, fmt.Errorf("%s is not a string: %w", d.Type(), ErrInvalidExpression)
+		}
+		if !s.IsValid() {
+			return compute.NewDatum(scalar.MakeNullScalar(arrow.BinaryTypes.String)), nil
+		}
+		return compute.NewDatum(fn(s.String())), nil
+	case *compute.ArrayDatum:
+		values := d.MakeArray()
+		defer values.Release()
+		strs, ok := values.(*array.String)
+		if !ok {
+			return nil, fmt.Errorf("%s is not a string: %w", d.Type(), ErrInvalidExpression)
+		}
+		builder := array.NewStringBuilder(memory.DefaultAllocator)
+		defer builder.Release()
+		for i := 0; i < strs.Len(); i++ {
+			if strs.IsNull(i) {
+				builder.AppendNull()
+				continue
+			}
+			builder.Append(fn(strs.Value(i)))
+		}
+		result := builder.NewArray()
+		defer result.Release()
+		return compute.NewDatum(result), nil
+	default:
+		return nil, fmt.Errorf("evaluate %s: %w", datum.Kind(), ErrInvalidExpression)
+	}
+}
+
+var compareFunctions = map[ComparisonType]string{
+	Equal:              "equal",
+	NotEqual:           "not_equal",
+	LessThan:           "less",
+	LessThanOrEqual:    "less_equal",
+	GreaterThan:        "greater",
+	GreaterThanOrEqual: "greater_equal",
+}
+
+// flipped is the comparison with the operands swapped.
+var flipped = map[ComparisonType]ComparisonType{
+	Equal:              Equal,
+	NotEqual:           NotEqual,
+	LessThan:           GreaterThan,
+	LessThanOrEqual:    GreaterThanOrEqual,
+	GreaterThan:        LessThan,
+	GreaterThanOrEqual: LessThanOrEqual,
+}
+
+// ExpressionFilter keeps the rows where the comparison of two expressions holds, e.g.
+// Add(Column("a"), Column("b")) > Literal(10) or Lower(Column("city")) == Literal("nyc").
+// Rows where either side is null are removed. Unlike Filter it may read several columns, it
+// is evaluated with Arrow compute after the rows are read.
+type ExpressionFilter struct {
+	cmpType     ComparisonType
+	left, right Expr
+}
+
+func NewExpressionFilter(cmpType ComparisonType, left, right Expr) *ExpressionFilter {
+	return &ExpressionFilter{cmpType: cmpType, left: left, right: right}
+}
+
+// Columns returns the columns read by the filter, without duplicates.
+func (f *ExpressionFilter) Columns() []string {
+	var columns []string
+	seen := make(map[string]bool)
+	for _, column := range append(f.left.Columns(), f.right.Columns()...) {
+		if !seen[column] {
+			seen[column] = true
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
+
+// Pushdown returns the equivalent constant filter if f compares a column with a literal,
+// which can skip row groups by their statistics.
+func (f *ExpressionFilter) Pushdown() (*ConstantFilter, bool) {
+	if column, ok := f.left.(columnExpr); ok {
+		if literal, ok := f.right.(literalExpr); ok {
+			return NewConstantFilter(f.cmpType, string(column), literal.value), true
+		}
+	}
+	if literal, ok := f.left.(literalExpr); ok {
+		if column, ok := f.right.(columnExpr); ok {
+			return NewConstantFilter(flipped[f.cmpType], string(column), literal.value), true
+		}
+	}
+	return nil, false
+}
+
+// Mask returns a boolean array that is true for the rows of rec that pass the filter, and
+// false or null for the others.
+func (f *ExpressionFilter) Mask(ctx context.Context, rec arrow.Record) (arrow.Array, error) {
+	function, ok := compareFunctions[f.cmpType]
+	if !ok {
+		return nil, fmt.Errorf("comparison %d: %w", f.cmpType, ErrInvalidExpression)
+	}
+	left, err := f.left.Eval(ctx, rec)
+	if err != nil {
+		return nil, err
+	}
+	defer left.Release()
+	right, err := f.right.Eval(ctx, rec)
+	if err != nil {
+		return nil, err
+	}
+	defer right.Release()
+	result, err := compute.CallFunction(ctx, function, nil, left, right)
+	if err != nil {
+		return nil, fmt.Errorf("evaluate %s: %w", f, err)
+	}
+	defer result.Release()
+	switch r := result.(type) {
+	case *compute.ArrayDatum:
+		return r.MakeArray(), nil
+	case *compute.ScalarDatum:
+		return scalar.MakeArrayFromScalar(r.Value, int(rec.NumRows()), memory.DefaultAllocator)
+	default:
+		return nil, fmt.Errorf("evaluate %s: %w", f, ErrInvalidExpression)
+	}
+}
+
+// Check returns an error if f can not be evaluated on records of schema, e.g. if it reads a
+// missing column or adds a string to a number.
+func (f *ExpressionFilter) Check(schema *arrow.Schema) error {
+	rec := array.NewRecordBuilder(memory.DefaultAllocator, schema)
+	defer rec.Release()
+	empty := rec.NewRecord()
+	defer empty.Release()
+	mask, err := f.Mask(context.Background(), empty)
+	if err != nil {
+		return err
+	}
+	defer mask.Release()
+	if mask.DataType().ID() != arrow.BOOL {
+		return fmt.Errorf("evaluate %s to %s: %w", f, mask.DataType(), ErrInvalidExpression)
+	}
+	return nil
+}
+
+func (f *ExpressionFilter) String() string {
+	return fmt.Sprintf("%s(%s, %s)", compareFunctions[f.cmpType], f.left, f.right)
+}
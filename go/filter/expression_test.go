@@ -0,0 +1,61 @@
+package filter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpressionFilter(t *testing.T) {
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "a", Type: arrow.PrimitiveTypes.Int64, Nullable: true},
+		{Name: "b", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "city", Type: arrow.BinaryTypes.String},
+	}, nil)
+	builder := array.NewRecordBuilder(memory.DefaultAllocator, schema)
+	defer builder.Release()
+	builder.Field(0).(*array.Int64Builder).AppendValues([]int64{1, 5, 9, 0}, []bool{true, true, true, false})
+	builder.Field(1).(*array.Int64Builder).AppendValues([]int64{2, 6, 1, 20}, nil)
+	builder.Field(2).(*array.StringBuilder).AppendValues([]string{"NYC", "sf", "nyc", "Nyc"}, nil)
+	rec := builder.NewRecord()
+	defer rec.Release()
+
+	mask := func(f *ExpressionFilter) []bool {
+		m, err := f.Mask(context.Background(), rec)
+		require.NoError(t, err)
+		defer m.Release()
+		values := make([]bool, m.Len())
+		for i := range values {
+			values[i] = m.IsValid(i) && m.(*array.Boolean).Value(i)
+		}
+		return values
+	}
+	sum := NewExpressionFilter(GreaterThan, Add(Column("a"), Column("b")), Literal(int64(10)))
+	assert.Equal(t, []bool{false, true, false, false}, mask(sum))
+	assert.Equal(t, []string{"a", "b"}, sum.Columns())
+	assert.Equal(t, "greater(add(a, b), 10)", sum.String())
+	city := NewExpressionFilter(Equal, Lower(Column("city")), Literal("nyc"))
+	assert.Equal(t, []bool{true, false, true, true}, mask(city))
+	assert.Equal(t, []bool{false, true, false, false}, mask(NewExpressionFilter(Equal, Upper(Column("city")), Literal("SF"))))
+	// null a is not less than b
+	assert.Equal(t, []bool{true, true, false, false}, mask(NewExpressionFilter(LessThan, Column("a"), Column("b"))))
+	assert.Equal(t, []bool{true, true, true, true}, mask(NewExpressionFilter(LessThan, Literal(1), Literal(2))))
+
+	_, ok := sum.Pushdown()
+	assert.False(t, ok)
+	pushed, ok := NewExpressionFilter(GreaterThan, Literal(int64(3)), Column("a")).Pushdown()
+	assert.True(t, ok)
+	assert.Equal(t, NewConstantFilter(LessThan, "a", int64(3)), pushed)
+
+	assert.NoError(t, sum.Check(schema))
+	assert.NoError(t, city.Check(schema))
+	assert.ErrorIs(t, NewExpressionFilter(Equal, Lower(Column("a")), Literal("x")).Check(schema), ErrInvalidExpression)
+	assert.ErrorIs(t, NewExpressionFilter(Equal, Column("missing"), Literal(1)).Check(schema), ErrInvalidExpression)
+	assert.ErrorIs(t, NewExpressionFilter(Equal, Column("a"), Literal(struct{}{})).Check(schema), ErrInvalidExpression)
+	assert.Error(t, NewExpressionFilter(Equal, Add(Column("a"), Column("city")), Literal(1)).Check(schema))
+}
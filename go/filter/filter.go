@@ -13,6 +13,7 @@ const (
 	Or
 	Constant
 	Range
+	Null
 )
 
 type Filter interface {
@@ -0,0 +1,62 @@
+package filter
+
+import (
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/parquet/metadata"
+	"github.com/bits-and-blooms/bitset"
+)
+
+// NullFilter keeps the rows whose column is null, or those whose column is not null, e.g. to
+// read the rows of a nullable vector column that are not embedded yet.
+type NullFilter struct {
+	isNull     bool
+	columnName string
+}
+
+func (f *NullFilter) GetColumnName() string {
+	return f.columnName
+}
+
+// IsNull reports whether the filter keeps the null rows rather than the others.
+func (f *NullFilter) IsNull() bool {
+	return f.isNull
+}
+
+// CheckStatistics returns true if the null count of a row group shows that none of its rows is
+// kept.
+func (f *NullFilter) CheckStatistics(stats metadata.TypedStatistics) bool {
+	if !stats.HasNullCount() {
+		return false
+	}
+	if f.isNull {
+		return stats.NullCount() == 0
+	}
+	return stats.NumValues() == 0
+}
+
+func (f *NullFilter) Apply(colData arrow.Array, filterBitSet *bitset.BitSet) {
+	for i := 0; i < colData.Len(); i++ {
+		if colData.IsNull(i) != f.isNull {
+			filterBitSet.Set(uint(i))
+		}
+	}
+}
+
+func (f *NullFilter) Type() FilterType {
+	return Null
+}
+
+// MarshalJSON encodes the filter as the JSON mapping of its protobuf, see ParseJSON.
+func (f *NullFilter) MarshalJSON() ([]byte, error) {
+	return marshalJSON(f)
+}
+
+// NewIsNullFilter keeps the rows whose column is null.
+func NewIsNullFilter(columnName string) *NullFilter {
+	return &NullFilter{isNull: true, columnName: columnName}
+}
+
+// NewIsNotNullFilter keeps the rows whose column is not null.
+func NewIsNotNullFilter(columnName string) *NullFilter {
+	return &NullFilter{columnName: columnName}
+}
@@ -0,0 +1,34 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/memory"
+	"github.com/apache/arrow/go/v12/parquet"
+	"github.com/apache/arrow/go/v12/parquet/metadata"
+	"github.com/apache/arrow/go/v12/parquet/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNullFilter(t *testing.T) {
+	vectors := array.NewFixedSizeBinaryBuilder(memory.DefaultAllocator, &arrow.FixedSizeBinaryType{ByteWidth: 4})
+	vectors.Append([]byte{1, 2, 3, 4})
+	vectors.AppendNull()
+	vectors.Append([]byte{5, 6, 7, 8})
+	column := vectors.NewArray()
+	defer column.Release()
+	assert.Equal(t, []int{1}, passed(NewIsNullFilter(""), column))
+	assert.Equal(t, []int{0, 2}, passed(NewIsNotNullFilter(""), column))
+
+	stats := func(values int64, nulls int64) metadata.TypedStatistics {
+		s := metadata.NewStatistics(schema.NewColumn(schema.NewInt64Node("a", parquet.Repetitions.Optional, -1), 1, 0), memory.DefaultAllocator)
+		s.(*metadata.Int64Statistics).Update(make([]int64, values), nulls)
+		return s
+	}
+	assert.True(t, NewIsNullFilter("a").CheckStatistics(stats(3, 0)))
+	assert.False(t, NewIsNullFilter("a").CheckStatistics(stats(3, 1)))
+	assert.True(t, NewIsNotNullFilter("a").CheckStatistics(stats(0, 3)))
+	assert.False(t, NewIsNotNullFilter("a").CheckStatistics(stats(1, 3)))
+}
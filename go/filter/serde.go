@@ -0,0 +1,135 @@
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/milvus-io/milvus-storage/go/proto/filter_proto"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+var ErrUnsupportedFilter = errors.New("unsupported filter")
+
+// ToProtobuf converts a filter tree, e.g. to send the filters of a read to a storage node.
+// Integer values are widened to int32, int64 or uint64, which compare the same after
+// FromProtobuf since values are converted to the type of the column.
+func ToProtobuf(f Filter) (*filter_proto.Filter, error) {
+	switch f := f.(type) {
+	case *ConstantFilter:
+		protoFilter := &filter_proto.Filter{Column: f.columnName, Comparison: filter_proto.ComparisonType(f.cmpType)}
+		switch v := f.value.(type) {
+		case int8:
+			protoFilter.Value = &filter_proto.Filter_Int32Value{Int32Value: int32(v)}
+		case int16:
+			protoFilter.Value = &filter_proto.Filter_Int32Value{Int32Value: int32(v)}
+		case int32:
+			protoFilter.Value = &filter_proto.Filter_Int32Value{Int32Value: v}
+		case int:
+			protoFilter.Value = &filter_proto.Filter_Int64Value{Int64Value: int64(v)}
+		case int64:
+			protoFilter.Value = &filter_proto.Filter_Int64Value{Int64Value: v}
+		case uint8:
+			protoFilter.Value = &filter_proto.Filter_Uint64Value{Uint64Value: uint64(v)}
+		case uint16:
+			protoFilter.Value = &filter_proto.Filter_Uint64Value{Uint64Value: uint64(v)}
+		case uint32:
+			protoFilter.Value = &filter_proto.Filter_Uint64Value{Uint64Value: uint64(v)}
+		case uint:
+			protoFilter.Value = &filter_proto.Filter_Uint64Value{Uint64Value: uint64(v)}
+		case uint64:
+			protoFilter.Value = &filter_proto.Filter_Uint64Value{Uint64Value: v}
+		case float32:
+			protoFilter.Value = &filter_proto.Filter_FloatValue{FloatValue: v}
+		case float64:
+			protoFilter.Value = &filter_proto.Filter_DoubleValue{DoubleValue: v}
+		case bool:
+			protoFilter.Value = &filter_proto.Filter_BoolValue{BoolValue: v}
+		case string:
+			protoFilter.Value = &filter_proto.Filter_StringValue{StringValue: v}
+		case []byte:
+			protoFilter.Value = &filter_proto.Filter_BytesValue{BytesValue: v}
+		case time.Time:
+			protoFilter.Value = &filter_proto.Filter_TimestampValue{TimestampValue: v.UnixNano()}
+		case time.Duration:
+			protoFilter.Value = &filter_proto.Filter_DurationValue{DurationValue: int64(v)}
+		default:
+			return nil, fmt.Errorf("filter on column %s with %T value: %w", f.columnName, f.value, ErrUnsupportedFilter)
+		}
+		return protoFilter, nil
+	case *ConjunctionAndFilter:
+		conjunction := &filter_proto.Conjunction{}
+		for _, child := range f.filters {
+			protoChild, err := ToProtobuf(child)
+			if err != nil {
+				return nil, err
+			}
+			conjunction.Filters = append(conjunction.Filters, protoChild)
+		}
+		return &filter_proto.Filter{Column: f.columnName, Value: &filter_proto.Filter_And{And: conjunction}}, nil
+	case *NullFilter:
+		return &filter_proto.Filter{Column: f.columnName, Value: &filter_proto.Filter_IsNull{IsNull: f.isNull}}, nil
+	default:
+		return nil, fmt.Errorf("filter of type %T: %w", f, ErrUnsupportedFilter)
+	}
+}
+
+func FromProtobuf(f *filter_proto.Filter) (Filter, error) {
+	var value interface{}
+	switch v := f.GetValue().(type) {
+	case *filter_proto.Filter_Int32Value:
+		value = v.Int32Value
+	case *filter_proto.Filter_Int64Value:
+		value = v.Int64Value
+	case *filter_proto.Filter_FloatValue:
+		value = v.FloatValue
+	case *filter_proto.Filter_DoubleValue:
+		value = v.DoubleValue
+	case *filter_proto.Filter_BoolValue:
+		value = v.BoolValue
+	case *filter_proto.Filter_StringValue:
+		value = v.StringValue
+	case *filter_proto.Filter_BytesValue:
+		value = v.BytesValue
+	case *filter_proto.Filter_Uint64Value:
+		value = v.Uint64Value
+	case *filter_proto.Filter_TimestampValue:
+		value = time.Unix(0, v.TimestampValue).UTC()
+	case *filter_proto.Filter_DurationValue:
+		value = time.Duration(v.DurationValue)
+	case *filter_proto.Filter_And:
+		var filters []Filter
+		for _, child := range v.And.GetFilters() {
+			childFilter, err := FromProtobuf(child)
+			if err != nil {
+				return nil, err
+			}
+			filters = append(filters, childFilter)
+		}
+		and := NewConjunctionAndFilter(filters...)
+		and.columnName = f.GetColumn()
+		return and, nil
+	case *filter_proto.Filter_IsNull:
+		return &NullFilter{isNull: v.IsNull, columnName: f.GetColumn()}, nil
+	default:
+		return nil, fmt.Errorf("filter on column %s has no value: %w", f.GetColumn(), ErrUnsupportedFilter)
+	}
+	return NewConstantFilter(ComparisonType(f.GetComparison()), f.GetColumn(), value), nil
+}
+
+// ParseJSON returns the filter encoded by the MarshalJSON method of a filter.
+func ParseJSON(data []byte) (Filter, error) {
+	protoFilter := &filter_proto.Filter{}
+	if err := protojson.Unmarshal(data, protoFilter); err != nil {
+		return nil, fmt.Errorf("parse filter: %w", err)
+	}
+	return FromProtobuf(protoFilter)
+}
+
+func marshalJSON(f Filter) ([]byte, error) {
+	protoFilter, err := ToProtobuf(f)
+	if err != nil {
+		return nil, err
+	}
+	return protojson.Marshal(protoFilter)
+}
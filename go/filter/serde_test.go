@@ -0,0 +1,58 @@
+package filter
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterSerialization(t *testing.T) {
+	epoch := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	filters := []Filter{
+		NewConstantFilter(Equal, "a", int32(1)),
+		NewConstantFilter(NotEqual, "a", int64(-1)),
+		NewConstantFilter(LessThan, "a", uint64(1<<63)),
+		NewConstantFilter(GreaterThan, "a", float32(1.5)),
+		NewConstantFilter(GreaterThanOrEqual, "a", 2.5),
+		NewConstantFilter(Equal, "a", true),
+		NewConstantFilter(LessThanOrEqual, "a", "b"),
+		NewConstantFilter(Equal, "a", []byte{0, 1}),
+		NewConstantFilter(LessThan, "a", epoch),
+		NewConstantFilter(LessThan, "a", time.Second),
+		NewConjunctionAndFilter(
+			NewConstantFilter(GreaterThan, "a", int64(1)),
+			NewConstantFilter(LessThan, "a", int64(5)),
+		),
+		NewConjunctionAndFilter(),
+		NewIsNullFilter("a"),
+		NewIsNotNullFilter("a"),
+	}
+	for _, f := range filters {
+		protoFilter, err := ToProtobuf(f)
+		require.NoError(t, err)
+		decoded, err := FromProtobuf(protoFilter)
+		require.NoError(t, err)
+		assert.Equal(t, f, decoded)
+
+		data, err := json.Marshal(f)
+		require.NoError(t, err)
+		decoded, err = ParseJSON(data)
+		require.NoError(t, err)
+		assert.Equal(t, f, decoded)
+	}
+
+	// narrow integers are widened
+	protoFilter, err := ToProtobuf(NewConstantFilter(Equal, "a", int8(1)))
+	require.NoError(t, err)
+	decoded, err := FromProtobuf(protoFilter)
+	require.NoError(t, err)
+	assert.Equal(t, NewConstantFilter(Equal, "a", int32(1)), decoded)
+
+	_, err = ToProtobuf(NewConstantFilter(Equal, "a", struct{}{}))
+	assert.ErrorIs(t, err, ErrUnsupportedFilter)
+	_, err = ParseJSON([]byte(`{"column":"a"}`))
+	assert.ErrorIs(t, err, ErrUnsupportedFilter)
+}
@@ -6,6 +6,7 @@ require (
 	github.com/apache/arrow/go/v12 v12.0.0-20230223012627-e0e740bd7a24
 	github.com/bits-and-blooms/bitset v1.5.0
 	github.com/google/uuid v1.3.0
+	github.com/klauspost/compress v1.16.7
 	github.com/minio/minio-go/v7 v7.0.61
 	github.com/stretchr/testify v1.8.4
 	go.uber.org/zap v1.24.0
@@ -25,7 +26,6 @@ require (
 	github.com/google/flatbuffers v2.0.8+incompatible // indirect
 	github.com/json-iterator/go v1.1.12 // indirect
 	github.com/klauspost/asmfmt v1.3.2 // indirect
-	github.com/klauspost/compress v1.16.7 // indirect
 	github.com/klauspost/cpuid/v2 v2.2.5 // indirect
 	github.com/kr/pretty v0.3.1 // indirect
 	github.com/minio/asm2plan9s v0.0.0-20200509001527-cdd76441f9d8 // indirect
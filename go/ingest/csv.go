@@ -0,0 +1,76 @@
+package ingest
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+)
+
+type csvSource struct {
+	reader  *csv.Reader
+	columns []string
+}
+
+func (s *csvSource) next() (map[string]any, error) {
+	record, err := s.reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read csv: %w", err)
+	}
+
+	row := make(map[string]any, len(s.columns))
+	for i, column := range s.columns {
+		// CSV has no null literal, empty cells are null
+		if column == "" || i >= len(record) || record[i] == "" {
+			continue
+		}
+		row[column] = record[i]
+	}
+	return row, nil
+}
+
+// NewCSVReader returns a RecordReader over CSV input. The first line must be a header whose
+// column names are mapped onto schema fields through options.ColumnMapping. Empty cells are
+// treated as null. Vector columns may hold a JSON array, e.g. "[0.1,0.2,0.3,0.4]".
+func NewCSVReader(r io.Reader, schema *arrow.Schema, options *Options) (array.RecordReader, error) {
+	if options == nil {
+		options = NewOptions()
+	}
+	reader := csv.NewReader(r)
+	if options.Comma != 0 {
+		reader.Comma = options.Comma
+	}
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read csv header: %w", err)
+	}
+	columns, err := mapColumns(header, schema, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return newBaseReader(schema, options, &csvSource{reader: reader, columns: columns}), nil
+}
+
+// mapColumns resolves input column names to schema field names. Unknown columns map to "".
+func mapColumns(header []string, schema *arrow.Schema, options *Options) ([]string, error) {
+	columns := make([]string, len(header))
+	for i, column := range header {
+		name := options.fieldName(column)
+		if !schema.HasField(name) {
+			if options.IgnoreUnknown {
+				continue
+			}
+			return nil, fmt.Errorf("map column %s: %w", column, ErrMissingColumn)
+		}
+		columns[i] = name
+	}
+	return columns, nil
+}
@@ -0,0 +1,413 @@
+package ingest
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/memory"
+)
+
+var (
+	ErrUnsupportedType = errors.New("unsupported column type")
+	ErrMissingColumn   = errors.New("missing column")
+	ErrNullValue       = errors.New("null value for non-nullable column")
+	ErrVectorDim       = errors.New("vector dimension not match")
+)
+
+const DefaultBatchSize = 1024
+
+// BinaryEncoding is how the values of binary columns are written in the input.
+type BinaryEncoding int8
+
+const (
+	// BinaryRaw takes the bytes of the value as they are.
+	BinaryRaw BinaryEncoding = iota
+	// BinaryBase64 decodes the value as standard base64, invalid input fails the row.
+	BinaryBase64
+)
+
+// Options controls how input columns are mapped onto the target schema.
+type Options struct {
+	// ColumnMapping maps input column names (CSV header or JSON key) to schema field names.
+	// Input columns not present in the mapping are matched by name.
+	ColumnMapping map[string]string
+	// BatchSize is the maximum number of rows per output record.
+	BatchSize int
+	// Comma is the CSV field delimiter, ',' if zero.
+	Comma rune
+	// IgnoreUnknown skips input columns that have no matching schema field instead of failing.
+	IgnoreUnknown bool
+	// BinaryEncoding is the encoding of the values of binary columns, BinaryRaw by default.
+	BinaryEncoding BinaryEncoding
+}
+
+func NewOptions() *Options {
+	return &Options{
+		ColumnMapping: make(map[string]string),
+		BatchSize:     DefaultBatchSize,
+		Comma:         ',',
+	}
+}
+
+func (o *Options) fieldName(column string) string {
+	if o.ColumnMapping != nil {
+		if name, ok := o.ColumnMapping[column]; ok {
+			return name
+		}
+	}
+	return column
+}
+
+func (o *Options) batchSize() int {
+	if o.BatchSize <= 0 {
+		return DefaultBatchSize
+	}
+	return o.BatchSize
+}
+
+// rowSource yields one row at a time keyed by schema field name. A missing key means null.
+type rowSource interface {
+	next() (map[string]any, error)
+}
+
+// baseReader batches rows from a rowSource into records of the target schema.
+type baseReader struct {
+	ref     int64
+	schema  *arrow.Schema
+	options *Options
+	source  rowSource
+	rec     arrow.Record
+	rows    int
+	done    bool
+	err     error
+}
+
+func newBaseReader(schema *arrow.Schema, options *Options, source rowSource) *baseReader {
+	return &baseReader{
+		ref:     1,
+		schema:  schema,
+		options: options,
+		source:  source,
+	}
+}
+
+func (r *baseReader) Retain() {
+	atomic.AddInt64(&r.ref, 1)
+}
+
+func (r *baseReader) Release() {
+	if atomic.AddInt64(&r.ref, -1) == 0 {
+		if r.rec != nil {
+			r.rec.Release()
+			r.rec = nil
+		}
+	}
+}
+
+func (r *baseReader) Schema() *arrow.Schema {
+	return r.schema
+}
+
+func (r *baseReader) Record() arrow.Record {
+	return r.rec
+}
+
+func (r *baseReader) Err() error {
+	return r.err
+}
+
+func (r *baseReader) Next() bool {
+	if r.rec != nil {
+		r.rec.Release()
+		r.rec = nil
+	}
+	if r.done || r.err != nil {
+		return false
+	}
+
+	builder := array.NewRecordBuilder(memory.DefaultAllocator, r.schema)
+	defer builder.Release()
+
+	n := 0
+	for n < r.options.batchSize() {
+		row, err := r.source.next()
+		if err != nil {
+			r.err = err
+			return false
+		}
+		if row == nil {
+			r.done = true
+			break
+		}
+		r.rows++
+		for i, field := range r.schema.Fields() {
+			if err := appendValue(builder.Field(i), field, row[field.Name], r.options); err != nil {
+				r.err = fmt.Errorf("ingest row %d column %s: %w", r.rows, field.Name, err)
+				return false
+			}
+		}
+		n++
+	}
+
+	if n == 0 {
+		return false
+	}
+	r.rec = builder.NewRecord()
+	return true
+}
+
+// appendValue coerces v into the builder's type. v is either a raw string (CSV) or a
+// value decoded from JSON with UseNumber (json.Number, string, bool, []any, nil).
+func appendValue(b array.Builder, field arrow.Field, v any, options *Options) error {
+	if v == nil {
+		if !field.Nullable {
+			return ErrNullValue
+		}
+		b.AppendNull()
+		return nil
+	}
+
+	switch builder := b.(type) {
+	case *array.BooleanBuilder:
+		val, err := toBool(v)
+		if err != nil {
+			return err
+		}
+		builder.Append(val)
+	case *array.Int8Builder:
+		val, err := toInt(v, 8)
+		if err != nil {
+			return err
+		}
+		builder.Append(int8(val))
+	case *array.Int16Builder:
+		val, err := toInt(v, 16)
+		if err != nil {
+			return err
+		}
+		builder.Append(int16(val))
+	case *array.Int32Builder:
+		val, err := toInt(v, 32)
+		if err != nil {
+			return err
+		}
+		builder.Append(int32(val))
+	case *array.Int64Builder:
+		val, err := toInt(v, 64)
+		if err != nil {
+			return err
+		}
+		builder.Append(val)
+	case *array.Uint8Builder:
+		val, err := toUint(v, 8)
+		if err != nil {
+			return err
+		}
+		builder.Append(uint8(val))
+	case *array.Uint16Builder:
+		val, err := toUint(v, 16)
+		if err != nil {
+			return err
+		}
+		builder.Append(uint16(val))
+	case *array.Uint32Builder:
+		val, err := toUint(v, 32)
+		if err != nil {
+			return err
+		}
+		builder.Append(uint32(val))
+	case *array.Uint64Builder:
+		val, err := toUint(v, 64)
+		if err != nil {
+			return err
+		}
+		builder.Append(val)
+	case *array.Float32Builder:
+		val, err := toFloat(v, 32)
+		if err != nil {
+			return err
+		}
+		builder.Append(float32(val))
+	case *array.Float64Builder:
+		val, err := toFloat(v, 64)
+		if err != nil {
+			return err
+		}
+		builder.Append(val)
+	case *array.StringBuilder:
+		builder.Append(toString(v))
+	case *array.BinaryBuilder:
+		val, err := toBytes(v, options.BinaryEncoding)
+		if err != nil {
+			return err
+		}
+		builder.Append(val)
+	case *array.FixedSizeBinaryBuilder:
+		width := field.Type.(*arrow.FixedSizeBinaryType).ByteWidth
+		val, err := toVectorBytes(v, width)
+		if err != nil {
+			return err
+		}
+		builder.Append(val)
+	case *array.FixedSizeListBuilder:
+		listType := field.Type.(*arrow.FixedSizeListType)
+		values, err := toList(v)
+		if err != nil {
+			return err
+		}
+		if len(values) != int(listType.Len()) {
+			return fmt.Errorf("%w: expect %d, actual %d", ErrVectorDim, listType.Len(), len(values))
+		}
+		builder.Append(true)
+		elemField := arrow.Field{Name: "item", Type: listType.Elem()}
+		for _, elem := range values {
+			if err := appendValue(builder.ValueBuilder(), elemField, elem, options); err != nil {
+				return err
+			}
+		}
+	case *array.ListBuilder:
+		values, err := toList(v)
+		if err != nil {
+			return err
+		}
+		builder.Append(true)
+		elemField := arrow.Field{Name: "item", Type: field.Type.(*arrow.ListType).Elem(), Nullable: true}
+		for _, elem := range values {
+			if err := appendValue(builder.ValueBuilder(), elemField, elem, options); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedType, field.Type)
+	}
+	return nil
+}
+
+func toString(v any) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case json.Number:
+		return val.String()
+	case bool:
+		return strconv.FormatBool(val)
+	default:
+		b, _ := json.Marshal(val)
+		return string(b)
+	}
+}
+
+func toBool(v any) (bool, error) {
+	if b, ok := v.(bool); ok {
+		return b, nil
+	}
+	return strconv.ParseBool(strings.TrimSpace(toString(v)))
+}
+
+func toInt(v any, bitSize int) (int64, error) {
+	s := strings.TrimSpace(toString(v))
+	val, err := strconv.ParseInt(s, 10, bitSize)
+	if err == nil {
+		return val, nil
+	}
+	// accept integral floats such as "3.0" or 1e3
+	f, ferr := strconv.ParseFloat(s, 64)
+	if ferr != nil || f != math.Trunc(f) {
+		return 0, err
+	}
+	return strconv.ParseInt(strconv.FormatFloat(f, 'f', 0, 64), 10, bitSize)
+}
+
+func toUint(v any, bitSize int) (uint64, error) {
+	return strconv.ParseUint(strings.TrimSpace(toString(v)), 10, bitSize)
+}
+
+func toFloat(v any, bitSize int) (float64, error) {
+	return strconv.ParseFloat(strings.TrimSpace(toString(v)), bitSize)
+}
+
+func toBytes(v any, encoding BinaryEncoding) ([]byte, error) {
+	s, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("%w: binary value must be a string", ErrUnsupportedType)
+	}
+	if encoding != BinaryBase64 {
+		return []byte(s), nil
+	}
+	decoded, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("decode binary: %w", err)
+	}
+	return decoded, nil
+}
+
+// toList accepts a decoded JSON array or a string holding a JSON array, e.g. "[0.1, 0.2]".
+func toList(v any) ([]any, error) {
+	if list, ok := v.([]any); ok {
+		return list, nil
+	}
+	s, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("%w: expect an array, got %T", ErrUnsupportedType, v)
+	}
+	var list []any
+	decoder := json.NewDecoder(strings.NewReader(s))
+	decoder.UseNumber()
+	if err := decoder.Decode(&list); err != nil {
+		return nil, fmt.Errorf("parse array %q: %w", s, err)
+	}
+	return list, nil
+}
+
+// toVectorBytes encodes a vector into a fixed size binary value. A JSON array is treated as
+// float32 values in little endian when width is a multiple of 4, otherwise as raw bytes.
+// A string that is not an array is decoded as base64.
+func toVectorBytes(v any, width int) ([]byte, error) {
+	if s, ok := v.(string); ok && !strings.HasPrefix(strings.TrimSpace(s), "[") {
+		decoded, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return nil, fmt.Errorf("decode vector: %w", err)
+		}
+		if len(decoded) != width {
+			return nil, fmt.Errorf("%w: expect %d bytes, actual %d", ErrVectorDim, width, len(decoded))
+		}
+		return decoded, nil
+	}
+
+	values, err := toList(v)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, width)
+	switch {
+	case len(values)*4 == width:
+		for i, elem := range values {
+			f, err := toFloat(elem, 32)
+			if err != nil {
+				return nil, err
+			}
+			binary.LittleEndian.PutUint32(buf[i*4:], math.Float32bits(float32(f)))
+		}
+	case len(values) == width:
+		for i, elem := range values {
+			b, err := toUint(elem, 8)
+			if err != nil {
+				return nil, err
+			}
+			buf[i] = byte(b)
+		}
+	default:
+		return nil, fmt.Errorf("%w: %d values for %d bytes", ErrVectorDim, len(values), width)
+	}
+	return buf, nil
+}
@@ -0,0 +1,122 @@
+package ingest
+
+import (
+	"encoding/binary"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testSchema() *arrow.Schema {
+	return arrow.NewSchema([]arrow.Field{
+		{Name: "pk_field", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "vs_field", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "score", Type: arrow.PrimitiveTypes.Float32, Nullable: true},
+		{Name: "name", Type: arrow.BinaryTypes.String, Nullable: true},
+		{Name: "vec_field", Type: &arrow.FixedSizeBinaryType{ByteWidth: 8}},
+	}, nil)
+}
+
+func TestCSVReader(t *testing.T) {
+	input := "id,vs_field,score,name,vec_field\n" +
+		"1,1,0.5,a,\"[1.0, 2.0]\"\n" +
+		"2,2,,b,\"[3, 4]\"\n" +
+		"3,3,1.5,,\"[5,6]\"\n"
+	options := NewOptions()
+	options.ColumnMapping["id"] = "pk_field"
+	options.BatchSize = 2
+
+	reader, err := NewCSVReader(strings.NewReader(input), testSchema(), options)
+	require.NoError(t, err)
+	defer reader.Release()
+
+	var pks []int64
+	var batches int
+	for reader.Next() {
+		rec := reader.Record()
+		pks = append(pks, rec.Column(0).(*array.Int64).Int64Values()...)
+		batches++
+	}
+	require.NoError(t, reader.Err())
+	assert.Equal(t, []int64{1, 2, 3}, pks)
+	assert.Equal(t, 2, batches)
+}
+
+func TestJSONLReader(t *testing.T) {
+	input := `{"pk_field": 1, "vs_field": 1, "score": 0.5, "vec_field": [1.5, -2]}
+{"pk_field": "2", "vs_field": 2, "name": "b", "vec_field": [3, 4]}
+`
+	reader, err := NewJSONLReader(strings.NewReader(input), testSchema(), nil)
+	require.NoError(t, err)
+	defer reader.Release()
+
+	require.True(t, reader.Next())
+	rec := reader.Record()
+	assert.Equal(t, int64(2), rec.NumRows())
+	assert.True(t, rec.Column(2).IsNull(1))
+	assert.True(t, rec.Column(3).IsNull(0))
+
+	vec := rec.Column(4).(*array.FixedSizeBinary).Value(0)
+	assert.Equal(t, float32(1.5), math.Float32frombits(binary.LittleEndian.Uint32(vec[0:4])))
+	assert.Equal(t, float32(-2), math.Float32frombits(binary.LittleEndian.Uint32(vec[4:8])))
+	assert.False(t, reader.Next())
+	assert.NoError(t, reader.Err())
+}
+
+func TestJSONLEmptyString(t *testing.T) {
+	input := `{"pk_field": 1, "vs_field": 1, "name": "", "vec_field": [1, 2]}`
+	reader, err := NewJSONLReader(strings.NewReader(input), testSchema(), nil)
+	require.NoError(t, err)
+	defer reader.Release()
+
+	require.True(t, reader.Next())
+	name := reader.Record().Column(3).(*array.String)
+	assert.False(t, name.IsNull(0))
+	assert.Equal(t, "", name.Value(0))
+}
+
+func TestIngestErrors(t *testing.T) {
+	_, err := NewCSVReader(strings.NewReader("unknown\n1\n"), testSchema(), nil)
+	assert.ErrorIs(t, err, ErrMissingColumn)
+
+	reader, err := NewJSONLReader(strings.NewReader(`{"pk_field": 1, "vs_field": 1, "vec_field": [1]}`), testSchema(), nil)
+	require.NoError(t, err)
+	assert.False(t, reader.Next())
+	assert.ErrorIs(t, reader.Err(), ErrVectorDim)
+
+	reader, err = NewJSONLReader(strings.NewReader(`{"vs_field": 1, "vec_field": [1, 2]}`), testSchema(), nil)
+	require.NoError(t, err)
+	assert.False(t, reader.Next())
+	assert.ErrorIs(t, reader.Err(), ErrNullValue)
+}
+
+func TestBinaryEncoding(t *testing.T) {
+	sc := arrow.NewSchema([]arrow.Field{{Name: "payload", Type: arrow.BinaryTypes.Binary}}, nil)
+	read := func(input string, options *Options) ([]byte, error) {
+		reader, err := NewCSVReader(strings.NewReader("payload\n"+input+"\n"), sc, options)
+		require.NoError(t, err)
+		defer reader.Release()
+		if !reader.Next() {
+			return nil, reader.Err()
+		}
+		return reader.Record().Column(0).(*array.Binary).Value(0), nil
+	}
+
+	// raw values are kept even if they happen to be valid base64
+	value, err := read("abcd", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("abcd"), value)
+
+	options := NewOptions()
+	options.BinaryEncoding = BinaryBase64
+	value, err = read("AQID", options)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{1, 2, 3}, value)
+	_, err = read("not base64!", options)
+	assert.Error(t, err)
+}
@@ -0,0 +1,67 @@
+package ingest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+)
+
+type jsonlSource struct {
+	scanner *bufio.Scanner
+	schema  *arrow.Schema
+	options *Options
+	line    int
+}
+
+func (s *jsonlSource) next() (map[string]any, error) {
+	for s.scanner.Scan() {
+		s.line++
+		line := bytes.TrimSpace(s.scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var obj map[string]any
+		decoder := json.NewDecoder(bytes.NewReader(line))
+		decoder.UseNumber()
+		if err := decoder.Decode(&obj); err != nil {
+			return nil, fmt.Errorf("parse json line %d: %w", s.line, err)
+		}
+
+		row := make(map[string]any, len(obj))
+		for key, value := range obj {
+			name := s.options.fieldName(key)
+			if !s.schema.HasField(name) {
+				if s.options.IgnoreUnknown {
+					continue
+				}
+				return nil, fmt.Errorf("map key %s at line %d: %w", key, s.line, ErrMissingColumn)
+			}
+			row[name] = value
+		}
+		return row, nil
+	}
+	if err := s.scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read json lines: %w", err)
+	}
+	return nil, nil
+}
+
+// NewJSONLReader returns a RecordReader over JSON Lines input, one object per line. Keys are
+// mapped onto schema fields through options.ColumnMapping; absent keys and null values are
+// treated as null. Vector columns are parsed from JSON arrays.
+func NewJSONLReader(r io.Reader, schema *arrow.Schema, options *Options) (array.RecordReader, error) {
+	if options == nil {
+		options = NewOptions()
+	}
+	scanner := bufio.NewScanner(r)
+	// vectors of high dimension easily exceed the default 64KB token size
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+
+	return newBaseReader(schema, options, &jsonlSource{scanner: scanner, schema: schema, options: options}), nil
+}
@@ -2,22 +2,86 @@ package parquet
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"github.com/apache/arrow/go/v12/arrow"
 	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/compute"
 	"github.com/apache/arrow/go/v12/arrow/memory"
+	"github.com/apache/arrow/go/v12/parquet"
 	"github.com/apache/arrow/go/v12/parquet/file"
 	"github.com/apache/arrow/go/v12/parquet/metadata"
 	"github.com/apache/arrow/go/v12/parquet/pqarrow"
 	"github.com/bits-and-blooms/bitset"
 	"github.com/milvus-io/milvus-storage/go/filter"
 	"github.com/milvus-io/milvus-storage/go/io/fs"
+	fs_file "github.com/milvus-io/milvus-storage/go/io/fs/file"
 	"github.com/milvus-io/milvus-storage/go/storage/options/option"
+	"io"
+	"sort"
+	"sync/atomic"
 )
 
+var ErrColumnNotFound = errors.New("column not found in file")
+
 type FileReader struct {
 	reader    *pqarrow.FileReader
+	input     *countingReader
+	closer    io.Closer
 	options   *option.ReadOptions
-	recReader pqarrow.RecordReader
+	recReader recordReader
+}
+
+// MaxPrefetchBytes bounds the column chunks fetched up front for a read, larger reads fetch
+// them on demand.
+const MaxPrefetchBytes = 64 << 20
+
+// countingReader counts the bytes read through ReadAt. Reads within prefetched ranges are
+// served from memory and counted when the ranges are fetched.
+type countingReader struct {
+	parquet.ReaderAtSeeker
+	n          int64
+	prefetched []prefetchedRange
+}
+
+type prefetchedRange struct {
+	offset int64
+	data   []byte
+}
+
+func (r *countingReader) ReadAt(p []byte, off int64) (int, error) {
+	for _, c := range r.prefetched {
+		if off >= c.offset && off+int64(len(p)) <= c.offset+int64(len(c.data)) {
+			return copy(p, c.data[off-c.offset:]), nil
+		}
+	}
+	n, err := r.ReaderAtSeeker.ReadAt(p, off)
+	atomic.AddInt64(&r.n, int64(n))
+	return n, err
+}
+
+// prefetch reads the ranges at once, adjacent ranges are coalesced into a single read.
+func (r *countingReader) prefetch(reader fs_file.RangeReader, ranges []fs_file.Range) error {
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].Offset < ranges[j].Offset })
+	var coalesced []fs_file.Range
+	for _, rg := range ranges {
+		if n := len(coalesced); n > 0 && rg.Offset <= coalesced[n-1].Offset+coalesced[n-1].Length {
+			if end := rg.Offset + rg.Length; end > coalesced[n-1].Offset+coalesced[n-1].Length {
+				coalesced[n-1].Length = end - coalesced[n-1].Offset
+			}
+			continue
+		}
+		coalesced = append(coalesced, rg)
+	}
+	bufs, err := reader.ReadRanges(coalesced)
+	if err != nil {
+		return err
+	}
+	for i, buf := range bufs {
+		r.prefetched = append(r.prefetched, prefetchedRange{offset: coalesced[i].Offset, data: buf})
+		atomic.AddInt64(&r.n, int64(len(buf)))
+	}
+	return nil
 }
 
 // When the Reader reaches the end of the underlying stream, it returns (nil, io.EOF)
@@ -33,10 +97,10 @@ func (r *FileReader) Read() (arrow.Record, error) {
 		return nil, err
 	}
 
-	return applyFilters(rec, r.options.Filters), nil
+	return applyFilters(rec, r.options.Filters)
 }
 
-func applyFilters(rec arrow.Record, filters map[string]filter.Filter) arrow.Record {
+func applyFilters(rec arrow.Record, filters map[string]filter.Filter) (arrow.Record, error) {
 	filterBitSet := bitset.New(uint(rec.NumRows()))
 	for col, f := range filters {
 		colIndices := rec.Schema().FieldIndices(col)
@@ -49,73 +113,17 @@ func applyFilters(rec arrow.Record, filters map[string]filter.Filter) arrow.Reco
 	}
 
 	if filterBitSet.None() {
-		return rec
-	}
-
-	var cols []arrow.Array
-	for i := 0; i < int(rec.NumCols()); i++ {
-		col := rec.Column(i)
-		switch t := col.(type) {
-		case *array.Int8:
-			builder := array.NewInt8Builder(memory.DefaultAllocator)
-			filtered := filterRecord(t.Int8Values(), filterBitSet)
-			builder.AppendValues(filtered, nil)
-			cols = append(cols, builder.NewArray())
-		case *array.Uint8:
-			builder := array.NewUint8Builder(memory.DefaultAllocator)
-			filtered := filterRecord(t.Uint8Values(), filterBitSet)
-			builder.AppendValues(filtered, nil)
-			cols = append(cols, builder.NewArray())
-		case *array.Int16:
-			builder := array.NewInt16Builder(memory.DefaultAllocator)
-			filtered := filterRecord(t.Int16Values(), filterBitSet)
-			builder.AppendValues(filtered, nil)
-			cols = append(cols, builder.NewArray())
-		case *array.Uint16:
-			builder := array.NewUint16Builder(memory.DefaultAllocator)
-			filtered := filterRecord(t.Uint16Values(), filterBitSet)
-			builder.AppendValues(filtered, nil)
-			cols = append(cols, builder.NewArray())
-		case *array.Int32:
-			builder := array.NewInt32Builder(memory.DefaultAllocator)
-			filtered := filterRecord(t.Int32Values(), filterBitSet)
-			builder.AppendValues(filtered, nil)
-			cols = append(cols, builder.NewArray())
-		case *array.Uint32:
-			builder := array.NewUint32Builder(memory.DefaultAllocator)
-			filtered := filterRecord(t.Uint32Values(), filterBitSet)
-			builder.AppendValues(filtered, nil)
-			cols = append(cols, builder.NewArray())
-		case *array.Int64:
-			builder := array.NewInt64Builder(memory.DefaultAllocator)
-			filtered := filterRecord(t.Int64Values(), filterBitSet)
-			builder.AppendValues(filtered, nil)
-			cols = append(cols, builder.NewArray())
-		case *array.Uint64:
-			builder := array.NewUint64Builder(memory.DefaultAllocator)
-			filtered := filterRecord(t.Uint64Values(), filterBitSet)
-			builder.AppendValues(filtered, nil)
-			cols = append(cols, builder.NewArray())
-		default:
-			panic("unsupported type")
-		}
+		return rec, nil
 	}
 
-	return array.NewRecord(rec.Schema(), cols, int64(cols[0].Len()))
-}
-
-type comparableColumnType interface {
-	int8 | uint8 | int16 | uint16 | int32 | uint32 | int64 | uint64 | float32 | float64
-}
-
-func filterRecord[T comparableColumnType](targets []T, filterBitSet *bitset.BitSet) []T {
-	var res []T
-	for i := 0; i < int(filterBitSet.Len()); i++ {
-		if !filterBitSet.Test(uint(i)) {
-			res = append(res, targets[i])
-		}
+	builder := array.NewBooleanBuilder(memory.DefaultAllocator)
+	defer builder.Release()
+	for i := 0; i < int(rec.NumRows()); i++ {
+		builder.Append(!filterBitSet.Test(uint(i)))
 	}
-	return res
+	mask := builder.NewArray()
+	defer mask.Release()
+	return compute.FilterRecordBatch(context.Background(), rec, mask, compute.DefaultFilterOptions())
 }
 
 func (r *FileReader) initRecReader() error {
@@ -138,7 +146,7 @@ x1:
 		for col, filter := range filters {
 			if checkColumnStats(rowGroupMetaData, col, filter) {
 				// ignore the row group
-				break x1
+				continue x1
 			}
 		}
 		rowGroups = append(rowGroups, i)
@@ -147,11 +155,28 @@ x1:
 	for _, col := range columns {
 		colIndex := fileMetaData.Schema.Root().FieldIndexByName(col)
 		if colIndex == -1 {
-			panic("column not found")
+			return fmt.Errorf("column %s: %w", col, ErrColumnNotFound)
 		}
 		colIndices = append(colIndices, colIndex)
 	}
 
+	if rangeReader, ok := r.closer.(fs_file.RangeReader); ok {
+		if ranges := chunkRanges(fileMetaData, rowGroups, colIndices); ranges != nil {
+			if err := r.input.prefetch(rangeReader, ranges); err != nil {
+				return err
+			}
+		}
+	}
+
+	if r.options != nil && r.options.Parallelism > 1 && len(rowGroups) > 1 {
+		if colIndices == nil {
+			for i := 0; i < fileMetaData.Schema.NumColumns(); i++ {
+				colIndices = append(colIndices, i)
+			}
+		}
+		r.recReader = newParallelRecordReader(r.reader, colIndices, rowGroups, r.options.Parallelism, r.reader.Props.BatchSize)
+		return nil
+	}
 	recReader, err := r.reader.GetRecordReader(context.TODO(), colIndices, rowGroups)
 	if err != nil {
 		return err
@@ -160,6 +185,40 @@ x1:
 	return nil
 }
 
+// chunkRanges returns the byte ranges of the column chunks read, or nil if they exceed
+// MaxPrefetchBytes. Nil columns means all columns.
+func chunkRanges(fileMetaData *metadata.FileMetaData, rowGroups []int, columns []int) []fs_file.Range {
+	var (
+		ranges []fs_file.Range
+		total  int64
+	)
+	for _, i := range rowGroups {
+		rowGroup := fileMetaData.RowGroup(i)
+		indices := columns
+		if indices == nil {
+			for c := 0; c < rowGroup.NumColumns(); c++ {
+				indices = append(indices, c)
+			}
+		}
+		for _, c := range indices {
+			chunk, err := rowGroup.ColumnChunk(c)
+			if err != nil {
+				return nil
+			}
+			offset := chunk.DataPageOffset()
+			if chunk.HasDictionaryPage() && chunk.DictionaryPageOffset() > 0 && chunk.DictionaryPageOffset() < offset {
+				offset = chunk.DictionaryPageOffset()
+			}
+			total += chunk.TotalCompressedSize()
+			if total > MaxPrefetchBytes {
+				return nil
+			}
+			ranges = append(ranges, fs_file.Range{Offset: offset, Length: chunk.TotalCompressedSize()})
+		}
+	}
+	return ranges
+}
+
 func checkColumnStats(rowGroupMetaData *metadata.RowGroupMetaData, col string, f filter.Filter) bool {
 	colIndex := rowGroupMetaData.Schema.Root().FieldIndexByName(col)
 	if colIndex == -1 {
@@ -177,11 +236,51 @@ func checkColumnStats(rowGroupMetaData *metadata.RowGroupMetaData, col string, f
 	return f.CheckStatistics(stats)
 }
 
+// ColumnStatistics returns the statistics of column in every row group from the footer, nil
+// for row groups without them. It returns false if the file has no such column.
+func (r *FileReader) ColumnStatistics(column string) ([]metadata.TypedStatistics, bool) {
+	fileMetaData := r.reader.ParquetReader().MetaData()
+	colIndex := fileMetaData.Schema.Root().FieldIndexByName(column)
+	if colIndex == -1 {
+		return nil, false
+	}
+	stats := make([]metadata.TypedStatistics, len(fileMetaData.RowGroups))
+	for i := range stats {
+		colMetaData, err := fileMetaData.RowGroup(i).ColumnChunk(colIndex)
+		if err != nil {
+			continue
+		}
+		if stats[i], err = colMetaData.Statistics(); err != nil {
+			stats[i] = nil
+		}
+	}
+	return stats, true
+}
+
+// Columns returns the names of the top level columns of the file from its footer.
+func (r *FileReader) Columns() []string {
+	root := r.reader.ParquetReader().MetaData().Schema.Root()
+	columns := make([]string, root.NumFields())
+	for i := range columns {
+		columns[i] = root.Field(i).Name()
+	}
+	return columns
+}
+
+// NumRows returns the number of rows in the file from its footer.
+func (r *FileReader) NumRows() int64 {
+	return r.reader.ParquetReader().NumRows()
+}
+
+func (r *FileReader) BytesRead() int64 {
+	return atomic.LoadInt64(&r.input.n)
+}
+
 func (r *FileReader) Close() error {
 	if r.recReader != nil {
 		r.recReader.Release()
 	}
-	return nil
+	return r.closer.Close()
 }
 
 func NewFileReader(fs fs.Fs, filePath string, options *option.ReadOptions) (*FileReader, error) {
@@ -190,14 +289,26 @@ func NewFileReader(fs fs.Fs, filePath string, options *option.ReadOptions) (*Fil
 		return nil, err
 	}
 
-	parquetReader, err := file.NewParquetReader(f)
+	mem := memory.DefaultAllocator
+	if options != nil {
+		mem = options.Allocator()
+	}
+	input := &countingReader{ReaderAtSeeker: f}
+	parquetReader, err := file.NewParquetReader(input, file.WithReadProps(parquet.NewReaderProperties(mem)))
 	if err != nil {
+		f.Close()
 		return nil, err
 	}
 
-	reader, err := pqarrow.NewFileReader(parquetReader, pqarrow.ArrowReadProperties{BatchSize: 1}, memory.DefaultAllocator)
+	props := pqarrow.ArrowReadProperties{BatchSize: 1}
+	if options != nil && options.BatchSize > 0 {
+		// records are read in batches of the requested size, they are regrouped after filtering
+		props.BatchSize = options.BatchSize
+	}
+	reader, err := pqarrow.NewFileReader(parquetReader, props, mem)
 	if err != nil {
+		f.Close()
 		return nil, err
 	}
-	return &FileReader{reader: reader, options: options}, nil
+	return &FileReader{reader: reader, input: input, closer: f, options: options}, nil
 }
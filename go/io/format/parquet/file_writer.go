@@ -1,20 +1,38 @@
 package parquet
 
 import (
+	"io"
+
 	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/memory"
 	"github.com/apache/arrow/go/v12/parquet"
+	"github.com/apache/arrow/go/v12/parquet/compress"
 	"github.com/apache/arrow/go/v12/parquet/pqarrow"
 	"github.com/milvus-io/milvus-storage/go/io/format"
 	"github.com/milvus-io/milvus-storage/go/io/fs"
+	"github.com/milvus-io/milvus-storage/go/storage/options/schema_option"
 )
 
 var _ format.Writer = (*FileWriter)(nil)
 
 type FileWriter struct {
 	writer *pqarrow.FileWriter
+	output *countingWriter
 	count  int64
 }
 
+// countingWriter counts the bytes written, Close is passed through to the file.
+type countingWriter struct {
+	io.WriteCloser
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.WriteCloser.Write(p)
+	w.n += int64(n)
+	return n, err
+}
+
 func (f *FileWriter) Write(record arrow.Record) error {
 	if err := f.writer.Write(record); err != nil {
 		return err
@@ -27,20 +45,101 @@ func (f *FileWriter) Count() int64 {
 	return f.count
 }
 
+func (f *FileWriter) Size() int64 {
+	return f.output.n
+}
+
 func (f *FileWriter) Close() error {
 	return f.writer.Close()
 }
 
-func NewFileWriter(schema *arrow.Schema, fs fs.Fs, filePath string) (*FileWriter, error) {
+// WriterOption changes how a FileWriter lays out its file.
+type WriterOption func(*[]parquet.WriterProperty)
+
+// WithMaxRowGroupRows bounds the rows of the row groups of the file, the parquet default
+// otherwise. The parquet library of arrow v12 neither writes page indexes nor reads single
+// pages, so small row groups stand in for them: their footer statistics let selective reads,
+// e.g. point lookups on sorted columns, skip all but the few row groups that may match.
+func WithMaxRowGroupRows(rows int64) WriterOption {
+	return func(properties *[]parquet.WriterProperty) {
+		*properties = append(*properties, parquet.WithMaxRowGroupLength(rows))
+	}
+}
+
+// NewFileWriter writes the file at filePath, storing the fields of schema as their profile in
+// profiles says. The pages buffered before they are written are allocated from mem.
+func NewFileWriter(schema *arrow.Schema, fs fs.Fs, filePath string, profiles map[string]schema_option.StorageProfile, mem memory.Allocator, opts ...WriterOption) (*FileWriter, error) {
+	// the arrow schema is stored so that the field metadata is read back
+	arrowProperties := pqarrow.NewArrowWriterProperties(pqarrow.WithStoreSchema(), pqarrow.WithAllocator(mem))
+	properties, err := writerProperties(schema, profiles, arrowProperties, mem, opts)
+	if err != nil {
+		return nil, err
+	}
+
 	file, err := fs.OpenFile(filePath)
 	if err != nil {
 		return nil, err
 	}
 
-	w, err := pqarrow.NewFileWriter(schema, file, parquet.NewWriterProperties(), pqarrow.DefaultWriterProps())
+	output := &countingWriter{WriteCloser: file}
+	w, err := pqarrow.NewFileWriter(schema, output, properties, arrowProperties)
 	if err != nil {
+		file.Close()
 		return nil, err
 	}
 
-	return &FileWriter{writer: w}, nil
+	return &FileWriter{writer: w, output: output}, nil
+}
+
+var codecs = map[schema_option.Codec]compress.Compression{
+	schema_option.CodecUncompressed: compress.Codecs.Uncompressed,
+	schema_option.CodecSnappy:       compress.Codecs.Snappy,
+	schema_option.CodecGzip:         compress.Codecs.Gzip,
+	schema_option.CodecBrotli:       compress.Codecs.Brotli,
+	schema_option.CodecZstd:         compress.Codecs.Zstd,
+}
+
+var encodings = map[schema_option.Encoding]parquet.Encoding{
+	schema_option.EncodingPlain:                parquet.Encodings.Plain,
+	schema_option.EncodingDeltaBinaryPacked:    parquet.Encodings.DeltaBinaryPacked,
+	schema_option.EncodingDeltaLengthByteArray: parquet.Encodings.DeltaLengthByteArray,
+	schema_option.EncodingDeltaByteArray:       parquet.Encodings.DeltaByteArray,
+}
+
+// writerProperties applies the profile of every field to the parquet columns it is stored in,
+// nested fields have several.
+func writerProperties(schema *arrow.Schema, profiles map[string]schema_option.StorageProfile, arrowProperties pqarrow.ArrowWriterProperties, mem memory.Allocator, opts []WriterOption) (*parquet.WriterProperties, error) {
+	options := []parquet.WriterProperty{parquet.WithAllocator(mem)}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if len(profiles) == 0 {
+		return parquet.NewWriterProperties(options...), nil
+	}
+	parquetSchema, err := pqarrow.ToParquet(schema, parquet.NewWriterProperties(), arrowProperties)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < parquetSchema.NumColumns(); i++ {
+		columnPath := parquetSchema.Column(i).ColumnPath()
+		profile, ok := profiles[columnPath[0]]
+		if !ok {
+			continue
+		}
+		path := columnPath.String()
+		if codec, ok := codecs[profile.Codec]; ok {
+			options = append(options, parquet.WithCompressionFor(path, codec))
+		}
+		if profile.Level != 0 {
+			options = append(options, parquet.WithCompressionLevelFor(path, profile.Level))
+		}
+		switch profile.Encoding {
+		case schema_option.EncodingDefault:
+		case schema_option.EncodingDictionary:
+			options = append(options, parquet.WithDictionaryFor(path, true))
+		default:
+			options = append(options, parquet.WithDictionaryFor(path, false), parquet.WithEncodingFor(path, encodings[profile.Encoding]))
+		}
+	}
+	return parquet.NewWriterProperties(options...), nil
 }
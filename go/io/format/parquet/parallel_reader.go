@@ -0,0 +1,127 @@
+package parquet
+
+import (
+	"context"
+	"io"
+
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/parquet/pqarrow"
+)
+
+// recordReader returns the records of a file, a record is released by the next call to Read
+// or by Release.
+type recordReader interface {
+	Read() (arrow.Record, error)
+	Release()
+}
+
+type rowGroupRecords struct {
+	recs []arrow.Record
+	err  error
+}
+
+// parallelRecordReader decodes row groups on up to parallelism goroutines and returns their
+// records in the order of the row groups. A row group holds its slot until it is read, so at
+// most parallelism decoded row groups are kept in memory.
+type parallelRecordReader struct {
+	cancel  context.CancelFunc
+	slots   chan struct{}
+	results []chan rowGroupRecords
+	pos     int
+	recs    []arrow.Record
+	cur     arrow.Record
+}
+
+func newParallelRecordReader(reader *pqarrow.FileReader, columns []int, rowGroups []int, parallelism int, batchSize int64) *parallelRecordReader {
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &parallelRecordReader{
+		cancel:  cancel,
+		slots:   make(chan struct{}, parallelism),
+		results: make([]chan rowGroupRecords, len(rowGroups)),
+	}
+	for i := range r.results {
+		r.results[i] = make(chan rowGroupRecords, 1)
+	}
+
+	go func() {
+		for i, rowGroup := range rowGroups {
+			select {
+			case r.slots <- struct{}{}:
+			case <-ctx.Done():
+				// every row group gets a result, Release waits for them
+				for _, result := range r.results[i:] {
+					result <- rowGroupRecords{err: ctx.Err()}
+				}
+				return
+			}
+			go func(result chan rowGroupRecords, rowGroup int) {
+				result <- readRowGroup(ctx, reader, columns, rowGroup, batchSize)
+			}(r.results[i], rowGroup)
+		}
+	}()
+	return r
+}
+
+// readRowGroup decodes a row group into records of batchSize rows unless ctx is done.
+func readRowGroup(ctx context.Context, reader *pqarrow.FileReader, columns []int, rowGroup int, batchSize int64) rowGroupRecords {
+	if err := ctx.Err(); err != nil {
+		return rowGroupRecords{err: err}
+	}
+	// the reader returns tables missing columns if its context is cancelled while decoding,
+	// so the decode itself is not cancelled
+	table, err := reader.ReadRowGroups(context.Background(), columns, []int{rowGroup})
+	if err != nil {
+		return rowGroupRecords{err: err}
+	}
+	defer table.Release()
+	tableReader := array.NewTableReader(table, batchSize)
+	defer tableReader.Release()
+	var recs []arrow.Record
+	for tableReader.Next() {
+		rec := tableReader.Record()
+		rec.Retain()
+		recs = append(recs, rec)
+	}
+	return rowGroupRecords{recs: recs}
+}
+
+func (r *parallelRecordReader) Read() (arrow.Record, error) {
+	if r.cur != nil {
+		r.cur.Release()
+		r.cur = nil
+	}
+	for len(r.recs) == 0 {
+		if r.pos == len(r.results) {
+			return nil, io.EOF
+		}
+		result := <-r.results[r.pos]
+		r.pos++
+		<-r.slots
+		if result.err != nil {
+			return nil, result.err
+		}
+		r.recs = result.recs
+	}
+	r.cur, r.recs = r.recs[0], r.recs[1:]
+	return r.cur, nil
+}
+
+// Release stops decoding and releases the records decoded but not read.
+func (r *parallelRecordReader) Release() {
+	r.cancel()
+	if r.cur != nil {
+		r.cur.Release()
+		r.cur = nil
+	}
+	for _, rec := range r.recs {
+		rec.Release()
+	}
+	r.recs = nil
+	for ; r.pos < len(r.results); r.pos++ {
+		result := <-r.results[r.pos]
+		for _, rec := range result.recs {
+			rec.Release()
+		}
+	}
+}
@@ -6,5 +6,7 @@ import (
 
 type Reader interface {
 	Read() (arrow.Record, error)
+	// BytesRead returns the number of bytes read from the file so far.
+	BytesRead() int64
 	Close() error
 }
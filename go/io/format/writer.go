@@ -4,6 +4,9 @@ import "github.com/apache/arrow/go/v12/arrow"
 
 type Writer interface {
 	Write(record arrow.Record) error
+	// Count returns the number of rows written.
 	Count() int64
+	// Size returns the number of bytes flushed to the file, it is exact after Close.
+	Size() int64
 	Close() error
 }
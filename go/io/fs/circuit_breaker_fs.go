@@ -0,0 +1,278 @@
+package fs
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/milvus-io/milvus-storage/go/common/errors"
+	"github.com/milvus-io/milvus-storage/go/common/log"
+	"github.com/milvus-io/milvus-storage/go/io/fs/file"
+	"github.com/milvus-io/milvus-storage/go/storage/options/option"
+)
+
+var ErrCircuitOpen = errors.NewWithKind(errors.ErrUnavailable, "circuit breaker open")
+
+type circuitState int8
+
+const (
+	circuitClosed circuitState = iota
+	circuitOpen
+	// circuitHalfOpen lets a single probe through, the other calls fail until it completes.
+	circuitHalfOpen
+)
+
+type circuitBreaker struct {
+	failures    int
+	openTimeout time.Duration
+
+	mu       sync.Mutex
+	state    circuitState
+	failed   int
+	openedAt time.Time
+}
+
+// allow returns whether a call may be sent, and whether it is the probe of a half-open
+// breaker.
+func (b *circuitBreaker) allow() (bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case circuitOpen:
+		if time.Since(b.openedAt) < b.openTimeout {
+			return false, ErrCircuitOpen
+		}
+		b.state = circuitHalfOpen
+		return true, nil
+	case circuitHalfOpen:
+		return false, ErrCircuitOpen
+	default:
+		return false, nil
+	}
+}
+
+// done records the outcome of a call allowed by allow.
+func (b *circuitBreaker) done(probe bool, err error) {
+	failed := isFailure(err)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch {
+	case probe && failed:
+		b.state, b.openedAt = circuitOpen, time.Now()
+		log.Warn("circuit breaker probe failed", log.String("err", err.Error()))
+	case probe:
+		b.state, b.failed = circuitClosed, 0
+		log.Info("circuit breaker closed")
+	case b.state != circuitClosed:
+		// calls sent before the breaker opened do not change it
+	case failed:
+		b.failed++
+		if b.failed >= b.failures {
+			b.state, b.openedAt = circuitOpen, time.Now()
+			log.Warn("circuit breaker opened", log.Int("failures", b.failed), log.String("err", err.Error()))
+		}
+	default:
+		b.failed = 0
+	}
+}
+
+// isFailure reports whether err means the backend is failing rather than answering.
+func isFailure(err error) bool {
+	return err != nil && err != io.EOF &&
+		!errors.Is(err, errors.ErrNotFound) &&
+		!errors.Is(err, errors.ErrPermissionDenied) &&
+		!errors.Is(err, errors.ErrConflict) &&
+		!errors.Is(err, errors.ErrChecksumMismatch)
+}
+
+func (b *circuitBreaker) call(fn func() error) error {
+	probe, err := b.allow()
+	if err != nil {
+		return err
+	}
+	err = fn()
+	b.done(probe, err)
+	return err
+}
+
+// circuitBreakerFs sends the calls to another file system, and the reads and writes of the
+// files it opens, through a circuit breaker.
+type circuitBreakerFs struct {
+	fs      Fs
+	breaker *circuitBreaker
+}
+
+// NewCircuitBreakerFs returns f failing fast with ErrCircuitOpen while it keeps failing, see
+// option.CircuitBreakerOptions. Zero options take the defaults.
+func NewCircuitBreakerFs(f Fs, options *option.CircuitBreakerOptions) Fs {
+	breaker := &circuitBreaker{failures: options.Failures, openTimeout: options.OpenTimeout}
+	if breaker.failures <= 0 {
+		breaker.failures = option.DefaultCircuitBreakerFailures
+	}
+	if breaker.openTimeout <= 0 {
+		breaker.openTimeout = option.DefaultCircuitBreakerOpenTimeout
+	}
+	return &circuitBreakerFs{fs: f, breaker: breaker}
+}
+
+func (c *circuitBreakerFs) OpenFile(path string) (file.File, error) {
+	var f file.File
+	err := c.breaker.call(func() (err error) {
+		f, err = c.fs.OpenFile(path)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &circuitBreakerFile{File: f, breaker: c.breaker}, nil
+}
+
+func (c *circuitBreakerFs) Rename(src string, dst string) error {
+	return c.breaker.call(func() error { return c.fs.Rename(src, dst) })
+}
+
+func (c *circuitBreakerFs) Copy(src string, dst string) error {
+	return c.breaker.call(func() error { return c.fs.Copy(src, dst) })
+}
+
+func (c *circuitBreakerFs) DeleteFile(path string) error {
+	return c.breaker.call(func() error { return c.fs.DeleteFile(path) })
+}
+
+func (c *circuitBreakerFs) CreateDir(path string) error {
+	return c.breaker.call(func() error { return c.fs.CreateDir(path) })
+}
+
+func (c *circuitBreakerFs) List(path string) (entries []FileEntry, err error) {
+	err = c.breaker.call(func() error {
+		entries, err = c.fs.List(path)
+		return err
+	})
+	return entries, err
+}
+
+func (c *circuitBreakerFs) ReadFile(path string) (content []byte, err error) {
+	err = c.breaker.call(func() error {
+		content, err = c.fs.ReadFile(path)
+		return err
+	})
+	return content, err
+}
+
+func (c *circuitBreakerFs) Exist(path string) (exist bool, err error) {
+	err = c.breaker.call(func() error {
+		exist, err = c.fs.Exist(path)
+		return err
+	})
+	return exist, err
+}
+
+func (c *circuitBreakerFs) Stat(path string) (info FileInfo, err error) {
+	stater, ok := c.fs.(Stater)
+	if !ok {
+		return FileInfo{}, ErrStatNotSupported
+	}
+	err = c.breaker.call(func() error {
+		info, err = stater.Stat(path)
+		return err
+	})
+	return info, err
+}
+
+// SignURL does not contact the backend, it is not guarded.
+func (c *circuitBreakerFs) SignURL(path string, ttl time.Duration) (string, error) {
+	return c.fs.SignURL(path, ttl)
+}
+
+func (c *circuitBreakerFs) Upload(localPath string, path string) error {
+	return c.breaker.call(func() error { return Upload(c.fs, localPath, path) })
+}
+
+func (c *circuitBreakerFs) Ping(ctx context.Context) error {
+	return c.breaker.call(func() error { return c.fs.Ping(ctx) })
+}
+
+// Close does not contact the backend, it is not guarded.
+func (c *circuitBreakerFs) Close() error {
+	return c.fs.Close()
+}
+
+// Sync syncs path if the wrapped file system buffers writes.
+func (c *circuitBreakerFs) Sync(path string) error {
+	syncer, ok := c.fs.(Syncer)
+	if !ok {
+		return nil
+	}
+	return c.breaker.call(func() error { return syncer.Sync(path) })
+}
+
+// circuitBreakerFile guards the calls that may reach the backend, seeking does not.
+type circuitBreakerFile struct {
+	file.File
+	breaker *circuitBreaker
+}
+
+var _ file.RangeReader = (*circuitBreakerFile)(nil)
+
+func (f *circuitBreakerFile) Read(p []byte) (n int, err error) {
+	err = f.breaker.call(func() error {
+		n, err = f.File.Read(p)
+		return err
+	})
+	return n, err
+}
+
+func (f *circuitBreakerFile) ReadAt(p []byte, off int64) (n int, err error) {
+	err = f.breaker.call(func() error {
+		n, err = f.File.ReadAt(p, off)
+		return err
+	})
+	return n, err
+}
+
+// ReadRanges reads the ranges at once if the wrapped file can, one by one otherwise.
+func (f *circuitBreakerFile) ReadRanges(ranges []file.Range) (bufs [][]byte, err error) {
+	err = f.breaker.call(func() error {
+		if reader, ok := f.File.(file.RangeReader); ok {
+			bufs, err = reader.ReadRanges(ranges)
+			return err
+		}
+		bufs, err = readRanges(f.File, ranges)
+		return err
+	})
+	return bufs, err
+}
+
+// readRanges reads the ranges of f one by one.
+func readRanges(f file.File, ranges []file.Range) ([][]byte, error) {
+	bufs := make([][]byte, len(ranges))
+	for i, r := range ranges {
+		buf := make([]byte, r.Length)
+		n, err := f.ReadAt(buf, r.Offset)
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		bufs[i] = buf[:n]
+	}
+	return bufs, nil
+}
+
+func (f *circuitBreakerFile) Write(p []byte) (n int, err error) {
+	err = f.breaker.call(func() error {
+		n, err = f.File.Write(p)
+		return err
+	})
+	return n, err
+}
+
+// Close is always sent to the file so that it releases its resources, e.g. remote files
+// upload their content on Close.
+func (f *circuitBreakerFile) Close() error {
+	probe, allowErr := f.breaker.allow()
+	err := f.File.Close()
+	if allowErr == nil {
+		f.breaker.done(probe, err)
+	}
+	return err
+}
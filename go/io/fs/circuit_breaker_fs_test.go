@@ -0,0 +1,71 @@
+package fs_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/milvus-io/milvus-storage/go/common/errors"
+	"github.com/milvus-io/milvus-storage/go/io/fs"
+	"github.com/milvus-io/milvus-storage/go/storage/options/option"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyFs fails ReadFile with err, and counts the calls that reach it.
+type flakyFs struct {
+	fs.Fs
+	err   error
+	calls int
+}
+
+func (f *flakyFs) ReadFile(path string) ([]byte, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.Fs.ReadFile(path)
+}
+
+func TestCircuitBreakerFs(t *testing.T) {
+	memoryFs := fs.NewMemoryFs()
+	require.NoError(t, fs.WriteFile(memoryFs, "a", []byte{1}))
+	flaky := &flakyFs{Fs: memoryFs, err: errors.New("connection reset")}
+	breakerFs := fs.NewCircuitBreakerFs(flaky, &option.CircuitBreakerOptions{Failures: 2, OpenTimeout: 20 * time.Millisecond})
+
+	// missing files are answers of the backend
+	flaky.err = errors.WithKind(errors.ErrNotFound, errors.New("no such file"))
+	for i := 0; i < 3; i++ {
+		_, err := breakerFs.ReadFile("a")
+		assert.True(t, errors.Is(err, errors.ErrNotFound))
+	}
+
+	flaky.err = errors.New("connection reset")
+	for i := 0; i < 2; i++ {
+		_, err := breakerFs.ReadFile("a")
+		assert.EqualError(t, err, "connection reset")
+	}
+	calls := flaky.calls
+	_, err := breakerFs.ReadFile("a")
+	assert.ErrorIs(t, err, fs.ErrCircuitOpen)
+	assert.True(t, errors.Is(err, errors.ErrUnavailable))
+	_, err = breakerFs.OpenFile("a")
+	assert.ErrorIs(t, err, fs.ErrCircuitOpen)
+	assert.Equal(t, calls, flaky.calls)
+
+	// a failed probe opens the breaker again
+	time.Sleep(30 * time.Millisecond)
+	_, err = breakerFs.ReadFile("a")
+	assert.EqualError(t, err, "connection reset")
+	_, err = breakerFs.ReadFile("a")
+	assert.ErrorIs(t, err, fs.ErrCircuitOpen)
+
+	// a successful probe closes it
+	time.Sleep(30 * time.Millisecond)
+	flaky.err = nil
+	content, err := breakerFs.ReadFile("a")
+	require.NoError(t, err)
+	assert.Equal(t, []byte{1}, content)
+	content, err = breakerFs.ReadFile("a")
+	require.NoError(t, err)
+	assert.Equal(t, []byte{1}, content)
+}
@@ -0,0 +1,61 @@
+package file
+
+import (
+	"net/http"
+	"os"
+
+	"github.com/milvus-io/milvus-storage/go/common/errors"
+	"github.com/minio/minio-go/v7"
+)
+
+// FromOsError classifies errors returned by the os package. Errors of other kinds, including
+// io.EOF, are returned unchanged.
+func FromOsError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, os.ErrNotExist):
+		return errors.WithKind(errors.ErrNotFound, err)
+	case errors.Is(err, os.ErrPermission):
+		return errors.WithKind(errors.ErrPermissionDenied, err)
+	case errors.Is(err, os.ErrExist):
+		return errors.WithKind(errors.ErrConflict, err)
+	default:
+		return err
+	}
+}
+
+// FromMinioError classifies errors returned by the minio client by their S3 error code and
+// http status. Errors of other kinds, including io.EOF, are returned unchanged.
+func FromMinioError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var resp minio.ErrorResponse
+	if !errors.As(err, &resp) {
+		return err
+	}
+	switch resp.Code {
+	case "NoSuchKey", "NoSuchBucket", "NoSuchUpload":
+		return errors.WithKind(errors.ErrNotFound, err)
+	case "AccessDenied", "InvalidAccessKeyId", "SignatureDoesNotMatch", "AccountProblem":
+		return errors.WithKind(errors.ErrPermissionDenied, err)
+	case "SlowDown", "SlowDownRead", "SlowDownWrite", "RequestLimitExceeded", "Throttling", "ServiceUnavailable":
+		return errors.WithKind(errors.ErrThrottled, err)
+	case "BadDigest", "InvalidDigest", "XAmzContentSHA256Mismatch", "XAmzContentChecksumMismatch":
+		return errors.WithKind(errors.ErrChecksumMismatch, err)
+	case "PreconditionFailed", "OperationAborted", "ConditionalRequestConflict":
+		return errors.WithKind(errors.ErrConflict, err)
+	}
+	switch resp.StatusCode {
+	case http.StatusNotFound:
+		return errors.WithKind(errors.ErrNotFound, err)
+	case http.StatusForbidden, http.StatusUnauthorized:
+		return errors.WithKind(errors.ErrPermissionDenied, err)
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return errors.WithKind(errors.ErrThrottled, err)
+	case http.StatusConflict, http.StatusPreconditionFailed:
+		return errors.WithKind(errors.ErrConflict, err)
+	}
+	return err
+}
@@ -9,3 +9,17 @@ type File interface {
 	io.Reader
 	io.Closer
 }
+
+// Range is a byte range of a file.
+type Range struct {
+	Offset int64
+	Length int64
+}
+
+// RangeReader is implemented by files that can read several ranges at once more cheaply
+// than one ReadAt per range, e.g. by issuing the reads concurrently.
+type RangeReader interface {
+	// ReadRanges returns the content of every range in order. Ranges past the end of the
+	// file are truncated.
+	ReadRanges(ranges []Range) ([][]byte, error)
+}
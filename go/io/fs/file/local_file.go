@@ -3,10 +3,16 @@ package file
 import (
 	"io"
 	"os"
+	"sync"
 )
 
 var EOF = io.EOF
 
+// DefaultReadConcurrency is the number of preads ReadRanges issues at the same time.
+const DefaultReadConcurrency = 8
+
+var _ RangeReader = (*LocalFile)(nil)
+
 type LocalFile struct {
 	file os.File
 }
@@ -23,6 +29,39 @@ func (l *LocalFile) ReadAt(p []byte, off int64) (n int, err error) {
 	return l.file.ReadAt(p, off)
 }
 
+// ReadRanges reads the ranges with concurrent preads, the file offset is not used so they
+// do not interfere with each other or with Read.
+func (l *LocalFile) ReadRanges(ranges []Range) ([][]byte, error) {
+	bufs := make([][]byte, len(ranges))
+	errs := make([]error, len(ranges))
+	sem := make(chan struct{}, DefaultReadConcurrency)
+	var wg sync.WaitGroup
+	for i, r := range ranges {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, r Range) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			buf := make([]byte, r.Length)
+			n, err := l.file.ReadAt(buf, r.Offset)
+			if err != nil && err != io.EOF {
+				errs[i] = FromOsError(err)
+				return
+			}
+			bufs[i] = buf[:n]
+		}(i, r)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return bufs, nil
+}
+
 func (l *LocalFile) Seek(offset int64, whence int) (int64, error) {
 	return l.file.Seek(offset, whence)
 }
@@ -3,11 +3,71 @@ package file
 import (
 	"bytes"
 	"context"
+	"io"
+	"sync"
 
+	"github.com/milvus-io/milvus-storage/go/io/fs/limiter"
 	"github.com/minio/minio-go/v7"
 )
 
-var _ File = (*MinioFile)(nil)
+var (
+	_ File        = (*MinioFile)(nil)
+	_ RangeReader = (*MinioFile)(nil)
+)
+
+// DownloadOptions control how large reads are fetched, they are split into parts of PartSize
+// bytes downloaded with up to Parallelism concurrent ranged GETs.
+type DownloadOptions struct {
+	PartSize    int64
+	Parallelism int
+}
+
+var DefaultDownloadOptions = DownloadOptions{
+	PartSize:    8 << 20,
+	Parallelism: 4,
+}
+
+// ObjectOptions are applied by the S3 backend to the objects it writes and the requests it
+// sends.
+type ObjectOptions struct {
+	// RequesterPays accesses a bucket whose requester pays for the requests, e.g. the data
+	// transfer, which is refused otherwise.
+	RequesterPays bool
+	// ExpectedBucketOwner is the account the bucket must belong to, requests to a bucket of
+	// another account fail. Empty does not check the owner.
+	ExpectedBucketOwner string
+	// Tags and Metadata are set on every object written, e.g. for cost allocation or lifecycle
+	// rules targeting the data of the spaces. Copies keep those of their source.
+	Tags     map[string]string
+	Metadata map[string]string
+}
+
+// Headers returns the headers sent with the reads, stats and lists. The minio client does not
+// let writes, copies and deletes send them, those must be made by the bucket owner.
+func (o ObjectOptions) Headers() map[string]string {
+	headers := make(map[string]string)
+	if o.RequesterPays {
+		headers["x-amz-request-payer"] = "requester"
+	}
+	if o.ExpectedBucketOwner != "" {
+		headers["x-amz-expected-bucket-owner"] = o.ExpectedBucketOwner
+	}
+	return headers
+}
+
+// GetOptions returns the options of a read or a stat of an object.
+func (o ObjectOptions) GetOptions() minio.GetObjectOptions {
+	options := minio.GetObjectOptions{}
+	for key, value := range o.Headers() {
+		options.Set(key, value)
+	}
+	return options
+}
+
+// PutOptions returns the options of a write of an object.
+func (o ObjectOptions) PutOptions() minio.PutObjectOptions {
+	return minio.PutObjectOptions{UserTags: o.Tags, UserMetadata: o.Metadata}
+}
 
 type MinioFile struct {
 	*minio.Object
@@ -15,6 +75,146 @@ type MinioFile struct {
 	client     *minio.Client
 	fileName   string
 	bucketName string
+	limiter    *limiter.Limiter
+	download   DownloadOptions
+	objects    ObjectOptions
+	size       int64
+	etag       string
+}
+
+// Read and ReadAt may issue a ranged GET each, so they are bounded by the limiter.
+func (f *MinioFile) Read(p []byte) (int, error) {
+	release, err := f.limiter.Acquire(context.TODO())
+	if err != nil {
+		return 0, err
+	}
+	defer release()
+	n, err := f.Object.Read(p)
+	return n, FromMinioError(err)
+}
+
+// ReadAt downloads reads larger than a part in parallel.
+func (f *MinioFile) ReadAt(p []byte, off int64) (int, error) {
+	if f.Object != nil && int64(len(p)) > f.download.PartSize && f.download.Parallelism > 1 {
+		bufs, err := f.ReadRanges([]Range{{Offset: off, Length: int64(len(p))}})
+		if err != nil {
+			return 0, err
+		}
+		n := copy(p, bufs[0])
+		if n < len(p) {
+			return n, io.EOF
+		}
+		return n, nil
+	}
+	release, err := f.limiter.Acquire(context.TODO())
+	if err != nil {
+		return 0, err
+	}
+	defer release()
+	n, err := f.Object.ReadAt(p, off)
+	return n, FromMinioError(err)
+}
+
+// ReadRanges downloads the ranges split into parts with up to Parallelism concurrent ranged
+// GETs, ranges are truncated at the end of the object. Parts are only read from the version
+// of the object that was opened.
+func (f *MinioFile) ReadRanges(ranges []Range) ([][]byte, error) {
+	return DownloadRanges(f.client, f.bucketName, f.fileName, f.size, f.etag, ranges, f.download, f.objects, f.limiter)
+}
+
+// DownloadRanges reads the ranges of an object of size bytes, see MinioFile.ReadRanges. An
+// empty etag reads whatever version is current.
+func DownloadRanges(
+	client *minio.Client,
+	bucketName string,
+	fileName string,
+	size int64,
+	etag string,
+	ranges []Range,
+	options DownloadOptions,
+	objects ObjectOptions,
+	l *limiter.Limiter,
+) ([][]byte, error) {
+	type part struct {
+		buf    []byte
+		offset int64
+	}
+	partSize, parallelism := options.PartSize, options.Parallelism
+	if partSize <= 0 {
+		partSize = DefaultDownloadOptions.PartSize
+	}
+	if parallelism <= 0 {
+		parallelism = 1
+	}
+
+	bufs := make([][]byte, len(ranges))
+	var parts []part
+	for i, r := range ranges {
+		end := r.Offset + r.Length
+		if end > size {
+			end = size
+		}
+		if end <= r.Offset {
+			bufs[i] = []byte{}
+			continue
+		}
+		bufs[i] = make([]byte, end-r.Offset)
+		for offset := r.Offset; offset < end; offset += partSize {
+			partEnd := offset + partSize
+			if partEnd > end {
+				partEnd = end
+			}
+			parts = append(parts, part{buf: bufs[i][offset-r.Offset : partEnd-r.Offset], offset: offset})
+		}
+	}
+
+	errs := make([]error, len(parts))
+	sem := make(chan struct{}, parallelism)
+	var wg sync.WaitGroup
+	for i, p := range parts {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, p part) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			errs[i] = downloadPart(client, bucketName, fileName, etag, p.offset, p.buf, objects, l)
+		}(i, p)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return bufs, nil
+}
+
+func downloadPart(client *minio.Client, bucketName string, fileName string, etag string, offset int64, buf []byte, objects ObjectOptions, l *limiter.Limiter) error {
+	release, err := l.Acquire(context.TODO())
+	if err != nil {
+		return err
+	}
+	defer release()
+	options := objects.GetOptions()
+	if etag != "" {
+		if err = options.SetMatchETag(etag); err != nil {
+			return err
+		}
+	}
+	if err = options.SetRange(offset, offset+int64(len(buf))-1); err != nil {
+		return err
+	}
+	object, err := client.GetObject(context.TODO(), bucketName, fileName, options)
+	if err != nil {
+		return FromMinioError(err)
+	}
+	defer object.Close()
+	if _, err = io.ReadFull(object, buf); err != nil {
+		return FromMinioError(err)
+	}
+	return nil
 }
 
 func (f *MinioFile) Write(b []byte) (int, error) {
@@ -25,28 +225,41 @@ func (f *MinioFile) Close() error {
 	if len(f.writer.b) == 0 {
 		return nil
 	}
-	_, err := f.client.PutObject(context.TODO(), f.bucketName, f.fileName, bytes.NewReader(f.writer.b), int64(len(f.writer.b)), minio.PutObjectOptions{})
-	return err
+	release, err := f.limiter.Acquire(context.TODO())
+	if err != nil {
+		return err
+	}
+	defer release()
+	_, err = f.client.PutObject(context.TODO(), f.bucketName, f.fileName, bytes.NewReader(f.writer.b), int64(len(f.writer.b)), f.objects.PutOptions())
+	return FromMinioError(err)
 }
 
-func NewMinioFile(client *minio.Client, fileName string, bucketName string) (*MinioFile, error) {
-	_, err := client.StatObject(context.TODO(), bucketName, fileName, minio.StatObjectOptions{})
+func NewMinioFile(client *minio.Client, fileName string, bucketName string, l *limiter.Limiter, download DownloadOptions, objects ObjectOptions) (*MinioFile, error) {
+	release, err := l.Acquire(context.TODO())
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	info, err := client.StatObject(context.TODO(), bucketName, fileName, objects.GetOptions())
 	if err != nil {
 		eresp := minio.ToErrorResponse(err)
 		if eresp.Code != "NoSuchKey" {
-			return nil, err
+			return nil, FromMinioError(err)
 		}
 		return &MinioFile{
 			writer:     NewMemoryFile(nil),
 			client:     client,
 			fileName:   fileName,
 			bucketName: bucketName,
+			limiter:    l,
+			download:   download,
+			objects:    objects,
 		}, nil
 	}
 
-	object, err := client.GetObject(context.TODO(), bucketName, fileName, minio.GetObjectOptions{})
+	object, err := client.GetObject(context.TODO(), bucketName, fileName, objects.GetOptions())
 	if err != nil {
-		return nil, err
+		return nil, FromMinioError(err)
 	}
 
 	return &MinioFile{
@@ -55,5 +268,10 @@ func NewMinioFile(client *minio.Client, fileName string, bucketName string) (*Mi
 		client:     client,
 		fileName:   fileName,
 		bucketName: bucketName,
+		limiter:    l,
+		download:   download,
+		objects:    objects,
+		size:       info.Size,
+		etag:       info.ETag,
 	}, nil
 }
@@ -1,18 +1,66 @@
 package fs
 
 import (
+	"context"
+	"time"
+
 	"github.com/milvus-io/milvus-storage/go/io/fs/file"
 )
 
 type Fs interface {
 	OpenFile(path string) (file.File, error)
 	Rename(src string, dst string) error
+	// Copy copies src to dst without passing the data through the process where the backend
+	// supports it, dst is replaced if it exists.
+	Copy(src string, dst string) error
 	DeleteFile(path string) error
 	CreateDir(path string) error
 	List(path string) ([]FileEntry, error)
 	ReadFile(path string) ([]byte, error)
 	Exist(path string) (bool, error)
+	// SignURL returns a URL granting read access to path for ttl without credentials,
+	// ErrSignURLNotSupported if the backend cannot produce one.
+	SignURL(path string, ttl time.Duration) (string, error)
+	// Ping checks that the backend is reachable with the configured credentials, e.g. that the
+	// bucket exists and may be accessed.
+	Ping(ctx context.Context) error
+	// Close releases the resources held by the file system, e.g. its idle connections. The
+	// file system must not be used afterwards.
+	Close() error
+}
+
+// Syncer is implemented by file systems whose writes are buffered by the machine until
+// flushed, e.g. local disks.
+type Syncer interface {
+	// Sync flushes the file or directory at path to stable storage. Syncing a directory makes
+	// the creation and renaming of its entries durable.
+	Sync(path string) error
+}
+
+// Stater is implemented by file systems that can describe a file without reading it.
+type Stater interface {
+	// Stat returns the size, entity tag and modification time of the file at path.
+	Stat(path string) (FileInfo, error)
 }
+
+// Uploader is implemented by file systems that can store a local file without holding it in
+// memory, e.g. object stores uploading it in parts.
+type Uploader interface {
+	// Upload stores the content of the local file at localPath at path, replacing it.
+	Upload(localPath string, path string) error
+}
+
+// FileInfo describes a file. ETag changes whenever the content of the file does, files with
+// the same path, size and ETag can be assumed identical. ModTime is when the file was last
+// written.
+type FileInfo struct {
+	Size    int64
+	ETag    string
+	ModTime time.Time
+}
+
 type FileEntry struct {
 	Path string
+	// IsDir is set for subdirectories, or the common prefixes of object stores
+	IsDir bool
 }
@@ -3,13 +3,19 @@ package fs
 import (
 	"errors"
 	"fmt"
+	"io"
 	"net/url"
+	"os"
+	"path/filepath"
 
+	"github.com/milvus-io/milvus-storage/go/io/fs/file"
 	"github.com/milvus-io/milvus-storage/go/storage/options/option"
 )
 
 var (
-	ErrInvalidFsType = errors.New("invalid fs type")
+	ErrInvalidFsType       = errors.New("invalid fs type")
+	ErrSignURLNotSupported = errors.New("sign url not supported")
+	ErrStatNotSupported    = errors.New("stat not supported")
 )
 
 func BuildFileSystem(uri string) (Fs, error) {
@@ -27,3 +33,90 @@ func BuildFileSystem(uri string) (Fs, error) {
 		return nil, fmt.Errorf("build file system with uri %s: %w", uri, ErrInvalidFsType)
 	}
 }
+
+// Sync flushes the files at paths, then their directories, to stable storage. It does nothing
+// on file systems that do not implement Syncer.
+func Sync(fs Fs, paths ...string) error {
+	syncer, ok := fs.(Syncer)
+	if !ok {
+		return nil
+	}
+	dirs := make(map[string]struct{})
+	for _, path := range paths {
+		if err := syncer.Sync(path); err != nil {
+			return fmt.Errorf("sync %s: %w", path, err)
+		}
+		dirs[filepath.Dir(path)] = struct{}{}
+	}
+	for dir := range dirs {
+		if err := syncer.Sync(dir); err != nil {
+			return fmt.Errorf("sync %s: %w", dir, err)
+		}
+	}
+	return nil
+}
+
+// Stat returns the size, entity tag and modification time of path, ErrStatNotSupported on file systems that do not
+// implement Stater.
+func Stat(fs Fs, path string) (FileInfo, error) {
+	stater, ok := fs.(Stater)
+	if !ok {
+		return FileInfo{}, ErrStatNotSupported
+	}
+	return stater.Stat(path)
+}
+
+// Upload stores the local file at localPath at path of fs, streaming it from disk on file
+// systems that do not implement Uploader.
+func Upload(fs Fs, localPath string, path string) error {
+	if uploader, ok := fs.(Uploader); ok {
+		return uploader.Upload(localPath, path)
+	}
+	src, err := os.Open(localPath)
+	if err != nil {
+		return fmt.Errorf("upload %s: %w", localPath, file.FromOsError(err))
+	}
+	defer src.Close()
+	dst, err := fs.OpenFile(path)
+	if err != nil {
+		return fmt.Errorf("upload %s: %w", localPath, err)
+	}
+	if _, err = io.Copy(dst, src); err != nil {
+		dst.Close()
+		return fmt.Errorf("upload %s: %w", localPath, err)
+	}
+	if err = dst.Close(); err != nil {
+		return fmt.Errorf("upload %s: %w", localPath, err)
+	}
+	return nil
+}
+
+// FileSize returns the size of an existing file.
+func FileSize(fs Fs, path string) (int64, error) {
+	f, err := fs.OpenFile(path)
+	if err != nil {
+		return 0, fmt.Errorf("get size of %s: %w", path, err)
+	}
+	defer f.Close()
+	size, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, fmt.Errorf("get size of %s: %w", path, err)
+	}
+	return size, nil
+}
+
+// WriteFile creates or replaces the file at path with content.
+func WriteFile(fs Fs, path string, content []byte) error {
+	f, err := fs.OpenFile(path)
+	if err != nil {
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	if _, err = f.Write(content); err != nil {
+		f.Close()
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	if err = f.Close(); err != nil {
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package limiter
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Limiter bounds the request rate and the number of in-flight requests against a remote
+// storage backend. It is shared by all readers and writers of a process so that large
+// compactions cannot trigger throttling storms (e.g. S3 503 SlowDown).
+type Limiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	sem    chan struct{}
+}
+
+// New returns a limiter allowing requestsPerSecond requests per second with bursts of up to
+// burst requests, and at most maxConcurrent requests in flight. Zero disables a limit.
+func New(requestsPerSecond float64, burst int, maxConcurrent int) *Limiter {
+	l := &Limiter{}
+	l.SetLimits(requestsPerSecond, burst, maxConcurrent)
+	return l
+}
+
+// SetLimits changes the limits. Requests in flight are not affected.
+func (l *Limiter) SetLimits(requestsPerSecond float64, burst int, maxConcurrent int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.rate = requestsPerSecond
+	l.burst = math.Max(float64(burst), 1)
+	l.tokens = l.burst
+	l.last = time.Now()
+	if maxConcurrent > 0 {
+		l.sem = make(chan struct{}, maxConcurrent)
+	} else {
+		l.sem = nil
+	}
+}
+
+// Acquire blocks until a request may be issued and returns a function that must be called
+// when the request completes.
+func (l *Limiter) Acquire(ctx context.Context) (func(), error) {
+	l.mu.Lock()
+	sem := l.sem
+	l.mu.Unlock()
+
+	release := func() {}
+	if sem != nil {
+		select {
+		case sem <- struct{}{}:
+			release = func() { <-sem }
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	if wait := l.reserve(); wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			release()
+			return nil, ctx.Err()
+		}
+	}
+	return release, nil
+}
+
+// reserve takes a token and returns how long the caller has to wait for it.
+func (l *Limiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.rate <= 0 {
+		return 0
+	}
+	now := time.Now()
+	l.tokens = math.Min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+var defaultLimiter = New(0, 0, 0)
+
+// Default returns the process wide limiter used by remote fs backends, unlimited unless
+// configured with SetDefaultLimits.
+func Default() *Limiter {
+	return defaultLimiter
+}
+
+// SetDefaultLimits configures the process wide limiter used by remote fs backends.
+func SetDefaultLimits(requestsPerSecond float64, burst int, maxConcurrent int) {
+	defaultLimiter.SetLimits(requestsPerSecond, burst, maxConcurrent)
+}
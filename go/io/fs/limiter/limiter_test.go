@@ -0,0 +1,49 @@
+package limiter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimiterRate(t *testing.T) {
+	l := New(100, 1, 0)
+	start := time.Now()
+	for i := 0; i < 11; i++ {
+		release, err := l.Acquire(context.Background())
+		require.NoError(t, err)
+		release()
+	}
+	assert.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond)
+}
+
+func TestLimiterConcurrency(t *testing.T) {
+	l := New(0, 0, 2)
+	release1, err := l.Acquire(context.Background())
+	require.NoError(t, err)
+	release2, err := l.Acquire(context.Background())
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = l.Acquire(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	release1()
+	release3, err := l.Acquire(context.Background())
+	require.NoError(t, err)
+	release2()
+	release3()
+}
+
+func TestLimiterUnlimited(t *testing.T) {
+	l := New(0, 0, 0)
+	for i := 0; i < 1000; i++ {
+		release, err := l.Acquire(context.Background())
+		require.NoError(t, err)
+		release()
+	}
+}
@@ -1,8 +1,13 @@
 package fs
 
 import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/milvus-io/milvus-storage/go/common/log"
 	"github.com/milvus-io/milvus-storage/go/io/fs/file"
@@ -16,22 +21,58 @@ func (l *LocalFS) OpenFile(path string) (file.File, error) {
 	// Create the directory (including all necessary parent directories)
 	err := os.MkdirAll(dir, os.ModePerm)
 	if err != nil {
-		return nil, err
+		return nil, file.FromOsError(err)
 	}
 	open, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0666)
 	if err != nil {
-		return nil, err
+		return nil, file.FromOsError(err)
 	}
 	return file.NewLocalFile(open), nil
 }
 
 // Rename renames (moves) a file. If newpath already exists and is not a directory, Rename replaces it.
 func (l *LocalFS) Rename(src string, dst string) error {
-	return os.Rename(src, dst)
+	return file.FromOsError(os.Rename(src, dst))
+}
+
+// Copy copies src to dst, io.Copy uses copy_file_range where the kernel supports it so the
+// data stays in the kernel.
+func (l *LocalFS) Copy(src string, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return file.FromOsError(err)
+	}
+	defer in.Close()
+	if err = os.MkdirAll(filepath.Dir(dst), os.ModePerm); err != nil {
+		return file.FromOsError(err)
+	}
+	out, err := os.Create(dst)
+	if err != nil {
+		return file.FromOsError(err)
+	}
+	if _, err = io.Copy(out, in); err != nil {
+		out.Close()
+		return file.FromOsError(err)
+	}
+	return file.FromOsError(out.Close())
+}
+
+// Sync fsyncs the file or directory at path, fsync applies to the file and not to the
+// descriptor it is called on, so the writes of a closed descriptor are flushed too.
+func (l *LocalFS) Sync(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return file.FromOsError(err)
+	}
+	if err = f.Sync(); err != nil {
+		f.Close()
+		return file.FromOsError(err)
+	}
+	return file.FromOsError(f.Close())
 }
 
 func (l *LocalFS) DeleteFile(path string) error {
-	return os.Remove(path)
+	return file.FromOsError(os.Remove(path))
 }
 
 func (l *LocalFS) CreateDir(path string) error {
@@ -46,23 +87,74 @@ func (l *LocalFS) List(path string) ([]FileEntry, error) {
 	entries, err := os.ReadDir(path)
 	if err != nil {
 		log.Error(err.Error())
-		return nil, err
+		return nil, file.FromOsError(err)
 	}
 
 	ret := make([]FileEntry, 0, len(entries))
 	for _, entry := range entries {
-		ret = append(ret, FileEntry{Path: filepath.Join(path, entry.Name())})
+		ret = append(ret, FileEntry{Path: filepath.Join(path, entry.Name()), IsDir: entry.IsDir()})
 	}
 
 	return ret, nil
 }
 
 func (l *LocalFS) ReadFile(path string) ([]byte, error) {
-	return os.ReadFile(path)
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, file.FromOsError(err)
+	}
+	return buf, nil
 }
 
 func (l *LocalFS) Exist(path string) (bool, error) {
-	panic("not implemented")
+	_, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, file.FromOsError(err)
+	}
+	return true, nil
+}
+
+// Stat uses the modification time and size as the entity tag, files are replaced by renaming
+// so a rewritten file has a new modification time.
+func (l *LocalFS) Stat(path string) (FileInfo, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return FileInfo{}, file.FromOsError(err)
+	}
+	return FileInfo{
+		Size:    info.Size(),
+		ETag:    fmt.Sprintf("%x-%x", info.ModTime().UnixNano(), info.Size()),
+		ModTime: info.ModTime(),
+	}, nil
+}
+
+// Upload copies the local file at localPath to path.
+func (l *LocalFS) Upload(localPath string, path string) error {
+	return l.Copy(localPath, path)
+}
+
+// Ping does nothing, local disks are always reachable.
+func (l *LocalFS) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (l *LocalFS) Close() error {
+	return nil
+}
+
+// SignURL returns the file URL of path, local files are not served so it does not expire.
+func (l *LocalFS) SignURL(path string, ttl time.Duration) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	if _, err = os.Stat(abs); err != nil {
+		return "", file.FromOsError(err)
+	}
+	return (&url.URL{Scheme: "file", Path: abs}).String(), nil
 }
 
 func NewLocalFs() *LocalFS {
@@ -0,0 +1,45 @@
+package fs_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/milvus-io/milvus-storage/go/common/errors"
+	"github.com/milvus-io/milvus-storage/go/io/fs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalFsCopy(t *testing.T) {
+	dir := t.TempDir()
+	src, dst := filepath.Join(dir, "a"), filepath.Join(dir, "b", "c")
+	require.NoError(t, os.WriteFile(src, []byte{1, 2, 3}, 0666))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "d"), []byte{4}, 0666))
+
+	localFs := fs.NewLocalFs()
+	require.NoError(t, localFs.Copy(src, dst))
+	content, err := localFs.ReadFile(dst)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{1, 2, 3}, content)
+
+	// the destination is replaced
+	require.NoError(t, localFs.Copy(filepath.Join(dir, "d"), dst))
+	content, err = localFs.ReadFile(dst)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{4}, content)
+
+	assert.True(t, errors.Is(localFs.Copy(filepath.Join(dir, "missing"), dst), errors.ErrNotFound))
+}
+
+func TestLocalFsSync(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a")
+	localFs := fs.NewLocalFs()
+	require.NoError(t, fs.WriteFile(localFs, path, []byte{1, 2, 3}))
+	require.NoError(t, fs.Sync(localFs, path))
+	assert.True(t, errors.Is(fs.Sync(localFs, filepath.Join(dir, "missing")), errors.ErrNotFound))
+
+	// file systems without buffered writes have nothing to flush
+	assert.NoError(t, fs.Sync(fs.NewMemoryFs(), "missing"))
+}
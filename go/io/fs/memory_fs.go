@@ -1,6 +1,11 @@
 package fs
 
 import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/milvus-io/milvus-storage/go/common/errors"
 	"github.com/milvus-io/milvus-storage/go/io/fs/file"
 )
 
@@ -31,6 +36,15 @@ func (m *MemoryFs) Rename(path string, path2 string) error {
 	return nil
 }
 
+func (m *MemoryFs) Copy(src string, dst string) error {
+	f, ok := m.files[src]
+	if !ok {
+		return fmt.Errorf("copy file %s: %w", src, errors.ErrNotFound)
+	}
+	m.files[dst] = file.NewMemoryFile(append([]byte(nil), f.Bytes()...))
+	return nil
+}
+
 func (m *MemoryFs) DeleteFile(path string) error {
 	delete(m.files, path)
 	return nil
@@ -41,11 +55,28 @@ func (m *MemoryFs) CreateDir(path string) error {
 }
 
 func (m *MemoryFs) ReadFile(path string) ([]byte, error) {
-	panic("implement me")
+	f, ok := m.files[path]
+	if !ok {
+		return nil, fmt.Errorf("read file %s: %w", path, errors.ErrNotFound)
+	}
+	return append([]byte(nil), f.Bytes()...), nil
 }
 
 func (m *MemoryFs) Exist(path string) (bool, error) {
-	panic("not implemented")
+	_, ok := m.files[path]
+	return ok, nil
+}
+
+func (m *MemoryFs) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (m *MemoryFs) Close() error {
+	return nil
+}
+
+func (m *MemoryFs) SignURL(path string, ttl time.Duration) (string, error) {
+	return "", fmt.Errorf("sign url of %s: %w", path, ErrSignURLNotSupported)
 }
 
 func NewMemoryFs() *MemoryFs {
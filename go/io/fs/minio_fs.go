@@ -3,11 +3,15 @@ package fs
 import (
 	"context"
 	"fmt"
-	"io"
+	"net/http"
 	"net/url"
+	"strings"
+	"time"
 
+	"github.com/milvus-io/milvus-storage/go/common/errors"
 	"github.com/milvus-io/milvus-storage/go/common/log"
 	"github.com/milvus-io/milvus-storage/go/io/fs/file"
+	"github.com/milvus-io/milvus-storage/go/io/fs/limiter"
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
 	"go.uber.org/zap"
@@ -15,27 +19,57 @@ import (
 
 type MinioFs struct {
 	client     *minio.Client
+	transport  *http.Transport
 	bucketName string
+	limiter    *limiter.Limiter
+	download   file.DownloadOptions
+	objects    file.ObjectOptions
+}
+
+// SetDownloadOptions changes how large files are downloaded, files already open are not
+// affected.
+func (fs *MinioFs) SetDownloadOptions(options file.DownloadOptions) {
+	fs.download = options
+}
+
+// SetObjectOptions changes the options applied to the objects written and the requests sent,
+// files already open are not affected.
+func (fs *MinioFs) SetObjectOptions(options file.ObjectOptions) {
+	fs.objects = options
 }
 
 func (fs *MinioFs) OpenFile(path string) (file.File, error) {
-	return file.NewMinioFile(fs.client, path, fs.bucketName)
+	return file.NewMinioFile(fs.client, path, fs.bucketName, fs.limiter, fs.download, fs.objects)
 }
 
 func (fs *MinioFs) Rename(src string, dst string) error {
-	_, err := fs.client.CopyObject(context.TODO(), minio.CopyDestOptions{Bucket: fs.bucketName, Object: dst}, minio.CopySrcOptions{Bucket: fs.bucketName, Object: src})
-	if err != nil {
+	if err := fs.Copy(src, dst); err != nil {
 		return err
 	}
-	err = fs.client.RemoveObject(context.TODO(), fs.bucketName, src, minio.RemoveObjectOptions{})
-	if err != nil {
+	if err := fs.DeleteFile(src); err != nil {
 		log.Warn("failed to remove source object", log.String("source", src))
 	}
 	return nil
 }
 
+// Copy copies the object within the bucket with a server-side copy.
+func (fs *MinioFs) Copy(src string, dst string) error {
+	release, err := fs.limiter.Acquire(context.TODO())
+	if err != nil {
+		return err
+	}
+	defer release()
+	_, err = fs.client.CopyObject(context.TODO(), minio.CopyDestOptions{Bucket: fs.bucketName, Object: dst}, minio.CopySrcOptions{Bucket: fs.bucketName, Object: src})
+	return file.FromMinioError(err)
+}
+
 func (fs *MinioFs) DeleteFile(path string) error {
-	return fs.client.RemoveObject(context.TODO(), fs.bucketName, path, minio.RemoveObjectOptions{})
+	release, err := fs.limiter.Acquire(context.TODO())
+	if err != nil {
+		return err
+	}
+	defer release()
+	return file.FromMinioError(fs.client.RemoveObject(context.TODO(), fs.bucketName, path, minio.RemoveObjectOptions{}))
 }
 
 func (fs *MinioFs) CreateDir(path string) error {
@@ -43,61 +77,139 @@ func (fs *MinioFs) CreateDir(path string) error {
 }
 
 func (fs *MinioFs) List(path string) ([]FileEntry, error) {
+	release, err := fs.limiter.Acquire(context.TODO())
+	if err != nil {
+		return nil, err
+	}
+	defer release()
 	ret := make([]FileEntry, 0)
-	for objInfo := range fs.client.ListObjects(context.TODO(), fs.bucketName, minio.ListObjectsOptions{Prefix: path, Recursive: false}) {
+	options := minio.ListObjectsOptions{Prefix: path, Recursive: false}
+	for key, value := range fs.objects.Headers() {
+		options.Set(key, value)
+	}
+	for objInfo := range fs.client.ListObjects(context.TODO(), fs.bucketName, options) {
 		if objInfo.Err != nil {
 			log.Warn("list object error", zap.Error(objInfo.Err))
-			return nil, objInfo.Err
+			return nil, file.FromMinioError(objInfo.Err)
 		}
-		ret = append(ret, FileEntry{Path: objInfo.Key})
+		ret = append(ret, FileEntry{Path: objInfo.Key, IsDir: strings.HasSuffix(objInfo.Key, "/")})
 	}
 	return ret, nil
 }
 
+// ReadFile downloads files larger than a part with parallel ranged GETs.
 func (fs *MinioFs) ReadFile(path string) ([]byte, error) {
-	obj, err := fs.client.GetObject(context.TODO(), fs.bucketName, path, minio.GetObjectOptions{})
+	release, err := fs.limiter.Acquire(context.TODO())
 	if err != nil {
 		return nil, err
 	}
-
-	stat, err := obj.Stat()
+	stat, err := fs.client.StatObject(context.TODO(), fs.bucketName, path, fs.objects.GetOptions())
+	release()
 	if err != nil {
-		return nil, err
+		return nil, file.FromMinioError(err)
 	}
 
-	buf := make([]byte, stat.Size)
-	n, err := obj.Read(buf)
-	if err != nil && err != io.EOF {
+	bufs, err := file.DownloadRanges(fs.client, fs.bucketName, path, stat.Size, stat.ETag,
+		[]file.Range{{Offset: 0, Length: stat.Size}}, fs.download, fs.objects, fs.limiter)
+	if err != nil {
 		return nil, err
 	}
-	if n != int(stat.Size) {
-		return nil, fmt.Errorf("failed to read full file, expect: %d, actual: %d", stat.Size, n)
+	if int64(len(bufs[0])) != stat.Size {
+		return nil, fmt.Errorf("failed to read full file, expect: %d, actual: %d", stat.Size, len(bufs[0]))
 	}
-	return buf, nil
+	return bufs[0], nil
 }
 
 func (fs *MinioFs) Exist(path string) (bool, error) {
-	_, err := fs.client.StatObject(context.TODO(), fs.bucketName, path, minio.StatObjectOptions{})
+	release, err := fs.limiter.Acquire(context.TODO())
+	if err != nil {
+		return false, err
+	}
+	defer release()
+	_, err = fs.client.StatObject(context.TODO(), fs.bucketName, path, fs.objects.GetOptions())
 	if err != nil {
 		resp := minio.ToErrorResponse(err)
 		if resp.Code == "NoSuchKey" {
 			return false, nil
 		}
-		return false, err
+		return false, file.FromMinioError(err)
 	}
 	return true, nil
 }
 
+func (fs *MinioFs) Stat(path string) (FileInfo, error) {
+	release, err := fs.limiter.Acquire(context.TODO())
+	if err != nil {
+		return FileInfo{}, err
+	}
+	defer release()
+	stat, err := fs.client.StatObject(context.TODO(), fs.bucketName, path, fs.objects.GetOptions())
+	if err != nil {
+		return FileInfo{}, file.FromMinioError(err)
+	}
+	return FileInfo{Size: stat.Size, ETag: stat.ETag, ModTime: stat.LastModified}, nil
+}
+
+// SignURL returns a presigned GET URL of path, ttl must be between a second and seven days.
+func (fs *MinioFs) SignURL(path string, ttl time.Duration) (string, error) {
+	u, err := fs.client.PresignedGetObject(context.TODO(), fs.bucketName, path, ttl, nil)
+	if err != nil {
+		return "", file.FromMinioError(err)
+	}
+	return u.String(), nil
+}
+
+// Upload streams the local file at localPath to path, large files are uploaded in parts.
+func (fs *MinioFs) Upload(localPath string, path string) error {
+	release, err := fs.limiter.Acquire(context.TODO())
+	if err != nil {
+		return err
+	}
+	defer release()
+	_, err = fs.client.FPutObject(context.TODO(), fs.bucketName, path, localPath, fs.objects.PutOptions())
+	return file.FromMinioError(err)
+}
+
+// Ping checks that the bucket exists and that the credentials may access it.
+func (fs *MinioFs) Ping(ctx context.Context) error {
+	release, err := fs.limiter.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+	exist, err := fs.client.BucketExists(ctx, fs.bucketName)
+	if err != nil {
+		return file.FromMinioError(err)
+	}
+	if !exist {
+		return errors.NewWithKind(errors.ErrNotFound, fmt.Sprintf("bucket %s not exist", fs.bucketName))
+	}
+	return nil
+}
+
+// Close closes the idle connections to the endpoint.
+func (fs *MinioFs) Close() error {
+	fs.transport.CloseIdleConnections()
+	return nil
+}
+
 // uri should be s3://accessKey:secretAceessKey@endpoint/bucket/
+// Requests are bounded by the process wide limiter, see limiter.SetDefaultLimits. Large files
+// are downloaded in parallel parts, see SetDownloadOptions.
 func NewMinioFs(uri *url.URL) (*MinioFs, error) {
 	accessKey := uri.User.Username()
 	secretAccessKey, set := uri.User.Password()
 	if !set {
 		log.Warn("secret access key not set")
 	}
+	transport, err := minio.DefaultTransport(false)
+	if err != nil {
+		return nil, err
+	}
 	cli, err := minio.New(uri.Host, &minio.Options{
 		BucketLookup: minio.BucketLookupAuto,
 		Creds:        credentials.NewStaticV4(accessKey, secretAccessKey, ""),
+		Transport:    transport,
 	})
 	if err != nil {
 		return nil, err
@@ -120,6 +232,9 @@ func NewMinioFs(uri *url.URL) (*MinioFs, error) {
 
 	return &MinioFs{
 		client:     cli,
+		transport:  transport,
 		bucketName: bucket,
+		limiter:    limiter.Default(),
+		download:   file.DefaultDownloadOptions,
 	}, nil
 }
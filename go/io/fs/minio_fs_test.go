@@ -1,11 +1,16 @@
 package fs_test
 
 import (
+	"context"
 	"io"
+	"net/http"
 	"testing"
+	"time"
 
 	"github.com/milvus-io/milvus-storage/go/io/fs"
+	"github.com/milvus-io/milvus-storage/go/io/fs/file"
 	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -58,6 +63,39 @@ func (suite *MinioFsTestSuite) TestMinioRename() {
 	suite.ElementsMatch(buf[:n], []byte{1})
 }
 
+func (suite *MinioFsTestSuite) TestMinioFsCopy() {
+	f, err := suite.fs.OpenFile("a")
+	suite.NoError(err)
+	_, err = f.Write([]byte{1})
+	suite.NoError(err)
+	suite.NoError(f.Close())
+
+	suite.NoError(suite.fs.Copy("a", "b"))
+	for _, path := range []string{"a", "b"} {
+		content, err := suite.fs.ReadFile(path)
+		suite.NoError(err)
+		suite.EqualValues([]byte{1}, content)
+	}
+}
+
+func (suite *MinioFsTestSuite) TestMinioFsSignURL() {
+	f, err := suite.fs.OpenFile("a")
+	suite.NoError(err)
+	_, err = f.Write([]byte{1})
+	suite.NoError(err)
+	suite.NoError(f.Close())
+
+	signed, err := suite.fs.SignURL("a", time.Minute)
+	suite.NoError(err)
+	resp, err := http.Get(signed)
+	suite.Require().NoError(err)
+	defer resp.Body.Close()
+	content, err := io.ReadAll(resp.Body)
+	suite.NoError(err)
+	suite.Equal(http.StatusOK, resp.StatusCode)
+	suite.Equal([]byte{1}, content)
+}
+
 func (suite *MinioFsTestSuite) TestMinioFsDeleteFile() {
 	file, err := suite.fs.OpenFile("a")
 	suite.NoError(err)
@@ -104,6 +142,33 @@ func (suite *MinioFsTestSuite) TestMinioFsReadFile() {
 	suite.EqualValues([]byte{1}, content)
 }
 
+func (suite *MinioFsTestSuite) TestMinioFsParallelDownload() {
+	suite.fs.(*fs.MinioFs).SetDownloadOptions(file.DownloadOptions{PartSize: 3, Parallelism: 2})
+	defer suite.fs.(*fs.MinioFs).SetDownloadOptions(file.DefaultDownloadOptions)
+
+	content := []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	f, err := suite.fs.OpenFile("a")
+	suite.NoError(err)
+	_, err = f.Write(content)
+	suite.NoError(err)
+	suite.NoError(f.Close())
+
+	read, err := suite.fs.ReadFile("a")
+	suite.NoError(err)
+	suite.Equal(content, read)
+
+	f, err = suite.fs.OpenFile("a")
+	suite.NoError(err)
+	buf := make([]byte, 8)
+	n, err := f.ReadAt(buf, 4)
+	suite.Equal(io.EOF, err)
+	suite.Equal(content[4:], buf[:n])
+
+	bufs, err := f.(file.RangeReader).ReadRanges([]file.Range{{Offset: 0, Length: 4}, {Offset: 8, Length: 4}})
+	suite.NoError(err)
+	suite.Equal([][]byte{content[:4], content[8:]}, bufs)
+}
+
 func (suite *MinioFsTestSuite) TestMinioFsExist() {
 	exist, err := suite.fs.Exist("nonexist")
 	suite.NoError(err)
@@ -121,6 +186,28 @@ func (suite *MinioFsTestSuite) TestMinioFsExist() {
 	suite.True(exist)
 }
 
+func (suite *MinioFsTestSuite) TestMinioFsObjectOptions() {
+	suite.fs.(*fs.MinioFs).SetObjectOptions(file.ObjectOptions{
+		Tags:     map[string]string{"team": "search"},
+		Metadata: map[string]string{"Space": "collection"},
+	})
+	defer suite.fs.(*fs.MinioFs).SetObjectOptions(file.ObjectOptions{})
+	suite.NoError(fs.WriteFile(suite.fs, "tagged", []byte{1}))
+
+	client, err := minio.New("localhost:9000", &minio.Options{Creds: credentials.NewStaticV4("minioadmin", "minioadmin", "")})
+	suite.Require().NoError(err)
+	info, err := client.StatObject(context.TODO(), "default", "tagged", minio.StatObjectOptions{})
+	suite.Require().NoError(err)
+	suite.Equal("collection", info.UserMetadata["Space"])
+	tags, err := client.GetObjectTagging(context.TODO(), "default", "tagged", minio.GetObjectTaggingOptions{})
+	suite.Require().NoError(err)
+	suite.Equal(map[string]string{"team": "search"}, tags.ToMap())
+
+	// reads send the headers of the requester pays buckets
+	suite.Equal(map[string]string{"x-amz-request-payer": "requester", "x-amz-expected-bucket-owner": "123456789012"},
+		file.ObjectOptions{RequesterPays: true, ExpectedBucketOwner: "123456789012"}.Headers())
+}
+
 func TestMinioFsSuite(t *testing.T) {
 	suite.Run(t, &MinioFsTestSuite{})
 }
@@ -0,0 +1,201 @@
+package fs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/milvus-io/milvus-storage/go/common/log"
+	"github.com/milvus-io/milvus-storage/go/io/fs/file"
+	"github.com/milvus-io/milvus-storage/go/storage/options/option"
+)
+
+var ErrOperationTimeout = fmt.Errorf("operation timed out: %w", context.DeadlineExceeded)
+
+// retryPolicy retries the calls of an operation, see option.RetryOptions.
+type retryPolicy struct {
+	options  option.RetryOptions
+	deadline time.Time
+}
+
+// call sends fn until it succeeds, fails with an answer of the backend, or the attempts or
+// the time of the operation are exhausted.
+func (p *retryPolicy) call(fn func() error) error {
+	backoff := p.options.Backoff
+	for attempt := 1; ; attempt++ {
+		if err := p.check(); err != nil {
+			return err
+		}
+		err := fn()
+		if !isFailure(err) || attempt >= p.options.MaxAttempts {
+			return err
+		}
+		if !p.deadline.IsZero() && time.Now().Add(backoff).After(p.deadline) {
+			return err
+		}
+		log.Debug("retry fs call", log.Int("attempt", attempt), log.String("err", err.Error()))
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// check fails once the time of the operation is exhausted.
+func (p *retryPolicy) check() error {
+	if !p.deadline.IsZero() && time.Now().After(p.deadline) {
+		return ErrOperationTimeout
+	}
+	return nil
+}
+
+// retryFs sends the calls to another file system, and the reads of the files it opens,
+// through a retry policy.
+type retryFs struct {
+	fs     Fs
+	policy *retryPolicy
+}
+
+// NewRetryFs returns f retrying the calls failing with an error of the backend, see
+// option.RetryOptions. The timeout starts when it is called, it is meant to be built for a
+// single operation.
+func NewRetryFs(f Fs, options *option.RetryOptions) Fs {
+	policy := &retryPolicy{options: *options}
+	if options.Timeout > 0 {
+		policy.deadline = time.Now().Add(options.Timeout)
+	}
+	return &retryFs{fs: f, policy: policy}
+}
+
+func (r *retryFs) OpenFile(path string) (file.File, error) {
+	var f file.File
+	err := r.policy.call(func() (err error) {
+		f, err = r.fs.OpenFile(path)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &retryFile{File: f, policy: r.policy}, nil
+}
+
+func (r *retryFs) Rename(src string, dst string) error {
+	return r.policy.call(func() error { return r.fs.Rename(src, dst) })
+}
+
+func (r *retryFs) Copy(src string, dst string) error {
+	return r.policy.call(func() error { return r.fs.Copy(src, dst) })
+}
+
+func (r *retryFs) DeleteFile(path string) error {
+	return r.policy.call(func() error { return r.fs.DeleteFile(path) })
+}
+
+func (r *retryFs) CreateDir(path string) error {
+	return r.policy.call(func() error { return r.fs.CreateDir(path) })
+}
+
+func (r *retryFs) List(path string) (entries []FileEntry, err error) {
+	err = r.policy.call(func() error {
+		entries, err = r.fs.List(path)
+		return err
+	})
+	return entries, err
+}
+
+func (r *retryFs) ReadFile(path string) (content []byte, err error) {
+	err = r.policy.call(func() error {
+		content, err = r.fs.ReadFile(path)
+		return err
+	})
+	return content, err
+}
+
+func (r *retryFs) Exist(path string) (exist bool, err error) {
+	err = r.policy.call(func() error {
+		exist, err = r.fs.Exist(path)
+		return err
+	})
+	return exist, err
+}
+
+func (r *retryFs) Stat(path string) (info FileInfo, err error) {
+	stater, ok := r.fs.(Stater)
+	if !ok {
+		return FileInfo{}, ErrStatNotSupported
+	}
+	err = r.policy.call(func() error {
+		info, err = stater.Stat(path)
+		return err
+	})
+	return info, err
+}
+
+// SignURL does not contact the backend, it is not retried.
+func (r *retryFs) SignURL(path string, ttl time.Duration) (string, error) {
+	return r.fs.SignURL(path, ttl)
+}
+
+func (r *retryFs) Upload(localPath string, path string) error {
+	return r.policy.call(func() error { return Upload(r.fs, localPath, path) })
+}
+
+func (r *retryFs) Ping(ctx context.Context) error {
+	return r.policy.call(func() error { return r.fs.Ping(ctx) })
+}
+
+// Close does not contact the backend, it is not retried.
+func (r *retryFs) Close() error {
+	return r.fs.Close()
+}
+
+// Sync syncs path if the wrapped file system buffers writes.
+func (r *retryFs) Sync(path string) error {
+	syncer, ok := r.fs.(Syncer)
+	if !ok {
+		return nil
+	}
+	return r.policy.call(func() error { return syncer.Sync(path) })
+}
+
+// retryFile retries the reads at an offset, which can be sent again. Sequential reads and
+// writes move the position of the file and are only checked against the timeout.
+type retryFile struct {
+	file.File
+	policy *retryPolicy
+}
+
+var _ file.RangeReader = (*retryFile)(nil)
+
+func (f *retryFile) Read(p []byte) (int, error) {
+	if err := f.policy.check(); err != nil {
+		return 0, err
+	}
+	return f.File.Read(p)
+}
+
+func (f *retryFile) ReadAt(p []byte, off int64) (n int, err error) {
+	err = f.policy.call(func() error {
+		n, err = f.File.ReadAt(p, off)
+		return err
+	})
+	return n, err
+}
+
+// ReadRanges reads the ranges at once if the wrapped file can, one by one otherwise.
+func (f *retryFile) ReadRanges(ranges []file.Range) (bufs [][]byte, err error) {
+	err = f.policy.call(func() error {
+		if reader, ok := f.File.(file.RangeReader); ok {
+			bufs, err = reader.ReadRanges(ranges)
+			return err
+		}
+		bufs, err = readRanges(f.File, ranges)
+		return err
+	})
+	return bufs, err
+}
+
+func (f *retryFile) Write(p []byte) (int, error) {
+	if err := f.policy.check(); err != nil {
+		return 0, err
+	}
+	return f.File.Write(p)
+}
@@ -0,0 +1,46 @@
+package fs_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/milvus-io/milvus-storage/go/common/errors"
+	"github.com/milvus-io/milvus-storage/go/io/fs"
+	"github.com/milvus-io/milvus-storage/go/storage/options/option"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryFs(t *testing.T) {
+	memoryFs := fs.NewMemoryFs()
+	require.NoError(t, fs.WriteFile(memoryFs, "a", []byte{1}))
+	flaky := &flakyFs{Fs: memoryFs, err: errors.New("connection reset")}
+	retryFs := fs.NewRetryFs(flaky, &option.RetryOptions{MaxAttempts: 3, Backoff: time.Millisecond})
+
+	_, err := retryFs.ReadFile("a")
+	assert.EqualError(t, err, "connection reset")
+	assert.Equal(t, 3, flaky.calls)
+
+	// answers of the backend are not retried
+	flaky.calls, flaky.err = 0, errors.WithKind(errors.ErrNotFound, errors.New("no such file"))
+	_, err = retryFs.ReadFile("a")
+	assert.True(t, errors.Is(err, errors.ErrNotFound))
+	assert.Equal(t, 1, flaky.calls)
+
+	flaky.calls, flaky.err = 0, nil
+	content, err := retryFs.ReadFile("a")
+	require.NoError(t, err)
+	assert.Equal(t, []byte{1}, content)
+
+	// no call is sent once the operation timed out, retries do not outlast it
+	flaky.calls, flaky.err = 0, errors.New("connection reset")
+	retryFs = fs.NewRetryFs(flaky, &option.RetryOptions{Timeout: 30 * time.Millisecond, MaxAttempts: 10, Backoff: 20 * time.Millisecond})
+	_, err = retryFs.ReadFile("a")
+	assert.EqualError(t, err, "connection reset")
+	assert.Equal(t, 2, flaky.calls)
+	time.Sleep(30 * time.Millisecond)
+	_, err = retryFs.ReadFile("a")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 2, flaky.calls)
+}
@@ -0,0 +1,135 @@
+package fs
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/milvus-io/milvus-storage/go/common/errors"
+	"github.com/milvus-io/milvus-storage/go/io/fs/file"
+)
+
+var ErrPathOutsideRoot = errors.NewWithKind(errors.ErrPermissionDenied, "path outside root")
+
+// InRoot reports whether path is root or below it, once ".." elements are resolved.
+func InRoot(root string, path string) bool {
+	root, path = filepath.Clean(root), filepath.Clean(path)
+	return path == root || strings.HasPrefix(path, root+string(filepath.Separator))
+}
+
+// rootedFs fails the calls to another file system with paths outside root.
+type rootedFs struct {
+	fs   Fs
+	root string
+}
+
+// NewRootedFs returns f failing every call with a path outside root with ErrPathOutsideRoot
+// without sending it, e.g. so that a space never touches the files of another tenant even if
+// its manifest is corrupted. The local files uploaded by Upload are not checked.
+func NewRootedFs(f Fs, root string) Fs {
+	return &rootedFs{fs: f, root: root}
+}
+
+func (r *rootedFs) check(paths ...string) error {
+	for _, path := range paths {
+		if !InRoot(r.root, path) {
+			return fmt.Errorf("access %s: %w", path, ErrPathOutsideRoot)
+		}
+	}
+	return nil
+}
+
+func (r *rootedFs) OpenFile(path string) (file.File, error) {
+	if err := r.check(path); err != nil {
+		return nil, err
+	}
+	return r.fs.OpenFile(path)
+}
+
+func (r *rootedFs) Rename(src string, dst string) error {
+	if err := r.check(src, dst); err != nil {
+		return err
+	}
+	return r.fs.Rename(src, dst)
+}
+
+func (r *rootedFs) Copy(src string, dst string) error {
+	if err := r.check(src, dst); err != nil {
+		return err
+	}
+	return r.fs.Copy(src, dst)
+}
+
+func (r *rootedFs) DeleteFile(path string) error {
+	if err := r.check(path); err != nil {
+		return err
+	}
+	return r.fs.DeleteFile(path)
+}
+
+func (r *rootedFs) CreateDir(path string) error {
+	if err := r.check(path); err != nil {
+		return err
+	}
+	return r.fs.CreateDir(path)
+}
+
+func (r *rootedFs) List(path string) ([]FileEntry, error) {
+	if err := r.check(path); err != nil {
+		return nil, err
+	}
+	return r.fs.List(path)
+}
+
+func (r *rootedFs) ReadFile(path string) ([]byte, error) {
+	if err := r.check(path); err != nil {
+		return nil, err
+	}
+	return r.fs.ReadFile(path)
+}
+
+func (r *rootedFs) Exist(path string) (bool, error) {
+	if err := r.check(path); err != nil {
+		return false, err
+	}
+	return r.fs.Exist(path)
+}
+
+func (r *rootedFs) Stat(path string) (FileInfo, error) {
+	if err := r.check(path); err != nil {
+		return FileInfo{}, err
+	}
+	return Stat(r.fs, path)
+}
+
+func (r *rootedFs) SignURL(path string, ttl time.Duration) (string, error) {
+	if err := r.check(path); err != nil {
+		return "", err
+	}
+	return r.fs.SignURL(path, ttl)
+}
+
+func (r *rootedFs) Upload(localPath string, path string) error {
+	if err := r.check(path); err != nil {
+		return err
+	}
+	return Upload(r.fs, localPath, path)
+}
+
+func (r *rootedFs) Ping(ctx context.Context) error {
+	return r.fs.Ping(ctx)
+}
+
+func (r *rootedFs) Close() error {
+	return r.fs.Close()
+}
+
+// Sync syncs path if the wrapped file system buffers writes.
+func (r *rootedFs) Sync(path string) error {
+	if err := r.check(path); err != nil {
+		return err
+	}
+	return Sync(r.fs, path)
+}
@@ -0,0 +1,36 @@
+package fs_test
+
+import (
+	"testing"
+
+	"github.com/milvus-io/milvus-storage/go/common/errors"
+	"github.com/milvus-io/milvus-storage/go/io/fs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRootedFs(t *testing.T) {
+	memoryFs := fs.NewMemoryFs()
+	require.NoError(t, fs.WriteFile(memoryFs, "tenant-b/secret", []byte{1}))
+	rootedFs := fs.NewRootedFs(memoryFs, "tenant-a")
+
+	require.NoError(t, fs.WriteFile(rootedFs, "tenant-a/data", []byte{2}))
+	content, err := rootedFs.ReadFile("tenant-a/data")
+	require.NoError(t, err)
+	assert.Equal(t, []byte{2}, content)
+
+	for _, path := range []string{"tenant-b/secret", "tenant-a/../tenant-b/secret", "tenant-ab/data", "/tenant-a/data"} {
+		_, err = rootedFs.ReadFile(path)
+		assert.ErrorIs(t, err, fs.ErrPathOutsideRoot, path)
+	}
+	assert.ErrorIs(t, rootedFs.Copy("tenant-b/secret", "tenant-a/copy"), fs.ErrPathOutsideRoot)
+	assert.ErrorIs(t, rootedFs.DeleteFile("tenant-b/secret"), fs.ErrPathOutsideRoot)
+	assert.True(t, errors.Is(rootedFs.DeleteFile("tenant-b/secret"), errors.ErrPermissionDenied))
+	exist, err := memoryFs.Exist("tenant-b/secret")
+	require.NoError(t, err)
+	assert.True(t, exist)
+
+	assert.True(t, fs.InRoot("a/b", "a/b"))
+	assert.True(t, fs.InRoot("a/b/", "a/b/c/../d"))
+	assert.False(t, fs.InRoot("a/b", "a/bc"))
+}
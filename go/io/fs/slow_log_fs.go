@@ -0,0 +1,112 @@
+package fs
+
+import (
+	"context"
+	"time"
+
+	"github.com/milvus-io/milvus-storage/go/common/log"
+	"github.com/milvus-io/milvus-storage/go/io/fs/file"
+	"go.uber.org/zap"
+)
+
+// slowLogFs logs the calls to another file system that take longer than threshold.
+type slowLogFs struct {
+	fs        Fs
+	threshold time.Duration
+}
+
+// NewSlowLogFs returns f logging every call that takes longer than threshold. The reads and
+// writes of the files it opens are not measured.
+func NewSlowLogFs(f Fs, threshold time.Duration) Fs {
+	return &slowLogFs{fs: f, threshold: threshold}
+}
+
+func (l *slowLogFs) logSlow(op string, start time.Time, err error, fields ...zap.Field) {
+	duration := time.Since(start)
+	if duration <= l.threshold {
+		return
+	}
+	fields = append(fields, log.String("op", op), log.Duration("duration", duration))
+	if err != nil {
+		fields = append(fields, log.String("err", err.Error()))
+	}
+	log.Warn("slow fs operation", fields...)
+}
+
+func (l *slowLogFs) OpenFile(path string) (f file.File, err error) {
+	defer func(start time.Time) { l.logSlow("open", start, err, log.String("path", path)) }(time.Now())
+	return l.fs.OpenFile(path)
+}
+
+func (l *slowLogFs) Rename(src string, dst string) (err error) {
+	defer func(start time.Time) { l.logSlow("rename", start, err, log.String("src", src), log.String("dst", dst)) }(time.Now())
+	return l.fs.Rename(src, dst)
+}
+
+func (l *slowLogFs) Copy(src string, dst string) (err error) {
+	defer func(start time.Time) { l.logSlow("copy", start, err, log.String("src", src), log.String("dst", dst)) }(time.Now())
+	return l.fs.Copy(src, dst)
+}
+
+func (l *slowLogFs) DeleteFile(path string) (err error) {
+	defer func(start time.Time) { l.logSlow("delete", start, err, log.String("path", path)) }(time.Now())
+	return l.fs.DeleteFile(path)
+}
+
+func (l *slowLogFs) CreateDir(path string) (err error) {
+	defer func(start time.Time) { l.logSlow("create_dir", start, err, log.String("path", path)) }(time.Now())
+	return l.fs.CreateDir(path)
+}
+
+func (l *slowLogFs) List(path string) (entries []FileEntry, err error) {
+	defer func(start time.Time) {
+		l.logSlow("list", start, err, log.String("path", path), log.Int("files", len(entries)))
+	}(time.Now())
+	return l.fs.List(path)
+}
+
+func (l *slowLogFs) ReadFile(path string) (content []byte, err error) {
+	defer func(start time.Time) {
+		l.logSlow("read", start, err, log.String("path", path), log.Int("bytes", len(content)))
+	}(time.Now())
+	return l.fs.ReadFile(path)
+}
+
+func (l *slowLogFs) Exist(path string) (exist bool, err error) {
+	defer func(start time.Time) { l.logSlow("exist", start, err, log.String("path", path)) }(time.Now())
+	return l.fs.Exist(path)
+}
+
+func (l *slowLogFs) SignURL(path string, ttl time.Duration) (url string, err error) {
+	defer func(start time.Time) { l.logSlow("sign_url", start, err, log.String("path", path)) }(time.Now())
+	return l.fs.SignURL(path, ttl)
+}
+
+func (l *slowLogFs) Stat(path string) (info FileInfo, err error) {
+	defer func(start time.Time) { l.logSlow("stat", start, err, log.String("path", path)) }(time.Now())
+	return Stat(l.fs, path)
+}
+
+func (l *slowLogFs) Upload(localPath string, path string) (err error) {
+	defer func(start time.Time) { l.logSlow("upload", start, err, log.String("path", path)) }(time.Now())
+	return Upload(l.fs, localPath, path)
+}
+
+func (l *slowLogFs) Ping(ctx context.Context) (err error) {
+	defer func(start time.Time) { l.logSlow("ping", start, err) }(time.Now())
+	return l.fs.Ping(ctx)
+}
+
+func (l *slowLogFs) Close() error {
+	return l.fs.Close()
+}
+
+// Sync syncs path if the wrapped file system buffers writes.
+func (l *slowLogFs) Sync(path string) (err error) {
+	syncer, ok := l.fs.(Syncer)
+	if !ok {
+		return nil
+	}
+	defer func(start time.Time) { l.logSlow("sync", start, err, log.String("path", path)) }(time.Now())
+	return syncer.Sync(path)
+}
@@ -0,0 +1,520 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.31.0
+// 	protoc        v3.21.9
+// source: filter.proto
+
+package filter_proto
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ComparisonType int32
+
+const (
+	ComparisonType_Equal              ComparisonType = 0
+	ComparisonType_NotEqual           ComparisonType = 1
+	ComparisonType_LessThan           ComparisonType = 2
+	ComparisonType_LessThanOrEqual    ComparisonType = 3
+	ComparisonType_GreaterThan        ComparisonType = 4
+	ComparisonType_GreaterThanOrEqual ComparisonType = 5
+)
+
+// Enum value maps for ComparisonType.
+var (
+	ComparisonType_name = map[int32]string{
+		0: "Equal",
+		1: "NotEqual",
+		2: "LessThan",
+		3: "LessThanOrEqual",
+		4: "GreaterThan",
+		5: "GreaterThanOrEqual",
+	}
+	ComparisonType_value = map[string]int32{
+		"Equal":              0,
+		"NotEqual":           1,
+		"LessThan":           2,
+		"LessThanOrEqual":    3,
+		"GreaterThan":        4,
+		"GreaterThanOrEqual": 5,
+	}
+)
+
+func (x ComparisonType) Enum() *ComparisonType {
+	p := new(ComparisonType)
+	*p = x
+	return p
+}
+
+func (x ComparisonType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ComparisonType) Descriptor() protoreflect.EnumDescriptor {
+	return file_filter_proto_enumTypes[0].Descriptor()
+}
+
+func (ComparisonType) Type() protoreflect.EnumType {
+	return &file_filter_proto_enumTypes[0]
+}
+
+func (x ComparisonType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ComparisonType.Descriptor instead.
+func (ComparisonType) EnumDescriptor() ([]byte, []int) {
+	return file_filter_proto_rawDescGZIP(), []int{0}
+}
+
+// Filter is a comparison of column with a constant, a check of whether column is null, or the
+// conjunction of filters on column.
+type Filter struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Column     string         `protobuf:"bytes,1,opt,name=column,proto3" json:"column,omitempty"`
+	Comparison ComparisonType `protobuf:"varint,2,opt,name=comparison,proto3,enum=filter_proto.ComparisonType" json:"comparison,omitempty"`
+	// Types that are assignable to Value:
+	//	*Filter_Int32Value
+	//	*Filter_Int64Value
+	//	*Filter_FloatValue
+	//	*Filter_DoubleValue
+	//	*Filter_BoolValue
+	//	*Filter_StringValue
+	//	*Filter_BytesValue
+	//	*Filter_Uint64Value
+	//	*Filter_TimestampValue
+	//	*Filter_DurationValue
+	//	*Filter_And
+	//	*Filter_IsNull
+	Value isFilter_Value `protobuf_oneof:"value"`
+}
+
+func (x *Filter) Reset() {
+	*x = Filter{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_filter_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Filter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Filter) ProtoMessage() {}
+
+func (x *Filter) ProtoReflect() protoreflect.Message {
+	mi := &file_filter_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Filter.ProtoReflect.Descriptor instead.
+func (*Filter) Descriptor() ([]byte, []int) {
+	return file_filter_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Filter) GetColumn() string {
+	if x != nil {
+		return x.Column
+	}
+	return ""
+}
+
+func (x *Filter) GetComparison() ComparisonType {
+	if x != nil {
+		return x.Comparison
+	}
+	return ComparisonType_Equal
+}
+
+func (m *Filter) GetValue() isFilter_Value {
+	if m != nil {
+		return m.Value
+	}
+	return nil
+}
+
+func (x *Filter) GetInt32Value() int32 {
+	if x, ok := x.GetValue().(*Filter_Int32Value); ok {
+		return x.Int32Value
+	}
+	return 0
+}
+
+func (x *Filter) GetInt64Value() int64 {
+	if x, ok := x.GetValue().(*Filter_Int64Value); ok {
+		return x.Int64Value
+	}
+	return 0
+}
+
+func (x *Filter) GetFloatValue() float32 {
+	if x, ok := x.GetValue().(*Filter_FloatValue); ok {
+		return x.FloatValue
+	}
+	return 0
+}
+
+func (x *Filter) GetDoubleValue() float64 {
+	if x, ok := x.GetValue().(*Filter_DoubleValue); ok {
+		return x.DoubleValue
+	}
+	return 0
+}
+
+func (x *Filter) GetBoolValue() bool {
+	if x, ok := x.GetValue().(*Filter_BoolValue); ok {
+		return x.BoolValue
+	}
+	return false
+}
+
+func (x *Filter) GetStringValue() string {
+	if x, ok := x.GetValue().(*Filter_StringValue); ok {
+		return x.StringValue
+	}
+	return ""
+}
+
+func (x *Filter) GetBytesValue() []byte {
+	if x, ok := x.GetValue().(*Filter_BytesValue); ok {
+		return x.BytesValue
+	}
+	return nil
+}
+
+func (x *Filter) GetUint64Value() uint64 {
+	if x, ok := x.GetValue().(*Filter_Uint64Value); ok {
+		return x.Uint64Value
+	}
+	return 0
+}
+
+func (x *Filter) GetTimestampValue() int64 {
+	if x, ok := x.GetValue().(*Filter_TimestampValue); ok {
+		return x.TimestampValue
+	}
+	return 0
+}
+
+func (x *Filter) GetDurationValue() int64 {
+	if x, ok := x.GetValue().(*Filter_DurationValue); ok {
+		return x.DurationValue
+	}
+	return 0
+}
+
+func (x *Filter) GetAnd() *Conjunction {
+	if x, ok := x.GetValue().(*Filter_And); ok {
+		return x.And
+	}
+	return nil
+}
+
+func (x *Filter) GetIsNull() bool {
+	if x, ok := x.GetValue().(*Filter_IsNull); ok {
+		return x.IsNull
+	}
+	return false
+}
+
+type isFilter_Value interface {
+	isFilter_Value()
+}
+
+type Filter_Int32Value struct {
+	Int32Value int32 `protobuf:"varint,3,opt,name=int32_value,json=int32Value,proto3,oneof"`
+}
+
+type Filter_Int64Value struct {
+	Int64Value int64 `protobuf:"varint,4,opt,name=int64_value,json=int64Value,proto3,oneof"`
+}
+
+type Filter_FloatValue struct {
+	FloatValue float32 `protobuf:"fixed32,5,opt,name=float_value,json=floatValue,proto3,oneof"`
+}
+
+type Filter_DoubleValue struct {
+	DoubleValue float64 `protobuf:"fixed64,6,opt,name=double_value,json=doubleValue,proto3,oneof"`
+}
+
+type Filter_BoolValue struct {
+	BoolValue bool `protobuf:"varint,7,opt,name=bool_value,json=boolValue,proto3,oneof"`
+}
+
+type Filter_StringValue struct {
+	StringValue string `protobuf:"bytes,8,opt,name=string_value,json=stringValue,proto3,oneof"`
+}
+
+type Filter_BytesValue struct {
+	BytesValue []byte `protobuf:"bytes,9,opt,name=bytes_value,json=bytesValue,proto3,oneof"`
+}
+
+type Filter_Uint64Value struct {
+	Uint64Value uint64 `protobuf:"varint,10,opt,name=uint64_value,json=uint64Value,proto3,oneof"`
+}
+
+type Filter_TimestampValue struct {
+	// time.Time as nanoseconds since the epoch
+	TimestampValue int64 `protobuf:"varint,11,opt,name=timestamp_value,json=timestampValue,proto3,oneof"`
+}
+
+type Filter_DurationValue struct {
+	// time.Duration in nanoseconds
+	DurationValue int64 `protobuf:"varint,12,opt,name=duration_value,json=durationValue,proto3,oneof"`
+}
+
+type Filter_And struct {
+	And *Conjunction `protobuf:"bytes,13,opt,name=and,proto3,oneof"`
+}
+
+type Filter_IsNull struct {
+	// keeps the rows whose column is null, or those whose column is not null if false
+	IsNull bool `protobuf:"varint,14,opt,name=is_null,json=isNull,proto3,oneof"`
+}
+
+func (*Filter_Int32Value) isFilter_Value() {}
+
+func (*Filter_Int64Value) isFilter_Value() {}
+
+func (*Filter_FloatValue) isFilter_Value() {}
+
+func (*Filter_DoubleValue) isFilter_Value() {}
+
+func (*Filter_BoolValue) isFilter_Value() {}
+
+func (*Filter_StringValue) isFilter_Value() {}
+
+func (*Filter_BytesValue) isFilter_Value() {}
+
+func (*Filter_Uint64Value) isFilter_Value() {}
+
+func (*Filter_TimestampValue) isFilter_Value() {}
+
+func (*Filter_DurationValue) isFilter_Value() {}
+
+func (*Filter_And) isFilter_Value() {}
+
+func (*Filter_IsNull) isFilter_Value() {}
+
+type Conjunction struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Filters []*Filter `protobuf:"bytes,1,rep,name=filters,proto3" json:"filters,omitempty"`
+}
+
+func (x *Conjunction) Reset() {
+	*x = Conjunction{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_filter_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Conjunction) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Conjunction) ProtoMessage() {}
+
+func (x *Conjunction) ProtoReflect() protoreflect.Message {
+	mi := &file_filter_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Conjunction.ProtoReflect.Descriptor instead.
+func (*Conjunction) Descriptor() ([]byte, []int) {
+	return file_filter_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Conjunction) GetFilters() []*Filter {
+	if x != nil {
+		return x.Filters
+	}
+	return nil
+}
+
+var File_filter_proto protoreflect.FileDescriptor
+
+var file_filter_proto_rawDesc = []byte{
+	0x0a, 0x0c, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0c,
+	0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x5f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xa1, 0x04, 0x0a,
+	0x06, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x12, 0x16, 0x0a, 0x06, 0x63, 0x6f, 0x6c, 0x75, 0x6d,
+	0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x12,
+	0x3c, 0x0a, 0x0a, 0x63, 0x6f, 0x6d, 0x70, 0x61, 0x72, 0x69, 0x73, 0x6f, 0x6e, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0e, 0x32, 0x1c, 0x2e, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x5f, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x2e, 0x43, 0x6f, 0x6d, 0x70, 0x61, 0x72, 0x69, 0x73, 0x6f, 0x6e, 0x54, 0x79, 0x70,
+	0x65, 0x52, 0x0a, 0x63, 0x6f, 0x6d, 0x70, 0x61, 0x72, 0x69, 0x73, 0x6f, 0x6e, 0x12, 0x21, 0x0a,
+	0x0b, 0x69, 0x6e, 0x74, 0x33, 0x32, 0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x05, 0x48, 0x00, 0x52, 0x0a, 0x69, 0x6e, 0x74, 0x33, 0x32, 0x56, 0x61, 0x6c, 0x75, 0x65,
+	0x12, 0x21, 0x0a, 0x0b, 0x69, 0x6e, 0x74, 0x36, 0x34, 0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x03, 0x48, 0x00, 0x52, 0x0a, 0x69, 0x6e, 0x74, 0x36, 0x34, 0x56, 0x61,
+	0x6c, 0x75, 0x65, 0x12, 0x21, 0x0a, 0x0b, 0x66, 0x6c, 0x6f, 0x61, 0x74, 0x5f, 0x76, 0x61, 0x6c,
+	0x75, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x02, 0x48, 0x00, 0x52, 0x0a, 0x66, 0x6c, 0x6f, 0x61,
+	0x74, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x23, 0x0a, 0x0c, 0x64, 0x6f, 0x75, 0x62, 0x6c, 0x65,
+	0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x01, 0x48, 0x00, 0x52, 0x0b,
+	0x64, 0x6f, 0x75, 0x62, 0x6c, 0x65, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x1f, 0x0a, 0x0a, 0x62,
+	0x6f, 0x6f, 0x6c, 0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x08, 0x48,
+	0x00, 0x52, 0x09, 0x62, 0x6f, 0x6f, 0x6c, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x23, 0x0a, 0x0c,
+	0x73, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x08, 0x20, 0x01,
+	0x28, 0x09, 0x48, 0x00, 0x52, 0x0b, 0x73, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x56, 0x61, 0x6c, 0x75,
+	0x65, 0x12, 0x21, 0x0a, 0x0b, 0x62, 0x79, 0x74, 0x65, 0x73, 0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65,
+	0x18, 0x09, 0x20, 0x01, 0x28, 0x0c, 0x48, 0x00, 0x52, 0x0a, 0x62, 0x79, 0x74, 0x65, 0x73, 0x56,
+	0x61, 0x6c, 0x75, 0x65, 0x12, 0x23, 0x0a, 0x0c, 0x75, 0x69, 0x6e, 0x74, 0x36, 0x34, 0x5f, 0x76,
+	0x61, 0x6c, 0x75, 0x65, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x04, 0x48, 0x00, 0x52, 0x0b, 0x75, 0x69,
+	0x6e, 0x74, 0x36, 0x34, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x29, 0x0a, 0x0f, 0x74, 0x69, 0x6d,
+	0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x0b, 0x20, 0x01,
+	0x28, 0x03, 0x48, 0x00, 0x52, 0x0e, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x56,
+	0x61, 0x6c, 0x75, 0x65, 0x12, 0x27, 0x0a, 0x0e, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x03, 0x48, 0x00, 0x52, 0x0d,
+	0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x2d, 0x0a,
+	0x03, 0x61, 0x6e, 0x64, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x66, 0x69, 0x6c,
+	0x74, 0x65, 0x72, 0x5f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x43, 0x6f, 0x6e, 0x6a, 0x75, 0x6e,
+	0x63, 0x74, 0x69, 0x6f, 0x6e, 0x48, 0x00, 0x52, 0x03, 0x61, 0x6e, 0x64, 0x12, 0x19, 0x0a, 0x07,
+	0x69, 0x73, 0x5f, 0x6e, 0x75, 0x6c, 0x6c, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x08, 0x48, 0x00, 0x52,
+	0x06, 0x69, 0x73, 0x4e, 0x75, 0x6c, 0x6c, 0x42, 0x07, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65,
+	0x22, 0x3d, 0x0a, 0x0b, 0x43, 0x6f, 0x6e, 0x6a, 0x75, 0x6e, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12,
+	0x2e, 0x0a, 0x07, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x14, 0x2e, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x5f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e,
+	0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x52, 0x07, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x73, 0x2a,
+	0x75, 0x0a, 0x0e, 0x43, 0x6f, 0x6d, 0x70, 0x61, 0x72, 0x69, 0x73, 0x6f, 0x6e, 0x54, 0x79, 0x70,
+	0x65, 0x12, 0x09, 0x0a, 0x05, 0x45, 0x71, 0x75, 0x61, 0x6c, 0x10, 0x00, 0x12, 0x0c, 0x0a, 0x08,
+	0x4e, 0x6f, 0x74, 0x45, 0x71, 0x75, 0x61, 0x6c, 0x10, 0x01, 0x12, 0x0c, 0x0a, 0x08, 0x4c, 0x65,
+	0x73, 0x73, 0x54, 0x68, 0x61, 0x6e, 0x10, 0x02, 0x12, 0x13, 0x0a, 0x0f, 0x4c, 0x65, 0x73, 0x73,
+	0x54, 0x68, 0x61, 0x6e, 0x4f, 0x72, 0x45, 0x71, 0x75, 0x61, 0x6c, 0x10, 0x03, 0x12, 0x0f, 0x0a,
+	0x0b, 0x47, 0x72, 0x65, 0x61, 0x74, 0x65, 0x72, 0x54, 0x68, 0x61, 0x6e, 0x10, 0x04, 0x12, 0x16,
+	0x0a, 0x12, 0x47, 0x72, 0x65, 0x61, 0x74, 0x65, 0x72, 0x54, 0x68, 0x61, 0x6e, 0x4f, 0x72, 0x45,
+	0x71, 0x75, 0x61, 0x6c, 0x10, 0x05, 0x42, 0x3b, 0x5a, 0x39, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62,
+	0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6d, 0x69, 0x6c, 0x76, 0x75, 0x73, 0x2d, 0x69, 0x6f, 0x2f, 0x6d,
+	0x69, 0x6c, 0x76, 0x75, 0x73, 0x2d, 0x73, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x2f, 0x67, 0x6f,
+	0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x5f, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_filter_proto_rawDescOnce sync.Once
+	file_filter_proto_rawDescData = file_filter_proto_rawDesc
+)
+
+func file_filter_proto_rawDescGZIP() []byte {
+	file_filter_proto_rawDescOnce.Do(func() {
+		file_filter_proto_rawDescData = protoimpl.X.CompressGZIP(file_filter_proto_rawDescData)
+	})
+	return file_filter_proto_rawDescData
+}
+
+var file_filter_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_filter_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_filter_proto_goTypes = []interface{}{
+	(ComparisonType)(0), // 0: filter_proto.ComparisonType
+	(*Filter)(nil),      // 1: filter_proto.Filter
+	(*Conjunction)(nil), // 2: filter_proto.Conjunction
+}
+var file_filter_proto_depIdxs = []int32{
+	0, // 0: filter_proto.Filter.comparison:type_name -> filter_proto.ComparisonType
+	2, // 1: filter_proto.Filter.and:type_name -> filter_proto.Conjunction
+	1, // 2: filter_proto.Conjunction.filters:type_name -> filter_proto.Filter
+	3, // [3:3] is the sub-list for method output_type
+	3, // [3:3] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_filter_proto_init() }
+func file_filter_proto_init() {
+	if File_filter_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_filter_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Filter); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_filter_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Conjunction); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_filter_proto_msgTypes[0].OneofWrappers = []interface{}{
+		(*Filter_Int32Value)(nil),
+		(*Filter_Int64Value)(nil),
+		(*Filter_FloatValue)(nil),
+		(*Filter_DoubleValue)(nil),
+		(*Filter_BoolValue)(nil),
+		(*Filter_StringValue)(nil),
+		(*Filter_BytesValue)(nil),
+		(*Filter_Uint64Value)(nil),
+		(*Filter_TimestampValue)(nil),
+		(*Filter_DurationValue)(nil),
+		(*Filter_And)(nil),
+		(*Filter_IsNull)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_filter_proto_rawDesc,
+			NumEnums:      1,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_filter_proto_goTypes,
+		DependencyIndexes: file_filter_proto_depIdxs,
+		EnumInfos:         file_filter_proto_enumTypes,
+		MessageInfos:      file_filter_proto_msgTypes,
+	}.Build()
+	File_filter_proto = out.File
+	file_filter_proto_rawDesc = nil
+	file_filter_proto_goTypes = nil
+	file_filter_proto_depIdxs = nil
+}
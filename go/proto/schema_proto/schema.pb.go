@@ -1,7 +1,7 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
-// 	protoc-gen-go v1.30.0
-// 	protoc        v3.19.4
+// 	protoc-gen-go v1.31.0
+// 	protoc        v3.21.9
 // source: schema.proto
 
 package schema_proto
@@ -39,31 +39,31 @@ const (
 	LogicType_STRING            LogicType = 13
 	LogicType_BINARY            LogicType = 14
 	LogicType_FIXED_SIZE_BINARY LogicType = 15
-	// DATE32 = 16;
-	// DATE64 = 17;
-	// TIMESTAMP = 18;
-	// TIME32 = 19;
-	// TIME64 = 20;
-	// INTERVAL_MONTHS = 21;
-	// INTERVAL_DAY_TIME = 22;
-	// DECIMAL128 = 23;
-	// option allow_alias = true;
-	// DECIMAL = 23;  // DECIMAL==DECIMAL128
-	// DECIMAL256 = 24;
+	//   DATE32 = 16;
+	//   DATE64 = 17;
+	//   TIMESTAMP = 18;
+	//   TIME32 = 19;
+	//   TIME64 = 20;
+	//   INTERVAL_MONTHS = 21;
+	//   INTERVAL_DAY_TIME = 22;
+	//   DECIMAL128 = 23;
+	//   option allow_alias = true;
+	//   DECIMAL = 23;  // DECIMAL==DECIMAL128
+	//   DECIMAL256 = 24;
 	LogicType_LIST   LogicType = 25
 	LogicType_STRUCT LogicType = 26
-	// SPARSE_UNION = 27;
-	// DENSE_UNION = 28;
+	//   SPARSE_UNION = 27;
+	//   DENSE_UNION = 28;
 	LogicType_DICTIONARY LogicType = 29
 	LogicType_MAP        LogicType = 30
-	// EXTENSION = 31;
+	//   EXTENSION = 31;
 	LogicType_FIXED_SIZE_LIST LogicType = 32
-	// DURATION = 33;
-	// LARGE_STRING = 34;
-	// LARGE_BINARY = 35;
-	// LARGE_LIST = 36;
-	// INTERVAL_MONTH_DAY_NANO = 37;
-	// RUN_END_ENCODED = 38;
+	//   DURATION = 33;
+	//   LARGE_STRING = 34;
+	//   LARGE_BINARY = 35;
+	//   LARGE_LIST = 36;
+	//   INTERVAL_MONTH_DAY_NANO = 37;
+	//   RUN_END_ENCODED = 38;
 	LogicType_MAX_ID LogicType = 39
 )
 
@@ -192,6 +192,168 @@ func (Endianness) EnumDescriptor() ([]byte, []int) {
 	return file_schema_proto_rawDescGZIP(), []int{1}
 }
 
+type Codec int32
+
+const (
+	Codec_CODEC_DEFAULT      Codec = 0
+	Codec_CODEC_UNCOMPRESSED Codec = 1
+	Codec_CODEC_SNAPPY       Codec = 2
+	Codec_CODEC_GZIP         Codec = 3
+	Codec_CODEC_BROTLI       Codec = 4
+	Codec_CODEC_ZSTD         Codec = 5
+)
+
+// Enum value maps for Codec.
+var (
+	Codec_name = map[int32]string{
+		0: "CODEC_DEFAULT",
+		1: "CODEC_UNCOMPRESSED",
+		2: "CODEC_SNAPPY",
+		3: "CODEC_GZIP",
+		4: "CODEC_BROTLI",
+		5: "CODEC_ZSTD",
+	}
+	Codec_value = map[string]int32{
+		"CODEC_DEFAULT":      0,
+		"CODEC_UNCOMPRESSED": 1,
+		"CODEC_SNAPPY":       2,
+		"CODEC_GZIP":         3,
+		"CODEC_BROTLI":       4,
+		"CODEC_ZSTD":         5,
+	}
+)
+
+func (x Codec) Enum() *Codec {
+	p := new(Codec)
+	*p = x
+	return p
+}
+
+func (x Codec) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Codec) Descriptor() protoreflect.EnumDescriptor {
+	return file_schema_proto_enumTypes[2].Descriptor()
+}
+
+func (Codec) Type() protoreflect.EnumType {
+	return &file_schema_proto_enumTypes[2]
+}
+
+func (x Codec) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Codec.Descriptor instead.
+func (Codec) EnumDescriptor() ([]byte, []int) {
+	return file_schema_proto_rawDescGZIP(), []int{2}
+}
+
+type Encoding int32
+
+const (
+	Encoding_ENCODING_DEFAULT                 Encoding = 0
+	Encoding_ENCODING_PLAIN                   Encoding = 1
+	Encoding_ENCODING_DICTIONARY              Encoding = 2
+	Encoding_ENCODING_DELTA_BINARY_PACKED     Encoding = 3
+	Encoding_ENCODING_DELTA_LENGTH_BYTE_ARRAY Encoding = 4
+	Encoding_ENCODING_DELTA_BYTE_ARRAY        Encoding = 5
+)
+
+// Enum value maps for Encoding.
+var (
+	Encoding_name = map[int32]string{
+		0: "ENCODING_DEFAULT",
+		1: "ENCODING_PLAIN",
+		2: "ENCODING_DICTIONARY",
+		3: "ENCODING_DELTA_BINARY_PACKED",
+		4: "ENCODING_DELTA_LENGTH_BYTE_ARRAY",
+		5: "ENCODING_DELTA_BYTE_ARRAY",
+	}
+	Encoding_value = map[string]int32{
+		"ENCODING_DEFAULT":                 0,
+		"ENCODING_PLAIN":                   1,
+		"ENCODING_DICTIONARY":              2,
+		"ENCODING_DELTA_BINARY_PACKED":     3,
+		"ENCODING_DELTA_LENGTH_BYTE_ARRAY": 4,
+		"ENCODING_DELTA_BYTE_ARRAY":        5,
+	}
+)
+
+func (x Encoding) Enum() *Encoding {
+	p := new(Encoding)
+	*p = x
+	return p
+}
+
+func (x Encoding) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Encoding) Descriptor() protoreflect.EnumDescriptor {
+	return file_schema_proto_enumTypes[3].Descriptor()
+}
+
+func (Encoding) Type() protoreflect.EnumType {
+	return &file_schema_proto_enumTypes[3]
+}
+
+func (x Encoding) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Encoding.Descriptor instead.
+func (Encoding) EnumDescriptor() ([]byte, []int) {
+	return file_schema_proto_rawDescGZIP(), []int{3}
+}
+
+type ColumnGroupPolicy int32
+
+const (
+	ColumnGroupPolicy_GROUP_BY_VECTOR ColumnGroupPolicy = 0
+	ColumnGroupPolicy_GROUP_TOGETHER  ColumnGroupPolicy = 1
+)
+
+// Enum value maps for ColumnGroupPolicy.
+var (
+	ColumnGroupPolicy_name = map[int32]string{
+		0: "GROUP_BY_VECTOR",
+		1: "GROUP_TOGETHER",
+	}
+	ColumnGroupPolicy_value = map[string]int32{
+		"GROUP_BY_VECTOR": 0,
+		"GROUP_TOGETHER":  1,
+	}
+)
+
+func (x ColumnGroupPolicy) Enum() *ColumnGroupPolicy {
+	p := new(ColumnGroupPolicy)
+	*p = x
+	return p
+}
+
+func (x ColumnGroupPolicy) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ColumnGroupPolicy) Descriptor() protoreflect.EnumDescriptor {
+	return file_schema_proto_enumTypes[4].Descriptor()
+}
+
+func (ColumnGroupPolicy) Type() protoreflect.EnumType {
+	return &file_schema_proto_enumTypes[4]
+}
+
+func (x ColumnGroupPolicy) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ColumnGroupPolicy.Descriptor instead.
+func (ColumnGroupPolicy) EnumDescriptor() ([]byte, []int) {
+	return file_schema_proto_rawDescGZIP(), []int{4}
+}
+
 type FixedSizeBinaryType struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -402,7 +564,6 @@ type DataType struct {
 	unknownFields protoimpl.UnknownFields
 
 	// Types that are assignable to TypeRelatedValues:
-	//
 	//	*DataType_FixedSizeBinaryType
 	//	*DataType_FixedSizeListType
 	//	*DataType_DictionaryType
@@ -652,9 +813,17 @@ type SchemaOptions struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	PrimaryColumn string `protobuf:"bytes,1,opt,name=primary_column,json=primaryColumn,proto3" json:"primary_column,omitempty"`
-	VersionColumn string `protobuf:"bytes,2,opt,name=version_column,json=versionColumn,proto3" json:"version_column,omitempty"`
-	VectorColumn  string `protobuf:"bytes,3,opt,name=vector_column,json=vectorColumn,proto3" json:"vector_column,omitempty"`
+	PrimaryColumn      string            `protobuf:"bytes,1,opt,name=primary_column,json=primaryColumn,proto3" json:"primary_column,omitempty"`
+	VersionColumn      string            `protobuf:"bytes,2,opt,name=version_column,json=versionColumn,proto3" json:"version_column,omitempty"`
+	VectorColumn       string            `protobuf:"bytes,3,opt,name=vector_column,json=vectorColumn,proto3" json:"vector_column,omitempty"`
+	ColumnGroupPolicy  ColumnGroupPolicy `protobuf:"varint,4,opt,name=column_group_policy,json=columnGroupPolicy,proto3,enum=schema_proto.ColumnGroupPolicy" json:"column_group_policy,omitempty"`
+	VectorGroupColumns []string          `protobuf:"bytes,5,rep,name=vector_group_columns,json=vectorGroupColumns,proto3" json:"vector_group_columns,omitempty"`
+	// generate the int64 primary keys of rows written without the primary column
+	AutoId bool `protobuf:"varint,6,opt,name=auto_id,json=autoId,proto3" json:"auto_id,omitempty"`
+	// how the data files store each field, by field name
+	StorageProfiles map[string]*StorageProfile `protobuf:"bytes,7,rep,name=storage_profiles,json=storageProfiles,proto3" json:"storage_profiles,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// vector columns other than vector_column, stored with it
+	VectorColumns []string `protobuf:"bytes,8,rep,name=vector_columns,json=vectorColumns,proto3" json:"vector_columns,omitempty"`
 }
 
 func (x *SchemaOptions) Reset() {
@@ -710,6 +879,105 @@ func (x *SchemaOptions) GetVectorColumn() string {
 	return ""
 }
 
+func (x *SchemaOptions) GetColumnGroupPolicy() ColumnGroupPolicy {
+	if x != nil {
+		return x.ColumnGroupPolicy
+	}
+	return ColumnGroupPolicy_GROUP_BY_VECTOR
+}
+
+func (x *SchemaOptions) GetVectorGroupColumns() []string {
+	if x != nil {
+		return x.VectorGroupColumns
+	}
+	return nil
+}
+
+func (x *SchemaOptions) GetAutoId() bool {
+	if x != nil {
+		return x.AutoId
+	}
+	return false
+}
+
+func (x *SchemaOptions) GetStorageProfiles() map[string]*StorageProfile {
+	if x != nil {
+		return x.StorageProfiles
+	}
+	return nil
+}
+
+func (x *SchemaOptions) GetVectorColumns() []string {
+	if x != nil {
+		return x.VectorColumns
+	}
+	return nil
+}
+
+type StorageProfile struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Codec Codec `protobuf:"varint,1,opt,name=codec,proto3,enum=schema_proto.Codec" json:"codec,omitempty"`
+	// 0 is the default level of the codec
+	Level    int32    `protobuf:"varint,2,opt,name=level,proto3" json:"level,omitempty"`
+	Encoding Encoding `protobuf:"varint,3,opt,name=encoding,proto3,enum=schema_proto.Encoding" json:"encoding,omitempty"`
+}
+
+func (x *StorageProfile) Reset() {
+	*x = StorageProfile{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_schema_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StorageProfile) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StorageProfile) ProtoMessage() {}
+
+func (x *StorageProfile) ProtoReflect() protoreflect.Message {
+	mi := &file_schema_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StorageProfile.ProtoReflect.Descriptor instead.
+func (*StorageProfile) Descriptor() ([]byte, []int) {
+	return file_schema_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *StorageProfile) GetCodec() Codec {
+	if x != nil {
+		return x.Codec
+	}
+	return Codec_CODEC_DEFAULT
+}
+
+func (x *StorageProfile) GetLevel() int32 {
+	if x != nil {
+		return x.Level
+	}
+	return 0
+}
+
+func (x *StorageProfile) GetEncoding() Encoding {
+	if x != nil {
+		return x.Encoding
+	}
+	return Encoding_ENCODING_DEFAULT
+}
+
 type ArrowSchema struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -723,7 +991,7 @@ type ArrowSchema struct {
 func (x *ArrowSchema) Reset() {
 	*x = ArrowSchema{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_schema_proto_msgTypes[8]
+		mi := &file_schema_proto_msgTypes[9]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -736,7 +1004,7 @@ func (x *ArrowSchema) String() string {
 func (*ArrowSchema) ProtoMessage() {}
 
 func (x *ArrowSchema) ProtoReflect() protoreflect.Message {
-	mi := &file_schema_proto_msgTypes[8]
+	mi := &file_schema_proto_msgTypes[9]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -749,7 +1017,7 @@ func (x *ArrowSchema) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ArrowSchema.ProtoReflect.Descriptor instead.
 func (*ArrowSchema) Descriptor() ([]byte, []int) {
-	return file_schema_proto_rawDescGZIP(), []int{8}
+	return file_schema_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *ArrowSchema) GetFields() []*Field {
@@ -778,14 +1046,18 @@ type Schema struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
+	// written for older readers when all the types of the schema can be mapped, readers use
+	// arrow_ipc_schema if it is set
 	ArrowSchema   *ArrowSchema   `protobuf:"bytes,1,opt,name=arrow_schema,json=arrowSchema,proto3" json:"arrow_schema,omitempty"`
 	SchemaOptions *SchemaOptions `protobuf:"bytes,2,opt,name=schema_options,json=schemaOptions,proto3" json:"schema_options,omitempty"`
+	// the schema serialized as an Arrow IPC stream without batches
+	ArrowIpcSchema []byte `protobuf:"bytes,3,opt,name=arrow_ipc_schema,json=arrowIpcSchema,proto3" json:"arrow_ipc_schema,omitempty"`
 }
 
 func (x *Schema) Reset() {
 	*x = Schema{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_schema_proto_msgTypes[9]
+		mi := &file_schema_proto_msgTypes[10]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -798,7 +1070,7 @@ func (x *Schema) String() string {
 func (*Schema) ProtoMessage() {}
 
 func (x *Schema) ProtoReflect() protoreflect.Message {
-	mi := &file_schema_proto_msgTypes[9]
+	mi := &file_schema_proto_msgTypes[10]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -811,7 +1083,7 @@ func (x *Schema) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Schema.ProtoReflect.Descriptor instead.
 func (*Schema) Descriptor() ([]byte, []int) {
-	return file_schema_proto_rawDescGZIP(), []int{9}
+	return file_schema_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *Schema) GetArrowSchema() *ArrowSchema {
@@ -828,6 +1100,13 @@ func (x *Schema) GetSchemaOptions() *SchemaOptions {
 	return nil
 }
 
+func (x *Schema) GetArrowIpcSchema() []byte {
+	if x != nil {
+		return x.ArrowIpcSchema
+	}
+	return nil
+}
+
 var File_schema_proto protoreflect.FileDescriptor
 
 var file_schema_proto_rawDesc = []byte{
@@ -893,8 +1172,8 @@ var file_schema_proto_rawDesc = []byte{
 	0x70, 0x65, 0x12, 0x3a, 0x0a, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x18, 0x04,
 	0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x5f, 0x70, 0x72,
 	0x6f, 0x74, 0x6f, 0x2e, 0x4b, 0x65, 0x79, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x4d, 0x65, 0x74, 0x61,
-	0x64, 0x61, 0x74, 0x61, 0x52, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x22, 0x82,
-	0x01, 0x0a, 0x0d, 0x53, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73,
+	0x64, 0x61, 0x74, 0x61, 0x52, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x22, 0x84,
+	0x04, 0x0a, 0x0d, 0x53, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73,
 	0x12, 0x25, 0x0a, 0x0e, 0x70, 0x72, 0x69, 0x6d, 0x61, 0x72, 0x79, 0x5f, 0x63, 0x6f, 0x6c, 0x75,
 	0x6d, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x70, 0x72, 0x69, 0x6d, 0x61, 0x72,
 	0x79, 0x43, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x12, 0x25, 0x0a, 0x0e, 0x76, 0x65, 0x72, 0x73, 0x69,
@@ -902,51 +1181,108 @@ var file_schema_proto_rawDesc = []byte{
 	0x0d, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x43, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x12, 0x23,
 	0x0a, 0x0d, 0x76, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x5f, 0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x18,
 	0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x76, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x43, 0x6f, 0x6c,
-	0x75, 0x6d, 0x6e, 0x22, 0xb0, 0x01, 0x0a, 0x0b, 0x41, 0x72, 0x72, 0x6f, 0x77, 0x53, 0x63, 0x68,
-	0x65, 0x6d, 0x61, 0x12, 0x2b, 0x0a, 0x06, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x73, 0x18, 0x01, 0x20,
-	0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x5f, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x2e, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x52, 0x06, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x73,
-	0x12, 0x38, 0x0a, 0x0a, 0x65, 0x6e, 0x64, 0x69, 0x61, 0x6e, 0x6e, 0x65, 0x73, 0x73, 0x18, 0x02,
-	0x20, 0x01, 0x28, 0x0e, 0x32, 0x18, 0x2e, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x5f, 0x70, 0x72,
-	0x6f, 0x74, 0x6f, 0x2e, 0x45, 0x6e, 0x64, 0x69, 0x61, 0x6e, 0x6e, 0x65, 0x73, 0x73, 0x52, 0x0a,
-	0x65, 0x6e, 0x64, 0x69, 0x61, 0x6e, 0x6e, 0x65, 0x73, 0x73, 0x12, 0x3a, 0x0a, 0x08, 0x6d, 0x65,
-	0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x73,
-	0x63, 0x68, 0x65, 0x6d, 0x61, 0x5f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x4b, 0x65, 0x79, 0x56,
-	0x61, 0x6c, 0x75, 0x65, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x52, 0x08, 0x6d, 0x65,
-	0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x22, 0x8a, 0x01, 0x0a, 0x06, 0x53, 0x63, 0x68, 0x65, 0x6d,
-	0x61, 0x12, 0x3c, 0x0a, 0x0c, 0x61, 0x72, 0x72, 0x6f, 0x77, 0x5f, 0x73, 0x63, 0x68, 0x65, 0x6d,
-	0x61, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61,
-	0x5f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x41, 0x72, 0x72, 0x6f, 0x77, 0x53, 0x63, 0x68, 0x65,
-	0x6d, 0x61, 0x52, 0x0b, 0x61, 0x72, 0x72, 0x6f, 0x77, 0x53, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x12,
-	0x42, 0x0a, 0x0e, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x5f, 0x6f, 0x70, 0x74, 0x69, 0x6f, 0x6e,
-	0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61,
-	0x5f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x53, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x4f, 0x70, 0x74,
-	0x69, 0x6f, 0x6e, 0x73, 0x52, 0x0d, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x4f, 0x70, 0x74, 0x69,
-	0x6f, 0x6e, 0x73, 0x2a, 0x9d, 0x02, 0x0a, 0x09, 0x4c, 0x6f, 0x67, 0x69, 0x63, 0x54, 0x79, 0x70,
-	0x65, 0x12, 0x06, 0x0a, 0x02, 0x4e, 0x41, 0x10, 0x00, 0x12, 0x08, 0x0a, 0x04, 0x42, 0x4f, 0x4f,
-	0x4c, 0x10, 0x01, 0x12, 0x09, 0x0a, 0x05, 0x55, 0x49, 0x4e, 0x54, 0x38, 0x10, 0x02, 0x12, 0x08,
-	0x0a, 0x04, 0x49, 0x4e, 0x54, 0x38, 0x10, 0x03, 0x12, 0x0a, 0x0a, 0x06, 0x55, 0x49, 0x4e, 0x54,
-	0x31, 0x36, 0x10, 0x04, 0x12, 0x09, 0x0a, 0x05, 0x49, 0x4e, 0x54, 0x31, 0x36, 0x10, 0x05, 0x12,
-	0x0a, 0x0a, 0x06, 0x55, 0x49, 0x4e, 0x54, 0x33, 0x32, 0x10, 0x06, 0x12, 0x09, 0x0a, 0x05, 0x49,
-	0x4e, 0x54, 0x33, 0x32, 0x10, 0x07, 0x12, 0x0a, 0x0a, 0x06, 0x55, 0x49, 0x4e, 0x54, 0x36, 0x34,
-	0x10, 0x08, 0x12, 0x09, 0x0a, 0x05, 0x49, 0x4e, 0x54, 0x36, 0x34, 0x10, 0x09, 0x12, 0x0e, 0x0a,
-	0x0a, 0x48, 0x41, 0x4c, 0x46, 0x5f, 0x46, 0x4c, 0x4f, 0x41, 0x54, 0x10, 0x0a, 0x12, 0x09, 0x0a,
-	0x05, 0x46, 0x4c, 0x4f, 0x41, 0x54, 0x10, 0x0b, 0x12, 0x0a, 0x0a, 0x06, 0x44, 0x4f, 0x55, 0x42,
-	0x4c, 0x45, 0x10, 0x0c, 0x12, 0x0a, 0x0a, 0x06, 0x53, 0x54, 0x52, 0x49, 0x4e, 0x47, 0x10, 0x0d,
-	0x12, 0x0a, 0x0a, 0x06, 0x42, 0x49, 0x4e, 0x41, 0x52, 0x59, 0x10, 0x0e, 0x12, 0x15, 0x0a, 0x11,
-	0x46, 0x49, 0x58, 0x45, 0x44, 0x5f, 0x53, 0x49, 0x5a, 0x45, 0x5f, 0x42, 0x49, 0x4e, 0x41, 0x52,
-	0x59, 0x10, 0x0f, 0x12, 0x08, 0x0a, 0x04, 0x4c, 0x49, 0x53, 0x54, 0x10, 0x19, 0x12, 0x0a, 0x0a,
-	0x06, 0x53, 0x54, 0x52, 0x55, 0x43, 0x54, 0x10, 0x1a, 0x12, 0x0e, 0x0a, 0x0a, 0x44, 0x49, 0x43,
-	0x54, 0x49, 0x4f, 0x4e, 0x41, 0x52, 0x59, 0x10, 0x1d, 0x12, 0x07, 0x0a, 0x03, 0x4d, 0x41, 0x50,
-	0x10, 0x1e, 0x12, 0x13, 0x0a, 0x0f, 0x46, 0x49, 0x58, 0x45, 0x44, 0x5f, 0x53, 0x49, 0x5a, 0x45,
-	0x5f, 0x4c, 0x49, 0x53, 0x54, 0x10, 0x20, 0x12, 0x0a, 0x0a, 0x06, 0x4d, 0x41, 0x58, 0x5f, 0x49,
-	0x44, 0x10, 0x27, 0x2a, 0x21, 0x0a, 0x0a, 0x45, 0x6e, 0x64, 0x69, 0x61, 0x6e, 0x6e, 0x65, 0x73,
-	0x73, 0x12, 0x0a, 0x0a, 0x06, 0x4c, 0x69, 0x74, 0x74, 0x6c, 0x65, 0x10, 0x00, 0x12, 0x07, 0x0a,
-	0x03, 0x42, 0x69, 0x67, 0x10, 0x01, 0x42, 0x4c, 0x5a, 0x4a, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62,
-	0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6d, 0x69, 0x6c, 0x76, 0x75, 0x73, 0x2d, 0x69, 0x6f, 0x2f, 0x6d,
-	0x69, 0x6c, 0x76, 0x75, 0x73, 0x2d, 0x73, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x2d, 0x66, 0x6f,
-	0x72, 0x6d, 0x61, 0x74, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x73, 0x63, 0x68, 0x65, 0x6d,
-	0x61, 0x5f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x3b, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x5f, 0x70,
+	0x75, 0x6d, 0x6e, 0x12, 0x4f, 0x0a, 0x13, 0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x5f, 0x67, 0x72,
+	0x6f, 0x75, 0x70, 0x5f, 0x70, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0e,
+	0x32, 0x1f, 0x2e, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x5f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e,
+	0x43, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x50, 0x6f, 0x6c, 0x69, 0x63,
+	0x79, 0x52, 0x11, 0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x50, 0x6f,
+	0x6c, 0x69, 0x63, 0x79, 0x12, 0x30, 0x0a, 0x14, 0x76, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x5f, 0x67,
+	0x72, 0x6f, 0x75, 0x70, 0x5f, 0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x73, 0x18, 0x05, 0x20, 0x03,
+	0x28, 0x09, 0x52, 0x12, 0x76, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x43,
+	0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x73, 0x12, 0x17, 0x0a, 0x07, 0x61, 0x75, 0x74, 0x6f, 0x5f, 0x69,
+	0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x61, 0x75, 0x74, 0x6f, 0x49, 0x64, 0x12,
+	0x5b, 0x0a, 0x10, 0x73, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x5f, 0x70, 0x72, 0x6f, 0x66, 0x69,
+	0x6c, 0x65, 0x73, 0x18, 0x07, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x30, 0x2e, 0x73, 0x63, 0x68, 0x65,
+	0x6d, 0x61, 0x5f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x53, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x4f,
+	0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x2e, 0x53, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x50, 0x72,
+	0x6f, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x0f, 0x73, 0x74, 0x6f,
+	0x72, 0x61, 0x67, 0x65, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x12, 0x25, 0x0a, 0x0e,
+	0x76, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x5f, 0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x73, 0x18, 0x08,
+	0x20, 0x03, 0x28, 0x09, 0x52, 0x0d, 0x76, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x43, 0x6f, 0x6c, 0x75,
+	0x6d, 0x6e, 0x73, 0x1a, 0x60, 0x0a, 0x14, 0x53, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x50, 0x72,
+	0x6f, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b,
+	0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x32, 0x0a,
+	0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x73,
+	0x63, 0x68, 0x65, 0x6d, 0x61, 0x5f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x53, 0x74, 0x6f, 0x72,
+	0x61, 0x67, 0x65, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75,
+	0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x85, 0x01, 0x0a, 0x0e, 0x53, 0x74, 0x6f, 0x72, 0x61, 0x67,
+	0x65, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x12, 0x29, 0x0a, 0x05, 0x63, 0x6f, 0x64, 0x65,
+	0x63, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x13, 0x2e, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61,
+	0x5f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x43, 0x6f, 0x64, 0x65, 0x63, 0x52, 0x05, 0x63, 0x6f,
+	0x64, 0x65, 0x63, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x65, 0x76, 0x65, 0x6c, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x05, 0x6c, 0x65, 0x76, 0x65, 0x6c, 0x12, 0x32, 0x0a, 0x08, 0x65, 0x6e, 0x63,
+	0x6f, 0x64, 0x69, 0x6e, 0x67, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x16, 0x2e, 0x73, 0x63,
+	0x68, 0x65, 0x6d, 0x61, 0x5f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x45, 0x6e, 0x63, 0x6f, 0x64,
+	0x69, 0x6e, 0x67, 0x52, 0x08, 0x65, 0x6e, 0x63, 0x6f, 0x64, 0x69, 0x6e, 0x67, 0x22, 0xb0, 0x01,
+	0x0a, 0x0b, 0x41, 0x72, 0x72, 0x6f, 0x77, 0x53, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x12, 0x2b, 0x0a,
+	0x06, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e,
+	0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x5f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x46, 0x69, 0x65,
+	0x6c, 0x64, 0x52, 0x06, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x73, 0x12, 0x38, 0x0a, 0x0a, 0x65, 0x6e,
+	0x64, 0x69, 0x61, 0x6e, 0x6e, 0x65, 0x73, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x18,
+	0x2e, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x5f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x45, 0x6e,
+	0x64, 0x69, 0x61, 0x6e, 0x6e, 0x65, 0x73, 0x73, 0x52, 0x0a, 0x65, 0x6e, 0x64, 0x69, 0x61, 0x6e,
+	0x6e, 0x65, 0x73, 0x73, 0x12, 0x3a, 0x0a, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x5f,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x4b, 0x65, 0x79, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x4d, 0x65,
+	0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x52, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61,
+	0x22, 0xb4, 0x01, 0x0a, 0x06, 0x53, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x12, 0x3c, 0x0a, 0x0c, 0x61,
+	0x72, 0x72, 0x6f, 0x77, 0x5f, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x19, 0x2e, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x5f, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x2e, 0x41, 0x72, 0x72, 0x6f, 0x77, 0x53, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x52, 0x0b, 0x61, 0x72,
+	0x72, 0x6f, 0x77, 0x53, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x12, 0x42, 0x0a, 0x0e, 0x73, 0x63, 0x68,
+	0x65, 0x6d, 0x61, 0x5f, 0x6f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x1b, 0x2e, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x5f, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x2e, 0x53, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x0d,
+	0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x28, 0x0a,
+	0x10, 0x61, 0x72, 0x72, 0x6f, 0x77, 0x5f, 0x69, 0x70, 0x63, 0x5f, 0x73, 0x63, 0x68, 0x65, 0x6d,
+	0x61, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0e, 0x61, 0x72, 0x72, 0x6f, 0x77, 0x49, 0x70,
+	0x63, 0x53, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x2a, 0x9d, 0x02, 0x0a, 0x09, 0x4c, 0x6f, 0x67, 0x69,
+	0x63, 0x54, 0x79, 0x70, 0x65, 0x12, 0x06, 0x0a, 0x02, 0x4e, 0x41, 0x10, 0x00, 0x12, 0x08, 0x0a,
+	0x04, 0x42, 0x4f, 0x4f, 0x4c, 0x10, 0x01, 0x12, 0x09, 0x0a, 0x05, 0x55, 0x49, 0x4e, 0x54, 0x38,
+	0x10, 0x02, 0x12, 0x08, 0x0a, 0x04, 0x49, 0x4e, 0x54, 0x38, 0x10, 0x03, 0x12, 0x0a, 0x0a, 0x06,
+	0x55, 0x49, 0x4e, 0x54, 0x31, 0x36, 0x10, 0x04, 0x12, 0x09, 0x0a, 0x05, 0x49, 0x4e, 0x54, 0x31,
+	0x36, 0x10, 0x05, 0x12, 0x0a, 0x0a, 0x06, 0x55, 0x49, 0x4e, 0x54, 0x33, 0x32, 0x10, 0x06, 0x12,
+	0x09, 0x0a, 0x05, 0x49, 0x4e, 0x54, 0x33, 0x32, 0x10, 0x07, 0x12, 0x0a, 0x0a, 0x06, 0x55, 0x49,
+	0x4e, 0x54, 0x36, 0x34, 0x10, 0x08, 0x12, 0x09, 0x0a, 0x05, 0x49, 0x4e, 0x54, 0x36, 0x34, 0x10,
+	0x09, 0x12, 0x0e, 0x0a, 0x0a, 0x48, 0x41, 0x4c, 0x46, 0x5f, 0x46, 0x4c, 0x4f, 0x41, 0x54, 0x10,
+	0x0a, 0x12, 0x09, 0x0a, 0x05, 0x46, 0x4c, 0x4f, 0x41, 0x54, 0x10, 0x0b, 0x12, 0x0a, 0x0a, 0x06,
+	0x44, 0x4f, 0x55, 0x42, 0x4c, 0x45, 0x10, 0x0c, 0x12, 0x0a, 0x0a, 0x06, 0x53, 0x54, 0x52, 0x49,
+	0x4e, 0x47, 0x10, 0x0d, 0x12, 0x0a, 0x0a, 0x06, 0x42, 0x49, 0x4e, 0x41, 0x52, 0x59, 0x10, 0x0e,
+	0x12, 0x15, 0x0a, 0x11, 0x46, 0x49, 0x58, 0x45, 0x44, 0x5f, 0x53, 0x49, 0x5a, 0x45, 0x5f, 0x42,
+	0x49, 0x4e, 0x41, 0x52, 0x59, 0x10, 0x0f, 0x12, 0x08, 0x0a, 0x04, 0x4c, 0x49, 0x53, 0x54, 0x10,
+	0x19, 0x12, 0x0a, 0x0a, 0x06, 0x53, 0x54, 0x52, 0x55, 0x43, 0x54, 0x10, 0x1a, 0x12, 0x0e, 0x0a,
+	0x0a, 0x44, 0x49, 0x43, 0x54, 0x49, 0x4f, 0x4e, 0x41, 0x52, 0x59, 0x10, 0x1d, 0x12, 0x07, 0x0a,
+	0x03, 0x4d, 0x41, 0x50, 0x10, 0x1e, 0x12, 0x13, 0x0a, 0x0f, 0x46, 0x49, 0x58, 0x45, 0x44, 0x5f,
+	0x53, 0x49, 0x5a, 0x45, 0x5f, 0x4c, 0x49, 0x53, 0x54, 0x10, 0x20, 0x12, 0x0a, 0x0a, 0x06, 0x4d,
+	0x41, 0x58, 0x5f, 0x49, 0x44, 0x10, 0x27, 0x2a, 0x21, 0x0a, 0x0a, 0x45, 0x6e, 0x64, 0x69, 0x61,
+	0x6e, 0x6e, 0x65, 0x73, 0x73, 0x12, 0x0a, 0x0a, 0x06, 0x4c, 0x69, 0x74, 0x74, 0x6c, 0x65, 0x10,
+	0x00, 0x12, 0x07, 0x0a, 0x03, 0x42, 0x69, 0x67, 0x10, 0x01, 0x2a, 0x76, 0x0a, 0x05, 0x43, 0x6f,
+	0x64, 0x65, 0x63, 0x12, 0x11, 0x0a, 0x0d, 0x43, 0x4f, 0x44, 0x45, 0x43, 0x5f, 0x44, 0x45, 0x46,
+	0x41, 0x55, 0x4c, 0x54, 0x10, 0x00, 0x12, 0x16, 0x0a, 0x12, 0x43, 0x4f, 0x44, 0x45, 0x43, 0x5f,
+	0x55, 0x4e, 0x43, 0x4f, 0x4d, 0x50, 0x52, 0x45, 0x53, 0x53, 0x45, 0x44, 0x10, 0x01, 0x12, 0x10,
+	0x0a, 0x0c, 0x43, 0x4f, 0x44, 0x45, 0x43, 0x5f, 0x53, 0x4e, 0x41, 0x50, 0x50, 0x59, 0x10, 0x02,
+	0x12, 0x0e, 0x0a, 0x0a, 0x43, 0x4f, 0x44, 0x45, 0x43, 0x5f, 0x47, 0x5a, 0x49, 0x50, 0x10, 0x03,
+	0x12, 0x10, 0x0a, 0x0c, 0x43, 0x4f, 0x44, 0x45, 0x43, 0x5f, 0x42, 0x52, 0x4f, 0x54, 0x4c, 0x49,
+	0x10, 0x04, 0x12, 0x0e, 0x0a, 0x0a, 0x43, 0x4f, 0x44, 0x45, 0x43, 0x5f, 0x5a, 0x53, 0x54, 0x44,
+	0x10, 0x05, 0x2a, 0xb4, 0x01, 0x0a, 0x08, 0x45, 0x6e, 0x63, 0x6f, 0x64, 0x69, 0x6e, 0x67, 0x12,
+	0x14, 0x0a, 0x10, 0x45, 0x4e, 0x43, 0x4f, 0x44, 0x49, 0x4e, 0x47, 0x5f, 0x44, 0x45, 0x46, 0x41,
+	0x55, 0x4c, 0x54, 0x10, 0x00, 0x12, 0x12, 0x0a, 0x0e, 0x45, 0x4e, 0x43, 0x4f, 0x44, 0x49, 0x4e,
+	0x47, 0x5f, 0x50, 0x4c, 0x41, 0x49, 0x4e, 0x10, 0x01, 0x12, 0x17, 0x0a, 0x13, 0x45, 0x4e, 0x43,
+	0x4f, 0x44, 0x49, 0x4e, 0x47, 0x5f, 0x44, 0x49, 0x43, 0x54, 0x49, 0x4f, 0x4e, 0x41, 0x52, 0x59,
+	0x10, 0x02, 0x12, 0x20, 0x0a, 0x1c, 0x45, 0x4e, 0x43, 0x4f, 0x44, 0x49, 0x4e, 0x47, 0x5f, 0x44,
+	0x45, 0x4c, 0x54, 0x41, 0x5f, 0x42, 0x49, 0x4e, 0x41, 0x52, 0x59, 0x5f, 0x50, 0x41, 0x43, 0x4b,
+	0x45, 0x44, 0x10, 0x03, 0x12, 0x24, 0x0a, 0x20, 0x45, 0x4e, 0x43, 0x4f, 0x44, 0x49, 0x4e, 0x47,
+	0x5f, 0x44, 0x45, 0x4c, 0x54, 0x41, 0x5f, 0x4c, 0x45, 0x4e, 0x47, 0x54, 0x48, 0x5f, 0x42, 0x59,
+	0x54, 0x45, 0x5f, 0x41, 0x52, 0x52, 0x41, 0x59, 0x10, 0x04, 0x12, 0x1d, 0x0a, 0x19, 0x45, 0x4e,
+	0x43, 0x4f, 0x44, 0x49, 0x4e, 0x47, 0x5f, 0x44, 0x45, 0x4c, 0x54, 0x41, 0x5f, 0x42, 0x59, 0x54,
+	0x45, 0x5f, 0x41, 0x52, 0x52, 0x41, 0x59, 0x10, 0x05, 0x2a, 0x3c, 0x0a, 0x11, 0x43, 0x6f, 0x6c,
+	0x75, 0x6d, 0x6e, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x12, 0x13,
+	0x0a, 0x0f, 0x47, 0x52, 0x4f, 0x55, 0x50, 0x5f, 0x42, 0x59, 0x5f, 0x56, 0x45, 0x43, 0x54, 0x4f,
+	0x52, 0x10, 0x00, 0x12, 0x12, 0x0a, 0x0e, 0x47, 0x52, 0x4f, 0x55, 0x50, 0x5f, 0x54, 0x4f, 0x47,
+	0x45, 0x54, 0x48, 0x45, 0x52, 0x10, 0x01, 0x42, 0x3b, 0x5a, 0x39, 0x67, 0x69, 0x74, 0x68, 0x75,
+	0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6d, 0x69, 0x6c, 0x76, 0x75, 0x73, 0x2d, 0x69, 0x6f, 0x2f,
+	0x6d, 0x69, 0x6c, 0x76, 0x75, 0x73, 0x2d, 0x73, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x2f, 0x67,
+	0x6f, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x5f, 0x70,
 	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
@@ -962,43 +1298,53 @@ func file_schema_proto_rawDescGZIP() []byte {
 	return file_schema_proto_rawDescData
 }
 
-var file_schema_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
-var file_schema_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_schema_proto_enumTypes = make([]protoimpl.EnumInfo, 5)
+var file_schema_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
 var file_schema_proto_goTypes = []interface{}{
 	(LogicType)(0),              // 0: schema_proto.LogicType
 	(Endianness)(0),             // 1: schema_proto.Endianness
-	(*FixedSizeBinaryType)(nil), // 2: schema_proto.FixedSizeBinaryType
-	(*FixedSizeListType)(nil),   // 3: schema_proto.FixedSizeListType
-	(*DictionaryType)(nil),      // 4: schema_proto.DictionaryType
-	(*MapType)(nil),             // 5: schema_proto.MapType
-	(*DataType)(nil),            // 6: schema_proto.DataType
-	(*KeyValueMetadata)(nil),    // 7: schema_proto.KeyValueMetadata
-	(*Field)(nil),               // 8: schema_proto.Field
-	(*SchemaOptions)(nil),       // 9: schema_proto.SchemaOptions
-	(*ArrowSchema)(nil),         // 10: schema_proto.ArrowSchema
-	(*Schema)(nil),              // 11: schema_proto.Schema
+	(Codec)(0),                  // 2: schema_proto.Codec
+	(Encoding)(0),               // 3: schema_proto.Encoding
+	(ColumnGroupPolicy)(0),      // 4: schema_proto.ColumnGroupPolicy
+	(*FixedSizeBinaryType)(nil), // 5: schema_proto.FixedSizeBinaryType
+	(*FixedSizeListType)(nil),   // 6: schema_proto.FixedSizeListType
+	(*DictionaryType)(nil),      // 7: schema_proto.DictionaryType
+	(*MapType)(nil),             // 8: schema_proto.MapType
+	(*DataType)(nil),            // 9: schema_proto.DataType
+	(*KeyValueMetadata)(nil),    // 10: schema_proto.KeyValueMetadata
+	(*Field)(nil),               // 11: schema_proto.Field
+	(*SchemaOptions)(nil),       // 12: schema_proto.SchemaOptions
+	(*StorageProfile)(nil),      // 13: schema_proto.StorageProfile
+	(*ArrowSchema)(nil),         // 14: schema_proto.ArrowSchema
+	(*Schema)(nil),              // 15: schema_proto.Schema
+	nil,                         // 16: schema_proto.SchemaOptions.StorageProfilesEntry
 }
 var file_schema_proto_depIdxs = []int32{
-	6,  // 0: schema_proto.DictionaryType.index_type:type_name -> schema_proto.DataType
-	6,  // 1: schema_proto.DictionaryType.value_type:type_name -> schema_proto.DataType
-	2,  // 2: schema_proto.DataType.fixed_size_binary_type:type_name -> schema_proto.FixedSizeBinaryType
-	3,  // 3: schema_proto.DataType.fixed_size_list_type:type_name -> schema_proto.FixedSizeListType
-	4,  // 4: schema_proto.DataType.dictionary_type:type_name -> schema_proto.DictionaryType
-	5,  // 5: schema_proto.DataType.map_type:type_name -> schema_proto.MapType
+	9,  // 0: schema_proto.DictionaryType.index_type:type_name -> schema_proto.DataType
+	9,  // 1: schema_proto.DictionaryType.value_type:type_name -> schema_proto.DataType
+	5,  // 2: schema_proto.DataType.fixed_size_binary_type:type_name -> schema_proto.FixedSizeBinaryType
+	6,  // 3: schema_proto.DataType.fixed_size_list_type:type_name -> schema_proto.FixedSizeListType
+	7,  // 4: schema_proto.DataType.dictionary_type:type_name -> schema_proto.DictionaryType
+	8,  // 5: schema_proto.DataType.map_type:type_name -> schema_proto.MapType
 	0,  // 6: schema_proto.DataType.logic_type:type_name -> schema_proto.LogicType
-	8,  // 7: schema_proto.DataType.children:type_name -> schema_proto.Field
-	6,  // 8: schema_proto.Field.data_type:type_name -> schema_proto.DataType
-	7,  // 9: schema_proto.Field.metadata:type_name -> schema_proto.KeyValueMetadata
-	8,  // 10: schema_proto.ArrowSchema.fields:type_name -> schema_proto.Field
-	1,  // 11: schema_proto.ArrowSchema.endianness:type_name -> schema_proto.Endianness
-	7,  // 12: schema_proto.ArrowSchema.metadata:type_name -> schema_proto.KeyValueMetadata
-	10, // 13: schema_proto.Schema.arrow_schema:type_name -> schema_proto.ArrowSchema
-	9,  // 14: schema_proto.Schema.schema_options:type_name -> schema_proto.SchemaOptions
-	15, // [15:15] is the sub-list for method output_type
-	15, // [15:15] is the sub-list for method input_type
-	15, // [15:15] is the sub-list for extension type_name
-	15, // [15:15] is the sub-list for extension extendee
-	0,  // [0:15] is the sub-list for field type_name
+	11, // 7: schema_proto.DataType.children:type_name -> schema_proto.Field
+	9,  // 8: schema_proto.Field.data_type:type_name -> schema_proto.DataType
+	10, // 9: schema_proto.Field.metadata:type_name -> schema_proto.KeyValueMetadata
+	4,  // 10: schema_proto.SchemaOptions.column_group_policy:type_name -> schema_proto.ColumnGroupPolicy
+	16, // 11: schema_proto.SchemaOptions.storage_profiles:type_name -> schema_proto.SchemaOptions.StorageProfilesEntry
+	2,  // 12: schema_proto.StorageProfile.codec:type_name -> schema_proto.Codec
+	3,  // 13: schema_proto.StorageProfile.encoding:type_name -> schema_proto.Encoding
+	11, // 14: schema_proto.ArrowSchema.fields:type_name -> schema_proto.Field
+	1,  // 15: schema_proto.ArrowSchema.endianness:type_name -> schema_proto.Endianness
+	10, // 16: schema_proto.ArrowSchema.metadata:type_name -> schema_proto.KeyValueMetadata
+	14, // 17: schema_proto.Schema.arrow_schema:type_name -> schema_proto.ArrowSchema
+	12, // 18: schema_proto.Schema.schema_options:type_name -> schema_proto.SchemaOptions
+	13, // 19: schema_proto.SchemaOptions.StorageProfilesEntry.value:type_name -> schema_proto.StorageProfile
+	20, // [20:20] is the sub-list for method output_type
+	20, // [20:20] is the sub-list for method input_type
+	20, // [20:20] is the sub-list for extension type_name
+	20, // [20:20] is the sub-list for extension extendee
+	0,  // [0:20] is the sub-list for field type_name
 }
 
 func init() { file_schema_proto_init() }
@@ -1104,7 +1450,7 @@ func file_schema_proto_init() {
 			}
 		}
 		file_schema_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ArrowSchema); i {
+			switch v := v.(*StorageProfile); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1116,6 +1462,18 @@ func file_schema_proto_init() {
 			}
 		}
 		file_schema_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ArrowSchema); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_schema_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*Schema); i {
 			case 0:
 				return &v.state
@@ -1139,8 +1497,8 @@ func file_schema_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_schema_proto_rawDesc,
-			NumEnums:      2,
-			NumMessages:   10,
+			NumEnums:      5,
+			NumMessages:   12,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
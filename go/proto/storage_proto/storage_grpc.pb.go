@@ -0,0 +1,713 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             v3.21.9
+// source: storage.proto
+
+package storage_proto
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	StorageService_OpenSpace_FullMethodName         = "/storage_proto.StorageService/OpenSpace"
+	StorageService_CloseSpace_FullMethodName        = "/storage_proto.StorageService/CloseSpace"
+	StorageService_Write_FullMethodName             = "/storage_proto.StorageService/Write"
+	StorageService_Delete_FullMethodName            = "/storage_proto.StorageService/Delete"
+	StorageService_Read_FullMethodName              = "/storage_proto.StorageService/Read"
+	StorageService_WriteBlob_FullMethodName         = "/storage_proto.StorageService/WriteBlob"
+	StorageService_ReadBlob_FullMethodName          = "/storage_proto.StorageService/ReadBlob"
+	StorageService_GetBlobByteSize_FullMethodName   = "/storage_proto.StorageService/GetBlobByteSize"
+	StorageService_GetCurrentVersion_FullMethodName = "/storage_proto.StorageService/GetCurrentVersion"
+	StorageService_Compact_FullMethodName           = "/storage_proto.StorageService/Compact"
+	StorageService_CompactDeletes_FullMethodName    = "/storage_proto.StorageService/CompactDeletes"
+	StorageService_DropColumn_FullMethodName        = "/storage_proto.StorageService/DropColumn"
+	StorageService_PinVersion_FullMethodName        = "/storage_proto.StorageService/PinVersion"
+	StorageService_ReleaseLease_FullMethodName      = "/storage_proto.StorageService/ReleaseLease"
+)
+
+// StorageServiceClient is the client API for StorageService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type StorageServiceClient interface {
+	OpenSpace(ctx context.Context, in *OpenSpaceRequest, opts ...grpc.CallOption) (*OpenSpaceResponse, error)
+	CloseSpace(ctx context.Context, in *CloseSpaceRequest, opts ...grpc.CallOption) (*CloseSpaceResponse, error)
+	Write(ctx context.Context, opts ...grpc.CallOption) (StorageService_WriteClient, error)
+	Delete(ctx context.Context, opts ...grpc.CallOption) (StorageService_DeleteClient, error)
+	Read(ctx context.Context, in *ReadRequest, opts ...grpc.CallOption) (StorageService_ReadClient, error)
+	WriteBlob(ctx context.Context, in *WriteBlobRequest, opts ...grpc.CallOption) (*WriteBlobResponse, error)
+	ReadBlob(ctx context.Context, in *ReadBlobRequest, opts ...grpc.CallOption) (StorageService_ReadBlobClient, error)
+	GetBlobByteSize(ctx context.Context, in *GetBlobByteSizeRequest, opts ...grpc.CallOption) (*GetBlobByteSizeResponse, error)
+	GetCurrentVersion(ctx context.Context, in *GetCurrentVersionRequest, opts ...grpc.CallOption) (*GetCurrentVersionResponse, error)
+	Compact(ctx context.Context, in *CompactRequest, opts ...grpc.CallOption) (*CompactResponse, error)
+	CompactDeletes(ctx context.Context, in *CompactDeletesRequest, opts ...grpc.CallOption) (*CompactDeletesResponse, error)
+	DropColumn(ctx context.Context, in *DropColumnRequest, opts ...grpc.CallOption) (*DropColumnResponse, error)
+	PinVersion(ctx context.Context, in *PinVersionRequest, opts ...grpc.CallOption) (*PinVersionResponse, error)
+	ReleaseLease(ctx context.Context, in *ReleaseLeaseRequest, opts ...grpc.CallOption) (*ReleaseLeaseResponse, error)
+}
+
+type storageServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewStorageServiceClient(cc grpc.ClientConnInterface) StorageServiceClient {
+	return &storageServiceClient{cc}
+}
+
+func (c *storageServiceClient) OpenSpace(ctx context.Context, in *OpenSpaceRequest, opts ...grpc.CallOption) (*OpenSpaceResponse, error) {
+	out := new(OpenSpaceResponse)
+	err := c.cc.Invoke(ctx, StorageService_OpenSpace_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *storageServiceClient) CloseSpace(ctx context.Context, in *CloseSpaceRequest, opts ...grpc.CallOption) (*CloseSpaceResponse, error) {
+	out := new(CloseSpaceResponse)
+	err := c.cc.Invoke(ctx, StorageService_CloseSpace_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *storageServiceClient) Write(ctx context.Context, opts ...grpc.CallOption) (StorageService_WriteClient, error) {
+	stream, err := c.cc.NewStream(ctx, &StorageService_ServiceDesc.Streams[0], StorageService_Write_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &storageServiceWriteClient{stream}
+	return x, nil
+}
+
+type StorageService_WriteClient interface {
+	Send(*WriteRequest) error
+	CloseAndRecv() (*WriteResponse, error)
+	grpc.ClientStream
+}
+
+type storageServiceWriteClient struct {
+	grpc.ClientStream
+}
+
+func (x *storageServiceWriteClient) Send(m *WriteRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *storageServiceWriteClient) CloseAndRecv() (*WriteResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(WriteResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *storageServiceClient) Delete(ctx context.Context, opts ...grpc.CallOption) (StorageService_DeleteClient, error) {
+	stream, err := c.cc.NewStream(ctx, &StorageService_ServiceDesc.Streams[1], StorageService_Delete_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &storageServiceDeleteClient{stream}
+	return x, nil
+}
+
+type StorageService_DeleteClient interface {
+	Send(*DeleteRequest) error
+	CloseAndRecv() (*DeleteResponse, error)
+	grpc.ClientStream
+}
+
+type storageServiceDeleteClient struct {
+	grpc.ClientStream
+}
+
+func (x *storageServiceDeleteClient) Send(m *DeleteRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *storageServiceDeleteClient) CloseAndRecv() (*DeleteResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(DeleteResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *storageServiceClient) Read(ctx context.Context, in *ReadRequest, opts ...grpc.CallOption) (StorageService_ReadClient, error) {
+	stream, err := c.cc.NewStream(ctx, &StorageService_ServiceDesc.Streams[2], StorageService_Read_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &storageServiceReadClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type StorageService_ReadClient interface {
+	Recv() (*ReadResponse, error)
+	grpc.ClientStream
+}
+
+type storageServiceReadClient struct {
+	grpc.ClientStream
+}
+
+func (x *storageServiceReadClient) Recv() (*ReadResponse, error) {
+	m := new(ReadResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *storageServiceClient) WriteBlob(ctx context.Context, in *WriteBlobRequest, opts ...grpc.CallOption) (*WriteBlobResponse, error) {
+	out := new(WriteBlobResponse)
+	err := c.cc.Invoke(ctx, StorageService_WriteBlob_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *storageServiceClient) ReadBlob(ctx context.Context, in *ReadBlobRequest, opts ...grpc.CallOption) (StorageService_ReadBlobClient, error) {
+	stream, err := c.cc.NewStream(ctx, &StorageService_ServiceDesc.Streams[3], StorageService_ReadBlob_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &storageServiceReadBlobClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type StorageService_ReadBlobClient interface {
+	Recv() (*ReadBlobResponse, error)
+	grpc.ClientStream
+}
+
+type storageServiceReadBlobClient struct {
+	grpc.ClientStream
+}
+
+func (x *storageServiceReadBlobClient) Recv() (*ReadBlobResponse, error) {
+	m := new(ReadBlobResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *storageServiceClient) GetBlobByteSize(ctx context.Context, in *GetBlobByteSizeRequest, opts ...grpc.CallOption) (*GetBlobByteSizeResponse, error) {
+	out := new(GetBlobByteSizeResponse)
+	err := c.cc.Invoke(ctx, StorageService_GetBlobByteSize_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *storageServiceClient) GetCurrentVersion(ctx context.Context, in *GetCurrentVersionRequest, opts ...grpc.CallOption) (*GetCurrentVersionResponse, error) {
+	out := new(GetCurrentVersionResponse)
+	err := c.cc.Invoke(ctx, StorageService_GetCurrentVersion_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *storageServiceClient) Compact(ctx context.Context, in *CompactRequest, opts ...grpc.CallOption) (*CompactResponse, error) {
+	out := new(CompactResponse)
+	err := c.cc.Invoke(ctx, StorageService_Compact_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *storageServiceClient) CompactDeletes(ctx context.Context, in *CompactDeletesRequest, opts ...grpc.CallOption) (*CompactDeletesResponse, error) {
+	out := new(CompactDeletesResponse)
+	err := c.cc.Invoke(ctx, StorageService_CompactDeletes_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *storageServiceClient) DropColumn(ctx context.Context, in *DropColumnRequest, opts ...grpc.CallOption) (*DropColumnResponse, error) {
+	out := new(DropColumnResponse)
+	err := c.cc.Invoke(ctx, StorageService_DropColumn_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *storageServiceClient) PinVersion(ctx context.Context, in *PinVersionRequest, opts ...grpc.CallOption) (*PinVersionResponse, error) {
+	out := new(PinVersionResponse)
+	err := c.cc.Invoke(ctx, StorageService_PinVersion_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *storageServiceClient) ReleaseLease(ctx context.Context, in *ReleaseLeaseRequest, opts ...grpc.CallOption) (*ReleaseLeaseResponse, error) {
+	out := new(ReleaseLeaseResponse)
+	err := c.cc.Invoke(ctx, StorageService_ReleaseLease_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// StorageServiceServer is the server API for StorageService service.
+// All implementations must embed UnimplementedStorageServiceServer
+// for forward compatibility
+type StorageServiceServer interface {
+	OpenSpace(context.Context, *OpenSpaceRequest) (*OpenSpaceResponse, error)
+	CloseSpace(context.Context, *CloseSpaceRequest) (*CloseSpaceResponse, error)
+	Write(StorageService_WriteServer) error
+	Delete(StorageService_DeleteServer) error
+	Read(*ReadRequest, StorageService_ReadServer) error
+	WriteBlob(context.Context, *WriteBlobRequest) (*WriteBlobResponse, error)
+	ReadBlob(*ReadBlobRequest, StorageService_ReadBlobServer) error
+	GetBlobByteSize(context.Context, *GetBlobByteSizeRequest) (*GetBlobByteSizeResponse, error)
+	GetCurrentVersion(context.Context, *GetCurrentVersionRequest) (*GetCurrentVersionResponse, error)
+	Compact(context.Context, *CompactRequest) (*CompactResponse, error)
+	CompactDeletes(context.Context, *CompactDeletesRequest) (*CompactDeletesResponse, error)
+	DropColumn(context.Context, *DropColumnRequest) (*DropColumnResponse, error)
+	PinVersion(context.Context, *PinVersionRequest) (*PinVersionResponse, error)
+	ReleaseLease(context.Context, *ReleaseLeaseRequest) (*ReleaseLeaseResponse, error)
+	mustEmbedUnimplementedStorageServiceServer()
+}
+
+// UnimplementedStorageServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedStorageServiceServer struct {
+}
+
+func (UnimplementedStorageServiceServer) OpenSpace(context.Context, *OpenSpaceRequest) (*OpenSpaceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method OpenSpace not implemented")
+}
+func (UnimplementedStorageServiceServer) CloseSpace(context.Context, *CloseSpaceRequest) (*CloseSpaceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CloseSpace not implemented")
+}
+func (UnimplementedStorageServiceServer) Write(StorageService_WriteServer) error {
+	return status.Errorf(codes.Unimplemented, "method Write not implemented")
+}
+func (UnimplementedStorageServiceServer) Delete(StorageService_DeleteServer) error {
+	return status.Errorf(codes.Unimplemented, "method Delete not implemented")
+}
+func (UnimplementedStorageServiceServer) Read(*ReadRequest, StorageService_ReadServer) error {
+	return status.Errorf(codes.Unimplemented, "method Read not implemented")
+}
+func (UnimplementedStorageServiceServer) WriteBlob(context.Context, *WriteBlobRequest) (*WriteBlobResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method WriteBlob not implemented")
+}
+func (UnimplementedStorageServiceServer) ReadBlob(*ReadBlobRequest, StorageService_ReadBlobServer) error {
+	return status.Errorf(codes.Unimplemented, "method ReadBlob not implemented")
+}
+func (UnimplementedStorageServiceServer) GetBlobByteSize(context.Context, *GetBlobByteSizeRequest) (*GetBlobByteSizeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBlobByteSize not implemented")
+}
+func (UnimplementedStorageServiceServer) GetCurrentVersion(context.Context, *GetCurrentVersionRequest) (*GetCurrentVersionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCurrentVersion not implemented")
+}
+func (UnimplementedStorageServiceServer) Compact(context.Context, *CompactRequest) (*CompactResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Compact not implemented")
+}
+func (UnimplementedStorageServiceServer) CompactDeletes(context.Context, *CompactDeletesRequest) (*CompactDeletesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CompactDeletes not implemented")
+}
+func (UnimplementedStorageServiceServer) DropColumn(context.Context, *DropColumnRequest) (*DropColumnResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DropColumn not implemented")
+}
+func (UnimplementedStorageServiceServer) PinVersion(context.Context, *PinVersionRequest) (*PinVersionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PinVersion not implemented")
+}
+func (UnimplementedStorageServiceServer) ReleaseLease(context.Context, *ReleaseLeaseRequest) (*ReleaseLeaseResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReleaseLease not implemented")
+}
+func (UnimplementedStorageServiceServer) mustEmbedUnimplementedStorageServiceServer() {}
+
+// UnsafeStorageServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to StorageServiceServer will
+// result in compilation errors.
+type UnsafeStorageServiceServer interface {
+	mustEmbedUnimplementedStorageServiceServer()
+}
+
+func RegisterStorageServiceServer(s grpc.ServiceRegistrar, srv StorageServiceServer) {
+	s.RegisterService(&StorageService_ServiceDesc, srv)
+}
+
+func _StorageService_OpenSpace_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OpenSpaceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StorageServiceServer).OpenSpace(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StorageService_OpenSpace_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StorageServiceServer).OpenSpace(ctx, req.(*OpenSpaceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StorageService_CloseSpace_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CloseSpaceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StorageServiceServer).CloseSpace(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StorageService_CloseSpace_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StorageServiceServer).CloseSpace(ctx, req.(*CloseSpaceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StorageService_Write_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(StorageServiceServer).Write(&storageServiceWriteServer{stream})
+}
+
+type StorageService_WriteServer interface {
+	SendAndClose(*WriteResponse) error
+	Recv() (*WriteRequest, error)
+	grpc.ServerStream
+}
+
+type storageServiceWriteServer struct {
+	grpc.ServerStream
+}
+
+func (x *storageServiceWriteServer) SendAndClose(m *WriteResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *storageServiceWriteServer) Recv() (*WriteRequest, error) {
+	m := new(WriteRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _StorageService_Delete_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(StorageServiceServer).Delete(&storageServiceDeleteServer{stream})
+}
+
+type StorageService_DeleteServer interface {
+	SendAndClose(*DeleteResponse) error
+	Recv() (*DeleteRequest, error)
+	grpc.ServerStream
+}
+
+type storageServiceDeleteServer struct {
+	grpc.ServerStream
+}
+
+func (x *storageServiceDeleteServer) SendAndClose(m *DeleteResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *storageServiceDeleteServer) Recv() (*DeleteRequest, error) {
+	m := new(DeleteRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _StorageService_Read_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ReadRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(StorageServiceServer).Read(m, &storageServiceReadServer{stream})
+}
+
+type StorageService_ReadServer interface {
+	Send(*ReadResponse) error
+	grpc.ServerStream
+}
+
+type storageServiceReadServer struct {
+	grpc.ServerStream
+}
+
+func (x *storageServiceReadServer) Send(m *ReadResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _StorageService_WriteBlob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WriteBlobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StorageServiceServer).WriteBlob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StorageService_WriteBlob_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StorageServiceServer).WriteBlob(ctx, req.(*WriteBlobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StorageService_ReadBlob_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ReadBlobRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(StorageServiceServer).ReadBlob(m, &storageServiceReadBlobServer{stream})
+}
+
+type StorageService_ReadBlobServer interface {
+	Send(*ReadBlobResponse) error
+	grpc.ServerStream
+}
+
+type storageServiceReadBlobServer struct {
+	grpc.ServerStream
+}
+
+func (x *storageServiceReadBlobServer) Send(m *ReadBlobResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _StorageService_GetBlobByteSize_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBlobByteSizeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StorageServiceServer).GetBlobByteSize(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StorageService_GetBlobByteSize_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StorageServiceServer).GetBlobByteSize(ctx, req.(*GetBlobByteSizeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StorageService_GetCurrentVersion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCurrentVersionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StorageServiceServer).GetCurrentVersion(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StorageService_GetCurrentVersion_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StorageServiceServer).GetCurrentVersion(ctx, req.(*GetCurrentVersionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StorageService_Compact_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CompactRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StorageServiceServer).Compact(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StorageService_Compact_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StorageServiceServer).Compact(ctx, req.(*CompactRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StorageService_CompactDeletes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CompactDeletesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StorageServiceServer).CompactDeletes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StorageService_CompactDeletes_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StorageServiceServer).CompactDeletes(ctx, req.(*CompactDeletesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StorageService_DropColumn_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DropColumnRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StorageServiceServer).DropColumn(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StorageService_DropColumn_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StorageServiceServer).DropColumn(ctx, req.(*DropColumnRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StorageService_PinVersion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PinVersionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StorageServiceServer).PinVersion(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StorageService_PinVersion_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StorageServiceServer).PinVersion(ctx, req.(*PinVersionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StorageService_ReleaseLease_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReleaseLeaseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StorageServiceServer).ReleaseLease(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StorageService_ReleaseLease_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StorageServiceServer).ReleaseLease(ctx, req.(*ReleaseLeaseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// StorageService_ServiceDesc is the grpc.ServiceDesc for StorageService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var StorageService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "storage_proto.StorageService",
+	HandlerType: (*StorageServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "OpenSpace",
+			Handler:    _StorageService_OpenSpace_Handler,
+		},
+		{
+			MethodName: "CloseSpace",
+			Handler:    _StorageService_CloseSpace_Handler,
+		},
+		{
+			MethodName: "WriteBlob",
+			Handler:    _StorageService_WriteBlob_Handler,
+		},
+		{
+			MethodName: "GetBlobByteSize",
+			Handler:    _StorageService_GetBlobByteSize_Handler,
+		},
+		{
+			MethodName: "GetCurrentVersion",
+			Handler:    _StorageService_GetCurrentVersion_Handler,
+		},
+		{
+			MethodName: "Compact",
+			Handler:    _StorageService_Compact_Handler,
+		},
+		{
+			MethodName: "CompactDeletes",
+			Handler:    _StorageService_CompactDeletes_Handler,
+		},
+		{
+			MethodName: "DropColumn",
+			Handler:    _StorageService_DropColumn_Handler,
+		},
+		{
+			MethodName: "PinVersion",
+			Handler:    _StorageService_PinVersion_Handler,
+		},
+		{
+			MethodName: "ReleaseLease",
+			Handler:    _StorageService_ReleaseLease_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Write",
+			Handler:       _StorageService_Write_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "Delete",
+			Handler:       _StorageService_Delete_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "Read",
+			Handler:       _StorageService_Read_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ReadBlob",
+			Handler:       _StorageService_ReadBlob_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "storage.proto",
+}
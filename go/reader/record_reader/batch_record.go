@@ -0,0 +1,160 @@
+package record_reader
+
+import (
+	"sync/atomic"
+
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/memory"
+)
+
+// BatchRecordReader regroups the records of another reader into records of exactly batchSize
+// rows, only the last record may have fewer. Records are sliced, and concatenated when a batch
+// spans several of them.
+type BatchRecordReader struct {
+	ref       int64
+	reader    array.RecordReader
+	batchSize int64
+	// pending holds the rows read but not returned yet, in order, and pendingSources where
+	// each of them was read from.
+	pending        []arrow.Record
+	pendingSources [][]Source
+	pendingRows    int64
+	rec            arrow.Record
+	recSources     []Source
+	err            error
+}
+
+// NewBatchRecordReader takes ownership of reader, batchSize must be positive.
+func NewBatchRecordReader(reader array.RecordReader, batchSize int64) *BatchRecordReader {
+	return &BatchRecordReader{ref: 1, reader: reader, batchSize: batchSize}
+}
+
+func (r *BatchRecordReader) Schema() *arrow.Schema {
+	return r.reader.Schema()
+}
+
+func (r *BatchRecordReader) Retain() {
+	atomic.AddInt64(&r.ref, 1)
+}
+
+func (r *BatchRecordReader) Release() {
+	if atomic.AddInt64(&r.ref, -1) == 0 {
+		if r.rec != nil {
+			r.rec.Release()
+			r.rec = nil
+		}
+		for _, rec := range r.pending {
+			rec.Release()
+		}
+		r.pending, r.pendingSources = nil, nil
+		r.reader.Release()
+	}
+}
+
+func (r *BatchRecordReader) Next() bool {
+	if r.rec != nil {
+		r.rec.Release()
+		r.rec = nil
+	}
+	r.recSources = nil
+	if r.err != nil {
+		return false
+	}
+	for r.pendingRows < r.batchSize && r.reader.Next() {
+		rec := r.reader.Record()
+		if rec.NumRows() == 0 {
+			continue
+		}
+		r.pending = append(r.pending, r.conform(rec))
+		r.pendingSources = append(r.pendingSources, Sources(r.reader))
+		r.pendingRows += rec.NumRows()
+	}
+	if err := r.reader.Err(); err != nil {
+		r.err = err
+		return false
+	}
+	if r.pendingRows == 0 {
+		return false
+	}
+
+	var batch []arrow.Record
+	for rows := int64(0); rows < r.batchSize && len(r.pending) > 0; {
+		rec := r.pending[0]
+		r.recSources = appendSources(r.recSources, r.pendingSources[0]...)
+		if n := r.batchSize - rows; rec.NumRows() > n {
+			batch = append(batch, rec.NewSlice(0, n))
+			r.pending[0] = rec.NewSlice(n, rec.NumRows())
+			rec.Release()
+			rows += n
+			continue
+		}
+		batch = append(batch, rec)
+		r.pending, r.pendingSources = r.pending[1:], r.pendingSources[1:]
+		rows += rec.NumRows()
+	}
+	defer func() {
+		for _, rec := range batch {
+			rec.Release()
+		}
+	}()
+	r.rec, r.err = concatRecords(r.Schema(), batch)
+	if r.err != nil {
+		return false
+	}
+	r.pendingRows -= r.rec.NumRows()
+	return true
+}
+
+// conform returns the columns of rec in the order of the schema of the reader, the records of
+// the file readers may have their fields in another order and may be released by them.
+func (r *BatchRecordReader) conform(rec arrow.Record) arrow.Record {
+	schema := r.Schema()
+	columns := make([]arrow.Array, 0, len(schema.Fields()))
+	for _, field := range schema.Fields() {
+		columns = append(columns, rec.Column(rec.Schema().FieldIndices(field.Name)[0]))
+	}
+	return array.NewRecord(schema, columns, rec.NumRows())
+}
+
+// concatRecords returns the rows of records, which all have schema, in a single record.
+func concatRecords(schema *arrow.Schema, records []arrow.Record) (arrow.Record, error) {
+	if len(records) == 1 {
+		records[0].Retain()
+		return records[0], nil
+	}
+	var rows int64
+	for _, rec := range records {
+		rows += rec.NumRows()
+	}
+	columns := make([]arrow.Array, 0, len(schema.Fields()))
+	defer func() {
+		for _, column := range columns {
+			column.Release()
+		}
+	}()
+	for i := range schema.Fields() {
+		chunks := make([]arrow.Array, 0, len(records))
+		for _, rec := range records {
+			chunks = append(chunks, rec.Column(i))
+		}
+		column, err := array.Concatenate(chunks, memory.DefaultAllocator)
+		if err != nil {
+			return nil, err
+		}
+		columns = append(columns, column)
+	}
+	return array.NewRecord(schema, columns, rows), nil
+}
+
+func (r *BatchRecordReader) Record() arrow.Record {
+	return r.rec
+}
+
+func (r *BatchRecordReader) Err() error {
+	return r.err
+}
+
+func (r *BatchRecordReader) Sources() []Source {
+	return r.recSources
+}
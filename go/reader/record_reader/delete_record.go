@@ -0,0 +1,159 @@
+package record_reader
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/compute"
+	"github.com/apache/arrow/go/v12/arrow/memory"
+	"github.com/milvus-io/milvus-storage/go/common/constant"
+	"github.com/milvus-io/milvus-storage/go/common/utils"
+	"github.com/milvus-io/milvus-storage/go/file/fragment"
+	"github.com/milvus-io/milvus-storage/go/storage/schema"
+)
+
+// DeleteRecordReader removes the rows of another reader that are deleted. A delete only
+// removes the rows of its primary key whose version is not newer than the delete, so a key
+// written again after it was deleted stays visible. With includeDeleted the rows are kept and
+// flagged in a constant.DeletedFieldName column instead.
+type DeleteRecordReader struct {
+	ref            int64
+	reader         array.RecordReader
+	schema         *schema.Schema
+	deletes        fragment.DeleteFragmentVector
+	includeDeleted bool
+	output         *arrow.Schema
+	rec            arrow.Record
+	err            error
+}
+
+// NewDeleteRecordReader takes ownership of reader, which must read the primary and version
+// columns. Records are projected to columns.
+func NewDeleteRecordReader(
+	reader array.RecordReader,
+	s *schema.Schema,
+	deletes fragment.DeleteFragmentVector,
+	columns []string,
+	includeDeleted bool,
+) *DeleteRecordReader {
+	output := utils.ProjectSchema(s.Schema(), columns)
+	if includeDeleted {
+		fields := append(output.Fields(), arrow.Field{Name: constant.DeletedFieldName, Type: arrow.FixedWidthTypes.Boolean})
+		output = arrow.NewSchema(fields, nil)
+	}
+	return &DeleteRecordReader{
+		ref:            1,
+		reader:         reader,
+		schema:         s,
+		deletes:        deletes,
+		includeDeleted: includeDeleted,
+		output:         output,
+	}
+}
+
+func (r *DeleteRecordReader) Schema() *arrow.Schema {
+	return r.output
+}
+
+func (r *DeleteRecordReader) Retain() {
+	atomic.AddInt64(&r.ref, 1)
+}
+
+func (r *DeleteRecordReader) Release() {
+	if atomic.AddInt64(&r.ref, -1) == 0 {
+		if r.rec != nil {
+			r.rec.Release()
+			r.rec = nil
+		}
+		r.reader.Release()
+	}
+}
+
+func (r *DeleteRecordReader) Next() bool {
+	if r.rec != nil {
+		r.rec.Release()
+		r.rec = nil
+	}
+	if r.err != nil {
+		return false
+	}
+	for r.reader.Next() {
+		rec, err := r.apply(r.reader.Record())
+		if err != nil {
+			r.err = err
+			return false
+		}
+		if rec == nil {
+			continue
+		}
+		r.rec = rec
+		return true
+	}
+	r.err = r.reader.Err()
+	return false
+}
+
+// apply projects rec to the output columns and drops its deleted rows, it returns nil if
+// every row is deleted.
+func (r *DeleteRecordReader) apply(rec arrow.Record) (arrow.Record, error) {
+	options := r.schema.Options()
+	pkColumn := rec.Column(rec.Schema().FieldIndices(options.PrimaryColumn)[0])
+	var versionColumn *array.Int64
+	if options.HasVersionColumn() {
+		versionColumn = rec.Column(rec.Schema().FieldIndices(options.VersionColumn)[0]).(*array.Int64)
+	}
+
+	builder := array.NewBooleanBuilder(memory.DefaultAllocator)
+	defer builder.Release()
+	var kept int64
+	for i := 0; i < int(rec.NumRows()); i++ {
+		var version int64
+		if versionColumn != nil {
+			version = versionColumn.Value(i)
+		}
+		deleted := r.deletes.IsDeleted(fragment.PkValue(pkColumn, i), version)
+		if !deleted {
+			kept++
+		}
+		if r.includeDeleted {
+			builder.Append(deleted)
+		} else {
+			builder.Append(!deleted)
+		}
+	}
+	// mask is the deleted column with includeDeleted, the rows to keep otherwise
+	mask := builder.NewArray()
+	defer mask.Release()
+	if kept == 0 && !r.includeDeleted {
+		return nil, nil
+	}
+
+	columns := make([]arrow.Array, 0, len(r.output.Fields()))
+	for _, field := range r.output.Fields() {
+		if r.includeDeleted && field.Name == constant.DeletedFieldName {
+			columns = append(columns, mask)
+			continue
+		}
+		columns = append(columns, rec.Column(rec.Schema().FieldIndices(field.Name)[0]))
+	}
+	projected := array.NewRecord(r.output, columns, rec.NumRows())
+	if r.includeDeleted || kept == rec.NumRows() {
+		return projected, nil
+	}
+	defer projected.Release()
+	return compute.FilterRecordBatch(context.Background(), projected, mask, compute.DefaultFilterOptions())
+}
+
+func (r *DeleteRecordReader) Record() arrow.Record {
+	return r.rec
+}
+
+func (r *DeleteRecordReader) Err() error {
+	return r.err
+}
+
+func (r *DeleteRecordReader) Sources() []Source {
+	return Sources(r.reader)
+}
@@ -0,0 +1,131 @@
+package record_reader
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/compute"
+	"github.com/milvus-io/milvus-storage/go/filter"
+)
+
+// ExpressionRecordReader removes the rows of another reader that do not pass expression
+// filters, which are evaluated on the records of the reader.
+type ExpressionRecordReader struct {
+	ref     int64
+	reader  array.RecordReader
+	filters []*filter.ExpressionFilter
+	output  *arrow.Schema
+	rec     arrow.Record
+	err     error
+}
+
+// NewExpressionRecordReader takes ownership of reader, which must read the columns of filters.
+// Records are projected to columns.
+func NewExpressionRecordReader(reader array.RecordReader, filters []*filter.ExpressionFilter, columns []string) *ExpressionRecordReader {
+	fields := make([]arrow.Field, 0, len(columns))
+	for _, column := range columns {
+		if indices := reader.Schema().FieldIndices(column); len(indices) > 0 {
+			fields = append(fields, reader.Schema().Field(indices[0]))
+		}
+	}
+	return &ExpressionRecordReader{
+		ref:     1,
+		reader:  reader,
+		filters: filters,
+		output:  arrow.NewSchema(fields, nil),
+	}
+}
+
+func (r *ExpressionRecordReader) Schema() *arrow.Schema {
+	return r.output
+}
+
+func (r *ExpressionRecordReader) Retain() {
+	atomic.AddInt64(&r.ref, 1)
+}
+
+func (r *ExpressionRecordReader) Release() {
+	if atomic.AddInt64(&r.ref, -1) == 0 {
+		if r.rec != nil {
+			r.rec.Release()
+			r.rec = nil
+		}
+		r.reader.Release()
+	}
+}
+
+func (r *ExpressionRecordReader) Next() bool {
+	if r.rec != nil {
+		r.rec.Release()
+		r.rec = nil
+	}
+	if r.err != nil {
+		return false
+	}
+	for r.reader.Next() {
+		rec, err := r.apply(r.reader.Record())
+		if err != nil {
+			r.err = err
+			return false
+		}
+		if rec.NumRows() == 0 {
+			rec.Release()
+			continue
+		}
+		r.rec = rec
+		return true
+	}
+	r.err = r.reader.Err()
+	return false
+}
+
+// apply projects rec to the output columns and drops the rows that do not pass the filters.
+func (r *ExpressionRecordReader) apply(rec arrow.Record) (arrow.Record, error) {
+	ctx := context.Background()
+	var mask arrow.Array
+	defer func() {
+		if mask != nil {
+			mask.Release()
+		}
+	}()
+	for _, f := range r.filters {
+		filterMask, err := f.Mask(ctx, rec)
+		if err != nil {
+			return nil, err
+		}
+		if mask == nil {
+			mask = filterMask
+			continue
+		}
+		combined, err := compute.CallFunction(ctx, "and", nil, compute.NewDatumWithoutOwning(mask), compute.NewDatumWithoutOwning(filterMask))
+		filterMask.Release()
+		if err != nil {
+			return nil, err
+		}
+		mask.Release()
+		mask = combined.(*compute.ArrayDatum).MakeArray()
+		combined.Release()
+	}
+
+	columns := make([]arrow.Array, 0, len(r.output.Fields()))
+	for _, field := range r.output.Fields() {
+		columns = append(columns, rec.Column(rec.Schema().FieldIndices(field.Name)[0]))
+	}
+	projected := array.NewRecord(r.output, columns, rec.NumRows())
+	defer projected.Release()
+	return compute.FilterRecordBatch(ctx, projected, mask, compute.DefaultFilterOptions())
+}
+
+func (r *ExpressionRecordReader) Record() arrow.Record {
+	return r.rec
+}
+
+func (r *ExpressionRecordReader) Err() error {
+	return r.err
+}
+
+func (r *ExpressionRecordReader) Sources() []Source {
+	return Sources(r.reader)
+}
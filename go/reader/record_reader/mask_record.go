@@ -0,0 +1,196 @@
+package record_reader
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/memory"
+	"github.com/milvus-io/milvus-storage/go/storage/options/option"
+)
+
+var ErrUnsupportedMask = errors.New("unsupported mask")
+
+// MaskRecordReader redacts the columns of another reader according to mask rules.
+type MaskRecordReader struct {
+	ref    int64
+	reader array.RecordReader
+	rules  map[string]option.MaskRule
+	schema *arrow.Schema
+	rec    arrow.Record
+	err    error
+}
+
+// NewMaskRecordReader takes ownership of reader. Rules for columns not in the schema of reader
+// are ignored.
+func NewMaskRecordReader(reader array.RecordReader, rules map[string]option.MaskRule) (*MaskRecordReader, error) {
+	for _, field := range reader.Schema().Fields() {
+		if rule, ok := rules[field.Name]; ok {
+			if err := checkMaskRule(field, rule); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return &MaskRecordReader{
+		ref:    1,
+		reader: reader,
+		rules:  rules,
+		schema: maskSchema(reader.Schema(), rules),
+	}, nil
+}
+
+// maskSchema marks the columns masked with MaskNull as nullable.
+func maskSchema(schema *arrow.Schema, rules map[string]option.MaskRule) *arrow.Schema {
+	fields := make([]arrow.Field, 0, len(schema.Fields()))
+	for _, field := range schema.Fields() {
+		if rule, ok := rules[field.Name]; ok && rule.Type == option.MaskNull {
+			field.Nullable = true
+		}
+		fields = append(fields, field)
+	}
+	metadata := schema.Metadata()
+	return arrow.NewSchema(fields, &metadata)
+}
+
+func checkMaskRule(field arrow.Field, rule option.MaskRule) error {
+	switch rule.Type {
+	case option.MaskNull:
+		return nil
+	case option.MaskHash:
+		if field.Type.ID() == arrow.STRING || field.Type.ID() == arrow.BINARY {
+			return nil
+		}
+	case option.MaskPartial:
+		if field.Type.ID() == arrow.STRING {
+			return nil
+		}
+	}
+	return fmt.Errorf("mask %d on column %s of type %s: %w", rule.Type, field.Name, field.Type, ErrUnsupportedMask)
+}
+
+func (r *MaskRecordReader) Schema() *arrow.Schema {
+	return r.schema
+}
+
+func (r *MaskRecordReader) Retain() {
+	atomic.AddInt64(&r.ref, 1)
+}
+
+func (r *MaskRecordReader) Release() {
+	if atomic.AddInt64(&r.ref, -1) == 0 {
+		if r.rec != nil {
+			r.rec.Release()
+			r.rec = nil
+		}
+		r.reader.Release()
+	}
+}
+
+func (r *MaskRecordReader) Next() bool {
+	if r.rec != nil {
+		r.rec.Release()
+		r.rec = nil
+	}
+	if !r.reader.Next() {
+		r.err = r.reader.Err()
+		return false
+	}
+	rec := r.reader.Record()
+	columns := make([]arrow.Array, rec.NumCols())
+	for i, col := range rec.Columns() {
+		rule, ok := r.rules[rec.ColumnName(i)]
+		if !ok {
+			col.Retain()
+			columns[i] = col
+			continue
+		}
+		columns[i] = maskArray(col, rule)
+	}
+	r.rec = array.NewRecord(maskSchema(rec.Schema(), r.rules), columns, rec.NumRows())
+	for _, col := range columns {
+		col.Release()
+	}
+	return true
+}
+
+func (r *MaskRecordReader) Record() arrow.Record {
+	return r.rec
+}
+
+func (r *MaskRecordReader) Err() error {
+	return r.err
+}
+
+// maskArray must only be called with rules accepted by checkMaskRule.
+func maskArray(arr arrow.Array, rule option.MaskRule) arrow.Array {
+	switch rule.Type {
+	case option.MaskHash:
+		if values, ok := arr.(*array.String); ok {
+			b := array.NewStringBuilder(memory.DefaultAllocator)
+			defer b.Release()
+			for i := 0; i < values.Len(); i++ {
+				if values.IsNull(i) {
+					b.AppendNull()
+					continue
+				}
+				sum := saltedHash(rule.Salt, []byte(values.Value(i)))
+				b.Append(hex.EncodeToString(sum[:]))
+			}
+			return b.NewArray()
+		}
+		values := arr.(*array.Binary)
+		b := array.NewBinaryBuilder(memory.DefaultAllocator, arrow.BinaryTypes.Binary)
+		defer b.Release()
+		for i := 0; i < values.Len(); i++ {
+			if values.IsNull(i) {
+				b.AppendNull()
+				continue
+			}
+			sum := saltedHash(rule.Salt, values.Value(i))
+			b.Append(sum[:])
+		}
+		return b.NewArray()
+	case option.MaskPartial:
+		values := arr.(*array.String)
+		b := array.NewStringBuilder(memory.DefaultAllocator)
+		defer b.Release()
+		for i := 0; i < values.Len(); i++ {
+			if values.IsNull(i) {
+				b.AppendNull()
+				continue
+			}
+			b.Append(maskPartial(values.Value(i), rule.KeepPrefix, rule.KeepSuffix))
+		}
+		return b.NewArray()
+	default:
+		return array.MakeArrayOfNull(memory.DefaultAllocator, arr.DataType(), arr.Len())
+	}
+}
+
+func saltedHash(salt, value []byte) [sha256.Size]byte {
+	return sha256.Sum256(append(append([]byte(nil), salt...), value...))
+}
+
+func maskPartial(value string, keepPrefix, keepSuffix int) string {
+	runes := []rune(value)
+	if keepPrefix < 0 {
+		keepPrefix = 0
+	}
+	if keepSuffix < 0 {
+		keepSuffix = 0
+	}
+	if keepPrefix+keepSuffix >= len(runes) {
+		// keeping everything would reveal short values entirely
+		return strings.Repeat("*", len(runes))
+	}
+	return string(runes[:keepPrefix]) + strings.Repeat("*", len(runes)-keepPrefix-keepSuffix) + string(runes[len(runes)-keepSuffix:])
+}
+
+func (r *MaskRecordReader) Sources() []Source {
+	return Sources(r.reader)
+}
@@ -1,61 +1,400 @@
 package record_reader
 
 import (
+	"container/heap"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync/atomic"
+
 	"github.com/apache/arrow/go/v12/arrow"
-	"github.com/milvus-io/milvus-storage/go/file/fragment"
-	"github.com/milvus-io/milvus-storage/go/io/fs"
-	"github.com/milvus-io/milvus-storage/go/storage/options/option"
-	"github.com/milvus-io/milvus-storage/go/storage/schema"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/compute"
+	"github.com/apache/arrow/go/v12/arrow/memory"
+	"github.com/milvus-io/milvus-storage/go/common/constant"
 )
 
+var ErrUnsupportedOrderColumn = errors.New("unsupported order column")
+
+// MergeRecordReader returns the rows of several readers sorted by a key column. The rows of
+// each reader are loaded and sorted in memory, then the readers are merged. Rows with equal
+// keys are returned in the order of the readers.
 type MergeRecordReader struct {
-	ref             int64
-	schema          *schema.Schema
-	options         *option.ReadOptions
-	fs              fs.Fs
-	scalarFragments fragment.FragmentVector
-	vectorFragments fragment.FragmentVector
-	deleteFragments fragment.DeleteFragmentVector
-	record          arrow.Record
+	ref     int64
+	schema  *arrow.Schema
+	key     string
+	readers []array.RecordReader
+	sources []*mergeSource
+	heap    mergeHeap
+	loaded  bool
+	rec     arrow.Record
+	// recSources are the sources of the rows of rec.
+	recSources []Source
+	err        error
+}
+
+// mergeSource is the sorted rows of one reader and the position of the next row to return.
+type mergeSource struct {
+	rec     arrow.Record
+	key     arrow.Array
+	row     int64
+	sources []Source
+}
+
+// NewMergeRecordReader takes ownership of readers, which must all read the key column and the
+// columns of schema.
+func NewMergeRecordReader(schema *arrow.Schema, readers []array.RecordReader, key string) *MergeRecordReader {
+	return &MergeRecordReader{
+		ref:     1,
+		schema:  schema,
+		key:     key,
+		readers: readers,
+	}
+}
+
+// CheckOrderColumn returns an error if rows can not be ordered by a column of type dataType.
+func CheckOrderColumn(column string, dataType arrow.DataType) error {
+	switch dataType.ID() {
+	case arrow.INT8, arrow.INT16, arrow.INT32, arrow.INT64, arrow.UINT8, arrow.UINT16, arrow.UINT32, arrow.UINT64,
+		arrow.FLOAT32, arrow.FLOAT64, arrow.STRING, arrow.BOOL:
+		return nil
+	default:
+		return fmt.Errorf("order by %s column %s: %w", dataType, column, ErrUnsupportedOrderColumn)
+	}
+}
+
+func (r *MergeRecordReader) Schema() *arrow.Schema {
+	return r.schema
+}
+
+func (r *MergeRecordReader) Retain() {
+	atomic.AddInt64(&r.ref, 1)
+}
+
+func (r *MergeRecordReader) Release() {
+	if atomic.AddInt64(&r.ref, -1) == 0 {
+		if r.rec != nil {
+			r.rec.Release()
+			r.rec = nil
+		}
+		for _, reader := range r.readers {
+			reader.Release()
+		}
+		r.readers = nil
+		for _, source := range r.sources {
+			source.rec.Release()
+		}
+		r.sources = nil
+	}
+}
+
+func (r *MergeRecordReader) Record() arrow.Record {
+	return r.rec
+}
+
+func (r *MergeRecordReader) Err() error {
+	return r.err
+}
+
+func (r *MergeRecordReader) Next() bool {
+	if r.rec != nil {
+		r.rec.Release()
+		r.rec = nil
+	}
+	r.recSources = nil
+	if r.err != nil {
+		return false
+	}
+	if !r.loaded {
+		r.loaded = true
+		if r.err = r.load(); r.err != nil {
+			return false
+		}
+	}
+	if r.heap.Len() == 0 {
+		return false
+	}
+
+	// runs of consecutive rows of the same source are sliced together
+	type run struct {
+		source     int
+		begin, end int64
+	}
+	var (
+		runs []run
+		rows int64
+	)
+	for rows < constant.ReadBatchSize && r.heap.Len() > 0 {
+		i := r.heap.indices[0]
+		source := r.sources[i]
+		if n := len(runs); n > 0 && runs[n-1].source == i {
+			runs[n-1].end++
+		} else {
+			runs = append(runs, run{source: i, begin: source.row, end: source.row + 1})
+		}
+		rows++
+		source.row++
+		if source.row == source.rec.NumRows() {
+			heap.Pop(&r.heap)
+		} else {
+			heap.Fix(&r.heap, 0)
+		}
+	}
+
+	columns := make([]arrow.Array, 0, len(r.schema.Fields()))
+	defer func() {
+		for _, column := range columns {
+			column.Release()
+		}
+	}()
+	for _, field := range r.schema.Fields() {
+		slices := make([]arrow.Array, 0, len(runs))
+		for _, run := range runs {
+			source := r.sources[run.source]
+			column := source.rec.Column(source.rec.Schema().FieldIndices(field.Name)[0])
+			slices = append(slices, array.NewSlice(column, run.begin, run.end))
+		}
+		column, err := array.Concatenate(slices, memory.DefaultAllocator)
+		for _, slice := range slices {
+			slice.Release()
+		}
+		if err != nil {
+			r.err = fmt.Errorf("merge records: %w", err)
+			return false
+		}
+		columns = append(columns, column)
+	}
+	r.rec = array.NewRecord(r.schema, columns, rows)
+	for _, run := range runs {
+		r.recSources = appendSources(r.recSources, r.sources[run.source].sources...)
+	}
+	return true
+}
+
+func (r *MergeRecordReader) Sources() []Source {
+	return r.recSources
+}
+
+// load reads and sorts the rows of every reader.
+func (r *MergeRecordReader) load() error {
+	for _, reader := range r.readers {
+		rec, sources, err := readAll(reader)
+		if err != nil {
+			return err
+		}
+		if rec == nil {
+			continue
+		}
+		if rec, err = sortRecord(rec, r.key); err != nil {
+			return err
+		}
+		r.sources = append(r.sources, &mergeSource{rec: rec, key: rec.Column(rec.Schema().FieldIndices(r.key)[0]), sources: sources})
+	}
+	for _, reader := range r.readers {
+		reader.Release()
+	}
+	r.readers = nil
+
+	r.heap = mergeHeap{sources: r.sources}
+	for i := range r.sources {
+		r.heap.indices = append(r.heap.indices, i)
+	}
+	heap.Init(&r.heap)
+	return nil
+}
+
+// readAll concatenates the records of reader and returns their sources, it returns nil if
+// there are none.
+func readAll(reader array.RecordReader) (arrow.Record, []Source, error) {
+	var (
+		records []arrow.Record
+		sources []Source
+	)
+	defer func() {
+		for _, rec := range records {
+			rec.Release()
+		}
+	}()
+	for reader.Next() {
+		rec := reader.Record()
+		if rec.NumRows() == 0 {
+			continue
+		}
+		// the record may be released by the file reader, only its columns are retained
+		records = append(records, array.NewRecord(rec.Schema(), rec.Columns(), rec.NumRows()))
+		sources = appendSources(sources, Sources(reader)...)
+	}
+	if err := reader.Err(); err != nil {
+		return nil, nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil, nil
+	}
+	if len(records) == 1 {
+		records[0].Retain()
+		return records[0], sources, nil
+	}
+
+	schema := records[0].Schema()
+	columns := make([]arrow.Array, 0, len(schema.Fields()))
+	defer func() {
+		for _, column := range columns {
+			column.Release()
+		}
+	}()
+	var rows int64
+	for _, rec := range records {
+		rows += rec.NumRows()
+	}
+	for i := range schema.Fields() {
+		chunks := make([]arrow.Array, 0, len(records))
+		for _, rec := range records {
+			chunks = append(chunks, rec.Column(i))
+		}
+		column, err := array.Concatenate(chunks, memory.DefaultAllocator)
+		if err != nil {
+			return nil, nil, fmt.Errorf("merge records: %w", err)
+		}
+		columns = append(columns, column)
+	}
+	return array.NewRecord(schema, columns, rows), sources, nil
+}
+
+// sortRecord returns rec stably sorted by key, it takes ownership of rec.
+func sortRecord(rec arrow.Record, key string) (arrow.Record, error) {
+	keyColumn := rec.Column(rec.Schema().FieldIndices(key)[0])
+	order := make([]int64, rec.NumRows())
+	sorted := true
+	for i := range order {
+		order[i] = int64(i)
+		if i > 0 && compareKeys(keyColumn, i, keyColumn, i-1) < 0 {
+			sorted = false
+		}
+	}
+	if sorted {
+		return rec, nil
+	}
+	defer rec.Release()
+	sort.SliceStable(order, func(i, j int) bool {
+		return compareKeys(keyColumn, int(order[i]), keyColumn, int(order[j])) < 0
+	})
+
+	builder := array.NewInt64Builder(memory.DefaultAllocator)
+	defer builder.Release()
+	builder.AppendValues(order, nil)
+	indices := builder.NewArray()
+	defer indices.Release()
+	columns := make([]arrow.Array, 0, rec.NumCols())
+	defer func() {
+		for _, column := range columns {
+			column.Release()
+		}
+	}()
+	for _, column := range rec.Columns() {
+		taken, err := compute.TakeArray(context.Background(), column, indices)
+		if err != nil {
+			return nil, fmt.Errorf("sort records: %w", err)
+		}
+		columns = append(columns, taken)
+	}
+	return array.NewRecord(rec.Schema(), columns, rec.NumRows()), nil
+}
+
+// compareKeys compares row i of a to row j of b, which have the same type accepted by
+// CheckOrderColumn. Nulls are less than any value.
+func compareKeys(a arrow.Array, i int, b arrow.Array, j int) int {
+	if a.IsNull(i) || b.IsNull(j) {
+		switch {
+		case a.IsNull(i) && b.IsNull(j):
+			return 0
+		case a.IsNull(i):
+			return -1
+		default:
+			return 1
+		}
+	}
+	switch c := a.(type) {
+	case *array.Int8:
+		return compareOrdered(c.Value(i), b.(*array.Int8).Value(j))
+	case *array.Int16:
+		return compareOrdered(c.Value(i), b.(*array.Int16).Value(j))
+	case *array.Int32:
+		return compareOrdered(c.Value(i), b.(*array.Int32).Value(j))
+	case *array.Int64:
+		return compareOrdered(c.Value(i), b.(*array.Int64).Value(j))
+	case *array.Uint8:
+		return compareOrdered(c.Value(i), b.(*array.Uint8).Value(j))
+	case *array.Uint16:
+		return compareOrdered(c.Value(i), b.(*array.Uint16).Value(j))
+	case *array.Uint32:
+		return compareOrdered(c.Value(i), b.(*array.Uint32).Value(j))
+	case *array.Uint64:
+		return compareOrdered(c.Value(i), b.(*array.Uint64).Value(j))
+	case *array.Float32:
+		return compareOrdered(c.Value(i), b.(*array.Float32).Value(j))
+	case *array.Float64:
+		return compareOrdered(c.Value(i), b.(*array.Float64).Value(j))
+	case *array.String:
+		return compareOrdered(c.Value(i), b.(*array.String).Value(j))
+	case *array.Boolean:
+		x, y := c.Value(i), b.(*array.Boolean).Value(j)
+		switch {
+		case x == y:
+			return 0
+		case !x:
+			return -1
+		default:
+			return 1
+		}
+	default:
+		panic(fmt.Sprintf("unsupported order column type %s", a.DataType()))
+	}
+}
+
+type ordered interface {
+	~int8 | ~int16 | ~int32 | ~int64 | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~float32 | ~float64 | ~string
 }
 
-func (m MergeRecordReader) Retain() {
-	//TODO implement me
-	panic("implement me")
+func compareOrdered[T ordered](x, y T) int {
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	default:
+		return 0
+	}
 }
 
-func (m MergeRecordReader) Release() {
-	//TODO implement me
-	panic("implement me")
+// mergeHeap orders sources by their next row, ties by source index.
+type mergeHeap struct {
+	sources []*mergeSource
+	indices []int
 }
 
-func (m MergeRecordReader) Schema() *arrow.Schema {
-	//TODO implement me
-	panic("implement me")
+func (h *mergeHeap) Len() int {
+	return len(h.indices)
 }
 
-func (m MergeRecordReader) Next() bool {
-	//TODO implement me
-	panic("implement me")
+func (h *mergeHeap) Less(i, j int) bool {
+	a, b := h.sources[h.indices[i]], h.sources[h.indices[j]]
+	if c := compareKeys(a.key, int(a.row), b.key, int(b.row)); c != 0 {
+		return c < 0
+	}
+	return h.indices[i] < h.indices[j]
 }
 
-func (m MergeRecordReader) Record() arrow.Record {
-	//TODO implement me
-	panic("implement me")
+func (h *mergeHeap) Swap(i, j int) {
+	h.indices[i], h.indices[j] = h.indices[j], h.indices[i]
 }
 
-func (m MergeRecordReader) Err() error {
-	//TODO implement me
-	panic("implement me")
+func (h *mergeHeap) Push(x any) {
+	h.indices = append(h.indices, x.(int))
 }
 
-func NewMergeRecordReader(
-	s *schema.Schema,
-	options *option.ReadOptions,
-	f fs.Fs,
-	scalarFragment fragment.FragmentVector,
-	vectorFragment fragment.FragmentVector,
-	deleteFragments fragment.DeleteFragmentVector) *MergeRecordReader {
-	//TODO implement me
-	panic("implement me")
+func (h *mergeHeap) Pop() any {
+	last := h.indices[len(h.indices)-1]
+	h.indices = h.indices[:len(h.indices)-1]
+	return last
 }
@@ -1,55 +1,154 @@
 package record_reader
 
 import (
+	"fmt"
+
 	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/milvus-io/milvus-storage/go/common/constant"
+	"github.com/milvus-io/milvus-storage/go/common/utils"
 	"github.com/milvus-io/milvus-storage/go/file/fragment"
-	"github.com/milvus-io/milvus-storage/go/filter"
 	"github.com/milvus-io/milvus-storage/go/io/fs"
 	"github.com/milvus-io/milvus-storage/go/storage/manifest"
 	"github.com/milvus-io/milvus-storage/go/storage/options/option"
 	"github.com/milvus-io/milvus-storage/go/storage/schema"
 )
 
+// MakeRecordReader returns a reader over the data of m. Reads that only touch scalar or only
+// vector columns scan one kind of files, others join the paired files of each fragment.
+// Rows removed by deleteFragments are skipped, or flagged when options.IncludeDeleted is set.
+// Scans of scalar columns skip them by offset instead when deletedRows covers every scalar
+// file, deletedRows may be nil. Expression filters are applied last, then the rows are
+// regrouped into options.BatchSize rows per record if it is set.
 func MakeRecordReader(
 	m *manifest.Manifest,
 	s *schema.Schema,
 	f fs.Fs,
 	deleteFragments fragment.DeleteFragmentVector,
+	deletedRows fragment.DeletedRows,
 	options *option.ReadOptions,
-) array.RecordReader {
-	relatedColumns := make([]string, 0)
-	for _, column := range options.Columns {
-		relatedColumns = append(relatedColumns, column)
+) (array.RecordReader, error) {
+	reader, err := makeExpressionRecordReader(m, s, f, deleteFragments, deletedRows, options)
+	if err != nil || options.BatchSize <= 0 {
+		return reader, err
 	}
+	return NewBatchRecordReader(reader, options.BatchSize), nil
+}
 
-	for _, filter := range options.Filters {
-		relatedColumns = append(relatedColumns, filter.GetColumnName())
+func makeExpressionRecordReader(
+	m *manifest.Manifest,
+	s *schema.Schema,
+	f fs.Fs,
+	deleteFragments fragment.DeleteFragmentVector,
+	deletedRows fragment.DeletedRows,
+	options *option.ReadOptions,
+) (array.RecordReader, error) {
+	if len(options.ExpressionFilters) == 0 {
+		return makeDeleteRecordReader(m, s, f, deleteFragments, deletedRows, options)
+	}
+	// the columns of the filters are dropped from the output unless requested
+	columns := append([]string(nil), options.OutputColumns()...)
+	if options.IncludeDeleted {
+		columns = append(columns, constant.DeletedFieldName)
+	}
+	filters := options.ExpressionFilters
+	options = options.Clone()
+	options.ExpressionFilters = nil
+	for _, expressionFilter := range filters {
+		for _, column := range expressionFilter.Columns() {
+			if !options.HasColumn(column) {
+				options.AddColumn(column)
+			}
+		}
+	}
+	reader, err := makeDeleteRecordReader(m, s, f, deleteFragments, deletedRows, options)
+	if err != nil {
+		return nil, err
+	}
+	return NewExpressionRecordReader(reader, filters, columns), nil
+}
+
+func makeDeleteRecordReader(
+	m *manifest.Manifest,
+	s *schema.Schema,
+	f fs.Fs,
+	deleteFragments fragment.DeleteFragmentVector,
+	deletedRows fragment.DeletedRows,
+	options *option.ReadOptions,
+) (array.RecordReader, error) {
+	if len(deleteFragments) == 0 && !options.IncludeDeleted {
+		return makeRecordReader(m, s, f, deleteFragments, options)
+	}
+	scalarData := m.GetScalarFragments()
+	if !options.IncludeDeleted && options.OrderBy.Type != option.OrderKey &&
+		onlyContainScalarColumns(s, relatedColumns(options)) && deletedRows.Covers(fragment.ToFilesVector(scalarData)) {
+		reader := NewScanRecordReader(s, options, f, scalarData, deleteFragments)
+		reader.deletedRows = deletedRows
+		reader.progress.FilesSkipped = int64(len(fragment.ToFilesVector(m.GetVectorFragments())))
+		return reader, nil
+	}
+	// deletes are matched on the primary and version columns, they are dropped from the
+	// output unless requested
+	columns := options.OutputColumns()
+	options = options.Clone()
+	for _, column := range []string{s.Options().PrimaryColumn, s.Options().VersionColumn} {
+		if column != "" && !options.HasColumn(column) {
+			options.AddColumn(column)
+		}
 	}
+	reader, err := makeRecordReader(m, s, f, deleteFragments, options)
+	if err != nil {
+		return nil, err
+	}
+	return NewDeleteRecordReader(reader, s, deleteFragments, columns, options.IncludeDeleted), nil
+}
 
+func makeRecordReader(
+	m *manifest.Manifest,
+	s *schema.Schema,
+	f fs.Fs,
+	deleteFragments fragment.DeleteFragmentVector,
+	options *option.ReadOptions,
+) (array.RecordReader, error) {
+	if options.OrderBy.Type == option.OrderKey {
+		return makeMergeRecordReader(m, s, f, deleteFragments, options)
+	}
+	relatedColumns := relatedColumns(options)
 	scalarData := m.GetScalarFragments()
 	vectorData := m.GetVectorFragments()
 
 	onlyScalar := onlyContainScalarColumns(s, relatedColumns)
 	onlyVector := onlyContainVectorColumns(s, relatedColumns)
 
+	// files of the other kind are never opened
 	if onlyScalar || onlyVector {
-		var dataFragments fragment.FragmentVector
-		if onlyScalar {
-			dataFragments = scalarData
-		} else {
-			dataFragments = vectorData
+		dataFragments, skipped := scalarData, vectorData
+		if !onlyScalar {
+			dataFragments, skipped = vectorData, scalarData
 		}
-		return NewScanRecordReader(s, options, f, dataFragments, deleteFragments)
+		reader := NewScanRecordReader(s, options, f, dataFragments, deleteFragments)
+		reader.progress.FilesSkipped = int64(len(fragment.ToFilesVector(skipped)))
+		return reader, nil
+	}
+	dataFragments, err := m.GetDataFragments()
+	if err != nil {
+		return nil, fmt.Errorf("make record reader: %w", err)
 	}
-	if len(options.Filters) > 0 && filtersOnlyContainPKAndVersion(s, options.FiltersV2) {
-		return NewMergeRecordReader(s, options, f, scalarData, vectorData, deleteFragments)
+	return NewZipRecordReader(s, options, f, dataFragments), nil
+}
+
+// relatedColumns returns the columns read for options, those of the output and of the filters.
+func relatedColumns(options *option.ReadOptions) []string {
+	columns := make([]string, 0, len(options.Columns)+len(options.Filters))
+	columns = append(columns, options.Columns...)
+	for _, filter := range options.Filters {
+		columns = append(columns, filter.GetColumnName())
 	}
-	return NewFilterQueryReader(s, options, f, scalarData, vectorData, deleteFragments)
+	return columns
 }
 
 func onlyContainVectorColumns(schema *schema.Schema, relatedColumns []string) bool {
 	for _, column := range relatedColumns {
-		if schema.Options().VectorColumn != column && schema.Options().PrimaryColumn != column && schema.Options().VersionColumn != column {
+		if _, ok := schema.VectorSchema().FieldsByName(column); !ok {
 			return false
 		}
 	}
@@ -58,19 +157,59 @@ func onlyContainVectorColumns(schema *schema.Schema, relatedColumns []string) bo
 
 func onlyContainScalarColumns(schema *schema.Schema, relatedColumns []string) bool {
 	for _, column := range relatedColumns {
-		if schema.Options().VectorColumn == column {
+		if _, ok := schema.ScalarSchema().FieldsByName(column); !ok {
 			return false
 		}
 	}
 	return true
 }
 
-func filtersOnlyContainPKAndVersion(s *schema.Schema, filters []filter.Filter) bool {
-	for _, f := range filters {
-		if f.GetColumnName() != s.Options().PrimaryColumn &&
-			f.GetColumnName() != s.Options().VersionColumn {
-			return false
+// makeMergeRecordReader reads every data file on its own and merges the files in the order of
+// options.OrderBy.
+func makeMergeRecordReader(
+	m *manifest.Manifest,
+	s *schema.Schema,
+	f fs.Fs,
+	deleteFragments fragment.DeleteFragmentVector,
+	options *option.ReadOptions,
+) (array.RecordReader, error) {
+	key := options.OrderBy.Column
+	field, ok := s.Schema().FieldsByName(key)
+	if !ok {
+		return nil, fmt.Errorf("order by missing column %q: %w", key, ErrUnsupportedOrderColumn)
+	}
+	if err := CheckOrderColumn(key, field[0].Type); err != nil {
+		return nil, err
+	}
+	output := utils.ProjectSchema(s.Schema(), options.OutputColumns())
+	fileOptions := options.Clone()
+	fileOptions.OrderBy = option.OrderBy{}
+	fileOptions.Progress = nil
+	if !fileOptions.HasColumn(key) {
+		fileOptions.AddColumn(key)
+	}
+
+	dataFragments, err := m.GetDataFragments()
+	if err != nil {
+		return nil, fmt.Errorf("make record reader: %w", err)
+	}
+	var readers []array.RecordReader
+	for _, dataFragment := range dataFragments {
+		for i, file := range dataFragment.Scalar.Files() {
+			scalar, vector := fragment.NewFragment(dataFragment.Scalar.FragmentId()), fragment.NewFragment(dataFragment.Vector.FragmentId())
+			scalar.AddFileWithStats(file, dataFragment.Scalar.FileStats()[i])
+			vector.AddFileWithStats(dataFragment.Vector.Files()[i], dataFragment.Vector.FileStats()[i])
+			single := manifest.NewManifest(s)
+			single.AddDataFragment(manifest.DataFragment{Scalar: *scalar, Vector: *vector})
+			reader, err := makeRecordReader(single, s, f, deleteFragments, fileOptions)
+			if err != nil {
+				for _, reader := range readers {
+					reader.Release()
+				}
+				return nil, err
+			}
+			readers = append(readers, reader)
 		}
 	}
-	return true
+	return NewMergeRecordReader(output, readers, key), nil
 }
@@ -1,8 +1,16 @@
 package record_reader
 
 import (
+	"context"
+	"io"
+	"sync/atomic"
+
 	"github.com/apache/arrow/go/v12/arrow"
 	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/compute"
+	"github.com/apache/arrow/go/v12/arrow/memory"
+	"github.com/bits-and-blooms/bitset"
+	"github.com/milvus-io/milvus-storage/go/common/constant"
 	"github.com/milvus-io/milvus-storage/go/common/log"
 	"github.com/milvus-io/milvus-storage/go/common/utils"
 	"github.com/milvus-io/milvus-storage/go/file/fragment"
@@ -12,8 +20,6 @@ import (
 	"github.com/milvus-io/milvus-storage/go/storage/options/option"
 	"github.com/milvus-io/milvus-storage/go/storage/schema"
 	"go.uber.org/zap"
-	"io"
-	"sync/atomic"
 )
 
 type ScanRecordReader struct {
@@ -28,6 +34,13 @@ type ScanRecordReader struct {
 	reader          array.RecordReader
 	nextPos         int
 	err             error
+	progress        option.Progress
+	finished        bool
+	// deletedRows, if set, holds the deleted rows of every file, they are skipped by offset.
+	deletedRows fragment.DeletedRows
+	curDeleted  *bitset.BitSet
+	// curSource is the file of curReader.
+	curSource Source
 }
 
 func NewScanRecordReader(
@@ -78,14 +91,25 @@ func (r *ScanRecordReader) Next() bool {
 	for {
 		if r.curReader == nil {
 			if r.nextPos >= len(datafiles) {
+				if !r.finished && r.options.Progress != nil {
+					r.options.Progress(r.progress)
+				}
+				r.finished = true
 				return false
 			}
 			// FIXME: nil options
-			reader, err := parquet.NewFileReader(r.fs, datafiles[r.nextPos], r.options)
+			options := r.options
+			if r.deletedRows != nil {
+				options = options.Clone()
+				options.AddColumn(constant.OffsetFieldName)
+				r.curDeleted = r.deletedRows[datafiles[r.nextPos]]
+			}
+			reader, err := parquet.NewFileReader(r.fs, datafiles[r.nextPos], options)
 			if err != nil {
 				r.err = err
 				return false
 			}
+			r.curSource = Source{FragmentID: fileFragmentID(r.dataFragments, r.nextPos), Files: []string{datafiles[r.nextPos]}}
 			r.nextPos++
 			r.curReader = reader
 		}
@@ -93,6 +117,8 @@ func (r *ScanRecordReader) Next() bool {
 		rec, err := r.curReader.Read()
 		if err != nil {
 			if err == io.EOF {
+				r.progress.Files++
+				r.progress.Bytes += r.curReader.BytesRead()
 				r.curReader.Close()
 				r.curReader = nil
 				continue
@@ -103,22 +129,87 @@ func (r *ScanRecordReader) Next() bool {
 			r.err = err
 			return false
 		}
+		if r.deletedRows != nil {
+			live, err := r.skipDeleted(rec)
+			rec.Release()
+			if err != nil {
+				r.curReader.Close()
+				r.curReader = nil
+				r.err = err
+				return false
+			}
+			rec = live
+		}
 		r.rec = rec
+		r.progress.Rows += rec.NumRows()
+		r.reportProgress()
 		return true
 	}
 }
 
+// skipDeleted returns the rows of rec whose offsets are not deleted, without the offset column.
+func (r *ScanRecordReader) skipDeleted(rec arrow.Record) (arrow.Record, error) {
+	offsets := rec.Column(rec.Schema().FieldIndices(constant.OffsetFieldName)[0]).(*array.Int64)
+	builder := array.NewBooleanBuilder(memory.DefaultAllocator)
+	defer builder.Release()
+	for i := 0; i < offsets.Len(); i++ {
+		builder.Append(r.curDeleted == nil || !r.curDeleted.Test(uint(offsets.Value(i))))
+	}
+	mask := builder.NewBooleanArray()
+	defer mask.Release()
+	filtered, err := compute.FilterRecordBatch(context.Background(), rec, mask, compute.DefaultFilterOptions())
+	if err != nil {
+		return nil, err
+	}
+	defer filtered.Release()
+	output := r.Schema()
+	columns := make([]arrow.Array, 0, len(output.Fields()))
+	for _, field := range output.Fields() {
+		columns = append(columns, filtered.Column(filtered.Schema().FieldIndices(field.Name)[0]))
+	}
+	return array.NewRecord(output, columns, filtered.NumRows()), nil
+}
+
+func (r *ScanRecordReader) reportProgress() {
+	if r.options.Progress == nil {
+		return
+	}
+	report := r.progress
+	if r.curReader != nil {
+		report.Bytes += r.curReader.BytesRead()
+	}
+	r.options.Progress(report)
+}
+
 func (r *ScanRecordReader) Record() arrow.Record {
 	return r.rec
 }
 
 func (r *ScanRecordReader) Err() error {
-	//TODO implement me
-	panic("implement me")
+	return r.err
 }
 
 func (r *ScanRecordReader) MakeInnerReader() array.RecordReader {
 	//TODO implement me
-	//	reader := NewMultiFilesSequentialReader(r.fs, r.dataFragments, r.Schema(), r.options)
 	return nil
 }
+
+// Sources returns the file the current record was read from.
+func (r *ScanRecordReader) Sources() []Source {
+	if r.rec == nil {
+		return nil
+	}
+	return []Source{r.curSource}
+}
+
+// fileFragmentID returns the id of the fragment holding the file at pos among the files of
+// fragments.
+func fileFragmentID(fragments fragment.FragmentVector, pos int) int64 {
+	for _, f := range fragments {
+		if pos < len(f.Files()) {
+			return f.FragmentId()
+		}
+		pos -= len(f.Files())
+	}
+	return 0
+}
@@ -0,0 +1,51 @@
+package record_reader
+
+import "github.com/apache/arrow/go/v12/arrow/array"
+
+// Source is a data fragment and the files of it that rows were read from, the scalar file
+// first when both kinds are read.
+type Source struct {
+	FragmentID int64
+	Files      []string
+}
+
+func (s Source) equal(other Source) bool {
+	if s.FragmentID != other.FragmentID || len(s.Files) != len(other.Files) {
+		return false
+	}
+	for i := range s.Files {
+		if s.Files[i] != other.Files[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// SourceReader is implemented by the readers that know where the rows of their current record
+// were read from.
+type SourceReader interface {
+	Sources() []Source
+}
+
+// Sources returns where the rows of the current record of reader were read from, nil if the
+// reader does not know.
+func Sources(reader array.RecordReader) []Source {
+	if sourceReader, ok := reader.(SourceReader); ok {
+		return sourceReader.Sources()
+	}
+	return nil
+}
+
+// appendSources appends the sources missing from sources.
+func appendSources(sources []Source, more ...Source) []Source {
+next:
+	for _, source := range more {
+		for _, existing := range sources {
+			if existing.equal(source) {
+				continue next
+			}
+		}
+		sources = append(sources, source)
+	}
+	return sources
+}
@@ -0,0 +1,312 @@
+package record_reader
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync/atomic"
+
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/compute"
+	"github.com/apache/arrow/go/v12/arrow/memory"
+	"github.com/bits-and-blooms/bitset"
+	"github.com/milvus-io/milvus-storage/go/common/utils"
+	"github.com/milvus-io/milvus-storage/go/io/format"
+	"github.com/milvus-io/milvus-storage/go/io/format/parquet"
+	"github.com/milvus-io/milvus-storage/go/io/fs"
+	"github.com/milvus-io/milvus-storage/go/storage/manifest"
+	"github.com/milvus-io/milvus-storage/go/storage/options/option"
+	"github.com/milvus-io/milvus-storage/go/storage/schema"
+)
+
+// ZipRecordReader reads columns stored in both scalar and vector files. The paired files of
+// each data fragment are read side by side and their rows joined by position, then the
+// filters are applied to the joined rows so that both sides stay aligned. Writes roll both
+// files of a pair at the same row, but their records may end at different rows, e.g. as row
+// groups are sized by bytes, so each side is consumed at its own pace.
+type ZipRecordReader struct {
+	ref       int64
+	schema    *schema.Schema
+	options   *option.ReadOptions
+	fs        fs.Fs
+	fragments []manifest.DataFragment
+	// scalarColumns and vectorColumns are the columns read from each side, shared primary
+	// and version columns are read from the scalar files only.
+	scalarColumns []string
+	vectorColumns []string
+
+	fragmentPos  int
+	filePos      int
+	scalarReader format.Reader
+	vectorReader format.Reader
+	scalar       sideRecord
+	vector       sideRecord
+	rec          arrow.Record
+	err          error
+	progress     option.Progress
+	finished     bool
+}
+
+// sideRecord is the last record read from one side and how many of its rows were consumed.
+type sideRecord struct {
+	rec    arrow.Record
+	offset int64
+}
+
+func (s *sideRecord) remaining() int64 {
+	if s.rec == nil {
+		return 0
+	}
+	return s.rec.NumRows() - s.offset
+}
+
+func NewZipRecordReader(
+	s *schema.Schema,
+	options *option.ReadOptions,
+	f fs.Fs,
+	fragments []manifest.DataFragment,
+) *ZipRecordReader {
+	related := append([]string(nil), options.Columns...)
+	for _, f := range options.FiltersV2 {
+		related = append(related, f.GetColumnName())
+	}
+	r := &ZipRecordReader{
+		ref:       1,
+		schema:    s,
+		options:   options,
+		fs:        f,
+		fragments: fragments,
+	}
+	seen := make(map[string]bool)
+	for _, column := range related {
+		if seen[column] {
+			continue
+		}
+		seen[column] = true
+		if _, ok := s.ScalarSchema().FieldsByName(column); ok {
+			r.scalarColumns = append(r.scalarColumns, column)
+		} else {
+			r.vectorColumns = append(r.vectorColumns, column)
+		}
+	}
+	return r
+}
+
+func (r *ZipRecordReader) Retain() {
+	atomic.AddInt64(&r.ref, 1)
+}
+
+func (r *ZipRecordReader) Release() {
+	if atomic.AddInt64(&r.ref, -1) == 0 {
+		if r.rec != nil {
+			r.rec.Release()
+			r.rec = nil
+		}
+		r.closeFiles()
+	}
+}
+
+func (r *ZipRecordReader) Schema() *arrow.Schema {
+	return utils.ProjectSchema(r.schema.Schema(), r.options.OutputColumns())
+}
+
+func (r *ZipRecordReader) Record() arrow.Record {
+	return r.rec
+}
+
+func (r *ZipRecordReader) Err() error {
+	return r.err
+}
+
+func (r *ZipRecordReader) Next() bool {
+	if r.rec != nil {
+		r.rec.Release()
+		r.rec = nil
+	}
+	if r.err != nil {
+		return false
+	}
+	for {
+		if r.scalarReader == nil {
+			if !r.openNextFiles() {
+				if !r.finished && r.options.Progress != nil {
+					r.options.Progress(r.progress)
+				}
+				r.finished = true
+				return false
+			}
+		}
+		if err := r.fill(r.scalarReader, &r.scalar); err != nil {
+			return r.fail(err)
+		}
+		if err := r.fill(r.vectorReader, &r.vector); err != nil {
+			return r.fail(err)
+		}
+		scalarRows, vectorRows := r.scalar.remaining(), r.vector.remaining()
+		if scalarRows == 0 && vectorRows == 0 {
+			r.progress.Files += 2
+			r.progress.Bytes += r.scalarReader.BytesRead() + r.vectorReader.BytesRead()
+			r.closeFiles()
+			continue
+		}
+		if scalarRows == 0 || vectorRows == 0 {
+			fragment := r.fragments[r.fragmentPos]
+			return r.fail(fmt.Errorf("read %s and %s: %w",
+				fragment.Scalar.Files()[r.filePos-1], fragment.Vector.Files()[r.filePos-1], manifest.ErrFragmentMismatch))
+		}
+
+		n := scalarRows
+		if vectorRows < n {
+			n = vectorRows
+		}
+		rec, err := r.zip(n)
+		if err != nil {
+			return r.fail(err)
+		}
+		if rec == nil {
+			continue
+		}
+		r.rec = rec
+		r.progress.Rows += rec.NumRows()
+		r.reportProgress()
+		return true
+	}
+}
+
+func (r *ZipRecordReader) fail(err error) bool {
+	r.err = err
+	r.closeFiles()
+	return false
+}
+
+// openNextFiles opens the next pair of files, it returns false when there are no more.
+func (r *ZipRecordReader) openNextFiles() bool {
+	for r.fragmentPos < len(r.fragments) && r.filePos >= len(r.fragments[r.fragmentPos].Scalar.Files()) {
+		r.fragmentPos++
+		r.filePos = 0
+	}
+	if r.fragmentPos >= len(r.fragments) {
+		return false
+	}
+	fragment := r.fragments[r.fragmentPos]
+	scalarReader, err := r.openFile(fragment.Scalar.Files()[r.filePos], r.scalarColumns)
+	if err != nil {
+		r.err = err
+		return false
+	}
+	vectorReader, err := r.openFile(fragment.Vector.Files()[r.filePos], r.vectorColumns)
+	if err != nil {
+		scalarReader.Close()
+		r.err = err
+		return false
+	}
+	r.filePos++
+	r.scalarReader, r.vectorReader = scalarReader, vectorReader
+	return true
+}
+
+// openFile opens path without filters, they are applied once both sides are joined.
+func (r *ZipRecordReader) openFile(path string, columns []string) (format.Reader, error) {
+	options := option.NewReadOptions()
+	options.SetColumns(columns)
+	return parquet.NewFileReader(r.fs, path, options)
+}
+
+func (r *ZipRecordReader) closeFiles() {
+	if r.scalarReader != nil {
+		r.scalarReader.Close()
+		r.scalarReader = nil
+	}
+	if r.vectorReader != nil {
+		r.vectorReader.Close()
+		r.vectorReader = nil
+	}
+	r.scalar, r.vector = sideRecord{}, sideRecord{}
+}
+
+// fill reads the next record of a side once the current one is consumed. The records are
+// owned by the file reader.
+func (r *ZipRecordReader) fill(reader format.Reader, side *sideRecord) error {
+	for side.rec == nil || side.remaining() == 0 {
+		rec, err := reader.Read()
+		if err == io.EOF {
+			side.rec = nil
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		side.rec, side.offset = rec, 0
+	}
+	return nil
+}
+
+// zip joins the next n rows of both sides and applies the filters, it returns nil if no
+// row passes them.
+func (r *ZipRecordReader) zip(n int64) (arrow.Record, error) {
+	scalar := r.scalar.rec.NewSlice(r.scalar.offset, r.scalar.offset+n)
+	defer scalar.Release()
+	vector := r.vector.rec.NewSlice(r.vector.offset, r.vector.offset+n)
+	defer vector.Release()
+	r.scalar.offset += n
+	r.vector.offset += n
+
+	column := func(name string) arrow.Array {
+		if indices := scalar.Schema().FieldIndices(name); len(indices) > 0 {
+			return scalar.Column(indices[0])
+		}
+		return vector.Column(vector.Schema().FieldIndices(name)[0])
+	}
+
+	filtered := bitset.New(uint(n))
+	for _, f := range r.options.Filters {
+		f.Apply(column(f.GetColumnName()), filtered)
+	}
+	if filtered.Count() == uint(n) {
+		return nil, nil
+	}
+
+	outSchema := r.Schema()
+	columns := make([]arrow.Array, 0, len(outSchema.Fields()))
+	for _, field := range outSchema.Fields() {
+		columns = append(columns, column(field.Name))
+	}
+	joined := array.NewRecord(outSchema, columns, n)
+	if filtered.None() {
+		return joined, nil
+	}
+	defer joined.Release()
+
+	builder := array.NewBooleanBuilder(memory.DefaultAllocator)
+	defer builder.Release()
+	for i := uint(0); i < uint(n); i++ {
+		builder.Append(!filtered.Test(i))
+	}
+	mask := builder.NewArray()
+	defer mask.Release()
+	return compute.FilterRecordBatch(context.Background(), joined, mask, compute.DefaultFilterOptions())
+}
+
+func (r *ZipRecordReader) reportProgress() {
+	if r.options.Progress == nil {
+		return
+	}
+	report := r.progress
+	if r.scalarReader != nil {
+		report.Bytes += r.scalarReader.BytesRead() + r.vectorReader.BytesRead()
+	}
+	r.options.Progress(report)
+}
+
+// Sources returns the pair of files the current record was read from.
+func (r *ZipRecordReader) Sources() []Source {
+	if r.rec == nil {
+		return nil
+	}
+	fragment := r.fragments[r.fragmentPos]
+	return []Source{{
+		FragmentID: fragment.Scalar.FragmentId(),
+		Files:      []string{fragment.Scalar.Files()[r.filePos-1], fragment.Vector.Files()[r.filePos-1]},
+	}}
+}
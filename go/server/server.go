@@ -0,0 +1,399 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/ipc"
+	"github.com/apache/arrow/go/v12/arrow/memory"
+	"github.com/milvus-io/milvus-storage/go/common/errors"
+	"github.com/milvus-io/milvus-storage/go/common/log"
+	"github.com/milvus-io/milvus-storage/go/filter"
+	"github.com/milvus-io/milvus-storage/go/proto/storage_proto"
+	"github.com/milvus-io/milvus-storage/go/storage"
+	"github.com/milvus-io/milvus-storage/go/storage/options/option"
+	"github.com/milvus-io/milvus-storage/go/storage/options/schema_option"
+	"github.com/milvus-io/milvus-storage/go/storage/schema"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	ErrSpaceNotOpened = errors.New("space not opened")
+	ErrEmptyStream    = errors.New("empty stream")
+	ErrInvalidPayload = errors.New("invalid arrow ipc payload")
+	ErrSchemaChanged  = errors.New("schema changed within stream")
+)
+
+// readBlobChunkSize is the number of bytes of a blob sent in each ReadBlob message.
+const readBlobChunkSize = 1 << 20
+
+var _ storage_proto.StorageServiceServer = (*Server)(nil)
+
+// Server exposes Space operations over gRPC. Spaces are opened once per uri and shared by
+// all subsequent requests until CloseSpace is called, opening a uri again closes the space
+// it replaces. Requests run with the grpc context, so
+// an interceptor can attach the caller identity with auth.WithIdentity for the authorizer.
+type Server struct {
+	storage_proto.UnimplementedStorageServiceServer
+
+	lock   sync.RWMutex
+	spaces map[string]*storage.Space
+}
+
+func NewServer() *Server {
+	return &Server{
+		spaces: make(map[string]*storage.Space),
+	}
+}
+
+// Register registers the storage service on a grpc server.
+func (s *Server) Register(grpcServer *grpc.Server) {
+	storage_proto.RegisterStorageServiceServer(grpcServer, s)
+}
+
+func (s *Server) getSpace(uri string) (*storage.Space, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	space, ok := s.spaces[uri]
+	if !ok {
+		return nil, status.Error(codes.FailedPrecondition, fmt.Sprintf("%s: %s", ErrSpaceNotOpened, uri))
+	}
+	return space, nil
+}
+
+func (s *Server) OpenSpace(ctx context.Context, req *storage_proto.OpenSpaceRequest) (*storage_proto.OpenSpaceResponse, error) {
+	var sc *schema.Schema
+	if req.GetSchema() != nil {
+		sc = schema.NewSchema(arrow.NewSchema(nil, nil), schema_option.Init())
+		if err := sc.FromProtobuf(req.GetSchema()); err != nil {
+			return nil, toStatus(err)
+		}
+		if err := sc.Validate(); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
+	space, err := storage.Open(req.GetUri(), *option.NewOptions(sc, req.GetVersion()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	s.lock.Lock()
+	replaced, ok := s.spaces[req.GetUri()]
+	s.spaces[req.GetUri()] = space
+	if ok {
+		if err = replaced.Close(); err != nil {
+			log.Warn("close replaced space failed", log.String("uri", req.GetUri()), log.String("err", err.Error()))
+		}
+	}
+	s.lock.Unlock()
+	log.Info("open space", log.String("uri", req.GetUri()), log.Int64("version", space.GetCurrentVersion()))
+	return &storage_proto.OpenSpaceResponse{Version: space.GetCurrentVersion()}, nil
+}
+
+func (s *Server) CloseSpace(ctx context.Context, req *storage_proto.CloseSpaceRequest) (*storage_proto.CloseSpaceResponse, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	space, ok := s.spaces[req.GetUri()]
+	if !ok {
+		return &storage_proto.CloseSpaceResponse{}, nil
+	}
+	delete(s.spaces, req.GetUri())
+	if err := space.Close(); err != nil {
+		return nil, toStatus(err)
+	}
+	return &storage_proto.CloseSpaceResponse{}, nil
+}
+
+func (s *Server) Write(stream storage_proto.StorageService_WriteServer) error {
+	req, err := stream.Recv()
+	if err == io.EOF {
+		return status.Error(codes.InvalidArgument, ErrEmptyStream.Error())
+	}
+	if err != nil {
+		return err
+	}
+	space, err := s.getSpace(req.GetUri())
+	if err != nil {
+		return err
+	}
+	options := option.NewWriteOption()
+	if req.GetMaxRecordPerFile() > 0 {
+		options.MaxRecordPerFile = req.GetMaxRecordPerFile()
+	}
+
+	reader, err := newRecordStream(req.GetArrowIpc(), func() ([]byte, error) {
+		req, err := stream.Recv()
+		return req.GetArrowIpc(), err
+	})
+	if err != nil {
+		return streamStatus(err)
+	}
+	defer reader.Release()
+
+	result, err := space.WriteContext(stream.Context(), reader, options)
+	if err != nil {
+		if reader.Err() != nil {
+			return streamStatus(reader.Err())
+		}
+		return toStatus(err)
+	}
+	return stream.SendAndClose(&storage_proto.WriteResponse{Version: result.Version})
+}
+
+func (s *Server) Delete(stream storage_proto.StorageService_DeleteServer) error {
+	req, err := stream.Recv()
+	if err == io.EOF {
+		return status.Error(codes.InvalidArgument, ErrEmptyStream.Error())
+	}
+	if err != nil {
+		return err
+	}
+	space, err := s.getSpace(req.GetUri())
+	if err != nil {
+		return err
+	}
+
+	reader, err := newRecordStream(req.GetArrowIpc(), func() ([]byte, error) {
+		req, err := stream.Recv()
+		return req.GetArrowIpc(), err
+	})
+	if err != nil {
+		return streamStatus(err)
+	}
+	defer reader.Release()
+
+	result, err := space.DeleteContext(stream.Context(), reader)
+	if err != nil {
+		if reader.Err() != nil {
+			return streamStatus(reader.Err())
+		}
+		return toStatus(err)
+	}
+	return stream.SendAndClose(&storage_proto.DeleteResponse{Version: result.Version})
+}
+
+func (s *Server) Read(req *storage_proto.ReadRequest, stream storage_proto.StorageService_ReadServer) error {
+	space, err := s.getSpace(req.GetUri())
+	if err != nil {
+		return err
+	}
+
+	readOptions, err := toReadOptions(req)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	reader, err := space.ReadContext(stream.Context(), readOptions)
+	if err != nil {
+		return toStatus(err)
+	}
+	defer reader.Release()
+
+	for reader.Next() {
+		payload, err := encodeRecord(reader.Record())
+		if err != nil {
+			return toStatus(err)
+		}
+		if err = stream.Send(&storage_proto.ReadResponse{ArrowIpc: payload}); err != nil {
+			return err
+		}
+	}
+	if err = reader.Err(); err != nil {
+		return toStatus(err)
+	}
+	return nil
+}
+
+func (s *Server) WriteBlob(ctx context.Context, req *storage_proto.WriteBlobRequest) (*storage_proto.WriteBlobResponse, error) {
+	space, err := s.getSpace(req.GetUri())
+	if err != nil {
+		return nil, err
+	}
+	result, err := space.WriteBlobContext(ctx, req.GetContent(), req.GetName(), req.GetReplace())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &storage_proto.WriteBlobResponse{Version: result.Version}, nil
+}
+
+// ReadBlob streams the content of a blob in messages of at most readBlobChunkSize bytes, so
+// that neither side holds the whole blob in memory.
+func (s *Server) ReadBlob(req *storage_proto.ReadBlobRequest, stream storage_proto.StorageService_ReadBlobServer) error {
+	space, err := s.getSpace(req.GetUri())
+	if err != nil {
+		return err
+	}
+	ctx := stream.Context()
+	size, err := space.GetBlobByteSizeContext(ctx, req.GetName())
+	if err != nil {
+		return toStatus(err)
+	}
+	chunk := make([]byte, readBlobChunkSize)
+	for off := int64(0); off < size; {
+		n, err := space.ReadBlobAtContext(ctx, req.GetName(), chunk, off)
+		if err != nil && err != io.EOF {
+			return toStatus(err)
+		}
+		if n == 0 {
+			break
+		}
+		if err = stream.Send(&storage_proto.ReadBlobResponse{Content: chunk[:n]}); err != nil {
+			return err
+		}
+		off += int64(n)
+	}
+	return nil
+}
+
+func (s *Server) GetBlobByteSize(ctx context.Context, req *storage_proto.GetBlobByteSizeRequest) (*storage_proto.GetBlobByteSizeResponse, error) {
+	space, err := s.getSpace(req.GetUri())
+	if err != nil {
+		return nil, err
+	}
+	size, err := space.GetBlobByteSizeContext(ctx, req.GetName())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &storage_proto.GetBlobByteSizeResponse{Size: size}, nil
+}
+
+func (s *Server) GetCurrentVersion(ctx context.Context, req *storage_proto.GetCurrentVersionRequest) (*storage_proto.GetCurrentVersionResponse, error) {
+	space, err := s.getSpace(req.GetUri())
+	if err != nil {
+		return nil, err
+	}
+	return &storage_proto.GetCurrentVersionResponse{Version: space.GetCurrentVersion()}, nil
+}
+
+func (s *Server) Compact(ctx context.Context, req *storage_proto.CompactRequest) (*storage_proto.CompactResponse, error) {
+	space, err := s.getSpace(req.GetUri())
+	if err != nil {
+		return nil, err
+	}
+	options := option.NewCompactOptions()
+	options.PurgeDeletes = req.GetPurgeDeletes()
+	options.ClusterColumns = req.GetClusterColumns()
+	options.PurgeDroppedColumns = req.GetPurgeDroppedColumns()
+	if req.GetMaxRecordPerFile() > 0 {
+		options.MaxRecordPerFile = req.GetMaxRecordPerFile()
+	}
+	version, err := space.CompactContext(ctx, options)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &storage_proto.CompactResponse{Version: version}, nil
+}
+
+func (s *Server) CompactDeletes(ctx context.Context, req *storage_proto.CompactDeletesRequest) (*storage_proto.CompactDeletesResponse, error) {
+	space, err := s.getSpace(req.GetUri())
+	if err != nil {
+		return nil, err
+	}
+	version, err := space.CompactDeletesContext(ctx)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &storage_proto.CompactDeletesResponse{Version: version}, nil
+}
+
+func (s *Server) DropColumn(ctx context.Context, req *storage_proto.DropColumnRequest) (*storage_proto.DropColumnResponse, error) {
+	space, err := s.getSpace(req.GetUri())
+	if err != nil {
+		return nil, err
+	}
+	version, err := space.DropColumnContext(ctx, req.GetName())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &storage_proto.DropColumnResponse{Version: version}, nil
+}
+
+func (s *Server) PinVersion(ctx context.Context, req *storage_proto.PinVersionRequest) (*storage_proto.PinVersionResponse, error) {
+	space, err := s.getSpace(req.GetUri())
+	if err != nil {
+		return nil, err
+	}
+	version, err := space.PinVersionContext(ctx, req.GetVersion())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &storage_proto.PinVersionResponse{Version: version}, nil
+}
+
+func (s *Server) ReleaseLease(ctx context.Context, req *storage_proto.ReleaseLeaseRequest) (*storage_proto.ReleaseLeaseResponse, error) {
+	space, err := s.getSpace(req.GetUri())
+	if err != nil {
+		return nil, err
+	}
+	if err = space.ReleaseLeaseContext(ctx); err != nil {
+		return nil, toStatus(err)
+	}
+	return &storage_proto.ReleaseLeaseResponse{}, nil
+}
+
+func encodeRecord(rec arrow.Record) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := ipc.NewWriter(&buf, ipc.WithSchema(rec.Schema()), ipc.WithAllocator(memory.DefaultAllocator))
+	if err := writer.Write(rec); err != nil {
+		return nil, fmt.Errorf("encode arrow ipc: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("encode arrow ipc: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// toReadOptions converts the options of req, the version is only set if req sets it, even to 0.
+func toReadOptions(req *storage_proto.ReadRequest) (*option.ReadOptions, error) {
+	readOptions := option.NewReadOptions()
+	readOptions.SetColumns(req.GetColumns())
+	if req.Version != nil {
+		readOptions.SetVersion(req.GetVersion())
+	}
+	for _, protoFilter := range req.GetFilters() {
+		f, err := filter.FromProtobuf(protoFilter)
+		if err != nil {
+			return nil, err
+		}
+		readOptions.AddFilter(f)
+	}
+	return readOptions, nil
+}
+
+// streamStatus converts the error of a record stream, the errors of the grpc stream are kept.
+func streamStatus(err error) error {
+	if errors.Is(err, ErrInvalidPayload) || errors.Is(err, ErrEmptyStream) || errors.Is(err, ErrSchemaChanged) {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return err
+}
+
+func toStatus(err error) error {
+	switch {
+	case errors.Is(err, storage.ErrBlobAlreadyExist):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, errors.ErrResourceExhausted):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, errors.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, errors.ErrPermissionDenied):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, errors.ErrThrottled), errors.Is(err, errors.ErrUnavailable):
+		return status.Error(codes.Unavailable, err.Error())
+	case errors.Is(err, errors.ErrChecksumMismatch):
+		return status.Error(codes.DataLoss, err.Error())
+	case errors.Is(err, errors.ErrConflict):
+		return status.Error(codes.Aborted, err.Error())
+	case errors.Is(err, storage.ErrSchemaIsNil), errors.Is(err, storage.ErrSchemaNotMatch),
+		errors.Is(err, storage.ErrColumnNotExist):
+		return status.Error(codes.InvalidArgument, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
@@ -0,0 +1,269 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/memory"
+	"github.com/milvus-io/milvus-storage/go/proto/filter_proto"
+	"github.com/milvus-io/milvus-storage/go/proto/storage_proto"
+	"github.com/milvus-io/milvus-storage/go/storage"
+	"github.com/milvus-io/milvus-storage/go/storage/options/option"
+	"github.com/milvus-io/milvus-storage/go/storage/options/schema_option"
+	"github.com/milvus-io/milvus-storage/go/storage/schema"
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type ServerTestSuite struct {
+	suite.Suite
+	grpcServer *grpc.Server
+	conn       *grpc.ClientConn
+	client     storage_proto.StorageServiceClient
+	uri        string
+	schema     *arrow.Schema
+}
+
+func (suite *ServerTestSuite) SetupTest() {
+	listener := bufconn.Listen(1024 * 1024)
+	suite.grpcServer = grpc.NewServer()
+	NewServer().Register(suite.grpcServer)
+	go suite.grpcServer.Serve(listener)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, s string) (net.Conn, error) { return listener.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	suite.Require().NoError(err)
+	suite.conn = conn
+	suite.client = storage_proto.NewStorageServiceClient(conn)
+	suite.uri = "file://" + suite.T().TempDir()
+
+	suite.schema = arrow.NewSchema([]arrow.Field{
+		{Name: "pk_field", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "vs_field", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "vec_field", Type: &arrow.FixedSizeBinaryType{ByteWidth: 4}},
+	}, nil)
+}
+
+func (suite *ServerTestSuite) TearDownTest() {
+	suite.conn.Close()
+	suite.grpcServer.Stop()
+}
+
+func (suite *ServerTestSuite) openSpace() {
+	sc := schema.NewSchema(suite.schema, &schema_option.SchemaOptions{
+		PrimaryColumn: "pk_field",
+		VersionColumn: "vs_field",
+		VectorColumn:  "vec_field",
+	})
+	suite.Require().NoError(sc.Validate())
+	protoSchema, err := sc.ToProtobuf()
+	suite.Require().NoError(err)
+
+	resp, err := suite.client.OpenSpace(context.Background(), &storage_proto.OpenSpaceRequest{Uri: suite.uri, Schema: protoSchema, Version: -1})
+	suite.Require().NoError(err)
+	suite.Equal(int64(0), resp.GetVersion())
+}
+
+func (suite *ServerTestSuite) payload(pks ...int64) []byte {
+	b := array.NewRecordBuilder(memory.DefaultAllocator, suite.schema)
+	defer b.Release()
+	for _, pk := range pks {
+		b.Field(0).(*array.Int64Builder).Append(pk)
+		b.Field(1).(*array.Int64Builder).Append(1)
+		b.Field(2).(*array.FixedSizeBinaryBuilder).Append([]byte{byte(pk), 1, 1, 1})
+	}
+	rec := b.NewRecord()
+	defer rec.Release()
+	payload, err := encodeRecord(rec)
+	suite.Require().NoError(err)
+	return payload
+}
+
+func (suite *ServerTestSuite) write(payloads ...[]byte) (*storage_proto.WriteResponse, error) {
+	writeStream, err := suite.client.Write(context.Background())
+	suite.Require().NoError(err)
+	for _, payload := range payloads {
+		suite.Require().NoError(writeStream.Send(&storage_proto.WriteRequest{Uri: suite.uri, ArrowIpc: payload}))
+	}
+	return writeStream.CloseAndRecv()
+}
+
+func (suite *ServerTestSuite) read(req *storage_proto.ReadRequest) []int64 {
+	req.Uri = suite.uri
+	req.Columns = []string{"pk_field"}
+	readStream, err := suite.client.Read(context.Background(), req)
+	suite.Require().NoError(err)
+	var values []int64
+	for {
+		resp, err := readStream.Recv()
+		if err == io.EOF {
+			break
+		}
+		suite.Require().NoError(err)
+		reader, err := decode(resp.GetArrowIpc())
+		suite.Require().NoError(err)
+		for reader.Next() {
+			values = append(values, reader.Record().Column(0).(*array.Int64).Int64Values()...)
+		}
+		reader.Release()
+	}
+	return values
+}
+
+func (suite *ServerTestSuite) readBlob(name string) ([]byte, error) {
+	readStream, err := suite.client.ReadBlob(context.Background(), &storage_proto.ReadBlobRequest{Uri: suite.uri, Name: name})
+	suite.Require().NoError(err)
+	var content []byte
+	for {
+		resp, err := readStream.Recv()
+		if err == io.EOF {
+			return content, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		suite.LessOrEqual(len(resp.GetContent()), readBlobChunkSize)
+		content = append(content, resp.GetContent()...)
+	}
+}
+
+func (suite *ServerTestSuite) TestWriteRead() {
+	suite.openSpace()
+
+	writeResp, err := suite.write(suite.payload(1, 2), suite.payload(3))
+	suite.Require().NoError(err)
+	suite.Equal(int64(1), writeResp.GetVersion())
+
+	suite.ElementsMatch([]int64{2, 3}, suite.read(&storage_proto.ReadRequest{
+		Filters: []*filter_proto.Filter{{
+			Column:     "pk_field",
+			Comparison: filter_proto.ComparisonType_GreaterThan,
+			Value:      &filter_proto.Filter_Int64Value{Int64Value: 1},
+		}},
+	}))
+}
+
+func (suite *ServerTestSuite) TestReadVersion() {
+	readOptions, err := toReadOptions(&storage_proto.ReadRequest{})
+	suite.Require().NoError(err)
+	suite.Equal(option.NewReadOptions().GetVersion(), readOptions.GetVersion())
+
+	version := int64(0)
+	readOptions, err = toReadOptions(&storage_proto.ReadRequest{Version: &version})
+	suite.Require().NoError(err)
+	suite.Equal(int64(0), readOptions.GetVersion())
+}
+
+func (suite *ServerTestSuite) TestWriteInvalidPayload() {
+	suite.openSpace()
+
+	_, err := suite.write(suite.payload(1), []byte("not arrow"))
+	suite.Equal(codes.InvalidArgument, status.Code(err))
+	_, err = suite.write()
+	suite.Equal(codes.InvalidArgument, status.Code(err))
+
+	resp, err := suite.client.GetCurrentVersion(context.Background(), &storage_proto.GetCurrentVersionRequest{Uri: suite.uri})
+	suite.Require().NoError(err)
+	suite.Equal(int64(0), resp.GetVersion())
+}
+
+func (suite *ServerTestSuite) TestAdmin() {
+	suite.openSpace()
+	ctx := context.Background()
+	_, err := suite.write(suite.payload(1, 2))
+	suite.Require().NoError(err)
+	_, err = suite.write(suite.payload(3))
+	suite.Require().NoError(err)
+
+	pinResp, err := suite.client.PinVersion(ctx, &storage_proto.PinVersionRequest{Uri: suite.uri, Version: 2})
+	suite.Require().NoError(err)
+	suite.Equal(int64(3), pinResp.GetVersion())
+
+	compactResp, err := suite.client.Compact(ctx, &storage_proto.CompactRequest{Uri: suite.uri, PurgeDeletes: true})
+	suite.Require().NoError(err)
+	versionResp, err := suite.client.GetCurrentVersion(ctx, &storage_proto.GetCurrentVersionRequest{Uri: suite.uri})
+	suite.Require().NoError(err)
+	suite.Equal(versionResp.GetVersion(), compactResp.GetVersion())
+	suite.ElementsMatch([]int64{1, 2, 3}, suite.read(&storage_proto.ReadRequest{}))
+
+	_, err = suite.client.ReleaseLease(ctx, &storage_proto.ReleaseLeaseRequest{Uri: suite.uri})
+	suite.Require().NoError(err)
+	_, err = suite.client.DropColumn(ctx, &storage_proto.DropColumnRequest{Uri: suite.uri, Name: "pk_field"})
+	suite.Error(err)
+}
+
+func (suite *ServerTestSuite) TestBlob() {
+	suite.openSpace()
+	ctx := context.Background()
+
+	_, err := suite.client.WriteBlob(ctx, &storage_proto.WriteBlobRequest{Uri: suite.uri, Name: "index", Content: []byte{1, 2, 3}})
+	suite.Require().NoError(err)
+
+	sizeResp, err := suite.client.GetBlobByteSize(ctx, &storage_proto.GetBlobByteSizeRequest{Uri: suite.uri, Name: "index"})
+	suite.Require().NoError(err)
+	suite.Equal(int64(3), sizeResp.GetSize())
+
+	content, err := suite.readBlob("index")
+	suite.Require().NoError(err)
+	suite.Equal([]byte{1, 2, 3}, content)
+
+	_, err = suite.client.WriteBlob(ctx, &storage_proto.WriteBlobRequest{Uri: suite.uri, Name: "index", Content: []byte{1}})
+	suite.Equal(codes.AlreadyExists, status.Code(err))
+
+	large := make([]byte, readBlobChunkSize+3)
+	for i := range large {
+		large[i] = byte(i)
+	}
+	_, err = suite.client.WriteBlob(ctx, &storage_proto.WriteBlobRequest{Uri: suite.uri, Name: "large", Content: large})
+	suite.Require().NoError(err)
+	content, err = suite.readBlob("large")
+	suite.Require().NoError(err)
+	suite.Equal(large, content)
+
+	_, err = suite.readBlob("missing")
+	suite.Equal(codes.NotFound, status.Code(err))
+}
+
+func (suite *ServerTestSuite) TestSpaceNotOpened() {
+	_, err := suite.client.GetCurrentVersion(context.Background(), &storage_proto.GetCurrentVersionRequest{Uri: suite.uri})
+	suite.Equal(codes.FailedPrecondition, status.Code(err))
+}
+
+func (suite *ServerTestSuite) TestCloseSpace() {
+	server := NewServer()
+	ctx := context.Background()
+	suite.openSpace()
+	_, err := suite.write(suite.payload(1))
+	suite.Require().NoError(err)
+	_, err = server.OpenSpace(ctx, &storage_proto.OpenSpaceRequest{Uri: suite.uri, Version: -1})
+	suite.Require().NoError(err)
+	first, err := server.getSpace(suite.uri)
+	suite.Require().NoError(err)
+
+	_, err = server.OpenSpace(ctx, &storage_proto.OpenSpaceRequest{Uri: suite.uri, Version: -1})
+	suite.Require().NoError(err)
+	_, err = first.ReadContext(ctx, option.NewReadOptions())
+	suite.ErrorIs(err, storage.ErrSpaceClosed)
+
+	second, err := server.getSpace(suite.uri)
+	suite.Require().NoError(err)
+	_, err = server.CloseSpace(ctx, &storage_proto.CloseSpaceRequest{Uri: suite.uri})
+	suite.Require().NoError(err)
+	_, err = second.ReadContext(ctx, option.NewReadOptions())
+	suite.ErrorIs(err, storage.ErrSpaceClosed)
+	_, err = server.GetCurrentVersion(ctx, &storage_proto.GetCurrentVersionRequest{Uri: suite.uri})
+	suite.Equal(codes.FailedPrecondition, status.Code(err))
+}
+
+func TestServerSuite(t *testing.T) {
+	suite.Run(t, new(ServerTestSuite))
+}
@@ -0,0 +1,114 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync/atomic"
+
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/ipc"
+	"github.com/apache/arrow/go/v12/arrow/memory"
+)
+
+// recordStream reads the record batches of the Arrow IPC payloads of a client stream as they
+// arrive, so that a write is not buffered in memory until the client closes the stream.
+type recordStream struct {
+	recv   func() ([]byte, error)
+	schema *arrow.Schema
+	cur    *ipc.Reader
+	err    error
+	refs   int64
+}
+
+var _ array.RecordReader = (*recordStream)(nil)
+
+// newRecordStream reads the schema from first, the payload of the first message, and receives
+// the next payloads with recv until it returns io.EOF. Every payload must have the same schema.
+func newRecordStream(first []byte, recv func() ([]byte, error)) (*recordStream, error) {
+	if len(first) == 0 {
+		return nil, ErrEmptyStream
+	}
+	cur, err := decode(first)
+	if err != nil {
+		return nil, err
+	}
+	return &recordStream{recv: recv, schema: cur.Schema(), cur: cur, refs: 1}, nil
+}
+
+func decode(payload []byte) (*ipc.Reader, error) {
+	reader, err := ipc.NewReader(bytes.NewReader(payload), ipc.WithAllocator(memory.DefaultAllocator))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPayload, err)
+	}
+	return reader, nil
+}
+
+func (r *recordStream) Schema() *arrow.Schema { return r.schema }
+
+// Record returns the current batch, valid until the next call to Next.
+func (r *recordStream) Record() arrow.Record {
+	if r.cur == nil {
+		return nil
+	}
+	return r.cur.Record()
+}
+
+// Err returns the error of the grpc stream, or ErrInvalidPayload and ErrSchemaChanged for
+// payloads that cannot be read.
+func (r *recordStream) Err() error { return r.err }
+
+func (r *recordStream) Next() bool {
+	for r.cur != nil {
+		if r.cur.Next() {
+			return true
+		}
+		if err := r.cur.Err(); err != nil {
+			r.fail(fmt.Errorf("%w: %v", ErrInvalidPayload, err))
+			return false
+		}
+		r.cur.Release()
+		r.cur = nil
+
+		payload, err := r.recv()
+		if err == io.EOF {
+			return false
+		}
+		if err != nil {
+			r.fail(err)
+			return false
+		}
+		if r.cur, err = decode(payload); err != nil {
+			r.fail(err)
+			return false
+		}
+		if !r.cur.Schema().Equal(r.schema) {
+			r.fail(fmt.Errorf("%w: %s", ErrSchemaChanged, r.cur.Schema()))
+			return false
+		}
+	}
+	return false
+}
+
+func (r *recordStream) fail(err error) {
+	r.err = err
+	if r.cur != nil {
+		r.cur.Release()
+		r.cur = nil
+	}
+}
+
+func (r *recordStream) Retain() {
+	atomic.AddInt64(&r.refs, 1)
+}
+
+func (r *recordStream) Release() {
+	if atomic.AddInt64(&r.refs, -1) > 0 {
+		return
+	}
+	if r.cur != nil {
+		r.cur.Release()
+		r.cur = nil
+	}
+}
@@ -0,0 +1,374 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/parquet/metadata"
+	"github.com/milvus-io/milvus-storage/go/common/errors"
+	"github.com/milvus-io/milvus-storage/go/file/fragment"
+	"github.com/milvus-io/milvus-storage/go/filter"
+	"github.com/milvus-io/milvus-storage/go/io/format/parquet"
+	"github.com/milvus-io/milvus-storage/go/storage/auth"
+	"github.com/milvus-io/milvus-storage/go/storage/manifest"
+	"github.com/milvus-io/milvus-storage/go/storage/options/option"
+)
+
+var ErrUnsupportedAggregate = errors.New("unsupported aggregate")
+
+func (s *Space) Aggregate(aggs []option.AggSpec, filters []filter.Filter) ([]any, error) {
+	return s.AggregateContext(context.Background(), aggs, filters)
+}
+
+// AggregateContext computes aggs over the rows that pass filters and returns their values in
+// the same order. Counts are int64, sums int64 for integer columns and float64 for floating
+// point ones, minimums and maximums have the Go type of the column values, or are nil if
+// there is no value. Without filters, deletes and row filters the file statistics answer
+// the counts, and the minimums and maximums of int32, int64, float32 and float64 columns
+// when every row group has them. Otherwise only the columns of the aggregates and filters
+// are read. ctx carries the caller identity, it requires auth.OpRead.
+func (s *Space) AggregateContext(ctx context.Context, aggs []option.AggSpec, filters []filter.Filter) ([]any, error) {
+	if err := s.authorize(ctx, auth.OpRead); err != nil {
+		return nil, err
+	}
+	m, err := s.readSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkAggregates(ctx, m, aggs); err != nil {
+		return nil, err
+	}
+
+	results := make([]any, len(aggs))
+	exact := len(filters) == 0 && len(m.GetDeleteFragments()) == 0 && s.rowFilter == nil
+	var scanned []int
+	for i, agg := range aggs {
+		if exact {
+			value, ok, err := s.statsAggregate(m, agg)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				results[i] = value
+				continue
+			}
+		}
+		scanned = append(scanned, i)
+	}
+	if len(scanned) == 0 {
+		return results, nil
+	}
+
+	aggregators := make([]*aggregator, len(scanned))
+	var columns []string
+	for i, index := range scanned {
+		aggregators[i] = newAggregator(m, aggs[index])
+		columns = append(columns, aggs[index].Column)
+	}
+	reader, err := s.aggregateReader(ctx, m, columns, filters)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Release()
+	for reader.Next() {
+		rec := reader.Record()
+		for _, a := range aggregators {
+			var column arrow.Array
+			if a.spec.Column != "" {
+				column = rec.Column(rec.Schema().FieldIndices(a.spec.Column)[0])
+			}
+			a.add(rec.NumRows(), column)
+		}
+	}
+	if err := reader.Err(); err != nil {
+		return nil, err
+	}
+	for i, index := range scanned {
+		results[index] = aggregators[i].result()
+	}
+	return results, nil
+}
+
+// checkAggregates checks that aggs are supported by the schema of m and do not read columns
+// masked for the caller.
+func (s *Space) checkAggregates(ctx context.Context, m *manifest.Manifest, aggs []option.AggSpec) error {
+	for _, agg := range aggs {
+		if err := checkAggregate(m, agg); err != nil {
+			return err
+		}
+		if err := s.checkUnmasked(ctx, agg.Column); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkUnmasked fails if column is masked for the caller, aggregates would reveal its values.
+func (s *Space) checkUnmasked(ctx context.Context, column string) error {
+	if s.masking == nil || column == "" {
+		return nil
+	}
+	if _, ok := s.masking(auth.IdentityFromContext(ctx))[column]; ok {
+		return errors.WithKind(errors.ErrPermissionDenied, fmt.Errorf("aggregate masked column %s", column))
+	}
+	return nil
+}
+
+// aggregateReader reads columns, empty names are ignored, of the rows of m that pass filters.
+func (s *Space) aggregateReader(ctx context.Context, m *manifest.Manifest, columns []string, filters []filter.Filter) (array.RecordReader, error) {
+	readOption := option.NewReadOptions()
+	addColumn := func(column string) {
+		if column != "" && !readOption.HasColumn(column) {
+			readOption.AddColumn(column)
+		}
+	}
+	for _, column := range columns {
+		addColumn(column)
+	}
+	for _, f := range filters {
+		readOption.AddFilter(f)
+		addColumn(f.GetColumnName())
+	}
+	if len(readOption.Columns) == 0 {
+		addColumn(m.GetSchema().Options().PrimaryColumn)
+	}
+	return s.read(ctx, m, readOption)
+}
+
+func checkAggregate(m *manifest.Manifest, agg option.AggSpec) error {
+	if agg.Column == "" {
+		if agg.Func != option.AggCount {
+			return fmt.Errorf("aggregate %d without column: %w", agg.Func, ErrUnsupportedAggregate)
+		}
+		return nil
+	}
+	fields, ok := m.GetSchema().Schema().FieldsByName(agg.Column)
+	if !ok {
+		return fmt.Errorf("aggregate %s: %w", agg.Column, ErrColumnNotExist)
+	}
+	dataType := fields[0].Type
+	switch agg.Func {
+	case option.AggCount:
+		return nil
+	case option.AggMin, option.AggMax:
+		if arrow.IsInteger(dataType.ID()) || arrow.IsFloating(dataType.ID()) || dataType.ID() == arrow.STRING {
+			return nil
+		}
+	case option.AggSum:
+		if arrow.IsInteger(dataType.ID()) || arrow.IsFloating(dataType.ID()) {
+			return nil
+		}
+	}
+	return fmt.Errorf("aggregate %d of %s column %s: %w", agg.Func, dataType, agg.Column, ErrUnsupportedAggregate)
+}
+
+// statsAggregate answers agg from the manifest and the footers of the data files, it returns
+// false if they are not enough.
+func (s *Space) statsAggregate(m *manifest.Manifest, agg option.AggSpec) (any, bool, error) {
+	if agg.Func == option.AggCount {
+		if agg.Column != "" {
+			// null counts are not kept in the manifest
+			if fields, _ := m.GetSchema().Schema().FieldsByName(agg.Column); fields[0].Nullable {
+				return nil, false, nil
+			}
+		}
+		rows, err := s.storedRows(m)
+		return rows, err == nil, err
+	}
+	if agg.Func != option.AggMin && agg.Func != option.AggMax {
+		return nil, false, nil
+	}
+	fields, _ := m.GetSchema().Schema().FieldsByName(agg.Column)
+	switch fields[0].Type.ID() {
+	case arrow.INT32, arrow.INT64, arrow.FLOAT32, arrow.FLOAT64:
+	default:
+		return nil, false, nil
+	}
+
+	fragments := m.GetVectorFragments()
+	if _, ok := m.GetSchema().ScalarSchema().FieldsByName(agg.Column); ok {
+		fragments = m.GetScalarFragments()
+	}
+	a := newAggregator(m, agg)
+	for _, path := range fragment.ToFilesVector(fragments) {
+		reader, err := parquet.NewFileReader(s.fs, path, option.NewReadOptions())
+		if err != nil {
+			return nil, false, fmt.Errorf("aggregate: %w", err)
+		}
+		stats, ok := reader.ColumnStatistics(agg.Column)
+		reader.Close()
+		if !ok {
+			return nil, false, nil
+		}
+		for _, rowGroupStats := range stats {
+			if !a.addStats(rowGroupStats) {
+				return nil, false, nil
+			}
+		}
+	}
+	return a.result(), true, nil
+}
+
+// aggregator accumulates one aggregate over record batches or row group statistics.
+type aggregator struct {
+	spec     option.AggSpec
+	count    int64
+	intSum   int64
+	floatSum float64
+	floating bool
+	value    any
+}
+
+func newAggregator(m *manifest.Manifest, agg option.AggSpec) *aggregator {
+	a := &aggregator{spec: agg}
+	if fields, ok := m.GetSchema().Schema().FieldsByName(agg.Column); ok {
+		a.floating = arrow.IsFloating(fields[0].Type.ID())
+	}
+	return a
+}
+
+func (a *aggregator) result() any {
+	switch a.spec.Func {
+	case option.AggCount:
+		return a.count
+	case option.AggSum:
+		if a.floating {
+			return a.floatSum
+		}
+		return a.intSum
+	default:
+		return a.value
+	}
+}
+
+// add adds a batch of rows, column is nil for a count of rows.
+func (a *aggregator) add(rows int64, column arrow.Array) {
+	switch a.spec.Func {
+	case option.AggCount:
+		if column == nil {
+			a.count += rows
+		} else {
+			a.count += int64(column.Len() - column.NullN())
+		}
+	case option.AggSum:
+		switch c := column.(type) {
+		case *array.Int8:
+			a.intSum += sumValues[int8, int64](c)
+		case *array.Int16:
+			a.intSum += sumValues[int16, int64](c)
+		case *array.Int32:
+			a.intSum += sumValues[int32, int64](c)
+		case *array.Int64:
+			a.intSum += sumValues[int64, int64](c)
+		case *array.Uint8:
+			a.intSum += sumValues[uint8, int64](c)
+		case *array.Uint16:
+			a.intSum += sumValues[uint16, int64](c)
+		case *array.Uint32:
+			a.intSum += sumValues[uint32, int64](c)
+		case *array.Uint64:
+			a.intSum += sumValues[uint64, int64](c)
+		case *array.Float32:
+			a.floatSum += sumValues[float32, float64](c)
+		case *array.Float64:
+			a.floatSum += sumValues[float64, float64](c)
+		}
+	default:
+		max := a.spec.Func == option.AggMax
+		switch c := column.(type) {
+		case *array.Int8:
+			a.value = extreme[int8](a.value, c, max)
+		case *array.Int16:
+			a.value = extreme[int16](a.value, c, max)
+		case *array.Int32:
+			a.value = extreme[int32](a.value, c, max)
+		case *array.Int64:
+			a.value = extreme[int64](a.value, c, max)
+		case *array.Uint8:
+			a.value = extreme[uint8](a.value, c, max)
+		case *array.Uint16:
+			a.value = extreme[uint16](a.value, c, max)
+		case *array.Uint32:
+			a.value = extreme[uint32](a.value, c, max)
+		case *array.Uint64:
+			a.value = extreme[uint64](a.value, c, max)
+		case *array.Float32:
+			a.value = extreme[float32](a.value, c, max)
+		case *array.Float64:
+			a.value = extreme[float64](a.value, c, max)
+		case *array.String:
+			a.value = extreme[string](a.value, c, max)
+		}
+	}
+}
+
+// addStats adds the minimum or maximum of a row group, it returns false if stats do not have
+// them. A row group of nulls only has no minimum and maximum either.
+func (a *aggregator) addStats(stats metadata.TypedStatistics) bool {
+	if stats == nil || !stats.HasMinMax() {
+		return false
+	}
+	max := a.spec.Func == option.AggMax
+	switch s := stats.(type) {
+	case *metadata.Int32Statistics:
+		a.value = extreme[int32](a.value, statsValues[int32]{s.Min(), s.Max()}, max)
+	case *metadata.Int64Statistics:
+		a.value = extreme[int64](a.value, statsValues[int64]{s.Min(), s.Max()}, max)
+	case *metadata.Float32Statistics:
+		a.value = extreme[float32](a.value, statsValues[float32]{s.Min(), s.Max()}, max)
+	case *metadata.Float64Statistics:
+		a.value = extreme[float64](a.value, statsValues[float64]{s.Min(), s.Max()}, max)
+	default:
+		return false
+	}
+	return true
+}
+
+type ordered interface {
+	~int8 | ~int16 | ~int32 | ~int64 | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~float32 | ~float64 | ~string
+}
+
+type number interface {
+	~int8 | ~int16 | ~int32 | ~int64 | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~float32 | ~float64
+}
+
+// values is the subset of the typed Arrow arrays the aggregates use.
+type values[T any] interface {
+	Len() int
+	IsNull(i int) bool
+	Value(i int) T
+}
+
+// statsValues holds the minimum and maximum of a row group as values.
+type statsValues[T any] [2]T
+
+func (v statsValues[T]) Len() int        { return 2 }
+func (v statsValues[T]) IsNull(int) bool { return false }
+func (v statsValues[T]) Value(i int) T   { return v[i] }
+
+func sumValues[T number, S int64 | float64](column values[T]) S {
+	var sum S
+	for i := 0; i < column.Len(); i++ {
+		if !column.IsNull(i) {
+			sum += S(column.Value(i))
+		}
+	}
+	return sum
+}
+
+// extreme returns the minimum, or maximum, of current and the non null values of column.
+// current is nil or a T.
+func extreme[T ordered](current any, column values[T], max bool) any {
+	for i := 0; i < column.Len(); i++ {
+		if column.IsNull(i) {
+			continue
+		}
+		value := column.Value(i)
+		if current == nil || (max && value > current.(T)) || (!max && value < current.(T)) {
+			current = value
+		}
+	}
+	return current
+}
@@ -0,0 +1,345 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/milvus-io/milvus-storage/go/common/constant"
+	"github.com/milvus-io/milvus-storage/go/common/errors"
+	"github.com/milvus-io/milvus-storage/go/storage/auth"
+	"github.com/milvus-io/milvus-storage/go/storage/manifest"
+	"github.com/milvus-io/milvus-storage/go/storage/options/option"
+)
+
+var ErrUnsupportedAnalyze = errors.New("unsupported analyze")
+
+// ColumnStats are the statistics of a column computed by Analyze over the rows visible in
+// Version. Later commits keep them as they are, they drift as rows are written and deleted
+// until the column is analyzed again.
+type ColumnStats struct {
+	Version int64 `json:"version"`
+	Rows    int64 `json:"rows"`
+	Nulls   int64 `json:"nulls"`
+	// NDV is the number of distinct non null values, estimated if Sampled.
+	NDV     int64 `json:"ndv"`
+	Sampled bool  `json:"sampled,omitempty"`
+	// Histogram splits the non null values of numeric columns in buckets of about the same
+	// number of rows, in increasing order.
+	Histogram []HistogramBucket `json:"histogram,omitempty"`
+}
+
+// HistogramBucket holds the values above the upper bound of the previous bucket and up to
+// Upper included.
+type HistogramBucket struct {
+	Upper float64 `json:"upper"`
+	Rows  int64   `json:"rows"`
+}
+
+// Analyze computes the statistics of columns over every row and commits them, see
+// AnalyzeContext.
+func (s *Space) Analyze(columns ...string) (int64, error) {
+	return s.AnalyzeContext(context.Background(), option.NewAnalyzeOptions(), columns...)
+}
+
+// AnalyzeContext scans columns, all of them but the vector columns if none is given, computes
+// their statistics and commits them in a new version, whose number is returned. The
+// statistics are kept in the properties of the version, and of the later ones, until the
+// column is analyzed again, see ColumnStats. ctx carries the caller identity, it requires
+// auth.OpRead and auth.OpWrite, and columns masked for the caller cannot be analyzed.
+func (s *Space) AnalyzeContext(ctx context.Context, opts *option.AnalyzeOptions, columns ...string) (int64, error) {
+	if err := s.authorize(ctx, auth.OpRead); err != nil {
+		return 0, err
+	}
+	if err := s.authorize(ctx, auth.OpWrite); err != nil {
+		return 0, err
+	}
+	m, err := s.readSnapshot()
+	if err != nil {
+		return 0, err
+	}
+	if len(columns) == 0 {
+		for _, field := range m.GetSchema().Schema().Fields() {
+			if !m.GetSchema().Options().IsVectorColumn(field.Name) {
+				columns = append(columns, field.Name)
+			}
+		}
+	}
+	for _, column := range columns {
+		fields, ok := m.GetSchema().Schema().FieldsByName(column)
+		if !ok {
+			return 0, fmt.Errorf("analyze %s: %w", column, ErrColumnNotExist)
+		}
+		if !analyzable(fields[0].Type) {
+			return 0, fmt.Errorf("analyze %s column %s: %w", fields[0].Type, column, ErrUnsupportedAnalyze)
+		}
+		if err := s.checkUnmasked(ctx, column); err != nil {
+			return 0, err
+		}
+	}
+
+	stats, err := s.analyze(ctx, m, opts, columns)
+	if err != nil {
+		return 0, err
+	}
+	properties := make(map[string]string, len(stats))
+	for i, column := range columns {
+		encoded, err := json.Marshal(stats[i])
+		if err != nil {
+			return 0, fmt.Errorf("analyze %s: %w", column, err)
+		}
+		properties[constant.StatsPropertyPrefix+column] = string(encoded)
+	}
+
+	var committed int64
+	record := &option.AuditRecord{Operation: auth.OpWrite}
+	err = s.commit(ctx, record, func(m *manifest.Manifest, version int64) error {
+		m.SetProperties(mergeProperties(m.Properties(), properties))
+		committed = version
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return committed, nil
+}
+
+// ColumnStats returns the statistics of column computed by the last Analyze of it, false if
+// it was never analyzed.
+func (s *Space) ColumnStats(column string) (ColumnStats, bool, error) {
+	return s.ColumnStatsContext(context.Background(), column)
+}
+
+// ColumnStatsContext is like ColumnStats, ctx carries the caller identity, it requires
+// auth.OpRead and the statistics of columns masked for the caller cannot be read.
+func (s *Space) ColumnStatsContext(ctx context.Context, column string) (ColumnStats, bool, error) {
+	var stats ColumnStats
+	if err := s.authorize(ctx, auth.OpRead); err != nil {
+		return stats, false, err
+	}
+	if err := s.checkUnmasked(ctx, column); err != nil {
+		return stats, false, err
+	}
+	m, err := s.readSnapshot()
+	if err != nil {
+		return stats, false, err
+	}
+	encoded, ok := m.Properties()[constant.StatsPropertyPrefix+column]
+	if !ok {
+		return stats, false, nil
+	}
+	if err := json.Unmarshal([]byte(encoded), &stats); err != nil {
+		return stats, false, fmt.Errorf("column stats of %s: %w", column, err)
+	}
+	return stats, true, nil
+}
+
+// statsProperties returns the column statistics of properties, which later versions keep.
+func statsProperties(properties map[string]string) map[string]string {
+	var stats map[string]string
+	for key, value := range properties {
+		if strings.HasPrefix(key, constant.StatsPropertyPrefix) {
+			if stats == nil {
+				stats = make(map[string]string)
+			}
+			stats[key] = value
+		}
+	}
+	return stats
+}
+
+// mergeProperties returns the properties of base overridden by those of properties.
+func mergeProperties(base map[string]string, properties map[string]string) map[string]string {
+	if len(base) == 0 {
+		return properties
+	}
+	merged := make(map[string]string, len(base)+len(properties))
+	for key, value := range base {
+		merged[key] = value
+	}
+	for key, value := range properties {
+		merged[key] = value
+	}
+	return merged
+}
+
+func analyzable(t arrow.DataType) bool {
+	switch t.ID() {
+	case arrow.BOOL, arrow.STRING, arrow.BINARY:
+		return true
+	}
+	return arrow.IsInteger(t.ID()) || arrow.IsFloating(t.ID())
+}
+
+// columnAnalyzer accumulates the statistics of a column over the rows read and the values of
+// the rows sampled.
+type columnAnalyzer struct {
+	stats    ColumnStats
+	numeric  bool
+	distinct map[any]int64
+	values   []float64
+	sampled  int64
+}
+
+func (s *Space) analyze(ctx context.Context, m *manifest.Manifest, opts *option.AnalyzeOptions, columns []string) ([]ColumnStats, error) {
+	stride := int64(1)
+	if opts.SampleRows > 0 {
+		stored, err := s.storedRows(m)
+		if err != nil {
+			return nil, err
+		}
+		if stored > opts.SampleRows {
+			stride = (stored + opts.SampleRows - 1) / opts.SampleRows
+		}
+	}
+	analyzers := make([]*columnAnalyzer, len(columns))
+	for i, column := range columns {
+		fields, _ := m.GetSchema().Schema().FieldsByName(column)
+		analyzers[i] = &columnAnalyzer{
+			stats:    ColumnStats{Version: m.Version(), Sampled: stride > 1},
+			numeric:  arrow.IsInteger(fields[0].Type.ID()) || arrow.IsFloating(fields[0].Type.ID()),
+			distinct: make(map[any]int64),
+		}
+	}
+
+	reader, err := s.aggregateReader(ctx, m, columns, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Release()
+	var row int64
+	for reader.Next() {
+		rec := reader.Record()
+		for i, column := range columns {
+			analyzers[i].add(rec.Column(rec.Schema().FieldIndices(column)[0]), row, stride)
+		}
+		row += rec.NumRows()
+	}
+	if err := reader.Err(); err != nil {
+		return nil, fmt.Errorf("analyze: %w", err)
+	}
+
+	stats := make([]ColumnStats, len(columns))
+	for i, a := range analyzers {
+		stats[i] = a.result(opts.Buckets)
+	}
+	return stats, nil
+}
+
+// add adds the values of column, whose first row is row of the scan, the rows whose index is a
+// multiple of stride are sampled.
+func (a *columnAnalyzer) add(column arrow.Array, row int64, stride int64) {
+	a.stats.Rows += int64(column.Len())
+	a.stats.Nulls += int64(column.NullN())
+	for i := 0; i < column.Len(); i++ {
+		if (row+int64(i))%stride != 0 || column.IsNull(i) {
+			continue
+		}
+		value := analyzedValue(column, i)
+		a.distinct[value]++
+		a.sampled++
+		if a.numeric {
+			a.values = append(a.values, toFloat64(value))
+		}
+	}
+}
+
+func (a *columnAnalyzer) result(buckets int) ColumnStats {
+	stats := a.stats
+	stats.NDV = int64(len(a.distinct))
+	if stats.Sampled && a.sampled > 0 {
+		// GEE estimator: values seen once in the sample stand for sqrt(N/n) values each
+		var once int64
+		for _, count := range a.distinct {
+			if count == 1 {
+				once++
+			}
+		}
+		scale := math.Sqrt(float64(stats.Rows-stats.Nulls) / float64(a.sampled))
+		stats.NDV = int64(math.Round(scale*float64(once))) + stats.NDV - once
+	}
+	if len(a.values) == 0 || buckets <= 0 {
+		return stats
+	}
+	sort.Float64s(a.values)
+	if buckets > len(a.values) {
+		buckets = len(a.values)
+	}
+	scale := float64(stats.Rows-stats.Nulls) / float64(len(a.values))
+	start := 0
+	for b := 1; b <= buckets; b++ {
+		end := b * len(a.values) / buckets
+		stats.Histogram = append(stats.Histogram, HistogramBucket{
+			Upper: a.values[end-1],
+			Rows:  int64(math.Round(float64(end-start) * scale)),
+		})
+		start = end
+	}
+	return stats
+}
+
+// analyzedValue returns the value of row i of column as a comparable Go value.
+func analyzedValue(column arrow.Array, i int) any {
+	switch c := column.(type) {
+	case *array.Boolean:
+		return c.Value(i)
+	case *array.Int8:
+		return c.Value(i)
+	case *array.Int16:
+		return c.Value(i)
+	case *array.Int32:
+		return c.Value(i)
+	case *array.Int64:
+		return c.Value(i)
+	case *array.Uint8:
+		return c.Value(i)
+	case *array.Uint16:
+		return c.Value(i)
+	case *array.Uint32:
+		return c.Value(i)
+	case *array.Uint64:
+		return c.Value(i)
+	case *array.Float16:
+		return c.Value(i).Float32()
+	case *array.Float32:
+		return c.Value(i)
+	case *array.Float64:
+		return c.Value(i)
+	case *array.String:
+		return c.Value(i)
+	case *array.Binary:
+		return string(c.Value(i))
+	}
+	return nil
+}
+
+func toFloat64(value any) float64 {
+	switch v := value.(type) {
+	case int8:
+		return float64(v)
+	case int16:
+		return float64(v)
+	case int32:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case uint8:
+		return float64(v)
+	case uint16:
+		return float64(v)
+	case uint32:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	case float32:
+		return float64(v)
+	case float64:
+		return v
+	}
+	return 0
+}
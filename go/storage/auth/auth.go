@@ -0,0 +1,93 @@
+// Package auth lets services enforce access control inside the storage layer. The caller
+// identity travels in the context passed to the *Context methods of Space, and the
+// Authorizer configured in the space options decides whether an operation may proceed.
+package auth
+
+import (
+	"context"
+	"fmt"
+)
+
+type Operation int8
+
+const (
+	OpRead Operation = iota
+	OpWrite
+	OpDelete
+	OpReadBlob
+	OpWriteBlob
+	// OpAdmin covers operations that manage the space rather than its data, e.g. releasing
+	// the writer lease.
+	OpAdmin
+)
+
+func (o Operation) String() string {
+	switch o {
+	case OpRead:
+		return "read"
+	case OpWrite:
+		return "write"
+	case OpDelete:
+		return "delete"
+	case OpReadBlob:
+		return "read_blob"
+	case OpWriteBlob:
+		return "write_blob"
+	case OpAdmin:
+		return "admin"
+	default:
+		return "unknown"
+	}
+}
+
+func (o Operation) MarshalText() ([]byte, error) {
+	return []byte(o.String()), nil
+}
+
+func (o *Operation) UnmarshalText(text []byte) error {
+	for op := OpRead; op <= OpAdmin; op++ {
+		if op.String() == string(text) {
+			*o = op
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown operation %q", text)
+}
+
+// Identity describes the caller of an operation.
+type Identity struct {
+	User   string
+	Groups []string
+}
+
+// Anonymous is the identity of calls whose context carries none.
+var Anonymous = Identity{}
+
+type identityKey struct{}
+
+// WithIdentity returns a copy of ctx carrying id.
+func WithIdentity(ctx context.Context, id Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, id)
+}
+
+// IdentityFromContext returns the identity carried by ctx, or Anonymous.
+func IdentityFromContext(ctx context.Context) Identity {
+	if id, ok := ctx.Value(identityKey{}).(Identity); ok {
+		return id
+	}
+	return Anonymous
+}
+
+// Authorizer decides whether identity may perform op on the space at path. It is called
+// before the operation touches any file. A non-nil error rejects the operation and is
+// returned to the caller classified as errors.ErrPermissionDenied.
+type Authorizer interface {
+	Authorize(ctx context.Context, op Operation, path string, identity Identity) error
+}
+
+// AuthorizerFunc adapts a function to Authorizer.
+type AuthorizerFunc func(ctx context.Context, op Operation, path string, identity Identity) error
+
+func (f AuthorizerFunc) Authorize(ctx context.Context, op Operation, path string, identity Identity) error {
+	return f(ctx, op, path, identity)
+}
@@ -0,0 +1,32 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/milvus-io/milvus-storage/go/common/errors"
+	"github.com/milvus-io/milvus-storage/go/storage/auth"
+)
+
+// authorize asks the configured authorizer whether the identity in ctx may perform op.
+// Replicas only allow reads, closed spaces nothing.
+func (s *Space) authorize(ctx context.Context, op auth.Operation) error {
+	if atomic.LoadInt32(&s.closed) != 0 {
+		return fmt.Errorf("%s %s: %w", op, s.path, ErrSpaceClosed)
+	}
+	if s.replica != nil && op != auth.OpRead && op != auth.OpReadBlob {
+		return fmt.Errorf("%s %s: %w", op, s.path, ErrReadOnlyReplica)
+	}
+	if s.authorizer == nil {
+		return nil
+	}
+	identity := auth.IdentityFromContext(ctx)
+	if err := s.authorizer.Authorize(ctx, op, s.path, identity); err != nil {
+		if !errors.Is(err, errors.ErrPermissionDenied) {
+			err = errors.WithKind(errors.ErrPermissionDenied, err)
+		}
+		return fmt.Errorf("%s %s by %q: %w", op, s.path, identity.User, err)
+	}
+	return nil
+}
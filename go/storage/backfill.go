@@ -0,0 +1,193 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/compute"
+	"github.com/apache/arrow/go/v12/arrow/memory"
+	"github.com/milvus-io/milvus-storage/go/common/errors"
+	"github.com/milvus-io/milvus-storage/go/file/fragment"
+	"github.com/milvus-io/milvus-storage/go/storage/auth"
+	"github.com/milvus-io/milvus-storage/go/storage/manifest"
+	"github.com/milvus-io/milvus-storage/go/storage/options/option"
+	"github.com/milvus-io/milvus-storage/go/storage/schema"
+)
+
+var ErrColumnExists = errors.New("column already exists")
+
+// BackfillColumn adds column name with the values of src, see BackfillColumnContext.
+func (s *Space) BackfillColumn(name string, src array.RecordReader) error {
+	return s.BackfillColumnContext(context.Background(), name, src)
+}
+
+// BackfillColumnContext adds column name to the schema in a new version, e.g. a feature
+// computed from the existing rows. src holds the primary column and name, the rows of the
+// space get the value of their primary key in src, or null if it has none, so the column is
+// always nullable. Only the scalar files are rewritten, the vector files are kept. It fails
+// with ErrManifestConflict if data is written meanwhile, since those files lack the column,
+// and with ErrFragmentPinned if fragments are pinned.
+// ctx carries the caller identity, it requires auth.OpAdmin.
+func (s *Space) BackfillColumnContext(ctx context.Context, name string, src array.RecordReader) error {
+	if err := s.authorize(ctx, auth.OpAdmin); err != nil {
+		return err
+	}
+	m := s.snapshot()
+	if err := m.ValidateDataFragments(); err != nil {
+		return fmt.Errorf("backfill %s: %w", name, err)
+	}
+	if pending, err := s.pendingTxns(m); err != nil {
+		return fmt.Errorf("backfill %s: %w", name, err)
+	} else if len(pending) > 0 {
+		return fmt.Errorf("backfill %s with %d transactions in progress: %w", name, len(pending), ErrTransactionPending)
+	}
+	if pinned, _ := splitPinned(m, m.GetScalarFragments()); len(pinned) > 0 {
+		return fmt.Errorf("backfill %s with %d fragments pinned: %w", name, len(pinned), ErrFragmentPinned)
+	}
+	sc, err := backfillSchema(m.GetSchema(), name, src.Schema())
+	if err != nil {
+		return err
+	}
+	values, rows, err := loadBackfill(src, m.GetSchema().Options().PrimaryColumn, name)
+	if err != nil {
+		return err
+	}
+	defer values.Release()
+
+	result := s.newCompaction(nil)
+	result.addKnownSizes(m.GetScalarFragments())
+	var scalarFragments fragment.FragmentVector
+	for _, f := range m.GetScalarFragments() {
+		rewritten := fragment.NewFragment(f.FragmentId())
+		rewritten.SetTxn(f.Txn())
+		for _, path := range f.Files() {
+			w := s.newRewriter(result, sc.ScalarSchema(), true)
+			if err := s.backfillFile(result, w, path, sc, values, rows); err != nil {
+				return err
+			}
+			file, stats, err := w.close(result)
+			if err != nil {
+				return err
+			}
+			rewritten.AddFileWithStats(file, stats)
+		}
+		scalarFragments = append(scalarFragments, *rewritten)
+	}
+
+	record := &option.AuditRecord{Operation: auth.OpAdmin, Files: result.newFiles}
+	return s.commit(ctx, record, func(latest *manifest.Manifest, version int64) error {
+		if !latest.GetSchema().Schema().Equal(m.GetSchema().Schema()) ||
+			len(latest.GetScalarFragments()) != len(m.GetScalarFragments()) ||
+			!hasPrefix(latest.GetScalarFragments(), m.GetScalarFragments()) ||
+			!samePins(latest, m) {
+			return fmt.Errorf("backfill %s of version %d: %w", name, m.Version(), ErrManifestConflict)
+		}
+		latest.SetSchema(sc)
+		latest.SetScalarFragments(scalarFragments)
+		latest.AddUsage(0, result.bytesDelta)
+		return nil
+	})
+}
+
+// backfillSchema returns sc with column name of type that of src appended.
+func backfillSchema(sc *schema.Schema, name string, src *arrow.Schema) (*schema.Schema, error) {
+	if _, ok := sc.Schema().FieldsByName(name); ok {
+		return nil, fmt.Errorf("backfill %s: %w", name, ErrColumnExists)
+	}
+	primary, _ := sc.Schema().FieldsByName(sc.Options().PrimaryColumn)
+	srcPrimary, ok := src.FieldsByName(sc.Options().PrimaryColumn)
+	if !ok || !arrow.TypeEqual(srcPrimary[0].Type, primary[0].Type) {
+		return nil, fmt.Errorf("backfill %s without primary column %s of type %s: %w", name, primary[0].Name, primary[0].Type, ErrSchemaNotMatch)
+	}
+	fields, ok := src.FieldsByName(name)
+	if !ok {
+		return nil, fmt.Errorf("backfill %s: %w", name, ErrColumnNotExist)
+	}
+	field := fields[0]
+	field.Nullable = true
+	backfilled := schema.NewSchema(arrow.NewSchema(append(sc.Schema().Fields(), field), nil), sc.Options())
+	if err := backfilled.Validate(); err != nil {
+		return nil, fmt.Errorf("backfill %s: %w", name, err)
+	}
+	return backfilled, nil
+}
+
+// loadBackfill reads src until its end and returns the values of column name with the row of
+// every primary key.
+func loadBackfill(src array.RecordReader, primary string, name string) (arrow.Array, map[any]int64, error) {
+	var chunks []arrow.Array
+	defer func() {
+		for _, chunk := range chunks {
+			chunk.Release()
+		}
+	}()
+	keys := newKeyTracker(primary)
+	var n int64
+	for src.Next() {
+		rec := src.Record()
+		if err := keys.check(rec, n); err != nil {
+			return nil, nil, fmt.Errorf("backfill %s: %w", name, err)
+		}
+		column := rec.Column(rec.Schema().FieldIndices(name)[0])
+		column.Retain()
+		chunks = append(chunks, column)
+		n += rec.NumRows()
+	}
+	if err := src.Err(); err != nil {
+		return nil, nil, fmt.Errorf("backfill %s: %w", name, err)
+	}
+	if len(chunks) == 0 {
+		fields, _ := src.Schema().FieldsByName(name)
+		return array.MakeArrayOfNull(memory.DefaultAllocator, fields[0].Type, 0), keys.rows, nil
+	}
+	values, err := array.Concatenate(chunks, memory.DefaultAllocator)
+	if err != nil {
+		return nil, nil, fmt.Errorf("backfill %s: %w", name, err)
+	}
+	return values, keys.rows, nil
+}
+
+// backfillFile appends the rows of the scalar file at path to w with the values of their
+// primary key.
+func (s *Space) backfillFile(result *compaction, w *rewriter, path string, sc *schema.Schema, values arrow.Array, rows map[any]int64) error {
+	size, err := result.fileSize(result.fs, path)
+	if err != nil {
+		return err
+	}
+	result.bytesDelta -= size
+	name := sc.Schema().Field(len(sc.Schema().Fields()) - 1).Name
+	return readFile(result.fs, path, option.NewReadOptions(), func(rec arrow.Record) error {
+		keys := rec.Column(rec.Schema().FieldIndices(sc.Options().PrimaryColumn)[0])
+		builder := array.NewInt64Builder(memory.DefaultAllocator)
+		defer builder.Release()
+		for i := 0; i < keys.Len(); i++ {
+			if row, ok := rows[keyAt(keys, i)]; ok {
+				builder.Append(row)
+			} else {
+				builder.AppendNull()
+			}
+		}
+		indices := builder.NewArray()
+		defer indices.Release()
+		column, err := compute.TakeArray(context.Background(), values, indices)
+		if err != nil {
+			return fmt.Errorf("backfill %s of %s: %w", name, path, err)
+		}
+		defer column.Release()
+
+		// the file may still hold a dropped column of the same name
+		var fields []arrow.Field
+		var columns []arrow.Array
+		for i, field := range rec.Schema().Fields() {
+			if field.Name != name {
+				fields, columns = append(fields, field), append(columns, rec.Column(i))
+			}
+		}
+		fields = append(fields, sc.Schema().Field(len(sc.Schema().Fields())-1))
+		backfilled := array.NewRecord(arrow.NewSchema(fields, nil), append(columns, column), rec.NumRows())
+		defer backfilled.Release()
+		return w.write(backfilled)
+	})
+}
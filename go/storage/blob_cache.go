@@ -0,0 +1,149 @@
+package storage
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/milvus-io/milvus-storage/go/file/blob"
+	"github.com/milvus-io/milvus-storage/go/storage/options/option"
+)
+
+var (
+	_ option.BlobCache   = (*LRUBlobCache)(nil)
+	_ option.Invalidator = (*LRUBlobCache)(nil)
+)
+
+// LRUBlobCache is a BlobCache holding up to a number of bytes, the least recently used
+// entries are evicted first. Content larger than the capacity is not cached.
+type LRUBlobCache struct {
+	mu       sync.Mutex
+	capacity int64
+	size     int64
+	entries  map[string]*list.Element
+	order    *list.List
+	hits     int64
+	misses   int64
+}
+
+type lruEntry struct {
+	file    string
+	content []byte
+}
+
+func NewLRUBlobCache(capacity int64) *LRUBlobCache {
+	return &LRUBlobCache{
+		capacity: capacity,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// Get returns the cached content of file, which must not be modified.
+func (c *LRUBlobCache) Get(file string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[file]
+	if !ok {
+		atomic.AddInt64(&c.misses, 1)
+		return nil, false
+	}
+	atomic.AddInt64(&c.hits, 1)
+	c.order.MoveToFront(e)
+	return e.Value.(*lruEntry).content, true
+}
+
+func (c *LRUBlobCache) Add(file string, content []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if int64(len(content)) > c.capacity {
+		return
+	}
+	if e, ok := c.entries[file]; ok {
+		c.order.MoveToFront(e)
+		return
+	}
+	c.entries[file] = c.order.PushFront(&lruEntry{file: file, content: content})
+	c.size += int64(len(content))
+	for c.size > c.capacity {
+		oldest := c.order.Back()
+		entry := oldest.Value.(*lruEntry)
+		c.order.Remove(oldest)
+		delete(c.entries, entry.file)
+		c.size -= int64(len(entry.content))
+	}
+}
+
+// Invalidate drops the entries of the files match reports.
+func (c *LRUBlobCache) Invalidate(match func(file string) bool) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var n int
+	for file, e := range c.entries {
+		if !match(file) {
+			continue
+		}
+		c.order.Remove(e)
+		delete(c.entries, file)
+		c.size -= int64(len(e.Value.(*lruEntry).content))
+		n++
+	}
+	return n
+}
+
+// Size returns the number of bytes cached.
+func (c *LRUBlobCache) Size() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.size
+}
+
+// Stats returns the number of lookups that found an entry and of those that did not.
+func (c *LRUBlobCache) Stats() (hits int64, misses int64) {
+	return atomic.LoadInt64(&c.hits), atomic.LoadInt64(&c.misses)
+}
+
+// blobContent returns the verified and decompressed content of a blob that is not chunked.
+func (s *Space) blobContent(b blob.Blob) ([]byte, error) {
+	if s.blobCache != nil {
+		if content, ok := s.blobCache.Get(b.File); ok {
+			return content, nil
+		}
+	}
+	stored, err := s.fs.ReadFile(b.File)
+	if err != nil {
+		return nil, err
+	}
+	content, err := b.Codec.Decompress(stored, b.Size)
+	if err != nil {
+		return nil, fmt.Errorf("read blob %s: %w", b.Name, err)
+	}
+	if int64(len(content)) != b.Size || !b.Verify(content) {
+		return nil, fmt.Errorf("read blob %s: %w", b.Name, ErrBlobCorrupted)
+	}
+	if s.blobCache != nil {
+		s.blobCache.Add(b.File, content)
+	}
+	return content, nil
+}
+
+// chunkContent returns the verified content of chunk c of b.
+func (s *Space) chunkContent(b blob.Blob, c blob.Chunk) ([]byte, error) {
+	if s.blobCache != nil {
+		if content, ok := s.blobCache.Get(c.File); ok {
+			return content, nil
+		}
+	}
+	content, err := s.fs.ReadFile(c.File)
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(content)) != c.Size || !c.Verify(content) {
+		return nil, fmt.Errorf("read blob %s chunk %s: %w", b.Name, c.File, ErrBlobCorrupted)
+	}
+	if s.blobCache != nil {
+		s.blobCache.Add(c.File, content)
+	}
+	return content, nil
+}
@@ -0,0 +1,311 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/milvus-io/milvus-storage/go/common/errors"
+	"github.com/milvus-io/milvus-storage/go/common/utils"
+	"github.com/milvus-io/milvus-storage/go/file/blob"
+	"github.com/milvus-io/milvus-storage/go/io/fs"
+	"github.com/milvus-io/milvus-storage/go/storage/auth"
+	"github.com/milvus-io/milvus-storage/go/storage/options/option"
+)
+
+var (
+	ErrBlobWriterClosed = errors.New("blob writer closed")
+	ErrBlobChunked      = errors.New("blob is chunked")
+)
+
+// BlobWriter writes a blob as a sequence of chunk files, so that blobs larger than memory
+// can be streamed, uploaded in parallel and read by range. The blob becomes visible when
+// Close commits it. An interrupted upload can be continued with Space.ResumeBlobWriter from
+// Offset, given its UploadID.
+type BlobWriter struct {
+	space    *Space
+	ctx      context.Context
+	name     string
+	options  *option.BlobWriterOptions
+	uploadID string
+	buf      []byte
+	offset   int64
+	sem      chan struct{}
+	wg       sync.WaitGroup
+	closed   bool
+
+	mu     sync.Mutex
+	chunks []blob.Chunk
+	err    error
+}
+
+func (s *Space) NewBlobWriter(name string, options *option.BlobWriterOptions) (*BlobWriter, error) {
+	return s.NewBlobWriterContext(context.Background(), name, options)
+}
+
+// NewBlobWriterContext is like NewBlobWriter, ctx carries the caller identity checked by the
+// authorizer.
+func (s *Space) NewBlobWriterContext(ctx context.Context, name string, options *option.BlobWriterOptions) (*BlobWriter, error) {
+	return s.newBlobWriter(ctx, name, uuid.New().String(), options)
+}
+
+func (s *Space) ResumeBlobWriter(name string, uploadID string, options *option.BlobWriterOptions) (*BlobWriter, error) {
+	return s.ResumeBlobWriterContext(context.Background(), name, uploadID, options)
+}
+
+// ResumeBlobWriterContext continues the upload uploadID of a writer that was not closed.
+// Complete chunks are kept and their content is read back to compute their checksum, the
+// caller continues writing from Offset.
+func (s *Space) ResumeBlobWriterContext(ctx context.Context, name string, uploadID string, options *option.BlobWriterOptions) (*BlobWriter, error) {
+	w, err := s.newBlobWriter(ctx, name, uploadID, options)
+	if err != nil {
+		return nil, err
+	}
+	for index := 0; ; index++ {
+		path := utils.GetBlobChunkFilePath(s.path, uploadID, index)
+		exist, err := s.fs.Exist(path)
+		if err != nil {
+			return nil, fmt.Errorf("resume blob %s: %w", name, err)
+		}
+		if !exist {
+			break
+		}
+		content, err := s.fs.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("resume blob %s: %w", name, err)
+		}
+		// the last chunk is only written by Close, a short chunk is rewritten
+		if int64(len(content)) != w.options.ChunkSize {
+			break
+		}
+		w.chunks = append(w.chunks, blob.Chunk{File: path, Size: int64(len(content)), Checksum: blob.Checksum(content)})
+		w.offset += int64(len(content))
+	}
+	return w, nil
+}
+
+func (s *Space) newBlobWriter(ctx context.Context, name string, uploadID string, options *option.BlobWriterOptions) (*BlobWriter, error) {
+	if err := s.authorize(ctx, auth.OpWriteBlob); err != nil {
+		return nil, err
+	}
+	if !options.Replace && s.snapshot().HasBlob(name) {
+		return nil, ErrBlobAlreadyExist
+	}
+	copied := *options
+	if copied.ChunkSize <= 0 {
+		copied.ChunkSize = option.DefaultBlobChunkSize
+	}
+	if copied.Parallelism <= 0 {
+		copied.Parallelism = option.DefaultBlobParallelism
+	}
+	copied.Metadata = copyMetadata(options.Metadata)
+	return &BlobWriter{
+		space:    s,
+		ctx:      ctx,
+		name:     name,
+		options:  &copied,
+		uploadID: uploadID,
+		sem:      make(chan struct{}, copied.Parallelism),
+	}, nil
+}
+
+func (w *BlobWriter) UploadID() string {
+	return w.uploadID
+}
+
+// Offset returns the number of bytes written so far.
+func (w *BlobWriter) Offset() int64 {
+	return w.offset + int64(len(w.buf))
+}
+
+// Write buffers p and uploads every complete chunk in the background. Upload errors are
+// returned by later calls to Write or Close.
+func (w *BlobWriter) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, ErrBlobWriterClosed
+	}
+	if err := w.uploadErr(); err != nil {
+		return 0, err
+	}
+	written := 0
+	for len(p) > 0 {
+		n := int(w.options.ChunkSize) - len(w.buf)
+		if n > len(p) {
+			n = len(p)
+		}
+		w.buf = append(w.buf, p[:n]...)
+		p = p[n:]
+		written += n
+		if int64(len(w.buf)) == w.options.ChunkSize {
+			w.upload()
+		}
+	}
+	return written, nil
+}
+
+// upload writes the buffered chunk in the background.
+func (w *BlobWriter) upload() {
+	index := int(w.offset / w.options.ChunkSize)
+	content := w.buf
+	w.offset += int64(len(content))
+	w.buf = nil
+
+	w.sem <- struct{}{}
+	w.wg.Add(1)
+	go func() {
+		defer func() {
+			<-w.sem
+			w.wg.Done()
+		}()
+		path := utils.GetBlobChunkFilePath(w.space.path, w.uploadID, index)
+		err := fs.WriteFile(w.space.fs, path, content)
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		if err != nil {
+			if w.err == nil {
+				w.err = fmt.Errorf("write blob %s chunk %d: %w", w.name, index, err)
+			}
+			return
+		}
+		for len(w.chunks) <= index {
+			w.chunks = append(w.chunks, blob.Chunk{})
+		}
+		w.chunks[index] = blob.Chunk{File: path, Size: int64(len(content)), Checksum: blob.Checksum(content)}
+	}()
+}
+
+// Flush waits until the complete chunks written so far are uploaded, a resumed upload
+// continues after them.
+func (w *BlobWriter) Flush() error {
+	w.wg.Wait()
+	return w.uploadErr()
+}
+
+func (w *BlobWriter) uploadErr() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err
+}
+
+// Close uploads the last chunk, waits for all uploads and commits the blob. The chunks are
+// kept if it fails, so that the upload can be resumed.
+func (w *BlobWriter) Close() error {
+	if w.closed {
+		return ErrBlobWriterClosed
+	}
+	w.closed = true
+	if len(w.buf) > 0 || w.offset == 0 {
+		w.upload()
+	}
+	w.wg.Wait()
+	if err := w.uploadErr(); err != nil {
+		return err
+	}
+	_, err := w.space.commitBlob(w.ctx, blob.Blob{
+		Name:     w.name,
+		Size:     w.offset,
+		Metadata: w.options.Metadata,
+		Chunks:   w.chunks,
+	}, w.options.Replace)
+	return err
+}
+
+// Abort stops the upload and removes the chunks written so far.
+func (w *BlobWriter) Abort() error {
+	w.closed = true
+	w.wg.Wait()
+	for index := 0; int64(index)*w.options.ChunkSize <= w.offset; index++ {
+		if err := deleteIfExist(w.space.fs, utils.GetBlobChunkFilePath(w.space.path, w.uploadID, index)); err != nil {
+			return fmt.Errorf("abort blob %s: %w", w.name, err)
+		}
+	}
+	return nil
+}
+
+// readBlobAt reads len(p) bytes of b at off, like io.ReaderAt. Chunks are read in parallel
+// and verified as a whole, compressed blobs and blobs read through the cache are always read
+// and verified entirely, other blobs only verified when read entirely.
+func (s *Space) readBlobAt(b blob.Blob, p []byte, off int64) (int, error) {
+	if off >= b.Size {
+		return 0, io.EOF
+	}
+	end := off + int64(len(p))
+	if end > b.Size {
+		end = b.Size
+	}
+	if b.Codec != blob.CodecNone || (s.blobCache != nil && !b.Chunked()) {
+		content, err := s.blobContent(b)
+		if err != nil {
+			return 0, err
+		}
+		copy(p, content[off:end])
+	} else if !b.Chunked() {
+		f, err := s.fs.OpenFile(b.File)
+		if err != nil {
+			return 0, err
+		}
+		defer f.Close()
+		n, err := f.ReadAt(p[:end-off], off)
+		if err != nil && err != io.EOF {
+			return n, err
+		}
+		if off == 0 && int64(n) == b.Size && !b.Verify(p[:n]) {
+			return 0, fmt.Errorf("read blob %s: %w", b.Name, ErrBlobCorrupted)
+		}
+	} else if err := s.readChunks(b, p, off, end); err != nil {
+		return 0, err
+	}
+	n := int(end - off)
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// readChunks reads [off, end) of the chunked blob b into p.
+func (s *Space) readChunks(b blob.Blob, p []byte, off int64, end int64) error {
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	sem := make(chan struct{}, option.DefaultBlobParallelism)
+	chunkOffset := int64(0)
+	for _, c := range b.Chunks {
+		chunkStart, chunkEnd := chunkOffset, chunkOffset+c.Size
+		chunkOffset = chunkEnd
+		if chunkEnd <= off || chunkStart >= end {
+			continue
+		}
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(c blob.Chunk, chunkStart int64) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			content, err := s.chunkContent(b, c)
+			if err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				mu.Unlock()
+				return
+			}
+			// copy the overlap of the chunk and [off, end)
+			from, to := off, end
+			if chunkStart > from {
+				from = chunkStart
+			}
+			if chunkStart+c.Size < to {
+				to = chunkStart + c.Size
+			}
+			copy(p[from-off:to-off], content[from-chunkStart:to-chunkStart])
+		}(c, chunkStart)
+	}
+	wg.Wait()
+	return firstErr
+}
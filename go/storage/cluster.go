@@ -0,0 +1,280 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/compute"
+	"github.com/apache/arrow/go/v12/arrow/memory"
+	"github.com/milvus-io/milvus-storage/go/common/errors"
+	"github.com/milvus-io/milvus-storage/go/file/fragment"
+	"github.com/milvus-io/milvus-storage/go/storage/manifest"
+	"github.com/milvus-io/milvus-storage/go/storage/options/option"
+)
+
+var ErrUnsupportedClusterColumn = errors.New("unsupported cluster column")
+
+func (s *Space) Cluster(columns []string) error {
+	return s.ClusterContext(context.Background(), columns)
+}
+
+// ClusterContext compacts the space with the rows sorted in Z-order over columns, see
+// option.CompactOptions.ClusterColumns. All rows are loaded in memory.
+func (s *Space) ClusterContext(ctx context.Context, columns []string) error {
+	options := option.NewCompactOptions()
+	options.PurgeDeletes = false
+	options.ClusterColumns = columns
+	_, err := s.CompactContext(ctx, options)
+	return err
+}
+
+// clusterFiles rewrites every file of result, which lacks the pinned fragments, in Z-order over columns into a new fragment.
+func (s *Space) clusterFiles(m *manifest.Manifest, result *compaction, columns []string, maxRecordPerFile int64) error {
+	scalarSchema := m.GetSchema().ScalarSchema()
+	for _, column := range columns {
+		if _, ok := scalarSchema.FieldsByName(column); !ok {
+			return fmt.Errorf("cluster by %s: %w", column, ErrColumnNotExist)
+		}
+	}
+	if len(columns) == 0 || len(result.scalarFragments) == 0 {
+		return nil
+	}
+	if maxRecordPerFile <= 0 {
+		maxRecordPerFile = option.DefaultWriteOptions.MaxRecordPerFile
+	}
+
+	scalar, err := s.loadFiles(result, m.GetSchema().ScalarSchema(), result.scalarFragments)
+	if err != nil {
+		return err
+	}
+	defer scalar.Release()
+	vector, err := s.loadFiles(result, m.GetSchema().VectorSchema(), result.vectorFragments)
+	if err != nil {
+		return err
+	}
+	defer vector.Release()
+	if scalar.NumRows() != vector.NumRows() {
+		return fmt.Errorf("cluster: %d scalar and %d vector rows: %w", scalar.NumRows(), vector.NumRows(), ErrFragmentMismatch)
+	}
+	if scalar.NumRows() == 0 {
+		return nil
+	}
+
+	keys := make([]arrow.Array, 0, len(columns))
+	for _, column := range columns {
+		keys = append(keys, scalar.Column(scalar.Schema().FieldIndices(column)[0]))
+	}
+	order, err := zOrder(keys, int(scalar.NumRows()))
+	if err != nil {
+		return err
+	}
+	sortedScalar, err := takeRecord(scalar, order)
+	if err != nil {
+		return err
+	}
+	defer sortedScalar.Release()
+	sortedVector, err := takeRecord(vector, order)
+	if err != nil {
+		return err
+	}
+	defer sortedVector.Release()
+
+	clusteredScalar, clusteredVector := fragment.NewFragment(0), fragment.NewFragment(0)
+	for begin := int64(0); begin < sortedScalar.NumRows(); begin += maxRecordPerFile {
+		end := begin + maxRecordPerFile
+		if end > sortedScalar.NumRows() {
+			end = sortedScalar.NumRows()
+		}
+		scalarWriter := s.newRewriter(result, m.GetSchema().ScalarSchema(), true)
+		vectorWriter := s.newRewriter(result, m.GetSchema().VectorSchema(), false)
+		scalarSlice, vectorSlice := sortedScalar.NewSlice(begin, end), sortedVector.NewSlice(begin, end)
+		err = scalarWriter.write(scalarSlice)
+		if err == nil {
+			err = vectorWriter.write(vectorSlice)
+		}
+		scalarSlice.Release()
+		vectorSlice.Release()
+		if err != nil {
+			return err
+		}
+		if err = closePair(result, scalarWriter, vectorWriter, clusteredScalar, clusteredVector); err != nil {
+			return err
+		}
+	}
+	result.scalarFragments, result.vectorFragments = nil, nil
+	result.mergedScalar, result.mergedVector = clusteredScalar, clusteredVector
+	return nil
+}
+
+// loadFiles reads all files of fragments into a single record of sc and subtracts their size
+// from the usage since they are replaced.
+func (s *Space) loadFiles(result *compaction, sc *arrow.Schema, fragments fragment.FragmentVector) (arrow.Record, error) {
+	columns := make([][]arrow.Array, len(sc.Fields()))
+	defer func() {
+		for _, chunks := range columns {
+			for _, chunk := range chunks {
+				chunk.Release()
+			}
+		}
+	}()
+	for _, path := range fragment.ToFilesVector(fragments) {
+		size, err := result.fileSize(result.fs, path)
+		if err != nil {
+			return nil, err
+		}
+		result.bytesDelta -= size
+		err = readFile(result.fs, path, option.NewReadOptions(), func(rec arrow.Record) error {
+			for i, field := range sc.Fields() {
+				column := rec.Column(rec.Schema().FieldIndices(field.Name)[0])
+				column.Retain()
+				columns[i] = append(columns[i], column)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	arrays := make([]arrow.Array, len(columns))
+	rows := int64(0)
+	for i, chunks := range columns {
+		if len(chunks) == 0 {
+			arrays[i] = array.MakeArrayOfNull(memory.DefaultAllocator, sc.Field(i).Type, 0)
+		} else {
+			concatenated, err := array.Concatenate(chunks, memory.DefaultAllocator)
+			if err != nil {
+				return nil, fmt.Errorf("cluster: %w", err)
+			}
+			arrays[i] = concatenated
+		}
+		rows = int64(arrays[i].Len())
+	}
+	rec := array.NewRecord(sc, arrays, rows)
+	for _, arr := range arrays {
+		arr.Release()
+	}
+	return rec, nil
+}
+
+func takeRecord(rec arrow.Record, order []int64) (arrow.Record, error) {
+	builder := array.NewInt64Builder(memory.DefaultAllocator)
+	defer builder.Release()
+	builder.AppendValues(order, nil)
+	indices := builder.NewArray()
+	defer indices.Release()
+
+	columns := make([]arrow.Array, 0, rec.NumCols())
+	defer func() {
+		for _, column := range columns {
+			column.Release()
+		}
+	}()
+	for _, column := range rec.Columns() {
+		taken, err := compute.TakeArray(context.Background(), column, indices)
+		if err != nil {
+			return nil, fmt.Errorf("cluster: %w", err)
+		}
+		columns = append(columns, taken)
+	}
+	return array.NewRecord(rec.Schema(), columns, int64(len(order))), nil
+}
+
+// zOrder returns the row indices sorted by the Z-order value of keys. Each key is mapped to
+// its rank among the distinct values of its column, so that columns of any range and type
+// get the same weight, and the ranks are scaled to the bits available per column.
+func zOrder(keys []arrow.Array, rows int) ([]int64, error) {
+	bits := 64 / len(keys)
+	if bits > 32 {
+		bits = 32
+	}
+	scaled := make([][]uint64, len(keys))
+	for k, key := range keys {
+		ranks, distinct, err := rank(key, rows)
+		if err != nil {
+			return nil, err
+		}
+		scaled[k] = make([]uint64, rows)
+		for i, r := range ranks {
+			scaled[k][i] = uint64(r) * (uint64(1) << bits) / uint64(distinct)
+		}
+	}
+
+	z := make([]uint64, rows)
+	for i := range z {
+		for b := bits - 1; b >= 0; b-- {
+			for k := range keys {
+				z[i] = z[i]<<1 | (scaled[k][i]>>b)&1
+			}
+		}
+	}
+	order := make([]int64, rows)
+	for i := range order {
+		order[i] = int64(i)
+	}
+	sort.SliceStable(order, func(i, j int) bool { return z[order[i]] < z[order[j]] })
+	return order, nil
+}
+
+// rank returns the dense rank of every value of key, nulls first, and the number of ranks.
+func rank(key arrow.Array, rows int) ([]int, int, error) {
+	less, err := lessFunc(key)
+	if err != nil {
+		return nil, 0, err
+	}
+	compare := func(i, j int) bool {
+		if key.IsNull(i) || key.IsNull(j) {
+			return key.IsNull(i) && !key.IsNull(j)
+		}
+		return less(i, j)
+	}
+	sorted := make([]int, rows)
+	for i := range sorted {
+		sorted[i] = i
+	}
+	sort.SliceStable(sorted, func(i, j int) bool { return compare(sorted[i], sorted[j]) })
+
+	ranks := make([]int, rows)
+	distinct := 0
+	for i, row := range sorted {
+		if i == 0 || compare(sorted[i-1], row) {
+			distinct++
+		}
+		ranks[row] = distinct - 1
+	}
+	return ranks, distinct, nil
+}
+
+func lessFunc(key arrow.Array) (func(i, j int) bool, error) {
+	switch c := key.(type) {
+	case *array.Int8:
+		return func(i, j int) bool { return c.Value(i) < c.Value(j) }, nil
+	case *array.Int16:
+		return func(i, j int) bool { return c.Value(i) < c.Value(j) }, nil
+	case *array.Int32:
+		return func(i, j int) bool { return c.Value(i) < c.Value(j) }, nil
+	case *array.Int64:
+		return func(i, j int) bool { return c.Value(i) < c.Value(j) }, nil
+	case *array.Uint8:
+		return func(i, j int) bool { return c.Value(i) < c.Value(j) }, nil
+	case *array.Uint16:
+		return func(i, j int) bool { return c.Value(i) < c.Value(j) }, nil
+	case *array.Uint32:
+		return func(i, j int) bool { return c.Value(i) < c.Value(j) }, nil
+	case *array.Uint64:
+		return func(i, j int) bool { return c.Value(i) < c.Value(j) }, nil
+	case *array.Float32:
+		return func(i, j int) bool { return c.Value(i) < c.Value(j) }, nil
+	case *array.Float64:
+		return func(i, j int) bool { return c.Value(i) < c.Value(j) }, nil
+	case *array.String:
+		return func(i, j int) bool { return c.Value(i) < c.Value(j) }, nil
+	case *array.Boolean:
+		return func(i, j int) bool { return !c.Value(i) && c.Value(j) }, nil
+	default:
+		return nil, fmt.Errorf("cluster by %s column: %w", key.DataType(), ErrUnsupportedClusterColumn)
+	}
+}
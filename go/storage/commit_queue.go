@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/milvus-io/milvus-storage/go/storage/options/option"
+)
+
+// commitQueue batches the writes of a space waiting to be committed, see
+// option.CommitQueueOptions. The first write to find no commit in progress commits the writes
+// waiting, its own included, after waiting up to MaxDelay for more, the others wait for it.
+type commitQueue struct {
+	options option.CommitQueueOptions
+	lock    sync.Mutex
+	cond    *sync.Cond
+	pending []*pendingWrite
+	// committing is set while a write commits the writes waiting
+	committing bool
+}
+
+func newCommitQueue(options option.CommitQueueOptions) *commitQueue {
+	q := &commitQueue{options: options}
+	q.cond = sync.NewCond(&q.lock)
+	return q
+}
+
+// commit commits write, with the writes waiting if no commit is in progress, and returns once
+// it is committed or failed.
+func (q *commitQueue) commit(ctx context.Context, s *Space, write *pendingWrite) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	q.pending = append(q.pending, write)
+	// wakes up the delayed commit to check how many writes are waiting
+	q.cond.Broadcast()
+	for !write.done && q.committing {
+		q.cond.Wait()
+	}
+	if write.done {
+		return
+	}
+
+	q.committing = true
+	if q.options.MaxDelay > 0 {
+		expired := false
+		timer := time.AfterFunc(q.options.MaxDelay, func() {
+			q.lock.Lock()
+			defer q.lock.Unlock()
+			expired = true
+			q.cond.Broadcast()
+		})
+		for !expired && (q.options.MaxWrites <= 0 || len(q.pending) < q.options.MaxWrites) {
+			q.cond.Wait()
+		}
+		timer.Stop()
+	}
+	for !write.done {
+		batch := q.pending
+		if q.options.MaxWrites > 0 && len(batch) > q.options.MaxWrites {
+			batch = batch[:q.options.MaxWrites]
+		}
+		q.pending = append([]*pendingWrite(nil), q.pending[len(batch):]...)
+		q.lock.Unlock()
+		s.commitWrites(ctx, batch)
+		q.lock.Lock()
+		for _, committed := range batch {
+			committed.done = true
+		}
+	}
+	q.committing = false
+	q.cond.Broadcast()
+}
@@ -0,0 +1,621 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/compute"
+	"github.com/apache/arrow/go/v12/arrow/memory"
+	"github.com/milvus-io/milvus-storage/go/common/constant"
+	"github.com/milvus-io/milvus-storage/go/common/errors"
+	"github.com/milvus-io/milvus-storage/go/common/utils"
+	"github.com/milvus-io/milvus-storage/go/file/fragment"
+	"github.com/milvus-io/milvus-storage/go/io/format"
+	"github.com/milvus-io/milvus-storage/go/io/format/parquet"
+	"github.com/milvus-io/milvus-storage/go/io/fs"
+	"github.com/milvus-io/milvus-storage/go/storage/auth"
+	"github.com/milvus-io/milvus-storage/go/storage/manifest"
+	"github.com/milvus-io/milvus-storage/go/storage/options/option"
+)
+
+var (
+	ErrFragmentMismatch      = manifest.ErrFragmentMismatch
+	ErrInvalidCompactionPlan = errors.New("invalid compaction plan")
+)
+
+// compaction is the result of rewriting the fragments of a snapshot.
+type compaction struct {
+	scalarFragments fragment.FragmentVector
+	vectorFragments fragment.FragmentVector
+	// merged holds the files written by merging or clustering, committed as a new fragment.
+	mergedScalar *fragment.Fragment
+	mergedVector *fragment.Fragment
+	purged       bool
+	// deletesKept is set when the delete fragments are kept after purging since they match
+	// rows of pinned fragments.
+	deletesKept bool
+	// columnsPurged is set once files are rewritten without dropped columns.
+	columnsPurged bool
+	removedRows   int64
+	// bytesDelta is the size of the new files minus the size of the files they replace.
+	bytesDelta int64
+	newFiles   []string
+	sizes      map[string]int64
+	// fs reads and writes the files, with the retries of the operation if any
+	fs fs.Fs
+}
+
+// newCompaction returns a compaction whose files are read and written with the retries of
+// retry, if not nil.
+func (s *Space) newCompaction(retry *option.RetryOptions) *compaction {
+	return &compaction{sizes: make(map[string]int64), fs: s.opFs(retry)}
+}
+
+// addKnownSizes records the sizes from the file stats of fragments, so that only files
+// written without stats are opened to learn their size.
+func (c *compaction) addKnownSizes(fragments fragment.FragmentVector) {
+	for _, f := range fragments {
+		for i, stats := range f.FileStats() {
+			if stats.Known() {
+				c.sizes[f.Files()[i]] = stats.Bytes
+			}
+		}
+	}
+}
+
+func (c *compaction) fileSize(f fs.Fs, path string) (int64, error) {
+	if size, ok := c.sizes[path]; ok {
+		return size, nil
+	}
+	size, err := fs.FileSize(f, path)
+	if err != nil {
+		return 0, err
+	}
+	c.sizes[path] = size
+	return size, nil
+}
+
+func (s *Space) Compact(options *option.CompactOptions) (int64, error) {
+	return s.CompactContext(context.Background(), options)
+}
+
+// CompactContext rewrites the data of the space as a new version, whose number is returned, or
+// the current one if there is nothing to rewrite. With PurgeDeletes, the rows matched by delete
+// fragments are removed from the scalar and vector files, which are rewritten together so that
+// their rows stay aligned, and the delete fragments are dropped. The files are then clustered
+// by ClusterColumns, or the files chosen by Policy are merged. With PurgeDroppedColumns, the
+// files left that still hold dropped columns are rewritten. Pinned fragments are never
+// rewritten, the delete fragments are kept if they match their rows. Indexes built from
+// rewritten fragments are marked stale, see RegisterIndex. Files of older versions are kept
+// since those versions can still be opened. ctx carries the caller identity, compaction
+// requires auth.OpAdmin.
+func (s *Space) CompactContext(ctx context.Context, options *option.CompactOptions) (int64, error) {
+	if err := s.authorize(ctx, auth.OpAdmin); err != nil {
+		return 0, err
+	}
+	m := s.snapshot()
+	if err := m.ValidateDataFragments(); err != nil {
+		return 0, fmt.Errorf("compact: %w", err)
+	}
+	// files of pending transactions must not be merged with visible ones
+	if pending, err := s.pendingTxns(m); err != nil {
+		return 0, fmt.Errorf("compact: %w", err)
+	} else if len(pending) > 0 {
+		return 0, fmt.Errorf("compact with %d transactions in progress: %w", len(pending), ErrTransactionPending)
+	}
+	// pinned fragments are kept as they are
+	pinnedScalar, scalarFragments := splitPinned(m, m.GetScalarFragments())
+	pinnedVector, vectorFragments := splitPinned(m, m.GetVectorFragments())
+	result := s.newCompaction(options.Retry)
+	result.scalarFragments, result.vectorFragments = scalarFragments, vectorFragments
+	for _, frags := range []fragment.FragmentVector{scalarFragments, vectorFragments, m.GetDeleteFragments()} {
+		result.addKnownSizes(frags)
+	}
+
+	var deleteBytes int64
+	if options.PurgeDeletes && len(m.GetDeleteFragments()) > 0 {
+		deletes, bytes, err := s.loadDeletes(m, result)
+		if err != nil {
+			return 0, err
+		}
+		if err = s.purgeDeletes(m, result, deletes); err != nil {
+			return 0, err
+		}
+		// the delete fragments still apply to the rows of pinned fragments
+		if result.deletesKept, err = s.hasDeletedRows(result, m, pinnedScalar, deletes); err != nil {
+			return 0, err
+		}
+		if !result.deletesKept {
+			deleteBytes = bytes
+		}
+	}
+	if len(options.ClusterColumns) > 0 {
+		if err := s.clusterFiles(m, result, options.ClusterColumns, options.MaxRecordPerFile); err != nil {
+			return 0, err
+		}
+	} else if options.Policy != nil {
+		if err := s.mergeFiles(m, result, options.Policy); err != nil {
+			return 0, err
+		}
+	}
+	if options.PurgeDroppedColumns {
+		if err := s.purgeDroppedColumns(m, result); err != nil {
+			return 0, err
+		}
+	}
+	purged := result.purged && (!result.deletesKept || result.removedRows > 0)
+	if !purged && result.mergedScalar == nil && !result.columnsPurged {
+		return m.Version(), nil
+	}
+
+	record := &option.AuditRecord{Operation: auth.OpAdmin, Rows: result.removedRows, Files: result.newFiles}
+	var committed int64
+	err := s.commit(ctx, record, func(latest *manifest.Manifest, version int64) error {
+		if !hasPrefix(latest.GetScalarFragments(), m.GetScalarFragments()) ||
+			!hasPrefix(latest.GetVectorFragments(), m.GetVectorFragments()) ||
+			!hasPrefix(latest.GetDeleteFragments(), m.GetDeleteFragments()) ||
+			!samePins(latest, m) {
+			return fmt.Errorf("compact version %d: %w", m.Version(), ErrManifestConflict)
+		}
+		scalarFragments := append(append(fragment.FragmentVector(nil), pinnedScalar...), result.scalarFragments...)
+		vectorFragments := append(append(fragment.FragmentVector(nil), pinnedVector...), result.vectorFragments...)
+		if result.mergedScalar != nil {
+			id := latest.NewFragmentID(version)
+			result.mergedScalar.SetFragmentId(id)
+			result.mergedVector.SetFragmentId(id)
+			scalarFragments = append(scalarFragments, *result.mergedScalar)
+			vectorFragments = append(vectorFragments, *result.mergedVector)
+		}
+		// fragments committed after the snapshot are kept as they are
+		scalarFragments = append(scalarFragments, latest.GetScalarFragments()[len(m.GetScalarFragments()):]...)
+		vectorFragments = append(vectorFragments, latest.GetVectorFragments()[len(m.GetVectorFragments()):]...)
+		latest.SetScalarFragments(scalarFragments)
+		latest.SetVectorFragments(vectorFragments)
+		invalidateIndexes(latest, m.GetScalarFragments())
+		if result.purged && !result.deletesKept {
+			deleteFragments := append(fragment.FragmentVector(nil), latest.GetDeleteFragments()[len(m.GetDeleteFragments()):]...)
+			latest.SetDeleteFragments(deleteFragments)
+		}
+		latest.AddUsage(-result.removedRows, result.bytesDelta-deleteBytes)
+		committed = version
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return committed, nil
+}
+
+// hasPrefix reports whether fragments starts with the fragments of prefix.
+func hasPrefix(fragments, prefix fragment.FragmentVector) bool {
+	if len(fragments) < len(prefix) {
+		return false
+	}
+	for i := range prefix {
+		if fragments[i].FragmentId() != prefix[i].FragmentId() || len(fragments[i].Files()) != len(prefix[i].Files()) {
+			return false
+		}
+		for j, file := range prefix[i].Files() {
+			if fragments[i].Files()[j] != file {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// loadDeletes merges the delete fragments of m and returns them with the size of their files.
+func (s *Space) loadDeletes(m *manifest.Manifest, result *compaction) (*fragment.DeleteFragment, int64, error) {
+	deletes := fragment.NewDeleteFragment(m.Version(), m.GetSchema(), result.fs)
+	var bytes int64
+	for _, f := range m.GetDeleteFragments() {
+		deleteFragment, err := fragment.Make(result.fs, m.GetSchema(), f)
+		if err != nil {
+			return nil, 0, err
+		}
+		deletes.Merge(&deleteFragment)
+		for _, path := range f.Files() {
+			size, err := result.fileSize(result.fs, path)
+			if err != nil {
+				return nil, 0, err
+			}
+			bytes += size
+		}
+	}
+	return deletes, bytes, nil
+}
+
+func (s *Space) purgeDeletes(m *manifest.Manifest, result *compaction, deletes *fragment.DeleteFragment) error {
+	var scalarFragments, vectorFragments fragment.FragmentVector
+	for i := range result.scalarFragments {
+		scalarFiles, vectorFiles := result.scalarFragments[i].Files(), result.vectorFragments[i].Files()
+		scalarFragment := fragment.NewFragment(result.scalarFragments[i].FragmentId())
+		vectorFragment := fragment.NewFragment(result.vectorFragments[i].FragmentId())
+		for j := range scalarFiles {
+			keep, removed, err := s.keepMask(result, m, scalarFiles[j], deletes)
+			if err != nil {
+				return err
+			}
+			if removed == 0 {
+				scalarFragment.AddFileWithStats(scalarFiles[j], result.scalarFragments[i].FileStats()[j])
+				vectorFragment.AddFileWithStats(vectorFiles[j], result.vectorFragments[i].FileStats()[j])
+				continue
+			}
+			result.removedRows += removed
+			scalarWriter := s.newRewriter(result, m.GetSchema().ScalarSchema(), true)
+			vectorWriter := s.newRewriter(result, m.GetSchema().VectorSchema(), false)
+			if err = rewritePair(result, scalarWriter, vectorWriter, scalarFiles[j], vectorFiles[j], keep); err != nil {
+				return err
+			}
+			if err = closePair(result, scalarWriter, vectorWriter, scalarFragment, vectorFragment); err != nil {
+				return err
+			}
+		}
+		// fragments whose rows are all deleted are dropped
+		if len(scalarFragment.Files()) > 0 {
+			scalarFragments = append(scalarFragments, *scalarFragment)
+			vectorFragments = append(vectorFragments, *vectorFragment)
+		}
+	}
+	result.scalarFragments, result.vectorFragments = scalarFragments, vectorFragments
+	result.purged = true
+	return nil
+}
+
+// purgeDroppedColumns rewrites the files of result holding columns that are not in the schema
+// of m. Rows keep their order, so the other file of a pair is kept as it is.
+func (s *Space) purgeDroppedColumns(m *manifest.Manifest, result *compaction) error {
+	written := make(map[string]bool, len(result.newFiles))
+	for _, file := range result.newFiles {
+		written[file] = true
+	}
+	purge := func(fragments fragment.FragmentVector, sc *arrow.Schema, isScalar bool) (fragment.FragmentVector, error) {
+		purged := make(fragment.FragmentVector, 0, len(fragments))
+		for _, f := range fragments {
+			rewritten := fragment.NewFragment(f.FragmentId())
+			rewritten.SetTxn(f.Txn())
+			for j, path := range f.Files() {
+				stale := false
+				if !written[path] {
+					var err error
+					if stale, err = s.hasDroppedColumns(result, path, sc); err != nil {
+						return nil, err
+					}
+				}
+				if !stale {
+					rewritten.AddFileWithStats(path, f.FileStats()[j])
+					continue
+				}
+				w := s.newRewriter(result, sc, isScalar)
+				if _, err := w.append(result, path, nil); err != nil {
+					return nil, err
+				}
+				file, stats, err := w.close(result)
+				if err != nil {
+					return nil, err
+				}
+				rewritten.AddFileWithStats(file, stats)
+				result.columnsPurged = true
+			}
+			purged = append(purged, *rewritten)
+		}
+		return purged, nil
+	}
+	scalarFragments, err := purge(result.scalarFragments, m.GetSchema().ScalarSchema(), true)
+	if err != nil {
+		return err
+	}
+	vectorFragments, err := purge(result.vectorFragments, m.GetSchema().VectorSchema(), false)
+	if err != nil {
+		return err
+	}
+	result.scalarFragments, result.vectorFragments = scalarFragments, vectorFragments
+	return nil
+}
+
+// hasDroppedColumns reports whether the file at path holds columns that are not in sc.
+func (s *Space) hasDroppedColumns(result *compaction, path string, sc *arrow.Schema) (bool, error) {
+	reader, err := parquet.NewFileReader(result.fs, path, option.NewReadOptions())
+	if err != nil {
+		return false, err
+	}
+	defer reader.Close()
+	for _, column := range reader.Columns() {
+		if _, ok := sc.FieldsByName(column); !ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// hasDeletedRows reports whether deletes remove rows of the scalar fragments.
+func (s *Space) hasDeletedRows(result *compaction, m *manifest.Manifest, fragments fragment.FragmentVector, deletes *fragment.DeleteFragment) (bool, error) {
+	for _, path := range fragment.ToFilesVector(fragments) {
+		_, removed, err := s.keepMask(result, m, path, deletes)
+		if err != nil {
+			return false, err
+		}
+		if removed > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// keepMask returns for every row of the scalar file whether it survives the deletes, and the
+// number of rows that do not.
+func (s *Space) keepMask(result *compaction, m *manifest.Manifest, path string, deletes *fragment.DeleteFragment) ([]bool, int64, error) {
+	schemaOptions := m.GetSchema().Options()
+	readOptions := option.NewReadOptions()
+	readOptions.AddColumn(schemaOptions.PrimaryColumn)
+	if schemaOptions.HasVersionColumn() {
+		readOptions.AddColumn(schemaOptions.VersionColumn)
+	}
+
+	var (
+		keep    []bool
+		removed int64
+	)
+	err := readFile(result.fs, path, readOptions, func(rec arrow.Record) error {
+		pkColumn := rec.Column(0)
+		for i := 0; i < int(rec.NumRows()); i++ {
+			var version int64
+			if schemaOptions.HasVersionColumn() {
+				version = rec.Column(1).(*array.Int64).Value(i)
+			}
+			deleted := deletes.IsDeleted(fragment.PkValue(pkColumn, i), version)
+			if deleted {
+				removed++
+			}
+			keep = append(keep, !deleted)
+		}
+		return nil
+	})
+	return keep, removed, err
+}
+
+// mergeFiles merges the files grouped by policy into a new fragment.
+func (s *Space) mergeFiles(m *manifest.Manifest, result *compaction, policy option.CompactionPolicy) error {
+	var files []option.CompactionFile
+	for i := range result.scalarFragments {
+		scalarFiles, vectorFiles := result.scalarFragments[i].Files(), result.vectorFragments[i].Files()
+		for j := range scalarFiles {
+			scalarSize, err := result.fileSize(result.fs, scalarFiles[j])
+			if err != nil {
+				return err
+			}
+			vectorSize, err := result.fileSize(result.fs, vectorFiles[j])
+			if err != nil {
+				return err
+			}
+			files = append(files, option.CompactionFile{
+				Fragment: result.scalarFragments[i].FragmentId(),
+				Index:    j,
+				Bytes:    scalarSize + vectorSize,
+				Rows:     result.scalarFragments[i].FileStats()[j].Rows,
+			})
+		}
+	}
+
+	// merged[fragment position][file index] marks the files moved to the merged fragment
+	positions := make(map[int64]int, len(result.scalarFragments))
+	for i, f := range result.scalarFragments {
+		positions[f.FragmentId()] = i
+	}
+	merged := make(map[int]map[int]bool)
+	mergedScalar, mergedVector := fragment.NewFragment(0), fragment.NewFragment(0)
+	for _, group := range policy.Plan(files) {
+		if len(group) < 2 {
+			continue
+		}
+		scalarWriter := s.newRewriter(result, m.GetSchema().ScalarSchema(), true)
+		vectorWriter := s.newRewriter(result, m.GetSchema().VectorSchema(), false)
+		for _, file := range group {
+			i, ok := positions[file.Fragment]
+			if !ok || file.Index < 0 || file.Index >= len(result.scalarFragments[i].Files()) || merged[i][file.Index] {
+				return fmt.Errorf("compact file %d of fragment %d: %w", file.Index, file.Fragment, ErrInvalidCompactionPlan)
+			}
+			if merged[i] == nil {
+				merged[i] = make(map[int]bool)
+			}
+			merged[i][file.Index] = true
+
+			scalarFile, vectorFile := result.scalarFragments[i].Files()[file.Index], result.vectorFragments[i].Files()[file.Index]
+			if err := rewritePair(result, scalarWriter, vectorWriter, scalarFile, vectorFile, nil); err != nil {
+				return err
+			}
+		}
+		if err := closePair(result, scalarWriter, vectorWriter, mergedScalar, mergedVector); err != nil {
+			return err
+		}
+	}
+	if len(merged) == 0 {
+		return nil
+	}
+
+	var scalarFragments, vectorFragments fragment.FragmentVector
+	for i := range result.scalarFragments {
+		if merged[i] == nil {
+			scalarFragments = append(scalarFragments, result.scalarFragments[i])
+			vectorFragments = append(vectorFragments, result.vectorFragments[i])
+			continue
+		}
+		scalarFragment := fragment.NewFragment(result.scalarFragments[i].FragmentId())
+		vectorFragment := fragment.NewFragment(result.vectorFragments[i].FragmentId())
+		for j := range result.scalarFragments[i].Files() {
+			if !merged[i][j] {
+				scalarFragment.AddFileWithStats(result.scalarFragments[i].Files()[j], result.scalarFragments[i].FileStats()[j])
+				vectorFragment.AddFileWithStats(result.vectorFragments[i].Files()[j], result.vectorFragments[i].FileStats()[j])
+			}
+		}
+		if len(scalarFragment.Files()) > 0 {
+			scalarFragments = append(scalarFragments, *scalarFragment)
+			vectorFragments = append(vectorFragments, *vectorFragment)
+		}
+	}
+	result.scalarFragments, result.vectorFragments = scalarFragments, vectorFragments
+	result.mergedScalar, result.mergedVector = mergedScalar, mergedVector
+	return nil
+}
+
+// rewritePair appends the rows of a scalar file and its vector file selected by keep, nil
+// keeps all rows, and checks that both files hold the same number of rows.
+func rewritePair(result *compaction, scalarWriter, vectorWriter *rewriter, scalarFile, vectorFile string, keep []bool) error {
+	scalarRows, err := scalarWriter.append(result, scalarFile, keep)
+	if err != nil {
+		return err
+	}
+	vectorRows, err := vectorWriter.append(result, vectorFile, keep)
+	if err != nil {
+		return err
+	}
+	if scalarRows != vectorRows {
+		return fmt.Errorf("compact %s: %d rows, %s: %d rows: %w", scalarFile, scalarRows, vectorFile, vectorRows, ErrFragmentMismatch)
+	}
+	return nil
+}
+
+func closePair(result *compaction, scalarWriter, vectorWriter *rewriter, scalarFragment, vectorFragment *fragment.Fragment) error {
+	scalarFile, scalarStats, err := scalarWriter.close(result)
+	if err != nil {
+		return err
+	}
+	vectorFile, vectorStats, err := vectorWriter.close(result)
+	if err != nil {
+		return err
+	}
+	// both are empty when every row was removed
+	if scalarFile != "" {
+		scalarFragment.AddFileWithStats(scalarFile, scalarStats)
+		vectorFragment.AddFileWithStats(vectorFile, vectorStats)
+	}
+	return nil
+}
+
+// rewriter writes rows of existing files of one kind to a single new file. Scalar files get
+// offsets matching their new row positions. No file is written if no row is appended.
+type rewriter struct {
+	space    *Space
+	fs       fs.Fs
+	schema   *arrow.Schema
+	isScalar bool
+	writer   format.Writer
+	path     string
+	offset   int64
+}
+
+func (s *Space) newRewriter(result *compaction, sc *arrow.Schema, isScalar bool) *rewriter {
+	return &rewriter{space: s, fs: result.fs, schema: sc, isScalar: isScalar}
+}
+
+// append writes the rows of path selected by keep, nil keeps all rows, and returns the
+// number of rows read from path.
+func (w *rewriter) append(result *compaction, path string, keep []bool) (int64, error) {
+	size, err := result.fileSize(w.fs, path)
+	if err != nil {
+		return 0, err
+	}
+	result.bytesDelta -= size
+
+	var rows int64
+	err = readFile(w.fs, path, option.NewReadOptions(), func(rec arrow.Record) error {
+		n := rec.NumRows()
+		if keep != nil {
+			if rows+n > int64(len(keep)) {
+				return fmt.Errorf("compact %s: more rows than its scalar file: %w", path, ErrFragmentMismatch)
+			}
+			builder := array.NewBooleanBuilder(memory.DefaultAllocator)
+			defer builder.Release()
+			builder.AppendValues(keep[rows:rows+n], nil)
+			mask := builder.NewArray()
+			defer mask.Release()
+
+			filtered, err := compute.FilterRecordBatch(context.Background(), rec, mask, compute.DefaultFilterOptions())
+			if err != nil {
+				return fmt.Errorf("compact %s: %w", path, err)
+			}
+			defer filtered.Release()
+			rec = filtered
+		}
+		rows += n
+		return w.write(rec)
+	})
+	return rows, err
+}
+
+func (w *rewriter) write(rec arrow.Record) error {
+	if rec.NumRows() == 0 {
+		return nil
+	}
+	columns := make([]arrow.Array, 0, len(w.schema.Fields()))
+	for _, field := range w.schema.Fields() {
+		if w.isScalar && field.Name == constant.OffsetFieldName {
+			column := sequenceColumn(w.offset, rec.NumRows())
+			defer column.Release()
+			columns = append(columns, column)
+			continue
+		}
+		columns = append(columns, rec.Column(rec.Schema().FieldIndices(field.Name)[0]))
+	}
+	w.offset += rec.NumRows()
+	out := array.NewRecord(w.schema, columns, rec.NumRows())
+	defer out.Release()
+
+	if w.writer == nil {
+		dir := utils.GetVectorDataDir(w.space.path)
+		if w.isScalar {
+			dir = utils.GetScalarDataDir(w.space.path)
+		}
+		path, err := w.space.newFilePath(dir)
+		if err != nil {
+			return err
+		}
+		w.path = path
+		writer, err := parquet.NewFileWriter(w.schema, w.fs, w.path, w.space.snapshot().GetSchema().Options().StorageProfiles, w.space.writeMemory)
+		if err != nil {
+			return err
+		}
+		w.writer = writer
+	}
+	return w.writer.Write(out)
+}
+
+// close finishes the new file and returns its path and stats, or "" if nothing was written.
+func (w *rewriter) close(result *compaction) (string, fragment.FileStats, error) {
+	if w.writer == nil {
+		return "", fragment.FileStats{}, nil
+	}
+	if err := w.writer.Close(); err != nil {
+		return "", fragment.FileStats{}, err
+	}
+	result.sizes[w.path] = w.writer.Size()
+	result.bytesDelta += w.writer.Size()
+	result.newFiles = append(result.newFiles, w.path)
+	return w.path, fragment.FileStats{Rows: w.writer.Count(), Bytes: w.writer.Size()}, nil
+}
+
+// readFile calls fn with every record of the parquet file at path.
+func readFile(f fs.Fs, path string, options *option.ReadOptions, fn func(rec arrow.Record) error) error {
+	reader, err := parquet.NewFileReader(f, path, options)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	for {
+		rec, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		err = fn(rec)
+		rec.Release()
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package storage
+
+import (
+	"fmt"
+
+	"github.com/milvus-io/milvus-storage/go/io/fs"
+	"github.com/milvus-io/milvus-storage/go/storage/manifest"
+)
+
+// checkConfined fails with ErrFileOutsideSpace if m references a file outside root, see
+// option.Options.ConfineToRoot.
+func checkConfined(m *manifest.Manifest, root string) error {
+	for _, file := range manifestFiles(m) {
+		if !fs.InRoot(root, file) {
+			return fmt.Errorf("manifest version %d references %s: %w", m.Version(), file, ErrFileOutsideSpace)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/milvus-io/milvus-storage/go/common/errors"
+	"github.com/milvus-io/milvus-storage/go/common/utils"
+	"github.com/milvus-io/milvus-storage/go/io/fs"
+	"github.com/milvus-io/milvus-storage/go/storage/auth"
+	"github.com/milvus-io/milvus-storage/go/storage/options/option"
+)
+
+var ErrStaleReplica = errors.NewWithKind(errors.ErrUnavailable, "stale replica")
+
+// ConsistencyToken returns the token of the current version, see ConsistencyTokenContext.
+func (s *Space) ConsistencyToken() (option.ConsistencyToken, error) {
+	return s.ConsistencyTokenContext(context.Background())
+}
+
+// ConsistencyTokenContext returns the token of the current version. Reads of another process
+// given it in option.ReadOptions.AtLeast see the writes committed so far, or fail with
+// ErrStaleReplica until that process refreshes its space, see RefreshContext. ctx carries the
+// caller identity, it requires auth.OpRead.
+func (s *Space) ConsistencyTokenContext(ctx context.Context) (option.ConsistencyToken, error) {
+	if err := s.authorize(ctx, auth.OpRead); err != nil {
+		return option.ConsistencyToken{}, err
+	}
+	version := s.snapshot().Version()
+	etag, err := s.manifestETag(version)
+	if err != nil {
+		return option.ConsistencyToken{}, err
+	}
+	return option.ConsistencyToken{Version: version, ETag: etag}, nil
+}
+
+// checkConsistency fails with ErrStaleReplica if the space has not loaded the version of token.
+func (s *Space) checkConsistency(token *option.ConsistencyToken) error {
+	version := s.snapshot().Version()
+	if version < token.Version {
+		return fmt.Errorf("read version %d at version %d: %w", token.Version, version, ErrStaleReplica)
+	}
+	if version > token.Version || token.ETag == "" {
+		return nil
+	}
+	etag, err := s.manifestETag(version)
+	if err != nil {
+		return err
+	}
+	if etag != "" && etag != token.ETag {
+		return fmt.Errorf("read version %d of another manifest: %w", token.Version, ErrStaleReplica)
+	}
+	return nil
+}
+
+// manifestETag returns the ETag of the manifest file of version, empty if the file system does
+// not tell.
+func (s *Space) manifestETag(version int64) (string, error) {
+	info, err := fs.Stat(s.fs, utils.GetManifestFilePath(s.path, version))
+	if errors.Is(err, fs.ErrStatNotSupported) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("stat manifest of version %d: %w", version, err)
+	}
+	return info.ETag, nil
+}
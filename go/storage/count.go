@@ -0,0 +1,77 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/milvus-io/milvus-storage/go/common/errors"
+	"github.com/milvus-io/milvus-storage/go/io/format/parquet"
+	"github.com/milvus-io/milvus-storage/go/storage/auth"
+	"github.com/milvus-io/milvus-storage/go/storage/manifest"
+	"github.com/milvus-io/milvus-storage/go/storage/options/option"
+)
+
+func (s *Space) CountRows() (int64, error) {
+	return s.CountRowsContext(context.Background())
+}
+
+// CountRowsContext returns the number of rows stored in the space, rows removed by deletes
+// are counted until compaction purges them. It is answered from the file stats of the
+// manifest, only files written before stats were recorded are opened to read their footer.
+// When a row filter applies to the caller the visible rows are read and counted instead.
+func (s *Space) CountRowsContext(ctx context.Context) (int64, error) {
+	if err := s.authorize(ctx, auth.OpRead); err != nil {
+		return 0, err
+	}
+	if s.rowFilter != nil {
+		identity := auth.IdentityFromContext(ctx)
+		filters, err := s.rowFilter(identity)
+		if err != nil {
+			return 0, errors.WithKind(errors.ErrPermissionDenied, fmt.Errorf("row filter for %q: %w", identity.User, err))
+		}
+		if len(filters) > 0 {
+			return s.countVisibleRows(ctx)
+		}
+	}
+
+	m, err := s.readSnapshot()
+	if err != nil {
+		return 0, err
+	}
+	return s.storedRows(m)
+}
+
+// storedRows returns the number of rows in the data files of m, including deleted rows.
+func (s *Space) storedRows(m *manifest.Manifest) (int64, error) {
+	var rows int64
+	for _, f := range m.GetScalarFragments() {
+		for i, stats := range f.FileStats() {
+			if stats.Known() {
+				rows += stats.Rows
+				continue
+			}
+			reader, err := parquet.NewFileReader(s.fs, f.Files()[i], option.NewReadOptions())
+			if err != nil {
+				return 0, fmt.Errorf("count rows: %w", err)
+			}
+			rows += reader.NumRows()
+			reader.Close()
+		}
+	}
+	return rows, nil
+}
+
+func (s *Space) countVisibleRows(ctx context.Context) (int64, error) {
+	readOption := option.NewReadOptions()
+	readOption.AddColumn(s.snapshot().GetSchema().Options().PrimaryColumn)
+	reader, err := s.ReadContext(ctx, readOption)
+	if err != nil {
+		return 0, err
+	}
+	defer reader.Release()
+	var rows int64
+	for reader.Next() {
+		rows += reader.Record().NumRows()
+	}
+	return rows, reader.Err()
+}
@@ -0,0 +1,226 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/memory"
+	"github.com/milvus-io/milvus-storage/go/common/utils"
+	"github.com/milvus-io/milvus-storage/go/file/fragment"
+	"github.com/milvus-io/milvus-storage/go/io/format/parquet"
+	"github.com/milvus-io/milvus-storage/go/storage/auth"
+	"github.com/milvus-io/milvus-storage/go/storage/manifest"
+	"github.com/milvus-io/milvus-storage/go/storage/options/option"
+)
+
+func (s *Space) CompactDeletes() (int64, error) {
+	return s.CompactDeletesContext(context.Background())
+}
+
+// CompactDeletesContext merges the delete fragments into a single one so that reads consult one
+// file instead of many, and returns the new version, or the current one if there is nothing to
+// merge. Only the newest delete of every key is kept, and deletes that no longer remove any
+// row, e.g. because the rows were purged, are dropped. Data files are not rewritten, see
+// CompactContext with PurgeDeletes for that. ctx carries the caller identity, it requires
+// auth.OpAdmin.
+func (s *Space) CompactDeletesContext(ctx context.Context) (int64, error) {
+	if err := s.authorize(ctx, auth.OpAdmin); err != nil {
+		return 0, err
+	}
+	m := s.snapshot()
+	if len(m.GetDeleteFragments()) == 0 {
+		return m.Version(), nil
+	}
+	result := s.newCompaction(nil)
+	result.addKnownSizes(m.GetDeleteFragments())
+	deletes, deleteBytes, err := s.loadDeletes(m, result)
+	if err != nil {
+		return 0, err
+	}
+	latest := deletes.Latest()
+	if err = s.dropUnmatchedDeletes(m, latest); err != nil {
+		return 0, err
+	}
+	if len(m.GetDeleteFragments()) == 1 && len(latest) == deletes.Len() {
+		return m.Version(), nil
+	}
+
+	merged := fragment.NewFragment(0)
+	var mergedBytes int64
+	if len(latest) > 0 {
+		path, err := s.newFilePath(utils.GetDeleteDataDir(s.path))
+		if err != nil {
+			return 0, err
+		}
+		writer, err := parquet.NewFileWriter(m.GetSchema().DeleteSchema(), s.fs, path, m.GetSchema().Options().StorageProfiles, s.writeMemory)
+		if err != nil {
+			return 0, err
+		}
+		rec := deleteRecord(m, latest)
+		err = writer.Write(rec)
+		rec.Release()
+		if err != nil {
+			return 0, err
+		}
+		if err = writer.Close(); err != nil {
+			return 0, err
+		}
+		merged.AddFileWithStats(path, fragment.FileStats{Rows: writer.Count(), Bytes: writer.Size()})
+		mergedBytes = writer.Size()
+	}
+
+	record := &option.AuditRecord{Operation: auth.OpAdmin, Rows: int64(deletes.Len() - len(latest)), Files: merged.Files()}
+	var committed int64
+	err = s.commit(ctx, record, func(current *manifest.Manifest, version int64) error {
+		// deletes were dropped because no row of the snapshot matched them, new rows could
+		if !hasPrefix(current.GetDeleteFragments(), m.GetDeleteFragments()) ||
+			len(current.GetScalarFragments()) != len(m.GetScalarFragments()) ||
+			!hasPrefix(current.GetScalarFragments(), m.GetScalarFragments()) {
+			return fmt.Errorf("compact deletes of version %d: %w", m.Version(), ErrManifestConflict)
+		}
+		var deleteFragments fragment.FragmentVector
+		if len(merged.Files()) > 0 {
+			merged.SetFragmentId(version)
+			deleteFragments = append(deleteFragments, *merged)
+		}
+		// deletes committed after the snapshot are kept as they are
+		deleteFragments = append(deleteFragments, current.GetDeleteFragments()[len(m.GetDeleteFragments()):]...)
+		current.SetDeleteFragments(deleteFragments)
+		current.AddUsage(0, mergedBytes-deleteBytes)
+		committed = version
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return committed, nil
+}
+
+// dropUnmatchedDeletes removes from latest the deletes that remove no row of m.
+func (s *Space) dropUnmatchedDeletes(m *manifest.Manifest, latest map[any]int64) error {
+	schemaOptions := m.GetSchema().Options()
+	readOptions := option.NewReadOptions()
+	readOptions.AddColumn(schemaOptions.PrimaryColumn)
+	if schemaOptions.HasVersionColumn() {
+		readOptions.AddColumn(schemaOptions.VersionColumn)
+	}
+	matched := make(map[any]bool, len(latest))
+	for _, path := range fragment.ToFilesVector(m.GetScalarFragments()) {
+		err := readFile(s.fs, path, readOptions, func(rec arrow.Record) error {
+			pkColumn := rec.Column(0)
+			for i := 0; i < int(rec.NumRows()); i++ {
+				pk := fragment.PkValue(pkColumn, i)
+				deleteVersion, ok := latest[pk]
+				if !ok {
+					continue
+				}
+				var version int64
+				if schemaOptions.HasVersionColumn() {
+					version = rec.Column(1).(*array.Int64).Value(i)
+				}
+				if deleteVersion >= version {
+					matched[pk] = true
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	for pk := range latest {
+		if !matched[pk] {
+			delete(latest, pk)
+		}
+	}
+	return nil
+}
+
+// deleteRecord returns a record of the delete schema of m holding latest, sorted by key.
+func deleteRecord(m *manifest.Manifest, latest map[any]int64) arrow.Record {
+	sc := m.GetSchema().DeleteSchema()
+	pks := make([]any, 0, len(latest))
+	for pk := range latest {
+		pks = append(pks, pk)
+	}
+	sort.Slice(pks, func(i, j int) bool {
+		switch pk := pks[i].(type) {
+		case int64:
+			return pk < pks[j].(int64)
+		default:
+			return pk.(string) < pks[j].(string)
+		}
+	})
+
+	builder := array.NewRecordBuilder(memory.DefaultAllocator, sc)
+	defer builder.Release()
+	for i, field := range sc.Fields() {
+		switch {
+		case field.Name == m.GetSchema().Options().PrimaryColumn:
+			for _, pk := range pks {
+				switch b := builder.Field(i).(type) {
+				case *array.Int64Builder:
+					b.Append(pk.(int64))
+				case *array.StringBuilder:
+					b.Append(pk.(string))
+				}
+			}
+		case field.Name == m.GetSchema().Options().VersionColumn:
+			for _, pk := range pks {
+				builder.Field(i).(*array.Int64Builder).Append(latest[pk])
+			}
+		}
+	}
+	return builder.NewRecord()
+}
+
+// DeleteStats describes the deletes of a space that data files still hold.
+type DeleteStats struct {
+	// Fragments is the number of delete fragments, every read consults all of them.
+	Fragments int
+	// Deletes is the number of deletes, Keys the number of distinct primary keys they delete.
+	Deletes int64
+	Keys    int64
+	// StoredRows is the number of rows in data files and LiveRows an estimate of those not
+	// deleted, assuming every deleted key removes one row.
+	StoredRows int64
+	LiveRows   int64
+}
+
+func (s *Space) DeleteStats() (DeleteStats, error) {
+	return s.DeleteStatsContext(context.Background())
+}
+
+// DeleteStatsContext returns the delete statistics of the current version, they tell whether
+// CompactDeletesContext or a compaction purging deletes is worthwhile. ctx carries the caller
+// identity, it requires auth.OpRead.
+func (s *Space) DeleteStatsContext(ctx context.Context) (DeleteStats, error) {
+	if err := s.authorize(ctx, auth.OpRead); err != nil {
+		return DeleteStats{}, err
+	}
+	m := s.snapshot()
+	deleteFragments, err := s.deleteFragments(m)
+	if err != nil {
+		return DeleteStats{}, fmt.Errorf("delete stats: %w", err)
+	}
+	rows, err := s.storedRows(m)
+	if err != nil {
+		return DeleteStats{}, fmt.Errorf("delete stats: %w", err)
+	}
+	stats := DeleteStats{
+		Fragments:  len(deleteFragments),
+		Keys:       int64(deleteFragments.Keys()),
+		StoredRows: rows,
+	}
+	for i := range deleteFragments {
+		stats.Deletes += int64(deleteFragments[i].Len())
+	}
+	stats.LiveRows = rows - stats.Keys
+	if stats.LiveRows < 0 {
+		stats.LiveRows = 0
+	}
+	return stats, nil
+}
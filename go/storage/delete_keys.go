@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/memory"
+	"github.com/milvus-io/milvus-storage/go/common/errors"
+)
+
+var ErrPrimaryKeyType = errors.New("primary key type mismatch")
+
+// DeleteKeys deletes the rows of the int64 primary keys pks whose version column is at most
+// version, like Delete. Rows of the keys written again with a later version stay visible.
+func (s *Space) DeleteKeys(pks []int64, version int64) (CommitResult, error) {
+	return s.DeleteKeysContext(context.Background(), pks, version)
+}
+
+// DeleteKeysContext is like DeleteKeys, ctx carries the caller identity checked by the
+// authorizer.
+func (s *Space) DeleteKeysContext(ctx context.Context, pks []int64, version int64) (CommitResult, error) {
+	return s.deleteKeys(ctx, arrow.INT64, len(pks), version, func(b array.Builder) {
+		b.(*array.Int64Builder).AppendValues(pks, nil)
+	})
+}
+
+// DeleteKeysString is DeleteKeys for string primary keys.
+func (s *Space) DeleteKeysString(pks []string, version int64) (CommitResult, error) {
+	return s.DeleteKeysStringContext(context.Background(), pks, version)
+}
+
+// DeleteKeysStringContext is like DeleteKeysString, ctx carries the caller identity checked
+// by the authorizer.
+func (s *Space) DeleteKeysStringContext(ctx context.Context, pks []string, version int64) (CommitResult, error) {
+	return s.deleteKeys(ctx, arrow.STRING, len(pks), version, func(b array.Builder) {
+		b.(*array.StringBuilder).AppendValues(pks, nil)
+	})
+}
+
+// deleteKeys deletes the rows up to version of n keys appended by appendKeys to a builder of
+// the primary column, whose type must be keyType.
+func (s *Space) deleteKeys(ctx context.Context, keyType arrow.Type, n int, version int64, appendKeys func(b array.Builder)) (CommitResult, error) {
+	sc := s.snapshot().GetSchema()
+	deleteSchema := sc.DeleteSchema()
+	if pkType := deleteSchema.Field(0).Type; pkType.ID() != keyType {
+		return CommitResult{}, fmt.Errorf("delete %s keys of %s primary column: %w", keyType, pkType, ErrPrimaryKeyType)
+	}
+	b := array.NewRecordBuilder(memory.DefaultAllocator, deleteSchema)
+	defer b.Release()
+	appendKeys(b.Field(0))
+	versions := b.Field(1).(*array.Int64Builder)
+	for i := 0; i < n; i++ {
+		versions.Append(version)
+	}
+	rec := b.NewRecord()
+	defer rec.Release()
+	reader, err := array.NewRecordReader(deleteSchema, []arrow.Record{rec})
+	if err != nil {
+		return CommitResult{}, err
+	}
+	defer reader.Release()
+	return s.DeleteContext(ctx, reader)
+}
@@ -0,0 +1,241 @@
+package storage
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/milvus-io/milvus-storage/go/common/errors"
+	"github.com/milvus-io/milvus-storage/go/common/log"
+	"github.com/milvus-io/milvus-storage/go/common/utils"
+	"github.com/milvus-io/milvus-storage/go/file/fragment"
+	"github.com/milvus-io/milvus-storage/go/io/fs"
+	"github.com/milvus-io/milvus-storage/go/storage/manifest"
+	"github.com/milvus-io/milvus-storage/go/storage/options/option"
+)
+
+var (
+	ErrSpaceDropped    = errors.NewWithKind(errors.ErrNotFound, "space dropped")
+	ErrSpaceNotDropped = errors.New("space not dropped")
+)
+
+// SoftDrop marks the space at uri as dropped by committing a tombstone version. The data is
+// kept, so Restore can undo the drop until Vacuum purges the space after the grace period.
+// Spaces opened before the drop fail to commit.
+func SoftDrop(uri string) error {
+	f, path, err := buildFs(uri)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	m, err := latestManifest(f, path)
+	if err != nil {
+		return err
+	}
+	if m.DroppedAt() != 0 {
+		return fmt.Errorf("drop space %s: %w", path, ErrSpaceDropped)
+	}
+	tombstone := m.Copy()
+	tombstone.SetVersion(m.Version() + 1)
+	tombstone.SetProperties(nil)
+	tombstone.SetDroppedAt(time.Now().UnixMilli())
+	if err = safeSaveManifest(f, path, tombstone, false); err != nil {
+		return fmt.Errorf("drop space %s: %w", path, err)
+	}
+	log.Info("soft drop space", log.String("path", path), log.Int64("version", tombstone.Version()))
+	return nil
+}
+
+// Restore undoes SoftDrop by committing a version with the same data as the tombstone.
+func Restore(uri string) error {
+	f, path, err := buildFs(uri)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	m, err := latestManifest(f, path)
+	if err != nil {
+		return err
+	}
+	if m.DroppedAt() == 0 {
+		return fmt.Errorf("restore space %s: %w", path, ErrSpaceNotDropped)
+	}
+	restored := m.Copy()
+	restored.SetVersion(m.Version() + 1)
+	restored.SetProperties(nil)
+	restored.SetDroppedAt(0)
+	if err = safeSaveManifest(f, path, restored, false); err != nil {
+		return fmt.Errorf("restore space %s: %w", path, err)
+	}
+	log.Info("restore space", log.String("path", path), log.Int64("version", restored.Version()))
+	return nil
+}
+
+// Vacuum purges the space at uri if it was soft dropped longer than the grace period ago.
+// Every file referenced by any version is removed, manifests last, so an interrupted purge
+// is completed by the next Vacuum. Live spaces and spaces still in the grace period are left
+// untouched.
+func Vacuum(uri string, options *option.VacuumOptions) error {
+	f, path, err := buildFs(uri)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	versions, err := manifestVersions(f, path)
+	if err != nil {
+		return err
+	}
+	if len(versions) == 0 {
+		return nil
+	}
+	latest, err := manifest.ParseFromFile(f, utils.GetManifestFilePath(path, versions[len(versions)-1]))
+	if err != nil {
+		return err
+	}
+	if latest.DroppedAt() == 0 {
+		return nil
+	}
+	if time.Since(time.UnixMilli(latest.DroppedAt())) < options.DropGracePeriod {
+		log.Debug("space in drop grace period", log.String("path", path))
+		return nil
+	}
+	return purge(f, path, versions)
+}
+
+func purge(f fs.Fs, path string, versions []int64) error {
+	files := make(map[string]struct{})
+	for _, version := range versions {
+		m, err := manifest.ParseFromFile(f, utils.GetManifestFilePath(path, version))
+		if err != nil {
+			return fmt.Errorf("purge space %s: %w", path, err)
+		}
+		for _, fragments := range []fragment.FragmentVector{m.GetScalarFragments(), m.GetVectorFragments(), m.GetDeleteFragments()} {
+			for _, file := range fragment.ToFilesVector(fragments) {
+				files[file] = struct{}{}
+			}
+		}
+		for _, b := range m.GetBlobs() {
+			for _, file := range b.Files() {
+				files[file] = struct{}{}
+			}
+		}
+	}
+	files[utils.GetLeaseFilePath(path)] = struct{}{}
+	for file := range files {
+		if err := deleteIfExist(f, file); err != nil {
+			return fmt.Errorf("purge space %s: %w", path, err)
+		}
+	}
+
+	// files of failed writes, audit records and bitmaps are not referenced by any manifest, data
+	// files may be in subdirectories of a layout
+	cold := utils.GetColdDataDir(path)
+	dirs := []string{utils.GetScalarDataDir(path), utils.GetVectorDataDir(path), utils.GetDeleteDataDir(path),
+		utils.GetBlobDir(path), utils.GetAuditDir(path), utils.GetBitmapDir(path),
+		utils.GetScalarDataDir(cold), utils.GetVectorDataDir(cold)}
+	for _, dir := range dirs {
+		entries, err := listFilesIfExist(f, dir)
+		if err != nil {
+			return fmt.Errorf("purge space %s: %w", path, err)
+		}
+		for _, entry := range entries {
+			if err = deleteIfExist(f, entry.Path); err != nil {
+				return fmt.Errorf("purge space %s: %w", path, err)
+			}
+		}
+	}
+
+	for _, version := range versions {
+		if err := deleteIfExist(f, utils.GetManifestFilePath(path, version)); err != nil {
+			return fmt.Errorf("purge space %s: %w", path, err)
+		}
+	}
+	if err := deleteIfExist(f, utils.GetLatestFilePath(path)); err != nil {
+		return fmt.Errorf("purge space %s: %w", path, err)
+	}
+	// directories only exist on local fs, removing them fails if unknown files are left
+	for _, dir := range append(dirs, cold, utils.GetManifestDir(path), path) {
+		if err := f.DeleteFile(dir); err != nil && !errors.Is(err, errors.ErrNotFound) {
+			log.Warn("remove space directory failed", log.String("path", dir), log.String("err", err.Error()))
+		}
+	}
+	log.Info("purge space", log.String("path", path), log.Int("files", len(files)))
+	return nil
+}
+
+func deleteIfExist(f fs.Fs, path string) error {
+	if err := f.DeleteFile(path); err != nil && !errors.Is(err, errors.ErrNotFound) {
+		return err
+	}
+	return nil
+}
+
+func listIfExist(f fs.Fs, path string) ([]fs.FileEntry, error) {
+	exist, err := f.Exist(path)
+	if err != nil || !exist {
+		return nil, err
+	}
+	return f.List(path)
+}
+
+// listFilesIfExist lists the files in path and its subdirectories, subdirectories are listed
+// after the files they hold.
+func listFilesIfExist(f fs.Fs, path string) ([]fs.FileEntry, error) {
+	entries, err := listIfExist(f, path)
+	if err != nil {
+		return nil, err
+	}
+	var files []fs.FileEntry
+	for _, entry := range entries {
+		if entry.IsDir && entry.Path != path && entry.Path != path+"/" {
+			nested, err := listFilesIfExist(f, entry.Path)
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, nested...)
+		}
+		files = append(files, entry)
+	}
+	return files, nil
+}
+
+func buildFs(uri string) (fs.Fs, string, error) {
+	f, err := fs.BuildFileSystem(uri)
+	if err != nil {
+		return nil, "", err
+	}
+	parsedUri, err := url.Parse(uri)
+	if err != nil {
+		return nil, "", err
+	}
+	return f, parsedUri.Path, nil
+}
+
+// manifestVersions returns the committed versions of the space in ascending order.
+func manifestVersions(f fs.Fs, path string) ([]int64, error) {
+	entries, err := listIfExist(f, utils.GetManifestDir(path))
+	if err != nil {
+		return nil, err
+	}
+	var versions []int64
+	for _, entry := range entries {
+		if version := utils.ParseVersionFromFileName(filepath.Base(entry.Path)); version != -1 {
+			versions = append(versions, version)
+		}
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i] < versions[j] })
+	return versions, nil
+}
+
+func latestManifest(f fs.Fs, path string) (*manifest.Manifest, error) {
+	version, err := latestVersion(f, path)
+	if err != nil {
+		return nil, err
+	}
+	if version == -1 {
+		return nil, fmt.Errorf("open space %s: %w", path, ErrManifestNotFound)
+	}
+	return manifest.ParseFromFile(f, utils.GetManifestFilePath(path, version))
+}
@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/milvus-io/milvus-storage/go/common/constant"
+	"github.com/milvus-io/milvus-storage/go/common/errors"
+	"github.com/milvus-io/milvus-storage/go/storage/auth"
+	"github.com/milvus-io/milvus-storage/go/storage/manifest"
+	"github.com/milvus-io/milvus-storage/go/storage/options/option"
+	"github.com/milvus-io/milvus-storage/go/storage/schema"
+)
+
+var ErrRequiredColumn = errors.New("column required by the schema options")
+
+// DropColumn removes column name from the schema, see DropColumnContext.
+func (s *Space) DropColumn(name string) (int64, error) {
+	return s.DropColumnContext(context.Background(), name)
+}
+
+// DropColumnContext removes column name from the schema in a new version, whose number is
+// returned. Only the metadata changes, the files keep the column until compaction rewrites
+// them, see option.CompactOptions.PurgeDroppedColumns. The primary, version and vector columns
+// cannot be dropped. ctx carries the caller identity, it requires auth.OpAdmin.
+func (s *Space) DropColumnContext(ctx context.Context, name string) (int64, error) {
+	if err := s.authorize(ctx, auth.OpAdmin); err != nil {
+		return 0, err
+	}
+	m := s.snapshot()
+	sc := m.GetSchema()
+	if _, ok := sc.Schema().FieldsByName(name); !ok {
+		return 0, fmt.Errorf("drop column %s: %w", name, ErrColumnNotExist)
+	}
+	if name == sc.Options().PrimaryColumn || name == sc.Options().VersionColumn || name == sc.Options().VectorColumn {
+		return 0, fmt.Errorf("drop column %s: %w", name, ErrRequiredColumn)
+	}
+	fields := make([]arrow.Field, 0, len(sc.Schema().Fields())-1)
+	for _, field := range sc.Schema().Fields() {
+		if field.Name != name {
+			fields = append(fields, field)
+		}
+	}
+	dropped := schema.NewSchema(arrow.NewSchema(fields, nil), sc.Options().WithoutColumn(name))
+	if err := dropped.Validate(); err != nil {
+		return 0, fmt.Errorf("drop column %s: %w", name, err)
+	}
+
+	record := &option.AuditRecord{Operation: auth.OpAdmin}
+	var committed int64
+	err := s.commit(ctx, record, func(latest *manifest.Manifest, version int64) error {
+		if !latest.GetSchema().Schema().Equal(sc.Schema()) {
+			return fmt.Errorf("drop column %s of version %d: %w", name, m.Version(), ErrManifestConflict)
+		}
+		latest.SetSchema(dropped)
+		delete(latest.Properties(), constant.StatsPropertyPrefix+name)
+		committed = version
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return committed, nil
+}
@@ -0,0 +1,119 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/compute"
+	"github.com/apache/arrow/go/v12/arrow/memory"
+	"github.com/milvus-io/milvus-storage/go/common/errors"
+)
+
+// ErrDuplicateKey is matched by every DuplicateKeyError.
+var ErrDuplicateKey = errors.New("duplicate primary key")
+
+// DuplicateKeyError reports a row of a write with option.DuplicatesReject whose primary key
+// was already written by an earlier row, nothing of the write is committed.
+type DuplicateKeyError struct {
+	Key any
+	// Row and FirstRow are the indices of the rows in the stream written, counting the rows of
+	// every batch.
+	Row      int64
+	FirstRow int64
+}
+
+func (e *DuplicateKeyError) Error() string {
+	return fmt.Sprintf("primary key %v of row %d already written by row %d", e.Key, e.Row, e.FirstRow)
+}
+
+func (e *DuplicateKeyError) Unwrap() error {
+	return ErrDuplicateKey
+}
+
+// keyAt returns the primary key of row i of column, an int64 or a string.
+func keyAt(column arrow.Array, i int) any {
+	switch column := column.(type) {
+	case *array.Int64:
+		return column.Value(i)
+	case *array.String:
+		return column.Value(i)
+	}
+	return nil
+}
+
+// keyTracker remembers the first row of every primary key written.
+type keyTracker struct {
+	column string
+	rows   map[any]int64
+}
+
+func newKeyTracker(column string) *keyTracker {
+	return &keyTracker{column: column, rows: make(map[any]int64)}
+}
+
+// check fails at the first row of rec whose key was already seen, the first row of rec is
+// row firstRow of the write.
+func (t *keyTracker) check(rec arrow.Record, firstRow int64) error {
+	column := rec.Column(rec.Schema().FieldIndices(t.column)[0])
+	for i := 0; i < column.Len(); i++ {
+		key := keyAt(column, i)
+		if first, ok := t.rows[key]; ok {
+			return &DuplicateKeyError{Key: key, Row: firstRow + int64(i), FirstRow: first}
+		}
+		t.rows[key] = firstRow + int64(i)
+	}
+	return nil
+}
+
+// lastWins reads reader until its end and returns a reader of its rows without those whose
+// primary key appears again later. The returned reader must be released.
+func lastWins(reader array.RecordReader, column string) (array.RecordReader, error) {
+	var recs []arrow.Record
+	defer func() {
+		for _, rec := range recs {
+			rec.Release()
+		}
+	}()
+	last := make(map[any]int64)
+	var rows int64
+	for reader.Next() {
+		rec := reader.Record()
+		rec.Retain()
+		recs = append(recs, rec)
+		keys := rec.Column(rec.Schema().FieldIndices(column)[0])
+		for i := 0; i < keys.Len(); i++ {
+			last[keyAt(keys, i)] = rows
+			rows++
+		}
+	}
+	if err := reader.Err(); err != nil {
+		return nil, err
+	}
+
+	deduped := make([]arrow.Record, 0, len(recs))
+	defer func() {
+		for _, rec := range deduped {
+			rec.Release()
+		}
+	}()
+	rows = 0
+	for _, rec := range recs {
+		keys := rec.Column(rec.Schema().FieldIndices(column)[0])
+		builder := array.NewBooleanBuilder(memory.DefaultAllocator)
+		for i := 0; i < keys.Len(); i++ {
+			builder.Append(last[keyAt(keys, i)] == rows)
+			rows++
+		}
+		mask := builder.NewArray()
+		builder.Release()
+		filtered, err := compute.FilterRecordBatch(context.Background(), rec, mask, compute.DefaultFilterOptions())
+		mask.Release()
+		if err != nil {
+			return nil, fmt.Errorf("deduplicate write: %w", err)
+		}
+		deduped = append(deduped, filtered)
+	}
+	return array.NewRecordReader(reader.Schema(), deduped)
+}
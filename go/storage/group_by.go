@@ -0,0 +1,209 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/compute"
+	"github.com/apache/arrow/go/v12/arrow/memory"
+	"github.com/milvus-io/milvus-storage/go/filter"
+	"github.com/milvus-io/milvus-storage/go/storage/auth"
+	"github.com/milvus-io/milvus-storage/go/storage/options/option"
+)
+
+// Group is the rows of a space that have the same value of the grouping column.
+type Group struct {
+	// Key is the value of the column, nil for the rows where it is null.
+	Key  any
+	Rows int64
+	// Values are the aggregates over the rows of the group, see AggregateContext.
+	Values []any
+}
+
+func (s *Space) GroupBy(column string, aggs []option.AggSpec, filters []filter.Filter, limit int) ([]Group, error) {
+	return s.GroupByContext(context.Background(), column, aggs, filters, limit)
+}
+
+// GroupByContext groups the rows that pass filters by the values of column and computes aggs
+// over each group. Groups are sorted by decreasing number of rows, then by key, and only the
+// first limit are returned if limit is positive, e.g. the top values of a facet. Every group
+// is kept in memory while reading, column should have few distinct values. ctx carries the
+// caller identity, it requires auth.OpRead.
+func (s *Space) GroupByContext(ctx context.Context, column string, aggs []option.AggSpec, filters []filter.Filter, limit int) ([]Group, error) {
+	if err := s.authorize(ctx, auth.OpRead); err != nil {
+		return nil, err
+	}
+	m, err := s.readSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	fields, ok := m.GetSchema().Schema().FieldsByName(column)
+	if !ok {
+		return nil, fmt.Errorf("group by %s: %w", column, ErrColumnNotExist)
+	}
+	switch id := fields[0].Type.ID(); {
+	case arrow.IsInteger(id), id == arrow.STRING, id == arrow.BOOL:
+	default:
+		return nil, fmt.Errorf("group by %s column %s: %w", fields[0].Type, column, ErrUnsupportedAggregate)
+	}
+	if err := s.checkUnmasked(ctx, column); err != nil {
+		return nil, err
+	}
+	if err := s.checkAggregates(ctx, m, aggs); err != nil {
+		return nil, err
+	}
+
+	columns := []string{column}
+	for _, agg := range aggs {
+		columns = append(columns, agg.Column)
+	}
+	reader, err := s.aggregateReader(ctx, m, columns, filters)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Release()
+
+	type group struct {
+		rows        int64
+		aggregators []*aggregator
+	}
+	groups := make(map[any]*group)
+	for reader.Next() {
+		rec := reader.Record()
+		keyColumn := rec.Column(rec.Schema().FieldIndices(column)[0])
+		rows := make(map[any][]int64)
+		for i := 0; i < int(rec.NumRows()); i++ {
+			key := groupKey(keyColumn, i)
+			rows[key] = append(rows[key], int64(i))
+		}
+		for key, indices := range rows {
+			g, ok := groups[key]
+			if !ok {
+				g = &group{}
+				for _, agg := range aggs {
+					g.aggregators = append(g.aggregators, newAggregator(m, agg))
+				}
+				groups[key] = g
+			}
+			g.rows += int64(len(indices))
+			if err := addGroupRows(g.aggregators, rec, indices); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := reader.Err(); err != nil {
+		return nil, err
+	}
+
+	result := make([]Group, 0, len(groups))
+	for key, g := range groups {
+		values := make([]any, len(g.aggregators))
+		for i, a := range g.aggregators {
+			values[i] = a.result()
+		}
+		result = append(result, Group{Key: key, Rows: g.rows, Values: values})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Rows != result[j].Rows {
+			return result[i].Rows > result[j].Rows
+		}
+		return lessKey(result[i].Key, result[j].Key)
+	})
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+// addGroupRows adds the rows of rec at indices to the aggregators of a group.
+func addGroupRows(aggregators []*aggregator, rec arrow.Record, indices []int64) error {
+	builder := array.NewInt64Builder(memory.DefaultAllocator)
+	defer builder.Release()
+	builder.AppendValues(indices, nil)
+	take := builder.NewArray()
+	defer take.Release()
+
+	for _, a := range aggregators {
+		if a.spec.Column == "" {
+			a.add(int64(len(indices)), nil)
+			continue
+		}
+		column := rec.Column(rec.Schema().FieldIndices(a.spec.Column)[0])
+		if len(indices) == column.Len() {
+			a.add(int64(len(indices)), column)
+			continue
+		}
+		taken, err := compute.TakeArray(context.Background(), column, take)
+		if err != nil {
+			return fmt.Errorf("group by: %w", err)
+		}
+		a.add(int64(len(indices)), taken)
+		taken.Release()
+	}
+	return nil
+}
+
+// groupKey returns the value of row i of an integer, string or boolean column, nil if null.
+func groupKey(column arrow.Array, i int) any {
+	if column.IsNull(i) {
+		return nil
+	}
+	switch c := column.(type) {
+	case *array.Int8:
+		return c.Value(i)
+	case *array.Int16:
+		return c.Value(i)
+	case *array.Int32:
+		return c.Value(i)
+	case *array.Int64:
+		return c.Value(i)
+	case *array.Uint8:
+		return c.Value(i)
+	case *array.Uint16:
+		return c.Value(i)
+	case *array.Uint32:
+		return c.Value(i)
+	case *array.Uint64:
+		return c.Value(i)
+	case *array.String:
+		return c.Value(i)
+	case *array.Boolean:
+		return c.Value(i)
+	default:
+		panic(fmt.Sprintf("unsupported group by column type %s", column.DataType()))
+	}
+}
+
+// lessKey orders the keys returned by groupKey for one column, nil first.
+func lessKey(a, b any) bool {
+	if a == nil || b == nil {
+		return a == nil && b != nil
+	}
+	switch x := a.(type) {
+	case int8:
+		return x < b.(int8)
+	case int16:
+		return x < b.(int16)
+	case int32:
+		return x < b.(int32)
+	case int64:
+		return x < b.(int64)
+	case uint8:
+		return x < b.(uint8)
+	case uint16:
+		return x < b.(uint16)
+	case uint32:
+		return x < b.(uint32)
+	case uint64:
+		return x < b.(uint64)
+	case string:
+		return x < b.(string)
+	case bool:
+		return !x && b.(bool)
+	default:
+		return false
+	}
+}
@@ -0,0 +1,124 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/milvus-io/milvus-storage/go/common/log"
+	"github.com/milvus-io/milvus-storage/go/file/blob"
+	"github.com/milvus-io/milvus-storage/go/file/fragment"
+	"github.com/milvus-io/milvus-storage/go/storage/auth"
+	"github.com/milvus-io/milvus-storage/go/storage/manifest"
+	"github.com/milvus-io/milvus-storage/go/storage/options/option"
+)
+
+// Index is a blob registered as an index over data fragments, see RegisterIndex.
+type Index struct {
+	Name string
+	// Version is the version the fragments were read at.
+	Version   int64
+	Fragments []int64
+	// Stale is set once compaction rewrote one of the fragments, the index must be rebuilt.
+	Stale bool
+}
+
+// RegisterIndex declares blob name an index over fragments of version, see
+// RegisterIndexContext.
+func (s *Space) RegisterIndex(name string, version int64, fragmentIDs ...int64) error {
+	return s.RegisterIndexContext(context.Background(), name, version, fragmentIDs...)
+}
+
+// RegisterIndexContext declares blob name an index built from the data fragments fragmentIDs
+// as read at version, all the fragments of version if fragmentIDs is empty. Compaction marks
+// the index stale once it rewrites one of them, see IndexesFor. Replacing the blob drops the
+// registration. It fails with ErrFragmentNotExist if a fragment is not in version or was
+// rewritten since. ctx carries the caller identity, it requires auth.OpWriteBlob.
+func (s *Space) RegisterIndexContext(ctx context.Context, name string, version int64, fragmentIDs ...int64) error {
+	if err := s.authorize(ctx, auth.OpWriteBlob); err != nil {
+		return err
+	}
+	m, err := s.manifestAt(version)
+	if err != nil {
+		return err
+	}
+	var covered fragment.FragmentVector
+	if len(fragmentIDs) == 0 {
+		covered = m.GetScalarFragments()
+	}
+	for _, id := range fragmentIDs {
+		i := findFragment(m.GetScalarFragments(), id)
+		if i < 0 {
+			return fmt.Errorf("register index %s on fragment %d of version %d: %w", name, id, version, ErrFragmentNotExist)
+		}
+		covered = append(covered, m.GetScalarFragments()[i])
+	}
+	coverage := &blob.Coverage{Version: version}
+	for _, f := range covered {
+		coverage.Fragments = append(coverage.Fragments, f.FragmentId())
+	}
+
+	record := &option.AuditRecord{Operation: auth.OpWriteBlob}
+	return s.commit(ctx, record, func(latest *manifest.Manifest, _ int64) error {
+		b, ok := latest.GetBlob(name)
+		if !ok {
+			return ErrBlobNotExist
+		}
+		for _, f := range covered {
+			if !hasFragment(latest.GetScalarFragments(), f) {
+				return fmt.Errorf("register index %s on fragment %d of version %d rewritten since: %w", name, f.FragmentId(), version, ErrFragmentNotExist)
+			}
+		}
+		b.Coverage = coverage
+		latest.RemoveBlobIfExist(name)
+		latest.AddBlob(b)
+		return nil
+	})
+}
+
+// IndexesFor returns the indexes built from data fragment id, see IndexesForContext.
+func (s *Space) IndexesFor(fragmentID int64) ([]Index, error) {
+	return s.IndexesForContext(context.Background(), fragmentID)
+}
+
+// IndexesForContext returns the indexes registered over data fragment id, stale ones included.
+// ctx carries the caller identity, it requires auth.OpReadBlob.
+func (s *Space) IndexesForContext(ctx context.Context, fragmentID int64) ([]Index, error) {
+	if err := s.authorize(ctx, auth.OpReadBlob); err != nil {
+		return nil, err
+	}
+	var indexes []Index
+	for _, b := range s.snapshot().GetBlobs() {
+		if b.Coverage == nil || !b.Coverage.Covers(fragmentID) {
+			continue
+		}
+		indexes = append(indexes, Index{
+			Name:      b.Name,
+			Version:   b.Coverage.Version,
+			Fragments: append([]int64(nil), b.Coverage.Fragments...),
+			Stale:     b.Coverage.Stale,
+		})
+	}
+	return indexes, nil
+}
+
+// invalidateIndexes marks stale the indexes of m built from fragments of before that m no
+// longer holds with the same files.
+func invalidateIndexes(m *manifest.Manifest, before fragment.FragmentVector) {
+	blobs := m.GetBlobs()
+	for i, b := range blobs {
+		if b.Coverage == nil || b.Coverage.Stale {
+			continue
+		}
+		for _, f := range before {
+			if !b.Coverage.Covers(f.FragmentId()) || hasFragment(m.GetScalarFragments(), f) {
+				continue
+			}
+			log.Warn("index source rewritten", log.String("index", b.Name), log.Int64("fragment", f.FragmentId()))
+			// the coverage is shared with the manifest copied from
+			coverage := *b.Coverage
+			coverage.Stale = true
+			blobs[i].Coverage = &coverage
+			break
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package storage
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/milvus-io/milvus-storage/go/common/log"
+	"github.com/milvus-io/milvus-storage/go/common/utils"
+	"github.com/milvus-io/milvus-storage/go/io/fs"
+)
+
+// writeLatestHint records version as the latest version of the space at path, so that opening
+// it does not list the manifest directory. The version is already committed, a failure only
+// makes the next open slower and is logged.
+func writeLatestHint(f fs.Fs, path string, version int64) {
+	if err := fs.WriteFile(f, utils.GetLatestFilePath(path), []byte(strconv.FormatInt(version, 10))); err != nil {
+		log.Warn("write latest version hint failed", log.String("path", path), log.String("err", err.Error()))
+	}
+}
+
+// latestVersion returns the newest version of the space at path, -1 if it has none. The hint is
+// only a starting point: concurrent commits may leave it behind, so newer manifests are
+// probed for. The manifest directory is listed when the hint is missing or wrong.
+func latestVersion(f fs.Fs, path string) (int64, error) {
+	if version, ok := hintedVersion(f, path); ok {
+		for {
+			exist, err := f.Exist(utils.GetManifestFilePath(path, version+1))
+			if err != nil {
+				return -1, err
+			}
+			if !exist {
+				return version, nil
+			}
+			version++
+		}
+	}
+	versions, err := manifestVersions(f, path)
+	if err != nil {
+		return -1, err
+	}
+	if len(versions) == 0 {
+		return -1, nil
+	}
+	return versions[len(versions)-1], nil
+}
+
+// hintedVersion returns the version of the hint if its manifest exists.
+func hintedVersion(f fs.Fs, path string) (int64, bool) {
+	content, err := f.ReadFile(utils.GetLatestFilePath(path))
+	if err != nil {
+		return 0, false
+	}
+	version, err := strconv.ParseInt(strings.TrimSpace(string(content)), 10, 64)
+	if err != nil || version < 0 {
+		log.Warn("ignore invalid latest version hint", log.String("path", path), log.String("hint", string(content)))
+		return 0, false
+	}
+	exist, err := f.Exist(utils.GetManifestFilePath(path, version))
+	if err != nil || !exist {
+		log.Debug("ignore stale latest version hint", log.String("path", path), log.Int64("version", version))
+		return 0, false
+	}
+	return version, true
+}
+
+// migrateLegacyManifests copies the manifests of a space saved in the legacy manifest directory
+// by early releases to the manifest directory, so that it opens with its data. It reports
+// whether any was copied. The legacy files are left in place for older releases. Versions
+// already in the manifest directory, e.g. committed by a process that migrated the space
+// concurrently, are kept as they are.
+func migrateLegacyManifests(f fs.Fs, path string) (bool, error) {
+	entries, err := listIfExist(f, utils.GetLegacyManifestDir(path))
+	if err != nil {
+		return false, err
+	}
+	var migrated bool
+	for _, entry := range entries {
+		version := utils.ParseVersionFromFileName(filepath.Base(entry.Path))
+		if version == -1 {
+			continue
+		}
+		manifestFilePath := utils.GetManifestFilePath(path, version)
+		exist, err := f.Exist(manifestFilePath)
+		if err != nil {
+			return false, fmt.Errorf("migrate manifest version %d: %w", version, err)
+		}
+		if exist {
+			log.Debug("skip migrated manifest", log.String("path", path), log.Int64("version", version))
+			continue
+		}
+		if err = f.Copy(entry.Path, manifestFilePath); err != nil {
+			return false, fmt.Errorf("migrate manifest version %d: %w", version, err)
+		}
+		migrated = true
+	}
+	if migrated {
+		log.Info("migrated legacy manifests", log.String("path", path))
+	}
+	return migrated, nil
+}
@@ -0,0 +1,43 @@
+package storage
+
+import (
+	"context"
+
+	"github.com/milvus-io/milvus-storage/go/file/layout"
+	"github.com/milvus-io/milvus-storage/go/storage/auth"
+	"github.com/milvus-io/milvus-storage/go/storage/manifest"
+	"github.com/milvus-io/milvus-storage/go/storage/options/option"
+)
+
+// Layout returns the directory layout of the data files of the current version.
+func (s *Space) Layout() layout.Layout {
+	return s.snapshot().Layout()
+}
+
+// SetLayout places the later data files with scheme, see SetLayoutContext.
+func (s *Space) SetLayout(scheme layout.Scheme) error {
+	return s.SetLayoutContext(context.Background(), scheme)
+}
+
+// SetLayoutContext records scheme in a new version with the next layout version, every writer
+// then places its data and delete files with it. Files already written are not moved, the
+// manifest references them by path. Nothing is committed if the space already uses scheme.
+// Unknown schemes fail with layout.ErrUnknownScheme. ctx carries the caller identity, it
+// requires auth.OpAdmin.
+func (s *Space) SetLayoutContext(ctx context.Context, scheme layout.Scheme) error {
+	if err := s.authorize(ctx, auth.OpAdmin); err != nil {
+		return err
+	}
+	if err := scheme.Check(); err != nil {
+		return err
+	}
+	if s.snapshot().Layout().Scheme == scheme {
+		return nil
+	}
+	record := &option.AuditRecord{Operation: auth.OpAdmin}
+	return s.commit(ctx, record, func(latest *manifest.Manifest, _ int64) error {
+		current := latest.Layout()
+		latest.SetLayout(layout.Layout{Version: current.Version + 1, Scheme: scheme})
+		return nil
+	})
+}
@@ -0,0 +1,196 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/milvus-io/milvus-storage/go/common/constant"
+	"github.com/milvus-io/milvus-storage/go/common/log"
+	"github.com/milvus-io/milvus-storage/go/common/utils"
+	"github.com/milvus-io/milvus-storage/go/io/fs"
+	"github.com/milvus-io/milvus-storage/go/storage/options/option"
+)
+
+var _ option.Lease = (*fileLease)(nil)
+
+type leaseRecord struct {
+	Owner    string `json:"owner"`
+	ExpireAt int64  `json:"expire_at"`
+}
+
+// fileLease is a lease persisted as a small file in the space root. While held, a
+// heartbeat extends the expiry every ttl/3. The lease is advisory: fs has no compare and
+// swap, so two writers racing on an expired lease are resolved by re-reading the file after
+// writing it, and the ttl should be generous compared to clock skew between hosts.
+type fileLease struct {
+	fs    fs.Fs
+	path  string
+	owner string
+	ttl   time.Duration
+
+	lock sync.Mutex
+	held bool
+	stop chan struct{}
+	done chan struct{}
+}
+
+func newFileLease(f fs.Fs, spacePath string, options *option.LeaseOptions) *fileLease {
+	owner := options.Owner
+	if owner == "" {
+		hostname, _ := os.Hostname()
+		owner = fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), uuid.NewString())
+	}
+	ttl := options.TTL
+	if ttl <= 0 {
+		ttl = option.DefaultLeaseTTL
+	}
+	return &fileLease{
+		fs:    f,
+		path:  utils.GetLeaseFilePath(spacePath),
+		owner: owner,
+		ttl:   ttl,
+	}
+}
+
+func (l *fileLease) Acquire() error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	current, err := l.read()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if current != nil && current.Owner != l.owner && now.UnixMilli() < current.ExpireAt {
+		l.stopHeartbeat()
+		return fmt.Errorf("acquire lease %s owned by %s: %w", l.path, current.Owner, ErrLeaseHeld)
+	}
+	if current != nil && current.Owner != l.owner {
+		log.Warn("take over expired lease", log.String("path", l.path), log.String("previous owner", current.Owner))
+	}
+
+	if err = l.write(now); err != nil {
+		return err
+	}
+	// another writer may have taken the expired lease at the same time
+	current, err = l.read()
+	if err != nil {
+		return err
+	}
+	if current == nil || current.Owner != l.owner {
+		l.stopHeartbeat()
+		return fmt.Errorf("acquire lease %s: %w", l.path, ErrLeaseHeld)
+	}
+
+	if !l.held {
+		l.held = true
+		l.stop = make(chan struct{})
+		l.done = make(chan struct{})
+		go l.heartbeat(l.stop, l.done)
+	}
+	return nil
+}
+
+func (l *fileLease) Release() error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if !l.held {
+		return nil
+	}
+	l.stopHeartbeat()
+
+	current, err := l.read()
+	if err != nil {
+		return err
+	}
+	if current == nil || current.Owner != l.owner {
+		return nil
+	}
+	return l.fs.DeleteFile(l.path)
+}
+
+// stopHeartbeat must be called with lock held. It returns once no renewal is in flight.
+func (l *fileLease) stopHeartbeat() {
+	if !l.held {
+		return
+	}
+	close(l.stop)
+	<-l.done
+	l.held = false
+}
+
+func (l *fileLease) heartbeat(stop, done chan struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(l.ttl / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if err := l.renew(); err != nil {
+				log.Warn("renew lease failed", log.String("path", l.path), log.String("err", err.Error()))
+			}
+		}
+	}
+}
+
+// renew runs on the heartbeat goroutine without lock, only fields that never change are used.
+func (l *fileLease) renew() error {
+	current, err := l.read()
+	if err != nil {
+		return err
+	}
+	if current != nil && current.Owner != l.owner {
+		// lost the lease, the next commit will fail in Acquire
+		return fmt.Errorf("renew lease owned by %s: %w", current.Owner, ErrLeaseHeld)
+	}
+	return l.write(time.Now())
+}
+
+func (l *fileLease) read() (*leaseRecord, error) {
+	exist, err := l.fs.Exist(l.path)
+	if err != nil {
+		return nil, fmt.Errorf("read lease: %w", err)
+	}
+	if !exist {
+		return nil, nil
+	}
+	buf, err := l.fs.ReadFile(l.path)
+	if err != nil {
+		return nil, fmt.Errorf("read lease: %w", err)
+	}
+	record := &leaseRecord{}
+	if err = json.Unmarshal(buf, record); err != nil {
+		// a torn lease file is treated as expired
+		log.Warn("parse lease failed", log.String("path", l.path), log.String("err", err.Error()))
+		return nil, nil
+	}
+	return record, nil
+}
+
+func (l *fileLease) write(now time.Time) error {
+	buf, err := json.Marshal(&leaseRecord{Owner: l.owner, ExpireAt: now.Add(l.ttl).UnixMilli()})
+	if err != nil {
+		return fmt.Errorf("write lease: %w", err)
+	}
+	tmpPath := l.path + constant.LeaseTempFileSuffix + "." + uuid.NewString()
+	f, err := l.fs.OpenFile(tmpPath)
+	if err != nil {
+		return fmt.Errorf("write lease: %w", err)
+	}
+	if _, err = f.Write(buf); err != nil {
+		return fmt.Errorf("write lease: %w", err)
+	}
+	if err = f.Close(); err != nil {
+		return fmt.Errorf("write lease: %w", err)
+	}
+	if err = l.fs.Rename(tmpPath, l.path); err != nil {
+		return fmt.Errorf("write lease: %w", err)
+	}
+	return nil
+}
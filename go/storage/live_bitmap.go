@@ -0,0 +1,194 @@
+package storage
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/bits-and-blooms/bitset"
+	"github.com/milvus-io/milvus-storage/go/common/constant"
+	"github.com/milvus-io/milvus-storage/go/common/errors"
+	"github.com/milvus-io/milvus-storage/go/common/log"
+	"github.com/milvus-io/milvus-storage/go/common/utils"
+	"github.com/milvus-io/milvus-storage/go/file/fragment"
+	"github.com/milvus-io/milvus-storage/go/io/fs"
+	"github.com/milvus-io/milvus-storage/go/storage/manifest"
+	"github.com/milvus-io/milvus-storage/go/storage/options/option"
+)
+
+// cachedBitmap is the deleted rows of the scalar fragment fragmentID loaded from its sidecar.
+type cachedBitmap struct {
+	fragmentID int64
+	rows       fragment.DeletedRows
+}
+
+// bitmapFilePath returns the path of the sidecar holding the deleted rows of the scalar
+// fragment f in m. The name changes with the files of the fragment and the delete fragments
+// of m, so a sidecar is never rewritten and reads never see a stale one.
+func (s *Space) bitmapFilePath(m *manifest.Manifest, f fragment.Fragment) string {
+	h := fnv.New64a()
+	for _, file := range f.Files() {
+		h.Write([]byte(file))
+		h.Write([]byte{0})
+	}
+	for _, d := range m.GetDeleteFragments() {
+		var id [8]byte
+		binary.LittleEndian.PutUint64(id[:], uint64(d.FragmentId()))
+		h.Write(id[:])
+	}
+	return utils.GetBitmapFilePath(s.path, f.FragmentId(), h.Sum64())
+}
+
+// refreshLiveBitmaps writes the sidecars of the scalar fragments of m that have none yet. The
+// version is already committed, so failures are logged and reads of the fragments keep
+// matching rows against the delete fragments.
+func (s *Space) refreshLiveBitmaps(m *manifest.Manifest) {
+	if len(m.GetDeleteFragments()) == 0 {
+		return
+	}
+	var deletes *fragment.DeleteFragment
+	for _, f := range m.GetScalarFragments() {
+		path := s.bitmapFilePath(m, f)
+		s.bitmapLock.Lock()
+		_, ok := s.bitmapCache[path]
+		s.bitmapLock.Unlock()
+		if ok {
+			continue
+		}
+		if exist, err := s.fs.Exist(path); err == nil && exist {
+			continue
+		}
+
+		if deletes == nil {
+			deleteFragments, err := s.deleteFragments(m)
+			if err != nil {
+				log.Warn("refresh live bitmaps failed", log.String("path", s.path), log.String("err", err.Error()))
+				return
+			}
+			deletes = fragment.NewDeleteFragment(m.Version(), m.GetSchema(), s.fs)
+			for i := range deleteFragments {
+				deletes.Merge(&deleteFragments[i])
+			}
+		}
+		rows := make(fragment.DeletedRows, len(f.Files()))
+		for _, file := range f.Files() {
+			deleted, ok, err := fileDeletedRows(s.fs, m, file, deletes)
+			if err != nil {
+				log.Warn("refresh live bitmaps failed", log.String("path", file), log.String("err", err.Error()))
+				return
+			}
+			// files whose rows are not numbered in order are left to the delete fragments
+			if ok {
+				rows[file] = deleted
+			}
+		}
+		content, err := json.Marshal(rows)
+		if err == nil {
+			err = fs.WriteFile(s.fs, path, content)
+		}
+		if err != nil {
+			log.Warn("refresh live bitmaps failed", log.String("path", path), log.String("err", err.Error()))
+			return
+		}
+		// the cache is replaced rather than updated, readers use it without the lock
+		s.bitmapLock.Lock()
+		cache := make(map[string]cachedBitmap, len(s.bitmapCache)+1)
+		for cachedPath, cachedRows := range s.bitmapCache {
+			cache[cachedPath] = cachedRows
+		}
+		cache[path] = cachedBitmap{fragmentID: f.FragmentId(), rows: rows}
+		s.bitmapCache = cache
+		s.bitmapLock.Unlock()
+	}
+}
+
+// fileDeletedRows returns the offsets of the rows of the scalar file removed by deletes. It
+// reports false if the offsets of the file are not the positions of its rows, as in files
+// written before offsets were numbered per file.
+func fileDeletedRows(f fs.Fs, m *manifest.Manifest, path string, deletes *fragment.DeleteFragment) (*bitset.BitSet, bool, error) {
+	schemaOptions := m.GetSchema().Options()
+	readOptions := option.NewReadOptions()
+	readOptions.AddColumn(schemaOptions.PrimaryColumn)
+	readOptions.AddColumn(constant.OffsetFieldName)
+	if schemaOptions.HasVersionColumn() {
+		readOptions.AddColumn(schemaOptions.VersionColumn)
+	}
+
+	deleted := bitset.New(0)
+	ordered := true
+	var position int64
+	err := readFile(f, path, readOptions, func(rec arrow.Record) error {
+		pkColumn := rec.Column(rec.Schema().FieldIndices(schemaOptions.PrimaryColumn)[0])
+		offsets := rec.Column(rec.Schema().FieldIndices(constant.OffsetFieldName)[0]).(*array.Int64)
+		var versions *array.Int64
+		if schemaOptions.HasVersionColumn() {
+			versions = rec.Column(rec.Schema().FieldIndices(schemaOptions.VersionColumn)[0]).(*array.Int64)
+		}
+		for i := 0; i < int(rec.NumRows()); i++ {
+			if offsets.Value(i) != position {
+				ordered = false
+			}
+			position++
+			var version int64
+			if versions != nil {
+				version = versions.Value(i)
+			}
+			if deletes.IsDeleted(fragment.PkValue(pkColumn, i), version) {
+				deleted.Set(uint(offsets.Value(i)))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("deleted rows of %s: %w", path, err)
+	}
+	return deleted, ordered, nil
+}
+
+// deletedRows loads the sidecars of the scalar fragments of m. Fragments without a sidecar
+// are missing from the result, their reads match rows against the delete fragments.
+func (s *Space) deletedRows(m *manifest.Manifest) fragment.DeletedRows {
+	s.bitmapLock.Lock()
+	cached := s.bitmapCache
+	s.bitmapLock.Unlock()
+
+	loaded := make(map[string]cachedBitmap, len(m.GetScalarFragments()))
+	deletedRows := make(fragment.DeletedRows)
+	for _, f := range m.GetScalarFragments() {
+		path := s.bitmapFilePath(m, f)
+		entry, ok := cached[path]
+		rows := entry.rows
+		if !ok {
+			content, err := s.fs.ReadFile(path)
+			if err != nil {
+				if !errors.Is(err, errors.ErrNotFound) {
+					log.Warn("load live bitmap failed", log.String("path", path), log.String("err", err.Error()))
+				}
+				continue
+			}
+			if err = json.Unmarshal(content, &rows); err != nil {
+				log.Warn("load live bitmap failed", log.String("path", path), log.String("err", err.Error()))
+				continue
+			}
+		}
+		loaded[path] = cachedBitmap{fragmentID: f.FragmentId(), rows: rows}
+		for file, deleted := range rows {
+			deletedRows[file] = deleted
+		}
+	}
+	// the deleted rows of pinned fragments stay cached while other versions are read
+	latest := s.snapshot()
+	for path, entry := range cached {
+		if _, ok := loaded[path]; !ok && latest.IsPinned(entry.fragmentID) {
+			loaded[path] = entry
+		}
+	}
+
+	s.bitmapLock.Lock()
+	s.bitmapCache = loaded
+	s.bitmapLock.Unlock()
+	return deletedRows
+}
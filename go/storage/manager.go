@@ -0,0 +1,238 @@
+package storage
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/milvus-io/milvus-storage/go/io/fs"
+	"github.com/milvus-io/milvus-storage/go/storage/options/option"
+)
+
+// DefaultManager is the Manager shared by the process.
+var DefaultManager = NewManager()
+
+// Manager deduplicates the opening of spaces: concurrent and repeated Opens of the same URI and
+// version return the same Space, which is created, and its manifest loaded, only once. Spaces
+// of the same URI share their file system and with it its connections, and a shared Space
+// shares its delete, bitmap and blob caches between the callers.
+//
+// A Space stays open while any of its handles does. Opens of a space already open return it as
+// it is, commits made by other processes are only seen once every handle is closed and the
+// space is opened again.
+type Manager struct {
+	mu          sync.Mutex
+	spaces      map[managerKey]*sharedSpace
+	fss         map[string]*sharedFs
+	memoryLimit int64
+}
+
+type managerKey struct {
+	uri     string
+	version int64
+}
+
+// sharedSpace is a space being opened until ready is closed, then space or err are set.
+type sharedSpace struct {
+	ready chan struct{}
+	space *Space
+	err   error
+	refs  int
+}
+
+type sharedFs struct {
+	fs   fs.Fs
+	path string
+	refs int
+}
+
+func NewManager() *Manager {
+	return &Manager{
+		spaces: make(map[managerKey]*sharedSpace),
+		fss:    make(map[string]*sharedFs),
+	}
+}
+
+// SpaceHandle is a Space shared through a Manager, Close releases it.
+type SpaceHandle struct {
+	*Space
+	once    sync.Once
+	release func() error
+	err     error
+}
+
+// Close releases the handle, the Space is closed once all of its handles are, and the file
+// system once all the spaces using it are. The Space must not be used after its handle is
+// closed.
+func (h *SpaceHandle) Close() error {
+	h.once.Do(func() { h.err = h.release() })
+	return h.err
+}
+
+// Open returns a handle to the space at uri, see Open. The options of the first Open of a space
+// apply until all of its handles are closed, later Opens only differing by other options than
+// the version share it.
+func (mgr *Manager) Open(uri string, op option.Options) (*SpaceHandle, error) {
+	key := managerKey{uri: uri, version: op.Version}
+	mgr.mu.Lock()
+	shared, ok := mgr.spaces[key]
+	if ok {
+		shared.refs++
+		mgr.mu.Unlock()
+		<-shared.ready
+		if shared.err != nil {
+			return nil, shared.err
+		}
+		return mgr.handle(key, shared), nil
+	}
+	shared = &sharedSpace{ready: make(chan struct{}), refs: 1}
+	mgr.spaces[key] = shared
+	mgr.mu.Unlock()
+
+	shared.space, shared.err = mgr.open(uri, op)
+	if shared.err == nil {
+		shared.space.memoryLimit = mgr.checkMemory
+	}
+	if shared.err != nil {
+		// the failure is returned to the Opens waiting for it, the next Open tries again
+		mgr.mu.Lock()
+		delete(mgr.spaces, key)
+		mgr.mu.Unlock()
+		close(shared.ready)
+		return nil, shared.err
+	}
+	close(shared.ready)
+	return mgr.handle(key, shared), nil
+}
+
+// open opens the space on the shared file system of uri. The file system is referenced before
+// the space is opened, so that a concurrent failure or release does not close it in use.
+func (mgr *Manager) open(uri string, op option.Options) (*Space, error) {
+	f, err := mgr.acquireFs(uri)
+	if err != nil {
+		return nil, err
+	}
+	space, err := open(f.fs, f.path, op)
+	if err != nil {
+		if closeFs := mgr.releaseFs(uri, f); closeFs != nil {
+			closeFs.Close()
+		}
+		return nil, err
+	}
+	return space, nil
+}
+
+// acquireFs returns the shared file system of uri, building it if needed, with a reference
+// taken for the caller.
+func (mgr *Manager) acquireFs(uri string) (*sharedFs, error) {
+	mgr.mu.Lock()
+	if f, ok := mgr.fss[uri]; ok {
+		f.refs++
+		mgr.mu.Unlock()
+		return f, nil
+	}
+	mgr.mu.Unlock()
+
+	built, path, err := buildFs(uri)
+	if err != nil {
+		return nil, err
+	}
+	mgr.mu.Lock()
+	// another version of the space may have built it meanwhile
+	f, ok := mgr.fss[uri]
+	if !ok {
+		f = &sharedFs{fs: built, path: path}
+		mgr.fss[uri] = f
+	}
+	f.refs++
+	mgr.mu.Unlock()
+	if ok {
+		built.Close()
+	}
+	return f, nil
+}
+
+// releaseFs drops a reference to f and returns its file system to close once none is left.
+func (mgr *Manager) releaseFs(uri string, f *sharedFs) fs.Fs {
+	mgr.mu.Lock()
+	defer mgr.mu.Unlock()
+	if f.refs--; f.refs > 0 {
+		return nil
+	}
+	if mgr.fss[uri] == f {
+		delete(mgr.fss, uri)
+	}
+	return f.fs
+}
+
+func (mgr *Manager) handle(key managerKey, shared *sharedSpace) *SpaceHandle {
+	return &SpaceHandle{Space: shared.space, release: func() error {
+		mgr.mu.Lock()
+		if shared.refs--; shared.refs > 0 {
+			mgr.mu.Unlock()
+			return nil
+		}
+		delete(mgr.spaces, key)
+		f := mgr.fss[key.uri]
+		mgr.mu.Unlock()
+
+		err := shared.space.Close()
+		var closeFs fs.Fs
+		if f != nil {
+			closeFs = mgr.releaseFs(key.uri, f)
+		}
+		if closeFs != nil {
+			if closeErr := closeFs.Close(); err == nil {
+				err = closeErr
+			}
+		}
+		return err
+	}}
+}
+
+// SetMemoryLimit caps the memory held by the spaces open through the manager, see
+// Space.MemoryUsage. Reads and writes started above the limit drop the caches of the spaces
+// first, then fail with ErrMemoryLimitExceeded if that was not enough. Zero, the default, means
+// no limit.
+func (mgr *Manager) SetMemoryLimit(limit int64) {
+	atomic.StoreInt64(&mgr.memoryLimit, limit)
+}
+
+// MemoryUsage returns the memory held by the spaces open through the manager.
+func (mgr *Manager) MemoryUsage() MemoryUsage {
+	var usage MemoryUsage
+	for _, space := range mgr.openSpaces() {
+		usage = usage.add(space.MemoryUsage())
+	}
+	return usage
+}
+
+func (mgr *Manager) openSpaces() []*Space {
+	mgr.mu.Lock()
+	defer mgr.mu.Unlock()
+	spaces := make([]*Space, 0, len(mgr.spaces))
+	for _, shared := range mgr.spaces {
+		select {
+		case <-shared.ready:
+			if shared.space != nil {
+				spaces = append(spaces, shared.space)
+			}
+		default:
+		}
+	}
+	return spaces
+}
+
+func (mgr *Manager) checkMemory() error {
+	limit := atomic.LoadInt64(&mgr.memoryLimit)
+	if limit <= 0 || mgr.MemoryUsage().Total() <= limit {
+		return nil
+	}
+	for _, space := range mgr.openSpaces() {
+		space.dropCaches(space.snapshot())
+	}
+	if usage := mgr.MemoryUsage(); usage.Total() > limit {
+		return fmt.Errorf("%d bytes in use, limit %d: %w", usage.Total(), limit, ErrMemoryLimitExceeded)
+	}
+	return nil
+}
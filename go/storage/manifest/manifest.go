@@ -2,11 +2,15 @@ package manifest
 
 import (
 	"fmt"
+	"path/filepath"
+	"strings"
 
 	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/milvus-io/milvus-storage/go/common/errors"
 	"github.com/milvus-io/milvus-storage/go/common/log"
 	"github.com/milvus-io/milvus-storage/go/file/blob"
 	"github.com/milvus-io/milvus-storage/go/file/fragment"
+	"github.com/milvus-io/milvus-storage/go/file/layout"
 	"github.com/milvus-io/milvus-storage/go/io/fs"
 	"github.com/milvus-io/milvus-storage/go/io/fs/file"
 	"github.com/milvus-io/milvus-storage/go/proto/manifest_proto"
@@ -15,6 +19,8 @@ import (
 	"google.golang.org/protobuf/proto"
 )
 
+var ErrFragmentMismatch = errors.New("scalar and vector fragments do not match")
+
 type Manifest struct {
 	schema          *schema.Schema
 	ScalarFragments fragment.FragmentVector
@@ -22,11 +28,28 @@ type Manifest struct {
 	deleteFragments fragment.FragmentVector
 	blobs           []blob.Blob
 	version         int64
+	usage           Usage
+	droppedAt       int64
+	nextAutoID      int64
+	properties      map[string]string
+	reservedColumns []string
+	pinnedFragments []int64
+	nextFragmentID  int64
+	layout          layout.Layout
+}
+
+// Usage is the amount of data referenced by a manifest, used to enforce space quotas.
+// Bytes covers data, delete and blob files. Rows counts written rows, deleted rows are only
+// subtracted once compaction purges them.
+type Usage struct {
+	Rows  int64
+	Bytes int64
 }
 
 func NewManifest(schema *schema.Schema) *Manifest {
 	return &Manifest{
-		schema: schema,
+		schema:          schema,
+		reservedColumns: schema_option.ReservedColumns(),
 	}
 }
 
@@ -36,8 +59,15 @@ func Init() *Manifest {
 	}
 }
 
+// Copy returns a copy that can be modified without affecting m. Fragments and blobs are
+// copied so that appending to or removing from the copy never touches m's backing arrays.
 func (m *Manifest) Copy() *Manifest {
 	copied := *m
+	copied.ScalarFragments = append(fragment.FragmentVector(nil), m.ScalarFragments...)
+	copied.vectorFragments = append(fragment.FragmentVector(nil), m.vectorFragments...)
+	copied.deleteFragments = append(fragment.FragmentVector(nil), m.deleteFragments...)
+	copied.blobs = append([]blob.Blob(nil), m.blobs...)
+	copied.pinnedFragments = append([]int64(nil), m.pinnedFragments...)
 	return &copied
 }
 
@@ -45,6 +75,11 @@ func (m *Manifest) GetSchema() *schema.Schema {
 	return m.schema
 }
 
+// SetSchema replaces the schema, e.g. once a column is added to every data file.
+func (m *Manifest) SetSchema(schema *schema.Schema) {
+	m.schema = schema
+}
+
 func (m *Manifest) AddScalarFragment(fragment fragment.Fragment) {
 	m.ScalarFragments = append(m.ScalarFragments, fragment)
 }
@@ -57,6 +92,67 @@ func (m *Manifest) AddDeleteFragment(fragment fragment.Fragment) {
 	m.deleteFragments = append(m.deleteFragments, fragment)
 }
 
+func (m *Manifest) SetScalarFragments(fragments fragment.FragmentVector) {
+	m.ScalarFragments = fragments
+}
+
+func (m *Manifest) SetVectorFragments(fragments fragment.FragmentVector) {
+	m.vectorFragments = fragments
+}
+
+func (m *Manifest) SetDeleteFragments(fragments fragment.FragmentVector) {
+	m.deleteFragments = fragments
+}
+
+// DataFragment is a scalar fragment and the vector fragment holding the same rows: file i of
+// both holds the same rows in the same order, so columns are zipped back together by
+// position.
+type DataFragment struct {
+	Scalar fragment.Fragment
+	Vector fragment.Fragment
+}
+
+func (m *Manifest) AddDataFragment(f DataFragment) {
+	m.AddScalarFragment(f.Scalar)
+	m.AddVectorFragment(f.Vector)
+}
+
+// GetDataFragments pairs the scalar and vector fragments, it fails if they do not match.
+func (m *Manifest) GetDataFragments() ([]DataFragment, error) {
+	if err := m.ValidateDataFragments(); err != nil {
+		return nil, err
+	}
+	fragments := make([]DataFragment, len(m.ScalarFragments))
+	for i := range m.ScalarFragments {
+		fragments[i] = DataFragment{Scalar: m.ScalarFragments[i], Vector: m.vectorFragments[i]}
+	}
+	return fragments, nil
+}
+
+// ValidateDataFragments checks that every scalar fragment has a vector fragment with the
+// same id and number of files, and that paired files hold the same number of rows when
+// their stats are known.
+func (m *Manifest) ValidateDataFragments() error {
+	if len(m.ScalarFragments) != len(m.vectorFragments) {
+		return fmt.Errorf("%d scalar and %d vector fragments: %w", len(m.ScalarFragments), len(m.vectorFragments), ErrFragmentMismatch)
+	}
+	for i := range m.ScalarFragments {
+		scalar, vector := &m.ScalarFragments[i], &m.vectorFragments[i]
+		if scalar.FragmentId() != vector.FragmentId() || len(scalar.Files()) != len(vector.Files()) {
+			return fmt.Errorf("scalar fragment %d with %d files, vector fragment %d with %d files: %w",
+				scalar.FragmentId(), len(scalar.Files()), vector.FragmentId(), len(vector.Files()), ErrFragmentMismatch)
+		}
+		for j := range scalar.Files() {
+			scalarStats, vectorStats := scalar.FileStats()[j], vector.FileStats()[j]
+			if scalarStats.Known() && vectorStats.Known() && scalarStats.Rows != vectorStats.Rows {
+				return fmt.Errorf("%s with %d rows, %s with %d rows: %w",
+					scalar.Files()[j], scalarStats.Rows, vector.Files()[j], vectorStats.Rows, ErrFragmentMismatch)
+			}
+		}
+	}
+	return nil
+}
+
 func (m *Manifest) GetScalarFragments() fragment.FragmentVector {
 	return m.ScalarFragments
 }
@@ -77,9 +173,105 @@ func (m *Manifest) SetVersion(version int64) {
 	m.version = version
 }
 
+func (m *Manifest) GetUsage() Usage {
+	return m.usage
+}
+
+// AddUsage adds rows and bytes to the usage, negative values release usage.
+func (m *Manifest) AddUsage(rows, bytes int64) {
+	m.usage.Rows += rows
+	m.usage.Bytes += bytes
+}
+
+// DroppedAt returns the unix milliseconds when the space was soft dropped, or 0.
+func (m *Manifest) DroppedAt() int64 {
+	return m.droppedAt
+}
+
+func (m *Manifest) SetDroppedAt(droppedAt int64) {
+	m.droppedAt = droppedAt
+}
+
+// NextAutoID returns the next primary key generated for rows written without the primary
+// column, the keys below it are taken.
+func (m *Manifest) NextAutoID() int64 {
+	return m.nextAutoID
+}
+
+func (m *Manifest) SetNextAutoID(id int64) {
+	m.nextAutoID = id
+}
+
+// NewFragmentID returns the id of a data fragment committed by version and takes it. It is the
+// version, unless a commit of an earlier version took it for one of its fragments.
+func (m *Manifest) NewFragmentID(version int64) int64 {
+	id := version
+	if m.nextFragmentID > id {
+		id = m.nextFragmentID
+	}
+	m.nextFragmentID = id + 1
+	return id
+}
+
+// Properties returns the properties recorded by the commit of this version, e.g. a checkpoint
+// of an external system. They are not inherited by later versions, except the column
+// statistics kept by the space.
+func (m *Manifest) Properties() map[string]string {
+	return m.properties
+}
+
+func (m *Manifest) SetProperties(properties map[string]string) {
+	m.properties = properties
+}
+
+// ReservedColumns returns the names of the internal columns the schema was checked against
+// when the space was created, nil for spaces created before they were recorded.
+func (m *Manifest) ReservedColumns() []string {
+	return m.reservedColumns
+}
+
+// PinnedFragments returns the ids of the pinned data fragments, see IsPinned.
+func (m *Manifest) PinnedFragments() []int64 {
+	return m.pinnedFragments
+}
+
+func (m *Manifest) SetPinnedFragments(ids []int64) {
+	m.pinnedFragments = ids
+}
+
+// IsPinned reports whether the data fragment id is pinned, its files are then never rewritten
+// or moved, e.g. since an external index refers to the rows by file and offset.
+func (m *Manifest) IsPinned(id int64) bool {
+	for _, pinned := range m.pinnedFragments {
+		if pinned == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Layout returns the directory layout of the data files written to this version.
+func (m *Manifest) Layout() layout.Layout {
+	return m.layout
+}
+
+func (m *Manifest) SetLayout(l layout.Layout) {
+	m.layout = l
+}
+
 func (m *Manifest) ToProtobuf() (*manifest_proto.Manifest, error) {
 	manifest := &manifest_proto.Manifest{}
 	manifest.Version = m.version
+	manifest.Usage = &manifest_proto.Usage{Rows: m.usage.Rows, Bytes: m.usage.Bytes}
+	manifest.DroppedAt = m.droppedAt
+	manifest.NextAutoId = m.nextAutoID
+	manifest.Properties = m.properties
+	manifest.ReservedColumns = m.reservedColumns
+	manifest.PinnedFragments = m.pinnedFragments
+	manifest.NextFragmentId = m.nextFragmentID
+	if m.layout != (layout.Layout{}) {
+		manifest.PathLayout = m.layout.ToProtobuf()
+	}
 	for _, vectorFragment := range m.vectorFragments {
 		manifest.VectorFragments = append(manifest.VectorFragments, vectorFragment.ToProtobuf())
 	}
@@ -126,14 +318,33 @@ func (m *Manifest) FromProtobuf(manifest *manifest_proto.Manifest) error {
 	}
 
 	m.version = manifest.Version
+	m.usage = Usage{Rows: manifest.GetUsage().GetRows(), Bytes: manifest.GetUsage().GetBytes()}
+	m.droppedAt = manifest.DroppedAt
+	m.nextAutoID = manifest.NextAutoId
+	m.properties = manifest.Properties
+	m.reservedColumns = manifest.ReservedColumns
+	m.pinnedFragments = manifest.PinnedFragments
+	m.nextFragmentID = manifest.NextFragmentId
+	m.layout = layout.FromProtobuf(manifest.PathLayout)
 	return nil
 }
 
+// WriteManifestFile writes manifest with the paths of its files as they are.
 func WriteManifestFile(manifest *Manifest, output file.File) error {
+	return WriteManifestFileAt(manifest, "", output)
+}
+
+// WriteManifestFileAt writes manifest of the space at root. The paths of its files are written
+// relative to root, so that reading the manifest from a copy of the space resolves them in the
+// copy, unless a file is outside root or root is empty.
+func WriteManifestFileAt(manifest *Manifest, root string, output file.File) error {
 	protoManifest, err := manifest.ToProtobuf()
 	if err != nil {
 		return err
 	}
+	if root != "" {
+		relativize(protoManifest, root)
+	}
 
 	bytes, err := proto.Marshal(protoManifest)
 	if err != nil {
@@ -172,10 +383,17 @@ func (m *Manifest) RemoveBlobIfExist(name string) {
 			break
 		}
 	}
+	if idx == -1 {
+		return
+	}
 
 	m.blobs = append(m.blobs[0:idx], m.blobs[idx+1:]...)
 }
 
+func (m *Manifest) GetBlobs() []blob.Blob {
+	return m.blobs
+}
+
 func (m *Manifest) GetBlob(name string) (blob.Blob, bool) {
 	for _, b := range m.blobs {
 		if b.Name == name {
@@ -186,21 +404,97 @@ func (m *Manifest) GetBlob(name string) (blob.Blob, bool) {
 	return blob.Blob{}, false
 }
 
+// ParseFromFile reads the manifest at path. Manifests already parsed by the process are reused
+// without reading the file again where f implements fs.Stater.
 func ParseFromFile(f fs.Fs, path string) (*Manifest, error) {
+	info, err := fs.Stat(f, path)
+	if err != nil && !errors.Is(err, fs.ErrStatNotSupported) {
+		return nil, fmt.Errorf("parse from file: %w", err)
+	}
+	if err == nil {
+		if m, ok := parsed.get(path, info); ok {
+			return m, nil
+		}
+	}
+	m, err := parseFromFile(f, path)
+	if err != nil {
+		return nil, err
+	}
+	if info.ETag != "" {
+		parsed.add(path, info, m)
+	}
+	return m, nil
+}
+
+func parseFromFile(f fs.Fs, path string) (*Manifest, error) {
 	manifest := Init()
 	manifestProto := &manifest_proto.Manifest{}
 
 	buf, err := f.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("parse from file: %w", err)
+	}
 	err = proto.Unmarshal(buf, manifestProto)
 	if err != nil {
 		log.Error("Failed to unmarshal manifest proto", log.String("err", err.Error()))
 		return nil, fmt.Errorf("parse from file: %w", err)
 	}
+	if manifestProto.RelativePaths {
+		// the manifest is in the manifest directory of the root, see utils.GetManifestFilePath
+		resolve(manifestProto, filepath.Dir(filepath.Dir(path)))
+	}
 	manifest.FromProtobuf(manifestProto)
 
 	return manifest, nil
 }
 
+// relativize makes the paths of the files of manifest relative to root, if all are in root.
+func relativize(manifest *manifest_proto.Manifest, root string) {
+	paths := filePaths(manifest)
+	relative := make([]string, len(paths))
+	for i, path := range paths {
+		rel, err := filepath.Rel(root, *path)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			return
+		}
+		relative[i] = rel
+	}
+	for i, path := range paths {
+		*path = relative[i]
+	}
+	manifest.RelativePaths = true
+}
+
+// resolve joins the relative paths of the files of manifest to root.
+func resolve(manifest *manifest_proto.Manifest, root string) {
+	for _, path := range filePaths(manifest) {
+		*path = filepath.Join(root, *path)
+	}
+	manifest.RelativePaths = false
+}
+
+// filePaths returns the paths of the data, delete and blob files of manifest to be changed in
+// place.
+func filePaths(manifest *manifest_proto.Manifest) []*string {
+	var paths []*string
+	for _, fragments := range [][]*manifest_proto.Fragment{manifest.ScalarFragments, manifest.VectorFragments, manifest.DeleteFragments} {
+		for _, f := range fragments {
+			for i := range f.Files {
+				paths = append(paths, &f.Files[i])
+			}
+		}
+	}
+	for _, b := range manifest.Blobs {
+		if b.File != "" {
+			paths = append(paths, &b.File)
+		}
+		for _, c := range b.Chunks {
+			paths = append(paths, &c.File)
+		}
+	}
+	return paths
+}
+
 // TODO REMOVE BELOW CODE
 
 type DataFile struct {
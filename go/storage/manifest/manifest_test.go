@@ -1,10 +1,12 @@
 package manifest
 
 import (
+	"path/filepath"
 	"testing"
 
 	"github.com/apache/arrow/go/v12/arrow"
 	"github.com/milvus-io/milvus-storage/go/file/fragment"
+	"github.com/milvus-io/milvus-storage/go/io/fs"
 	"github.com/milvus-io/milvus-storage/go/storage/options/schema_option"
 	"github.com/milvus-io/milvus-storage/go/storage/schema"
 	"github.com/stretchr/testify/assert"
@@ -62,3 +64,90 @@ func TestManifest(t *testing.T) {
 	require.Equal(t, len(maniFest.GetDeleteFragments()), 1)
 	require.Equal(t, sc, maniFest.GetSchema())
 }
+
+func TestFragmentFileStats(t *testing.T) {
+	f := fragment.NewFragment(1)
+	f.AddFileWithStats("scalar1", fragment.FileStats{Rows: 2, Bytes: 100})
+	f.AddFile("scalar2")
+	require.Equal(t, fragment.UnknownFileStats, f.Stats())
+	f.SetFileStats("scalar2", fragment.FileStats{Rows: 3, Bytes: 50})
+	require.Equal(t, fragment.FileStats{Rows: 5, Bytes: 150}, f.Stats())
+
+	restored := fragment.FromProtobuf(f.ToProtobuf())
+	require.Equal(t, f.Files(), restored.Files())
+	require.Equal(t, f.FileStats(), restored.FileStats())
+
+	// fragments written before stats were recorded have unknown stats
+	old := f.ToProtobuf()
+	old.FileStats = nil
+	restored = fragment.FromProtobuf(old)
+	require.Equal(t, []fragment.FileStats{fragment.UnknownFileStats, fragment.UnknownFileStats}, restored.FileStats())
+}
+
+func TestValidateDataFragments(t *testing.T) {
+	m := NewManifest(nil)
+	scalar, vector := fragment.NewFragment(1), fragment.NewFragment(1)
+	scalar.AddFileWithStats("scalar1", fragment.FileStats{Rows: 2, Bytes: 10})
+	vector.AddFileWithStats("vector1", fragment.FileStats{Rows: 2, Bytes: 20})
+	m.AddDataFragment(DataFragment{Scalar: *scalar, Vector: *vector})
+	fragments, err := m.GetDataFragments()
+	require.NoError(t, err)
+	require.Len(t, fragments, 1)
+
+	mismatched := fragment.NewFragment(2)
+	mismatched.AddFileWithStats("vector2", fragment.FileStats{Rows: 3, Bytes: 20})
+	m.AddVectorFragment(*mismatched)
+	require.ErrorIs(t, m.ValidateDataFragments(), ErrFragmentMismatch)
+
+	scalar2 := fragment.NewFragment(2)
+	scalar2.AddFileWithStats("scalar2", fragment.FileStats{Rows: 2, Bytes: 10})
+	m.AddScalarFragment(*scalar2)
+	require.ErrorIs(t, m.ValidateDataFragments(), ErrFragmentMismatch)
+	_, err = m.GetDataFragments()
+	require.ErrorIs(t, err, ErrFragmentMismatch)
+}
+
+func TestParseFromFileCache(t *testing.T) {
+	as := arrow.NewSchema([]arrow.Field{
+		{Name: "pk_field", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "vs_field", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "vec_field", Type: &arrow.FixedSizeBinaryType{ByteWidth: 16}},
+	}, nil)
+	sc := schema.NewSchema(as, &schema_option.SchemaOptions{
+		PrimaryColumn: "pk_field",
+		VersionColumn: "vs_field",
+		VectorColumn:  "vec_field",
+	})
+	require.NoError(t, sc.Validate())
+
+	f := &fs.LocalFS{}
+	path := filepath.Join(t.TempDir(), "1.manifest")
+	write := func(m *Manifest) {
+		out, err := f.OpenFile(path)
+		require.NoError(t, err)
+		require.NoError(t, WriteManifestFile(m, out))
+		require.NoError(t, out.Close())
+	}
+	m := NewManifest(sc)
+	m.AddScalarFragment(*fragment.NewFragment(1))
+	write(m)
+
+	hits, misses := ParseCacheStats()
+	parsed1, err := ParseFromFile(f, path)
+	require.NoError(t, err)
+	parsed1.AddScalarFragment(*fragment.NewFragment(2))
+	parsed2, err := ParseFromFile(f, path)
+	require.NoError(t, err)
+	assert.Len(t, parsed2.GetScalarFragments(), 1, "modifying a parsed manifest must not change the cache")
+	newHits, newMisses := ParseCacheStats()
+	assert.Equal(t, hits+1, newHits)
+	assert.Equal(t, misses+1, newMisses)
+
+	// a rewritten file is parsed again
+	require.NoError(t, f.DeleteFile(path))
+	m.AddScalarFragment(*fragment.NewFragment(2))
+	write(m)
+	parsed3, err := ParseFromFile(f, path)
+	require.NoError(t, err)
+	assert.Len(t, parsed3.GetScalarFragments(), 2)
+}
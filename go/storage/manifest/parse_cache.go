@@ -0,0 +1,80 @@
+package manifest
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+
+	"github.com/milvus-io/milvus-storage/go/io/fs"
+)
+
+// ParseCacheEntries is the number of parsed manifests kept by the process, the least recently
+// used are evicted first.
+const ParseCacheEntries = 256
+
+// parseCache holds manifests parsed from files by path. Committed manifest files are never
+// rewritten in place, but a space dropped and created again reuses its paths, so an entry is
+// only used while the file still has the size and entity tag it was parsed with.
+type parseCache struct {
+	mu       sync.Mutex
+	capacity int
+	entries  map[string]*list.Element
+	order    *list.List
+	hits     int64
+	misses   int64
+}
+
+type parseCacheEntry struct {
+	path     string
+	info     fs.FileInfo
+	manifest *Manifest
+}
+
+var parsed = newParseCache(ParseCacheEntries)
+
+func newParseCache(capacity int) *parseCache {
+	return &parseCache{
+		capacity: capacity,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// get returns a copy of the manifest parsed from path if it was parsed from a file described
+// by info.
+func (c *parseCache) get(path string, info fs.FileInfo) (*Manifest, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[path]
+	if !ok || e.Value.(*parseCacheEntry).info != info {
+		atomic.AddInt64(&c.misses, 1)
+		return nil, false
+	}
+	atomic.AddInt64(&c.hits, 1)
+	c.order.MoveToFront(e)
+	return e.Value.(*parseCacheEntry).manifest.Copy(), true
+}
+
+// add keeps a copy of m, so that the caller may modify m.
+func (c *parseCache) add(path string, info fs.FileInfo, m *Manifest) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry := &parseCacheEntry{path: path, info: info, manifest: m.Copy()}
+	if e, ok := c.entries[path]; ok {
+		e.Value = entry
+		c.order.MoveToFront(e)
+		return
+	}
+	c.entries[path] = c.order.PushFront(entry)
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*parseCacheEntry).path)
+	}
+}
+
+// ParseCacheStats returns the number of manifest parses served from the cache and of those
+// that read the file.
+func ParseCacheStats() (hits int64, misses int64) {
+	return atomic.LoadInt64(&parsed.hits), atomic.LoadInt64(&parsed.misses)
+}
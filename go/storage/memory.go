@@ -0,0 +1,130 @@
+package storage
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/apache/arrow/go/v12/arrow/memory"
+	"github.com/milvus-io/milvus-storage/go/common/errors"
+	"github.com/milvus-io/milvus-storage/go/file/fragment"
+	"github.com/milvus-io/milvus-storage/go/storage/manifest"
+)
+
+// ErrMemoryLimitExceeded is returned by the reads and writes started while the spaces of a
+// Manager hold more memory than its limit, see Manager.SetMemoryLimit.
+var ErrMemoryLimitExceeded = errors.NewWithKind(errors.ErrThrottled, "memory limit exceeded")
+
+// MemoryUsage is the memory held by a space in bytes. Caches are estimated, readers and writers
+// are counted by the allocator of the arrow buffers they allocate.
+type MemoryUsage struct {
+	// Caches holds the loaded delete fragments, live bitmaps and, if they report their size,
+	// the blob and result caches.
+	Caches int64
+	// Readers holds the buffers decoded by the reads of the space and not released yet,
+	// including those of records retained by the caller.
+	Readers int64
+	// Writers holds the pages buffered by the writes in progress before they are flushed.
+	Writers int64
+}
+
+func (u MemoryUsage) Total() int64 {
+	return u.Caches + u.Readers + u.Writers
+}
+
+func (u MemoryUsage) add(other MemoryUsage) MemoryUsage {
+	return MemoryUsage{
+		Caches:  u.Caches + other.Caches,
+		Readers: u.Readers + other.Readers,
+		Writers: u.Writers + other.Writers,
+	}
+}
+
+// sizedCache is implemented by blob and result caches that can tell how many bytes they hold,
+// such as LRUBlobCache and LRUResultCache.
+type sizedCache interface {
+	Size() int64
+}
+
+// countingAllocator counts the bytes allocated from the default allocator and not freed yet.
+type countingAllocator struct {
+	allocated int64
+}
+
+var _ memory.Allocator = (*countingAllocator)(nil)
+
+func (a *countingAllocator) Allocate(size int) []byte {
+	atomic.AddInt64(&a.allocated, int64(size))
+	return memory.DefaultAllocator.Allocate(size)
+}
+
+func (a *countingAllocator) Reallocate(size int, b []byte) []byte {
+	atomic.AddInt64(&a.allocated, int64(size-len(b)))
+	return memory.DefaultAllocator.Reallocate(size, b)
+}
+
+func (a *countingAllocator) Free(b []byte) {
+	atomic.AddInt64(&a.allocated, -int64(len(b)))
+	memory.DefaultAllocator.Free(b)
+}
+
+func (a *countingAllocator) Allocated() int64 {
+	return atomic.LoadInt64(&a.allocated)
+}
+
+// MemoryUsage returns the memory currently held by the space.
+func (s *Space) MemoryUsage() MemoryUsage {
+	usage := MemoryUsage{
+		Readers: s.readMemory.Allocated(),
+		Writers: s.writeMemory.Allocated(),
+	}
+	s.deleteLock.Lock()
+	for _, deleteFragment := range s.deleteCache {
+		usage.Caches += deleteFragment.MemorySize()
+	}
+	s.deleteLock.Unlock()
+	s.bitmapLock.Lock()
+	for _, entry := range s.bitmapCache {
+		usage.Caches += entry.rows.MemorySize()
+	}
+	s.bitmapLock.Unlock()
+	if cache, ok := s.blobCache.(sizedCache); ok {
+		usage.Caches += cache.Size()
+	}
+	if cache, ok := s.resultCache.(sizedCache); ok {
+		usage.Caches += cache.Size()
+	}
+	return usage
+}
+
+// dropCaches releases the loaded delete fragments and live bitmaps but those of the fragments
+// pinned by m, if not nil, they are loaded again by the next reads. The blob and result caches
+// belong to the caller and are kept.
+func (s *Space) dropCaches(m *manifest.Manifest) {
+	s.deleteLock.Lock()
+	s.deleteCache = make(map[int64]fragment.DeleteFragment)
+	s.deleteLock.Unlock()
+	s.bitmapLock.Lock()
+	var pinned map[string]cachedBitmap
+	for path, entry := range s.bitmapCache {
+		if m != nil && m.IsPinned(entry.fragmentID) {
+			if pinned == nil {
+				pinned = make(map[string]cachedBitmap)
+			}
+			pinned[path] = entry
+		}
+	}
+	s.bitmapCache = pinned
+	s.bitmapLock.Unlock()
+}
+
+// checkMemory fails op with ErrMemoryLimitExceeded if the space belongs to a Manager whose
+// spaces hold more memory than its limit even without their caches.
+func (s *Space) checkMemory(op string) error {
+	if s.memoryLimit == nil {
+		return nil
+	}
+	if err := s.memoryLimit(); err != nil {
+		return fmt.Errorf("%s %s: %w", op, s.path, err)
+	}
+	return nil
+}
@@ -0,0 +1,303 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/milvus-io/milvus-storage/go/common/errors"
+	"github.com/milvus-io/milvus-storage/go/common/log"
+	"github.com/milvus-io/milvus-storage/go/file/blob"
+	"github.com/milvus-io/milvus-storage/go/file/fragment"
+	"github.com/milvus-io/milvus-storage/go/io/fs"
+	"github.com/milvus-io/milvus-storage/go/storage/manifest"
+	"github.com/milvus-io/milvus-storage/go/storage/options/option"
+)
+
+var ErrFileOutsideSpace = errors.New("file outside space")
+
+// MirrorStatus describes how far the mirror of a space lags behind it.
+type MirrorStatus struct {
+	// ReplicatedVersion is the latest version present on the mirror, -1 if none was
+	// replicated since the space was opened.
+	ReplicatedVersion int64
+	// ReplicatedAt is when ReplicatedVersion was replicated.
+	ReplicatedAt time.Time
+	// Lag is the number of committed versions not replicated yet.
+	Lag int
+	// LastError is the error of the last replication attempt, nil once it succeeded.
+	LastError error
+}
+
+// mirror replicates committed versions to a second space in the background. Versions are
+// replicated in commit order and the files of a version are copied before its manifest, so
+// that every manifest on the mirror only references files already present there. Drops and
+// restores are not replicated.
+type mirror struct {
+	src           fs.Fs
+	srcPath       string
+	dst           fs.Fs
+	dstPath       string
+	retryInterval time.Duration
+
+	mu           sync.Mutex
+	pending      []*manifest.Manifest
+	done         chan struct{}
+	stopped      chan struct{}
+	replicated   int64
+	replicatedAt time.Time
+	lastErr      error
+}
+
+func newMirror(src fs.Fs, srcPath string, options *option.MirrorOptions) (*mirror, error) {
+	dst, dstPath, err := buildFs(options.URI)
+	if err != nil {
+		return nil, fmt.Errorf("open mirror %s: %w", options.URI, err)
+	}
+	retryInterval := options.RetryInterval
+	if retryInterval <= 0 {
+		retryInterval = option.DefaultMirrorRetryInterval
+	}
+	return &mirror{
+		src:           src,
+		srcPath:       srcPath,
+		dst:           dst,
+		dstPath:       dstPath,
+		retryInterval: retryInterval,
+		stopped:       make(chan struct{}),
+		replicated:    -1,
+	}, nil
+}
+
+// enqueue schedules the replication of a committed manifest, which must not be modified
+// afterwards.
+func (m *mirror) enqueue(committed *manifest.Manifest) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pending = append(m.pending, committed)
+	if m.done == nil && !m.isStopped() {
+		m.done = make(chan struct{})
+		go m.run(m.done)
+	}
+}
+
+// run replicates the pending versions until there are none left or the mirror is stopped,
+// failed versions are retried until they succeed.
+func (m *mirror) run(done chan struct{}) {
+	for {
+		m.mu.Lock()
+		if len(m.pending) == 0 || m.isStopped() {
+			m.done = nil
+			close(done)
+			m.mu.Unlock()
+			return
+		}
+		next := m.pending[0]
+		m.mu.Unlock()
+
+		err := m.replicate(next)
+		m.mu.Lock()
+		m.lastErr = err
+		if err == nil {
+			m.pending = m.pending[1:]
+			m.replicated = next.Version()
+			m.replicatedAt = time.Now()
+		}
+		m.mu.Unlock()
+		if err != nil {
+			log.Warn("replicate version failed", log.String("path", m.srcPath), log.Int64("version", next.Version()), log.String("err", err.Error()))
+			select {
+			case <-time.After(m.retryInterval):
+			case <-m.stopped:
+			}
+		}
+	}
+}
+
+func (m *mirror) isStopped() bool {
+	select {
+	case <-m.stopped:
+		return true
+	default:
+		return false
+	}
+}
+
+// stop stops replicating once the version being copied, if any, is done and closes the file
+// system of the mirror. The versions not replicated yet are replicated by the next process
+// opening the space with the mirror.
+func (m *mirror) stop() error {
+	m.mu.Lock()
+	close(m.stopped)
+	done := m.done
+	m.mu.Unlock()
+	if done != nil {
+		<-done
+	}
+	return m.dst.Close()
+}
+
+// wait blocks until every pending version is replicated or ctx is done.
+func (m *mirror) wait(ctx context.Context) error {
+	m.mu.Lock()
+	done := m.done
+	m.mu.Unlock()
+	if done == nil {
+		return nil
+	}
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (m *mirror) status() MirrorStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return MirrorStatus{
+		ReplicatedVersion: m.replicated,
+		ReplicatedAt:      m.replicatedAt,
+		Lag:               len(m.pending),
+		LastError:         m.lastErr,
+	}
+}
+
+func (m *mirror) replicate(committed *manifest.Manifest) error {
+	remapped, err := remapManifest(committed, m.remap)
+	if err != nil {
+		return err
+	}
+	for _, file := range manifestFiles(committed) {
+		if err = m.copyFile(file); err != nil {
+			return err
+		}
+	}
+	// the version may already be on the mirror if a previous attempt failed after saving it
+	if err = safeSaveManifest(m.dst, m.dstPath, remapped, false); err != nil && !errors.Is(err, ErrManifestConflict) {
+		return err
+	}
+	return nil
+}
+
+// manifestFiles returns the data, delete and blob files referenced by m.
+func manifestFiles(m *manifest.Manifest) []string {
+	files := fragment.ToFilesVector(m.GetScalarFragments())
+	files = append(files, fragment.ToFilesVector(m.GetVectorFragments())...)
+	files = append(files, fragment.ToFilesVector(m.GetDeleteFragments())...)
+	for _, b := range m.GetBlobs() {
+		files = append(files, b.Files()...)
+	}
+	return files
+}
+
+// copyFile copies a file of the space unless the mirror already has it, files are never
+// modified once committed.
+func (m *mirror) copyFile(file string) error {
+	target, err := m.remap(file)
+	if err != nil {
+		return err
+	}
+	exist, err := m.dst.Exist(target)
+	if err != nil {
+		return fmt.Errorf("replicate %s: %w", file, err)
+	}
+	if exist {
+		return nil
+	}
+	content, err := m.src.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("replicate %s: %w", file, err)
+	}
+	return fs.WriteFile(m.dst, target, content)
+}
+
+// remap returns the path of a file of the space on the mirror.
+func (m *mirror) remap(file string) (string, error) {
+	rel, err := filepath.Rel(m.srcPath, file)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return "", fmt.Errorf("replicate %s: %w", file, ErrFileOutsideSpace)
+	}
+	return filepath.Join(m.dstPath, rel), nil
+}
+
+// remapManifest returns a copy of m whose files are renamed by remap.
+func remapManifest(m *manifest.Manifest, remap func(string) (string, error)) (*manifest.Manifest, error) {
+	remapFragments := func(fragments fragment.FragmentVector) (fragment.FragmentVector, error) {
+		remapped := make(fragment.FragmentVector, 0, len(fragments))
+		for _, f := range fragments {
+			copied := fragment.NewFragment(f.FragmentId())
+			for i, file := range f.Files() {
+				target, err := remap(file)
+				if err != nil {
+					return nil, err
+				}
+				copied.AddFileWithStats(target, f.FileStats()[i])
+			}
+			remapped = append(remapped, *copied)
+		}
+		return remapped, nil
+	}
+
+	copied := m.Copy()
+	scalar, err := remapFragments(m.GetScalarFragments())
+	if err != nil {
+		return nil, err
+	}
+	vector, err := remapFragments(m.GetVectorFragments())
+	if err != nil {
+		return nil, err
+	}
+	deletes, err := remapFragments(m.GetDeleteFragments())
+	if err != nil {
+		return nil, err
+	}
+	copied.SetScalarFragments(scalar)
+	copied.SetVectorFragments(vector)
+	copied.SetDeleteFragments(deletes)
+	for _, b := range m.GetBlobs() {
+		if !b.Chunked() {
+			target, err := remap(b.File)
+			if err != nil {
+				return nil, err
+			}
+			b.File = target
+		}
+		chunks := make([]blob.Chunk, 0, len(b.Chunks))
+		for _, c := range b.Chunks {
+			target, err := remap(c.File)
+			if err != nil {
+				return nil, err
+			}
+			c.File = target
+			chunks = append(chunks, c)
+		}
+		if b.Chunked() {
+			b.Chunks = chunks
+		}
+		copied.RemoveBlobIfExist(b.Name)
+		copied.AddBlob(b)
+	}
+	return copied, nil
+}
+
+// MirrorStatus returns the replication status of the mirror, false if the space has none.
+func (s *Space) MirrorStatus() (MirrorStatus, bool) {
+	if s.mirror == nil {
+		return MirrorStatus{}, false
+	}
+	return s.mirror.status(), true
+}
+
+// WaitMirror blocks until every version committed so far is replicated to the mirror or ctx
+// is done. It returns immediately if the space has no mirror.
+func (s *Space) WaitMirror(ctx context.Context) error {
+	if s.mirror == nil {
+		return nil
+	}
+	return s.mirror.wait(ctx)
+}
@@ -2,16 +2,376 @@ package option
 
 import (
 	"math"
+	"sort"
+	"time"
 
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/memory"
+	"github.com/milvus-io/milvus-storage/go/file/blob"
+	"github.com/milvus-io/milvus-storage/go/file/layout"
 	"github.com/milvus-io/milvus-storage/go/filter"
+	"github.com/milvus-io/milvus-storage/go/io/fs/file"
+	"github.com/milvus-io/milvus-storage/go/storage/auth"
 	"github.com/milvus-io/milvus-storage/go/storage/schema"
 )
 
 type Options struct {
 	Schema  *schema.Schema
 	Version int64
+	// WriterLease enables a writer lease that must be held to commit, nil disables it.
+	WriterLease *LeaseOptions
+	// Quota limits the data a space can hold, nil means unlimited.
+	Quota *QuotaOptions
+	// Authorizer is consulted before every operation, nil allows everything.
+	Authorizer auth.Authorizer
+	// Audit records every commit, nil disables auditing.
+	Audit *AuditOptions
+	// Masking redacts columns on read depending on the caller, nil returns data as stored.
+	Masking MaskingPolicy
+	// RowFilter restricts the rows visible to the caller, nil returns all rows.
+	RowFilter RowFilterPolicy
+	// Mirror replicates every committed version to a second location, nil disables it.
+	Mirror *MirrorOptions
+	// Replica opens the space as a read-only replica, e.g. of a mirror, nil opens it for
+	// reads and writes.
+	Replica *ReplicaOptions
+	// BlobCodec compresses blobs written by WriteBlob, blobs that do not shrink are stored
+	// uncompressed. Chunked blobs are never compressed.
+	BlobCodec blob.Codec
+	// BlobCache caches the content of blobs read, nil reads them from storage every time.
+	BlobCache BlobCache
+	// ResultCache caches the records of reads, repeated reads of an unchanged version with the
+	// same columns and filters return them without reading the files. nil disables it.
+	ResultCache ResultCache
+	// WriteHooks transform the records of every write in order before they are encoded.
+	WriteHooks []WriteHook
+	// LiveBitmaps keeps the deleted rows of every scalar fragment in a sidecar file, refreshed
+	// on commit, so reads of scalar columns skip deleted rows by offset instead of matching
+	// every row against the delete fragments.
+	LiveBitmaps bool
+	// Durability controls whether committed files are flushed to stable storage before the
+	// commit returns.
+	Durability Durability
+	// SlowLog logs the operations that take longer than its thresholds, nil disables it.
+	SlowLog *SlowLogOptions
+	// CircuitBreaker fails the calls to the file system fast while it keeps failing, nil
+	// sends every call to it.
+	CircuitBreaker *CircuitBreakerOptions
+	// StagingDir is the staging directory of the writes that do not set their own, see
+	// WriteOptions.StagingDir. Open removes the files left in it by a crashed process, so it
+	// must not be shared with another process writing the same space.
+	StagingDir string
+	// OnVersionChange is called by Refresh and Watch with the changes of the versions they
+	// load, nil disables it.
+	OnVersionChange func(VersionChange)
+	// CommitQueue commits the writes completed at the same time by goroutines of the process
+	// in a single version, nil commits a version per write.
+	CommitQueue *CommitQueueOptions
+	// S3 sets the requester pays and expected bucket owner of the requests of the S3 backend,
+	// and the tags and metadata of the objects it writes, nil sends none. Other backends
+	// ignore it.
+	S3 *file.ObjectOptions
+	// Naming names the data and delete files written by the space, nil names them with
+	// random UUIDs.
+	Naming *NamingOptions
+	// Layout places the data and delete files of a space created by Open in subdirectories of
+	// the data directories. Opening an existing space ignores it, the layout recorded in its
+	// manifest is used, see Space.SetLayout.
+	Layout layout.Scheme
+	// ConfineToRoot fails every file system call of the space with a path outside the space
+	// with fs.ErrPathOutsideRoot, and the opening or refreshing of versions whose manifest
+	// references files outside it, e.g. to isolate the spaces of tenants sharing a bucket from
+	// corrupted or forged manifests.
+	ConfineToRoot bool
+	// TxnMarkerDir is the directory of the markers of the transactions writing the space, see
+	// storage.NewTransaction, as a path in the file system of the space, e.g. in its bucket.
+	// Reads of spaces holding fragments of transactions fail without it.
+	TxnMarkerDir string
 }
 
+// NamingOptions names the files written by a space. Names only need to be unique, readers
+// find the files through the manifest.
+type NamingOptions struct {
+	Strategy NamingStrategy
+	// Prefix starts the names of the files, e.g. the id of the writer so that the files of
+	// every writer can be told apart and object stores spread their requests over more
+	// partitions.
+	Prefix string
+}
+
+type NamingStrategy int8
+
+const (
+	// NamingRandom names the files with random UUIDs.
+	NamingRandom NamingStrategy = iota
+	// NamingTimeSorted names the files with UUIDv7, which start with the time they were
+	// written, so that they sort by it. See utils.TimeOfFile.
+	NamingTimeSorted
+)
+
+// CommitQueueOptions configures the commit queue of a space. Writes are encoded concurrently,
+// then wait for the commit in progress, if any, and the next commit adds the data fragments
+// of all the writes waiting. The version is audited as a single write of the caller
+// committing it.
+type CommitQueueOptions struct {
+	// MaxWrites is the maximum number of writes committed in a version, zero means no limit.
+	MaxWrites int
+	// MaxDelay delays the commits by up to that long to add the writes completed meanwhile,
+	// fewer manifest files are written under high ingest rates at the cost of the latency of
+	// the writes. The commit does not wait once MaxWrites writes are waiting. Zero commits the
+	// writes waiting right away.
+	MaxDelay time.Duration
+}
+
+// VersionChange describes the versions committed by other processes that a space loaded, see
+// storage.Space.Refresh. A fragment rewritten with the same id, e.g. by compaction purging
+// deletes, is both removed and added.
+type VersionChange struct {
+	// From is the version the space had loaded, To the version it loaded, equal if there is
+	// no newer version.
+	From int64
+	To   int64
+	// AddedFragments and RemovedFragments are the ids of the data fragments added and removed.
+	AddedFragments   []int64
+	RemovedFragments []int64
+	// AddedDeleteFragments and RemovedDeleteFragments are the ids of the delete fragments
+	// added and removed.
+	AddedDeleteFragments   []int64
+	RemovedDeleteFragments []int64
+	// RemovedBlobs are the names of the blobs removed or replaced.
+	RemovedBlobs []string
+}
+
+// ConsistencyToken identifies a version committed to a space, see storage.Space.ConsistencyToken.
+// It can be passed to another process to read at least that version, see ReadOptions.AtLeast.
+type ConsistencyToken struct {
+	Version int64
+	// ETag is the ETag of the manifest file of Version, empty if the file system does not tell.
+	// It tells apart versions with the same number of spaces dropped and created again.
+	ETag string
+}
+
+// Invalidator is implemented by caches whose entries can be dropped, e.g. once the data they
+// hold is no longer referenced by the latest version of a space.
+type Invalidator interface {
+	// Invalidate drops the entries whose key match reports, and returns how many it dropped.
+	Invalidate(match func(key string) bool) int
+}
+
+// RetryOptions configures how the calls of an operation to the file system are retried, e.g.
+// to fail interactive reads fast but let a bulk compaction retry for minutes. Only failures of
+// the backend, e.g. throttling or a lost connection, are retried, not its answers like a
+// missing file. Writes to a file already started are never retried. Commits are not governed
+// by it.
+type RetryOptions struct {
+	// Timeout bounds the duration of the operation from its start, the calls started after it
+	// fail with an error wrapping context.DeadlineExceeded. Zero means no limit.
+	Timeout time.Duration
+	// MaxAttempts is how many times a failing call is sent, zero or one sends it once.
+	MaxAttempts int
+	// Backoff is the wait before the first retry, doubled before every following one.
+	Backoff time.Duration
+}
+
+// CircuitBreakerOptions configures the circuit breaker of a file system. The breaker opens
+// after Failures consecutive failed calls, calls then fail with an error of kind
+// errors.ErrUnavailable without reaching the backend. After OpenTimeout a single call is let
+// through as a probe, the breaker closes if it succeeds and opens again otherwise. Missing
+// files, denied permissions and conflicts are answers of the backend, not failures.
+type CircuitBreakerOptions struct {
+	Failures    int
+	OpenTimeout time.Duration
+}
+
+const (
+	DefaultCircuitBreakerFailures    = 5
+	DefaultCircuitBreakerOpenTimeout = 10 * time.Second
+)
+
+// SlowLogOptions holds the duration above which each kind of operation is logged, once when
+// it finishes, with its size. Zero disables logging that kind of operation.
+type SlowLogOptions struct {
+	// Read is measured from Read until the reader is released.
+	Read time.Duration
+	// Write is measured from Write until it returns, its commit included.
+	Write time.Duration
+	// Commit is measured from the start of the commit of a version, including the wait for
+	// other commits of the space, until it is visible.
+	Commit time.Duration
+	// Fs applies to every call to the file system, reads and writes of open files excepted.
+	Fs time.Duration
+}
+
+type Durability int8
+
+const (
+	// DurabilityDefault leaves flushing to the file system, a crash of the machine may lose
+	// commits that were acknowledged, or leave a manifest referencing incomplete files.
+	DurabilityDefault Durability = iota
+	// DurabilitySync fsyncs the new files of a commit and its manifest, and the directories
+	// holding them after they are created or renamed, before the commit returns. Object
+	// stores are durable once a write completes and are not affected.
+	DurabilitySync
+)
+
+// BlobCache caches verified and decompressed blob content by file. Files are never modified
+// once written, a rewritten blob gets a new file, so entries never become stale. A cache can
+// be shared by several spaces and must be safe for concurrent use. Caches implementing
+// Invalidator drop the files of the blobs removed by the versions a space refreshes to.
+type BlobCache interface {
+	Get(file string) ([]byte, bool)
+	Add(file string, content []byte)
+}
+
+// WriteHook transforms a record written to the space, e.g. to fill a column or normalize
+// vectors. rec has the schema of the space, generated primary keys included, and the returned
+// record must keep it. The write releases the returned record, a hook may return rec itself
+// after retaining it. An error fails the write.
+type WriteHook func(rec arrow.Record) (arrow.Record, error)
+
+// ResultCache caches the records returned by reads by key, see storage.LRUResultCache. The key
+// changes with the version read, so entries never become stale. A cache can be shared by
+// several spaces and must be safe for concurrent use. Caches implementing Invalidator drop the
+// results of older versions of a space once it refreshes to a newer one.
+type ResultCache interface {
+	// Get returns the records of key retained for the caller, which must release them.
+	Get(key string) ([]arrow.Record, bool)
+	// Add retains records and caches them under key.
+	Add(key string, records []arrow.Record)
+	// Fits reports whether records of size bytes can be cached, reads stop collecting their
+	// records once they cannot.
+	Fits(size int64) bool
+}
+
+// ReplicaOptions configures spaces opened from a location that is replicated asynchronously.
+// The replica opens the newest version whose files are all present, versions still being
+// replicated are skipped.
+type ReplicaOptions struct {
+	// PrimaryURI is the location of the replicated space, used to measure the lag. Empty
+	// measures the lag against the newest manifest of the replica, replicated fully or not.
+	PrimaryURI string
+	// MaxLag is the number of versions the replica may be behind, opening a replica that is
+	// further behind fails. Zero means unbounded.
+	MaxLag int64
+}
+
+// MirrorOptions configures the asynchronous replication of a space, e.g. to a bucket in
+// another region for disaster recovery. The mirror is a regular space that can be opened
+// with its URI.
+type MirrorOptions struct {
+	// URI is the location of the mirror space, it may use a different fs than the space.
+	URI string
+	// RetryInterval is how long to wait before retrying a failed replication.
+	RetryInterval time.Duration
+}
+
+const DefaultMirrorRetryInterval = time.Second
+
+// BlobWriterOptions configures how a chunked blob is written, see storage.BlobWriter.
+type BlobWriterOptions struct {
+	// ChunkSize is the size of every chunk but the last, a resumed upload must use the size
+	// it was started with.
+	ChunkSize int64
+	// Parallelism is the number of chunks uploaded at the same time.
+	Parallelism int
+	Metadata    map[string]string
+	// Replace allows replacing an existing blob of the same name.
+	Replace bool
+}
+
+const (
+	DefaultBlobChunkSize   = 64 << 20
+	DefaultBlobParallelism = 4
+)
+
+func NewBlobWriterOptions() *BlobWriterOptions {
+	return &BlobWriterOptions{
+		ChunkSize:   DefaultBlobChunkSize,
+		Parallelism: DefaultBlobParallelism,
+	}
+}
+
+// RowFilterPolicy returns the filters added to every read by identity, e.g. tenant_id == X.
+// The filters are combined with the filters of the read, so callers can narrow but never
+// widen the visible rows. An error rejects the read. Filter columns are added to the read
+// columns if missing.
+type RowFilterPolicy func(identity auth.Identity) ([]filter.Filter, error)
+
+type MaskType int8
+
+const (
+	// MaskNull replaces every value with null.
+	MaskNull MaskType = iota
+	// MaskHash replaces string and binary values with their salted sha256, hex encoded for
+	// strings, so that equal values stay equal.
+	MaskHash
+	// MaskPartial keeps the first KeepPrefix and last KeepSuffix characters of strings and
+	// replaces the others with '*'.
+	MaskPartial
+)
+
+type MaskRule struct {
+	Type       MaskType
+	Salt       []byte
+	KeepPrefix int
+	KeepSuffix int
+}
+
+// MaskingPolicy returns the rules, keyed by column name, applied to the columns read by
+// identity. Columns without a rule are returned as stored. Filters on masked columns are
+// rejected since they would reveal the stored values.
+type MaskingPolicy func(identity auth.Identity) map[string]MaskRule
+
+// AuditRecord describes a committed operation.
+type AuditRecord struct {
+	Time      time.Time      `json:"time"`
+	User      string         `json:"user"`
+	Path      string         `json:"path"`
+	Version   int64          `json:"version"`
+	Operation auth.Operation `json:"operation"`
+	Rows      int64          `json:"rows"`
+	Files     []string       `json:"files"`
+}
+
+// AuditSink receives a record after every commit. A failing sink does not undo the commit,
+// which is already visible, the error is logged.
+type AuditSink interface {
+	Record(record *AuditRecord) error
+}
+
+type AuditOptions struct {
+	// Sink replaces the default sink, which writes one json file per version to the audit
+	// directory of the space. Use storage.NewLogAuditSink to write to the logger instead.
+	Sink AuditSink
+}
+
+// QuotaOptions limits the usage tracked in the manifest. Zero means no limit.
+type QuotaOptions struct {
+	MaxRows  int64
+	MaxBytes int64
+}
+
+// Lease guards the commits of a space against writers in other processes.
+type Lease interface {
+	// Acquire takes the lease, or confirms it is still held by us. It fails if another
+	// writer holds an unexpired lease.
+	Acquire() error
+	// Release gives up the lease so that another writer can take it immediately.
+	Release() error
+}
+
+type LeaseOptions struct {
+	// Owner identifies this writer, a unique id is generated if empty.
+	Owner string
+	// TTL is how long the lease stays valid without a heartbeat. Another writer can take
+	// over the lease once it expires, e.g. after this process crashed.
+	TTL time.Duration
+	// Lease replaces the default heartbeat file lease, e.g. with one backed by a lock service.
+	Lease Lease
+}
+
+const DefaultLeaseTTL = 30 * time.Second
+
 func NewOptions(schema *schema.Schema, version int64) *Options {
 	return &Options{
 		Schema:  schema,
@@ -23,10 +383,69 @@ func Init() *Options {
 	return &Options{}
 }
 
+// Progress describes how far a long running operation has advanced.
+type Progress struct {
+	// Rows is the number of rows processed.
+	Rows int64
+	// Bytes is the number of file bytes written or read.
+	Bytes int64
+	// Files is the number of files completed.
+	Files int64
+	// FilesSkipped is the number of files a read did not open because they hold none of
+	// the columns it needs, e.g. the vector files of a read of scalar columns.
+	FilesSkipped int64
+}
+
+// ProgressFunc is called with the accumulated progress after every record batch and once
+// more when the operation finishes. It runs on the calling goroutine and must not block.
+type ProgressFunc func(Progress)
+
 type WriteOptions struct {
 	MaxRecordPerFile int64
+	// MaxRowGroupRows bounds the rows of the row groups of the scalar files written, so that
+	// selective reads skip the row groups whose statistics cannot match, e.g. 8192 for point
+	// lookups. Zero keeps the parquet default. Vector files are never read selectively and
+	// keep the default.
+	MaxRowGroupRows int64
+	Progress        ProgressFunc
+	// StagingDir is a local directory data files are encoded in before they are uploaded to
+	// the space, instead of being buffered in memory until they are complete by file systems
+	// such as S3. Empty uses the staging directory of the space, if any.
+	StagingDir string
+	// MemoryBudget bounds the bytes of the batches encoded at once: larger batches are written
+	// in slices, and the files they go to are encoded on disk, in the staging directory or else
+	// the temporary directory of the system, instead of in memory. Zero writes batches whole.
+	MemoryBudget int64
+	// RejectNonFinite fails writes of float vectors holding NaN or infinite values, which
+	// distance computations cannot handle.
+	RejectNonFinite bool
+	// Duplicates decides what happens to rows of the write sharing a primary key. Rows are
+	// only compared with the other rows of the same write, not with the rows of the space.
+	Duplicates DuplicatePolicy
+	// AutoVersion fills the version column of every row written with VersionTimestamp, or with
+	// the version committed by the write if it is zero. The records may omit the version
+	// column. A write filled with its version fails with storage.ErrAutoVersionConflict if
+	// another write commits that version first, concurrent writers should supply a timestamp.
+	AutoVersion      bool
+	VersionTimestamp int64
+	// Retry overrides how the calls of the write to the file system are retried, nil sends
+	// them once without a time limit.
+	Retry *RetryOptions
 }
 
+type DuplicatePolicy int8
+
+const (
+	// DuplicatesAllow writes every row, duplicated keys included.
+	DuplicatesAllow DuplicatePolicy = iota
+	// DuplicatesReject fails the write at the first row whose primary key was already written
+	// by it, with a storage.DuplicateKeyError.
+	DuplicatesReject
+	// DuplicatesLastWins only writes the last row of every primary key. The write is buffered
+	// in memory until the whole stream is read.
+	DuplicatesLastWins
+)
+
 var DefaultWriteOptions = WriteOptions{
 	MaxRecordPerFile: 1024,
 }
@@ -37,6 +456,166 @@ func NewWriteOption() *WriteOptions {
 	}
 }
 
+// VacuumOptions controls what Vacuum removes.
+type VacuumOptions struct {
+	// DropGracePeriod is how long a soft dropped space can be restored before it is purged.
+	DropGracePeriod time.Duration
+}
+
+const DefaultDropGracePeriod = 7 * 24 * time.Hour
+
+func NewVacuumOptions() *VacuumOptions {
+	return &VacuumOptions{
+		DropGracePeriod: DefaultDropGracePeriod,
+	}
+}
+
+// TierOptions selects the fragments MigrateCold moves to the cold tier.
+type TierOptions struct {
+	// OlderThan moves the fragments whose files were all written at least OlderThan ago.
+	OlderThan time.Duration
+}
+
+// CompactOptions controls what Compact rewrites.
+type CompactOptions struct {
+	// PurgeDeletes physically removes the rows matched by delete fragments and drops the
+	// delete fragments that were applied.
+	PurgeDeletes bool
+	// Policy selects the files merged after deletes are purged, nil merges nothing.
+	Policy CompactionPolicy
+	// ClusterColumns rewrites all files with the rows sorted in Z-order over these scalar
+	// columns, so that filters on any of them can skip row groups. Policy is not used when
+	// clustering since every file is rewritten.
+	ClusterColumns []string
+	// MaxRecordPerFile limits the rows of the files written by clustering.
+	MaxRecordPerFile int64
+	// PurgeDroppedColumns rewrites the files still holding columns dropped from the schema,
+	// which other files keep until they are rewritten, e.g. by merging.
+	PurgeDroppedColumns bool
+	// Retry overrides how the calls of the compaction to the file system are retried, nil
+	// sends them once without a time limit.
+	Retry *RetryOptions
+}
+
+// CompactionFile is a scalar file and the vector file holding the same rows.
+type CompactionFile struct {
+	// Fragment is the id of the fragment the files belong to.
+	Fragment int64
+	// Index is the position of the files in the fragment.
+	Index int
+	// Bytes is the size of the scalar and vector file together.
+	Bytes int64
+	// Rows is the number of rows in the files, -1 if they were written before row counts
+	// were recorded.
+	Rows int64
+}
+
+// CompactionPolicy chooses which files Compact merges.
+type CompactionPolicy interface {
+	// Plan returns groups of files, the files of each group are merged into a single new
+	// file. Files not in any group are kept as they are, and groups of one file are ignored.
+	Plan(files []CompactionFile) [][]CompactionFile
+}
+
+// BinPackingPolicy merges files smaller than SmallFileBytes into files of up to
+// TargetFileBytes, packing the largest files first.
+type BinPackingPolicy struct {
+	SmallFileBytes  int64
+	TargetFileBytes int64
+}
+
+const (
+	DefaultSmallFileBytes  = 16 << 20
+	DefaultTargetFileBytes = 128 << 20
+)
+
+func NewBinPackingPolicy() *BinPackingPolicy {
+	return &BinPackingPolicy{
+		SmallFileBytes:  DefaultSmallFileBytes,
+		TargetFileBytes: DefaultTargetFileBytes,
+	}
+}
+
+func (p *BinPackingPolicy) Plan(files []CompactionFile) [][]CompactionFile {
+	var small []CompactionFile
+	for _, f := range files {
+		if f.Bytes < p.SmallFileBytes {
+			small = append(small, f)
+		}
+	}
+	sort.SliceStable(small, func(i, j int) bool { return small[i].Bytes > small[j].Bytes })
+
+	// first fit decreasing
+	var (
+		bins  [][]CompactionFile
+		sizes []int64
+	)
+	for _, f := range small {
+		placed := false
+		for i := range bins {
+			if sizes[i]+f.Bytes <= p.TargetFileBytes {
+				bins[i] = append(bins[i], f)
+				sizes[i] += f.Bytes
+				placed = true
+				break
+			}
+		}
+		if !placed {
+			bins = append(bins, []CompactionFile{f})
+			sizes = append(sizes, f.Bytes)
+		}
+	}
+
+	groups := make([][]CompactionFile, 0, len(bins))
+	for _, bin := range bins {
+		if len(bin) > 1 {
+			groups = append(groups, bin)
+		}
+	}
+	return groups
+}
+
+func NewCompactOptions() *CompactOptions {
+	return &CompactOptions{
+		PurgeDeletes:     true,
+		MaxRecordPerFile: DefaultWriteOptions.MaxRecordPerFile,
+	}
+}
+
+type AggFunc int8
+
+const (
+	// AggCount counts the rows, or the non null values of Column when it is set.
+	AggCount AggFunc = iota
+	// AggMin and AggMax return the smallest and largest non null value of Column.
+	AggMin
+	AggMax
+	// AggSum adds the non null values of a numeric Column.
+	AggSum
+)
+
+// AggSpec is an aggregate computed over the rows of a space.
+type AggSpec struct {
+	Func   AggFunc
+	Column string
+}
+
+// AnalyzeOptions controls how Analyze computes column statistics.
+type AnalyzeOptions struct {
+	// SampleRows bounds the rows the distinct values and histograms are computed from, rows
+	// are then sampled evenly over the space and the number of distinct values is estimated.
+	// Zero uses every row.
+	SampleRows int64
+	// Buckets is the number of buckets of the histograms of numeric columns.
+	Buckets int
+}
+
+const DefaultHistogramBuckets = 16
+
+func NewAnalyzeOptions() *AnalyzeOptions {
+	return &AnalyzeOptions{Buckets: DefaultHistogramBuckets}
+}
+
 type FsType int8
 
 const (
@@ -55,12 +634,67 @@ type FilterSet []filter.Filter
 
 var version int64 = math.MaxInt64
 
+type OrderType int8
+
+const (
+	// OrderUnspecified returns rows in any order, which may change between reads.
+	OrderUnspecified OrderType = iota
+	// OrderFragment returns rows in the order of the fragments in the manifest, then of the
+	// files in each fragment and of the rows in each file. It is the order rows were written
+	// in until a compaction moves the rewritten rows to a new fragment at the end.
+	OrderFragment
+	// OrderKey returns rows sorted in ascending order of OrderBy.Column, nulls first. Rows
+	// with equal values are in fragment order. Every file is sorted in memory and the files
+	// are merged.
+	OrderKey
+)
+
+// OrderBy is the order of the rows returned by a read.
+type OrderBy struct {
+	Type   OrderType
+	Column string
+}
+
+// ReadHook transforms every record returned by a read, e.g. to decrypt a column or to add a
+// column derived from others. Reads with hooks are not cached by the result cache.
+type ReadHook interface {
+	// Schema returns the schema of the records returned for records of schema in, it is
+	// called once when the read starts.
+	Schema(in *arrow.Schema) (*arrow.Schema, error)
+	// Transform returns rec transformed, which the read releases. It may return rec itself
+	// after retaining it. An error fails the read.
+	Transform(rec arrow.Record) (arrow.Record, error)
+}
+
 type ReadOptions struct {
 	//Filters map[string]filter.Filter
 	Filters   map[string]filter.Filter
 	FiltersV2 FilterSet
-	Columns   []string
+	// ExpressionFilters are evaluated on the rows after they are read, see AddExpressionFilter.
+	ExpressionFilters []*filter.ExpressionFilter
+	Columns           []string
+	Progress          ProgressFunc
+	// IncludeDeleted returns deleted rows too, with a boolean constant.DeletedFieldName column
+	// telling whether each row is deleted.
+	IncludeDeleted bool
+	OrderBy        OrderBy
+	// BatchSize is the number of rows of every record returned by the read but the last,
+	// which may have fewer. Zero returns the records as they are read.
+	BatchSize int64
+	// Parallelism is the number of row groups of a file decoded at the same time, records are
+	// still returned in the order of the file. Zero or one decodes them one by one.
+	Parallelism int
+	// Hooks transform the records returned by the read in order, see AddHook.
+	Hooks []ReadHook
+	// Retry overrides how the calls of the read to the file system are retried, nil sends
+	// them once without a time limit.
+	Retry *RetryOptions
+	// AtLeast makes the read fail with storage.ErrStaleReplica if the space read has not loaded
+	// the version of the token yet, e.g. to read the writes of another process.
+	AtLeast   *ConsistencyToken
 	version   int64
+	castTo    *arrow.Schema
+	allocator memory.Allocator
 }
 
 func NewReadOptions() *ReadOptions {
@@ -72,9 +706,55 @@ func NewReadOptions() *ReadOptions {
 	}
 }
 
-func (o *ReadOptions) AddFilter(filter filter.Filter) {
-	o.Filters[filter.GetColumnName()] = filter
-	o.FiltersV2 = append(o.FiltersV2, filter)
+// AddFilter adds a filter that rows must satisfy in addition to the filters added before,
+// including those on the same column.
+func (o *ReadOptions) AddFilter(f filter.Filter) {
+	if existing, ok := o.Filters[f.GetColumnName()]; ok {
+		o.Filters[f.GetColumnName()] = filter.NewConjunctionAndFilter(existing, f)
+	} else {
+		o.Filters[f.GetColumnName()] = f
+	}
+	o.FiltersV2 = append(o.FiltersV2, f)
+}
+
+// AddExpressionFilter adds a filter that rows must satisfy in addition to the other filters.
+// A comparison of a column with a literal is added with AddFilter to skip row groups by their
+// statistics, other expressions are evaluated after the rows are read.
+func (o *ReadOptions) AddExpressionFilter(f *filter.ExpressionFilter) {
+	if pushed, ok := f.Pushdown(); ok {
+		o.AddFilter(pushed)
+		return
+	}
+	o.ExpressionFilters = append(o.ExpressionFilters, f)
+}
+
+// Clone returns a copy whose filters and columns can be changed without affecting o.
+func (o *ReadOptions) Clone() *ReadOptions {
+	cloned := *o
+	cloned.Filters = make(map[string]filter.Filter, len(o.Filters))
+	for column, f := range o.Filters {
+		cloned.Filters[column] = f
+	}
+	cloned.FiltersV2 = append(FilterSet(nil), o.FiltersV2...)
+	cloned.ExpressionFilters = append([]*filter.ExpressionFilter(nil), o.ExpressionFilters...)
+	cloned.Columns = append([]string(nil), o.Columns...)
+	cloned.Hooks = append([]ReadHook(nil), o.Hooks...)
+	return &cloned
+}
+
+// AddHook adds a hook run on the records returned by the read after the hooks added before,
+// and after the casts of CastTo.
+func (o *ReadOptions) AddHook(hook ReadHook) {
+	o.Hooks = append(o.Hooks, hook)
+}
+
+func (o *ReadOptions) HasColumn(column string) bool {
+	for _, c := range o.Columns {
+		if c == column {
+			return true
+		}
+	}
+	return false
 }
 
 func (o *ReadOptions) AddColumn(column string) {
@@ -96,3 +776,29 @@ func (o *ReadOptions) GetVersion() int64 {
 func (o *ReadOptions) OutputColumns() []string {
 	return o.Columns
 }
+
+// CastTo casts the output columns named in schema to the type of their field, e.g. float64 to
+// float32 or timestamps to another unit. Other columns keep their type. Casts that lose data,
+// e.g. overflowing integers or truncating timestamps, fail the read.
+func (o *ReadOptions) CastTo(schema *arrow.Schema) {
+	o.castTo = schema
+}
+
+// CastSchema returns the schema set by CastTo, nil if the columns are not cast.
+func (o *ReadOptions) CastSchema() *arrow.Schema {
+	return o.castTo
+}
+
+// SetAllocator makes the files read allocate their buffers from mem, e.g. to account for the
+// memory held by the read.
+func (o *ReadOptions) SetAllocator(mem memory.Allocator) {
+	o.allocator = mem
+}
+
+// Allocator returns the allocator set by SetAllocator, memory.DefaultAllocator by default.
+func (o *ReadOptions) Allocator() memory.Allocator {
+	if o.allocator == nil {
+		return memory.DefaultAllocator
+	}
+	return o.allocator
+}
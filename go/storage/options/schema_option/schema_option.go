@@ -2,8 +2,11 @@ package schema_option
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/milvus-io/milvus-storage/go/common/constant"
 	"github.com/milvus-io/milvus-storage/go/proto/schema_proto"
 )
 
@@ -14,14 +17,89 @@ var (
 	ErrVersionColumnNotFound = errors.New("version column not found")
 	ErrVersionColumnType     = errors.New("version column is not int64")
 	ErrVectorColumnNotFound  = errors.New("vector column not found")
-	ErrVectorColumnType      = errors.New("vector column is not fixed size binary")
+	ErrVectorColumnType      = errors.New("vector column is not fixed size binary or a list of numbers")
 	ErrVectorColumnEmpty     = errors.New("vector column is empty")
+	ErrInvalidColumnGroup    = errors.New("invalid column group")
+	ErrAutoIDType            = errors.New("auto id primary column is not int64")
+	ErrInvalidStorageProfile = errors.New("invalid storage profile")
+	ErrDuplicateColumn       = errors.New("duplicate column")
+	ErrReservedColumn        = errors.New("column name is reserved")
+	ErrColumnReused          = errors.New("column used for several roles")
 )
 
+// ColumnGroupPolicy decides which columns are stored in the scalar files and which in the
+// vector files. The primary and version columns are stored in both.
+type ColumnGroupPolicy int32
+
+const (
+	// GroupByVector stores the vector column and VectorGroupColumns in the vector files and
+	// the other columns in the scalar files, so that scalar reads skip the large columns.
+	GroupByVector ColumnGroupPolicy = iota
+	// GroupTogether stores every column in the scalar files, for workloads that mostly read
+	// whole rows. The vector files only hold the primary and version columns.
+	GroupTogether
+)
+
+// Codec compresses the pages of a column. LZ4 is not offered, the parquet writer cannot
+// produce it.
+type Codec int32
+
+const (
+	// CodecDefault leaves the pages uncompressed, as files written without a profile.
+	CodecDefault Codec = iota
+	CodecUncompressed
+	CodecSnappy
+	CodecGzip
+	CodecBrotli
+	CodecZstd
+)
+
+// Encoding encodes the values of a column before compression.
+type Encoding int32
+
+const (
+	// EncodingDefault uses a dictionary and falls back to plain values when it grows too large.
+	EncodingDefault Encoding = iota
+	EncodingPlain
+	EncodingDictionary
+	// EncodingDeltaBinaryPacked is for integer columns.
+	EncodingDeltaBinaryPacked
+	// EncodingDeltaLengthByteArray and EncodingDeltaByteArray are for string and binary
+	// columns.
+	EncodingDeltaLengthByteArray
+	EncodingDeltaByteArray
+)
+
+// StorageProfile is how the data files store a field, e.g. zstd at level 19 for scalar
+// columns that are rarely read and snappy for hot vector columns.
+type StorageProfile struct {
+	Codec Codec
+	// Level is the compression level of Codec, 0 is its default. Only gzip, brotli and zstd
+	// have levels.
+	Level    int
+	Encoding Encoding
+}
+
 type SchemaOptions struct {
 	PrimaryColumn string
 	VersionColumn string
-	VectorColumn  string
+	// VectorColumn is null in no row unless its field is nullable, e.g. for rows embedded
+	// later, which filter.NewIsNullFilter reads.
+	VectorColumn string
+	// VectorColumns are the vector columns other than VectorColumn, e.g. the dense and sparse
+	// embeddings of a document. They are validated like VectorColumn and stored with it.
+	VectorColumns []string
+	// ColumnGroupPolicy is recorded in the manifest and cannot change once the space exists.
+	ColumnGroupPolicy ColumnGroupPolicy
+	// VectorGroupColumns are other large columns stored with the vector column, only used
+	// with GroupByVector.
+	VectorGroupColumns []string
+	// AutoID generates the primary keys of records written without the primary column, which
+	// must be int64. Keys increase with every write and are unique within the space.
+	AutoID bool
+	// StorageProfiles are the storage profiles of fields by name, the other fields use the
+	// default one. Profiles apply to the files written after they are set.
+	StorageProfiles map[string]StorageProfile
 }
 
 func Init() *SchemaOptions {
@@ -37,6 +115,20 @@ func (o *SchemaOptions) ToProtobuf() *schema_proto.SchemaOptions {
 	options.PrimaryColumn = o.PrimaryColumn
 	options.VersionColumn = o.VersionColumn
 	options.VectorColumn = o.VectorColumn
+	options.VectorColumns = append([]string(nil), o.VectorColumns...)
+	options.ColumnGroupPolicy = schema_proto.ColumnGroupPolicy(o.ColumnGroupPolicy)
+	options.VectorGroupColumns = append([]string(nil), o.VectorGroupColumns...)
+	options.AutoId = o.AutoID
+	if len(o.StorageProfiles) > 0 {
+		options.StorageProfiles = make(map[string]*schema_proto.StorageProfile, len(o.StorageProfiles))
+		for name, profile := range o.StorageProfiles {
+			options.StorageProfiles[name] = &schema_proto.StorageProfile{
+				Codec:    schema_proto.Codec(profile.Codec),
+				Level:    int32(profile.Level),
+				Encoding: schema_proto.Encoding(profile.Encoding),
+			}
+		}
+	}
 	return options
 }
 
@@ -44,15 +136,44 @@ func (o *SchemaOptions) FromProtobuf(options *schema_proto.SchemaOptions) {
 	o.PrimaryColumn = options.PrimaryColumn
 	o.VersionColumn = options.VersionColumn
 	o.VectorColumn = options.VectorColumn
+	o.VectorColumns = append([]string(nil), options.VectorColumns...)
+	o.ColumnGroupPolicy = ColumnGroupPolicy(options.ColumnGroupPolicy)
+	o.VectorGroupColumns = append([]string(nil), options.VectorGroupColumns...)
+	o.AutoID = options.AutoId
+	o.StorageProfiles = nil
+	if len(options.StorageProfiles) > 0 {
+		o.StorageProfiles = make(map[string]StorageProfile, len(options.StorageProfiles))
+		for name, profile := range options.StorageProfiles {
+			o.StorageProfiles[name] = StorageProfile{
+				Codec:    Codec(profile.GetCodec()),
+				Level:    int(profile.GetLevel()),
+				Encoding: Encoding(profile.GetEncoding()),
+			}
+		}
+	}
 }
 
+// Validate checks that the options fit schema, errors name the column at fault and wrap one of
+// the errors of the package.
 func (o *SchemaOptions) Validate(schema *arrow.Schema) error {
+	seen := make(map[string]bool, len(schema.Fields()))
+	for _, field := range schema.Fields() {
+		if seen[field.Name] {
+			return fmt.Errorf("column %s: %w", field.Name, ErrDuplicateColumn)
+		}
+		seen[field.Name] = true
+		if strings.HasPrefix(field.Name, constant.ReservedColumnPrefix) {
+			return fmt.Errorf("column %s: %w", field.Name, ErrReservedColumn)
+		}
+	}
 	if o.PrimaryColumn != "" {
 		primaryField, ok := schema.FieldsByName(o.PrimaryColumn)
 		if !ok {
-			return ErrPrimaryColumnNotFound
+			return fmt.Errorf("primary column %s: %w", o.PrimaryColumn, ErrPrimaryColumnNotFound)
 		} else if primaryField[0].Type.ID() != arrow.STRING && primaryField[0].Type.ID() != arrow.INT64 {
-			return ErrPrimaryColumnType
+			return fmt.Errorf("primary column %s of type %s: %w", o.PrimaryColumn, primaryField[0].Type, ErrPrimaryColumnType)
+		} else if o.AutoID && primaryField[0].Type.ID() != arrow.INT64 {
+			return fmt.Errorf("primary column %s of type %s: %w", o.PrimaryColumn, primaryField[0].Type, ErrAutoIDType)
 		}
 	} else {
 		return ErrPrimaryColumnEmpty
@@ -60,24 +181,174 @@ func (o *SchemaOptions) Validate(schema *arrow.Schema) error {
 	if o.VersionColumn != "" {
 		versionField, ok := schema.FieldsByName(o.VersionColumn)
 		if !ok {
-			return ErrVersionColumnNotFound
+			return fmt.Errorf("version column %s: %w", o.VersionColumn, ErrVersionColumnNotFound)
 		} else if versionField[0].Type.ID() != arrow.INT64 {
-			return ErrVersionColumnType
+			return fmt.Errorf("version column %s of type %s: %w", o.VersionColumn, versionField[0].Type, ErrVersionColumnType)
+		} else if o.VersionColumn == o.PrimaryColumn {
+			return fmt.Errorf("version column %s is the primary column: %w", o.VersionColumn, ErrColumnReused)
 		}
 	}
-	if o.VectorColumn != "" {
-		vectorField, b := schema.FieldsByName(o.VectorColumn)
+	if o.VectorColumn == "" {
+		return ErrVectorColumnEmpty
+	}
+	vectors := make(map[string]bool)
+	for _, column := range o.AllVectorColumns() {
+		vectorField, b := schema.FieldsByName(column)
 		if !b {
-			return ErrVectorColumnNotFound
-		} else if vectorField[0].Type.ID() != arrow.FIXED_SIZE_BINARY {
-			return ErrVectorColumnType
+			return fmt.Errorf("vector column %s: %w", column, ErrVectorColumnNotFound)
+		} else if !IsVectorType(vectorField[0].Type) {
+			return fmt.Errorf("vector column %s of type %s: %w", column, vectorField[0].Type, ErrVectorColumnType)
+		} else if vectors[column] {
+			return fmt.Errorf("vector column %s listed twice: %w", column, ErrColumnReused)
+		}
+		vectors[column] = true
+	}
+	if err := o.validateColumnGroups(schema); err != nil {
+		return err
+	}
+	return o.validateStorageProfiles(schema)
+}
+
+func (o *SchemaOptions) validateColumnGroups(schema *arrow.Schema) error {
+	switch o.ColumnGroupPolicy {
+	case GroupByVector:
+	case GroupTogether:
+		if len(o.VectorGroupColumns) > 0 {
+			return fmt.Errorf("vector group columns with all columns grouped together: %w", ErrInvalidColumnGroup)
+		}
+	default:
+		return fmt.Errorf("column group policy %d: %w", o.ColumnGroupPolicy, ErrInvalidColumnGroup)
+	}
+	seen := make(map[string]bool)
+	for _, column := range o.VectorGroupColumns {
+		if _, ok := schema.FieldsByName(column); !ok {
+			return fmt.Errorf("vector group column %s not found: %w", column, ErrInvalidColumnGroup)
+		}
+		if column == o.PrimaryColumn || column == o.VersionColumn || o.IsVectorColumn(column) {
+			return fmt.Errorf("vector group column %s is already placed: %w", column, ErrInvalidColumnGroup)
+		}
+		if seen[column] {
+			return fmt.Errorf("vector group column %s listed twice: %w", column, ErrInvalidColumnGroup)
+		}
+		seen[column] = true
+	}
+	return nil
+}
+
+func (o *SchemaOptions) validateStorageProfiles(schema *arrow.Schema) error {
+	for name, profile := range o.StorageProfiles {
+		fields, ok := schema.FieldsByName(name)
+		if !ok {
+			return fmt.Errorf("storage profile of %s: field not found: %w", name, ErrInvalidStorageProfile)
+		}
+		if profile.Codec < CodecDefault || profile.Codec > CodecZstd {
+			return fmt.Errorf("storage profile of %s: codec %d: %w", name, profile.Codec, ErrInvalidStorageProfile)
+		}
+		if profile.Level != 0 && profile.Codec != CodecGzip && profile.Codec != CodecBrotli && profile.Codec != CodecZstd {
+			return fmt.Errorf("storage profile of %s: codec %d has no level: %w", name, profile.Codec, ErrInvalidStorageProfile)
+		}
+		var valid bool
+		switch profile.Encoding {
+		case EncodingDefault, EncodingPlain, EncodingDictionary:
+			valid = true
+		case EncodingDeltaBinaryPacked:
+			valid = arrow.IsInteger(fields[0].Type.ID())
+		case EncodingDeltaLengthByteArray, EncodingDeltaByteArray:
+			valid = fields[0].Type.ID() == arrow.STRING || fields[0].Type.ID() == arrow.BINARY
+		}
+		if !valid {
+			return fmt.Errorf("storage profile of %s: encoding %d with type %s: %w", name, profile.Encoding, fields[0].Type, ErrInvalidStorageProfile)
 		}
-	} else {
-		return ErrVectorColumnEmpty
 	}
 	return nil
 }
 
+// ReservedColumns returns the names of the columns added by the storage, e.g. to the scalar
+// files or to the reads including deleted rows.
+func ReservedColumns() []string {
+	return []string{constant.OffsetFieldName, constant.DeletedFieldName}
+}
+
+// IsVectorType reports whether a vector column may have type t: fixed size binary, e.g. binary
+// vectors, a fixed size list of floats or bytes whose size is the dimension, or a list of them
+// for vectors of varying length, e.g. multi-vectors.
+func IsVectorType(t arrow.DataType) bool {
+	switch t := t.(type) {
+	case *arrow.FixedSizeBinaryType:
+		return true
+	case *arrow.FixedSizeListType:
+		return isVectorElem(t.Elem())
+	case *arrow.ListType:
+		return isVectorElem(t.Elem())
+	}
+	return false
+}
+
+func isVectorElem(t arrow.DataType) bool {
+	switch t.ID() {
+	case arrow.FLOAT16, arrow.FLOAT32, arrow.FLOAT64, arrow.INT8, arrow.UINT8:
+		return true
+	}
+	return false
+}
+
+// WithoutColumn returns a copy of o with no reference to column, e.g. once it is dropped.
+func (o *SchemaOptions) WithoutColumn(column string) *SchemaOptions {
+	without := func(columns []string) []string {
+		var kept []string
+		for _, c := range columns {
+			if c != column {
+				kept = append(kept, c)
+			}
+		}
+		return kept
+	}
+	copied := *o
+	copied.VectorColumns = without(o.VectorColumns)
+	copied.VectorGroupColumns = without(o.VectorGroupColumns)
+	copied.StorageProfiles = nil
+	for name, profile := range o.StorageProfiles {
+		if name == column {
+			continue
+		}
+		if copied.StorageProfiles == nil {
+			copied.StorageProfiles = make(map[string]StorageProfile, len(o.StorageProfiles))
+		}
+		copied.StorageProfiles[name] = profile
+	}
+	return &copied
+}
+
+// AllVectorColumns returns VectorColumn followed by VectorColumns.
+func (o *SchemaOptions) AllVectorColumns() []string {
+	return append([]string{o.VectorColumn}, o.VectorColumns...)
+}
+
+func (o *SchemaOptions) IsVectorColumn(column string) bool {
+	for _, c := range o.AllVectorColumns() {
+		if c == column {
+			return true
+		}
+	}
+	return false
+}
+
+// InVectorGroup reports whether column is stored in the vector files only.
+func (o *SchemaOptions) InVectorGroup(column string) bool {
+	if o.ColumnGroupPolicy == GroupTogether {
+		return false
+	}
+	if o.IsVectorColumn(column) {
+		return true
+	}
+	for _, c := range o.VectorGroupColumns {
+		if c == column {
+			return true
+		}
+	}
+	return false
+}
+
 func (o *SchemaOptions) HasVersionColumn() bool {
 	return o.VersionColumn != ""
 }
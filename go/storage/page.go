@@ -0,0 +1,261 @@
+package storage
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/milvus-io/milvus-storage/go/common/errors"
+	"github.com/milvus-io/milvus-storage/go/common/utils"
+	"github.com/milvus-io/milvus-storage/go/file/fragment"
+	"github.com/milvus-io/milvus-storage/go/storage/auth"
+	"github.com/milvus-io/milvus-storage/go/storage/manifest"
+	"github.com/milvus-io/milvus-storage/go/storage/options/option"
+)
+
+var ErrInvalidPageToken = errors.New("invalid page token")
+
+// pageToken is the position where a page ended: the rows of file File of the data fragment
+// Fragment that were already returned, at manifest version Version.
+type pageToken struct {
+	Version  int64 `json:"v"`
+	Fragment int64 `json:"f"`
+	File     int   `json:"i"`
+	Rows     int64 `json:"r"`
+}
+
+func (t pageToken) encode() string {
+	data, _ := json.Marshal(t)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodePageToken(token string) (pageToken, error) {
+	var t pageToken
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err == nil {
+		err = json.Unmarshal(data, &t)
+	}
+	if err != nil {
+		return pageToken{}, fmt.Errorf("decode page token: %w", ErrInvalidPageToken)
+	}
+	return t, nil
+}
+
+func (s *Space) ReadPage(readOption *option.ReadOptions, token string, limit int64) (*PageReader, error) {
+	return s.ReadPageContext(context.Background(), readOption, token, limit)
+}
+
+// ReadPageContext reads at most limit rows, all of them if limit is not positive, starting
+// where the page of token ended or at the beginning if token is empty. Files are read in
+// manifest order and the pages of a token all read the version the first page read, so
+// paging through a scan returns every row once even if commits happen in between. The
+// next pages must be read with the same options. ctx carries the caller identity, it
+// requires auth.OpRead.
+func (s *Space) ReadPageContext(ctx context.Context, readOption *option.ReadOptions, token string, limit int64) (*PageReader, error) {
+	if err := s.authorize(ctx, auth.OpRead); err != nil {
+		return nil, err
+	}
+	m, err := s.readSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	start := pageToken{Version: m.Version()}
+	if token != "" {
+		if start, err = decodePageToken(token); err != nil {
+			return nil, err
+		}
+		if m, err = s.manifestAt(start.Version); err != nil {
+			return nil, fmt.Errorf("read page: %w", err)
+		}
+	}
+
+	r := &PageReader{ref: 1, ctx: ctx, space: s, m: m, options: readOption, limit: limit}
+	if token != "" {
+		r.fragmentPos = -1
+		for i, f := range m.GetScalarFragments() {
+			if f.FragmentId() == start.Fragment && start.File < len(f.Files()) {
+				r.fragmentPos, r.filePos, r.skip = i, start.File, start.Rows
+				break
+			}
+		}
+		if r.fragmentPos < 0 {
+			return nil, fmt.Errorf("file %d of fragment %d: %w", start.File, start.Fragment, ErrInvalidPageToken)
+		}
+	}
+
+	// the schema is taken from a reader that has no files to open
+	empty := m.Copy()
+	empty.SetScalarFragments(nil)
+	empty.SetVectorFragments(nil)
+	reader, err := s.read(ctx, empty, readOption)
+	if err != nil {
+		return nil, err
+	}
+	r.schema = reader.Schema()
+	reader.Release()
+	return r, nil
+}
+
+// manifestAt returns the manifest of version as seen by readers, which must not have been
+// vacuumed.
+func (s *Space) manifestAt(version int64) (*manifest.Manifest, error) {
+	if m := s.snapshot(); m.Version() == version {
+		return s.visible(m)
+	}
+	m, err := manifest.ParseFromFile(s.fs, utils.GetManifestFilePath(s.path, version))
+	if err != nil {
+		return nil, err
+	}
+	return s.visible(m)
+}
+
+// PageReader reads one page of a scan, NextPageToken returns where the next page starts
+// once Next returned false.
+type PageReader struct {
+	ref     int64
+	ctx     context.Context
+	space   *Space
+	m       *manifest.Manifest
+	options *option.ReadOptions
+	schema  *arrow.Schema
+	limit   int64
+
+	fragmentPos int
+	filePos     int
+	// skip is the number of rows of the current file returned by previous pages, fileRows
+	// those returned so far including them.
+	skip     int64
+	fileRows int64
+	rows     int64
+	reader   array.RecordReader
+	rec      arrow.Record
+	err      error
+	token    string
+}
+
+func (r *PageReader) Schema() *arrow.Schema {
+	return r.schema
+}
+
+func (r *PageReader) Retain() {
+	atomic.AddInt64(&r.ref, 1)
+}
+
+func (r *PageReader) Release() {
+	if atomic.AddInt64(&r.ref, -1) == 0 {
+		if r.rec != nil {
+			r.rec.Release()
+			r.rec = nil
+		}
+		r.closeFile()
+	}
+}
+
+func (r *PageReader) Record() arrow.Record {
+	return r.rec
+}
+
+func (r *PageReader) Err() error {
+	return r.err
+}
+
+// NextPageToken returns the token of the next page, or an empty string if the scan is
+// complete. A page that ends exactly with the last row may return a token whose page is empty.
+func (r *PageReader) NextPageToken() string {
+	return r.token
+}
+
+func (r *PageReader) Next() bool {
+	if r.rec != nil {
+		r.rec.Release()
+		r.rec = nil
+	}
+	if r.err != nil {
+		return false
+	}
+	for {
+		if r.limit > 0 && r.rows >= r.limit {
+			r.token = pageToken{
+				Version:  r.m.Version(),
+				Fragment: r.m.GetScalarFragments()[r.fragmentPos].FragmentId(),
+				File:     r.filePos,
+				Rows:     r.fileRows,
+			}.encode()
+			r.closeFile()
+			return false
+		}
+		if r.reader == nil && !r.openFile() {
+			return false
+		}
+		if !r.reader.Next() {
+			if err := r.reader.Err(); err != nil {
+				r.err = err
+				r.closeFile()
+				return false
+			}
+			r.closeFile()
+			r.filePos++
+			r.skip, r.fileRows = 0, 0
+			continue
+		}
+
+		rec := r.reader.Record()
+		begin, end := int64(0), rec.NumRows()
+		if r.skip > r.fileRows {
+			begin = r.skip - r.fileRows
+			if begin > end {
+				begin = end
+			}
+		}
+		if r.limit > 0 && end-begin > r.limit-r.rows {
+			end = begin + r.limit - r.rows
+		}
+		r.fileRows += end
+		if begin == end {
+			continue
+		}
+		r.rows += end - begin
+		r.rec = rec.NewSlice(begin, end)
+		return true
+	}
+}
+
+// openFile opens a reader over the current file, moving to the next fragment when the files
+// of the current one are read. It returns false when all files are read.
+func (r *PageReader) openFile() bool {
+	scalarFragments, vectorFragments := r.m.GetScalarFragments(), r.m.GetVectorFragments()
+	for r.fragmentPos < len(scalarFragments) && r.filePos >= len(scalarFragments[r.fragmentPos].Files()) {
+		r.fragmentPos++
+		r.filePos = 0
+	}
+	if r.fragmentPos >= len(scalarFragments) {
+		return false
+	}
+
+	single := func(f fragment.Fragment) fragment.FragmentVector {
+		file := fragment.NewFragment(f.FragmentId())
+		file.AddFileWithStats(f.Files()[r.filePos], f.FileStats()[r.filePos])
+		return fragment.FragmentVector{*file}
+	}
+	m := r.m.Copy()
+	m.SetScalarFragments(single(scalarFragments[r.fragmentPos]))
+	m.SetVectorFragments(single(vectorFragments[r.fragmentPos]))
+	reader, err := r.space.read(r.ctx, m, r.options)
+	if err != nil {
+		r.err = err
+		return false
+	}
+	r.reader = reader
+	return true
+}
+
+func (r *PageReader) closeFile() {
+	if r.reader != nil {
+		r.reader.Release()
+		r.reader = nil
+	}
+}
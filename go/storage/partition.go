@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/milvus-io/milvus-storage/go/storage/auth"
+	"github.com/milvus-io/milvus-storage/go/storage/options/option"
+)
+
+// PartitionKey identifies a partition of the rows written by WritePartitioned.
+type PartitionKey string
+
+// WritePartitioned writes the rows of every partition to its own data fragment, see
+// WritePartitionedContext.
+func (s *Space) WritePartitioned(partitions map[PartitionKey]array.RecordReader, options *option.WriteOptions) (CommitResult, error) {
+	return s.WritePartitionedContext(context.Background(), partitions, options)
+}
+
+// WritePartitionedContext writes the rows of every partition to its own data fragments and
+// commits them all in a single version, so that the rows of a partition stay together, e.g.
+// for a bulk load, without a commit per partition. The fragment ids of the result are in the
+// order of the partition keys, partitions without rows add no fragment. Duplicates of
+// option.DuplicatesLastWins are only dropped within a partition. ctx carries the caller
+// identity, it requires auth.OpWrite.
+func (s *Space) WritePartitionedContext(ctx context.Context, partitions map[PartitionKey]array.RecordReader, options *option.WriteOptions) (CommitResult, error) {
+	if err := s.authorize(ctx, auth.OpWrite); err != nil {
+		return CommitResult{}, err
+	}
+	if err := s.checkMemory("write"); err != nil {
+		return CommitResult{}, err
+	}
+	keys := make([]PartitionKey, 0, len(partitions))
+	for key := range partitions {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+
+	m := s.snapshot()
+	var w *fragmentWrite
+	var fragments []writtenFragment
+	for _, key := range keys {
+		reader := partitions[key]
+		if w == nil {
+			var err error
+			if w, err = s.newFragmentWrite(m, reader.Schema(), options); err != nil {
+				return CommitResult{}, err
+			}
+			defer func(start time.Time) {
+				s.logSlow("write partitioned", s.slowLog.Write, start, progressFields(*w.progress)...)
+			}(time.Now())
+		} else if autoID, err := checkWriteSchema(m.GetSchema(), reader.Schema(), options); err != nil {
+			return CommitResult{}, err
+		} else if autoID != w.autoID {
+			// the partitions must all omit the primary column or none
+			return CommitResult{}, ErrSchemaNotMatch
+		}
+		written, err := w.write(reader)
+		if err != nil {
+			w.discard(fragments...)
+			return CommitResult{}, err
+		}
+		if len(written.scalar.Files()) > 0 {
+			fragments = append(fragments, written)
+		}
+	}
+	if len(fragments) == 0 {
+		return CommitResult{Version: m.Version()}, nil
+	}
+	reportWriteProgress(options, w.progress, nil, nil)
+	return w.commit(ctx, fragments, "")
+}
@@ -0,0 +1,161 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/milvus-io/milvus-storage/go/common/errors"
+	"github.com/milvus-io/milvus-storage/go/file/fragment"
+	"github.com/milvus-io/milvus-storage/go/storage/auth"
+	"github.com/milvus-io/milvus-storage/go/storage/manifest"
+	"github.com/milvus-io/milvus-storage/go/storage/options/option"
+)
+
+var (
+	ErrFragmentNotExist = errors.NewWithKind(errors.ErrNotFound, "fragment not exist")
+	ErrFragmentPinned   = errors.New("fragment pinned")
+)
+
+// PinFragments pins the data fragments ids, see PinFragmentsContext.
+func (s *Space) PinFragments(ids ...int64) error {
+	return s.PinFragmentsContext(context.Background(), ids...)
+}
+
+// PinFragmentsContext pins the data fragments ids in a new version, e.g. since an external
+// index refers to their rows by file and offset. Compaction and tier migration leave pinned
+// fragments as they are, and their cached deleted rows are kept while other versions are
+// read. Operations that must rewrite every file, like BackfillColumn, fail with
+// ErrFragmentPinned. ctx carries the caller identity, it requires auth.OpAdmin.
+func (s *Space) PinFragmentsContext(ctx context.Context, ids ...int64) error {
+	if err := s.authorize(ctx, auth.OpAdmin); err != nil {
+		return err
+	}
+	record := &option.AuditRecord{Operation: auth.OpAdmin}
+	return s.commit(ctx, record, func(latest *manifest.Manifest, version int64) error {
+		pinned := append([]int64(nil), latest.PinnedFragments()...)
+		for _, id := range ids {
+			if findFragment(latest.GetScalarFragments(), id) < 0 {
+				return fmt.Errorf("pin fragment %d: %w", id, ErrFragmentNotExist)
+			}
+			if !latest.IsPinned(id) {
+				pinned = append(pinned, id)
+			}
+		}
+		latest.SetPinnedFragments(pinned)
+		return nil
+	})
+}
+
+// PinVersion pins the data fragments of version, see PinVersionContext.
+func (s *Space) PinVersion(version int64) (int64, error) {
+	return s.PinVersionContext(context.Background(), version)
+}
+
+// PinVersionContext pins the data fragments read by version, see PinFragmentsContext, and
+// returns the version that records the pins. It fails with ErrFragmentNotExist if a fragment of
+// version was rewritten or removed since. ctx carries the caller identity, it requires
+// auth.OpAdmin.
+func (s *Space) PinVersionContext(ctx context.Context, version int64) (int64, error) {
+	if err := s.authorize(ctx, auth.OpAdmin); err != nil {
+		return 0, err
+	}
+	m, err := s.manifestAt(version)
+	if err != nil {
+		return 0, err
+	}
+	record := &option.AuditRecord{Operation: auth.OpAdmin}
+	var committed int64
+	err = s.commit(ctx, record, func(latest *manifest.Manifest, version int64) error {
+		pinned := append([]int64(nil), latest.PinnedFragments()...)
+		for _, f := range m.GetScalarFragments() {
+			if !hasFragment(latest.GetScalarFragments(), f) {
+				return fmt.Errorf("pin fragment %d of version %d: %w", f.FragmentId(), version, ErrFragmentNotExist)
+			}
+			if !latest.IsPinned(f.FragmentId()) {
+				pinned = append(pinned, f.FragmentId())
+			}
+		}
+		latest.SetPinnedFragments(pinned)
+		committed = version
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return committed, nil
+}
+
+// UnpinFragments unpins the data fragments ids, see UnpinFragmentsContext.
+func (s *Space) UnpinFragments(ids ...int64) error {
+	return s.UnpinFragmentsContext(context.Background(), ids...)
+}
+
+// UnpinFragmentsContext unpins the data fragments ids in a new version, ids that are not
+// pinned are ignored. ctx carries the caller identity, it requires auth.OpAdmin.
+func (s *Space) UnpinFragmentsContext(ctx context.Context, ids ...int64) error {
+	if err := s.authorize(ctx, auth.OpAdmin); err != nil {
+		return err
+	}
+	unpin := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		unpin[id] = true
+	}
+	record := &option.AuditRecord{Operation: auth.OpAdmin}
+	return s.commit(ctx, record, func(latest *manifest.Manifest, _ int64) error {
+		var pinned []int64
+		for _, id := range latest.PinnedFragments() {
+			if !unpin[id] {
+				pinned = append(pinned, id)
+			}
+		}
+		latest.SetPinnedFragments(pinned)
+		return nil
+	})
+}
+
+// PinnedFragments returns the ids of the pinned data fragments of the current version.
+func (s *Space) PinnedFragments() []int64 {
+	return append([]int64(nil), s.snapshot().PinnedFragments()...)
+}
+
+// findFragment returns the position of fragment id in fragments, -1 if it is not there.
+func findFragment(fragments fragment.FragmentVector, id int64) int {
+	for i, f := range fragments {
+		if f.FragmentId() == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// hasFragment reports whether fragments hold f with the same files.
+func hasFragment(fragments fragment.FragmentVector, f fragment.Fragment) bool {
+	i := findFragment(fragments, f.FragmentId())
+	return i >= 0 && hasPrefix(fragments[i:i+1], fragment.FragmentVector{f})
+}
+
+// splitPinned returns the fragments of m pinned and the others, in order.
+func splitPinned(m *manifest.Manifest, fragments fragment.FragmentVector) (fragment.FragmentVector, fragment.FragmentVector) {
+	var pinned, others fragment.FragmentVector
+	for _, f := range fragments {
+		if m.IsPinned(f.FragmentId()) {
+			pinned = append(pinned, f)
+		} else {
+			others = append(others, f)
+		}
+	}
+	return pinned, others
+}
+
+// samePins reports whether a and b pin the same fragments.
+func samePins(a, b *manifest.Manifest) bool {
+	if len(a.PinnedFragments()) != len(b.PinnedFragments()) {
+		return false
+	}
+	for _, id := range a.PinnedFragments() {
+		if !b.IsPinned(id) {
+			return false
+		}
+	}
+	return true
+}
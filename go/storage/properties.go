@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"context"
+
+	"github.com/milvus-io/milvus-storage/go/storage/auth"
+	"github.com/milvus-io/milvus-storage/go/storage/manifest"
+	"github.com/milvus-io/milvus-storage/go/storage/options/option"
+)
+
+// CommitEmpty commits a version with the data of the current one and properties, e.g. a
+// checkpoint or barrier of an external system that readers of the version history key off.
+func (s *Space) CommitEmpty(properties map[string]string) (int64, error) {
+	return s.CommitEmptyContext(context.Background(), properties)
+}
+
+// CommitEmptyContext is like CommitEmpty, ctx carries the caller identity checked by the
+// authorizer.
+func (s *Space) CommitEmptyContext(ctx context.Context, properties map[string]string) (int64, error) {
+	if err := s.authorize(ctx, auth.OpWrite); err != nil {
+		return 0, err
+	}
+	properties = copyMetadata(properties)
+	var committed int64
+	record := &option.AuditRecord{Operation: auth.OpWrite}
+	err := s.commit(ctx, record, func(m *manifest.Manifest, version int64) error {
+		m.SetProperties(mergeProperties(m.Properties(), properties))
+		committed = version
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return committed, nil
+}
+
+// VersionProperties returns the properties committed with version, nil if there are none. They
+// include the column statistics kept since the last Analyze, under
+// constant.StatsPropertyPrefix.
+func (s *Space) VersionProperties(version int64) (map[string]string, error) {
+	return s.VersionPropertiesContext(context.Background(), version)
+}
+
+// VersionPropertiesContext is like VersionProperties, ctx carries the caller identity checked
+// by the authorizer.
+func (s *Space) VersionPropertiesContext(ctx context.Context, version int64) (map[string]string, error) {
+	if err := s.authorize(ctx, auth.OpRead); err != nil {
+		return nil, err
+	}
+	m, err := s.manifestAt(version)
+	if err != nil {
+		return nil, err
+	}
+	return copyMetadata(m.Properties()), nil
+}
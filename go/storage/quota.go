@@ -0,0 +1,29 @@
+package storage
+
+import (
+	"fmt"
+
+	"github.com/milvus-io/milvus-storage/go/storage/manifest"
+	"github.com/milvus-io/milvus-storage/go/storage/options/option"
+)
+
+// checkQuota returns ErrQuotaExceeded if adding rows and bytes to usage exceeds quota. A nil
+// quota never fails.
+func checkQuota(quota *option.QuotaOptions, usage manifest.Usage, rows, bytes int64) error {
+	if quota == nil {
+		return nil
+	}
+	if quota.MaxRows > 0 && usage.Rows+rows > quota.MaxRows {
+		return fmt.Errorf("%d rows exceed limit %d: %w", usage.Rows+rows, quota.MaxRows, ErrQuotaExceeded)
+	}
+	if quota.MaxBytes > 0 && usage.Bytes+bytes > quota.MaxBytes {
+		return fmt.Errorf("%d bytes exceed limit %d: %w", usage.Bytes+bytes, quota.MaxBytes, ErrQuotaExceeded)
+	}
+	return nil
+}
+
+// Usage returns the rows and bytes referenced by the current version. Manifests written
+// before usage was tracked start from zero.
+func (s *Space) Usage() manifest.Usage {
+	return s.snapshot().GetUsage()
+}
@@ -0,0 +1,262 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync/atomic"
+
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/arrio"
+	"github.com/apache/arrow/go/v12/arrow/compute"
+	"github.com/milvus-io/milvus-storage/go/common/errors"
+	"github.com/milvus-io/milvus-storage/go/reader/record_reader"
+	"github.com/milvus-io/milvus-storage/go/storage/options/option"
+)
+
+var (
+	ErrCastNotSupported = errors.New("cast not supported")
+	// ErrHookSchema is returned by reads whose hooks return records of another schema than
+	// they announced.
+	ErrHookSchema = errors.New("read hook returned an unexpected schema")
+)
+
+// RecordReader is the reader returned by Space.Read. It is also an arrio.Reader, so it can be
+// copied with arrio.Copy, e.g. to an ipc.Writer.
+type RecordReader interface {
+	array.RecordReader
+	arrio.Reader
+	// Provenance returns where the rows of the current record were read from.
+	Provenance() Provenance
+}
+
+// Provenance is the lineage of a record returned by a read, e.g. to key downstream caches or
+// to trace bad rows to their files.
+type Provenance struct {
+	// Version is the version of the space read.
+	Version int64
+	// Sources are the fragments and files the rows were read from, empty if the reader does
+	// not know them.
+	Sources []record_reader.Source
+}
+
+// recordReader returns records with its schema, the records of the file readers carry parquet
+// metadata and their fields may be in another order.
+type recordReader struct {
+	array.RecordReader
+	ref    int64
+	schema *arrow.Schema
+	rec    arrow.Record
+	// cast tells which fields of schema are cast from the type read.
+	cast []bool
+	err  error
+	// version is the version of the space read.
+	version int64
+	// closed, if set, is the closed flag of the space read, the reader fails once it is set.
+	closed *int32
+	// onRelease, if set, is called once the reader is released.
+	onRelease func()
+	// fill, if set, adds the records returned to the result cache.
+	fill *resultFill
+	// hooks transform the records, hookSchemas[i] is the schema of the records returned by
+	// hooks[i].
+	hooks       []option.ReadHook
+	hookSchemas []*arrow.Schema
+}
+
+// newRecordReader returns the fields of reader in the order of columns, followed by the
+// fields added by the read such as the version column. The fields named in castTo, if not
+// nil, are cast to its types.
+func newRecordReader(reader array.RecordReader, columns []string, castTo *arrow.Schema) (*recordReader, error) {
+	fields := make([]arrow.Field, 0, len(reader.Schema().Fields()))
+	added := make(map[string]bool)
+	for _, column := range columns {
+		if indices := reader.Schema().FieldIndices(column); len(indices) > 0 && !added[column] {
+			added[column] = true
+			fields = append(fields, reader.Schema().Field(indices[0]))
+		}
+	}
+	for _, field := range reader.Schema().Fields() {
+		if !added[field.Name] {
+			fields = append(fields, field)
+		}
+	}
+	var cast []bool
+	if castTo != nil {
+		cast = make([]bool, len(fields))
+		for _, target := range castTo.Fields() {
+			var found bool
+			for i, field := range fields {
+				if field.Name != target.Name {
+					continue
+				}
+				found = true
+				if arrow.TypeEqual(field.Type, target.Type) {
+					break
+				}
+				if !compute.CanCast(field.Type, target.Type) {
+					return nil, fmt.Errorf("cast %s from %s to %s: %w", field.Name, field.Type, target.Type, ErrCastNotSupported)
+				}
+				fields[i].Type, cast[i] = target.Type, true
+				break
+			}
+			if !found {
+				return nil, fmt.Errorf("cast %s: %w", target.Name, ErrColumnNotExist)
+			}
+		}
+	}
+	return &recordReader{RecordReader: reader, ref: 1, schema: arrow.NewSchema(fields, nil), cast: cast}, nil
+}
+
+// setHooks makes the reader return the records transformed by hooks.
+func (r *recordReader) setHooks(hooks []option.ReadHook) error {
+	schema := r.schema
+	for _, hook := range hooks {
+		var err error
+		if schema, err = hook.Schema(schema); err != nil {
+			return fmt.Errorf("read hook: %w", err)
+		}
+		r.hookSchemas = append(r.hookSchemas, schema)
+	}
+	r.hooks = hooks
+	return nil
+}
+
+func (r *recordReader) Schema() *arrow.Schema {
+	if len(r.hookSchemas) > 0 {
+		return r.hookSchemas[len(r.hookSchemas)-1]
+	}
+	return r.schema
+}
+
+func (r *recordReader) Next() bool {
+	if r.rec != nil {
+		r.rec.Release()
+		r.rec = nil
+	}
+	if r.closed != nil && atomic.LoadInt32(r.closed) != 0 {
+		r.err = ErrSpaceClosed
+	}
+	if r.err != nil || !r.RecordReader.Next() {
+		if r.fill != nil {
+			if r.Err() == nil {
+				r.fill.done()
+			}
+			r.fill.discard()
+		}
+		return false
+	}
+	rec, schema := r.RecordReader.Record(), r.schema
+	columns := make([]arrow.Array, 0, len(schema.Fields()))
+	for i, field := range schema.Fields() {
+		column := rec.Column(rec.Schema().FieldIndices(field.Name)[0])
+		if r.cast != nil && r.cast[i] {
+			cast, err := compute.CastToType(context.Background(), column, field.Type)
+			if err != nil {
+				for j := range columns {
+					if r.cast[j] {
+						columns[j].Release()
+					}
+				}
+				r.err = fmt.Errorf("cast %s: %w", field.Name, err)
+				return false
+			}
+			column = cast
+		}
+		columns = append(columns, column)
+	}
+	r.rec = array.NewRecord(schema, columns, rec.NumRows())
+	for i, hook := range r.hooks {
+		transformed, err := hook.Transform(r.rec)
+		r.rec.Release()
+		r.rec = transformed
+		if err == nil && !transformed.Schema().Equal(r.hookSchemas[i]) {
+			err = fmt.Errorf("%s instead of %s: %w", transformed.Schema(), r.hookSchemas[i], ErrHookSchema)
+		}
+		if err != nil {
+			r.err = fmt.Errorf("read hook: %w", err)
+			return false
+		}
+	}
+	if r.fill != nil {
+		r.fill.collect(r.rec)
+	}
+	for i, column := range columns {
+		// the record holds the cast columns
+		if r.cast != nil && r.cast[i] {
+			column.Release()
+		}
+	}
+	return true
+}
+
+func (r *recordReader) Err() error {
+	if r.err != nil {
+		return r.err
+	}
+	return r.RecordReader.Err()
+}
+
+func (r *recordReader) Record() arrow.Record {
+	return r.rec
+}
+
+func (r *recordReader) Provenance() Provenance {
+	if r.rec == nil {
+		return Provenance{Version: r.version}
+	}
+	return Provenance{Version: r.version, Sources: record_reader.Sources(r.RecordReader)}
+}
+
+func (r *recordReader) Retain() {
+	atomic.AddInt64(&r.ref, 1)
+}
+
+func (r *recordReader) Release() {
+	if atomic.AddInt64(&r.ref, -1) == 0 {
+		if r.rec != nil {
+			r.rec.Release()
+			r.rec = nil
+		}
+		r.RecordReader.Release()
+		if r.fill != nil {
+			r.fill.discard()
+		}
+		if r.onRelease != nil {
+			r.onRelease()
+		}
+	}
+}
+
+// Read returns the next record, which is valid until the next call, and io.EOF after the last
+// one.
+func (r *recordReader) Read() (arrow.Record, error) {
+	if r.Next() {
+		return r.Record(), nil
+	}
+	if err := r.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// RecordsToTable reads the remaining records of reader into a table, which the caller must
+// release. The reader is not released.
+func RecordsToTable(reader array.RecordReader) (arrow.Table, error) {
+	var records []arrow.Record
+	defer func() {
+		for _, rec := range records {
+			rec.Release()
+		}
+	}()
+	for reader.Next() {
+		rec := reader.Record()
+		// the record may be released by the file reader, only its columns are retained
+		records = append(records, array.NewRecord(reader.Schema(), rec.Columns(), rec.NumRows()))
+	}
+	if err := reader.Err(); err != nil {
+		return nil, err
+	}
+	return array.NewTableFromRecords(reader.Schema(), records), nil
+}
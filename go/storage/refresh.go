@@ -0,0 +1,155 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/milvus-io/milvus-storage/go/common/log"
+	"github.com/milvus-io/milvus-storage/go/common/utils"
+	"github.com/milvus-io/milvus-storage/go/file/fragment"
+	"github.com/milvus-io/milvus-storage/go/storage/auth"
+	"github.com/milvus-io/milvus-storage/go/storage/manifest"
+	"github.com/milvus-io/milvus-storage/go/storage/options/option"
+)
+
+// Refresh loads the newest version committed, see RefreshContext.
+func (s *Space) Refresh() (option.VersionChange, error) {
+	return s.RefreshContext(context.Background())
+}
+
+// RefreshContext loads the newest version committed, e.g. by another process, so that later
+// operations see it. Replicas load the newest version replicated fully. The entries of the
+// blob and result caches implementing option.Invalidator that only older versions use are
+// dropped, and option.Options.OnVersionChange is called with the fragments added and removed.
+// Nothing changes if there is no newer version. ctx carries the caller identity, it requires
+// auth.OpRead.
+func (s *Space) RefreshContext(ctx context.Context) (option.VersionChange, error) {
+	if err := s.authorize(ctx, auth.OpRead); err != nil {
+		return option.VersionChange{}, err
+	}
+	current := s.snapshot()
+	unchanged := option.VersionChange{From: current.Version(), To: current.Version()}
+	var m *manifest.Manifest
+	if s.replica != nil {
+		var err error
+		if m, err = replicatedManifest(s.fs, s.path, -1); err != nil {
+			return option.VersionChange{}, err
+		}
+	} else {
+		version, err := latestVersion(s.fs, s.path)
+		if err != nil {
+			return option.VersionChange{}, err
+		}
+		if version <= current.Version() {
+			return unchanged, nil
+		}
+		if m, err = manifest.ParseFromFile(s.fs, utils.GetManifestFilePath(s.path, version)); err != nil {
+			return option.VersionChange{}, err
+		}
+	}
+	if m.DroppedAt() != 0 {
+		return option.VersionChange{}, fmt.Errorf("refresh space %s: %w", s.path, ErrSpaceDropped)
+	}
+	if err := checkReservedColumns(m); err != nil {
+		return option.VersionChange{}, fmt.Errorf("refresh space %s: %w", s.path, err)
+	}
+	if s.confined {
+		if err := checkConfined(m, s.path); err != nil {
+			return option.VersionChange{}, fmt.Errorf("refresh space %s: %w", s.path, err)
+		}
+	}
+
+	s.lock.Lock()
+	previous := s.manifest
+	if m.Version() <= previous.Version() {
+		s.lock.Unlock()
+		return unchanged, nil
+	}
+	s.manifest = m
+	s.nextManifestVersion = m.Version() + 1
+	s.lock.Unlock()
+
+	change := diffVersions(previous, m)
+	s.invalidateCaches(previous, m)
+	if s.onVersionChange != nil {
+		s.onVersionChange(change)
+	}
+	return change, nil
+}
+
+// Watch refreshes the space every interval until ctx is done, see RefreshContext. Failed
+// refreshes are logged and retried at the next interval. It returns the error of ctx.
+func (s *Space) Watch(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if _, err := s.RefreshContext(ctx); err != nil {
+				log.Warn("refresh space failed", log.String("path", s.path), log.String("err", err.Error()))
+			}
+		}
+	}
+}
+
+// diffVersions returns the fragments and blobs of from that to changed.
+func diffVersions(from, to *manifest.Manifest) option.VersionChange {
+	change := option.VersionChange{From: from.Version(), To: to.Version()}
+	change.AddedFragments = changedFragments(to.GetScalarFragments(), from.GetScalarFragments())
+	change.RemovedFragments = changedFragments(from.GetScalarFragments(), to.GetScalarFragments())
+	change.AddedDeleteFragments = changedFragments(to.GetDeleteFragments(), from.GetDeleteFragments())
+	change.RemovedDeleteFragments = changedFragments(from.GetDeleteFragments(), to.GetDeleteFragments())
+	for _, b := range from.GetBlobs() {
+		if replaced, ok := to.GetBlob(b.Name); !ok || replaced.File != b.File || len(replaced.Chunks) != len(b.Chunks) {
+			change.RemovedBlobs = append(change.RemovedBlobs, b.Name)
+		}
+	}
+	return change
+}
+
+// changedFragments returns the ids of the fragments that others does not hold with the same
+// files.
+func changedFragments(fragments, others fragment.FragmentVector) []int64 {
+	var ids []int64
+	for _, f := range fragments {
+		if !hasFragment(others, f) {
+			ids = append(ids, f.FragmentId())
+		}
+	}
+	return ids
+}
+
+// invalidateCaches drops the cached blob files that to no longer references, and the cached
+// results of the space for versions other than to.
+func (s *Space) invalidateCaches(from, to *manifest.Manifest) {
+	if cache, ok := s.blobCache.(option.Invalidator); ok {
+		referenced := make(map[string]bool)
+		for _, b := range to.GetBlobs() {
+			for _, file := range b.Files() {
+				referenced[file] = true
+			}
+		}
+		removed := make(map[string]bool)
+		for _, b := range from.GetBlobs() {
+			for _, file := range b.Files() {
+				if !referenced[file] {
+					removed[file] = true
+				}
+			}
+		}
+		if len(removed) > 0 {
+			cache.Invalidate(func(file string) bool { return removed[file] })
+		}
+	}
+	if cache, ok := s.resultCache.(option.Invalidator); ok {
+		// keys start with the path and version of the space, see resultKey
+		space, latest := s.path+"\x00", fmt.Sprintf("%s\x00%d\x00", s.path, to.Version())
+		cache.Invalidate(func(key string) bool {
+			return strings.HasPrefix(key, space) && !strings.HasPrefix(key, latest)
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/milvus-io/milvus-storage/go/common/errors"
+	"github.com/milvus-io/milvus-storage/go/common/log"
+	"github.com/milvus-io/milvus-storage/go/common/utils"
+	"github.com/milvus-io/milvus-storage/go/io/fs"
+	"github.com/milvus-io/milvus-storage/go/storage/auth"
+	"github.com/milvus-io/milvus-storage/go/storage/manifest"
+	"github.com/milvus-io/milvus-storage/go/storage/options/option"
+)
+
+var (
+	ErrReadOnlyReplica = errors.NewWithKind(errors.ErrPermissionDenied, "space opened as read replica")
+	ErrReplicaTooStale = errors.New("replica too stale")
+)
+
+// openReplica opens the space at path as a read replica, see option.ReplicaOptions.
+func openReplica(f fs.Fs, path string, op option.Options) (*Space, error) {
+	m, err := replicatedManifest(f, path, op.Version)
+	if err != nil {
+		return nil, err
+	}
+	if m.DroppedAt() != 0 {
+		return nil, fmt.Errorf("open space %s: %w", path, ErrSpaceDropped)
+	}
+	if err = checkReservedColumns(m); err != nil {
+		return nil, fmt.Errorf("open space %s: %w", path, err)
+	}
+	if op.ConfineToRoot {
+		if err = checkConfined(m, path); err != nil {
+			return nil, fmt.Errorf("open space %s: %w", path, err)
+		}
+	}
+	space := NewSpace(f, path, m, m.Version()+1)
+	space.confined = op.ConfineToRoot
+	space.txnMarkerDir = txnMarkerDir(op)
+	space.authorizer = op.Authorizer
+	space.masking = op.Masking
+	space.rowFilter = op.RowFilter
+	space.replica = op.Replica
+	space.blobCache = op.BlobCache
+	space.onVersionChange = op.OnVersionChange
+	if op.Replica.PrimaryURI != "" {
+		if space.primaryFs, space.primaryPath, err = buildFs(op.Replica.PrimaryURI); err != nil {
+			return nil, err
+		}
+	}
+
+	if op.Replica.MaxLag > 0 {
+		lag, err := space.replicaLag()
+		if err == nil && lag > op.Replica.MaxLag {
+			err = fmt.Errorf("open replica %s %d versions behind: %w", path, lag, ErrReplicaTooStale)
+		}
+		if err != nil {
+			if space.primaryFs != nil {
+				space.primaryFs.Close()
+			}
+			return nil, err
+		}
+	}
+	return space, nil
+}
+
+// replicatedManifest returns the given version, or the newest version if -1, among the
+// versions whose manifest and files are all present.
+func replicatedManifest(f fs.Fs, path string, version int64) (*manifest.Manifest, error) {
+	versions, err := manifestVersions(f, path)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(versions) - 1; i >= 0; i-- {
+		if version != -1 && versions[i] != version {
+			continue
+		}
+		m, err := manifest.ParseFromFile(f, utils.GetManifestFilePath(path, versions[i]))
+		if err != nil {
+			log.Debug("skip unreadable manifest", log.String("path", path), log.Int64("version", versions[i]), log.String("err", err.Error()))
+			continue
+		}
+		complete, err := filesExist(f, manifestFiles(m))
+		if err != nil {
+			return nil, err
+		}
+		if complete {
+			return m, nil
+		}
+		log.Debug("skip partially replicated version", log.String("path", path), log.Int64("version", versions[i]))
+	}
+	return nil, fmt.Errorf("open replica %s: %w", path, ErrManifestNotFound)
+}
+
+func filesExist(f fs.Fs, files []string) (bool, error) {
+	for _, file := range files {
+		exist, err := f.Exist(file)
+		if err != nil {
+			return false, fmt.Errorf("check %s: %w", file, err)
+		}
+		if !exist {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// ReplicaLag returns how many versions a space opened as replica is behind its primary, see
+// ReplicaLagContext.
+func (s *Space) ReplicaLag() (int64, error) {
+	return s.ReplicaLagContext(context.Background())
+}
+
+// ReplicaLagContext returns how many versions a space opened as replica is behind its primary,
+// or behind the newest manifest of the replica if the primary is unknown. It is 0 for spaces
+// not opened as replica. ctx carries the caller identity, it requires auth.OpRead.
+func (s *Space) ReplicaLagContext(ctx context.Context) (int64, error) {
+	if err := s.authorize(ctx, auth.OpRead); err != nil {
+		return 0, err
+	}
+	return s.replicaLag()
+}
+
+func (s *Space) replicaLag() (int64, error) {
+	if s.replica == nil {
+		return 0, nil
+	}
+	f, path := s.fs, s.path
+	if s.primaryFs != nil {
+		f, path = s.primaryFs, s.primaryPath
+	}
+	latest, err := latestVersion(f, path)
+	if err != nil {
+		return 0, fmt.Errorf("replica lag: %w", err)
+	}
+	if latest <= s.GetCurrentVersion() {
+		return 0, nil
+	}
+	return latest - s.GetCurrentVersion(), nil
+}
@@ -0,0 +1,231 @@
+package storage
+
+import (
+	"container/list"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/milvus-io/milvus-storage/go/filter"
+	"github.com/milvus-io/milvus-storage/go/storage/auth"
+	"github.com/milvus-io/milvus-storage/go/storage/manifest"
+	"github.com/milvus-io/milvus-storage/go/storage/options/option"
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	_ option.ResultCache = (*LRUResultCache)(nil)
+	_ option.Invalidator = (*LRUResultCache)(nil)
+)
+
+// LRUResultCache is a ResultCache holding records of up to a number of bytes, the least
+// recently used entries are evicted first. Results larger than the capacity are not cached.
+type LRUResultCache struct {
+	mu       sync.Mutex
+	capacity int64
+	size     int64
+	entries  map[string]*list.Element
+	order    *list.List
+	hits     int64
+	misses   int64
+}
+
+type resultEntry struct {
+	key     string
+	records []arrow.Record
+	size    int64
+}
+
+func NewLRUResultCache(capacity int64) *LRUResultCache {
+	return &LRUResultCache{
+		capacity: capacity,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+func (c *LRUResultCache) Get(key string) ([]arrow.Record, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		atomic.AddInt64(&c.misses, 1)
+		return nil, false
+	}
+	atomic.AddInt64(&c.hits, 1)
+	c.order.MoveToFront(e)
+	records := e.Value.(*resultEntry).records
+	for _, rec := range records {
+		rec.Retain()
+	}
+	return append([]arrow.Record(nil), records...), true
+}
+
+func (c *LRUResultCache) Add(key string, records []arrow.Record) {
+	var size int64
+	for _, rec := range records {
+		size += recordBytes(rec)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if size > c.capacity {
+		return
+	}
+	if e, ok := c.entries[key]; ok {
+		c.order.MoveToFront(e)
+		return
+	}
+	for _, rec := range records {
+		rec.Retain()
+	}
+	c.entries[key] = c.order.PushFront(&resultEntry{key: key, records: append([]arrow.Record(nil), records...), size: size})
+	c.size += size
+	for c.size > c.capacity {
+		oldest := c.order.Back()
+		entry := oldest.Value.(*resultEntry)
+		c.order.Remove(oldest)
+		delete(c.entries, entry.key)
+		c.size -= entry.size
+		for _, rec := range entry.records {
+			rec.Release()
+		}
+	}
+}
+
+func (c *LRUResultCache) Fits(size int64) bool {
+	return size <= c.capacity
+}
+
+// Invalidate drops the entries of the keys match reports.
+func (c *LRUResultCache) Invalidate(match func(key string) bool) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var n int
+	for key, e := range c.entries {
+		if !match(key) {
+			continue
+		}
+		entry := e.Value.(*resultEntry)
+		c.order.Remove(e)
+		delete(c.entries, key)
+		c.size -= entry.size
+		for _, rec := range entry.records {
+			rec.Release()
+		}
+		n++
+	}
+	return n
+}
+
+// Size returns the number of bytes cached.
+func (c *LRUResultCache) Size() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.size
+}
+
+// Stats returns the number of lookups that found an entry and of those that did not.
+func (c *LRUResultCache) Stats() (hits int64, misses int64) {
+	return atomic.LoadInt64(&c.hits), atomic.LoadInt64(&c.misses)
+}
+
+// resultKey returns the key of the records of a read of m with readOption, false if the read
+// cannot be cached, e.g. because a filter cannot be encoded or hooks transform the records.
+// Reads of a space with masking or row filters are keyed by caller too.
+func (s *Space) resultKey(ctx context.Context, m *manifest.Manifest, readOption *option.ReadOptions) (string, bool) {
+	if len(readOption.Hooks) > 0 {
+		return "", false
+	}
+	var key strings.Builder
+	fmt.Fprintf(&key, "%s\x00%d\x00%d\x00%q", s.path, m.Version(), readOption.GetVersion(), readOption.Columns)
+	for _, f := range readOption.FiltersV2 {
+		protoFilter, err := filter.ToProtobuf(f)
+		if err != nil {
+			return "", false
+		}
+		encoded, err := proto.MarshalOptions{Deterministic: true}.Marshal(protoFilter)
+		if err != nil {
+			return "", false
+		}
+		fmt.Fprintf(&key, "\x00%s", hex.EncodeToString(encoded))
+	}
+	for _, f := range readOption.ExpressionFilters {
+		fmt.Fprintf(&key, "\x00%s", f)
+	}
+	fmt.Fprintf(&key, "\x00%t\x00%+v\x00%d", readOption.IncludeDeleted, readOption.OrderBy, readOption.BatchSize)
+	if cast := readOption.CastSchema(); cast != nil {
+		fmt.Fprintf(&key, "\x00%s", cast)
+	}
+	if s.masking != nil || s.rowFilter != nil {
+		fmt.Fprintf(&key, "\x00%+v", auth.IdentityFromContext(ctx))
+	}
+	return key.String(), true
+}
+
+// cachedResult returns a reader of the cached records of key, false if there are none.
+func (s *Space) cachedResult(key string, version int64) (*recordReader, bool) {
+	records, ok := s.resultCache.Get(key)
+	if !ok || len(records) == 0 {
+		for _, rec := range records {
+			rec.Release()
+		}
+		return nil, false
+	}
+	reader, err := array.NewRecordReader(records[0].Schema(), records)
+	for _, rec := range records {
+		rec.Release()
+	}
+	if err != nil {
+		return nil, false
+	}
+	recordReader, err := newRecordReader(reader, nil, nil)
+	if err != nil {
+		reader.Release()
+		return nil, false
+	}
+	recordReader.version = version
+	recordReader.closed = &s.closed
+	return recordReader, true
+}
+
+// resultFill collects the records returned by a read to add them to the result cache once
+// the read reaches its end.
+type resultFill struct {
+	cache   option.ResultCache
+	key     string
+	records []arrow.Record
+	size    int64
+}
+
+func (f *resultFill) collect(rec arrow.Record) {
+	if f.cache == nil {
+		return
+	}
+	f.size += recordBytes(rec)
+	if !f.cache.Fits(f.size) {
+		f.discard()
+		return
+	}
+	rec.Retain()
+	f.records = append(f.records, rec)
+}
+
+// done adds the records collected to the cache, only reads returning records are cached.
+func (f *resultFill) done() {
+	if f.cache != nil && len(f.records) > 0 {
+		f.cache.Add(f.key, f.records)
+	}
+	f.discard()
+}
+
+func (f *resultFill) discard() {
+	for _, rec := range f.records {
+		rec.Release()
+	}
+	f.records, f.cache = nil, nil
+}
@@ -0,0 +1,192 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/milvus-io/milvus-storage/go/common/errors"
+	"github.com/milvus-io/milvus-storage/go/file/fragment"
+	"github.com/milvus-io/milvus-storage/go/io/fs"
+	"github.com/milvus-io/milvus-storage/go/reader/record_reader"
+	"github.com/milvus-io/milvus-storage/go/storage/auth"
+	"github.com/milvus-io/milvus-storage/go/storage/manifest"
+	"github.com/milvus-io/milvus-storage/go/storage/options/option"
+	"github.com/milvus-io/milvus-storage/go/storage/schema"
+)
+
+var ErrInvalidNumSplits = errors.New("invalid number of splits")
+
+// ScanTask is a share of a read planned by PlanScan, encoded with encoding/json to be sent to
+// the worker that reads it. Together the tasks of a plan read every data file of the planned
+// version once.
+type ScanTask struct {
+	Version   int64          `json:"version"`
+	Fragments []ScanFragment `json:"fragments"`
+	// Deletes are all the delete fragments of the version, every task applies them.
+	Deletes []ScanFiles `json:"deletes,omitempty"`
+}
+
+// ScanFragment is a subset of the files of a data fragment, the scalar and vector files at
+// the same position hold the same rows.
+type ScanFragment struct {
+	Scalar ScanFiles `json:"scalar"`
+	Vector ScanFiles `json:"vector"`
+}
+
+type ScanFiles struct {
+	ID    int64                `json:"id"`
+	Files []string             `json:"files"`
+	Stats []fragment.FileStats `json:"stats"`
+}
+
+// Fragment returns the fragment of the files, which can be read with fragment.NewReader.
+func (f ScanFiles) Fragment() fragment.Fragment {
+	frag := fragment.NewFragment(f.ID)
+	for i, file := range f.Files {
+		stats := fragment.UnknownFileStats
+		if i < len(f.Stats) {
+			stats = f.Stats[i]
+		}
+		frag.AddFileWithStats(file, stats)
+	}
+	return *frag
+}
+
+func newScanFiles(f fragment.Fragment) ScanFiles {
+	return ScanFiles{ID: f.FragmentId(), Files: f.Files(), Stats: f.FileStats()}
+}
+
+// NewReader reads the rows of the task with options, like Space.Read does for the whole
+// version: scalar and vector files are joined and deletes are applied. f and s are the file
+// system and schema of the space.
+func (t *ScanTask) NewReader(f fs.Fs, s *schema.Schema, options *option.ReadOptions) (RecordReader, error) {
+	m := manifest.NewManifest(s)
+	m.SetVersion(t.Version)
+	for _, frag := range t.Fragments {
+		m.AddDataFragment(manifest.DataFragment{Scalar: frag.Scalar.Fragment(), Vector: frag.Vector.Fragment()})
+	}
+	deleteFragments := make(fragment.DeleteFragmentVector, 0, len(t.Deletes))
+	for _, files := range t.Deletes {
+		deleteFragment, err := fragment.Make(f, s, files.Fragment())
+		if err != nil {
+			return nil, err
+		}
+		deleteFragments = append(deleteFragments, deleteFragment)
+	}
+	reader, err := record_reader.MakeRecordReader(m, s, f, deleteFragments, nil, options)
+	if err != nil {
+		return nil, err
+	}
+	recordReader, err := newRecordReader(reader, options.OutputColumns(), options.CastSchema())
+	if err != nil {
+		reader.Release()
+		return nil, err
+	}
+	recordReader.version = t.Version
+	return recordReader, nil
+}
+
+func (s *Space) PlanScan(readOption *option.ReadOptions, numSplits int) ([]ScanTask, error) {
+	return s.PlanScanContext(context.Background(), readOption, numSplits)
+}
+
+// PlanScanContext splits a read of the current version into at most numSplits tasks of
+// about the same number of rows, to be read by different workers with ScanTask.NewReader or,
+// fragment by fragment, with fragment.NewReader. Files are not split, so there are fewer
+// tasks than numSplits if there are fewer files. The options are checked as Read checks them.
+// Workers read the files directly, so the plan is denied to callers with masked columns or a
+// row filter. ctx carries the caller identity, it requires auth.OpRead.
+func (s *Space) PlanScanContext(ctx context.Context, readOption *option.ReadOptions, numSplits int) ([]ScanTask, error) {
+	if err := s.authorize(ctx, auth.OpRead); err != nil {
+		return nil, err
+	}
+	if numSplits <= 0 {
+		return nil, fmt.Errorf("plan scan into %d splits: %w", numSplits, ErrInvalidNumSplits)
+	}
+	identity := auth.IdentityFromContext(ctx)
+	if s.masking != nil && len(s.masking(identity)) > 0 {
+		return nil, errors.WithKind(errors.ErrPermissionDenied, fmt.Errorf("plan scan with masked columns for %q", identity.User))
+	}
+	if s.rowFilter != nil {
+		filters, err := s.rowFilter(identity)
+		if err != nil || len(filters) > 0 {
+			return nil, errors.WithKind(errors.ErrPermissionDenied, fmt.Errorf("plan scan with row filter for %q", identity.User))
+		}
+	}
+
+	m, err := s.readSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	// the options are checked by a reader that has no files to open
+	empty := m.Copy()
+	empty.SetScalarFragments(nil)
+	empty.SetVectorFragments(nil)
+	empty.SetDeleteFragments(nil)
+	reader, err := s.read(ctx, empty, readOption)
+	if err != nil {
+		return nil, err
+	}
+	reader.Release()
+
+	dataFragments, err := m.GetDataFragments()
+	if err != nil {
+		return nil, fmt.Errorf("plan scan: %w", err)
+	}
+	var deletes []ScanFiles
+	for _, f := range m.GetDeleteFragments() {
+		deletes = append(deletes, newScanFiles(f))
+	}
+	return splitFragments(dataFragments, numSplits, m.Version(), deletes), nil
+}
+
+// splitFragments assigns each file to the task where its first row falls when the rows are
+// divided evenly, files count as one row each if the rows of some file are unknown.
+func splitFragments(dataFragments []manifest.DataFragment, numSplits int, version int64, deletes []ScanFiles) []ScanTask {
+	known := true
+	var total int64
+	for _, f := range dataFragments {
+		stats := f.Scalar.Stats()
+		known = known && stats.Known()
+		total += stats.Rows
+	}
+	if !known || total == 0 {
+		known, total = false, 0
+		for _, f := range dataFragments {
+			total += int64(len(f.Scalar.Files()))
+		}
+	}
+
+	tasks := make([]ScanTask, numSplits)
+	var start int64
+	for _, f := range dataFragments {
+		for i := range f.Scalar.Files() {
+			task := &tasks[start*int64(numSplits)/total]
+			if n := len(task.Fragments); n == 0 || task.Fragments[n-1].Scalar.ID != f.Scalar.FragmentId() {
+				task.Fragments = append(task.Fragments, ScanFragment{
+					Scalar: ScanFiles{ID: f.Scalar.FragmentId()},
+					Vector: ScanFiles{ID: f.Vector.FragmentId()},
+				})
+			}
+			frag := &task.Fragments[len(task.Fragments)-1]
+			frag.Scalar.Files = append(frag.Scalar.Files, f.Scalar.Files()[i])
+			frag.Scalar.Stats = append(frag.Scalar.Stats, f.Scalar.FileStats()[i])
+			frag.Vector.Files = append(frag.Vector.Files, f.Vector.Files()[i])
+			frag.Vector.Stats = append(frag.Vector.Stats, f.Vector.FileStats()[i])
+			if known {
+				start += f.Scalar.FileStats()[i].Rows
+			} else {
+				start++
+			}
+		}
+	}
+
+	planned := make([]ScanTask, 0, numSplits)
+	for _, task := range tasks {
+		if len(task.Fragments) > 0 {
+			task.Version, task.Deletes = version, deletes
+			planned = append(planned, task)
+		}
+	}
+	return planned
+}
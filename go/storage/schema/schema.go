@@ -65,8 +65,18 @@ func (s *Schema) DeleteSchema() *arrow.Schema {
 	return s.deleteSchema
 }
 
+// FromProtobuf reads the IPC encoded schema, manifests written before it was stored only have
+// the mapped schema.
 func (s *Schema) FromProtobuf(schema *schema_proto.Schema) error {
-	schemaType, err := utils.FromProtobufSchema(schema.ArrowSchema)
+	var (
+		schemaType *arrow.Schema
+		err        error
+	)
+	if len(schema.GetArrowIpcSchema()) > 0 {
+		schemaType, err = utils.DeserializeSchema(schema.GetArrowIpcSchema())
+	} else {
+		schemaType, err = utils.FromProtobufSchema(schema.ArrowSchema)
+	}
 	if err != nil {
 		return err
 	}
@@ -81,11 +91,15 @@ func (s *Schema) FromProtobuf(schema *schema_proto.Schema) error {
 
 func (s *Schema) ToProtobuf() (*schema_proto.Schema, error) {
 	schema := &schema_proto.Schema{}
-	arrowSchema, err := utils.ToProtobufSchema(s.schema)
+	ipcSchema, err := utils.SerializeSchema(s.schema)
 	if err != nil {
 		return nil, err
 	}
-	schema.ArrowSchema = arrowSchema
+	schema.ArrowIpcSchema = ipcSchema
+	// older readers only read the mapped schema, which does not support every type
+	if arrowSchema, err := utils.ToProtobufSchema(s.schema); err == nil {
+		schema.ArrowSchema = arrowSchema
+	}
 	schema.SchemaOptions = s.options.ToProtobuf()
 	return schema, nil
 }
@@ -93,7 +107,7 @@ func (s *Schema) ToProtobuf() (*schema_proto.Schema, error) {
 func (s *Schema) BuildScalarSchema() error {
 	fields := make([]arrow.Field, 0, len(s.schema.Fields()))
 	for _, field := range s.schema.Fields() {
-		if field.Name == s.options.VectorColumn {
+		if s.options.InVectorGroup(field.Name) {
 			continue
 		}
 		fields = append(fields, field)
@@ -108,7 +122,7 @@ func (s *Schema) BuildScalarSchema() error {
 func (s *Schema) BuildVectorSchema() error {
 	fields := make([]arrow.Field, 0, len(s.schema.Fields()))
 	for _, field := range s.schema.Fields() {
-		if field.Name == s.options.VectorColumn ||
+		if s.options.InVectorGroup(field.Name) ||
 			field.Name == s.options.PrimaryColumn ||
 			field.Name == s.options.VersionColumn {
 			fields = append(fields, field)
@@ -126,7 +140,7 @@ func (s *Schema) BuildDeleteSchema() error {
 	}
 	versionField, ok := s.schema.FieldsByName(s.options.VersionColumn)
 	if !ok {
-		return schema_option.ErrPrimaryColumnNotFound
+		return schema_option.ErrVersionColumnNotFound
 	}
 	fields := make([]arrow.Field, 0, 2)
 	fields = append(fields, pkColumn[0])
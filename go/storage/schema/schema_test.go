@@ -38,3 +38,186 @@ func TestBuildSchema(t *testing.T) {
 	err := sc.Validate()
 	assert.NoError(t, err)
 }
+
+func TestColumnGroups(t *testing.T) {
+	as := arrow.NewSchema([]arrow.Field{
+		{Name: "pk_field", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "vs_field", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "doc", Type: arrow.BinaryTypes.String},
+		{Name: "age", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "vec_field", Type: &arrow.FixedSizeBinaryType{ByteWidth: 16}},
+	}, nil)
+	fieldNames := func(s *arrow.Schema) []string {
+		var names []string
+		for _, f := range s.Fields() {
+			names = append(names, f.Name)
+		}
+		return names
+	}
+
+	sc := NewSchema(as, &schema_option.SchemaOptions{
+		PrimaryColumn:      "pk_field",
+		VersionColumn:      "vs_field",
+		VectorColumn:       "vec_field",
+		VectorGroupColumns: []string{"doc"},
+	})
+	assert.NoError(t, sc.Validate())
+	assert.Equal(t, []string{"pk_field", "vs_field", "age", "__offset"}, fieldNames(sc.ScalarSchema()))
+	assert.Equal(t, []string{"pk_field", "vs_field", "doc", "vec_field"}, fieldNames(sc.VectorSchema()))
+
+	restored := NewSchema(nil, schema_option.Init())
+	pb, err := sc.ToProtobuf()
+	assert.NoError(t, err)
+	assert.NoError(t, restored.FromProtobuf(pb))
+	assert.Equal(t, fieldNames(sc.VectorSchema()), fieldNames(restored.VectorSchema()))
+
+	sc = NewSchema(as, &schema_option.SchemaOptions{
+		PrimaryColumn:     "pk_field",
+		VersionColumn:     "vs_field",
+		VectorColumn:      "vec_field",
+		ColumnGroupPolicy: schema_option.GroupTogether,
+	})
+	assert.NoError(t, sc.Validate())
+	assert.Equal(t, []string{"pk_field", "vs_field", "doc", "age", "vec_field", "__offset"}, fieldNames(sc.ScalarSchema()))
+	assert.Equal(t, []string{"pk_field", "vs_field"}, fieldNames(sc.VectorSchema()))
+
+	for _, columns := range [][]string{{"missing"}, {"pk_field"}, {"doc", "doc"}} {
+		sc = NewSchema(as, &schema_option.SchemaOptions{
+			PrimaryColumn:      "pk_field",
+			VersionColumn:      "vs_field",
+			VectorColumn:       "vec_field",
+			VectorGroupColumns: columns,
+		})
+		assert.ErrorIs(t, sc.Validate(), schema_option.ErrInvalidColumnGroup)
+	}
+}
+
+func TestAutoID(t *testing.T) {
+	for _, pkType := range []arrow.DataType{arrow.PrimitiveTypes.Int64, arrow.BinaryTypes.String} {
+		as := arrow.NewSchema([]arrow.Field{
+			{Name: "pk_field", Type: pkType},
+			{Name: "vs_field", Type: arrow.PrimitiveTypes.Int64},
+			{Name: "vec_field", Type: &arrow.FixedSizeBinaryType{ByteWidth: 16}},
+		}, nil)
+		sc := NewSchema(as, &schema_option.SchemaOptions{
+			PrimaryColumn: "pk_field",
+			VersionColumn: "vs_field",
+			VectorColumn:  "vec_field",
+			AutoID:        true,
+		})
+		if pkType.ID() == arrow.INT64 {
+			assert.NoError(t, sc.Validate())
+		} else {
+			assert.ErrorIs(t, sc.Validate(), schema_option.ErrAutoIDType)
+		}
+	}
+}
+
+func TestSchemaProtobufRoundTrip(t *testing.T) {
+	schemaMetadata := arrow.NewMetadata([]string{"owner"}, []string{"search"})
+	as := arrow.NewSchema([]arrow.Field{
+		{Name: "pk_field", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "vs_field", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "created", Type: &arrow.TimestampType{Unit: arrow.Millisecond, TimeZone: "UTC"}, Nullable: true},
+		{Name: "tags", Type: arrow.ListOf(arrow.StructOf(
+			arrow.Field{Name: "key", Type: arrow.BinaryTypes.String},
+			arrow.Field{Name: "weight", Type: arrow.PrimitiveTypes.Float32, Nullable: true},
+		)), Nullable: true},
+		{Name: "vec_field", Type: &arrow.FixedSizeBinaryType{ByteWidth: 16},
+			Metadata: arrow.NewMetadata([]string{"model"}, []string{"text-embedding"})},
+	}, &schemaMetadata)
+	options := &schema_option.SchemaOptions{
+		PrimaryColumn: "pk_field",
+		VersionColumn: "vs_field",
+		VectorColumn:  "vec_field",
+	}
+	sc := NewSchema(as, options)
+	assert.NoError(t, sc.Validate())
+
+	pb, err := sc.ToProtobuf()
+	assert.NoError(t, err)
+	assert.NotEmpty(t, pb.GetArrowIpcSchema())
+	restored := NewSchema(nil, schema_option.Init())
+	assert.NoError(t, restored.FromProtobuf(pb))
+	assert.True(t, as.Equal(restored.Schema()))
+	assert.True(t, schemaMetadata.Equal(restored.Schema().Metadata()))
+
+	// manifests written before the IPC schema was stored
+	legacy := arrow.NewSchema([]arrow.Field{
+		{Name: "pk_field", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "vs_field", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "vec_field", Type: &arrow.FixedSizeBinaryType{ByteWidth: 16}},
+	}, nil)
+	pb, err = NewSchema(legacy, options).ToProtobuf()
+	assert.NoError(t, err)
+	assert.NotNil(t, pb.GetArrowSchema())
+	pb.ArrowIpcSchema = nil
+	restored = NewSchema(nil, schema_option.Init())
+	assert.NoError(t, restored.FromProtobuf(pb))
+	assert.True(t, legacy.Equal(restored.Schema()))
+}
+
+func TestStorageProfileValidation(t *testing.T) {
+	as := arrow.NewSchema([]arrow.Field{
+		{Name: "pk_field", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "vs_field", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "str_field", Type: arrow.BinaryTypes.String},
+		{Name: "vec_field", Type: &arrow.FixedSizeBinaryType{ByteWidth: 16}},
+	}, nil)
+	validate := func(profiles map[string]schema_option.StorageProfile) error {
+		return NewSchema(as, &schema_option.SchemaOptions{
+			PrimaryColumn:   "pk_field",
+			VersionColumn:   "vs_field",
+			VectorColumn:    "vec_field",
+			StorageProfiles: profiles,
+		}).Validate()
+	}
+
+	assert.NoError(t, validate(map[string]schema_option.StorageProfile{
+		"pk_field":  {Codec: schema_option.CodecZstd, Level: 19, Encoding: schema_option.EncodingDeltaBinaryPacked},
+		"str_field": {Codec: schema_option.CodecGzip, Encoding: schema_option.EncodingDeltaByteArray},
+		"vec_field": {Codec: schema_option.CodecSnappy, Encoding: schema_option.EncodingPlain},
+	}))
+	for _, profiles := range []map[string]schema_option.StorageProfile{
+		{"missing": {Codec: schema_option.CodecZstd}},
+		{"pk_field": {Codec: schema_option.CodecZstd + 1}},
+		{"pk_field": {Codec: schema_option.CodecSnappy, Level: 3}},
+		{"str_field": {Encoding: schema_option.EncodingDeltaBinaryPacked}},
+		{"pk_field": {Encoding: schema_option.EncodingDeltaLengthByteArray}},
+	} {
+		assert.ErrorIs(t, validate(profiles), schema_option.ErrInvalidStorageProfile)
+	}
+}
+
+func TestValidateErrors(t *testing.T) {
+	pk := arrow.Field{Name: "pk_field", Type: arrow.PrimitiveTypes.Int64}
+	vs := arrow.Field{Name: "vs_field", Type: arrow.PrimitiveTypes.Int64}
+	vec := arrow.Field{Name: "vec_field", Type: arrow.FixedSizeListOf(8, arrow.PrimitiveTypes.Float32)}
+	options := func() *schema_option.SchemaOptions {
+		return &schema_option.SchemaOptions{PrimaryColumn: "pk_field", VersionColumn: "vs_field", VectorColumn: "vec_field"}
+	}
+	tests := []struct {
+		fields  []arrow.Field
+		options *schema_option.SchemaOptions
+		err     error
+		column  string
+	}{
+		{[]arrow.Field{pk, vs, vec, {Name: "__offset", Type: arrow.PrimitiveTypes.Int64}}, options(), schema_option.ErrReservedColumn, "__offset"},
+		{[]arrow.Field{pk, vs, vec, {Name: "__custom", Type: arrow.PrimitiveTypes.Int64}}, options(), schema_option.ErrReservedColumn, "__custom"},
+		{[]arrow.Field{pk, vs, vec, vs}, options(), schema_option.ErrDuplicateColumn, "vs_field"},
+		{[]arrow.Field{{Name: "pk_field", Type: arrow.PrimitiveTypes.Float64}, vs, vec}, options(), schema_option.ErrPrimaryColumnType, "pk_field"},
+		{[]arrow.Field{pk, {Name: "vs_field", Type: arrow.BinaryTypes.String}, vec}, options(), schema_option.ErrVersionColumnType, "vs_field"},
+		{[]arrow.Field{pk, vec}, options(), schema_option.ErrVersionColumnNotFound, "vs_field"},
+		{[]arrow.Field{pk, vs, {Name: "vec_field", Type: arrow.FixedSizeListOf(8, arrow.BinaryTypes.String)}}, options(), schema_option.ErrVectorColumnType, "vec_field"},
+		{[]arrow.Field{pk, vs, vec}, &schema_option.SchemaOptions{PrimaryColumn: "pk_field", VersionColumn: "pk_field", VectorColumn: "vec_field"}, schema_option.ErrColumnReused, "pk_field"},
+		{[]arrow.Field{pk, vs, vec}, &schema_option.SchemaOptions{PrimaryColumn: "pk_field", VersionColumn: "vs_field", VectorColumn: "vec_field", VectorColumns: []string{"bin_field"}}, schema_option.ErrVectorColumnNotFound, "bin_field"},
+		{[]arrow.Field{pk, vs, vec}, &schema_option.SchemaOptions{PrimaryColumn: "pk_field", VersionColumn: "vs_field", VectorColumn: "vec_field", VectorColumns: []string{"vs_field"}}, schema_option.ErrVectorColumnType, "vs_field"},
+		{[]arrow.Field{pk, vs, vec}, &schema_option.SchemaOptions{PrimaryColumn: "pk_field", VersionColumn: "vs_field", VectorColumn: "vec_field", VectorColumns: []string{"vec_field"}}, schema_option.ErrColumnReused, "vec_field"},
+	}
+	for _, test := range tests {
+		err := NewSchema(arrow.NewSchema(test.fields, nil), test.options).Validate()
+		assert.ErrorIs(t, err, test.err)
+		assert.ErrorContains(t, err, test.column)
+	}
+	assert.NoError(t, NewSchema(arrow.NewSchema([]arrow.Field{pk, vs, vec}, nil), options()).Validate())
+}
@@ -0,0 +1,41 @@
+package storage
+
+import (
+	"time"
+
+	"github.com/milvus-io/milvus-storage/go/common/log"
+	"github.com/milvus-io/milvus-storage/go/storage/options/option"
+	"go.uber.org/zap"
+)
+
+// logSlow logs op if it started longer than threshold ago, a zero threshold disables it.
+func (s *Space) logSlow(op string, threshold time.Duration, start time.Time, fields ...zap.Field) {
+	duration := time.Since(start)
+	if threshold <= 0 || duration <= threshold {
+		return
+	}
+	fields = append([]zap.Field{log.String("path", s.path), log.String("op", op), log.Duration("duration", duration)}, fields...)
+	log.Warn("slow operation", fields...)
+}
+
+func progressFields(progress option.Progress) []zap.Field {
+	return []zap.Field{
+		log.Int64("rows", progress.Rows),
+		log.Int64("bytes", progress.Bytes),
+		log.Int64("files", progress.Files),
+	}
+}
+
+// slowReadOptions returns options that also record the progress of the read in progress,
+// the progress callback of readOption is still called.
+func slowReadOptions(readOption *option.ReadOptions, progress *option.Progress) *option.ReadOptions {
+	readOption = readOption.Clone()
+	report := readOption.Progress
+	readOption.Progress = func(p option.Progress) {
+		*progress = p
+		if report != nil {
+			report(p)
+		}
+	}
+	return readOption
+}
@@ -1,81 +1,371 @@
 package storage
 
 import (
-	"errors"
+	"context"
 	"fmt"
+	"io"
 	"math"
-	"net/url"
-	"path/filepath"
-	"sort"
+	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/apache/arrow/go/v12/arrow"
 	"github.com/apache/arrow/go/v12/arrow/array"
 	"github.com/apache/arrow/go/v12/arrow/memory"
+	"github.com/google/uuid"
+	"github.com/milvus-io/milvus-storage/go/common/constant"
+	"github.com/milvus-io/milvus-storage/go/common/errors"
 	"github.com/milvus-io/milvus-storage/go/common/log"
 	"github.com/milvus-io/milvus-storage/go/common/utils"
 	"github.com/milvus-io/milvus-storage/go/file/blob"
 	"github.com/milvus-io/milvus-storage/go/file/fragment"
+	"github.com/milvus-io/milvus-storage/go/file/layout"
 	"github.com/milvus-io/milvus-storage/go/filter"
 	"github.com/milvus-io/milvus-storage/go/io/format"
 	"github.com/milvus-io/milvus-storage/go/io/format/parquet"
 	"github.com/milvus-io/milvus-storage/go/io/fs"
 	"github.com/milvus-io/milvus-storage/go/reader/record_reader"
+	"github.com/milvus-io/milvus-storage/go/storage/auth"
 	"github.com/milvus-io/milvus-storage/go/storage/manifest"
 	"github.com/milvus-io/milvus-storage/go/storage/options/option"
+	"github.com/milvus-io/milvus-storage/go/storage/options/schema_option"
+	"github.com/milvus-io/milvus-storage/go/storage/schema"
 )
 
 var (
 	ErrSchemaIsNil      = errors.New("schema is nil")
-	ErrManifestNotFound = errors.New("manifest not found")
-	ErrBlobAlreadyExist = errors.New("blob already exist")
-	ErrBlobNotExist     = errors.New("blob not exist")
+	ErrManifestNotFound = errors.NewWithKind(errors.ErrNotFound, "manifest not found")
+	ErrBlobAlreadyExist = errors.NewWithKind(errors.ErrConflict, "blob already exist")
+	ErrBlobNotExist     = errors.NewWithKind(errors.ErrNotFound, "blob not exist")
+	ErrBlobCorrupted    = errors.NewWithKind(errors.ErrChecksumMismatch, "blob content does not match its checksum")
 	ErrSchemaNotMatch   = errors.New("schema not match")
 	ErrColumnNotExist   = errors.New("column not exist")
+	ErrLeaseHeld        = errors.NewWithKind(errors.ErrConflict, "lease held by another writer")
+	// ErrManifestConflict is returned by commits when another process already committed the
+	// same version. The space must be reopened to see the other commit before retrying.
+	ErrManifestConflict = errors.NewWithKind(errors.ErrConflict, "manifest version already committed")
+	ErrQuotaExceeded    = errors.NewWithKind(errors.ErrResourceExhausted, "quota exceeded")
+	// ErrAutoVersionConflict is returned by writes filling the version column with the version
+	// they commit when another commit took that version meanwhile.
+	ErrAutoVersionConflict = errors.NewWithKind(errors.ErrConflict, "version filled in by the write committed by another")
+	// ErrSpaceClosed is returned by the operations of a closed space and by the readers it
+	// returned.
+	ErrSpaceClosed = errors.New("space closed")
 )
 
+// Space is safe for concurrent use by multiple goroutines. Every operation works on a
+// snapshot of the manifest taken when it starts, so readers never observe a partially
+// applied commit. Commits are serialized by lock: manifest and nextManifestVersion are
+// only read or written while holding it.
 type Space struct {
 	path                string
 	fs                  fs.Fs
-	deleteFragments     fragment.DeleteFragmentVector
 	manifest            *manifest.Manifest
 	lock                sync.RWMutex
 	nextManifestVersion int64
-}
-
-func (s *Space) init() error {
-	for _, f := range s.manifest.GetDeleteFragments() {
-		deleteFragment := fragment.Make(s.fs, s.manifest.GetSchema(), f)
-		s.deleteFragments = append(s.deleteFragments, deleteFragment)
-	}
-	return nil
+	lease               option.Lease
+	quota               *option.QuotaOptions
+	authorizer          auth.Authorizer
+	auditSink           option.AuditSink
+	masking             option.MaskingPolicy
+	rowFilter           option.RowFilterPolicy
+	mirror              *mirror
+	replica             *option.ReplicaOptions
+	primaryFs           fs.Fs
+	primaryPath         string
+	blobCodec           blob.Codec
+	blobCache           option.BlobCache
+	resultCache         option.ResultCache
+	writeHooks          []option.WriteHook
+	liveBitmaps         bool
+	durability          option.Durability
+	slowLog             option.SlowLogOptions
+	staging             string
+	onVersionChange     func(option.VersionChange)
+	commitQueue         *commitQueue
+	naming              option.NamingOptions
+	// confined checks that the manifests loaded only reference files in the space
+	confined bool
+	// ownsFs tells whether fs was built for the space, and is closed with it.
+	ownsFs bool
+	closed int32
+	// readMemory and writeMemory count the buffers allocated by reads and writes, memoryLimit,
+	// if set, fails when the Manager of the space is over its memory limit.
+	readMemory  *countingAllocator
+	writeMemory *countingAllocator
+	memoryLimit func() error
+	// deleteCache holds the loaded delete fragments by id, guarded by deleteLock.
+	deleteLock  sync.Mutex
+	deleteCache map[int64]fragment.DeleteFragment
+	// nextAutoID is the next primary key generated by the writes of the space, guarded by
+	// autoIDLock.
+	autoIDLock sync.Mutex
+	nextAutoID int64
+	// bitmapCache holds the loaded deleted rows by sidecar path, guarded by bitmapLock.
+	bitmapLock  sync.Mutex
+	bitmapCache map[string]cachedBitmap
+	// txnMarkerDir holds the markers of the transactions of the space, see Transaction
+	txnMarkerDir string
+	// committedTxns holds the transactions whose marker was seen, guarded by txnLock.
+	txnLock       sync.Mutex
+	committedTxns map[string]bool
 }
 
 func NewSpace(f fs.Fs, path string, m *manifest.Manifest, nv int64) *Space {
-	deleteFragments := fragment.DeleteFragmentVector{}
 	return &Space{
 		fs:                  f,
 		path:                path,
 		manifest:            m,
 		nextManifestVersion: nv,
-		deleteFragments:     deleteFragments,
+		deleteCache:         make(map[int64]fragment.DeleteFragment),
+		readMemory:          &countingAllocator{},
+		writeMemory:         &countingAllocator{},
 	}
 }
 
-func (s *Space) Write(reader array.RecordReader, options *option.WriteOptions) error {
-	// check schema consistency
-	if !s.manifest.GetSchema().Schema().Equal(reader.Schema()) {
-		return ErrSchemaNotMatch
+// deleteFragments loads the delete fragments of m. Committed delete fragments never change, so
+// they are cached until a manifest without them is read.
+func (s *Space) deleteFragments(m *manifest.Manifest) (fragment.DeleteFragmentVector, error) {
+	s.deleteLock.Lock()
+	cached := s.deleteCache
+	s.deleteLock.Unlock()
+
+	loaded := make(map[int64]fragment.DeleteFragment, len(m.GetDeleteFragments()))
+	deleteFragments := make(fragment.DeleteFragmentVector, 0, len(m.GetDeleteFragments()))
+	for _, f := range m.GetDeleteFragments() {
+		deleteFragment, ok := cached[f.FragmentId()]
+		if !ok {
+			var err error
+			if deleteFragment, err = fragment.Make(s.fs, m.GetSchema(), f); err != nil {
+				return nil, err
+			}
+		}
+		loaded[f.FragmentId()] = deleteFragment
+		deleteFragments = append(deleteFragments, deleteFragment)
 	}
 
-	scalarSchema, vectorSchema := s.manifest.GetSchema().ScalarSchema(), s.manifest.GetSchema().VectorSchema()
+	s.deleteLock.Lock()
+	s.deleteCache = loaded
+	s.deleteLock.Unlock()
+	return deleteFragments, nil
+}
+
+// snapshot returns the current manifest. The returned manifest is never modified, commits
+// replace it with a new copy.
+func (s *Space) snapshot() *manifest.Manifest {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.manifest
+}
+
+// newFilePath returns the path of a new parquet file in dir, named by the naming options of
+// the space and placed by the layout of its current version.
+func (s *Space) newFilePath(dir string) (string, error) {
+	id := uuid.New().String()
+	if s.naming.Strategy == option.NamingTimeSorted {
+		id = utils.NewTimeSortedID()
+	}
+	name := s.naming.Prefix + id
+	dir, err := s.snapshot().Layout().Dir(dir, name, time.Now())
+	if err != nil {
+		return "", err
+	}
+	return utils.GetNamedParquetFilePath(dir, name), nil
+}
+
+// opFs returns the file system of an operation retrying its calls with retry, if not nil.
+func (s *Space) opFs(retry *option.RetryOptions) fs.Fs {
+	if retry == nil {
+		return s.fs
+	}
+	return fs.NewRetryFs(s.fs, retry)
+}
+
+// commit applies update to a copy of the latest manifest, persists it as the next version
+// and makes it visible. update is called with the lock held and must not block. record
+// describes the operation for the audit sink, commit fills in who, when and the version.
+func (s *Space) commit(ctx context.Context, record *option.AuditRecord, update func(m *manifest.Manifest, version int64) error) error {
+	start := time.Now()
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	nextVersion := s.nextManifestVersion
+	defer s.logSlow("commit", s.slowLog.Commit, start, log.String("operation", record.Operation.String()),
+		log.Int64("version", nextVersion), log.Int64("rows", record.Rows), log.Int("files", len(record.Files)))
+	log.Debug("commit", log.Int64("current version", s.manifest.Version()), log.Int64("next version", nextVersion))
+
+	if s.lease != nil {
+		if err := s.lease.Acquire(); err != nil {
+			return err
+		}
+	}
+
+	copied := s.manifest.Copy()
+	copied.SetVersion(nextVersion)
+	// only the column statistics outlive the version they are committed with
+	copied.SetProperties(statsProperties(s.manifest.Properties()))
+	if err := update(copied, nextVersion); err != nil {
+		return err
+	}
+	if err := copied.ValidateDataFragments(); err != nil {
+		return fmt.Errorf("commit version %d: %w", nextVersion, err)
+	}
+	if s.durability == option.DurabilitySync {
+		if err := fs.Sync(s.fs, record.Files...); err != nil {
+			return fmt.Errorf("commit version %d: %w", nextVersion, err)
+		}
+	}
+	if err := safeSaveManifest(s.fs, s.path, copied, s.durability == option.DurabilitySync); err != nil {
+		return err
+	}
+	s.manifest = copied
+	s.nextManifestVersion++
+	s.audit(ctx, record, nextVersion)
+	if s.mirror != nil {
+		s.mirror.enqueue(copied)
+	}
+	if s.liveBitmaps {
+		s.refreshLiveBitmaps(copied)
+	}
+	return nil
+}
+
+func (s *Space) audit(ctx context.Context, record *option.AuditRecord, version int64) {
+	if s.auditSink == nil {
+		return
+	}
+	record.Time = time.Now()
+	record.User = auth.IdentityFromContext(ctx).User
+	record.Path = s.path
+	record.Version = version
+	if err := s.auditSink.Record(record); err != nil {
+		log.Error("record audit failed", log.String("path", s.path), log.Int64("version", version), log.String("err", err.Error()))
+	}
+}
+
+// CommitResult describes what an operation committed, so that callers can record where their
+// data went, e.g. to make a pipeline exactly-once, without reading the manifest again.
+type CommitResult struct {
+	// Version is the version committed, or the current version if there was nothing to commit.
+	Version int64
+	// FragmentIDs are the ids of the data or delete fragments added, blobs have none.
+	FragmentIDs []int64
+	// Files are the data, delete or blob files written.
+	Files []string
+	Rows  int64
+	Bytes int64
+}
+
+func (s *Space) Write(reader array.RecordReader, options *option.WriteOptions) (CommitResult, error) {
+	return s.WriteContext(context.Background(), reader, options)
+}
+
+// WriteContext is like Write, ctx carries the caller identity checked by the authorizer.
+func (s *Space) WriteContext(ctx context.Context, reader array.RecordReader, options *option.WriteOptions) (CommitResult, error) {
+	if err := s.authorize(ctx, auth.OpWrite); err != nil {
+		return CommitResult{}, err
+	}
+	return s.writeFragment(ctx, reader, options, "")
+}
+
+// writeFragment writes the rows of reader to a new data fragment of the transaction whose
+// marker is txn, if any, and commits it.
+func (s *Space) writeFragment(ctx context.Context, reader array.RecordReader, options *option.WriteOptions, txn string) (CommitResult, error) {
+	if err := s.checkMemory("write"); err != nil {
+		return CommitResult{}, err
+	}
+	m := s.snapshot()
+	w, err := s.newFragmentWrite(m, reader.Schema(), options)
+	if err != nil {
+		return CommitResult{}, err
+	}
+	defer func(start time.Time) {
+		s.logSlow("write", s.slowLog.Write, start, progressFields(*w.progress)...)
+	}(time.Now())
+
+	written, err := w.write(reader)
+	if err != nil {
+		return CommitResult{}, err
+	}
+	reportWriteProgress(options, w.progress, nil, nil)
+	return w.commit(ctx, []writtenFragment{written}, txn)
+}
+
+// fragmentWrite writes data fragments of a version of the space to commit together.
+type fragmentWrite struct {
+	s       *Space
+	m       *manifest.Manifest
+	options *option.WriteOptions
+	// autoID tells whether the records omit the primary column
+	autoID bool
+	// versionValue fills the version column with AutoVersion, autoVersion is the version the
+	// write must commit if it is filled with it
+	versionValue int64
+	autoVersion  int64
+	// keys is shared by the fragments so that duplicates are rejected across them
+	keys      *keyTracker
+	progress  *option.Progress
+	autoIDEnd int64
+	// fs writes the files, with the retries of options if any
+	fs fs.Fs
+}
+
+// writtenFragment is a data fragment written but not committed yet.
+type writtenFragment struct {
+	scalar *fragment.Fragment
+	vector *fragment.Fragment
+}
+
+// newFragmentWrite checks that records of schema written can be written to the space at m with
+// options.
+func (s *Space) newFragmentWrite(m *manifest.Manifest, written *arrow.Schema, options *option.WriteOptions) (*fragmentWrite, error) {
+	autoID, err := checkWriteSchema(m.GetSchema(), written, options)
+	if err != nil {
+		return nil, err
+	}
+	w := &fragmentWrite{s: s, m: m, options: options, autoID: autoID, versionValue: options.VersionTimestamp, progress: &option.Progress{}, fs: s.opFs(options.Retry)}
+	if options.AutoVersion && w.versionValue == 0 {
+		s.lock.RLock()
+		w.autoVersion = s.nextManifestVersion
+		s.lock.RUnlock()
+		w.versionValue = w.autoVersion
+	}
+	// generated keys are unique
+	if !autoID && options.Duplicates == option.DuplicatesReject {
+		w.keys = newKeyTracker(m.GetSchema().Options().PrimaryColumn)
+	}
+	return w, nil
+}
+
+// write writes the rows of reader to a new data fragment. Duplicates of option.DuplicatesLastWins
+// are only dropped among the rows of reader.
+// The files written are removed if the write fails.
+func (w *fragmentWrite) write(reader array.RecordReader) (_ writtenFragment, err error) {
+	s, m, options, progress := w.s, w.m, w.options, w.progress
+	if !w.autoID && options.Duplicates == option.DuplicatesLastWins {
+		deduped, err := lastWins(reader, m.GetSchema().Options().PrimaryColumn)
+		if err != nil {
+			return writtenFragment{}, err
+		}
+		defer deduped.Release()
+		reader = deduped
+	}
+
+	scalarSchema, vectorSchema := m.GetSchema().ScalarSchema(), m.GetSchema().VectorSchema()
 	var (
 		scalarWriter format.Writer
 		vectorWriter format.Writer
 	)
-	scalarFragment := fragment.NewFragment(s.manifest.Version())
-	vectorFragment := fragment.NewFragment(s.manifest.Version())
+	scalarFragment := fragment.NewFragment(m.Version())
+	vectorFragment := fragment.NewFragment(m.Version())
+	defer func() {
+		if err != nil {
+			abortWriter(scalarWriter)
+			abortWriter(vectorWriter)
+			w.discard(writtenFragment{scalar: scalarFragment, vector: vectorFragment})
+		}
+	}()
 
 	for reader.Next() {
 		rec := reader.Record()
@@ -83,62 +373,305 @@ func (s *Space) Write(reader array.RecordReader, options *option.WriteOptions) e
 		if rec.NumRows() == 0 {
 			continue
 		}
-		var err error
-		scalarWriter, err = s.write(scalarSchema, rec, scalarWriter, scalarFragment, options, true)
+		generated := make(map[string]arrow.Array)
+		if w.autoID {
+			generated[m.GetSchema().Options().PrimaryColumn], w.autoIDEnd = s.nextAutoIDs(rec.NumRows())
+		}
+		if options.AutoVersion {
+			generated[m.GetSchema().Options().VersionColumn] = constantColumn(w.versionValue, rec.NumRows())
+		}
+		rec, err := s.applyWriteHooks(m.GetSchema(), withColumns(m.GetSchema(), rec, generated))
+		for _, column := range m.GetSchema().Options().AllVectorColumns() {
+			if err == nil {
+				err = validateVectors(rec, column, progress.Rows, options)
+			}
+		}
+		if err == nil && w.keys != nil {
+			err = w.keys.check(rec, progress.Rows)
+		}
 		if err != nil {
-			return err
+			if rec != nil {
+				rec.Release()
+			}
+			return writtenFragment{}, err
+		}
+		if options.MemoryBudget > 0 && s.stagingDir(options) == "" && recordBytes(rec) > options.MemoryBudget {
+			options = spillOptions(options)
 		}
-		vectorWriter, err = s.write(vectorSchema, rec, vectorWriter, vectorFragment, options, false)
+		for _, part := range splitRecord(rec, options.MemoryBudget) {
+			if err == nil {
+				scalarWriter, err = s.write(w.fs, scalarSchema, part, scalarWriter, scalarFragment, options, true)
+			}
+			if err == nil {
+				vectorWriter, err = s.write(w.fs, vectorSchema, part, vectorWriter, vectorFragment, options, false)
+			}
+			// both sides roll at the same row so that paired files hold the same rows, which
+			// their stats record for readers to check
+			if err == nil && scalarWriter.Count() >= options.MaxRecordPerFile {
+				log.Debug("close writers", log.Any("count", scalarWriter.Count()))
+				err = closeWriters(scalarWriter, vectorWriter, scalarFragment, vectorFragment, progress)
+				scalarWriter, vectorWriter = nil, nil
+			}
+			part.Release()
+		}
+		rec.Release()
 		if err != nil {
-			return err
+			return writtenFragment{}, err
 		}
+		progress.Rows += rec.NumRows()
+		reportWriteProgress(options, progress, scalarWriter, vectorWriter)
+		// fail early instead of writing the whole stream, the commit checks again
+		if err = checkQuota(s.quota, m.GetUsage(), progress.Rows, writtenBytes(progress, scalarWriter, vectorWriter)); err != nil {
+			return writtenFragment{}, err
+		}
+	}
+	// a stream failing midway, e.g. a client disconnecting, commits nothing
+	if err := reader.Err(); err != nil {
+		return writtenFragment{}, fmt.Errorf("write: %w", err)
 	}
 
 	if scalarWriter != nil {
-		if err := scalarWriter.Close(); err != nil {
-			return err
+		err = closeWriters(scalarWriter, vectorWriter, scalarFragment, vectorFragment, progress)
+		scalarWriter, vectorWriter = nil, nil
+		if err != nil {
+			return writtenFragment{}, err
 		}
 	}
-	if vectorWriter != nil {
-		if err := vectorWriter.Close(); err != nil {
-			return err
+	return writtenFragment{scalar: scalarFragment, vector: vectorFragment}, nil
+}
+
+// discard removes the files of fragments that are not committed, e.g. rejected by the quota.
+// Files that cannot be removed are logged and left behind.
+func (w *fragmentWrite) discard(fragments ...writtenFragment) {
+	for _, f := range fragments {
+		for _, path := range append(f.scalar.Files(), f.vector.Files()...) {
+			if err := deleteIfExist(w.fs, path); err != nil {
+				log.Warn("remove file of failed write failed", log.String("path", path), log.String("err", err.Error()))
+			}
 		}
 	}
+}
 
-	s.lock.Lock()
-	defer s.lock.Unlock()
-	copied := s.manifest.Copy()
+// pendingWrite is a write whose data fragments are written but not committed yet.
+type pendingWrite struct {
+	w         *fragmentWrite
+	fragments []writtenFragment
+	// txn is the marker of the transaction of the fragments, if any
+	txn    string
+	result CommitResult
+	err    error
+	// done is set once the write is committed or failed, guarded by the lock of the commit
+	// queue if any
+	done bool
+}
 
-	nextVersion := s.nextManifestVersion
-	currentVersion := s.manifest.Version()
-	log.Debug("s.manifest.Version()", log.Int64("current version", currentVersion))
-	log.Debug("s.nextManifestVersion", log.Int64("next version", nextVersion))
+// commit commits the fragments written in a new version, as fragments of the transaction whose
+// marker is txn, if any. Spaces with a commit queue commit them with the writes completed
+// meanwhile, see option.CommitQueueOptions.
+func (w *fragmentWrite) commit(ctx context.Context, fragments []writtenFragment, txn string) (CommitResult, error) {
+	write := &pendingWrite{w: w, fragments: fragments, txn: txn}
+	if w.s.commitQueue != nil {
+		w.s.commitQueue.commit(ctx, w.s, write)
+	} else {
+		w.s.commitWrites(ctx, []*pendingWrite{write})
+	}
+	return write.result, write.err
+}
 
-	scalarFragment.SetFragmentId(nextVersion)
-	vectorFragment.SetFragmentId(nextVersion)
+// commitWrites commits the fragments of writes in a new version and sets their result. A write
+// failing its checks, e.g. since its schema is outdated, fails alone.
+func (s *Space) commitWrites(ctx context.Context, writes []*pendingWrite) {
+	record := &option.AuditRecord{Operation: auth.OpWrite}
+	for _, write := range writes {
+		record.Rows += write.w.progress.Rows
+		for _, f := range write.fragments {
+			record.Files = append(append(record.Files, f.scalar.Files()...), f.vector.Files()...)
+		}
+	}
+	var committed int64
+	results := make([]CommitResult, len(writes))
+	errs := make([]error, len(writes))
+	err := s.commit(ctx, record, func(m *manifest.Manifest, version int64) error {
+		accepted := 0
+		for i, write := range writes {
+			results[i], errs[i] = write.apply(m, version)
+			if errs[i] == nil {
+				accepted++
+			}
+		}
+		if accepted == 0 {
+			return errs[0]
+		}
+		committed = version
+		return nil
+	})
+	for i, write := range writes {
+		switch {
+		case errs[i] != nil:
+			// rejected writes are not in any version, other errors may not tell whether the
+			// manifest was saved
+			write.w.discard(write.fragments...)
+			write.err = errs[i]
+		case err != nil:
+			write.err = err
+		default:
+			results[i].Version = committed
+			write.result = results[i]
+		}
+	}
+}
 
-	copied.SetVersion(nextVersion)
-	copied.AddScalarFragment(*scalarFragment)
-	copied.AddVectorFragment(*vectorFragment)
+// apply adds the fragments of write to m, the manifest of version.
+func (write *pendingWrite) apply(m *manifest.Manifest, version int64) (CommitResult, error) {
+	w, progress := write.w, write.w.progress
+	// the files would lack the columns added meanwhile, e.g. by BackfillColumn
+	if !m.GetSchema().Schema().Equal(w.m.GetSchema().Schema()) {
+		return CommitResult{}, fmt.Errorf("write with the schema of an older version: %w", ErrManifestConflict)
+	}
+	if w.autoVersion != 0 && version != w.autoVersion {
+		return CommitResult{}, fmt.Errorf("write of version %d: %w", w.autoVersion, ErrAutoVersionConflict)
+	}
+	if err := checkQuota(w.s.quota, m.GetUsage(), progress.Rows, progress.Bytes); err != nil {
+		return CommitResult{}, err
+	}
+	result := CommitResult{Rows: progress.Rows, Bytes: progress.Bytes}
+	for _, f := range write.fragments {
+		id := m.NewFragmentID(version)
+		f.scalar.SetFragmentId(id)
+		f.vector.SetFragmentId(id)
+		f.scalar.SetTxn(write.txn)
+		f.vector.SetTxn(write.txn)
+		m.AddDataFragment(manifest.DataFragment{Scalar: *f.scalar, Vector: *f.vector})
+		result.FragmentIDs = append(result.FragmentIDs, id)
+		result.Files = append(append(result.Files, f.scalar.Files()...), f.vector.Files()...)
+	}
+	m.AddUsage(progress.Rows, progress.Bytes)
+	if w.autoIDEnd > m.NextAutoID() {
+		m.SetNextAutoID(w.autoIDEnd)
+	}
+	return result, nil
+}
 
-	log.Debug("check copied set version", log.Int64("copied version", copied.Version()))
-	if err := safeSaveManifest(s.fs, s.path, copied); err != nil {
-		return err
+// checkWriteSchema checks that records of schema written can be written to sc with options,
+// and tells whether they omit the primary column, generated with AutoID. The version column
+// may be omitted with AutoVersion.
+func checkWriteSchema(sc *schema.Schema, written *arrow.Schema, options *option.WriteOptions) (bool, error) {
+	if options.AutoVersion && !sc.Options().HasVersionColumn() {
+		return false, fmt.Errorf("auto version without version column: %w", ErrColumnNotExist)
 	}
-	s.manifest = copied
-	atomic.AddInt64(&s.nextManifestVersion, 1)
+	primary, version := sc.Options().PrimaryColumn, sc.Options().VersionColumn
+	if sc.Schema().Equal(written) || options.AutoVersion && withoutColumns(sc, version).Equal(written) {
+		return false, nil
+	}
+	if sc.Options().AutoID {
+		if withoutColumns(sc, primary).Equal(written) || options.AutoVersion && withoutColumns(sc, primary, version).Equal(written) {
+			return true, nil
+		}
+	}
+	return false, ErrSchemaNotMatch
+}
 
+// checkReservedColumns fails if a column of the schema of m is named like an internal column,
+// which spaces created before schemas were validated may have. Their reads would return the
+// internal column in its place.
+func checkReservedColumns(m *manifest.Manifest) error {
+	for _, name := range schema_option.ReservedColumns() {
+		if _, ok := m.GetSchema().Schema().FieldsByName(name); ok {
+			return fmt.Errorf("column %s: %w", name, schema_option.ErrReservedColumn)
+		}
+	}
 	return nil
 }
 
-func (s *Space) Delete(reader array.RecordReader) error {
+func withoutColumns(sc *schema.Schema, columns ...string) *arrow.Schema {
+	fields := make([]arrow.Field, 0, len(sc.Schema().Fields()))
+	for _, field := range sc.Schema().Fields() {
+		omitted := false
+		for _, column := range columns {
+			omitted = omitted || field.Name == column
+		}
+		if !omitted {
+			fields = append(fields, field)
+		}
+	}
+	metadata := sc.Schema().Metadata()
+	return arrow.NewSchema(fields, &metadata)
+}
+
+// withColumns returns rec with the columns of generated in place of its own, or added if rec
+// lacks them, which the caller must release. The generated columns are released.
+func withColumns(sc *schema.Schema, rec arrow.Record, generated map[string]arrow.Array) arrow.Record {
+	if len(generated) == 0 {
+		rec.Retain()
+		return rec
+	}
+	columns := make([]arrow.Array, 0, len(sc.Schema().Fields()))
+	for _, field := range sc.Schema().Fields() {
+		if column, ok := generated[field.Name]; ok {
+			columns = append(columns, column)
+			continue
+		}
+		columns = append(columns, rec.Column(rec.Schema().FieldIndices(field.Name)[0]))
+	}
+	filled := array.NewRecord(sc.Schema(), columns, rec.NumRows())
+	for _, column := range generated {
+		column.Release()
+	}
+	return filled
+}
+
+// applyWriteHooks returns rec transformed by the write hooks of the space, it releases rec and
+// the caller must release the returned record. The records returned by the hooks must keep the
+// schema of the space.
+func (s *Space) applyWriteHooks(sc *schema.Schema, rec arrow.Record) (arrow.Record, error) {
+	for _, hook := range s.writeHooks {
+		transformed, err := hook(rec)
+		rec.Release()
+		if err != nil {
+			return nil, fmt.Errorf("write hook: %w", err)
+		}
+		if !transformed.Schema().Equal(sc.Schema()) {
+			transformed.Release()
+			return nil, fmt.Errorf("write hook returned %s: %w", transformed.Schema(), ErrSchemaNotMatch)
+		}
+		rec = transformed
+	}
+	return rec, nil
+}
+
+// nextAutoIDs returns n generated primary keys, which the caller must release, and the key
+// following the last one. Keys are never reused by the writes of s, even if they fail, and the
+// manifest keeps the end of the committed keys for the next writers.
+func (s *Space) nextAutoIDs(n int64) (arrow.Array, int64) {
+	s.autoIDLock.Lock()
+	start := s.nextAutoID
+	if next := s.snapshot().NextAutoID(); next > start {
+		start = next
+	}
+	s.nextAutoID = start + n
+	s.autoIDLock.Unlock()
+	return sequenceColumn(start, n), start + n
+}
+
+func (s *Space) Delete(reader array.RecordReader) (CommitResult, error) {
+	return s.DeleteContext(context.Background(), reader)
+}
+
+// DeleteContext is like Delete, ctx carries the caller identity checked by the authorizer.
+// Nothing is committed if reader has no rows.
+func (s *Space) DeleteContext(ctx context.Context, reader array.RecordReader) (CommitResult, error) {
+	if err := s.authorize(ctx, auth.OpDelete); err != nil {
+		return CommitResult{}, err
+	}
 	// TODO: add delete frament
-	schema := s.manifest.GetSchema().DeleteSchema()
-	fragment := fragment.NewFragment(s.manifest.Version())
+	m := s.snapshot()
+	schema := m.GetSchema().DeleteSchema()
+	deleteFragment := fragment.NewFragment(m.Version())
 	var (
 		err        error
 		writer     format.Writer
 		deleteFile string
+		rows       int64
 	)
 
 	for reader.Next() {
@@ -148,62 +681,110 @@ func (s *Space) Delete(reader array.RecordReader) error {
 		}
 
 		if writer == nil {
-			deleteFile = utils.GetNewParquetFilePath(utils.GetDeleteDataDir(s.path))
-			writer, err = parquet.NewFileWriter(schema, s.fs, deleteFile)
+			if deleteFile, err = s.newFilePath(utils.GetDeleteDataDir(s.path)); err != nil {
+				return CommitResult{}, err
+			}
+			writer, err = parquet.NewFileWriter(schema, s.fs, deleteFile, s.snapshot().GetSchema().Options().StorageProfiles, s.writeMemory)
 			if err != nil {
-				return err
+				return CommitResult{}, err
 			}
 		}
 
 		if err = writer.Write(rec); err != nil {
-			return err
+			return CommitResult{}, err
 		}
+		rows += rec.NumRows()
+	}
+	if err = reader.Err(); err != nil {
+		if writer != nil {
+			abortWriter(writer)
+			if rmErr := deleteIfExist(s.fs, deleteFile); rmErr != nil {
+				log.Warn("remove file of failed delete failed", log.String("path", deleteFile), log.String("err", rmErr.Error()))
+			}
+		}
+		return CommitResult{}, fmt.Errorf("delete: %w", err)
 	}
 
 	if writer != nil {
 		if err = writer.Close(); err != nil {
-			return err
+			return CommitResult{}, err
 		}
+		deleteFragment.AddFileWithStats(deleteFile, fragment.FileStats{Rows: writer.Count(), Bytes: writer.Size()})
 
-		s.lock.Lock()
-		defer s.lock.Unlock()
-		copied := s.manifest.Copy()
-
-		nextVersion := s.nextManifestVersion
-		fragment.SetFragmentId(nextVersion)
-
-		copied.SetVersion(nextVersion)
-		copied.AddDeleteFragment(*fragment)
-
-		if err := safeSaveManifest(s.fs, s.path, copied); err != nil {
-			return err
+		record := &option.AuditRecord{Operation: auth.OpDelete, Rows: rows, Files: []string{deleteFile}}
+		var committed int64
+		err = s.commit(ctx, record, func(m *manifest.Manifest, version int64) error {
+			deleteFragment.SetFragmentId(version)
+			m.AddDeleteFragment(*deleteFragment)
+			// deletes are never rejected by the quota so that a full space can still be cleaned up
+			m.AddUsage(0, writer.Size())
+			committed = version
+			return nil
+		})
+		if err != nil {
+			return CommitResult{}, err
 		}
-		s.manifest = copied
-		atomic.AddInt64(&s.nextManifestVersion, 1)
+		return CommitResult{
+			Version:     committed,
+			FragmentIDs: []int64{committed},
+			Files:       record.Files,
+			Rows:        rows,
+			Bytes:       writer.Size(),
+		}, nil
 	}
-	return nil
+	return CommitResult{Version: m.Version()}, nil
 }
 
-func safeSaveManifest(fs fs.Fs, path string, m *manifest.Manifest) error {
-	tmpManifestFilePath := utils.GetManifestTmpFilePath(utils.GetManifestDir(path), m.Version())
-	manifestFilePath := utils.GetManifestFilePath(utils.GetManifestDir(path), m.Version())
+// safeSaveManifest writes m to a temporary file renamed to the path of its version. With sync
+// the manifest is flushed before the rename and the rename before returning.
+func safeSaveManifest(f fs.Fs, path string, m *manifest.Manifest, sync bool) error {
+	tmpManifestFilePath := utils.GetManifestTmpFilePath(path, m.Version())
+	manifestFilePath := utils.GetManifestFilePath(path, m.Version())
 	log.Debug("path", log.String("tmpManifestFilePath", tmpManifestFilePath), log.String("manifestFilePath", manifestFilePath))
-	output, err := fs.OpenFile(tmpManifestFilePath)
+	output, err := f.OpenFile(tmpManifestFilePath)
 	if err != nil {
 		return fmt.Errorf("save manfiest: %w", err)
 	}
-	if err = manifest.WriteManifestFile(m, output); err != nil {
+	if err = manifest.WriteManifestFileAt(m, path, output); err != nil {
+		output.Close()
 		return err
 	}
-	err = fs.Rename(tmpManifestFilePath, manifestFilePath)
+	if err = output.Close(); err != nil {
+		return fmt.Errorf("save manifest: %w", err)
+	}
+	if sync {
+		if err = fs.Sync(f, tmpManifestFilePath); err != nil {
+			return fmt.Errorf("save manifest: %w", err)
+		}
+	}
+	// rename replaces the destination, check first so that a concurrent commit of the same
+	// version from another process is reported instead of silently overwritten
+	exist, err := f.Exist(manifestFilePath)
+	if err != nil {
+		return fmt.Errorf("save manfiest: %w", err)
+	}
+	if exist {
+		if err = f.DeleteFile(tmpManifestFilePath); err != nil {
+			log.Warn("failed to remove tmp manifest", log.String("path", tmpManifestFilePath))
+		}
+		return fmt.Errorf("save manifest version %d: %w", m.Version(), ErrManifestConflict)
+	}
+	err = f.Rename(tmpManifestFilePath, manifestFilePath)
 	if err != nil {
 		return fmt.Errorf("save manfiest: %w", err)
 	}
+	if sync {
+		if err = fs.Sync(f, manifestFilePath); err != nil {
+			return fmt.Errorf("save manifest: %w", err)
+		}
+	}
 	log.Debug("save manifest file success", log.String("path", manifestFilePath))
+	writeLatestHint(f, path, m.Version())
 	return nil
 }
 
 func (s *Space) write(
+	f fs.Fs,
 	schema *arrow.Schema,
 	rec arrow.Record,
 	writer format.Writer,
@@ -223,15 +804,14 @@ func (s *Space) write(
 
 	var rootPath string
 	if isScalar {
-		// add offset column for scalar
-		offsetValues := make([]int64, rec.NumRows())
-		for i := 0; i < int(rec.NumRows()); i++ {
-			offsetValues[i] = int64(i)
-		}
-		builder := array.NewInt64Builder(memory.DefaultAllocator)
-		builder.AppendValues(offsetValues, nil)
-		offsetColumn := builder.NewArray()
-		columns = append(columns, offsetColumn)
+		// add offset column for scalar, offsets are the positions of the rows in the file
+		var start int64
+		if writer != nil {
+			start = writer.Count()
+		}
+		offsets := sequenceColumn(start, rec.NumRows())
+		defer offsets.Release()
+		columns = append(columns, offsets)
 		rootPath = utils.GetScalarDataDir(s.path)
 	} else {
 		rootPath = utils.GetVectorDataDir(s.path)
@@ -240,10 +820,22 @@ func (s *Space) write(
 	var err error
 
 	record := array.NewRecord(schema, columns, rec.NumRows())
+	defer record.Release()
 
 	if writer == nil {
-		filePath := utils.GetNewParquetFilePath(rootPath)
-		writer, err = parquet.NewFileWriter(schema, s.fs, filePath)
+		filePath, err := s.newFilePath(rootPath)
+		if err != nil {
+			return nil, err
+		}
+		var opts []parquet.WriterOption
+		if isScalar && opt.MaxRowGroupRows > 0 {
+			opts = append(opts, parquet.WithMaxRowGroupRows(opt.MaxRowGroupRows))
+		}
+		if dir := s.stagingDir(opt); dir != "" {
+			writer, err = s.newStagedWriter(f, schema, dir, filePath, opts...)
+		} else {
+			writer, err = parquet.NewFileWriter(schema, f, filePath, s.snapshot().GetSchema().Options().StorageProfiles, s.writeMemory, opts...)
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -254,39 +846,155 @@ func (s *Space) write(
 	if err != nil {
 		return nil, err
 	}
+	return writer, nil
+}
 
-	if writer.Count() >= opt.MaxRecordPerFile {
-		log.Debug("close writer", log.Any("count", writer.Count()))
-		err = writer.Close()
-		if err != nil {
-			return nil, err
-		}
-		writer = nil
+// sequenceBuilders and sequenceBuffers are reused by the offset columns of all writes, one is
+// built for every record written to a scalar file.
+var (
+	sequenceBuilders = sync.Pool{New: func() any { return array.NewInt64Builder(memory.DefaultAllocator) }}
+	sequenceBuffers  = sync.Pool{New: func() any { return new([]int64) }}
+)
+
+// sequenceColumn returns the n values following start, e.g. the offsets of n rows written
+// after start rows of a file.
+func sequenceColumn(start, n int64) arrow.Array {
+	buf := sequenceBuffers.Get().(*[]int64)
+	defer sequenceBuffers.Put(buf)
+	if int64(cap(*buf)) < n {
+		*buf = make([]int64, n)
+	}
+	values := (*buf)[:n]
+	for i := range values {
+		values[i] = start + int64(i)
 	}
+	builder := sequenceBuilders.Get().(*array.Int64Builder)
+	defer sequenceBuilders.Put(builder)
+	builder.AppendValues(values, nil)
+	return builder.NewArray()
+}
 
-	return writer, nil
+// constantColumn returns n times value.
+func constantColumn(value, n int64) arrow.Array {
+	builder := array.NewInt64Builder(memory.DefaultAllocator)
+	defer builder.Release()
+	builder.Reserve(int(n))
+	for i := int64(0); i < n; i++ {
+		builder.UnsafeAppend(value)
+	}
+	return builder.NewArray()
+}
+
+// closeWriters closes the writers of the last pair of files of a write, which hold the same
+// rows unless the write is broken.
+func closeWriters(scalarWriter, vectorWriter format.Writer, scalarFragment, vectorFragment *fragment.Fragment, progress *option.Progress) error {
+	if scalarWriter.Count() != vectorWriter.Count() {
+		return fmt.Errorf("close files of %d scalar and %d vector rows: %w", scalarWriter.Count(), vectorWriter.Count(), ErrFragmentMismatch)
+	}
+	if err := closeWriter(scalarWriter, scalarFragment, progress); err != nil {
+		return err
+	}
+	return closeWriter(vectorWriter, vectorFragment, progress)
+}
+
+// closeWriter closes the writer of the last file of frag and records the file stats.
+func closeWriter(writer format.Writer, frag *fragment.Fragment, progress *option.Progress) error {
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	files := frag.Files()
+	frag.SetFileStats(files[len(files)-1], fragment.FileStats{Rows: writer.Count(), Bytes: writer.Size()})
+	progress.Files++
+	progress.Bytes += writer.Size()
+	return nil
+}
+
+// reportWriteProgress reports the completed files plus the bytes flushed by open writers.
+func reportWriteProgress(opt *option.WriteOptions, progress *option.Progress, openWriters ...format.Writer) {
+	if opt.Progress == nil {
+		return
+	}
+	report := *progress
+	report.Bytes = writtenBytes(progress, openWriters...)
+	opt.Progress(report)
+}
+
+// writtenBytes returns the bytes of the completed files plus the bytes flushed by open writers.
+func writtenBytes(progress *option.Progress, openWriters ...format.Writer) int64 {
+	bytes := progress.Bytes
+	for _, w := range openWriters {
+		if w != nil {
+			bytes += w.Size()
+		}
+	}
+	return bytes
 }
 
 // Open opened a space or create if the space does not exist.
 // If space does not exist. schema should not be nullptr, or an error will be returned.
 // If space exists and version is specified, it will restore to the state at this version,
 // or it will choose the latest version.
+// Opening a soft dropped space fails with ErrSpaceDropped until it is restored.
 func Open(uri string, op option.Options) (*Space, error) {
-	var f fs.Fs
-	var m *manifest.Manifest
-	var path string
-	var nextManifestVersion int64
-	f, err := fs.BuildFileSystem(uri)
+	f, path, err := buildFs(uri)
 	if err != nil {
 		return nil, err
 	}
-
-	parsedUri, err := url.Parse(uri)
+	space, err := open(f, path, op)
 	if err != nil {
+		f.Close()
 		return nil, err
 	}
-	path = parsedUri.Path
+	space.ownsFs = true
+	return space, nil
+}
+
+// lookupVersion returns version if its manifest exists, or the latest version if version is -1.
+// It is -1 if the space has no version, and fails with ErrManifestNotFound if it has others.
+func lookupVersion(f fs.Fs, path string, version int64) (int64, error) {
+	// the version is looked up directly, the manifest directory is only listed when the
+	// space has no valid latest version hint
+	if version == -1 {
+		latest, err := latestVersion(f, path)
+		if err != nil {
+			log.Error("find latest manifest error", log.String("path", utils.GetManifestDir(path)))
+			return -1, err
+		}
+		return latest, nil
+	}
+	exist, err := f.Exist(utils.GetManifestFilePath(path, version))
+	if err != nil || exist {
+		return version, err
+	}
+	if latest, err := latestVersion(f, path); err != nil {
+		return -1, err
+	} else if latest != -1 {
+		return -1, fmt.Errorf("open manifest: %w", ErrManifestNotFound)
+	}
+	return -1, nil
+}
+
+// open opens the space at path of f, see Open.
+func open(f fs.Fs, path string, op option.Options) (*Space, error) {
+	var m *manifest.Manifest
+	var nextManifestVersion int64
+	var err error
+	if minio, ok := f.(*fs.MinioFs); ok && op.S3 != nil {
+		minio.SetObjectOptions(*op.S3)
+	}
+	if op.CircuitBreaker != nil {
+		f = fs.NewCircuitBreakerFs(f, op.CircuitBreaker)
+	}
+	if op.SlowLog != nil && op.SlowLog.Fs > 0 {
+		f = fs.NewSlowLogFs(f, op.SlowLog.Fs)
+	}
+	if op.ConfineToRoot {
+		f = fs.NewRootedFs(f, path)
+	}
 	log.Debug("open space", log.String("path", path))
+	if op.Replica != nil {
+		return openReplica(f, path, op)
+	}
 
 	log.Debug(utils.GetManifestDir(path))
 	if err = f.CreateDir(utils.GetManifestDir(path)); err != nil {
@@ -305,169 +1013,634 @@ func Open(uri string, op option.Options) (*Space, error) {
 		return nil, err
 	}
 
-	manifestFileInfoVec, err := findAllManifest(f, utils.GetManifestDir(path))
+	version, err := lookupVersion(f, path, op.Version)
 	if err != nil {
-		log.Error("find all manifest file error", log.String("path", utils.GetManifestDir(path)))
 		return nil, err
 	}
-
-	var filteredInfoVec []fs.FileEntry
-	for _, info := range manifestFileInfoVec {
-		if utils.ParseVersionFromFileName(filepath.Base(info.Path)) != -1 {
-			filteredInfoVec = append(filteredInfoVec, info)
+	if version == -1 {
+		// spaces of early releases have their manifests in the legacy manifest directory
+		if migrated, err := migrateLegacyManifests(f, path); err != nil {
+			return nil, err
+		} else if migrated {
+			if version, err = lookupVersion(f, path, op.Version); err != nil {
+				return nil, err
+			}
 		}
 	}
-	sort.Slice(filteredInfoVec, func(i, j int) bool {
-		return utils.ParseVersionFromFileName(filepath.Base(filteredInfoVec[i].Path)) < utils.ParseVersionFromFileName(filepath.Base(filteredInfoVec[j].Path))
-	})
 
 	// not exist manifest file, create new manifest file
-	if len(filteredInfoVec) == 0 {
+	if version == -1 {
 		if op.Schema == nil {
 			log.Error("schema is nil")
 			return nil, ErrSchemaIsNil
 		}
+		// a space created with an invalid schema could not be read back
+		if err = op.Schema.Validate(); err != nil {
+			return nil, fmt.Errorf("create space %s: %w", path, err)
+		}
 		m = manifest.NewManifest(op.Schema)
 		m.SetVersion(0) //TODO: check if this is necessary
-		if err = safeSaveManifest(f, path, m); err != nil {
+		if err = op.Layout.Check(); err != nil {
+			return nil, fmt.Errorf("create space %s: %w", path, err)
+		}
+		if op.Layout != layout.SchemeFlat {
+			m.SetLayout(layout.Layout{Version: 1, Scheme: op.Layout})
+		}
+		if err = safeSaveManifest(f, path, m, op.Durability == option.DurabilitySync); err != nil {
 			return nil, err
 		}
-		atomic.AddInt64(&nextManifestVersion, 1)
+		nextManifestVersion = 1
 	} else {
-		var fileInfo fs.FileEntry
-		var version int64
-		// not assign version to restore to the latest version manifest
-		if op.Version == -1 {
-			maxVersion := int64(-1)
-			var maxManifest fs.FileEntry
-			for _, info := range filteredInfoVec {
-				version := utils.ParseVersionFromFileName(filepath.Base(info.Path))
-				if version > maxVersion {
-					maxVersion = version
-					maxManifest = info
-				}
-			}
-			// the last one
-			fileInfo = maxManifest
-			version = maxVersion
-			atomic.AddInt64(&nextManifestVersion, version+1)
-
-		} else {
-			// assign version to restore to the specified version manifest
-			for _, info := range filteredInfoVec {
-				ver := utils.ParseVersionFromFileName(filepath.Base(info.Path))
-				if ver == op.Version {
-					fileInfo = info
-					atomic.AddInt64(&nextManifestVersion, ver+1)
-				}
-			}
-			if fileInfo.Path == "" {
-				return nil, fmt.Errorf("open manifest: %w", ErrManifestNotFound)
-			}
-			version = op.Version
-		}
-		manifestFilePath := utils.GetManifestFilePath(path, version)
-
-		m, err = manifest.ParseFromFile(f, manifestFilePath)
+		nextManifestVersion = version + 1
+		m, err = manifest.ParseFromFile(f, utils.GetManifestFilePath(path, version))
 		if err != nil {
 			return nil, err
 		}
+		if m.DroppedAt() != 0 {
+			return nil, fmt.Errorf("open space %s: %w", path, ErrSpaceDropped)
+		}
+		if err = checkReservedColumns(m); err != nil {
+			return nil, fmt.Errorf("open space %s: %w", path, err)
+		}
+		if op.ConfineToRoot {
+			if err = checkConfined(m, path); err != nil {
+				return nil, fmt.Errorf("open space %s: %w", path, err)
+			}
+		}
 	}
 	space := NewSpace(f, path, m, nextManifestVersion)
+	space.confined = op.ConfineToRoot
+	space.txnMarkerDir = txnMarkerDir(op)
+	space.quota = op.Quota
+	space.authorizer = op.Authorizer
+	space.masking = op.Masking
+	space.rowFilter = op.RowFilter
+	space.blobCodec = op.BlobCodec
+	space.blobCache = op.BlobCache
+	space.resultCache = op.ResultCache
+	space.writeHooks = op.WriteHooks
+	space.liveBitmaps = op.LiveBitmaps
+	space.durability = op.Durability
+	space.onVersionChange = op.OnVersionChange
+	if op.StagingDir != "" {
+		if err = cleanStaging(op.StagingDir, path); err != nil {
+			return nil, err
+		}
+		space.staging = op.StagingDir
+	}
+	if op.Naming != nil {
+		space.naming = *op.Naming
+	}
+	if op.CommitQueue != nil {
+		space.commitQueue = newCommitQueue(*op.CommitQueue)
+	}
+	if op.SlowLog != nil {
+		space.slowLog = *op.SlowLog
+	}
+	if op.Audit != nil {
+		space.auditSink = op.Audit.Sink
+		if space.auditSink == nil {
+			space.auditSink = newFileAuditSink(f, path)
+		}
+	}
+	if op.WriterLease != nil {
+		space.lease = op.WriterLease.Lease
+		if space.lease == nil {
+			space.lease = newFileLease(f, path, op.WriterLease)
+		}
+	}
+	if op.Mirror != nil {
+		if space.mirror, err = newMirror(f, path, op.Mirror); err != nil {
+			return nil, err
+		}
+		// the mirror may lag behind from a previous process
+		space.mirror.enqueue(m)
+	}
 	// space.init()
 	return space, nil
 }
 
-func findAllManifest(fs fs.Fs, path string) ([]fs.FileEntry, error) {
-	log.Debug("find all manifest", log.String("path", path))
-	files, err := fs.List(path)
-	for _, file := range files {
-		log.Debug("find all manifest", log.String("file", file.Path))
+// Read returns a reader over the manifest version current at the time of the call. Commits
+// made while the reader is in use are not visible to it.
+func (s *Space) Read(readOption *option.ReadOptions) (RecordReader, error) {
+	return s.ReadContext(context.Background(), readOption)
+}
+
+// ReadContext is like Read, ctx carries the caller identity checked by the authorizer.
+func (s *Space) ReadContext(ctx context.Context, readOption *option.ReadOptions) (RecordReader, error) {
+	if err := s.authorize(ctx, auth.OpRead); err != nil {
+		return nil, err
+	}
+	if err := s.checkMemory("read"); err != nil {
+		return nil, err
+	}
+	readOption = readOption.Clone()
+	readOption.SetAllocator(s.readMemory)
+	// checked before the cached results of older versions are looked up
+	if readOption.AtLeast != nil {
+		if err := s.checkConsistency(readOption.AtLeast); err != nil {
+			return nil, err
+		}
+		readOption.AtLeast = nil
 	}
+	m, err := s.readSnapshot()
 	if err != nil {
 		return nil, err
 	}
-	return files, nil
-}
+	var fill *resultFill
+	if s.resultCache != nil {
+		if key, ok := s.resultKey(ctx, m, readOption); ok {
+			if cached, ok := s.cachedResult(key, m.Version()); ok {
+				return cached, nil
+			}
+			fill = &resultFill{cache: s.resultCache, key: key}
+		}
+	}
+	if s.slowLog.Read <= 0 {
+		reader, err := s.read(ctx, m, readOption)
+		if err != nil {
+			return nil, err
+		}
+		recordReader, err := newRecordReader(reader, readOption.OutputColumns(), readOption.CastSchema())
+		if err == nil {
+			err = recordReader.setHooks(readOption.Hooks)
+		}
+		if err != nil {
+			reader.Release()
+			return nil, err
+		}
+		recordReader.version = m.Version()
+		recordReader.closed = &s.closed
+		recordReader.fill = fill
+		return recordReader, nil
+	}
 
-func (s *Space) Read(readOption *option.ReadOptions) (array.RecordReader, error) {
+	start, progress := time.Now(), &option.Progress{}
+	reader, err := s.read(ctx, m, slowReadOptions(readOption, progress))
+	if err != nil {
+		return nil, err
+	}
+	recordReader, err := newRecordReader(reader, readOption.OutputColumns(), readOption.CastSchema())
+	if err == nil {
+		err = recordReader.setHooks(readOption.Hooks)
+	}
+	if err != nil {
+		reader.Release()
+		return nil, err
+	}
+	recordReader.version = m.Version()
+	recordReader.closed = &s.closed
+	recordReader.fill = fill
+	recordReader.onRelease = func() {
+		s.logSlow("read", s.slowLog.Read, start, progressFields(*progress)...)
+	}
+	return recordReader, nil
+}
 
-	if s.manifest.GetSchema().Options().HasVersionColumn() {
-		f := filter.NewConstantFilter(filter.LessThanOrEqual, s.manifest.GetSchema().Options().VersionColumn, int64(math.MaxInt64))
+// read returns a reader over m that applies the masking, row filter and deletes.
+func (s *Space) read(ctx context.Context, m *manifest.Manifest, readOption *option.ReadOptions) (array.RecordReader, error) {
+	if readOption.AtLeast != nil {
+		if err := s.checkConsistency(readOption.AtLeast); err != nil {
+			return nil, err
+		}
+	}
+	// a dropped column is still in the files written before it was dropped
+	for _, column := range readOption.OutputColumns() {
+		if _, ok := m.GetSchema().Schema().FieldsByName(column); !ok && !strings.HasPrefix(column, constant.ReservedColumnPrefix) {
+			return nil, fmt.Errorf("read %q: %w", column, ErrColumnNotExist)
+		}
+	}
+	if readOption.OrderBy.Type == option.OrderKey {
+		if _, ok := m.GetSchema().Schema().FieldsByName(readOption.OrderBy.Column); !ok {
+			return nil, fmt.Errorf("order by %q: %w", readOption.OrderBy.Column, ErrColumnNotExist)
+		}
+	}
+	for _, f := range readOption.FiltersV2 {
+		fields, ok := m.GetSchema().Schema().FieldsByName(f.GetColumnName())
+		if !ok {
+			return nil, fmt.Errorf("filter on %q: %w", f.GetColumnName(), ErrColumnNotExist)
+		}
+		if err := filter.CheckType(f, fields[0].Type); err != nil {
+			return nil, fmt.Errorf("read: %w", err)
+		}
+	}
+	for _, f := range readOption.ExpressionFilters {
+		for _, column := range f.Columns() {
+			if _, ok := m.GetSchema().Schema().FieldsByName(column); !ok {
+				return nil, fmt.Errorf("filter %s on %q: %w", f, column, ErrColumnNotExist)
+			}
+		}
+		if err := f.Check(m.GetSchema().Schema()); err != nil {
+			return nil, fmt.Errorf("read: %w", err)
+		}
+	}
+	var maskRules map[string]option.MaskRule
+	if s.masking != nil {
+		maskRules = s.masking(auth.IdentityFromContext(ctx))
+		for _, f := range readOption.FiltersV2 {
+			if _, ok := maskRules[f.GetColumnName()]; ok {
+				return nil, errors.WithKind(errors.ErrPermissionDenied, fmt.Errorf("filter on masked column %s", f.GetColumnName()))
+			}
+		}
+		for _, f := range readOption.ExpressionFilters {
+			for _, column := range f.Columns() {
+				if _, ok := maskRules[column]; ok {
+					return nil, errors.WithKind(errors.ErrPermissionDenied, fmt.Errorf("filter on masked column %s", column))
+				}
+			}
+		}
+		if _, ok := maskRules[readOption.OrderBy.Column]; ok && readOption.OrderBy.Type == option.OrderKey {
+			return nil, errors.WithKind(errors.ErrPermissionDenied, fmt.Errorf("order by masked column %s", readOption.OrderBy.Column))
+		}
+	}
+	// the filters added below must not leak into the options of the caller
+	readOption = readOption.Clone()
+	if s.rowFilter != nil {
+		identity := auth.IdentityFromContext(ctx)
+		filters, err := s.rowFilter(identity)
+		if err != nil {
+			return nil, errors.WithKind(errors.ErrPermissionDenied, fmt.Errorf("row filter for %q: %w", identity.User, err))
+		}
+		for _, f := range filters {
+			readOption.AddFilter(f)
+			if !readOption.HasColumn(f.GetColumnName()) {
+				readOption.AddColumn(f.GetColumnName())
+			}
+		}
+	}
+	if m.GetSchema().Options().HasVersionColumn() {
+		f := filter.NewConstantFilter(filter.LessThanOrEqual, m.GetSchema().Options().VersionColumn, int64(math.MaxInt64))
 		readOption.AddFilter(f)
-		readOption.AddColumn(s.manifest.GetSchema().Options().VersionColumn)
+		if !readOption.HasColumn(m.GetSchema().Options().VersionColumn) {
+			readOption.AddColumn(m.GetSchema().Options().VersionColumn)
+		}
 	}
 	log.Debug("read", log.Any("readOption", readOption))
 
-	return record_reader.MakeRecordReader(s.manifest, s.manifest.GetSchema(), s.fs, s.deleteFragments, readOption), nil
+	deleteFragments, err := s.deleteFragments(m)
+	if err != nil {
+		return nil, err
+	}
+	var deletedRows fragment.DeletedRows
+	if s.liveBitmaps && len(deleteFragments) > 0 {
+		deletedRows = s.deletedRows(m)
+	}
+	reader, err := record_reader.MakeRecordReader(m, m.GetSchema(), s.opFs(readOption.Retry), deleteFragments, deletedRows, readOption)
+	if err != nil {
+		return nil, err
+	}
+	if len(maskRules) == 0 {
+		return reader, nil
+	}
+	masked, err := record_reader.NewMaskRecordReader(reader, maskRules)
+	if err != nil {
+		reader.Release()
+		return nil, err
+	}
+	return masked, nil
+}
+
+func (s *Space) WriteBlob(content []byte, name string, replace bool) (CommitResult, error) {
+	return s.WriteBlobContext(context.Background(), content, name, replace)
 }
 
-func (s *Space) WriteBlob(content []byte, name string, replace bool) error {
-	if !replace && s.manifest.HasBlob(name) {
-		return ErrBlobAlreadyExist
+// WriteBlobContext is like WriteBlob, ctx carries the caller identity checked by the authorizer.
+func (s *Space) WriteBlobContext(ctx context.Context, content []byte, name string, replace bool) (CommitResult, error) {
+	return s.WriteBlobWithMetaContext(ctx, content, name, nil, replace)
+}
+
+// WriteBlobWithMeta is like WriteBlob and stores metadata with the blob, see GetBlobMeta.
+func (s *Space) WriteBlobWithMeta(content []byte, name string, metadata map[string]string, replace bool) (CommitResult, error) {
+	return s.WriteBlobWithMetaContext(context.Background(), content, name, metadata, replace)
+}
+
+// WriteBlobWithMetaContext is like WriteBlobWithMeta, ctx carries the caller identity checked
+// by the authorizer.
+func (s *Space) WriteBlobWithMetaContext(ctx context.Context, content []byte, name string, metadata map[string]string, replace bool) (CommitResult, error) {
+	if err := s.authorize(ctx, auth.OpWriteBlob); err != nil {
+		return CommitResult{}, err
+	}
+	m := s.snapshot()
+	if !replace && m.HasBlob(name) {
+		return CommitResult{}, ErrBlobAlreadyExist
+	}
+	stored, codec, err := compressBlob(s.blobCodec, content)
+	if err != nil {
+		return CommitResult{}, err
+	}
+	if err := checkQuota(s.quota, m.GetUsage(), 0, blobGrowth(m, name, int64(len(stored)))); err != nil {
+		return CommitResult{}, err
 	}
 
-	blobFile := utils.GetBlobFilePath(utils.GetBlobDir(s.path))
+	blobFile := utils.GetBlobFilePath(s.path)
 	f, err := s.fs.OpenFile(blobFile)
 	if err != nil {
-		return err
+		return CommitResult{}, err
 	}
 
-	n, err := f.Write(content)
+	n, err := f.Write(stored)
 	if err != nil {
-		return err
+		return CommitResult{}, err
 	}
 
-	if n != len(content) {
-		return fmt.Errorf("blob not writen completely, writen %d but expect %d", n, len(content))
+	if n != len(stored) {
+		return CommitResult{}, fmt.Errorf("blob not writen completely, writen %d but expect %d", n, len(stored))
 	}
 
 	if err = f.Close(); err != nil {
-		return err
+		return CommitResult{}, err
 	}
 
-	s.lock.Lock()
-	defer s.lock.Unlock()
-	copied := s.manifest.Copy()
+	return s.commitBlob(ctx, blob.Blob{
+		Name:       name,
+		Size:       int64(len(content)),
+		File:       blobFile,
+		Metadata:   copyMetadata(metadata),
+		Checksum:   blob.Checksum(content),
+		Codec:      codec,
+		StoredSize: int64(len(stored)),
+	}, replace)
+}
 
-	nextVersion := s.nextManifestVersion
-	copied.SetVersion(nextVersion)
-	copied.AddBlob(blob.Blob{
-		Name: name,
-		Size: int64(len(content)),
-		File: blobFile,
+// compressBlob returns the bytes to store for content and their codec, content is stored as
+// is unless compressing it saves space.
+func compressBlob(codec blob.Codec, content []byte) ([]byte, blob.Codec, error) {
+	if codec == blob.CodecNone {
+		return content, blob.CodecNone, nil
+	}
+	compressed, err := codec.Compress(content)
+	if err != nil {
+		return nil, blob.CodecNone, err
+	}
+	if len(compressed) >= len(content) {
+		return content, blob.CodecNone, nil
+	}
+	return compressed, codec, nil
+}
+
+// commitBlob adds b, whose files are written, to the manifest.
+func (s *Space) commitBlob(ctx context.Context, b blob.Blob, replace bool) (CommitResult, error) {
+	record := &option.AuditRecord{Operation: auth.OpWriteBlob, Files: b.Files()}
+	var committed int64
+	err := s.commit(ctx, record, func(m *manifest.Manifest, version int64) error {
+		// another writer may have added the blob since it was checked
+		if !replace && m.HasBlob(b.Name) {
+			return ErrBlobAlreadyExist
+		}
+		growth := blobGrowth(m, b.Name, b.StoredBytes())
+		if err := checkQuota(s.quota, m.GetUsage(), 0, growth); err != nil {
+			return err
+		}
+		m.RemoveBlobIfExist(b.Name)
+		m.AddBlob(b)
+		m.AddUsage(0, growth)
+		committed = version
+		return nil
 	})
+	if err != nil {
+		return CommitResult{}, err
+	}
+	return CommitResult{Version: committed, Files: record.Files, Bytes: b.StoredBytes()}, nil
+}
 
-	if err := safeSaveManifest(s.fs, s.path, copied); err != nil {
-		return err
+func copyMetadata(metadata map[string]string) map[string]string {
+	if len(metadata) == 0 {
+		return nil
 	}
-	s.manifest = copied
-	atomic.AddInt64(&s.nextManifestVersion, 1)
-	return nil
+	copied := make(map[string]string, len(metadata))
+	for k, v := range metadata {
+		copied[k] = v
+	}
+	return copied
+}
+
+// blobGrowth returns how many bytes storing size bytes as blob name adds to the usage of m,
+// a replaced blob releases its size.
+func blobGrowth(m *manifest.Manifest, name string, size int64) int64 {
+	growth := size
+	if old, ok := m.GetBlob(name); ok {
+		growth -= old.StoredBytes()
+	}
+	return growth
 }
 
 func (s *Space) ReadBlob(name string, output []byte) (int, error) {
-	blob, ok := s.manifest.GetBlob(name)
+	return s.ReadBlobContext(context.Background(), name, output)
+}
+
+// ReadBlobContext is like ReadBlob, ctx carries the caller identity checked by the authorizer.
+func (s *Space) ReadBlobContext(ctx context.Context, name string, output []byte) (int, error) {
+	if err := s.authorize(ctx, auth.OpReadBlob); err != nil {
+		return -1, err
+	}
+	blob, ok := s.snapshot().GetBlob(name)
 	if !ok {
 		return -1, ErrBlobNotExist
 	}
 
-	f, err := s.fs.OpenFile(blob.File)
+	n, err := s.readBlobAt(blob, output, 0)
+	if err == io.EOF {
+		// output is larger than the blob
+		err = nil
+	}
 	if err != nil {
 		return -1, err
 	}
+	return n, nil
+}
+
+// ReadBlobRaw returns the stored bytes of a blob that is not chunked and their codec,
+// without decompressing or verifying them.
+func (s *Space) ReadBlobRaw(name string) ([]byte, blob.Codec, error) {
+	return s.ReadBlobRawContext(context.Background(), name)
+}
+
+// ReadBlobRawContext is like ReadBlobRaw, ctx carries the caller identity checked by the
+// authorizer.
+func (s *Space) ReadBlobRawContext(ctx context.Context, name string) ([]byte, blob.Codec, error) {
+	if err := s.authorize(ctx, auth.OpReadBlob); err != nil {
+		return nil, blob.CodecNone, err
+	}
+	b, ok := s.snapshot().GetBlob(name)
+	if !ok {
+		return nil, blob.CodecNone, ErrBlobNotExist
+	}
+	if b.Chunked() {
+		return nil, blob.CodecNone, fmt.Errorf("read raw blob %s: %w", name, ErrBlobChunked)
+	}
+	stored, err := s.fs.ReadFile(b.File)
+	if err != nil {
+		return nil, blob.CodecNone, err
+	}
+	return stored, b.Codec, nil
+}
+
+func (s *Space) ReadBlobAt(name string, p []byte, off int64) (int, error) {
+	return s.ReadBlobAtContext(context.Background(), name, p, off)
+}
 
-	return f.Read(output)
+// ReadBlobAtContext reads len(p) bytes of the blob at off with the semantics of io.ReaderAt,
+// only the chunks overlapping the range are downloaded. ctx carries the caller identity
+// checked by the authorizer.
+func (s *Space) ReadBlobAtContext(ctx context.Context, name string, p []byte, off int64) (int, error) {
+	if err := s.authorize(ctx, auth.OpReadBlob); err != nil {
+		return 0, err
+	}
+	blob, ok := s.snapshot().GetBlob(name)
+	if !ok {
+		return 0, ErrBlobNotExist
+	}
+	return s.readBlobAt(blob, p, off)
+}
+
+func (s *Space) GetBlobMeta(name string) (map[string]string, error) {
+	return s.GetBlobMetaContext(context.Background(), name)
+}
+
+// GetBlobMetaContext is like GetBlobMeta, ctx carries the caller identity checked by the
+// authorizer.
+func (s *Space) GetBlobMetaContext(ctx context.Context, name string) (map[string]string, error) {
+	if err := s.authorize(ctx, auth.OpReadBlob); err != nil {
+		return nil, err
+	}
+	blob, ok := s.snapshot().GetBlob(name)
+	if !ok {
+		return nil, ErrBlobNotExist
+	}
+	return copyMetadata(blob.Metadata), nil
+}
+
+// SignBlobURL returns a URL from which the blob can be downloaded without credentials for
+// ttl, see fs.Fs.SignURL.
+func (s *Space) SignBlobURL(name string, ttl time.Duration) (string, error) {
+	return s.SignBlobURLContext(context.Background(), name, ttl)
+}
+
+// SignBlobURLContext is like SignBlobURL, ctx carries the caller identity checked by the
+// authorizer.
+func (s *Space) SignBlobURLContext(ctx context.Context, name string, ttl time.Duration) (string, error) {
+	if err := s.authorize(ctx, auth.OpReadBlob); err != nil {
+		return "", err
+	}
+	blob, ok := s.snapshot().GetBlob(name)
+	if !ok {
+		return "", ErrBlobNotExist
+	}
+	if blob.Chunked() {
+		return "", fmt.Errorf("sign url of blob %s: %w", name, ErrBlobChunked)
+	}
+	return s.fs.SignURL(blob.File, ttl)
 }
 
 func (s *Space) GetBlobByteSize(name string) (int64, error) {
-	blob, ok := s.manifest.GetBlob(name)
+	return s.GetBlobByteSizeContext(context.Background(), name)
+}
+
+// GetBlobByteSizeContext is like GetBlobByteSize, ctx carries the caller identity checked by
+// the authorizer.
+func (s *Space) GetBlobByteSizeContext(ctx context.Context, name string) (int64, error) {
+	if err := s.authorize(ctx, auth.OpReadBlob); err != nil {
+		return -1, err
+	}
+	blob, ok := s.snapshot().GetBlob(name)
 	if !ok {
 		return -1, ErrBlobNotExist
 	}
 	return blob.Size, nil
 }
 
+// ReleaseLease gives up the writer lease, if any, so that other writers can commit
+// immediately instead of waiting for it to expire. The next commit acquires it again.
+func (s *Space) ReleaseLease() error {
+	return s.ReleaseLeaseContext(context.Background())
+}
+
+// ReleaseLeaseContext is like ReleaseLease, ctx carries the caller identity checked by the
+// authorizer.
+func (s *Space) ReleaseLeaseContext(ctx context.Context) error {
+	if err := s.authorize(ctx, auth.OpAdmin); err != nil {
+		return err
+	}
+	if s.lease == nil {
+		return nil
+	}
+	return s.lease.Release()
+}
+
+// Close releases the space: replication to the mirror stops, the writer lease is given up, the
+// cached delete fragments and bitmaps are dropped, the file system a replica built for its
+// primary is closed and so is the file system of the space unless it is shared through a
+// Manager. Commits in progress complete first. Afterwards the operations of the space, and the
+// readers it returned, fail with ErrSpaceClosed. Closing a closed space does nothing.
+func (s *Space) Close() error {
+	if !atomic.CompareAndSwapInt32(&s.closed, 0, 1) {
+		return nil
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	var errs []error
+	if s.mirror != nil {
+		errs = append(errs, s.mirror.stop())
+	}
+	if s.lease != nil {
+		errs = append(errs, s.lease.Release())
+	}
+	s.dropCaches(nil)
+	if s.primaryFs != nil {
+		errs = append(errs, s.primaryFs.Close())
+	}
+	if s.ownsFs {
+		errs = append(errs, s.fs.Close())
+	}
+	for _, err := range errs {
+		if err != nil {
+			return fmt.Errorf("close space %s: %w", s.path, err)
+		}
+	}
+	return nil
+}
+
+// Ping checks that the space can be used, so that services can fail their readiness checks
+// before accepting traffic: the backend must be reachable with the configured credentials and,
+// unless the space is a replica, a probe file must be written and deleted under the space. ctx
+// carries the caller identity checked by the authorizer.
+func (s *Space) Ping(ctx context.Context) error {
+	if err := s.authorize(ctx, auth.OpRead); err != nil {
+		return err
+	}
+	if err := s.fs.Ping(ctx); err != nil {
+		return fmt.Errorf("ping %s: %w", s.path, err)
+	}
+	if s.replica != nil {
+		return nil
+	}
+	probe := utils.GetPingFilePath(s.path)
+	if err := fs.WriteFile(s.fs, probe, []byte("ping")); err != nil {
+		return fmt.Errorf("ping %s: %w", s.path, err)
+	}
+	if err := s.fs.DeleteFile(probe); err != nil {
+		return fmt.Errorf("ping %s: %w", s.path, err)
+	}
+	return nil
+}
+
+// Flush returns a version holding every write that returned before the call, e.g. as the
+// checkpoint an index is built from. Writes are committed before they return, so nothing is
+// buffered: Flush only waits for the commit in progress, if any.
+func (s *Space) Flush() (int64, error) {
+	return s.FlushContext(context.Background())
+}
+
+// FlushContext is like Flush, ctx carries the caller identity checked by the authorizer.
+func (s *Space) FlushContext(ctx context.Context) (int64, error) {
+	if err := s.authorize(ctx, auth.OpWrite); err != nil {
+		return 0, err
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.manifest.Version(), nil
+}
+
 func (s *Space) GetCurrentVersion() int64 {
-	return s.manifest.Version()
+	return s.snapshot().Version()
 }
@@ -1,16 +1,47 @@
 package storage_test
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	iofs "io/fs"
+	"math"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/milvus-io/milvus-storage/go/common/constant"
+	"github.com/milvus-io/milvus-storage/go/common/errors"
+	"github.com/milvus-io/milvus-storage/go/common/log"
+	"github.com/milvus-io/milvus-storage/go/common/utils"
+	"github.com/milvus-io/milvus-storage/go/file/blob"
+	"github.com/milvus-io/milvus-storage/go/file/fragment"
+	"github.com/milvus-io/milvus-storage/go/file/layout"
+	"github.com/milvus-io/milvus-storage/go/io/format/parquet"
+	"github.com/milvus-io/milvus-storage/go/io/fs"
+	"github.com/milvus-io/milvus-storage/go/storage/auth"
+	"github.com/milvus-io/milvus-storage/go/storage/manifest"
 	"github.com/milvus-io/milvus-storage/go/storage/options/option"
 	"github.com/milvus-io/milvus-storage/go/storage/options/schema_option"
 	"github.com/milvus-io/milvus-storage/go/storage/schema"
 
 	"github.com/apache/arrow/go/v12/arrow"
 	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/arrio"
+	"github.com/apache/arrow/go/v12/arrow/ipc"
 	"github.com/apache/arrow/go/v12/arrow/memory"
+	pqlib "github.com/apache/arrow/go/v12/parquet"
+	"github.com/apache/arrow/go/v12/parquet/compress"
+	pqfile "github.com/apache/arrow/go/v12/parquet/file"
+	pqmetadata "github.com/apache/arrow/go/v12/parquet/metadata"
 	"github.com/milvus-io/milvus-storage/go/filter"
+	"github.com/milvus-io/milvus-storage/go/reader/record_reader"
 	"github.com/milvus-io/milvus-storage/go/storage"
 	"github.com/stretchr/testify/suite"
 )
@@ -74,11 +105,11 @@ func (suite *SpaceTestSuite) TestSpaceReadWrite() {
 
 	ops := option.NewOptions(sc, 0)
 
-	space, err := storage.Open("file:///tmp", *ops)
+	space, err := storage.Open("file://"+suite.T().TempDir(), *ops)
 	suite.NoError(err)
 
 	writeOpt := &option.WriteOptions{MaxRecordPerFile: 1000}
-	err = space.Write(recReader, writeOpt)
+	_, err = space.Write(recReader, writeOpt)
 	suite.NoError(err)
 
 	f := filter.NewConstantFilter(filter.Equal, "pk_field", int64(1))
@@ -98,6 +129,4259 @@ func (suite *SpaceTestSuite) TestSpaceReadWrite() {
 	suite.ElementsMatch([]int64{1}, resVals)
 }
 
+func createSchema() *schema.Schema {
+	pkField := arrow.Field{
+		Name:     "pk_field",
+		Type:     arrow.DataType(&arrow.Int64Type{}),
+		Nullable: false,
+	}
+	vsField := arrow.Field{
+		Name:     "vs_field",
+		Type:     arrow.DataType(&arrow.Int64Type{}),
+		Nullable: false,
+	}
+	vecField := arrow.Field{
+		Name:     "vec_field",
+		Type:     arrow.DataType(&arrow.FixedSizeBinaryType{ByteWidth: 10}),
+		Nullable: false,
+	}
+	as := arrow.NewSchema([]arrow.Field{pkField, vsField, vecField}, nil)
+	schemaOptions := &schema_option.SchemaOptions{
+		PrimaryColumn: "pk_field",
+		VersionColumn: "vs_field",
+		VectorColumn:  "vec_field",
+	}
+	return schema.NewSchema(as, schemaOptions)
+}
+
+func createRecordReader(sc *schema.Schema, pks []int64) array.RecordReader {
+	return createVersionedRecordReader(sc, pks, pks)
+}
+
+func createVersionedRecordReader(sc *schema.Schema, pks []int64, versions []int64) array.RecordReader {
+	as := sc.Schema()
+	b := array.NewRecordBuilder(memory.DefaultAllocator, as)
+	defer b.Release()
+	for i, pk := range pks {
+		b.Field(0).(*array.Int64Builder).Append(pk)
+		b.Field(1).(*array.Int64Builder).Append(versions[i])
+		b.Field(2).(*array.FixedSizeBinaryBuilder).Append([]byte{byte(pk), 2, 3, 4, 5, 6, 7, 8, 9, 10})
+	}
+	rec := b.NewRecord()
+	recReader, err := array.NewRecordReader(as, []arrow.Record{rec})
+	if err != nil {
+		panic(err)
+	}
+	return recReader
+}
+
+// commitErr returns the error of an operation returning a storage.CommitResult.
+func commitErr(_ storage.CommitResult, err error) error {
+	return err
+}
+
+func versionErr(_ int64, err error) error {
+	return err
+}
+
+func readPks(space *storage.Space) ([]int64, error) {
+	readOpt := option.NewReadOptions()
+	readOpt.AddColumn("pk_field")
+	reader, err := space.Read(readOpt)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Release()
+	var pks []int64
+	for reader.Next() {
+		pks = append(pks, reader.Record().Column(0).(*array.Int64).Int64Values()...)
+	}
+	return pks, reader.Err()
+}
+
+func (suite *SpaceTestSuite) TestConcurrentReadWrite() {
+	sc := createSchema()
+	suite.Require().NoError(sc.Validate())
+	space, err := storage.Open("file://"+suite.T().TempDir(), *option.NewOptions(sc, -1))
+	suite.Require().NoError(err)
+
+	const writers = 4
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			pks := []int64{int64(i * 3), int64(i*3 + 1), int64(i*3 + 2)}
+			suite.NoError(commitErr(space.Write(createRecordReader(sc, pks), option.NewWriteOption())))
+		}(i)
+		go func() {
+			defer wg.Done()
+			pks, err := readPks(space)
+			suite.NoError(err)
+			suite.Zero(len(pks) % 3)
+		}()
+	}
+	wg.Wait()
+
+	suite.Equal(int64(writers), space.GetCurrentVersion())
+	pks, err := readPks(space)
+	suite.NoError(err)
+	suite.Len(pks, writers*3)
+}
+
+func (suite *SpaceTestSuite) TestWriterLease() {
+	sc := createSchema()
+	suite.Require().NoError(sc.Validate())
+	dir := suite.T().TempDir()
+	openWithLease := func(owner string) *storage.Space {
+		ops := option.NewOptions(sc, -1)
+		ops.WriterLease = &option.LeaseOptions{Owner: owner, TTL: time.Minute}
+		space, err := storage.Open("file://"+dir, *ops)
+		suite.Require().NoError(err)
+		return space
+	}
+
+	a := openWithLease("a")
+	b := openWithLease("b")
+	suite.NoError(commitErr(a.Write(createRecordReader(sc, []int64{1}), option.NewWriteOption())))
+	suite.ErrorIs(commitErr(b.Write(createRecordReader(sc, []int64{2}), option.NewWriteOption())), storage.ErrLeaseHeld)
+
+	suite.NoError(a.ReleaseLease())
+	// b must reopen to see the version committed by a
+	suite.ErrorIs(commitErr(b.WriteBlob([]byte{1}, "blob", false)), storage.ErrManifestConflict)
+	suite.NoError(b.ReleaseLease())
+	b = openWithLease("b")
+	suite.NoError(commitErr(b.WriteBlob([]byte{1}, "blob", false)))
+	suite.ErrorIs(commitErr(a.WriteBlob([]byte{1}, "blob", true)), storage.ErrLeaseHeld)
+	suite.NoError(b.ReleaseLease())
+
+	// a crashed writer leaves an expired lease behind
+	leaseFile := filepath.Join(dir, constant.LeaseFileName)
+	expired := fmt.Sprintf(`{"owner": "crashed", "expire_at": %d}`, time.Now().Add(-time.Second).UnixMilli())
+	suite.Require().NoError(os.WriteFile(leaseFile, []byte(expired), 0666))
+	c := openWithLease("c")
+	suite.NoError(commitErr(c.WriteBlob([]byte{1}, "other", false)))
+	suite.NoError(c.ReleaseLease())
+
+	alive := fmt.Sprintf(`{"owner": "alive", "expire_at": %d}`, time.Now().Add(time.Minute).UnixMilli())
+	suite.Require().NoError(os.WriteFile(leaseFile, []byte(alive), 0666))
+	suite.ErrorIs(commitErr(c.WriteBlob([]byte{1}, "another", false)), storage.ErrLeaseHeld)
+}
+
+func (suite *SpaceTestSuite) TestErrorKinds() {
+	sc := createSchema()
+	suite.Require().NoError(sc.Validate())
+	dir := suite.T().TempDir()
+	a, err := storage.Open("file://"+dir, *option.NewOptions(sc, -1))
+	suite.Require().NoError(err)
+	b, err := storage.Open("file://"+dir, *option.NewOptions(sc, -1))
+	suite.Require().NoError(err)
+
+	// b has not seen the version committed by a
+	suite.Require().NoError(commitErr(a.Write(createRecordReader(sc, []int64{1}), option.NewWriteOption())))
+	_, err = b.Write(createRecordReader(sc, []int64{2}), option.NewWriteOption())
+	suite.ErrorIs(err, storage.ErrManifestConflict)
+	suite.ErrorIs(err, errors.ErrConflict)
+
+	_, err = a.ReadBlob("missing", nil)
+	suite.ErrorIs(err, errors.ErrNotFound)
+	_, err = storage.Open("file://"+dir, *option.NewOptions(sc, 10))
+	suite.ErrorIs(err, errors.ErrNotFound)
+	suite.NoError(commitErr(a.WriteBlob([]byte{1}, "blob", false)))
+	suite.ErrorIs(commitErr(a.WriteBlob([]byte{1}, "blob", false)), errors.ErrConflict)
+}
+
+func (suite *SpaceTestSuite) TestBlobMetaAndChecksum() {
+	sc := createSchema()
+	suite.Require().NoError(sc.Validate())
+	dir := suite.T().TempDir()
+	space, err := storage.Open("file://"+dir, *option.NewOptions(sc, -1))
+	suite.Require().NoError(err)
+	meta := map[string]string{"index_type": "HNSW", "M": "16"}
+	suite.Require().NoError(commitErr(space.WriteBlobWithMeta([]byte{1, 2, 3}, "index", meta, false)))
+	suite.Require().NoError(commitErr(space.WriteBlob([]byte{4}, "plain", false)))
+
+	space, err = storage.Open("file://"+dir, *option.NewOptions(sc, -1))
+	suite.Require().NoError(err)
+	got, err := space.GetBlobMeta("index")
+	suite.Require().NoError(err)
+	suite.Equal(meta, got)
+	got, err = space.GetBlobMeta("plain")
+	suite.Require().NoError(err)
+	suite.Empty(got)
+	output := make([]byte, 3)
+	n, err := space.ReadBlob("index", output)
+	suite.Require().NoError(err)
+	suite.Equal([]byte{1, 2, 3}, output[:n])
+
+	entries, err := os.ReadDir(filepath.Join(dir, constant.BlobDir))
+	suite.Require().NoError(err)
+	for _, entry := range entries {
+		info, err := entry.Info()
+		suite.Require().NoError(err)
+		if info.Size() == 3 {
+			suite.Require().NoError(os.WriteFile(filepath.Join(dir, constant.BlobDir, entry.Name()), []byte{1, 2, 4}, 0666))
+		}
+	}
+	_, err = space.ReadBlob("index", output)
+	suite.ErrorIs(err, storage.ErrBlobCorrupted)
+	suite.ErrorIs(err, errors.ErrChecksumMismatch)
+}
+
+func (suite *SpaceTestSuite) TestChunkedBlob() {
+	sc := createSchema()
+	suite.Require().NoError(sc.Validate())
+	dir := suite.T().TempDir()
+	space, err := storage.Open("file://"+dir, *option.NewOptions(sc, -1))
+	suite.Require().NoError(err)
+	content := []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+	options := &option.BlobWriterOptions{ChunkSize: 4, Parallelism: 2, Metadata: map[string]string{"k": "v"}}
+
+	w, err := space.NewBlobWriter("blob", options)
+	suite.Require().NoError(err)
+	for _, part := range [][]byte{content[:3], content[3:9], content[9:]} {
+		_, err = w.Write(part)
+		suite.Require().NoError(err)
+	}
+	suite.Require().NoError(w.Close())
+
+	size, err := space.GetBlobByteSize("blob")
+	suite.Require().NoError(err)
+	suite.Equal(int64(10), size)
+	output := make([]byte, 10)
+	n, err := space.ReadBlob("blob", output)
+	suite.Require().NoError(err)
+	suite.Equal(content, output[:n])
+	meta, err := space.GetBlobMeta("blob")
+	suite.Require().NoError(err)
+	suite.Equal(options.Metadata, meta)
+
+	// ranges may span chunks and end past the blob
+	p := make([]byte, 3)
+	n, err = space.ReadBlobAt("blob", p, 3)
+	suite.Require().NoError(err)
+	suite.Equal(content[3:6], p[:n])
+	p = make([]byte, 4)
+	n, err = space.ReadBlobAt("blob", p, 8)
+	suite.Equal(io.EOF, err)
+	suite.Equal(content[8:], p[:n])
+
+	// an interrupted upload continues after its complete chunks
+	w, err = space.NewBlobWriter("resumed", options)
+	suite.Require().NoError(err)
+	_, err = w.Write(content[:9])
+	suite.Require().NoError(err)
+	suite.Require().NoError(w.Flush())
+	w, err = space.ResumeBlobWriter("resumed", w.UploadID(), options)
+	suite.Require().NoError(err)
+	suite.Equal(int64(8), w.Offset())
+	_, err = w.Write(content[w.Offset():])
+	suite.Require().NoError(err)
+	suite.Require().NoError(w.Close())
+	n, err = space.ReadBlob("resumed", output)
+	suite.Require().NoError(err)
+	suite.Equal(content, output[:n])
+
+	// aborted uploads leave no chunks behind
+	entries, err := os.ReadDir(filepath.Join(dir, constant.BlobDir))
+	suite.Require().NoError(err)
+	w, err = space.NewBlobWriter("aborted", options)
+	suite.Require().NoError(err)
+	_, err = w.Write(content)
+	suite.Require().NoError(err)
+	suite.Require().NoError(w.Flush())
+	suite.Require().NoError(w.Abort())
+	after, err := os.ReadDir(filepath.Join(dir, constant.BlobDir))
+	suite.Require().NoError(err)
+	suite.Equal(len(entries), len(after))
+	_, err = space.GetBlobByteSize("aborted")
+	suite.ErrorIs(err, storage.ErrBlobNotExist)
+
+	for _, entry := range after {
+		suite.Require().NoError(os.WriteFile(filepath.Join(dir, constant.BlobDir, entry.Name()), []byte{9, 9, 9, 9}, 0666))
+	}
+	_, err = space.ReadBlobAt("blob", p, 0)
+	suite.ErrorIs(err, storage.ErrBlobCorrupted)
+}
+
+func (suite *SpaceTestSuite) TestBlobCompression() {
+	sc := createSchema()
+	suite.Require().NoError(sc.Validate())
+	opts := option.NewOptions(sc, -1)
+	opts.BlobCodec = blob.CodecZstd
+	space, err := storage.Open("file://"+suite.T().TempDir(), *opts)
+	suite.Require().NoError(err)
+	content := bytes.Repeat([]byte("index metadata "), 256)
+	suite.Require().NoError(commitErr(space.WriteBlob(content, "text", false)))
+	suite.Require().NoError(commitErr(space.WriteBlob([]byte{1, 2, 3}, "tiny", false)))
+
+	size, err := space.GetBlobByteSize("text")
+	suite.Require().NoError(err)
+	suite.Equal(int64(len(content)), size)
+	output := make([]byte, size)
+	n, err := space.ReadBlob("text", output)
+	suite.Require().NoError(err)
+	suite.Equal(content, output[:n])
+	p := make([]byte, 5)
+	_, err = space.ReadBlobAt("text", p, 6)
+	suite.Require().NoError(err)
+	suite.Equal([]byte("metad"), p)
+
+	stored, codec, err := space.ReadBlobRaw("text")
+	suite.Require().NoError(err)
+	suite.Equal(blob.CodecZstd, codec)
+	suite.Less(len(stored), len(content))
+	decompressed, err := codec.Decompress(stored, size)
+	suite.Require().NoError(err)
+	suite.Equal(content, decompressed)
+
+	// blobs that do not shrink are stored as is
+	stored, codec, err = space.ReadBlobRaw("tiny")
+	suite.Require().NoError(err)
+	suite.Equal(blob.CodecNone, codec)
+	suite.Equal([]byte{1, 2, 3}, stored)
+}
+
+func (suite *SpaceTestSuite) TestBlobCache() {
+	sc := createSchema()
+	suite.Require().NoError(sc.Validate())
+	dir := suite.T().TempDir()
+	cache := storage.NewLRUBlobCache(8)
+	opts := option.NewOptions(sc, -1)
+	opts.BlobCache = cache
+	space, err := storage.Open("file://"+dir, *opts)
+	suite.Require().NoError(err)
+	for i, name := range []string{"a", "b", "c"} {
+		suite.Require().NoError(commitErr(space.WriteBlob([]byte{byte(i), 1, 2, 3}, name, false)))
+	}
+	read := func(name string) ([]byte, error) {
+		output := make([]byte, 4)
+		n, err := space.ReadBlob(name, output)
+		if err != nil {
+			return nil, err
+		}
+		return output[:n], nil
+	}
+
+	content, err := read("a")
+	suite.Require().NoError(err)
+	suite.Equal([]byte{0, 1, 2, 3}, content)
+	// the second read is served from the cache even though the files are gone
+	suite.Require().NoError(os.RemoveAll(filepath.Join(dir, constant.BlobDir)))
+	content, err = read("a")
+	suite.Require().NoError(err)
+	suite.Equal([]byte{0, 1, 2, 3}, content)
+	hits, misses := cache.Stats()
+	suite.Equal(int64(1), hits)
+	suite.Equal(int64(1), misses)
+
+	// a is evicted once two other blobs of the same size are cached
+	cache.Add("x", make([]byte, 4))
+	cache.Add("y", make([]byte, 4))
+	_, err = read("a")
+	suite.Error(err)
+}
+
+func (suite *SpaceTestSuite) TestSignBlobURL() {
+	sc := createSchema()
+	suite.Require().NoError(sc.Validate())
+	space, err := storage.Open("file://"+suite.T().TempDir(), *option.NewOptions(sc, -1))
+	suite.Require().NoError(err)
+	suite.Require().NoError(commitErr(space.WriteBlob([]byte{1, 2}, "blob", false)))
+
+	signed, err := space.SignBlobURL("blob", time.Minute)
+	suite.Require().NoError(err)
+	u, err := url.Parse(signed)
+	suite.Require().NoError(err)
+	suite.Equal("file", u.Scheme)
+	content, err := os.ReadFile(u.Path)
+	suite.Require().NoError(err)
+	suite.Equal([]byte{1, 2}, content)
+
+	_, err = space.SignBlobURL("missing", time.Minute)
+	suite.ErrorIs(err, storage.ErrBlobNotExist)
+}
+
+func (suite *SpaceTestSuite) TestMirror() {
+	sc := createSchema()
+	suite.Require().NoError(sc.Validate())
+	mirrorDir := suite.T().TempDir()
+	opts := option.NewOptions(sc, -1)
+	opts.Mirror = &option.MirrorOptions{URI: "file://" + mirrorDir}
+	space, err := storage.Open("file://"+suite.T().TempDir(), *opts)
+	suite.Require().NoError(err)
+	suite.Require().NoError(commitErr(space.Write(createRecordReader(sc, []int64{1, 2}), option.NewWriteOption())))
+	suite.Require().NoError(commitErr(space.Write(createRecordReader(sc, []int64{3}), option.NewWriteOption())))
+	suite.Require().NoError(commitErr(space.WriteBlob([]byte{1, 2}, "blob", false)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	suite.Require().NoError(space.WaitMirror(ctx))
+	status, ok := space.MirrorStatus()
+	suite.Require().True(ok)
+	suite.NoError(status.LastError)
+	suite.Equal(0, status.Lag)
+	suite.Equal(space.GetCurrentVersion(), status.ReplicatedVersion)
+
+	// the mirror is a regular space that only references its own files
+	replica, err := storage.Open("file://"+mirrorDir, *option.NewOptions(nil, -1))
+	suite.Require().NoError(err)
+	suite.Equal(space.GetCurrentVersion(), replica.GetCurrentVersion())
+	readOpt := option.NewReadOptions()
+	readOpt.AddColumn("pk_field")
+	reader, err := replica.Read(readOpt)
+	suite.Require().NoError(err)
+	defer reader.Release()
+	var pks []int64
+	for reader.Next() {
+		rec := reader.Record()
+		pks = append(pks, rec.Column(rec.Schema().FieldIndices("pk_field")[0]).(*array.Int64).Int64Values()...)
+	}
+	suite.Require().NoError(reader.Err())
+	suite.ElementsMatch([]int64{1, 2, 3}, pks)
+	signed, err := replica.SignBlobURL("blob", time.Minute)
+	suite.Require().NoError(err)
+	suite.True(strings.HasPrefix(signed, "file://"+mirrorDir))
+}
+
+func (suite *SpaceTestSuite) TestReadReplica() {
+	sc := createSchema()
+	suite.Require().NoError(sc.Validate())
+	primaryDir, mirrorDir := suite.T().TempDir(), suite.T().TempDir()
+	opts := option.NewOptions(sc, -1)
+	opts.Mirror = &option.MirrorOptions{URI: "file://" + mirrorDir}
+	space, err := storage.Open("file://"+primaryDir, *opts)
+	suite.Require().NoError(err)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	suite.Require().NoError(commitErr(space.Write(createRecordReader(sc, []int64{1, 2}), option.NewWriteOption())))
+	suite.Require().NoError(commitErr(space.Write(createRecordReader(sc, []int64{3}), option.NewWriteOption())))
+	suite.Require().NoError(commitErr(space.WriteBlob([]byte{1}, "blob", false)))
+	suite.Require().NoError(commitErr(space.Write(createRecordReader(sc, []int64{4}), option.NewWriteOption())))
+	suite.Require().NoError(space.WaitMirror(ctx))
+
+	// the blob of version 3 is still being replicated, so versions 3 and 4 are incomplete
+	suite.Require().NoError(os.RemoveAll(filepath.Join(mirrorDir, constant.BlobDir)))
+	replicaOpts := option.NewOptions(nil, -1)
+	replicaOpts.Replica = &option.ReplicaOptions{PrimaryURI: "file://" + primaryDir}
+	replica, err := storage.Open("file://"+mirrorDir, *replicaOpts)
+	suite.Require().NoError(err)
+	suite.Equal(int64(2), replica.GetCurrentVersion())
+	lag, err := replica.ReplicaLag()
+	suite.NoError(err)
+	suite.Equal(int64(2), lag)
+
+	readOpt := option.NewReadOptions()
+	readOpt.AddColumn("pk_field")
+	reader, err := replica.Read(readOpt)
+	suite.Require().NoError(err)
+	defer reader.Release()
+	var pks []int64
+	for reader.Next() {
+		rec := reader.Record()
+		pks = append(pks, rec.Column(rec.Schema().FieldIndices("pk_field")[0]).(*array.Int64).Int64Values()...)
+	}
+	suite.Require().NoError(reader.Err())
+	suite.ElementsMatch([]int64{1, 2, 3}, pks)
+
+	_, err = replica.Write(createRecordReader(sc, []int64{5}), option.NewWriteOption())
+	suite.ErrorIs(err, storage.ErrReadOnlyReplica)
+	suite.ErrorIs(err, errors.ErrPermissionDenied)
+
+	replicaOpts.Replica.MaxLag = 1
+	_, err = storage.Open("file://"+mirrorDir, *replicaOpts)
+	suite.ErrorIs(err, storage.ErrReplicaTooStale)
+
+	suite.NoError(replica.Close())
+	_, err = replica.ReplicaLag()
+	suite.ErrorIs(err, storage.ErrSpaceClosed)
+}
+
+func (suite *SpaceTestSuite) TestQuota() {
+	sc := createSchema()
+	suite.Require().NoError(sc.Validate())
+	dir := suite.T().TempDir()
+	ops := option.NewOptions(sc, -1)
+	ops.Quota = &option.QuotaOptions{MaxRows: 3, MaxBytes: 1 << 20}
+	space, err := storage.Open("file://"+dir, *ops)
+	suite.Require().NoError(err)
+
+	suite.Require().NoError(commitErr(space.Write(createRecordReader(sc, []int64{1, 2}), option.NewWriteOption())))
+	usage := space.Usage()
+	suite.Equal(int64(2), usage.Rows)
+	suite.Greater(usage.Bytes, int64(0))
+
+	err = commitErr(space.Write(createRecordReader(sc, []int64{3, 4}), option.NewWriteOption()))
+	suite.ErrorIs(err, storage.ErrQuotaExceeded)
+	suite.ErrorIs(err, errors.ErrResourceExhausted)
+	suite.Equal(usage, space.Usage())
+	// the files of the rejected write are removed
+	suite.Len(dataFiles(suite.T(), dir), 2)
+
+	suite.NoError(commitErr(space.WriteBlob(make([]byte, 1024), "blob", false)))
+	suite.Equal(usage.Bytes+1024, space.Usage().Bytes)
+	suite.ErrorIs(commitErr(space.WriteBlob(make([]byte, 1<<20), "other", false)), storage.ErrQuotaExceeded)
+	// replacing releases the size of the old blob
+	suite.NoError(commitErr(space.WriteBlob(make([]byte, 512), "blob", true)))
+	suite.Equal(usage.Bytes+512, space.Usage().Bytes)
+
+	// usage is persisted in the manifest
+	reopened, err := storage.Open("file://"+dir, *ops)
+	suite.Require().NoError(err)
+	suite.Equal(space.Usage(), reopened.Usage())
+}
+
+func (suite *SpaceTestSuite) TestAuthorizer() {
+	sc := createSchema()
+	suite.Require().NoError(sc.Validate())
+	var ops []auth.Operation
+	options := option.NewOptions(sc, -1)
+	options.Authorizer = auth.AuthorizerFunc(func(ctx context.Context, op auth.Operation, path string, identity auth.Identity) error {
+		ops = append(ops, op)
+		if identity.User == "admin" || (identity.User == "reader" && (op == auth.OpRead || op == auth.OpReadBlob)) {
+			return nil
+		}
+		return fmt.Errorf("%s not allowed", identity.User)
+	})
+	space, err := storage.Open("file://"+suite.T().TempDir(), *options)
+	suite.Require().NoError(err)
+
+	admin := auth.WithIdentity(context.Background(), auth.Identity{User: "admin"})
+	reader := auth.WithIdentity(context.Background(), auth.Identity{User: "reader"})
+	suite.NoError(commitErr(space.WriteContext(admin, createRecordReader(sc, []int64{1}), option.NewWriteOption())))
+	suite.ErrorIs(commitErr(space.WriteContext(reader, createRecordReader(sc, []int64{2}), option.NewWriteOption())), errors.ErrPermissionDenied)
+	suite.ErrorIs(commitErr(space.Write(createRecordReader(sc, []int64{2}), option.NewWriteOption())), errors.ErrPermissionDenied)
+	suite.Equal(int64(1), space.GetCurrentVersion())
+
+	readOpt := option.NewReadOptions()
+	readOpt.AddColumn("pk_field")
+	r, err := space.ReadContext(reader, readOpt)
+	suite.Require().NoError(err)
+	r.Release()
+	suite.NoError(commitErr(space.WriteBlobContext(admin, []byte{1}, "blob", false)))
+	_, err = space.GetBlobByteSizeContext(reader, "blob")
+	suite.NoError(err)
+	suite.ErrorIs(space.ReleaseLeaseContext(reader), errors.ErrPermissionDenied)
+	_, err = space.ReadAuditRecordsContext(reader)
+	suite.ErrorIs(err, errors.ErrPermissionDenied)
+	suite.Equal([]auth.Operation{auth.OpWrite, auth.OpWrite, auth.OpWrite, auth.OpRead, auth.OpWriteBlob, auth.OpReadBlob, auth.OpAdmin, auth.OpAdmin}, ops)
+}
+
+func (suite *SpaceTestSuite) TestAudit() {
+	sc := createSchema()
+	suite.Require().NoError(sc.Validate())
+	options := option.NewOptions(sc, -1)
+	options.Audit = &option.AuditOptions{}
+	space, err := storage.Open("file://"+suite.T().TempDir(), *options)
+	suite.Require().NoError(err)
+
+	ctx := auth.WithIdentity(context.Background(), auth.Identity{User: "alice"})
+	suite.Require().NoError(commitErr(space.WriteContext(ctx, createRecordReader(sc, []int64{1, 2}), option.NewWriteOption())))
+	suite.Require().NoError(commitErr(space.WriteBlob([]byte{1}, "blob", false)))
+
+	records, err := space.ReadAuditRecords()
+	suite.Require().NoError(err)
+	suite.Require().Len(records, 2)
+	suite.Equal("alice", records[0].User)
+	suite.Equal(int64(1), records[0].Version)
+	suite.Equal(auth.OpWrite, records[0].Operation)
+	suite.Equal(int64(2), records[0].Rows)
+	suite.Len(records[0].Files, 2)
+	suite.Equal("", records[1].User)
+	suite.Equal(int64(2), records[1].Version)
+	suite.Equal(auth.OpWriteBlob, records[1].Operation)
+
+	suite.Require().NoError(space.Close())
+	_, err = space.ReadAuditRecords()
+	suite.ErrorIs(err, storage.ErrSpaceClosed)
+}
+
+func (suite *SpaceTestSuite) TestMasking() {
+	as := arrow.NewSchema([]arrow.Field{
+		{Name: "pk_field", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "vs_field", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "email", Type: arrow.BinaryTypes.String},
+		{Name: "phone", Type: arrow.BinaryTypes.String},
+		{Name: "ssn", Type: arrow.BinaryTypes.String},
+		{Name: "vec_field", Type: &arrow.FixedSizeBinaryType{ByteWidth: 4}},
+	}, nil)
+	sc := schema.NewSchema(as, &schema_option.SchemaOptions{PrimaryColumn: "pk_field", VersionColumn: "vs_field", VectorColumn: "vec_field"})
+	suite.Require().NoError(sc.Validate())
+	options := option.NewOptions(sc, -1)
+	options.Masking = func(identity auth.Identity) map[string]option.MaskRule {
+		if identity.User == "admin" {
+			return nil
+		}
+		return map[string]option.MaskRule{
+			"email": {Type: option.MaskHash, Salt: []byte("salt")},
+			"phone": {Type: option.MaskPartial, KeepSuffix: 4},
+			"ssn":   {Type: option.MaskNull},
+		}
+	}
+	space, err := storage.Open("file://"+suite.T().TempDir(), *options)
+	suite.Require().NoError(err)
+
+	b := array.NewRecordBuilder(memory.DefaultAllocator, as)
+	defer b.Release()
+	b.Field(0).(*array.Int64Builder).AppendValues([]int64{1, 2}, nil)
+	b.Field(1).(*array.Int64Builder).AppendValues([]int64{1, 1}, nil)
+	b.Field(2).(*array.StringBuilder).AppendValues([]string{"a@x.com", "b@x.com"}, nil)
+	b.Field(3).(*array.StringBuilder).AppendValues([]string{"5551234567", "123"}, nil)
+	b.Field(4).(*array.StringBuilder).AppendValues([]string{"111-22-3333", "444-55-6666"}, nil)
+	b.Field(5).(*array.FixedSizeBinaryBuilder).AppendValues([][]byte{{1, 1, 1, 1}, {2, 2, 2, 2}}, nil)
+	rec := b.NewRecord()
+	reader, err := array.NewRecordReader(as, []arrow.Record{rec})
+	suite.Require().NoError(err)
+	suite.Require().NoError(commitErr(space.Write(reader, option.NewWriteOption())))
+
+	// read returns the values of the string columns, null values as "<null>"
+	read := func(ctx context.Context) map[string][]string {
+		readOpt := option.NewReadOptions()
+		readOpt.SetColumns([]string{"pk_field", "email", "phone", "ssn"})
+		r, err := space.ReadContext(ctx, readOpt)
+		suite.Require().NoError(err)
+		defer r.Release()
+		values := make(map[string][]string)
+		for r.Next() {
+			rec := r.Record()
+			for i, col := range rec.Columns() {
+				if strs, ok := col.(*array.String); ok {
+					for j := 0; j < strs.Len(); j++ {
+						v := strs.Value(j)
+						if strs.IsNull(j) {
+							v = "<null>"
+						}
+						values[rec.ColumnName(i)] = append(values[rec.ColumnName(i)], v)
+					}
+				}
+			}
+		}
+		suite.Require().NoError(r.Err())
+		return values
+	}
+
+	masked := read(context.Background())
+	suite.Len(masked["email"][0], 64)
+	suite.NotEqual(masked["email"][0], masked["email"][1])
+	suite.Equal([]string{"******4567", "***"}, masked["phone"])
+	suite.Equal([]string{"<null>", "<null>"}, masked["ssn"])
+
+	plain := read(auth.WithIdentity(context.Background(), auth.Identity{User: "admin"}))
+	suite.Equal([]string{"a@x.com", "b@x.com"}, plain["email"])
+
+	readOpt := option.NewReadOptions()
+	readOpt.AddFilter(filter.NewConstantFilter(filter.Equal, "ssn", "111-22-3333"))
+	_, err = space.ReadContext(context.Background(), readOpt)
+	suite.ErrorIs(err, errors.ErrPermissionDenied)
+}
+
+func (suite *SpaceTestSuite) TestRowFilter() {
+	as := arrow.NewSchema([]arrow.Field{
+		{Name: "pk_field", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "vs_field", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "tenant_id", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "vec_field", Type: &arrow.FixedSizeBinaryType{ByteWidth: 4}},
+	}, nil)
+	sc := schema.NewSchema(as, &schema_option.SchemaOptions{PrimaryColumn: "pk_field", VersionColumn: "vs_field", VectorColumn: "vec_field"})
+	suite.Require().NoError(sc.Validate())
+	tenants := map[string]int64{"alice": 1, "bob": 2}
+	options := option.NewOptions(sc, -1)
+	options.RowFilter = func(identity auth.Identity) ([]filter.Filter, error) {
+		tenant, ok := tenants[identity.User]
+		if !ok {
+			return nil, fmt.Errorf("no tenant")
+		}
+		return []filter.Filter{filter.NewConstantFilter(filter.Equal, "tenant_id", tenant)}, nil
+	}
+	space, err := storage.Open("file://"+suite.T().TempDir(), *options)
+	suite.Require().NoError(err)
+
+	b := array.NewRecordBuilder(memory.DefaultAllocator, as)
+	defer b.Release()
+	b.Field(0).(*array.Int64Builder).AppendValues([]int64{1, 2, 3}, nil)
+	b.Field(1).(*array.Int64Builder).AppendValues([]int64{1, 1, 1}, nil)
+	b.Field(2).(*array.Int64Builder).AppendValues([]int64{1, 2, 1}, nil)
+	b.Field(3).(*array.FixedSizeBinaryBuilder).AppendValues([][]byte{{1, 1, 1, 1}, {2, 2, 2, 2}, {3, 3, 3, 3}}, nil)
+	rec := b.NewRecord()
+	reader, err := array.NewRecordReader(as, []arrow.Record{rec})
+	suite.Require().NoError(err)
+	suite.Require().NoError(commitErr(space.Write(reader, option.NewWriteOption())))
+
+	read := func(user string, readOpt *option.ReadOptions) ([]int64, error) {
+		r, err := space.ReadContext(auth.WithIdentity(context.Background(), auth.Identity{User: user}), readOpt)
+		if err != nil {
+			return nil, err
+		}
+		defer r.Release()
+		var pks []int64
+		for r.Next() {
+			pks = append(pks, r.Record().Column(0).(*array.Int64).Int64Values()...)
+		}
+		return pks, r.Err()
+	}
+
+	readOpt := option.NewReadOptions()
+	readOpt.AddColumn("pk_field")
+	pks, err := read("alice", readOpt)
+	suite.NoError(err)
+	suite.ElementsMatch([]int64{1, 3}, pks)
+	// the injected filters are not added to the options of the caller
+	suite.Equal([]string{"pk_field"}, readOpt.Columns)
+	pks, err = read("bob", readOpt)
+	suite.NoError(err)
+	suite.ElementsMatch([]int64{2}, pks)
+
+	// filters of the caller cannot widen the visible rows
+	readOpt.AddFilter(filter.NewConstantFilter(filter.Equal, "tenant_id", int64(2)))
+	readOpt.AddColumn("tenant_id")
+	pks, err = read("alice", readOpt)
+	suite.NoError(err)
+	suite.Empty(pks)
+
+	_, err = read("mallory", option.NewReadOptions())
+	suite.ErrorIs(err, errors.ErrPermissionDenied)
+}
+
+func (suite *SpaceTestSuite) TestSoftDrop() {
+	sc := createSchema()
+	suite.Require().NoError(sc.Validate())
+	dir := suite.T().TempDir()
+	uri := "file://" + dir
+	space, err := storage.Open(uri, *option.NewOptions(sc, -1))
+	suite.Require().NoError(err)
+	suite.Require().NoError(commitErr(space.Write(createRecordReader(sc, []int64{1, 2}), option.NewWriteOption())))
+	suite.Require().NoError(commitErr(space.WriteBlob([]byte{1}, "blob", false)))
+
+	suite.Require().NoError(storage.SoftDrop(uri))
+	_, err = storage.Open(uri, *option.NewOptions(sc, -1))
+	suite.ErrorIs(err, storage.ErrSpaceDropped)
+	suite.ErrorIs(err, errors.ErrNotFound)
+	suite.ErrorIs(commitErr(space.Write(createRecordReader(sc, []int64{3}), option.NewWriteOption())), errors.ErrConflict)
+
+	suite.Require().NoError(storage.Restore(uri))
+	suite.ErrorIs(storage.Restore(uri), storage.ErrSpaceNotDropped)
+	restored, err := storage.Open(uri, *option.NewOptions(sc, -1))
+	suite.Require().NoError(err)
+	pks, err := readPks(restored)
+	suite.NoError(err)
+	suite.ElementsMatch([]int64{1, 2}, pks)
+
+	suite.Require().NoError(storage.SoftDrop(uri))
+	// still in the grace period
+	suite.Require().NoError(storage.Vacuum(uri, option.NewVacuumOptions()))
+	suite.Require().NoError(storage.Restore(uri))
+	suite.Require().NoError(storage.SoftDrop(uri))
+	suite.Require().NoError(storage.Vacuum(uri, &option.VacuumOptions{DropGracePeriod: 0}))
+	_, err = os.Stat(dir)
+	suite.True(os.IsNotExist(err))
+	suite.ErrorIs(storage.Restore(uri), storage.ErrManifestNotFound)
+}
+
+func createDeleteReader(sc *schema.Schema, pks []int64, versions []int64) array.RecordReader {
+	as := sc.DeleteSchema()
+	b := array.NewRecordBuilder(memory.DefaultAllocator, as)
+	defer b.Release()
+	b.Field(0).(*array.Int64Builder).AppendValues(pks, nil)
+	b.Field(1).(*array.Int64Builder).AppendValues(versions, nil)
+	recReader, err := array.NewRecordReader(as, []arrow.Record{b.NewRecord()})
+	if err != nil {
+		panic(err)
+	}
+	return recReader
+}
+
+func (suite *SpaceTestSuite) TestCompactPurgeDeletes() {
+	sc := createSchema()
+	suite.Require().NoError(sc.Validate())
+	space, err := storage.Open("file://"+suite.T().TempDir(), *option.NewOptions(sc, -1))
+	suite.Require().NoError(err)
+	suite.Require().NoError(commitErr(space.Write(createRecordReader(sc, []int64{1, 2, 3}), option.NewWriteOption())))
+	suite.Require().NoError(commitErr(space.Write(createRecordReader(sc, []int64{4, 5}), option.NewWriteOption())))
+	// pk 3 was written with version 3 so the delete at version 1 does not remove it
+	suite.Require().NoError(commitErr(space.Delete(createDeleteReader(sc, []int64{2, 3, 4, 5}, []int64{2, 1, 4, 5}))))
+	before := space.Usage()
+
+	suite.Require().NoError(versionErr(space.Compact(option.NewCompactOptions())))
+	suite.Equal(int64(4), space.GetCurrentVersion())
+	suite.Equal(before.Rows-3, space.Usage().Rows)
+
+	readOpt := option.NewReadOptions()
+	readOpt.AddColumn("pk_field")
+	readOpt.AddColumn("vec_field")
+	reader, err := space.Read(readOpt)
+	suite.Require().NoError(err)
+	defer reader.Release()
+	var pks []int64
+	for reader.Next() {
+		rec := reader.Record()
+		pkColumn := rec.Column(0).(*array.Int64)
+		vecColumn := rec.Column(1).(*array.FixedSizeBinary)
+		for i := 0; i < int(rec.NumRows()); i++ {
+			// scalar and vector rows stay aligned
+			suite.Equal(byte(pkColumn.Value(i)), vecColumn.Value(i)[0])
+			pks = append(pks, pkColumn.Value(i))
+		}
+	}
+	suite.Require().NoError(reader.Err())
+	suite.ElementsMatch([]int64{1, 3}, pks)
+
+	// the delete fragment was applied, compacting again commits nothing
+	suite.Require().NoError(versionErr(space.Compact(option.NewCompactOptions())))
+	suite.Equal(int64(4), space.GetCurrentVersion())
+}
+
+func (suite *SpaceTestSuite) TestReadAppliesDeletes() {
+	sc := createSchema()
+	suite.Require().NoError(sc.Validate())
+	uri := "file://" + suite.T().TempDir()
+	space, err := storage.Open(uri, *option.NewOptions(sc, -1))
+	suite.Require().NoError(err)
+	suite.Require().NoError(commitErr(space.Write(createRecordReader(sc, []int64{1, 2, 3}), option.NewWriteOption())))
+	// pk 3 was written with version 3 so the delete at version 1 does not remove it
+	suite.Require().NoError(commitErr(space.Delete(createDeleteReader(sc, []int64{2, 3}, []int64{2, 1}))))
+	pks, err := readPks(space)
+	suite.Require().NoError(err)
+	suite.ElementsMatch([]int64{1, 3}, pks)
+
+	readOpt := option.NewReadOptions()
+	readOpt.AddColumn("pk_field")
+	readOpt.IncludeDeleted = true
+	reader, err := space.Read(readOpt)
+	suite.Require().NoError(err)
+	deleted := make(map[int64]bool)
+	for reader.Next() {
+		rec := reader.Record()
+		pkColumn := rec.Column(rec.Schema().FieldIndices("pk_field")[0]).(*array.Int64)
+		deletedColumn := rec.Column(rec.Schema().FieldIndices(constant.DeletedFieldName)[0]).(*array.Boolean)
+		for i := 0; i < int(rec.NumRows()); i++ {
+			deleted[pkColumn.Value(i)] = deletedColumn.Value(i)
+		}
+	}
+	suite.Require().NoError(reader.Err())
+	reader.Release()
+	suite.Equal(map[int64]bool{1: false, 2: true, 3: false}, deleted)
+
+	// writing pk 2 again after its delete makes it visible
+	suite.Require().NoError(commitErr(space.Write(createVersionedRecordReader(sc, []int64{2}, []int64{7}), option.NewWriteOption())))
+	pks, err = readPks(space)
+	suite.Require().NoError(err)
+	suite.ElementsMatch([]int64{1, 2, 3}, pks)
+
+	// the columns used to match deletes are not returned unless requested
+	readOpt = option.NewReadOptions()
+	readOpt.AddColumn("vec_field")
+	reader, err = space.Read(readOpt)
+	suite.Require().NoError(err)
+	defer reader.Release()
+	var firstBytes []byte
+	for reader.Next() {
+		rec := reader.Record()
+		vecColumn := rec.Column(rec.Schema().FieldIndices("vec_field")[0]).(*array.FixedSizeBinary)
+		suite.Empty(rec.Schema().FieldIndices("pk_field"))
+		for i := 0; i < int(rec.NumRows()); i++ {
+			firstBytes = append(firstBytes, vecColumn.Value(i)[0])
+		}
+	}
+	suite.Require().NoError(reader.Err())
+	suite.ElementsMatch([]byte{1, 2, 3}, firstBytes)
+
+	reopened, err := storage.Open(uri, option.Options{Version: -1})
+	suite.Require().NoError(err)
+	pks, err = readPks(reopened)
+	suite.Require().NoError(err)
+	suite.ElementsMatch([]int64{1, 2, 3}, pks)
+}
+
+func (suite *SpaceTestSuite) TestReadPage() {
+	sc := createSchema()
+	suite.Require().NoError(sc.Validate())
+	space, err := storage.Open("file://"+suite.T().TempDir(), *option.NewOptions(sc, -1))
+	suite.Require().NoError(err)
+	suite.Require().NoError(commitErr(space.Write(createRecordReader(sc, []int64{1, 2, 3, 4, 5}), &option.WriteOptions{MaxRecordPerFile: 2})))
+	suite.Require().NoError(commitErr(space.Write(createRecordReader(sc, []int64{6, 7}), option.NewWriteOption())))
+	suite.Require().NoError(commitErr(space.Delete(createDeleteReader(sc, []int64{4}, []int64{4}))))
+
+	readOpt := option.NewReadOptions()
+	readOpt.AddColumn("pk_field")
+	readPage := func(token string) ([]int64, string) {
+		reader, err := space.ReadPage(readOpt, token, 2)
+		suite.Require().NoError(err)
+		defer reader.Release()
+		var pks []int64
+		for reader.Next() {
+			rec := reader.Record()
+			pks = append(pks, rec.Column(rec.Schema().FieldIndices("pk_field")[0]).(*array.Int64).Int64Values()...)
+		}
+		suite.Require().NoError(reader.Err())
+		return pks, reader.NextPageToken()
+	}
+
+	var (
+		pks   []int64
+		token string
+		pages int
+	)
+	for {
+		page, next := readPage(token)
+		suite.LessOrEqual(len(page), 2)
+		pks = append(pks, page...)
+		pages++
+		if next == "" {
+			break
+		}
+		token = next
+		if pages == 1 {
+			// pages keep reading the version of the first page
+			suite.Require().NoError(commitErr(space.Write(createRecordReader(sc, []int64{8}), option.NewWriteOption())))
+		}
+	}
+	suite.Equal([]int64{1, 2, 3, 5, 6, 7}, pks)
+
+	_, err = space.ReadPage(readOpt, "garbage", 2)
+	suite.ErrorIs(err, storage.ErrInvalidPageToken)
+}
+
+func (suite *SpaceTestSuite) TestReadOrderBy() {
+	sc := createSchema()
+	suite.Require().NoError(sc.Validate())
+	space, err := storage.Open("file://"+suite.T().TempDir(), *option.NewOptions(sc, -1))
+	suite.Require().NoError(err)
+	suite.Require().NoError(commitErr(space.Write(createRecordReader(sc, []int64{5, 1, 9, 3}), &option.WriteOptions{MaxRecordPerFile: 2})))
+	suite.Require().NoError(commitErr(space.Write(createRecordReader(sc, []int64{8, 2, 7}), option.NewWriteOption())))
+	suite.Require().NoError(commitErr(space.Delete(createDeleteReader(sc, []int64{7}, []int64{7}))))
+
+	read := func(orderBy option.OrderBy, columns ...string) ([]int64, []byte) {
+		readOpt := option.NewReadOptions()
+		readOpt.SetColumns(columns)
+		readOpt.OrderBy = orderBy
+		reader, err := space.Read(readOpt)
+		suite.Require().NoError(err)
+		defer reader.Release()
+		var (
+			pks        []int64
+			firstBytes []byte
+		)
+		for reader.Next() {
+			rec := reader.Record()
+			if indices := rec.Schema().FieldIndices("pk_field"); len(indices) > 0 {
+				pks = append(pks, rec.Column(indices[0]).(*array.Int64).Int64Values()...)
+			}
+			if indices := rec.Schema().FieldIndices("vec_field"); len(indices) > 0 {
+				vecColumn := rec.Column(indices[0]).(*array.FixedSizeBinary)
+				for i := 0; i < int(rec.NumRows()); i++ {
+					firstBytes = append(firstBytes, vecColumn.Value(i)[0])
+				}
+			}
+		}
+		suite.Require().NoError(reader.Err())
+		return pks, firstBytes
+	}
+
+	pks, _ := read(option.OrderBy{Type: option.OrderFragment}, "pk_field")
+	suite.Equal([]int64{5, 1, 9, 3, 8, 2}, pks)
+	pks, firstBytes := read(option.OrderBy{Type: option.OrderKey, Column: "pk_field"}, "pk_field", "vec_field")
+	suite.Equal([]int64{1, 2, 3, 5, 8, 9}, pks)
+	suite.Equal([]byte{1, 2, 3, 5, 8, 9}, firstBytes)
+	// the key column is not returned unless requested
+	pks, firstBytes = read(option.OrderBy{Type: option.OrderKey, Column: "pk_field"}, "vec_field")
+	suite.Empty(pks)
+	suite.Equal([]byte{1, 2, 3, 5, 8, 9}, firstBytes)
+
+	readOpt := option.NewReadOptions()
+	readOpt.AddColumn("pk_field")
+	readOpt.OrderBy = option.OrderBy{Type: option.OrderKey, Column: "missing"}
+	_, err = space.Read(readOpt)
+	suite.ErrorIs(err, storage.ErrColumnNotExist)
+	readOpt.OrderBy = option.OrderBy{Type: option.OrderKey, Column: "vec_field"}
+	_, err = space.Read(readOpt)
+	suite.ErrorIs(err, record_reader.ErrUnsupportedOrderColumn)
+}
+
+func (suite *SpaceTestSuite) TestAggregate() {
+	sc := createSchema()
+	suite.Require().NoError(sc.Validate())
+	space, err := storage.Open("file://"+suite.T().TempDir(), *option.NewOptions(sc, -1))
+	suite.Require().NoError(err)
+	suite.Require().NoError(commitErr(space.Write(createRecordReader(sc, []int64{3, 1, 5}), &option.WriteOptions{MaxRecordPerFile: 2})))
+	suite.Require().NoError(commitErr(space.Write(createRecordReader(sc, []int64{2, 4}), option.NewWriteOption())))
+
+	aggs := []option.AggSpec{
+		{Func: option.AggCount},
+		{Func: option.AggMin, Column: "pk_field"},
+		{Func: option.AggMax, Column: "pk_field"},
+		{Func: option.AggSum, Column: "pk_field"},
+	}
+	values, err := space.Aggregate(aggs, nil)
+	suite.Require().NoError(err)
+	suite.Equal([]any{int64(5), int64(1), int64(5), int64(15)}, values)
+
+	values, err = space.Aggregate(aggs, []filter.Filter{filter.NewConstantFilter(filter.GreaterThan, "pk_field", int64(2))})
+	suite.Require().NoError(err)
+	suite.Equal([]any{int64(3), int64(3), int64(5), int64(12)}, values)
+
+	// deleted rows are not aggregated
+	suite.Require().NoError(commitErr(space.Delete(createDeleteReader(sc, []int64{5}, []int64{5}))))
+	values, err = space.Aggregate(aggs, nil)
+	suite.Require().NoError(err)
+	suite.Equal([]any{int64(4), int64(1), int64(4), int64(10)}, values)
+
+	values, err = space.Aggregate(aggs[1:2], []filter.Filter{filter.NewConstantFilter(filter.GreaterThan, "pk_field", int64(9))})
+	suite.Require().NoError(err)
+	suite.Equal([]any{nil}, values)
+
+	_, err = space.Aggregate([]option.AggSpec{{Func: option.AggSum, Column: "vec_field"}}, nil)
+	suite.ErrorIs(err, storage.ErrUnsupportedAggregate)
+	_, err = space.Aggregate([]option.AggSpec{{Func: option.AggMax, Column: "missing"}}, nil)
+	suite.ErrorIs(err, storage.ErrColumnNotExist)
+}
+
+func (suite *SpaceTestSuite) TestGroupBy() {
+	sc := createSchema()
+	suite.Require().NoError(sc.Validate())
+	space, err := storage.Open("file://"+suite.T().TempDir(), *option.NewOptions(sc, -1))
+	suite.Require().NoError(err)
+	suite.Require().NoError(commitErr(space.Write(createVersionedRecordReader(sc, []int64{1, 2, 3, 4, 5, 6}, []int64{1, 1, 2, 2, 2, 3}), option.NewWriteOption())))
+
+	aggs := []option.AggSpec{{Func: option.AggSum, Column: "pk_field"}, {Func: option.AggMax, Column: "pk_field"}}
+	groups, err := space.GroupBy("vs_field", aggs, nil, 0)
+	suite.Require().NoError(err)
+	suite.Equal([]storage.Group{
+		{Key: int64(2), Rows: 3, Values: []any{int64(12), int64(5)}},
+		{Key: int64(1), Rows: 2, Values: []any{int64(3), int64(2)}},
+		{Key: int64(3), Rows: 1, Values: []any{int64(6), int64(6)}},
+	}, groups)
+
+	// top 1 of the rows with pk_field < 5
+	groups, err = space.GroupBy("vs_field", nil, []filter.Filter{filter.NewConstantFilter(filter.LessThan, "pk_field", int64(5))}, 1)
+	suite.Require().NoError(err)
+	suite.Equal([]storage.Group{{Key: int64(1), Rows: 2, Values: []any{}}}, groups)
+
+	_, err = space.GroupBy("vec_field", nil, nil, 0)
+	suite.ErrorIs(err, storage.ErrUnsupportedAggregate)
+}
+
+func (suite *SpaceTestSuite) TestCompactDeletes() {
+	sc := createSchema()
+	suite.Require().NoError(sc.Validate())
+	space, err := storage.Open("file://"+suite.T().TempDir(), *option.NewOptions(sc, -1))
+	suite.Require().NoError(err)
+	suite.Require().NoError(commitErr(space.Write(createRecordReader(sc, []int64{1, 2, 3}), option.NewWriteOption())))
+	// pk 2 is deleted twice, pk 3 was written after its delete and pk 9 was never written
+	suite.Require().NoError(commitErr(space.Delete(createDeleteReader(sc, []int64{2, 3}, []int64{2, 1}))))
+	suite.Require().NoError(commitErr(space.Delete(createDeleteReader(sc, []int64{2, 9}, []int64{5, 9}))))
+	before := space.Usage()
+	stats, err := space.DeleteStats()
+	suite.Require().NoError(err)
+	suite.Equal(storage.DeleteStats{Fragments: 2, Deletes: 4, Keys: 3, StoredRows: 3, LiveRows: 0}, stats)
+
+	suite.Require().NoError(versionErr(space.CompactDeletes()))
+	suite.Equal(int64(4), space.GetCurrentVersion())
+	stats, err = space.DeleteStats()
+	suite.Require().NoError(err)
+	suite.Equal(storage.DeleteStats{Fragments: 1, Deletes: 1, Keys: 1, StoredRows: 3, LiveRows: 2}, stats)
+	suite.Less(space.Usage().Bytes, before.Bytes)
+	// a single delete of pk 2 is left, so there is nothing more to compact
+	suite.Require().NoError(versionErr(space.CompactDeletes()))
+	suite.Equal(int64(4), space.GetCurrentVersion())
+
+	suite.Require().NoError(versionErr(space.Compact(option.NewCompactOptions())))
+	suite.Equal(before.Rows-1, space.Usage().Rows)
+	readOpt := option.NewReadOptions()
+	readOpt.AddColumn("pk_field")
+	reader, err := space.Read(readOpt)
+	suite.Require().NoError(err)
+	defer reader.Release()
+	var pks []int64
+	for reader.Next() {
+		rec := reader.Record()
+		pks = append(pks, rec.Column(rec.Schema().FieldIndices("pk_field")[0]).(*array.Int64).Int64Values()...)
+	}
+	suite.Require().NoError(reader.Err())
+	suite.ElementsMatch([]int64{1, 3}, pks)
+}
+
+type recordingPolicy struct {
+	policy option.CompactionPolicy
+	files  []option.CompactionFile
+}
+
+func (p *recordingPolicy) Plan(files []option.CompactionFile) [][]option.CompactionFile {
+	p.files = files
+	return p.policy.Plan(files)
+}
+
+func (suite *SpaceTestSuite) TestCompactBinPacking() {
+	sc := createSchema()
+	suite.Require().NoError(sc.Validate())
+	space, err := storage.Open("file://"+suite.T().TempDir(), *option.NewOptions(sc, -1))
+	suite.Require().NoError(err)
+	for _, pks := range [][]int64{{1, 2}, {3}, {4, 5, 6}} {
+		suite.Require().NoError(commitErr(space.Write(createRecordReader(sc, pks), option.NewWriteOption())))
+	}
+
+	policy := &recordingPolicy{policy: option.NewBinPackingPolicy()}
+	suite.Require().NoError(versionErr(space.Compact(&option.CompactOptions{Policy: policy})))
+	suite.Len(policy.files, 3)
+	suite.Equal(int64(4), space.GetCurrentVersion())
+
+	suite.Require().NoError(versionErr(space.Compact(&option.CompactOptions{Policy: policy})))
+	suite.Require().Len(policy.files, 1)
+	// a single file has nothing to merge with
+	suite.Equal(int64(4), space.GetCurrentVersion())
+
+	pks, err := readPks(space)
+	suite.Require().NoError(err)
+	suite.ElementsMatch([]int64{1, 2, 3, 4, 5, 6}, pks)
+
+	binPacking := &option.BinPackingPolicy{SmallFileBytes: 10, TargetFileBytes: 100}
+	groups := binPacking.Plan([]option.CompactionFile{
+		{Fragment: 1, Bytes: 60}, {Fragment: 2, Bytes: 50}, {Fragment: 3, Bytes: 5},
+		{Fragment: 4, Bytes: 5}, {Fragment: 5, Bytes: 9},
+	})
+	suite.Equal([][]option.CompactionFile{{{Fragment: 5, Bytes: 9}, {Fragment: 3, Bytes: 5}, {Fragment: 4, Bytes: 5}}}, groups)
+	binPacking.SmallFileBytes = 100
+	groups = binPacking.Plan([]option.CompactionFile{{Fragment: 1, Bytes: 60}, {Fragment: 2, Bytes: 50}, {Fragment: 3, Bytes: 40}})
+	suite.Equal([][]option.CompactionFile{{{Fragment: 1, Bytes: 60}, {Fragment: 3, Bytes: 40}}}, groups)
+}
+
+func (suite *SpaceTestSuite) TestCluster() {
+	sc := createSchema()
+	suite.Require().NoError(sc.Validate())
+	space, err := storage.Open("file://"+suite.T().TempDir(), *option.NewOptions(sc, -1))
+	suite.Require().NoError(err)
+	suite.Require().NoError(commitErr(space.Write(createRecordReader(sc, []int64{5, 1, 4}), option.NewWriteOption())))
+	suite.Require().NoError(commitErr(space.Write(createRecordReader(sc, []int64{3, 2}), option.NewWriteOption())))
+
+	suite.ErrorIs(space.Cluster([]string{"vec_field"}), storage.ErrColumnNotExist)
+	suite.Require().NoError(space.Cluster([]string{"pk_field", "vs_field"}))
+	suite.Equal(int64(3), space.GetCurrentVersion())
+
+	readOpt := option.NewReadOptions()
+	readOpt.AddColumn("pk_field")
+	readOpt.AddColumn("vec_field")
+	reader, err := space.Read(readOpt)
+	suite.Require().NoError(err)
+	defer reader.Release()
+	var pks []int64
+	for reader.Next() {
+		rec := reader.Record()
+		for i := 0; i < int(rec.NumRows()); i++ {
+			pk := rec.Column(0).(*array.Int64).Value(i)
+			suite.Equal(byte(pk), rec.Column(1).(*array.FixedSizeBinary).Value(i)[0])
+			pks = append(pks, pk)
+		}
+	}
+	suite.Require().NoError(reader.Err())
+	// pk and version are equal, so the Z-order is the pk order
+	suite.Equal([]int64{1, 2, 3, 4, 5}, pks)
+}
+
+func (suite *SpaceTestSuite) TestCountRows() {
+	sc := createSchema()
+	suite.Require().NoError(sc.Validate())
+	uri := "file://" + suite.T().TempDir()
+	space, err := storage.Open(uri, *option.NewOptions(sc, -1))
+	suite.Require().NoError(err)
+	suite.Require().NoError(commitErr(space.Write(createRecordReader(sc, []int64{1, 2, 3}), &option.WriteOptions{MaxRecordPerFile: 2})))
+	suite.Require().NoError(commitErr(space.Write(createRecordReader(sc, []int64{4}), option.NewWriteOption())))
+	rows, err := space.CountRows()
+	suite.Require().NoError(err)
+	suite.Equal(int64(4), rows)
+
+	suite.Require().NoError(commitErr(space.Delete(createDeleteReader(sc, []int64{1}, []int64{1}))))
+	rows, err = space.CountRows()
+	suite.Require().NoError(err)
+	// deleted rows are counted until compaction
+	suite.Equal(int64(4), rows)
+	suite.Require().NoError(versionErr(space.Compact(option.NewCompactOptions())))
+	rows, err = space.CountRows()
+	suite.Require().NoError(err)
+	suite.Equal(int64(3), rows)
+
+	rowFilter := func(identity auth.Identity) ([]filter.Filter, error) {
+		return []filter.Filter{filter.NewConstantFilter(filter.GreaterThan, "pk_field", int64(2))}, nil
+	}
+	filtered, err := storage.Open(uri, option.Options{Version: -1, RowFilter: rowFilter})
+	suite.Require().NoError(err)
+	rows, err = filtered.CountRows()
+	suite.Require().NoError(err)
+	suite.Equal(int64(2), rows)
+}
+
+func (suite *SpaceTestSuite) TestReadScalarAndVectorColumns() {
+	as := arrow.NewSchema([]arrow.Field{
+		{Name: "pk_field", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "vs_field", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "age", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "vec_field", Type: &arrow.FixedSizeBinaryType{ByteWidth: 2}},
+	}, nil)
+	sc := schema.NewSchema(as, &schema_option.SchemaOptions{
+		PrimaryColumn: "pk_field",
+		VersionColumn: "vs_field",
+		VectorColumn:  "vec_field",
+	})
+	suite.Require().NoError(sc.Validate())
+	space, err := storage.Open("file://"+suite.T().TempDir(), *option.NewOptions(sc, -1))
+	suite.Require().NoError(err)
+
+	for _, pks := range [][]int64{{1, 2, 3}, {4, 5}} {
+		b := array.NewRecordBuilder(memory.DefaultAllocator, as)
+		for _, pk := range pks {
+			b.Field(0).(*array.Int64Builder).Append(pk)
+			b.Field(1).(*array.Int64Builder).Append(1)
+			b.Field(2).(*array.Int64Builder).Append(pk * 10)
+			b.Field(3).(*array.FixedSizeBinaryBuilder).Append([]byte{byte(pk), 0})
+		}
+		reader, err := array.NewRecordReader(as, []arrow.Record{b.NewRecord()})
+		suite.Require().NoError(err)
+		b.Release()
+		suite.Require().NoError(commitErr(space.Write(reader, &option.WriteOptions{MaxRecordPerFile: 2})))
+	}
+
+	readOpt := option.NewReadOptions()
+	readOpt.AddColumn("pk_field")
+	readOpt.AddColumn("age")
+	readOpt.AddColumn("vec_field")
+	readOpt.AddFilter(filter.NewConstantFilter(filter.GreaterThan, "age", int64(15)))
+	reader, err := space.Read(readOpt)
+	suite.Require().NoError(err)
+	defer reader.Release()
+	var pks []int64
+	for reader.Next() {
+		rec := reader.Record()
+		column := func(name string) arrow.Array { return rec.Column(rec.Schema().FieldIndices(name)[0]) }
+		for i := 0; i < int(rec.NumRows()); i++ {
+			pk := column("pk_field").(*array.Int64).Value(i)
+			suite.Equal(pk*10, column("age").(*array.Int64).Value(i))
+			suite.Equal(byte(pk), column("vec_field").(*array.FixedSizeBinary).Value(i)[0])
+			pks = append(pks, pk)
+		}
+	}
+	suite.Require().NoError(reader.Err())
+	suite.Equal([]int64{2, 3, 4, 5}, pks)
+}
+
+func (suite *SpaceTestSuite) TestColumnGroupTogether() {
+	sc := createSchema()
+	sc.Options().ColumnGroupPolicy = schema_option.GroupTogether
+	suite.Require().NoError(sc.Validate())
+	uri := "file://" + suite.T().TempDir()
+	space, err := storage.Open(uri, *option.NewOptions(sc, -1))
+	suite.Require().NoError(err)
+	suite.Require().NoError(commitErr(space.Write(createRecordReader(sc, []int64{1, 2}), option.NewWriteOption())))
+
+	// the policy is recorded in the manifest
+	reopened, err := storage.Open(uri, *option.NewOptions(nil, -1))
+	suite.Require().NoError(err)
+	readOpt := option.NewReadOptions()
+	readOpt.AddColumn("pk_field")
+	readOpt.AddColumn("vec_field")
+	reader, err := reopened.Read(readOpt)
+	suite.Require().NoError(err)
+	defer reader.Release()
+	var pks []int64
+	for reader.Next() {
+		rec := reader.Record()
+		vecColumn := rec.Column(rec.Schema().FieldIndices("vec_field")[0]).(*array.FixedSizeBinary)
+		for i := 0; i < int(rec.NumRows()); i++ {
+			pk := rec.Column(rec.Schema().FieldIndices("pk_field")[0]).(*array.Int64).Value(i)
+			suite.Equal(byte(pk), vecColumn.Value(i)[0])
+			pks = append(pks, pk)
+		}
+	}
+	suite.Require().NoError(reader.Err())
+	suite.ElementsMatch([]int64{1, 2}, pks)
+}
+
+func (suite *SpaceTestSuite) TestReadSkipsOtherColumnGroup() {
+	sc := createSchema()
+	suite.Require().NoError(sc.Validate())
+	read := func(dir string, columns ...string) ([]int64, option.Progress, error) {
+		space, err := storage.Open("file://"+dir, *option.NewOptions(sc, -1))
+		suite.Require().NoError(err)
+		var progress option.Progress
+		readOpt := option.NewReadOptions()
+		readOpt.SetColumns(columns)
+		readOpt.Progress = func(p option.Progress) { progress = p }
+		reader, err := space.Read(readOpt)
+		suite.Require().NoError(err)
+		defer reader.Release()
+		var pks []int64
+		for reader.Next() {
+			rec := reader.Record()
+			pks = append(pks, rec.Column(rec.Schema().FieldIndices("pk_field")[0]).(*array.Int64).Int64Values()...)
+		}
+		return pks, progress, reader.Err()
+	}
+	write := func() string {
+		dir := suite.T().TempDir()
+		space, err := storage.Open("file://"+dir, *option.NewOptions(sc, -1))
+		suite.Require().NoError(err)
+		suite.Require().NoError(commitErr(space.Write(createRecordReader(sc, []int64{1, 2, 3}), &option.WriteOptions{MaxRecordPerFile: 2})))
+		return dir
+	}
+
+	// reads succeed without the files of the other group, so they are never opened
+	dir := write()
+	suite.Require().NoError(os.RemoveAll(filepath.Join(dir, constant.VectorDataDir)))
+	pks, progress, err := read(dir, "pk_field")
+	suite.Require().NoError(err)
+	suite.ElementsMatch([]int64{1, 2, 3}, pks)
+	suite.Equal(int64(1), progress.Files)
+	suite.Equal(int64(1), progress.FilesSkipped)
+
+	dir = write()
+	suite.Require().NoError(os.RemoveAll(filepath.Join(dir, constant.ScalarDataDir)))
+	pks, progress, err = read(dir, "pk_field", "vec_field")
+	suite.Require().NoError(err)
+	suite.ElementsMatch([]int64{1, 2, 3}, pks)
+	suite.Equal(int64(1), progress.Files)
+	suite.Equal(int64(1), progress.FilesSkipped)
+}
+
+func (suite *SpaceTestSuite) TestReadPrefetchedChunks() {
+	sc := createSchema()
+	suite.Require().NoError(sc.Validate())
+	dir := suite.T().TempDir()
+	space, err := storage.Open("file://"+dir, *option.NewOptions(sc, -1))
+	suite.Require().NoError(err)
+	suite.Require().NoError(commitErr(space.Write(createRecordReader(sc, []int64{1, 2, 3}), &option.DefaultWriteOptions)))
+
+	// column chunks are fetched up front, only the projected ones are read
+	var progress option.Progress
+	readOpt := option.NewReadOptions()
+	readOpt.SetColumns([]string{"pk_field"})
+	readOpt.Progress = func(p option.Progress) { progress = p }
+	reader, err := space.Read(readOpt)
+	suite.Require().NoError(err)
+	defer reader.Release()
+	var pks []int64
+	for reader.Next() {
+		rec := reader.Record()
+		pks = append(pks, rec.Column(rec.Schema().FieldIndices("pk_field")[0]).(*array.Int64).Int64Values()...)
+	}
+	suite.Require().NoError(reader.Err())
+	suite.ElementsMatch([]int64{1, 2, 3}, pks)
+
+	entries, err := os.ReadDir(filepath.Join(dir, constant.ScalarDataDir))
+	suite.Require().NoError(err)
+	suite.Require().Len(entries, 1)
+	info, err := entries[0].Info()
+	suite.Require().NoError(err)
+	size := info.Size()
+	suite.Greater(progress.Bytes, int64(0))
+	suite.Less(progress.Bytes, size)
+}
+
+func (suite *SpaceTestSuite) TestProgress() {
+	sc := createSchema()
+	suite.Require().NoError(sc.Validate())
+	space, err := storage.Open("file://"+suite.T().TempDir(), *option.NewOptions(sc, -1))
+	suite.Require().NoError(err)
+
+	var writeProgress []option.Progress
+	writeOpt := &option.WriteOptions{
+		MaxRecordPerFile: 2,
+		Progress:         func(p option.Progress) { writeProgress = append(writeProgress, p) },
+	}
+	suite.Require().NoError(commitErr(space.Write(createRecordReader(sc, []int64{1, 2, 3}), writeOpt)))
+	suite.Require().NotEmpty(writeProgress)
+	last := writeProgress[len(writeProgress)-1]
+	suite.Equal(int64(3), last.Rows)
+	// one scalar and one vector file per write since the record is written as a whole
+	suite.Equal(int64(2), last.Files)
+	suite.Greater(last.Bytes, int64(0))
+
+	var readProgress []option.Progress
+	readOpt := option.NewReadOptions()
+	readOpt.AddColumn("pk_field")
+	readOpt.Progress = func(p option.Progress) { readProgress = append(readProgress, p) }
+	reader, err := space.Read(readOpt)
+	suite.Require().NoError(err)
+	for reader.Next() {
+	}
+	suite.Require().NotEmpty(readProgress)
+	last = readProgress[len(readProgress)-1]
+	suite.Equal(int64(3), last.Rows)
+	suite.Equal(int64(1), last.Files)
+	suite.Greater(last.Bytes, int64(0))
+}
+
+func (suite *SpaceTestSuite) TestReadTypedFilters() {
+	as := arrow.NewSchema([]arrow.Field{
+		{Name: "pk_field", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "vs_field", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "score", Type: arrow.PrimitiveTypes.Float64},
+		{Name: "name", Type: arrow.BinaryTypes.String},
+		{Name: "flag", Type: arrow.FixedWidthTypes.Boolean},
+		{Name: "vec_field", Type: &arrow.FixedSizeBinaryType{ByteWidth: 2}},
+	}, nil)
+	sc := schema.NewSchema(as, &schema_option.SchemaOptions{
+		PrimaryColumn: "pk_field",
+		VersionColumn: "vs_field",
+		VectorColumn:  "vec_field",
+	})
+	suite.Require().NoError(sc.Validate())
+	space, err := storage.Open("file://"+suite.T().TempDir(), *option.NewOptions(sc, -1))
+	suite.Require().NoError(err)
+
+	for _, pks := range [][]int64{{1, 2, 3}, {4, 5}} {
+		b := array.NewRecordBuilder(memory.DefaultAllocator, as)
+		for _, pk := range pks {
+			b.Field(0).(*array.Int64Builder).Append(pk)
+			b.Field(1).(*array.Int64Builder).Append(1)
+			b.Field(2).(*array.Float64Builder).Append(float64(pk) / 2)
+			b.Field(3).(*array.StringBuilder).Append(string(rune('a' + pk - 1)))
+			b.Field(4).(*array.BooleanBuilder).Append(pk%2 == 0)
+			b.Field(5).(*array.FixedSizeBinaryBuilder).Append([]byte{byte(pk), 0})
+		}
+		reader, err := array.NewRecordReader(as, []arrow.Record{b.NewRecord()})
+		suite.Require().NoError(err)
+		b.Release()
+		suite.Require().NoError(commitErr(space.Write(reader, &option.WriteOptions{MaxRecordPerFile: 2})))
+	}
+
+	read := func(filters ...filter.Filter) []int64 {
+		readOpt := option.NewReadOptions()
+		readOpt.AddColumn("pk_field")
+		readOpt.AddColumn("vec_field")
+		for _, f := range filters {
+			readOpt.AddFilter(f)
+			readOpt.AddColumn(f.GetColumnName())
+		}
+		reader, err := space.Read(readOpt)
+		suite.Require().NoError(err)
+		defer reader.Release()
+		var pks []int64
+		for reader.Next() {
+			rec := reader.Record()
+			for i := 0; i < int(rec.NumRows()); i++ {
+				pks = append(pks, rec.Column(rec.Schema().FieldIndices("pk_field")[0]).(*array.Int64).Value(i))
+			}
+		}
+		suite.Require().NoError(reader.Err())
+		return pks
+	}
+	// integer values are converted to the type of the column
+	suite.Equal([]int64{3, 4, 5}, read(filter.NewConstantFilter(filter.GreaterThanOrEqual, "score", 1.5)))
+	suite.Equal([]int64{1, 2}, read(filter.NewConstantFilter(filter.LessThanOrEqual, "score", 1)))
+	suite.Equal([]int64{2}, read(filter.NewConstantFilter(filter.Equal, "name", "b")))
+	suite.Equal([]int64{4, 5}, read(filter.NewConstantFilter(filter.GreaterThan, "name", "c")))
+	suite.Equal([]int64{2, 4}, read(filter.NewConstantFilter(filter.Equal, "flag", true)))
+	suite.Equal([]int64{4}, read(
+		filter.NewConstantFilter(filter.Equal, "flag", true),
+		filter.NewConstantFilter(filter.NotEqual, "name", "b"),
+		filter.NewConstantFilter(filter.LessThan, "score", float32(2.5)),
+	))
+
+	readOpt := option.NewReadOptions()
+	readOpt.AddColumn("pk_field")
+	readOpt.AddFilter(filter.NewConstantFilter(filter.Equal, "name", int64(1)))
+	_, err = space.Read(readOpt)
+	suite.ErrorIs(err, filter.ErrIncomparableValue)
+}
+
+func (suite *SpaceTestSuite) TestReadExpressionFilters() {
+	as := arrow.NewSchema([]arrow.Field{
+		{Name: "pk_field", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "vs_field", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "a", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "city", Type: arrow.BinaryTypes.String},
+		{Name: "vec_field", Type: &arrow.FixedSizeBinaryType{ByteWidth: 2}},
+	}, nil)
+	sc := schema.NewSchema(as, &schema_option.SchemaOptions{
+		PrimaryColumn: "pk_field",
+		VersionColumn: "vs_field",
+		VectorColumn:  "vec_field",
+	})
+	suite.Require().NoError(sc.Validate())
+	space, err := storage.Open("file://"+suite.T().TempDir(), *option.NewOptions(sc, -1))
+	suite.Require().NoError(err)
+
+	cities := []string{"NYC", "sf", "nyc", "Nyc", "la"}
+	b := array.NewRecordBuilder(memory.DefaultAllocator, as)
+	for pk := int64(1); pk <= 5; pk++ {
+		b.Field(0).(*array.Int64Builder).Append(pk)
+		b.Field(1).(*array.Int64Builder).Append(1)
+		b.Field(2).(*array.Int64Builder).Append(pk * 3)
+		b.Field(3).(*array.StringBuilder).Append(cities[pk-1])
+		b.Field(4).(*array.FixedSizeBinaryBuilder).Append([]byte{byte(pk), 0})
+	}
+	reader, err := array.NewRecordReader(as, []arrow.Record{b.NewRecord()})
+	suite.Require().NoError(err)
+	b.Release()
+	suite.Require().NoError(commitErr(space.Write(reader, &option.WriteOptions{MaxRecordPerFile: 2})))
+
+	read := func(readOpt *option.ReadOptions) []int64 {
+		reader, err := space.Read(readOpt)
+		suite.Require().NoError(err)
+		defer reader.Release()
+		suite.False(reader.Schema().HasField("a"))
+		suite.False(reader.Schema().HasField("city"))
+		var pks []int64
+		for reader.Next() {
+			rec := reader.Record()
+			suite.Equal(reader.Schema(), rec.Schema())
+			vec := rec.Column(rec.Schema().FieldIndices("vec_field")[0]).(*array.FixedSizeBinary)
+			for i := 0; i < int(rec.NumRows()); i++ {
+				pks = append(pks, int64(vec.Value(i)[0]))
+			}
+		}
+		suite.Require().NoError(reader.Err())
+		return pks
+	}
+
+	// the columns of the filters are read but not returned
+	readOpt := option.NewReadOptions()
+	readOpt.AddColumn("vec_field")
+	readOpt.AddExpressionFilter(filter.NewExpressionFilter(filter.GreaterThan,
+		filter.Add(filter.Column("a"), filter.Column("pk_field")), filter.Literal(int64(10))))
+	readOpt.AddExpressionFilter(filter.NewExpressionFilter(filter.Equal, filter.Lower(filter.Column("city")), filter.Literal("nyc")))
+	suite.Equal([]int64{3, 4}, read(readOpt))
+	suite.Empty(readOpt.FiltersV2)
+
+	// comparisons of a column with a literal are pushed down
+	readOpt = option.NewReadOptions()
+	readOpt.AddColumn("vec_field")
+	readOpt.AddExpressionFilter(filter.NewExpressionFilter(filter.LessThanOrEqual, filter.Literal(int64(9)), filter.Column("a")))
+	suite.Len(readOpt.FiltersV2, 1)
+	suite.Empty(readOpt.ExpressionFilters)
+	suite.Equal([]int64{3, 4, 5}, read(readOpt))
+
+	readOpt = option.NewReadOptions()
+	readOpt.AddColumn("vec_field")
+	readOpt.AddExpressionFilter(filter.NewExpressionFilter(filter.Equal, filter.Lower(filter.Column("a")), filter.Literal("x")))
+	_, err = space.Read(readOpt)
+	suite.ErrorIs(err, filter.ErrInvalidExpression)
+	readOpt = option.NewReadOptions()
+	readOpt.AddExpressionFilter(filter.NewExpressionFilter(filter.Equal, filter.Lower(filter.Column("town")), filter.Literal("x")))
+	_, err = space.Read(readOpt)
+	suite.ErrorIs(err, storage.ErrColumnNotExist)
+}
+
+func (suite *SpaceTestSuite) TestReadArrowInterfaces() {
+	sc := createSchema()
+	suite.Require().NoError(sc.Validate())
+	space, err := storage.Open("file://"+suite.T().TempDir(), *option.NewOptions(sc, -1))
+	suite.Require().NoError(err)
+	suite.Require().NoError(commitErr(space.Write(createRecordReader(sc, []int64{1, 2, 3}), &option.WriteOptions{MaxRecordPerFile: 2})))
+	suite.Require().NoError(commitErr(space.Write(createRecordReader(sc, []int64{4, 5}), &option.WriteOptions{MaxRecordPerFile: 2})))
+
+	readOpt := option.NewReadOptions()
+	readOpt.AddColumn("pk_field")
+	readOpt.AddColumn("vec_field")
+	reader, err := space.Read(readOpt)
+	suite.Require().NoError(err)
+	var buf bytes.Buffer
+	writer := ipc.NewWriter(&buf, ipc.WithSchema(reader.Schema()))
+	n, err := arrio.Copy(writer, reader)
+	suite.Require().NoError(err)
+	suite.Greater(n, int64(0))
+	suite.Require().NoError(writer.Close())
+	rec, err := reader.Read()
+	suite.Nil(rec)
+	suite.ErrorIs(err, io.EOF)
+	reader.Release()
+
+	ipcReader, err := ipc.NewReader(&buf)
+	suite.Require().NoError(err)
+	defer ipcReader.Release()
+	table, err := storage.RecordsToTable(ipcReader)
+	suite.Require().NoError(err)
+	defer table.Release()
+	suite.Equal(int64(5), table.NumRows())
+	suite.Equal(int64(len(ipcReader.Schema().Fields())), table.NumCols())
+
+	reader, err = space.Read(readOpt)
+	suite.Require().NoError(err)
+	defer reader.Release()
+	table, err = storage.RecordsToTable(reader)
+	suite.Require().NoError(err)
+	defer table.Release()
+	suite.Equal(int64(5), table.NumRows())
+	suite.True(table.Schema().Equal(reader.Schema()))
+}
+
+func (suite *SpaceTestSuite) TestFragmentReader() {
+	sc := createSchema()
+	suite.Require().NoError(sc.Validate())
+	dir := suite.T().TempDir()
+	space, err := storage.Open("file://"+dir, *option.NewOptions(sc, -1))
+	suite.Require().NoError(err)
+	suite.Require().NoError(commitErr(space.Write(createRecordReader(sc, []int64{1, 2, 3}), &option.WriteOptions{MaxRecordPerFile: 2})))
+	suite.Require().NoError(commitErr(space.Write(createRecordReader(sc, []int64{4, 5}), &option.WriteOptions{MaxRecordPerFile: 2})))
+
+	f, err := fs.BuildFileSystem("file://" + dir)
+	suite.Require().NoError(err)
+	m, err := manifest.ParseFromFile(f, utils.GetManifestFilePath(dir, space.GetCurrentVersion()))
+	suite.Require().NoError(err)
+	fragments, err := m.GetDataFragments()
+	suite.Require().NoError(err)
+	suite.Require().Len(fragments, 2)
+
+	readPks := func(frag fragment.Fragment, readOpt *option.ReadOptions) []int64 {
+		reader, err := fragment.NewReader(f, frag, sc, readOpt)
+		suite.Require().NoError(err)
+		defer reader.Release()
+		var pks []int64
+		for reader.Next() {
+			rec := reader.Record()
+			suite.True(rec.Schema().Equal(reader.Schema()))
+			pks = append(pks, rec.Column(0).(*array.Int64).Int64Values()...)
+		}
+		suite.Require().NoError(reader.Err())
+		return pks
+	}
+	readOpt := option.NewReadOptions()
+	readOpt.AddColumn("pk_field")
+	suite.ElementsMatch([]int64{1, 2, 3}, readPks(fragments[0].Scalar, readOpt))
+	suite.ElementsMatch([]int64{4, 5}, readPks(fragments[1].Scalar, readOpt))
+
+	readOpt = option.NewReadOptions()
+	readOpt.AddColumn("pk_field")
+	readOpt.AddColumn("vec_field")
+	readOpt.AddFilter(filter.NewConstantFilter(filter.GreaterThan, "pk_field", int64(1)))
+	suite.ElementsMatch([]int64{2, 3}, readPks(fragments[0].Vector, readOpt))
+
+	// vector columns are not in scalar files
+	reader, err := fragment.NewReader(f, fragments[0].Scalar, sc, readOpt)
+	suite.Require().NoError(err)
+	suite.False(reader.Next())
+	suite.ErrorIs(reader.Err(), parquet.ErrColumnNotFound)
+	reader.Release()
+	readOpt = option.NewReadOptions()
+	readOpt.AddColumn("vec_field")
+	readOpt.AddFilter(filter.NewConstantFilter(filter.Equal, "pk_field", int64(1)))
+	readOpt.AddFilter(filter.NewConstantFilter(filter.Equal, constant.OffsetFieldName, int64(1)))
+	_, err = fragment.NewReader(f, fragments[0].Vector, sc, readOpt)
+	suite.ErrorIs(err, fragment.ErrColumnNotInFragment)
+}
+
+func (suite *SpaceTestSuite) TestPlanScan() {
+	sc := createSchema()
+	suite.Require().NoError(sc.Validate())
+	dir := suite.T().TempDir()
+	space, err := storage.Open("file://"+dir, *option.NewOptions(sc, -1))
+	suite.Require().NoError(err)
+	for _, pks := range [][]int64{{1, 2}, {3}, {4, 5}, {6}} {
+		suite.Require().NoError(commitErr(space.Write(createRecordReader(sc, pks), &option.WriteOptions{MaxRecordPerFile: 10})))
+	}
+	suite.Require().NoError(commitErr(space.Delete(createDeleteReader(sc, []int64{5}, []int64{5}))))
+
+	readOpt := option.NewReadOptions()
+	readOpt.AddColumn("pk_field")
+	readOpt.AddColumn("vec_field")
+	_, err = space.PlanScan(readOpt, 0)
+	suite.ErrorIs(err, storage.ErrInvalidNumSplits)
+	badOpt := option.NewReadOptions()
+	badOpt.AddColumn("pk_field")
+	badOpt.AddFilter(filter.NewConstantFilter(filter.Equal, "missing", int64(1)))
+	_, err = space.PlanScan(badOpt, 2)
+	suite.ErrorIs(err, storage.ErrColumnNotExist)
+
+	tasks, err := space.PlanScan(readOpt, 3)
+	suite.Require().NoError(err)
+	suite.Len(tasks, 3)
+	f, err := fs.BuildFileSystem("file://" + dir)
+	suite.Require().NoError(err)
+	var pks []int64
+	for _, task := range tasks {
+		data, err := json.Marshal(task)
+		suite.Require().NoError(err)
+		var decoded storage.ScanTask
+		suite.Require().NoError(json.Unmarshal(data, &decoded))
+		suite.Equal(task, decoded)
+
+		reader, err := decoded.NewReader(f, sc, readOpt)
+		suite.Require().NoError(err)
+		table, err := storage.RecordsToTable(reader)
+		suite.Require().NoError(err)
+		suite.Greater(table.NumRows(), int64(0))
+		for _, chunk := range table.Column(0).Data().Chunks() {
+			pks = append(pks, chunk.(*array.Int64).Int64Values()...)
+		}
+		table.Release()
+		reader.Release()
+	}
+	suite.ElementsMatch([]int64{1, 2, 3, 4, 6}, pks)
+
+	// the fragments of a task can be read on their own, without applying deletes
+	tasks, err = space.PlanScan(readOpt, 1)
+	suite.Require().NoError(err)
+	suite.Require().Len(tasks, 1)
+	suite.Require().Len(tasks[0].Fragments, 4)
+	scalarOpt := option.NewReadOptions()
+	scalarOpt.AddColumn("pk_field")
+	reader, err := fragment.NewReader(f, tasks[0].Fragments[2].Scalar.Fragment(), sc, scalarOpt)
+	suite.Require().NoError(err)
+	defer reader.Release()
+	table, err := storage.RecordsToTable(reader)
+	suite.Require().NoError(err)
+	defer table.Release()
+	suite.Equal(int64(2), table.NumRows())
+}
+
+func (suite *SpaceTestSuite) TestReadBatchSize() {
+	sc := createSchema()
+	suite.Require().NoError(sc.Validate())
+	space, err := storage.Open("file://"+suite.T().TempDir(), *option.NewOptions(sc, -1))
+	suite.Require().NoError(err)
+	for _, pks := range [][]int64{{1, 2, 3}, {4, 5, 6, 7}, {8, 9}} {
+		suite.Require().NoError(commitErr(space.Write(createRecordReader(sc, pks), &option.WriteOptions{MaxRecordPerFile: 10})))
+	}
+	suite.Require().NoError(commitErr(space.Delete(createDeleteReader(sc, []int64{5}, []int64{5}))))
+
+	readBatches := func(readOpt *option.ReadOptions) ([]int64, []int64) {
+		reader, err := space.Read(readOpt)
+		suite.Require().NoError(err)
+		defer reader.Release()
+		var sizes, pks []int64
+		for reader.Next() {
+			rec := reader.Record()
+			sizes = append(sizes, rec.NumRows())
+			pkIndex := rec.Schema().FieldIndices("pk_field")[0]
+			pks = append(pks, rec.Column(pkIndex).(*array.Int64).Int64Values()...)
+		}
+		suite.Require().NoError(reader.Err())
+		return sizes, pks
+	}
+
+	readOpt := option.NewReadOptions()
+	readOpt.AddColumn("pk_field")
+	readOpt.AddColumn("vec_field")
+	readOpt.BatchSize = 3
+	sizes, pks := readBatches(readOpt)
+	suite.Equal([]int64{3, 3, 2}, sizes)
+	suite.ElementsMatch([]int64{1, 2, 3, 4, 6, 7, 8, 9}, pks)
+
+	readOpt = option.NewReadOptions()
+	readOpt.AddColumn("pk_field")
+	readOpt.AddFilter(filter.NewConstantFilter(filter.GreaterThan, "pk_field", int64(1)))
+	readOpt.BatchSize = 5
+	sizes, pks = readBatches(readOpt)
+	suite.Equal([]int64{5, 2}, sizes)
+	suite.ElementsMatch([]int64{2, 3, 4, 6, 7, 8, 9}, pks)
+}
+
+func (suite *SpaceTestSuite) TestWriteFileOffsets() {
+	sc := createSchema()
+	suite.Require().NoError(sc.Validate())
+	dir := suite.T().TempDir()
+	space, err := storage.Open("file://"+dir, *option.NewOptions(sc, -1))
+	suite.Require().NoError(err)
+	var records []arrow.Record
+	for _, pks := range [][]int64{{1, 2}, {3, 4, 5}} {
+		reader := createRecordReader(sc, pks)
+		suite.Require().True(reader.Next())
+		rec := reader.Record()
+		rec.Retain()
+		records = append(records, rec)
+		reader.Release()
+	}
+	reader, err := array.NewRecordReader(sc.Schema(), records)
+	suite.Require().NoError(err)
+	suite.Require().NoError(commitErr(space.Write(reader, &option.WriteOptions{MaxRecordPerFile: 10})))
+
+	f, err := fs.BuildFileSystem("file://" + dir)
+	suite.Require().NoError(err)
+	m, err := manifest.ParseFromFile(f, utils.GetManifestFilePath(dir, space.GetCurrentVersion()))
+	suite.Require().NoError(err)
+	files := m.GetScalarFragments()[0].Files()
+	suite.Require().Len(files, 1)
+	readOpt := option.NewReadOptions()
+	readOpt.AddColumn(constant.OffsetFieldName)
+	fileReader, err := parquet.NewFileReader(f, files[0], readOpt)
+	suite.Require().NoError(err)
+	defer fileReader.Close()
+	var offsets []int64
+	for {
+		rec, err := fileReader.Read()
+		if err == io.EOF {
+			break
+		}
+		suite.Require().NoError(err)
+		offsets = append(offsets, rec.Column(0).(*array.Int64).Int64Values()...)
+	}
+	// offsets continue across the records written to a file
+	suite.Equal([]int64{0, 1, 2, 3, 4}, offsets)
+}
+
+func (suite *SpaceTestSuite) TestAutoID() {
+	sc := createSchema()
+	sc.Options().AutoID = true
+	suite.Require().NoError(sc.Validate())
+	uri := "file://" + suite.T().TempDir()
+	space, err := storage.Open(uri, *option.NewOptions(sc, -1))
+	suite.Require().NoError(err)
+
+	// records without the primary column get generated keys
+	input := arrow.NewSchema([]arrow.Field{sc.Schema().Field(1), sc.Schema().Field(2)}, nil)
+	writeWithoutPks := func(space *storage.Space, versions []int64) {
+		b := array.NewRecordBuilder(memory.DefaultAllocator, input)
+		defer b.Release()
+		for _, version := range versions {
+			b.Field(0).(*array.Int64Builder).Append(version)
+			b.Field(1).(*array.FixedSizeBinaryBuilder).Append([]byte{byte(version), 2, 3, 4, 5, 6, 7, 8, 9, 10})
+		}
+		rec := b.NewRecord()
+		defer rec.Release()
+		reader, err := array.NewRecordReader(input, []arrow.Record{rec})
+		suite.Require().NoError(err)
+		suite.Require().NoError(commitErr(space.Write(reader, &option.WriteOptions{MaxRecordPerFile: 10})))
+	}
+	writeWithoutPks(space, []int64{1, 2, 3})
+	writeWithoutPks(space, []int64{4, 5})
+	pks, err := readPks(space)
+	suite.Require().NoError(err)
+	suite.ElementsMatch([]int64{0, 1, 2, 3, 4}, pks)
+
+	// the next keys are kept in the manifest
+	reopened, err := storage.Open(uri, *option.NewOptions(nil, -1))
+	suite.Require().NoError(err)
+	writeWithoutPks(reopened, []int64{6})
+	pks, err = readPks(reopened)
+	suite.Require().NoError(err)
+	suite.ElementsMatch([]int64{0, 1, 2, 3, 4, 5}, pks)
+
+	// records with the primary column keep their keys
+	suite.Require().NoError(commitErr(reopened.Write(createRecordReader(sc, []int64{100}), &option.WriteOptions{MaxRecordPerFile: 10})))
+	pks, err = readPks(reopened)
+	suite.Require().NoError(err)
+	suite.ElementsMatch([]int64{0, 1, 2, 3, 4, 5, 100}, pks)
+
+	suite.ErrorIs(commitErr(space.Write(createRecordReader(createSchema(), []int64{1}), &option.WriteOptions{MaxRecordPerFile: 10})), storage.ErrManifestConflict)
+}
+
+func (suite *SpaceTestSuite) TestFieldMetadata() {
+	vecMetadata := arrow.NewMetadata([]string{"model", "dim"}, []string{"text-embedding", "10"})
+	as := arrow.NewSchema([]arrow.Field{
+		{Name: "pk_field", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "vs_field", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "vec_field", Type: &arrow.FixedSizeBinaryType{ByteWidth: 10}, Metadata: vecMetadata},
+	}, nil)
+	sc := schema.NewSchema(as, &schema_option.SchemaOptions{
+		PrimaryColumn: "pk_field",
+		VersionColumn: "vs_field",
+		VectorColumn:  "vec_field",
+	})
+	suite.Require().NoError(sc.Validate())
+	dir := suite.T().TempDir()
+	space, err := storage.Open("file://"+dir, *option.NewOptions(sc, -1))
+	suite.Require().NoError(err)
+	suite.Require().NoError(commitErr(space.Write(createRecordReader(sc, []int64{1, 2}), &option.WriteOptions{MaxRecordPerFile: 10})))
+
+	checkMetadata := func(sc *arrow.Schema) {
+		fields, ok := sc.FieldsByName("vec_field")
+		suite.Require().True(ok)
+		suite.True(fields[0].Metadata.Equal(vecMetadata), "metadata %v", fields[0].Metadata)
+	}
+	reopened, err := storage.Open("file://"+dir, *option.NewOptions(nil, -1))
+	suite.Require().NoError(err)
+	readOpt := option.NewReadOptions()
+	readOpt.AddColumn("pk_field")
+	readOpt.AddColumn("vec_field")
+	reader, err := reopened.Read(readOpt)
+	suite.Require().NoError(err)
+	defer reader.Release()
+	checkMetadata(reader.Schema())
+	suite.Require().True(reader.Next())
+	checkMetadata(reader.Record().Schema())
+
+	// the parquet files carry the metadata too
+	f, err := fs.BuildFileSystem("file://" + dir)
+	suite.Require().NoError(err)
+	m, err := manifest.ParseFromFile(f, utils.GetManifestFilePath(dir, reopened.GetCurrentVersion()))
+	suite.Require().NoError(err)
+	suite.True(m.GetSchema().Schema().Equal(as))
+	vecOpt := option.NewReadOptions()
+	vecOpt.AddColumn("vec_field")
+	fileReader, err := parquet.NewFileReader(f, m.GetVectorFragments()[0].Files()[0], vecOpt)
+	suite.Require().NoError(err)
+	defer fileReader.Close()
+	rec, err := fileReader.Read()
+	suite.Require().NoError(err)
+	// the parquet reader adds the field ids
+	fileMetadata := rec.Schema().Field(0).Metadata
+	for i, key := range vecMetadata.Keys() {
+		idx := fileMetadata.FindKey(key)
+		suite.Require().GreaterOrEqual(idx, 0)
+		suite.Equal(vecMetadata.Values()[i], fileMetadata.Values()[idx])
+	}
+}
+
 func TestSpaceTestSuite(t *testing.T) {
 	suite.Run(t, new(SpaceTestSuite))
 }
+
+func (suite *SpaceTestSuite) TestLiveBitmaps() {
+	sc := createSchema()
+	suite.Require().NoError(sc.Validate())
+	dir := suite.T().TempDir()
+	opts := option.NewOptions(sc, -1)
+	opts.LiveBitmaps = true
+	space, err := storage.Open("file://"+dir, *opts)
+	suite.Require().NoError(err)
+	for _, pks := range [][]int64{{1, 2, 3}, {4, 5, 6}} {
+		suite.Require().NoError(commitErr(space.Write(createRecordReader(sc, pks), &option.WriteOptions{MaxRecordPerFile: 10})))
+	}
+	suite.Require().NoError(commitErr(space.Delete(createDeleteReader(sc, []int64{2, 5}, []int64{2, 5}))))
+
+	entries, err := os.ReadDir(filepath.Join(dir, "bitmap"))
+	suite.Require().NoError(err)
+	suite.Len(entries, 2)
+
+	readPks := func(columns ...string) []int64 {
+		readOpt := option.NewReadOptions()
+		for _, column := range columns {
+			readOpt.AddColumn(column)
+		}
+		reader, err := space.Read(readOpt)
+		suite.Require().NoError(err)
+		defer reader.Release()
+		var pks []int64
+		for reader.Next() {
+			rec := reader.Record()
+			pkIndex := rec.Schema().FieldIndices("pk_field")[0]
+			pks = append(pks, rec.Column(pkIndex).(*array.Int64).Int64Values()...)
+		}
+		suite.Require().NoError(reader.Err())
+		return pks
+	}
+	suite.ElementsMatch([]int64{1, 3, 4, 6}, readPks("pk_field"))
+	suite.ElementsMatch([]int64{1, 3, 4, 6}, readPks("pk_field", "vec_field"))
+
+	// a new space instance loads the sidecars written by the first one
+	reopened, err := storage.Open("file://"+dir, *opts)
+	suite.Require().NoError(err)
+	suite.Require().NoError(commitErr(reopened.Delete(createDeleteReader(sc, []int64{1}, []int64{1}))))
+	space = reopened
+	suite.ElementsMatch([]int64{3, 4, 6}, readPks("pk_field"))
+}
+
+func (suite *SpaceTestSuite) TestDurabilitySync() {
+	sc := createSchema()
+	suite.Require().NoError(sc.Validate())
+	dir := suite.T().TempDir()
+	opts := option.NewOptions(sc, -1)
+	opts.Durability = option.DurabilitySync
+	space, err := storage.Open("file://"+dir, *opts)
+	suite.Require().NoError(err)
+	suite.Require().NoError(commitErr(space.Write(createRecordReader(sc, []int64{1, 2, 3}), &option.WriteOptions{MaxRecordPerFile: 10})))
+	suite.Require().NoError(commitErr(space.Delete(createDeleteReader(sc, []int64{2}, []int64{2}))))
+
+	reopened, err := storage.Open("file://"+dir, *opts)
+	suite.Require().NoError(err)
+	suite.Equal(space.GetCurrentVersion(), reopened.GetCurrentVersion())
+	readOpt := option.NewReadOptions()
+	readOpt.AddColumn("pk_field")
+	reader, err := reopened.Read(readOpt)
+	suite.Require().NoError(err)
+	defer reader.Release()
+	var pks []int64
+	for reader.Next() {
+		rec := reader.Record()
+		pks = append(pks, rec.Column(rec.Schema().FieldIndices("pk_field")[0]).(*array.Int64).Int64Values()...)
+	}
+	suite.Require().NoError(reader.Err())
+	suite.ElementsMatch([]int64{1, 3}, pks)
+}
+
+func (suite *SpaceTestSuite) TestFlush() {
+	sc := createSchema()
+	suite.Require().NoError(sc.Validate())
+	space, err := storage.Open("file://"+suite.T().TempDir(), *option.NewOptions(sc, -1))
+	suite.Require().NoError(err)
+	version, err := space.Flush()
+	suite.Require().NoError(err)
+	suite.Equal(int64(0), version)
+
+	var wg sync.WaitGroup
+	for i := int64(0); i < 4; i++ {
+		wg.Add(1)
+		go func(pk int64) {
+			defer wg.Done()
+			suite.NoError(commitErr(space.Write(createRecordReader(sc, []int64{pk}), option.NewWriteOption())))
+		}(i)
+	}
+	wg.Wait()
+	version, err = space.Flush()
+	suite.Require().NoError(err)
+	suite.Equal(int64(4), version)
+	suite.Equal(space.GetCurrentVersion(), version)
+}
+
+func (suite *SpaceTestSuite) TestSlowLog() {
+	var out bytes.Buffer
+	defaultLogger := log.Default()
+	log.ReplaceDefault(log.New(&out, log.WarnLevel))
+	defer log.ReplaceDefault(defaultLogger)
+
+	sc := createSchema()
+	suite.Require().NoError(sc.Validate())
+	opts := option.NewOptions(sc, -1)
+	opts.SlowLog = &option.SlowLogOptions{Read: time.Nanosecond, Write: time.Nanosecond, Commit: time.Nanosecond, Fs: time.Nanosecond}
+	space, err := storage.Open("file://"+suite.T().TempDir(), *opts)
+	suite.Require().NoError(err)
+	suite.Require().NoError(commitErr(space.Write(createRecordReader(sc, []int64{1, 2, 3}), option.NewWriteOption())))
+	readOpt := option.NewReadOptions()
+	readOpt.AddColumn("pk_field")
+	reader, err := space.Read(readOpt)
+	suite.Require().NoError(err)
+	for reader.Next() {
+	}
+	suite.Require().NoError(reader.Err())
+	// the operations of the space are logged apart from those of the file system
+	slowOps := func() string {
+		var ops []string
+		for _, line := range strings.Split(out.String(), "\n") {
+			if strings.Contains(line, "slow operation") {
+				ops = append(ops, line)
+			}
+		}
+		return strings.Join(ops, "\n")
+	}
+	suite.NotContains(slowOps(), `"op": "read"`)
+	reader.Release()
+
+	suite.Contains(out.String(), "slow fs operation")
+	for _, op := range []string{"write", "commit", "read"} {
+		suite.Contains(slowOps(), fmt.Sprintf(`"op": %q`, op))
+	}
+	suite.Contains(slowOps(), `"rows": 3`)
+}
+
+func (suite *SpaceTestSuite) TestDeleteKeys() {
+	sc := createSchema()
+	suite.Require().NoError(sc.Validate())
+	space, err := storage.Open("file://"+suite.T().TempDir(), *option.NewOptions(sc, -1))
+	suite.Require().NoError(err)
+	suite.Require().NoError(commitErr(space.Write(createRecordReader(sc, []int64{1, 2, 3, 4, 5}), option.NewWriteOption())))
+	result, err := space.DeleteKeys([]int64{2, 4}, 4)
+	suite.Require().NoError(err)
+	suite.Equal(int64(2), result.Version)
+	suite.Equal(int64(2), space.GetCurrentVersion())
+
+	// an empty delete commits nothing
+	suite.Require().NoError(commitErr(space.DeleteKeys(nil, 4)))
+	suite.Equal(int64(2), space.GetCurrentVersion())
+	suite.ErrorIs(commitErr(space.DeleteKeysString([]string{"1"}, 4)), storage.ErrPrimaryKeyType)
+	// a key written again with a later version stays visible
+	suite.Require().NoError(commitErr(space.Write(createVersionedRecordReader(sc, []int64{4}, []int64{5}), option.NewWriteOption())))
+
+	readOpt := option.NewReadOptions()
+	readOpt.AddColumn("pk_field")
+	reader, err := space.Read(readOpt)
+	suite.Require().NoError(err)
+	defer reader.Release()
+	var pks []int64
+	for reader.Next() {
+		rec := reader.Record()
+		pks = append(pks, rec.Column(rec.Schema().FieldIndices("pk_field")[0]).(*array.Int64).Int64Values()...)
+	}
+	suite.Require().NoError(reader.Err())
+	suite.ElementsMatch([]int64{1, 3, 4, 5}, pks)
+}
+
+func (suite *SpaceTestSuite) TestCommitEmpty() {
+	sc := createSchema()
+	suite.Require().NoError(sc.Validate())
+	space, err := storage.Open("file://"+suite.T().TempDir(), *option.NewOptions(sc, -1))
+	suite.Require().NoError(err)
+	suite.Require().NoError(commitErr(space.Write(createRecordReader(sc, []int64{1, 2}), option.NewWriteOption())))
+	usage := space.Usage()
+
+	version, err := space.CommitEmpty(map[string]string{"checkpoint": "42"})
+	suite.Require().NoError(err)
+	suite.Equal(int64(2), version)
+	suite.Equal(version, space.GetCurrentVersion())
+	suite.Equal(usage, space.Usage())
+	properties, err := space.VersionProperties(version)
+	suite.Require().NoError(err)
+	suite.Equal(map[string]string{"checkpoint": "42"}, properties)
+
+	// properties belong to the version they were committed with
+	suite.Require().NoError(commitErr(space.Write(createRecordReader(sc, []int64{3}), option.NewWriteOption())))
+	properties, err = space.VersionProperties(space.GetCurrentVersion())
+	suite.Require().NoError(err)
+	suite.Nil(properties)
+	properties, err = space.VersionProperties(version)
+	suite.Require().NoError(err)
+	suite.Equal("42", properties["checkpoint"])
+
+	count, err := space.CountRows()
+	suite.Require().NoError(err)
+	suite.Equal(int64(3), count)
+}
+
+func (suite *SpaceTestSuite) TestTransaction() {
+	sc := createSchema()
+	suite.Require().NoError(sc.Validate())
+	markerDir := suite.T().TempDir()
+	shards := make([]*storage.Space, 2)
+	uris := make([]string, 2)
+	for i := range shards {
+		uris[i] = "file://" + suite.T().TempDir()
+		ops := option.NewOptions(sc, -1)
+		ops.TxnMarkerDir = markerDir
+		space, err := storage.Open(uris[i], *ops)
+		suite.Require().NoError(err)
+		shards[i] = space
+	}
+	txnURI := "file://" + markerDir
+
+	txn, err := storage.NewTransaction(txnURI)
+	suite.Require().NoError(err)
+	suite.Require().NoError(txn.Write(shards[0], createRecordReader(sc, []int64{1, 2}), option.NewWriteOption()))
+	suite.Require().NoError(txn.Write(shards[1], createRecordReader(sc, []int64{3}), option.NewWriteOption()))
+	// only the name of the marker is stored in the manifests
+	m, err := manifest.ParseFromFile(fs.NewLocalFs(), utils.GetManifestFilePath(strings.TrimPrefix(uris[0], "file://"), shards[0].GetCurrentVersion()))
+	suite.Require().NoError(err)
+	suite.Equal(txn.ID(), m.GetScalarFragments()[0].Txn())
+
+	// spaces without the marker directory can neither write nor read the transaction
+	unset, err := storage.Open(uris[0], *option.NewOptions(nil, -1))
+	suite.Require().NoError(err)
+	_, err = readPks(unset)
+	suite.ErrorIs(err, storage.ErrTxnMarkerDir)
+	suite.ErrorIs(txn.Write(unset, createRecordReader(sc, []int64{4}), option.NewWriteOption()), storage.ErrTxnMarkerDir)
+
+	// nothing is visible before the commit, including to other instances
+	for i, space := range shards {
+		ops := option.NewOptions(nil, -1)
+		ops.TxnMarkerDir = markerDir
+		reopened, err := storage.Open(uris[i], *ops)
+		suite.Require().NoError(err)
+		for _, s := range []*storage.Space{space, reopened} {
+			pks, err := readPks(s)
+			suite.Require().NoError(err)
+			suite.Empty(pks)
+			count, err := s.CountRows()
+			suite.Require().NoError(err)
+			suite.Equal(int64(0), count)
+		}
+	}
+	suite.ErrorIs(versionErr(shards[0].Compact(option.NewCompactOptions())), errors.ErrConflict)
+
+	suite.Require().NoError(txn.Commit())
+	suite.ErrorIs(txn.Commit(), storage.ErrTransactionDone)
+	suite.ErrorIs(txn.Write(shards[0], createRecordReader(sc, []int64{4}), option.NewWriteOption()), storage.ErrTransactionDone)
+	for i, want := range [][]int64{{1, 2}, {3}} {
+		pks, err := readPks(shards[i])
+		suite.Require().NoError(err)
+		suite.ElementsMatch(want, pks)
+	}
+
+	aborted, err := storage.NewTransaction(txnURI)
+	suite.Require().NoError(err)
+	suite.Require().NoError(aborted.Write(shards[0], createRecordReader(sc, []int64{5}), option.NewWriteOption()))
+	suite.Require().NoError(aborted.Write(shards[1], createRecordReader(sc, []int64{6}), option.NewWriteOption()))
+	suite.Require().NoError(aborted.Abort())
+	suite.ErrorIs(aborted.Commit(), storage.ErrTransactionDone)
+	for i, want := range [][]int64{{1, 2}, {3}} {
+		pks, err := readPks(shards[i])
+		suite.Require().NoError(err)
+		suite.ElementsMatch(want, pks)
+		// the fragments of the aborted transaction are gone, compaction is not blocked
+		suite.Require().NoError(versionErr(shards[i].Compact(option.NewCompactOptions())))
+	}
+}
+
+func (suite *SpaceTestSuite) TestStorageProfiles() {
+	sc := createSchema()
+	sc.Options().StorageProfiles = map[string]schema_option.StorageProfile{
+		"vs_field":  {Codec: schema_option.CodecZstd, Level: 19, Encoding: schema_option.EncodingDeltaBinaryPacked},
+		"vec_field": {Codec: schema_option.CodecSnappy, Encoding: schema_option.EncodingPlain},
+	}
+	suite.Require().NoError(sc.Validate())
+	dir := suite.T().TempDir()
+	space, err := storage.Open("file://"+dir, *option.NewOptions(sc, -1))
+	suite.Require().NoError(err)
+	suite.Require().NoError(commitErr(space.Write(createRecordReader(sc, []int64{1, 2, 3}), option.NewWriteOption())))
+
+	// the profiles are kept in the manifest
+	f, err := fs.BuildFileSystem("file://" + dir)
+	suite.Require().NoError(err)
+	m, err := manifest.ParseFromFile(f, utils.GetManifestFilePath(dir, space.GetCurrentVersion()))
+	suite.Require().NoError(err)
+	suite.Equal(sc.Options().StorageProfiles, m.GetSchema().Options().StorageProfiles)
+
+	columns := make(map[string]*pqmetadata.ColumnChunkMetaData)
+	for _, dataDir := range []string{utils.GetScalarDataDir(dir), utils.GetVectorDataDir(dir)} {
+		files, err := filepath.Glob(filepath.Join(dataDir, "*.parquet"))
+		suite.Require().NoError(err)
+		suite.Require().Len(files, 1)
+		reader, err := pqfile.OpenParquetFile(files[0], false)
+		suite.Require().NoError(err)
+		rowGroup := reader.MetaData().RowGroup(0)
+		for i := 0; i < rowGroup.NumColumns(); i++ {
+			column, err := rowGroup.ColumnChunk(i)
+			suite.Require().NoError(err)
+			columns[column.PathInSchema().String()] = column
+		}
+		reader.Close()
+	}
+	suite.Equal(compress.Codecs.Zstd, columns["vs_field"].Compression())
+	suite.Contains(columns["vs_field"].Encodings(), pqlib.Encodings.DeltaBinaryPacked)
+	suite.Equal(compress.Codecs.Snappy, columns["vec_field"].Compression())
+	suite.NotContains(columns["vec_field"].Encodings(), pqlib.Encodings.PlainDict)
+	suite.Equal(compress.Codecs.Uncompressed, columns["pk_field"].Compression())
+
+	pks, err := readPks(space)
+	suite.Require().NoError(err)
+	suite.ElementsMatch([]int64{1, 2, 3}, pks)
+}
+
+func (suite *SpaceTestSuite) TestReadSkipsRowGroups() {
+	sc := createSchema()
+	suite.Require().NoError(sc.Validate())
+	space, err := storage.Open("file://"+suite.T().TempDir(), *option.NewOptions(sc, -1))
+	suite.Require().NoError(err)
+	const rowGroupRows = 8192
+	pks := make([]int64, 4*rowGroupRows)
+	for i := range pks {
+		pks[i] = int64(i)
+	}
+	suite.Require().NoError(commitErr(space.Write(createRecordReader(sc, pks), &option.WriteOptions{MaxRecordPerFile: int64(len(pks)), MaxRowGroupRows: rowGroupRows})))
+
+	read := func(filters ...filter.Filter) ([]int64, int64) {
+		var bytes int64
+		readOpt := option.NewReadOptions()
+		readOpt.AddColumn("pk_field")
+		for _, f := range filters {
+			readOpt.AddFilter(f)
+		}
+		readOpt.Progress = func(p option.Progress) { bytes = p.Bytes }
+		reader, err := space.Read(readOpt)
+		suite.Require().NoError(err)
+		defer reader.Release()
+		var read []int64
+		for reader.Next() {
+			read = append(read, reader.Record().Column(0).(*array.Int64).Int64Values()...)
+		}
+		suite.Require().NoError(reader.Err())
+		return read, bytes
+	}
+	all, allBytes := read()
+	suite.Len(all, len(pks))
+	// the point lookup only reads the row group holding the key
+	found, bytes := read(filter.NewConstantFilter(filter.Equal, "pk_field", int64(2*rowGroupRows+5)))
+	suite.Equal([]int64{2*rowGroupRows + 5}, found)
+	suite.Less(bytes, allBytes/2)
+}
+
+func (suite *SpaceTestSuite) TestReadParallelRowGroups() {
+	sc := createSchema()
+	suite.Require().NoError(sc.Validate())
+	space, err := storage.Open("file://"+suite.T().TempDir(), *option.NewOptions(sc, -1))
+	suite.Require().NoError(err)
+	const rowGroupRows = 8192
+	pks := make([]int64, 5*rowGroupRows+7)
+	for i := range pks {
+		pks[i] = int64(i)
+	}
+	suite.Require().NoError(commitErr(space.Write(createRecordReader(sc, pks), &option.WriteOptions{MaxRecordPerFile: int64(len(pks)), MaxRowGroupRows: rowGroupRows})))
+
+	for _, parallelism := range []int{0, 3} {
+		readOpt := option.NewReadOptions()
+		readOpt.AddColumn("pk_field")
+		readOpt.BatchSize = 1000
+		readOpt.Parallelism = parallelism
+		reader, err := space.Read(readOpt)
+		suite.Require().NoError(err)
+		var read []int64
+		for reader.Next() {
+			read = append(read, reader.Record().Column(0).(*array.Int64).Int64Values()...)
+		}
+		suite.Require().NoError(reader.Err())
+		reader.Release()
+		// the rows are returned in the order of the file
+		suite.Equal(pks, read)
+	}
+
+	// releasing the reader early stops decoding
+	readOpt := option.NewReadOptions()
+	readOpt.AddColumn("pk_field")
+	readOpt.Parallelism = 2
+	reader, err := space.Read(readOpt)
+	suite.Require().NoError(err)
+	suite.Require().True(reader.Next())
+	reader.Release()
+}
+
+func (suite *SpaceTestSuite) TestReadCastTo() {
+	as := arrow.NewSchema([]arrow.Field{
+		{Name: "pk_field", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "vs_field", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "score", Type: arrow.PrimitiveTypes.Float64},
+		{Name: "ts", Type: &arrow.TimestampType{Unit: arrow.Millisecond}},
+		{Name: "vec_field", Type: &arrow.FixedSizeBinaryType{ByteWidth: 2}},
+	}, nil)
+	sc := schema.NewSchema(as, &schema_option.SchemaOptions{
+		PrimaryColumn: "pk_field",
+		VersionColumn: "vs_field",
+		VectorColumn:  "vec_field",
+	})
+	suite.Require().NoError(sc.Validate())
+	space, err := storage.Open("file://"+suite.T().TempDir(), *option.NewOptions(sc, -1))
+	suite.Require().NoError(err)
+	b := array.NewRecordBuilder(memory.DefaultAllocator, as)
+	for _, pk := range []int64{1, 2, 300} {
+		b.Field(0).(*array.Int64Builder).Append(pk)
+		b.Field(1).(*array.Int64Builder).Append(1)
+		b.Field(2).(*array.Float64Builder).Append(float64(pk) / 2)
+		b.Field(3).(*array.TimestampBuilder).Append(arrow.Timestamp(pk * 1000))
+		b.Field(4).(*array.FixedSizeBinaryBuilder).Append([]byte{byte(pk), 0})
+	}
+	rec := b.NewRecord()
+	b.Release()
+	reader, err := array.NewRecordReader(as, []arrow.Record{rec})
+	suite.Require().NoError(err)
+	suite.Require().NoError(commitErr(space.Write(reader, option.NewWriteOption())))
+
+	readOpt := option.NewReadOptions()
+	readOpt.SetColumns([]string{"pk_field", "score", "ts"})
+	readOpt.CastTo(arrow.NewSchema([]arrow.Field{
+		{Name: "score", Type: arrow.PrimitiveTypes.Float32},
+		{Name: "ts", Type: &arrow.TimestampType{Unit: arrow.Second}},
+	}, nil))
+	result, err := space.Read(readOpt)
+	suite.Require().NoError(err)
+	suite.Equal(arrow.PrimitiveTypes.Float32, result.Schema().Field(1).Type)
+	suite.True(arrow.TypeEqual(&arrow.TimestampType{Unit: arrow.Second}, result.Schema().Field(2).Type))
+	var (
+		scores []float32
+		ts     []arrow.Timestamp
+	)
+	for result.Next() {
+		rec := result.Record()
+		suite.True(rec.Schema().Equal(result.Schema()))
+		scores = append(scores, rec.Column(1).(*array.Float32).Float32Values()...)
+		ts = append(ts, rec.Column(2).(*array.Timestamp).TimestampValues()...)
+	}
+	suite.Require().NoError(result.Err())
+	result.Release()
+	suite.ElementsMatch([]float32{0.5, 1, 150}, scores)
+	suite.ElementsMatch([]arrow.Timestamp{1, 2, 300}, ts)
+
+	// casts that lose data fail the read
+	readOpt = option.NewReadOptions()
+	readOpt.AddColumn("pk_field")
+	readOpt.CastTo(arrow.NewSchema([]arrow.Field{{Name: "pk_field", Type: arrow.PrimitiveTypes.Int8}}, nil))
+	result, err = space.Read(readOpt)
+	suite.Require().NoError(err)
+	for result.Next() {
+	}
+	suite.Error(result.Err())
+	result.Release()
+
+	readOpt.AddColumn("score")
+	readOpt.CastTo(arrow.NewSchema([]arrow.Field{{Name: "score", Type: &arrow.FixedSizeBinaryType{ByteWidth: 2}}}, nil))
+	_, err = space.Read(readOpt)
+	suite.ErrorIs(err, storage.ErrCastNotSupported)
+	readOpt.CastTo(arrow.NewSchema([]arrow.Field{{Name: "vec_field", Type: arrow.BinaryTypes.Binary}}, nil))
+	_, err = space.Read(readOpt)
+	suite.ErrorIs(err, storage.ErrColumnNotExist)
+}
+
+func (suite *SpaceTestSuite) TestReadProvenance() {
+	sc := createSchema()
+	suite.Require().NoError(sc.Validate())
+	dir := suite.T().TempDir()
+	space, err := storage.Open("file://"+dir, *option.NewOptions(sc, -1))
+	suite.Require().NoError(err)
+	suite.Require().NoError(commitErr(space.Write(createRecordReader(sc, []int64{1, 3}), option.NewWriteOption())))
+	suite.Require().NoError(commitErr(space.Write(createRecordReader(sc, []int64{2, 4}), option.NewWriteOption())))
+
+	read := func(readOpt *option.ReadOptions) []storage.Provenance {
+		reader, err := space.Read(readOpt)
+		suite.Require().NoError(err)
+		defer reader.Release()
+		var provenances []storage.Provenance
+		for reader.Next() {
+			provenances = append(provenances, reader.Provenance())
+		}
+		suite.Require().NoError(reader.Err())
+		return provenances
+	}
+
+	// a scan of scalar columns reads one file per fragment
+	readOpt := option.NewReadOptions()
+	readOpt.AddColumn("pk_field")
+	provenances := read(readOpt)
+	suite.Require().NotEmpty(provenances)
+	fragments := make(map[int64]bool)
+	for _, p := range provenances {
+		suite.Equal(space.GetCurrentVersion(), p.Version)
+		suite.Require().Len(p.Sources, 1)
+		suite.Require().Len(p.Sources[0].Files, 1)
+		suite.True(strings.HasPrefix(p.Sources[0].Files[0], utils.GetScalarDataDir(dir)))
+		fragments[p.Sources[0].FragmentID] = true
+	}
+	suite.Len(fragments, 2)
+
+	// vector columns are read from the vector files, joined reads from a scalar and a vector
+	// file
+	readOpt.AddColumn("vec_field")
+	for _, p := range read(readOpt) {
+		suite.Require().Len(p.Sources, 1)
+		suite.Require().Len(p.Sources[0].Files, 1)
+		suite.True(strings.HasPrefix(p.Sources[0].Files[0], utils.GetVectorDataDir(dir)))
+	}
+	readOpt.AddColumn(constant.OffsetFieldName)
+	for _, p := range read(readOpt) {
+		suite.Require().Len(p.Sources, 1)
+		suite.Require().Len(p.Sources[0].Files, 2)
+		suite.True(strings.HasPrefix(p.Sources[0].Files[0], utils.GetScalarDataDir(dir)))
+		suite.True(strings.HasPrefix(p.Sources[0].Files[1], utils.GetVectorDataDir(dir)))
+	}
+
+	// batches and ordered reads combine the rows of several files
+	readOpt = option.NewReadOptions()
+	readOpt.AddColumn("pk_field")
+	readOpt.BatchSize = 4
+	provenances = read(readOpt)
+	suite.Require().Len(provenances, 1)
+	suite.Len(provenances[0].Sources, 2)
+	readOpt = option.NewReadOptions()
+	readOpt.AddColumn("pk_field")
+	readOpt.OrderBy = option.OrderBy{Type: option.OrderKey, Column: "pk_field"}
+	provenances = read(readOpt)
+	suite.Require().Len(provenances, 1)
+	suite.Len(provenances[0].Sources, 2)
+}
+
+func (suite *SpaceTestSuite) TestLatestVersionHint() {
+	sc := createSchema()
+	suite.Require().NoError(sc.Validate())
+	dir := suite.T().TempDir()
+	space, err := storage.Open("file://"+dir, *option.NewOptions(sc, -1))
+	suite.Require().NoError(err)
+	for _, pk := range []int64{1, 2, 3} {
+		suite.Require().NoError(commitErr(space.Write(createRecordReader(sc, []int64{pk}), option.NewWriteOption())))
+	}
+	hint, err := os.ReadFile(utils.GetLatestFilePath(dir))
+	suite.Require().NoError(err)
+	suite.Equal(fmt.Sprint(space.GetCurrentVersion()), string(hint))
+
+	open := func() int64 {
+		reopened, err := storage.Open("file://"+dir, *option.NewOptions(nil, -1))
+		suite.Require().NoError(err)
+		return reopened.GetCurrentVersion()
+	}
+	suite.Equal(space.GetCurrentVersion(), open())
+	// hints left behind by concurrent commits are followed to the newest manifest, invalid
+	// or missing ones fall back to listing the manifests
+	for _, content := range []string{"1", "garbage", "42"} {
+		suite.Require().NoError(os.WriteFile(utils.GetLatestFilePath(dir), []byte(content), 0o644))
+		suite.Equal(space.GetCurrentVersion(), open())
+	}
+	suite.Require().NoError(os.Remove(utils.GetLatestFilePath(dir)))
+	suite.Equal(space.GetCurrentVersion(), open())
+
+	// a version is opened without the hint too
+	reopened, err := storage.Open("file://"+dir, *option.NewOptions(nil, 1))
+	suite.Require().NoError(err)
+	suite.Equal(int64(1), reopened.GetCurrentVersion())
+	_, err = storage.Open("file://"+dir, *option.NewOptions(nil, 42))
+	suite.ErrorIs(err, storage.ErrManifestNotFound)
+}
+
+func (suite *SpaceTestSuite) TestManager() {
+	sc := createSchema()
+	suite.Require().NoError(sc.Validate())
+	uri := "file://" + suite.T().TempDir()
+	mgr := storage.NewManager()
+
+	handles := make([]*storage.SpaceHandle, 8)
+	var wg sync.WaitGroup
+	for i := range handles {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			h, err := mgr.Open(uri, *option.NewOptions(sc, -1))
+			suite.NoError(err)
+			handles[i] = h
+		}(i)
+	}
+	wg.Wait()
+	for _, h := range handles {
+		suite.Require().NotNil(h)
+		suite.Same(handles[0].Space, h.Space)
+	}
+	suite.Require().NoError(commitErr(handles[0].Write(createRecordReader(sc, []int64{1}), option.NewWriteOption())))
+
+	// another version is another space
+	old, err := mgr.Open(uri, *option.NewOptions(nil, 0))
+	suite.Require().NoError(err)
+	suite.NotSame(handles[0].Space, old.Space)
+	suite.Equal(int64(0), old.GetCurrentVersion())
+	suite.NoError(old.Close())
+
+	// the space is opened again once every handle is closed
+	for _, h := range handles[1:] {
+		suite.NoError(h.Close())
+	}
+	h, err := mgr.Open(uri, *option.NewOptions(nil, -1))
+	suite.Require().NoError(err)
+	suite.Same(handles[0].Space, h.Space)
+	suite.NoError(h.Close())
+	suite.NoError(handles[0].Close())
+	suite.NoError(handles[0].Close(), "closing twice releases once")
+	h, err = mgr.Open(uri, *option.NewOptions(nil, -1))
+	suite.Require().NoError(err)
+	suite.NotSame(handles[0].Space, h.Space)
+	suite.Equal(handles[0].GetCurrentVersion(), h.GetCurrentVersion())
+	suite.NoError(h.Close())
+
+	// a failed Open does not close the file system of a concurrent one
+	handles = make([]*storage.SpaceHandle, 8)
+	for i := range handles {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			h, err := mgr.Open(uri, *option.NewOptions(nil, -1))
+			suite.NoError(err)
+			handles[i] = h
+		}(i)
+		go func() {
+			defer wg.Done()
+			_, err := mgr.Open(uri, *option.NewOptions(nil, 42))
+			suite.Error(err)
+		}()
+	}
+	wg.Wait()
+	suite.Require().NotNil(handles[0])
+	suite.Require().NoError(commitErr(handles[0].Write(createRecordReader(sc, []int64{2}), option.NewWriteOption())))
+	for _, h := range handles {
+		suite.NoError(h.Close())
+	}
+}
+
+func (suite *SpaceTestSuite) TestClose() {
+	sc := createSchema()
+	suite.Require().NoError(sc.Validate())
+	dir := suite.T().TempDir()
+	openWithLease := func(owner string) *storage.Space {
+		ops := option.NewOptions(sc, -1)
+		ops.WriterLease = &option.LeaseOptions{Owner: owner, TTL: time.Minute}
+		space, err := storage.Open("file://"+dir, *ops)
+		suite.Require().NoError(err)
+		return space
+	}
+	a := openWithLease("a")
+	suite.Require().NoError(commitErr(a.Write(createRecordReader(sc, []int64{1, 2, 3}), option.NewWriteOption())))
+	reader, err := a.Read(option.NewReadOptions())
+	suite.Require().NoError(err)
+	defer reader.Release()
+	suite.True(reader.Next())
+
+	suite.NoError(a.Close())
+	suite.NoError(a.Close(), "closing twice does nothing")
+	suite.False(reader.Next())
+	suite.ErrorIs(reader.Err(), storage.ErrSpaceClosed)
+	suite.ErrorIs(commitErr(a.Write(createRecordReader(sc, []int64{4}), option.NewWriteOption())), storage.ErrSpaceClosed)
+	_, err = a.Read(option.NewReadOptions())
+	suite.ErrorIs(err, storage.ErrSpaceClosed)
+
+	// the lease is given up, so another writer does not wait for it to expire
+	b := openWithLease("b")
+	suite.NoError(commitErr(b.Write(createRecordReader(sc, []int64{4}), option.NewWriteOption())))
+	suite.NoError(b.Close())
+}
+
+func (suite *SpaceTestSuite) TestPing() {
+	sc := createSchema()
+	suite.Require().NoError(sc.Validate())
+	dir := suite.T().TempDir()
+	space, err := storage.Open("file://"+dir, *option.NewOptions(sc, -1))
+	suite.Require().NoError(err)
+	suite.NoError(space.Ping(context.Background()))
+	probes, err := filepath.Glob(filepath.Join(dir, constant.PingFilePrefix+"*"))
+	suite.Require().NoError(err)
+	suite.Empty(probes, "the probe file is deleted")
+
+	suite.Require().NoError(space.Close())
+	suite.ErrorIs(space.Ping(context.Background()), storage.ErrSpaceClosed)
+}
+
+func (suite *SpaceTestSuite) TestCommitResult() {
+	sc := createSchema()
+	suite.Require().NoError(sc.Validate())
+	space, err := storage.Open("file://"+suite.T().TempDir(), *option.NewOptions(sc, -1))
+	suite.Require().NoError(err)
+
+	written, err := space.Write(createRecordReader(sc, []int64{1, 2, 3}), option.NewWriteOption())
+	suite.Require().NoError(err)
+	suite.Equal(space.GetCurrentVersion(), written.Version)
+	suite.Equal([]int64{written.Version}, written.FragmentIDs)
+	suite.Equal(int64(3), written.Rows)
+	suite.Positive(written.Bytes)
+	suite.Require().NotEmpty(written.Files)
+	for _, file := range written.Files {
+		suite.FileExists(file)
+	}
+
+	deleted, err := space.Delete(createDeleteReader(sc, []int64{2}, []int64{2}))
+	suite.Require().NoError(err)
+	suite.Equal(written.Version+1, deleted.Version)
+	suite.Equal([]int64{deleted.Version}, deleted.FragmentIDs)
+	suite.Equal(int64(1), deleted.Rows)
+	suite.Len(deleted.Files, 1)
+
+	// nothing to delete, nothing committed
+	empty, err := space.Delete(createDeleteReader(sc, nil, nil))
+	suite.Require().NoError(err)
+	suite.Equal(storage.CommitResult{Version: deleted.Version}, empty)
+
+	blobResult, err := space.WriteBlob([]byte("content"), "blob", false)
+	suite.Require().NoError(err)
+	suite.Equal(deleted.Version+1, blobResult.Version)
+	suite.Empty(blobResult.FragmentIDs)
+	suite.Len(blobResult.Files, 1)
+	suite.Equal(int64(len("content")), blobResult.Bytes)
+}
+
+func (suite *SpaceTestSuite) TestMemoryUsage() {
+	sc := createSchema()
+	suite.Require().NoError(sc.Validate())
+	mgr := storage.NewManager()
+	space, err := mgr.Open("file://"+suite.T().TempDir(), *option.NewOptions(sc, -1))
+	suite.Require().NoError(err)
+	defer space.Close()
+	suite.Require().NoError(commitErr(space.Write(createRecordReader(sc, []int64{1, 2, 3}), option.NewWriteOption())))
+	suite.Require().NoError(commitErr(space.Delete(createDeleteReader(sc, []int64{2}, []int64{2}))))
+	suite.Zero(space.MemoryUsage().Writers, "the pages of finished writes are released")
+
+	reader, err := space.Read(option.NewReadOptions())
+	suite.Require().NoError(err)
+	suite.True(reader.Next())
+	usage := space.MemoryUsage()
+	suite.Positive(usage.Readers)
+	suite.Positive(usage.Caches, "the delete fragment is cached")
+	suite.Equal(usage, mgr.MemoryUsage())
+
+	// the open reader keeps the manager over its limit, dropping the caches does not help
+	mgr.SetMemoryLimit(1)
+	_, err = space.Read(option.NewReadOptions())
+	suite.ErrorIs(err, storage.ErrMemoryLimitExceeded)
+	suite.ErrorIs(err, errors.ErrThrottled)
+	suite.Zero(space.MemoryUsage().Caches)
+	suite.ErrorIs(commitErr(space.Write(createRecordReader(sc, []int64{4}), option.NewWriteOption())), storage.ErrMemoryLimitExceeded)
+
+	reader.Release()
+	suite.Zero(space.MemoryUsage().Readers)
+	pks, err := readPks(space.Space)
+	suite.Require().NoError(err)
+	suite.ElementsMatch([]int64{1, 3}, pks)
+}
+
+func (suite *SpaceTestSuite) TestStagingDir() {
+	sc := createSchema()
+	suite.Require().NoError(sc.Validate())
+	dir, staging := suite.T().TempDir(), suite.T().TempDir()
+	ops := option.NewOptions(sc, -1)
+	ops.StagingDir = staging
+	space, err := storage.Open("file://"+dir, *ops)
+	suite.Require().NoError(err)
+	result, err := space.Write(createRecordReader(sc, []int64{1, 2, 3}), option.NewWriteOption())
+	suite.Require().NoError(err)
+	for _, file := range result.Files {
+		suite.FileExists(file)
+	}
+	pks, err := readPks(space)
+	suite.Require().NoError(err)
+	suite.ElementsMatch([]int64{1, 2, 3}, pks)
+
+	// the staged files are removed once uploaded
+	var staged []string
+	suite.Require().NoError(filepath.WalkDir(staging, func(path string, d iofs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			staged = append(staged, path)
+		}
+		return err
+	}))
+	suite.Empty(staged)
+
+	// files left by a crashed process are removed by the next open
+	spaceDirs, err := os.ReadDir(staging)
+	suite.Require().NoError(err)
+	suite.Require().Len(spaceDirs, 1)
+	crashed := filepath.Join(staging, spaceDirs[0].Name(), "crashed", "leftover.parquet")
+	suite.Require().NoError(os.MkdirAll(filepath.Dir(crashed), 0o755))
+	suite.Require().NoError(os.WriteFile(crashed, []byte("partial"), 0o644))
+	_, err = storage.Open("file://"+dir, *ops)
+	suite.Require().NoError(err)
+	suite.NoDirExists(filepath.Dir(crashed))
+
+	// a write may use its own staging directory
+	opt := option.NewWriteOption()
+	opt.StagingDir = suite.T().TempDir()
+	suite.NoError(commitErr(space.Write(createRecordReader(sc, []int64{4}), opt)))
+	pks, err = readPks(space)
+	suite.Require().NoError(err)
+	suite.ElementsMatch([]int64{1, 2, 3, 4}, pks)
+}
+
+func (suite *SpaceTestSuite) TestWriteMemoryBudget() {
+	sc := createSchema()
+	suite.Require().NoError(sc.Validate())
+	space, err := storage.Open("file://"+suite.T().TempDir(), *option.NewOptions(sc, -1))
+	suite.Require().NoError(err)
+	pks := make([]int64, 100)
+	for i := range pks {
+		pks[i] = int64(i)
+	}
+
+	opt := option.NewWriteOption()
+	opt.MaxRecordPerFile = 10
+	whole, err := space.Write(createRecordReader(sc, pks), opt)
+	suite.Require().NoError(err)
+	suite.Len(whole.Files, 2, "a batch is written to a single file pair")
+
+	// oversized batches are written in slices, so files are rolled over as configured
+	opt.MemoryBudget = 256
+	sliced, err := space.Write(createRecordReader(sc, pks), opt)
+	suite.Require().NoError(err)
+	suite.Greater(len(sliced.Files), len(whole.Files))
+	suite.Equal(int64(100), sliced.Rows)
+	for _, file := range sliced.Files {
+		suite.FileExists(file)
+	}
+	read, err := readPks(space)
+	suite.Require().NoError(err)
+	suite.Len(read, 200)
+}
+
+func (suite *SpaceTestSuite) TestValidateVectors() {
+	as := arrow.NewSchema([]arrow.Field{
+		{Name: "pk_field", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "vs_field", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "vec_field", Type: arrow.FixedSizeListOf(4, arrow.PrimitiveTypes.Float32)},
+	}, nil)
+	sc := schema.NewSchema(as, &schema_option.SchemaOptions{
+		PrimaryColumn: "pk_field",
+		VersionColumn: "vs_field",
+		VectorColumn:  "vec_field",
+	})
+	suite.Require().NoError(sc.Validate())
+	space, err := storage.Open("file://"+suite.T().TempDir(), *option.NewOptions(sc, -1))
+	suite.Require().NoError(err)
+
+	// vectors are given as their first value, other dimensions are 0, nil is a null vector
+	batch := func(first int64, vectors ...*float32) arrow.Record {
+		b := array.NewRecordBuilder(memory.DefaultAllocator, as)
+		defer b.Release()
+		list := b.Field(2).(*array.FixedSizeListBuilder)
+		values := list.ValueBuilder().(*array.Float32Builder)
+		for i, vector := range vectors {
+			b.Field(0).(*array.Int64Builder).Append(first + int64(i))
+			b.Field(1).(*array.Int64Builder).Append(1)
+			if vector == nil {
+				list.AppendNull()
+				values.AppendValues(make([]float32, 4), nil)
+				continue
+			}
+			list.Append(true)
+			values.AppendValues([]float32{*vector, 0, 0, 0}, nil)
+		}
+		return b.NewRecord()
+	}
+	value := func(v float32) *float32 { return &v }
+	write := func(opt *option.WriteOptions, recs ...arrow.Record) error {
+		reader, err := array.NewRecordReader(as, recs)
+		suite.Require().NoError(err)
+		return commitErr(space.Write(reader, opt))
+	}
+
+	opt := option.NewWriteOption()
+	suite.NoError(write(opt, batch(0, value(1), value(float32(math.NaN())))))
+
+	// NaN and infinite values are rejected when configured, rows count across batches
+	opt.RejectNonFinite = true
+	err = write(opt, batch(2, value(1), value(2)), batch(4, value(3), value(float32(math.Inf(1)))))
+	var vectorErr *storage.VectorError
+	suite.Require().ErrorAs(err, &vectorErr)
+	suite.ErrorIs(err, storage.ErrInvalidVector)
+	suite.Equal("vec_field", vectorErr.Column)
+	suite.Equal(int64(3), vectorErr.Row)
+
+	err = write(opt, batch(6, value(1), nil))
+	suite.Require().ErrorAs(err, &vectorErr)
+	suite.Equal(int64(1), vectorErr.Row)
+
+	pks, err := readPks(space)
+	suite.Require().NoError(err)
+	suite.ElementsMatch([]int64{0, 1}, pks)
+}
+
+func (suite *SpaceTestSuite) TestWriteDuplicates() {
+	sc := createSchema()
+	suite.Require().NoError(sc.Validate())
+	space, err := storage.Open("file://"+suite.T().TempDir(), *option.NewOptions(sc, -1))
+	suite.Require().NoError(err)
+	write := func(opt *option.WriteOptions, batches ...[]int64) (storage.CommitResult, error) {
+		var recs []arrow.Record
+		for _, pks := range batches {
+			reader := createRecordReader(sc, pks)
+			suite.Require().True(reader.Next())
+			recs = append(recs, reader.Record())
+		}
+		reader, err := array.NewRecordReader(sc.Schema(), recs)
+		suite.Require().NoError(err)
+		return space.Write(reader, opt)
+	}
+
+	// duplicates are written by default
+	opt := option.NewWriteOption()
+	suite.NoError(commitErr(write(opt, []int64{1, 1})))
+
+	opt.Duplicates = option.DuplicatesReject
+	_, err = write(opt, []int64{2, 3}, []int64{4, 3})
+	var duplicateErr *storage.DuplicateKeyError
+	suite.Require().ErrorAs(err, &duplicateErr)
+	suite.ErrorIs(err, storage.ErrDuplicateKey)
+	suite.Equal(int64(3), duplicateErr.Key)
+	suite.Equal(int64(3), duplicateErr.Row)
+	suite.Equal(int64(1), duplicateErr.FirstRow)
+
+	opt.Duplicates = option.DuplicatesLastWins
+	result, err := write(opt, []int64{2, 3}, []int64{4, 3, 2})
+	suite.Require().NoError(err)
+	suite.Equal(int64(3), result.Rows)
+
+	pks, err := readPks(space)
+	suite.Require().NoError(err)
+	suite.ElementsMatch([]int64{1, 1, 2, 3, 4}, pks)
+}
+
+func (suite *SpaceTestSuite) TestAnalyze() {
+	sc := createSchema()
+	suite.Require().NoError(sc.Validate())
+	space, err := storage.Open("file://"+suite.T().TempDir(), *option.NewOptions(sc, -1))
+	suite.Require().NoError(err)
+	pks := make([]int64, 100)
+	for i := range pks {
+		pks[i] = int64(i % 50)
+	}
+	suite.Require().NoError(commitErr(space.Write(createRecordReader(sc, pks), option.NewWriteOption())))
+
+	_, ok, err := space.ColumnStats("pk_field")
+	suite.Require().NoError(err)
+	suite.False(ok)
+
+	version, err := space.Analyze("pk_field")
+	suite.Require().NoError(err)
+	stats, ok, err := space.ColumnStats("pk_field")
+	suite.Require().NoError(err)
+	suite.Require().True(ok)
+	suite.Equal(version-1, stats.Version)
+	suite.Equal(int64(100), stats.Rows)
+	suite.Equal(int64(50), stats.NDV)
+	suite.False(stats.Sampled)
+	suite.Len(stats.Histogram, option.DefaultHistogramBuckets)
+	suite.Equal(float64(49), stats.Histogram[len(stats.Histogram)-1].Upper)
+	var rows int64
+	for _, bucket := range stats.Histogram {
+		rows += bucket.Rows
+	}
+	suite.Equal(int64(100), rows)
+
+	// statistics outlive later commits but not a new Analyze
+	suite.Require().NoError(commitErr(space.Delete(createDeleteReader(sc, []int64{0, 1}, []int64{0, 1}))))
+	kept, ok, err := space.ColumnStats("pk_field")
+	suite.Require().NoError(err)
+	suite.Require().True(ok)
+	suite.Equal(stats, kept)
+
+	opts := option.NewAnalyzeOptions()
+	opts.SampleRows = 20
+	_, err = space.AnalyzeContext(context.Background(), opts)
+	suite.Require().NoError(err)
+	stats, _, err = space.ColumnStats("pk_field")
+	suite.Require().NoError(err)
+	suite.True(stats.Sampled)
+	suite.Equal(int64(96), stats.Rows)
+	suite.Greater(stats.NDV, int64(0))
+	_, ok, err = space.ColumnStats("vs_field")
+	suite.Require().NoError(err)
+	suite.True(ok)
+
+	_, err = space.Analyze("vec_field")
+	suite.ErrorIs(err, storage.ErrUnsupportedAnalyze)
+	_, err = space.Analyze("missing")
+	suite.ErrorIs(err, storage.ErrColumnNotExist)
+}
+
+func (suite *SpaceTestSuite) TestResultCache() {
+	sc := createSchema()
+	suite.Require().NoError(sc.Validate())
+	opts := option.NewOptions(sc, -1)
+	cache := storage.NewLRUResultCache(1 << 20)
+	opts.ResultCache = cache
+	space, err := storage.Open("file://"+suite.T().TempDir(), *opts)
+	suite.Require().NoError(err)
+	suite.Require().NoError(commitErr(space.Write(createRecordReader(sc, []int64{1, 2, 3}), option.NewWriteOption())))
+
+	read := func(value int64) []int64 {
+		readOpt := option.NewReadOptions()
+		readOpt.AddColumn("pk_field")
+		readOpt.AddFilter(filter.NewConstantFilter(filter.GreaterThan, "pk_field", value))
+		reader, err := space.Read(readOpt)
+		suite.Require().NoError(err)
+		defer reader.Release()
+		var pks []int64
+		for reader.Next() {
+			pks = append(pks, reader.Record().Column(0).(*array.Int64).Int64Values()...)
+		}
+		suite.Require().NoError(reader.Err())
+		return pks
+	}
+
+	suite.ElementsMatch([]int64{2, 3}, read(1))
+	suite.Greater(cache.Size(), int64(0))
+	suite.ElementsMatch([]int64{2, 3}, read(1))
+	hits, misses := cache.Stats()
+	suite.Equal(int64(1), hits)
+	suite.Equal(int64(1), misses)
+
+	// other filters and new versions miss
+	suite.ElementsMatch([]int64{3}, read(2))
+	suite.Require().NoError(commitErr(space.Write(createRecordReader(sc, []int64{4}), option.NewWriteOption())))
+	suite.ElementsMatch([]int64{2, 3, 4}, read(1))
+	hits, misses = cache.Stats()
+	suite.Equal(int64(1), hits)
+	suite.Equal(int64(3), misses)
+
+	// results over the capacity are not cached
+	small := storage.NewLRUResultCache(1)
+	opts.ResultCache = small
+	other, err := storage.Open("file://"+suite.T().TempDir(), *opts)
+	suite.Require().NoError(err)
+	suite.Require().NoError(commitErr(other.Write(createRecordReader(sc, []int64{1}), option.NewWriteOption())))
+	pks, err := readPks(other)
+	suite.Require().NoError(err)
+	suite.Equal([]int64{1}, pks)
+	suite.Equal(int64(0), small.Size())
+}
+
+// doubleHook adds a pk_doubled column to the records read.
+type doubleHook struct {
+	wrongSchema bool
+}
+
+func (h doubleHook) Schema(in *arrow.Schema) (*arrow.Schema, error) {
+	return arrow.NewSchema(append(in.Fields(), arrow.Field{Name: "pk_doubled", Type: arrow.PrimitiveTypes.Int64}), nil), nil
+}
+
+func (h doubleHook) Transform(rec arrow.Record) (arrow.Record, error) {
+	if h.wrongSchema {
+		rec.Retain()
+		return rec, nil
+	}
+	b := array.NewInt64Builder(memory.DefaultAllocator)
+	defer b.Release()
+	for _, pk := range rec.Column(0).(*array.Int64).Int64Values() {
+		b.Append(pk * 2)
+	}
+	doubled := b.NewArray()
+	defer doubled.Release()
+	schema, _ := h.Schema(rec.Schema())
+	return array.NewRecord(schema, append(rec.Columns(), doubled), rec.NumRows()), nil
+}
+
+func (suite *SpaceTestSuite) TestReadHooks() {
+	sc := createSchema()
+	suite.Require().NoError(sc.Validate())
+	space, err := storage.Open("file://"+suite.T().TempDir(), *option.NewOptions(sc, -1))
+	suite.Require().NoError(err)
+	suite.Require().NoError(commitErr(space.Write(createRecordReader(sc, []int64{1, 2, 3}), option.NewWriteOption())))
+
+	readOpt := option.NewReadOptions()
+	readOpt.AddColumn("pk_field")
+	readOpt.AddHook(doubleHook{})
+	reader, err := space.Read(readOpt)
+	suite.Require().NoError(err)
+	fields := reader.Schema().Fields()
+	suite.Equal("pk_doubled", fields[len(fields)-1].Name)
+	doubled := make(map[int64]int64)
+	for reader.Next() {
+		rec := reader.Record()
+		for i, pk := range rec.Column(0).(*array.Int64).Int64Values() {
+			doubled[pk] = rec.Column(len(fields) - 1).(*array.Int64).Value(i)
+		}
+	}
+	suite.NoError(reader.Err())
+	reader.Release()
+	suite.Equal(map[int64]int64{1: 2, 2: 4, 3: 6}, doubled)
+
+	readOpt = option.NewReadOptions()
+	readOpt.AddColumn("pk_field")
+	readOpt.AddHook(doubleHook{wrongSchema: true})
+	reader, err = space.Read(readOpt)
+	suite.Require().NoError(err)
+	defer reader.Release()
+	suite.False(reader.Next())
+	suite.ErrorIs(reader.Err(), storage.ErrHookSchema)
+}
+
+func (suite *SpaceTestSuite) TestWriteHooks() {
+	sc := createSchema()
+	suite.Require().NoError(sc.Validate())
+	opts := option.NewOptions(sc, -1)
+	// stamps every row with version 100
+	opts.WriteHooks = []option.WriteHook{func(rec arrow.Record) (arrow.Record, error) {
+		b := array.NewInt64Builder(memory.DefaultAllocator)
+		defer b.Release()
+		for i := int64(0); i < rec.NumRows(); i++ {
+			b.Append(100)
+		}
+		versions := b.NewArray()
+		defer versions.Release()
+		return array.NewRecord(rec.Schema(), []arrow.Array{rec.Column(0), versions, rec.Column(2)}, rec.NumRows()), nil
+	}}
+	space, err := storage.Open("file://"+suite.T().TempDir(), *opts)
+	suite.Require().NoError(err)
+	suite.Require().NoError(commitErr(space.Write(createRecordReader(sc, []int64{1, 2, 3}), option.NewWriteOption())))
+
+	readOpt := option.NewReadOptions()
+	readOpt.AddColumn("vs_field")
+	reader, err := space.Read(readOpt)
+	suite.Require().NoError(err)
+	var versions []int64
+	for reader.Next() {
+		versions = append(versions, reader.Record().Column(0).(*array.Int64).Int64Values()...)
+	}
+	suite.NoError(reader.Err())
+	reader.Release()
+	suite.Equal([]int64{100, 100, 100}, versions)
+
+	// hooks cannot change the schema
+	opts.WriteHooks = append(opts.WriteHooks, func(rec arrow.Record) (arrow.Record, error) {
+		schema := arrow.NewSchema(rec.Schema().Fields()[:2], nil)
+		return array.NewRecord(schema, rec.Columns()[:2], rec.NumRows()), nil
+	})
+	space, err = storage.Open("file://"+suite.T().TempDir(), *opts)
+	suite.Require().NoError(err)
+	suite.ErrorIs(commitErr(space.Write(createRecordReader(sc, []int64{1}), option.NewWriteOption())), storage.ErrSchemaNotMatch)
+}
+
+func (suite *SpaceTestSuite) TestAutoVersion() {
+	sc := createSchema()
+	suite.Require().NoError(sc.Validate())
+	opts := option.NewOptions(sc, -1)
+	var (
+		space      *storage.Space
+		interleave bool
+	)
+	// commits another version in the middle of a write when interleave is set
+	opts.WriteHooks = []option.WriteHook{func(rec arrow.Record) (arrow.Record, error) {
+		if interleave {
+			if _, err := space.CommitEmpty(nil); err != nil {
+				return nil, err
+			}
+		}
+		rec.Retain()
+		return rec, nil
+	}}
+	space, err := storage.Open("file://"+suite.T().TempDir(), *opts)
+	suite.Require().NoError(err)
+	readVersions := func() map[int64]int64 {
+		readOpt := option.NewReadOptions()
+		readOpt.AddColumn("pk_field")
+		readOpt.AddColumn("vs_field")
+		reader, err := space.Read(readOpt)
+		suite.Require().NoError(err)
+		defer reader.Release()
+		versions := make(map[int64]int64)
+		for reader.Next() {
+			rec := reader.Record()
+			for i, pk := range rec.Column(0).(*array.Int64).Int64Values() {
+				versions[pk] = rec.Column(1).(*array.Int64).Value(i)
+			}
+		}
+		suite.Require().NoError(reader.Err())
+		return versions
+	}
+
+	// the version column may be omitted and is filled with the version committed
+	withoutVersion := arrow.NewSchema([]arrow.Field{sc.Schema().Field(0), sc.Schema().Field(2)}, nil)
+	rec := createRecordReader(sc, []int64{1, 2})
+	suite.Require().True(rec.Next())
+	partial := array.NewRecord(withoutVersion, []arrow.Array{rec.Record().Column(0), rec.Record().Column(2)}, 2)
+	defer partial.Release()
+	reader, err := array.NewRecordReader(withoutVersion, []arrow.Record{partial})
+	suite.Require().NoError(err)
+	opt := option.NewWriteOption()
+	suite.ErrorIs(commitErr(space.Write(reader, opt)), storage.ErrSchemaNotMatch)
+	reader, err = array.NewRecordReader(withoutVersion, []arrow.Record{partial})
+	suite.Require().NoError(err)
+	opt.AutoVersion = true
+	result, err := space.Write(reader, opt)
+	suite.Require().NoError(err)
+	suite.Equal(map[int64]int64{1: result.Version, 2: result.Version}, readVersions())
+
+	// or with the timestamp given, replacing the versions of the records
+	opt.VersionTimestamp = 1700000000
+	suite.Require().NoError(commitErr(space.Write(createRecordReader(sc, []int64{3}), opt)))
+	suite.Equal(int64(1700000000), readVersions()[3])
+
+	// a write filled with its version cannot commit another one
+	opt.VersionTimestamp = 0
+	interleave = true
+	suite.ErrorIs(commitErr(space.Write(createRecordReader(sc, []int64{4}), opt)), storage.ErrAutoVersionConflict)
+	opt.VersionTimestamp = 1700000001
+	suite.NoError(commitErr(space.Write(createRecordReader(sc, []int64{4}), opt)))
+}
+
+func (suite *SpaceTestSuite) TestOpenValidatesSchema() {
+	as := arrow.NewSchema([]arrow.Field{
+		{Name: "pk_field", Type: arrow.PrimitiveTypes.Float64},
+		{Name: "vs_field", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "vec_field", Type: &arrow.FixedSizeBinaryType{ByteWidth: 10}},
+	}, nil)
+	sc := schema.NewSchema(as, &schema_option.SchemaOptions{
+		PrimaryColumn: "pk_field",
+		VersionColumn: "vs_field",
+		VectorColumn:  "vec_field",
+	})
+	dir := suite.T().TempDir()
+	_, err := storage.Open("file://"+dir, *option.NewOptions(sc, -1))
+	suite.ErrorIs(err, schema_option.ErrPrimaryColumnType)
+	manifests, err := os.ReadDir(filepath.Join(dir, "versions"))
+	suite.Require().NoError(err)
+	suite.Empty(manifests)
+
+	// schemas are validated without calling Validate first
+	sc = createSchema()
+	space, err := storage.Open("file://"+dir, *option.NewOptions(sc, -1))
+	suite.Require().NoError(err)
+	suite.NoError(commitErr(space.Write(createRecordReader(sc, []int64{1}), option.NewWriteOption())))
+}
+
+func (suite *SpaceTestSuite) TestReservedColumns() {
+	dir := suite.T().TempDir()
+	sc := createSchema()
+	space, err := storage.Open("file://"+dir, *option.NewOptions(sc, -1))
+	suite.Require().NoError(err)
+	f, err := fs.BuildFileSystem("file://" + dir)
+	suite.Require().NoError(err)
+	m, err := manifest.ParseFromFile(f, utils.GetManifestFilePath(dir, space.GetCurrentVersion()))
+	suite.Require().NoError(err)
+	suite.Equal(schema_option.ReservedColumns(), m.ReservedColumns())
+
+	// a space created with a colliding column before schemas were validated cannot be opened
+	dir = suite.T().TempDir()
+	as := arrow.NewSchema(append(sc.Schema().Fields(), arrow.Field{Name: "__offset", Type: arrow.PrimitiveTypes.Int64}), nil)
+	old := manifest.NewManifest(schema.NewSchema(as, sc.Options()))
+	suite.Require().NoError(f.CreateDir(utils.GetManifestDir(dir)))
+	file, err := f.OpenFile(utils.GetManifestFilePath(dir, 0))
+	suite.Require().NoError(err)
+	suite.Require().NoError(manifest.WriteManifestFile(old, file))
+	suite.Require().NoError(file.Close())
+	_, err = storage.Open("file://"+dir, *option.NewOptions(nil, -1))
+	suite.ErrorIs(err, schema_option.ErrReservedColumn)
+}
+
+func (suite *SpaceTestSuite) TestMultipleVectorColumns() {
+	as := arrow.NewSchema([]arrow.Field{
+		{Name: "pk_field", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "vs_field", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "vec_field", Type: &arrow.FixedSizeBinaryType{ByteWidth: 4}},
+		{Name: "sparse_field", Type: &arrow.FixedSizeBinaryType{ByteWidth: 8}},
+	}, nil)
+	sc := schema.NewSchema(as, &schema_option.SchemaOptions{
+		PrimaryColumn: "pk_field",
+		VersionColumn: "vs_field",
+		VectorColumn:  "vec_field",
+		VectorColumns: []string{"sparse_field"},
+	})
+	suite.Require().NoError(sc.Validate())
+	// both vector columns are stored in the vector files only
+	suite.True(sc.VectorSchema().HasField("sparse_field"))
+	suite.False(sc.ScalarSchema().HasField("sparse_field"))
+	suite.False(sc.ScalarSchema().HasField("vec_field"))
+	space, err := storage.Open("file://"+suite.T().TempDir(), *option.NewOptions(sc, -1))
+	suite.Require().NoError(err)
+
+	b := array.NewRecordBuilder(memory.DefaultAllocator, as)
+	defer b.Release()
+	for i := 0; i < 3; i++ {
+		b.Field(0).(*array.Int64Builder).Append(int64(i))
+		b.Field(1).(*array.Int64Builder).Append(1)
+		b.Field(2).(*array.FixedSizeBinaryBuilder).Append([]byte{byte(i), 0, 0, 0})
+		b.Field(3).(*array.FixedSizeBinaryBuilder).Append([]byte{0, 0, 0, 0, byte(i), 0, 0, 1})
+	}
+	rec := b.NewRecord()
+	defer rec.Release()
+	reader, err := array.NewRecordReader(as, []arrow.Record{rec})
+	suite.Require().NoError(err)
+	suite.Require().NoError(commitErr(space.Write(reader, option.NewWriteOption())))
+
+	opt := option.NewReadOptions()
+	opt.AddColumn("vec_field")
+	opt.AddColumn("sparse_field")
+	read, err := space.Read(opt)
+	suite.Require().NoError(err)
+	defer read.Release()
+	var rows int
+	for read.Next() {
+		rec := read.Record()
+		vec := rec.Column(rec.Schema().FieldIndices("vec_field")[0]).(*array.FixedSizeBinary)
+		sparse := rec.Column(rec.Schema().FieldIndices("sparse_field")[0]).(*array.FixedSizeBinary)
+		for i := 0; i < int(rec.NumRows()); i++ {
+			suite.Equal(byte(rows), vec.Value(i)[0])
+			suite.Equal([]byte{0, 0, 0, 0, byte(rows), 0, 0, 1}, sparse.Value(i))
+			rows++
+		}
+	}
+	suite.NoError(read.Err())
+	suite.Equal(3, rows)
+
+	// every vector column is validated
+	b.Field(0).(*array.Int64Builder).Append(3)
+	b.Field(1).(*array.Int64Builder).Append(1)
+	b.Field(2).(*array.FixedSizeBinaryBuilder).Append([]byte{3, 0, 0, 0})
+	b.Field(3).(*array.FixedSizeBinaryBuilder).AppendNull()
+	invalid := b.NewRecord()
+	defer invalid.Release()
+	reader, err = array.NewRecordReader(as, []arrow.Record{invalid})
+	suite.Require().NoError(err)
+	err = commitErr(space.Write(reader, option.NewWriteOption()))
+	var vectorErr *storage.VectorError
+	suite.Require().ErrorAs(err, &vectorErr)
+	suite.Equal("sparse_field", vectorErr.Column)
+
+	// vector columns are left out of Analyze by default
+	_, err = space.Analyze()
+	suite.Require().NoError(err)
+	_, ok, err := space.ColumnStats("sparse_field")
+	suite.NoError(err)
+	suite.False(ok)
+}
+
+func (suite *SpaceTestSuite) TestNullableVectors() {
+	as := arrow.NewSchema([]arrow.Field{
+		{Name: "pk_field", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "vs_field", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "vec_field", Type: &arrow.FixedSizeBinaryType{ByteWidth: 4}, Nullable: true},
+		{Name: "multi_field", Type: arrow.ListOf(arrow.PrimitiveTypes.Float32), Nullable: true},
+	}, nil)
+	sc := schema.NewSchema(as, &schema_option.SchemaOptions{
+		PrimaryColumn: "pk_field",
+		VersionColumn: "vs_field",
+		VectorColumn:  "vec_field",
+		VectorColumns: []string{"multi_field"},
+	})
+	suite.Require().NoError(sc.Validate())
+	space, err := storage.Open("file://"+suite.T().TempDir(), *option.NewOptions(sc, -1))
+	suite.Require().NoError(err)
+
+	// odd rows are not embedded yet, even rows have pk+1 values in multi_field
+	b := array.NewRecordBuilder(memory.DefaultAllocator, as)
+	defer b.Release()
+	multi := b.Field(3).(*array.ListBuilder)
+	for i := 0; i < 4; i++ {
+		b.Field(0).(*array.Int64Builder).Append(int64(i))
+		b.Field(1).(*array.Int64Builder).Append(1)
+		if i%2 == 1 {
+			b.Field(2).(*array.FixedSizeBinaryBuilder).AppendNull()
+			multi.AppendNull()
+			continue
+		}
+		b.Field(2).(*array.FixedSizeBinaryBuilder).Append([]byte{byte(i), 0, 0, 0})
+		multi.Append(true)
+		multi.ValueBuilder().(*array.Float32Builder).AppendValues(make([]float32, i+1), nil)
+	}
+	rec := b.NewRecord()
+	defer rec.Release()
+	reader, err := array.NewRecordReader(as, []arrow.Record{rec})
+	suite.Require().NoError(err)
+	suite.Require().NoError(commitErr(space.Write(reader, option.NewWriteOption())))
+
+	read := func(f filter.Filter) (pks []int64, lengths []int) {
+		opt := option.NewReadOptions()
+		opt.AddColumn("pk_field")
+		opt.AddColumn("vec_field")
+		opt.AddColumn("multi_field")
+		if f != nil {
+			opt.AddFilter(f)
+		}
+		reader, err := space.Read(opt)
+		suite.Require().NoError(err)
+		defer reader.Release()
+		for reader.Next() {
+			rec := reader.Record()
+			vec := rec.Column(rec.Schema().FieldIndices("vec_field")[0])
+			multi := rec.Column(rec.Schema().FieldIndices("multi_field")[0]).(*array.List)
+			for i, pk := range rec.Column(rec.Schema().FieldIndices("pk_field")[0]).(*array.Int64).Int64Values() {
+				pks = append(pks, pk)
+				suite.Equal(pk%2 == 1, vec.IsNull(i))
+				suite.Equal(pk%2 == 1, multi.IsNull(i))
+				start, end := multi.ValueOffsets(i)
+				lengths = append(lengths, int(end-start))
+			}
+		}
+		suite.Require().NoError(reader.Err())
+		return pks, lengths
+	}
+	pks, lengths := read(nil)
+	suite.Equal([]int64{0, 1, 2, 3}, pks)
+	suite.Equal([]int{1, 0, 3, 0}, lengths)
+	pks, _ = read(filter.NewIsNullFilter("vec_field"))
+	suite.Equal([]int64{1, 3}, pks)
+	pks, lengths = read(filter.NewIsNotNullFilter("multi_field"))
+	suite.Equal([]int64{0, 2}, pks)
+	suite.Equal([]int{1, 3}, lengths)
+
+	// values of a vector are still checked
+	b.Field(0).(*array.Int64Builder).Append(4)
+	b.Field(1).(*array.Int64Builder).Append(1)
+	b.Field(2).(*array.FixedSizeBinaryBuilder).AppendNull()
+	multi.Append(true)
+	multi.ValueBuilder().(*array.Float32Builder).AppendNull()
+	invalid := b.NewRecord()
+	defer invalid.Release()
+	reader, err = array.NewRecordReader(as, []arrow.Record{invalid})
+	suite.Require().NoError(err)
+	err = commitErr(space.Write(reader, option.NewWriteOption()))
+	var vectorErr *storage.VectorError
+	suite.Require().ErrorAs(err, &vectorErr)
+	suite.Equal("multi_field", vectorErr.Column)
+}
+
+func (suite *SpaceTestSuite) TestPairedFilesAligned() {
+	as := arrow.NewSchema([]arrow.Field{
+		{Name: "pk_field", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "vs_field", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "age", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "vec_field", Type: &arrow.FixedSizeBinaryType{ByteWidth: 2}},
+	}, nil)
+	sc := schema.NewSchema(as, &schema_option.SchemaOptions{
+		PrimaryColumn: "pk_field",
+		VersionColumn: "vs_field",
+		VectorColumn:  "vec_field",
+	})
+	suite.Require().NoError(sc.Validate())
+	dir := suite.T().TempDir()
+	space, err := storage.Open("file://"+dir, *option.NewOptions(sc, -1))
+	suite.Require().NoError(err)
+
+	// files roll after the batch reaching MaxRecordPerFile, on both sides at once
+	var recs []arrow.Record
+	var pk int64
+	for _, n := range []int{2, 5, 1, 3} {
+		b := array.NewRecordBuilder(memory.DefaultAllocator, as)
+		for i := 0; i < n; i++ {
+			b.Field(0).(*array.Int64Builder).Append(pk)
+			b.Field(1).(*array.Int64Builder).Append(1)
+			b.Field(2).(*array.Int64Builder).Append(pk * 10)
+			b.Field(3).(*array.FixedSizeBinaryBuilder).Append([]byte{byte(pk), 0})
+			pk++
+		}
+		recs = append(recs, b.NewRecord())
+		b.Release()
+	}
+	reader, err := array.NewRecordReader(as, recs)
+	suite.Require().NoError(err)
+	suite.Require().NoError(commitErr(space.Write(reader, &option.WriteOptions{MaxRecordPerFile: 3})))
+
+	f, err := fs.BuildFileSystem("file://" + dir)
+	suite.Require().NoError(err)
+	m, err := manifest.ParseFromFile(f, utils.GetManifestFilePath(dir, space.GetCurrentVersion()))
+	suite.Require().NoError(err)
+	fragments, err := m.GetDataFragments()
+	suite.Require().NoError(err)
+	suite.Require().Len(fragments, 1)
+	var rows []int64
+	for i := range fragments[0].Scalar.Files() {
+		suite.Equal(fragments[0].Scalar.FileStats()[i].Rows, fragments[0].Vector.FileStats()[i].Rows)
+		rows = append(rows, fragments[0].Scalar.FileStats()[i].Rows)
+	}
+	suite.Equal([]int64{7, 4}, rows)
+
+	readOpt := option.NewReadOptions()
+	readOpt.AddColumn("age")
+	readOpt.AddColumn("vec_field")
+	read, err := space.Read(readOpt)
+	suite.Require().NoError(err)
+	defer read.Release()
+	var n int
+	for read.Next() {
+		rec := read.Record()
+		ages := rec.Column(rec.Schema().FieldIndices("age")[0]).(*array.Int64)
+		vectors := rec.Column(rec.Schema().FieldIndices("vec_field")[0]).(*array.FixedSizeBinary)
+		for i := 0; i < int(rec.NumRows()); i++ {
+			suite.Equal(int64(vectors.Value(i)[0])*10, ages.Value(i))
+			n++
+		}
+	}
+	suite.Require().NoError(read.Err())
+	suite.Equal(11, n)
+}
+
+func (suite *SpaceTestSuite) TestBackfillColumn() {
+	dir := suite.T().TempDir()
+	sc := createSchema()
+	opts := option.NewOptions(sc, -1)
+	var interleave func() error
+	opts.WriteHooks = []option.WriteHook{func(rec arrow.Record) (arrow.Record, error) {
+		if interleave != nil {
+			if err := interleave(); err != nil {
+				return nil, err
+			}
+		}
+		rec.Retain()
+		return rec, nil
+	}}
+	space, err := storage.Open("file://"+dir, *opts)
+	suite.Require().NoError(err)
+	suite.Require().NoError(commitErr(space.Write(createRecordReader(sc, []int64{1, 2, 3}), &option.WriteOptions{MaxRecordPerFile: 2})))
+	suite.Require().NoError(commitErr(space.Write(createRecordReader(sc, []int64{4, 5}), option.NewWriteOption())))
+	f, err := fs.BuildFileSystem("file://" + dir)
+	suite.Require().NoError(err)
+	before, err := manifest.ParseFromFile(f, utils.GetManifestFilePath(dir, space.GetCurrentVersion()))
+	suite.Require().NoError(err)
+
+	srcSchema := arrow.NewSchema([]arrow.Field{
+		{Name: "pk_field", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "label", Type: arrow.BinaryTypes.String},
+	}, nil)
+	source := func(pks []int64, labels []string) array.RecordReader {
+		b := array.NewRecordBuilder(memory.DefaultAllocator, srcSchema)
+		defer b.Release()
+		b.Field(0).(*array.Int64Builder).AppendValues(pks, nil)
+		b.Field(1).(*array.StringBuilder).AppendValues(labels, nil)
+		reader, err := array.NewRecordReader(srcSchema, []arrow.Record{b.NewRecord()})
+		suite.Require().NoError(err)
+		return reader
+	}
+	suite.ErrorIs(space.BackfillColumn("label", source([]int64{1, 1}, []string{"a", "b"})), storage.ErrDuplicateKey)
+	suite.ErrorIs(space.BackfillColumn("vs_field", source(nil, nil)), storage.ErrColumnExists)
+	suite.Require().NoError(space.BackfillColumn("label", source([]int64{1, 3, 4, 9}, []string{"a", "c", "d", "x"})))
+
+	// rows without a value are null, vector files are kept
+	after, err := manifest.ParseFromFile(f, utils.GetManifestFilePath(dir, space.GetCurrentVersion()))
+	suite.Require().NoError(err)
+	suite.Equal(before.GetVectorFragments(), after.GetVectorFragments())
+	suite.NotEqual(before.GetScalarFragments()[0].Files(), after.GetScalarFragments()[0].Files())
+	readLabels := func(space *storage.Space) map[int64]any {
+		readOpt := option.NewReadOptions()
+		readOpt.AddColumn("pk_field")
+		readOpt.AddColumn("label")
+		readOpt.AddColumn("vec_field")
+		reader, err := space.Read(readOpt)
+		suite.Require().NoError(err)
+		defer reader.Release()
+		labels := make(map[int64]any)
+		for reader.Next() {
+			rec := reader.Record()
+			pks := rec.Column(rec.Schema().FieldIndices("pk_field")[0]).(*array.Int64)
+			column := rec.Column(rec.Schema().FieldIndices("label")[0]).(*array.String)
+			vectors := rec.Column(rec.Schema().FieldIndices("vec_field")[0]).(*array.FixedSizeBinary)
+			for i := 0; i < int(rec.NumRows()); i++ {
+				suite.Equal(byte(pks.Value(i)), vectors.Value(i)[0])
+				labels[pks.Value(i)] = nil
+				if column.IsValid(i) {
+					labels[pks.Value(i)] = column.Value(i)
+				}
+			}
+		}
+		suite.Require().NoError(reader.Err())
+		return labels
+	}
+	expected := map[int64]any{1: "a", 2: nil, 3: "c", 4: "d", 5: nil}
+	suite.Equal(expected, readLabels(space))
+	reopened, err := storage.Open("file://"+dir, *option.NewOptions(nil, -1))
+	suite.Require().NoError(err)
+	suite.Equal(expected, readLabels(reopened))
+
+	// later writes include the column, writes started before a backfill fail
+	suite.ErrorIs(commitErr(space.Write(createRecordReader(sc, []int64{6}), option.NewWriteOption())), storage.ErrSchemaNotMatch)
+	as := arrow.NewSchema(append(sc.Schema().Fields(), arrow.Field{Name: "label", Type: arrow.BinaryTypes.String, Nullable: true}), nil)
+	write := func(pk int64) error {
+		b := array.NewRecordBuilder(memory.DefaultAllocator, as)
+		defer b.Release()
+		b.Field(0).(*array.Int64Builder).Append(pk)
+		b.Field(1).(*array.Int64Builder).Append(pk)
+		b.Field(2).(*array.FixedSizeBinaryBuilder).Append([]byte{byte(pk), 2, 3, 4, 5, 6, 7, 8, 9, 10})
+		b.Field(3).(*array.StringBuilder).Append("f")
+		reader, err := array.NewRecordReader(as, []arrow.Record{b.NewRecord()})
+		suite.Require().NoError(err)
+		return commitErr(space.Write(reader, option.NewWriteOption()))
+	}
+	suite.NoError(write(6))
+	interleave = func() error {
+		interleave = nil
+		scores := arrow.NewSchema([]arrow.Field{srcSchema.Field(0), {Name: "score", Type: arrow.PrimitiveTypes.Float64}}, nil)
+		reader, err := array.NewRecordReader(scores, nil)
+		suite.Require().NoError(err)
+		return space.BackfillColumn("score", reader)
+	}
+	suite.ErrorIs(write(7), storage.ErrManifestConflict)
+}
+
+func (suite *SpaceTestSuite) TestDropColumn() {
+	as := arrow.NewSchema([]arrow.Field{
+		{Name: "pk_field", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "vs_field", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "age", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "vec_field", Type: &arrow.FixedSizeBinaryType{ByteWidth: 2}},
+	}, nil)
+	sc := schema.NewSchema(as, &schema_option.SchemaOptions{
+		PrimaryColumn:   "pk_field",
+		VersionColumn:   "vs_field",
+		VectorColumn:    "vec_field",
+		StorageProfiles: map[string]schema_option.StorageProfile{"age": {Codec: schema_option.CodecZstd}},
+	})
+	suite.Require().NoError(sc.Validate())
+	dir := suite.T().TempDir()
+	space, err := storage.Open("file://"+dir, *option.NewOptions(sc, -1))
+	suite.Require().NoError(err)
+	write := func(as *arrow.Schema, pks ...int64) error {
+		b := array.NewRecordBuilder(memory.DefaultAllocator, as)
+		defer b.Release()
+		for _, pk := range pks {
+			b.Field(0).(*array.Int64Builder).Append(pk)
+			b.Field(1).(*array.Int64Builder).Append(1)
+			if len(as.Fields()) == 4 {
+				b.Field(2).(*array.Int64Builder).Append(pk * 10)
+			}
+			b.Field(len(as.Fields()) - 1).(*array.FixedSizeBinaryBuilder).Append([]byte{byte(pk), 0})
+		}
+		reader, err := array.NewRecordReader(as, []arrow.Record{b.NewRecord()})
+		suite.Require().NoError(err)
+		return commitErr(space.Write(reader, &option.WriteOptions{MaxRecordPerFile: 2}))
+	}
+	suite.Require().NoError(write(as, 1, 2, 3))
+	_, err = space.Analyze("age")
+	suite.Require().NoError(err)
+
+	suite.ErrorIs(versionErr(space.DropColumn("pk_field")), storage.ErrRequiredColumn)
+	suite.ErrorIs(versionErr(space.DropColumn("missing")), storage.ErrColumnNotExist)
+	committed, err := space.DropColumn("age")
+	suite.Require().NoError(err)
+	suite.Equal(space.GetCurrentVersion(), committed)
+	_, ok, err := space.ColumnStats("age")
+	suite.NoError(err)
+	suite.False(ok)
+	readOpt := option.NewReadOptions()
+	readOpt.AddColumn("age")
+	_, err = space.Read(readOpt)
+	suite.ErrorIs(err, storage.ErrColumnNotExist)
+	suite.ErrorIs(write(as, 4), storage.ErrSchemaNotMatch)
+	dropped := arrow.NewSchema([]arrow.Field{as.Field(0), as.Field(1), as.Field(3)}, nil)
+	suite.Require().NoError(write(dropped, 4))
+
+	// the files keep the column until compaction rewrites them
+	f, err := fs.BuildFileSystem("file://" + dir)
+	suite.Require().NoError(err)
+	parse := func() *manifest.Manifest {
+		m, err := manifest.ParseFromFile(f, utils.GetManifestFilePath(dir, space.GetCurrentVersion()))
+		suite.Require().NoError(err)
+		return m
+	}
+	before := parse()
+	suite.Nil(before.GetSchema().Options().StorageProfiles)
+	suite.Require().NoError(versionErr(space.Compact(&option.CompactOptions{PurgeDroppedColumns: true})))
+	after := parse()
+	suite.Equal(before.GetVectorFragments(), after.GetVectorFragments())
+	suite.NotEqual(before.GetScalarFragments()[0].Files(), after.GetScalarFragments()[0].Files())
+	suite.Equal(before.GetScalarFragments()[1].Files(), after.GetScalarFragments()[1].Files())
+	columns := func(path string) []string {
+		reader, err := parquet.NewFileReader(f, path, option.NewReadOptions())
+		suite.Require().NoError(err)
+		defer reader.Close()
+		return reader.Columns()
+	}
+	suite.Contains(columns(before.GetScalarFragments()[0].Files()[0]), "age")
+	suite.NotContains(columns(after.GetScalarFragments()[0].Files()[0]), "age")
+	pks, err := readPks(space)
+	suite.Require().NoError(err)
+	suite.ElementsMatch([]int64{1, 2, 3, 4}, pks)
+	version := space.GetCurrentVersion()
+	suite.Require().NoError(versionErr(space.Compact(&option.CompactOptions{PurgeDroppedColumns: true})))
+	suite.Equal(version, space.GetCurrentVersion())
+}
+
+func (suite *SpaceTestSuite) TestMigrateCold() {
+	sc := createSchema()
+	suite.Require().NoError(sc.Validate())
+	dir := suite.T().TempDir()
+	space, err := storage.Open("file://"+dir, *option.NewOptions(sc, -1))
+	suite.Require().NoError(err)
+	for i := 0; i < 2; i++ {
+		_, err = space.Write(createRecordReader(sc, []int64{int64(2 * i), int64(2*i + 1)}), &option.WriteOptions{MaxRecordPerFile: 1})
+		suite.Require().NoError(err)
+	}
+	f, err := fs.BuildFileSystem("file://" + dir)
+	suite.Require().NoError(err)
+	parse := func() *manifest.Manifest {
+		m, err := manifest.ParseFromFile(f, utils.GetManifestFilePath(dir, space.GetCurrentVersion()))
+		suite.Require().NoError(err)
+		return m
+	}
+	hot := parse()
+	old := time.Now().Add(-2 * time.Hour)
+	for _, fragments := range []fragment.FragmentVector{hot.GetScalarFragments()[:1], hot.GetVectorFragments()[:1]} {
+		for _, path := range fragment.ToFilesVector(fragments) {
+			suite.Require().NoError(os.Chtimes(path, old, old))
+		}
+	}
+	pks, err := readPks(space)
+	suite.Require().NoError(err)
+
+	moved, err := space.MigrateCold(&option.TierOptions{OlderThan: time.Hour})
+	suite.Require().NoError(err)
+	suite.Equal(1, moved)
+	cold := parse()
+	coldDir := utils.GetColdDataDir(dir)
+	for _, path := range cold.GetScalarFragments()[0].Files() {
+		suite.Equal(utils.GetScalarDataDir(coldDir), filepath.Dir(path))
+	}
+	for _, path := range cold.GetVectorFragments()[0].Files() {
+		suite.Equal(utils.GetVectorDataDir(coldDir), filepath.Dir(path))
+	}
+	suite.Equal(hot.GetScalarFragments()[1], cold.GetScalarFragments()[1])
+	suite.Equal(hot.GetScalarFragments()[0].FileStats(), cold.GetScalarFragments()[0].FileStats())
+	suite.Equal(hot.GetUsage(), cold.GetUsage())
+	migrated, err := readPks(space)
+	suite.Require().NoError(err)
+	suite.ElementsMatch(pks, migrated)
+	moved, err = space.MigrateCold(&option.TierOptions{OlderThan: time.Hour})
+	suite.Require().NoError(err)
+	suite.Zero(moved)
+
+	moved, err = space.WarmUp(hot.GetScalarFragments()[1].FragmentId())
+	suite.Require().NoError(err)
+	suite.Zero(moved)
+	moved, err = space.WarmUp()
+	suite.Require().NoError(err)
+	suite.Equal(1, moved)
+	suite.Equal(hot.GetScalarFragments(), parse().GetScalarFragments())
+	suite.Equal(hot.GetVectorFragments(), parse().GetVectorFragments())
+	warm, err := readPks(space)
+	suite.Require().NoError(err)
+	suite.ElementsMatch(pks, warm)
+}
+
+func (suite *SpaceTestSuite) TestPinFragments() {
+	sc := createSchema()
+	suite.Require().NoError(sc.Validate())
+	dir := suite.T().TempDir()
+	space, err := storage.Open("file://"+dir, *option.NewOptions(sc, -1))
+	suite.Require().NoError(err)
+	for _, pks := range [][]int64{{1, 2}, {3}, {4, 5, 6}} {
+		suite.Require().NoError(commitErr(space.Write(createRecordReader(sc, pks), option.NewWriteOption())))
+	}
+	f, err := fs.BuildFileSystem("file://" + dir)
+	suite.Require().NoError(err)
+	parse := func() *manifest.Manifest {
+		m, err := manifest.ParseFromFile(f, utils.GetManifestFilePath(dir, space.GetCurrentVersion()))
+		suite.Require().NoError(err)
+		return m
+	}
+
+	suite.ErrorIs(space.PinFragments(42), storage.ErrFragmentNotExist)
+	suite.Require().NoError(versionErr(space.PinVersion(1)))
+	suite.Equal([]int64{1}, space.PinnedFragments())
+	suite.True(parse().IsPinned(1))
+	pinned := parse().GetScalarFragments()[0]
+	suite.Require().NoError(commitErr(space.DeleteKeys([]int64{2, 4}, 4)))
+
+	// the pinned fragment is neither merged nor purged, so the deletes still apply to it
+	suite.Require().NoError(versionErr(space.Compact(&option.CompactOptions{PurgeDeletes: true, Policy: option.NewBinPackingPolicy()})))
+	compacted := parse()
+	suite.Equal(pinned, compacted.GetScalarFragments()[0])
+	suite.Len(compacted.GetScalarFragments(), 2)
+	suite.Len(compacted.GetDeleteFragments(), 1)
+	pks, err := readPks(space)
+	suite.Require().NoError(err)
+	suite.ElementsMatch([]int64{1, 3, 5, 6}, pks)
+
+	moved, err := space.MigrateCold(&option.TierOptions{})
+	suite.Require().NoError(err)
+	suite.Equal(1, moved)
+	suite.Equal(pinned, parse().GetScalarFragments()[0])
+	reader, err := array.NewRecordReader(arrow.NewSchema([]arrow.Field{
+		{Name: "pk_field", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "score", Type: arrow.PrimitiveTypes.Int64},
+	}, nil), nil)
+	suite.Require().NoError(err)
+	suite.ErrorIs(space.BackfillColumn("score", reader), storage.ErrFragmentPinned)
+
+	suite.Require().NoError(space.UnpinFragments(1, 42))
+	suite.Empty(space.PinnedFragments())
+	suite.Require().NoError(versionErr(space.Compact(&option.CompactOptions{PurgeDeletes: true})))
+	suite.Empty(parse().GetDeleteFragments())
+	pks, err = readPks(space)
+	suite.Require().NoError(err)
+	suite.ElementsMatch([]int64{1, 3, 5, 6}, pks)
+}
+
+func (suite *SpaceTestSuite) TestRegisterIndex() {
+	sc := createSchema()
+	suite.Require().NoError(sc.Validate())
+	dir := suite.T().TempDir()
+	space, err := storage.Open("file://"+dir, *option.NewOptions(sc, -1))
+	suite.Require().NoError(err)
+	for _, pks := range [][]int64{{1, 2}, {3}, {4}} {
+		suite.Require().NoError(commitErr(space.Write(createRecordReader(sc, pks), option.NewWriteOption())))
+	}
+	suite.Require().NoError(commitErr(space.WriteBlob([]byte("index"), "idx", false)))
+	suite.Require().NoError(commitErr(space.WriteBlob([]byte("all"), "all", false)))
+
+	suite.ErrorIs(space.RegisterIndex("missing", 3), storage.ErrBlobNotExist)
+	suite.ErrorIs(space.RegisterIndex("idx", 3, 42), storage.ErrFragmentNotExist)
+	suite.Require().NoError(space.RegisterIndex("idx", 3, 1))
+	suite.Require().NoError(space.RegisterIndex("all", 2))
+	suite.Require().NoError(space.PinFragments(3))
+	indexes, err := space.IndexesFor(1)
+	suite.Require().NoError(err)
+	suite.ElementsMatch([]storage.Index{
+		{Name: "idx", Version: 3, Fragments: []int64{1}},
+		{Name: "all", Version: 2, Fragments: []int64{1, 2}},
+	}, indexes)
+	indexes, err = space.IndexesFor(3)
+	suite.Require().NoError(err)
+	suite.Empty(indexes)
+
+	// merging the unpinned fragments rewrites the sources of both indexes
+	suite.Require().NoError(versionErr(space.Compact(&option.CompactOptions{Policy: option.NewBinPackingPolicy()})))
+	reopened, err := storage.Open("file://"+dir, *option.NewOptions(nil, -1))
+	suite.Require().NoError(err)
+	indexes, err = reopened.IndexesFor(2)
+	suite.Require().NoError(err)
+	suite.Equal([]storage.Index{{Name: "all", Version: 2, Fragments: []int64{1, 2}, Stale: true}}, indexes)
+	indexes, err = reopened.IndexesFor(1)
+	suite.Require().NoError(err)
+	suite.Len(indexes, 2)
+	for _, index := range indexes {
+		suite.True(index.Stale)
+	}
+	suite.ErrorIs(space.RegisterIndex("idx", 3, 1), storage.ErrFragmentNotExist)
+}
+
+func (suite *SpaceTestSuite) TestRefresh() {
+	sc := createSchema()
+	suite.Require().NoError(sc.Validate())
+	dir := suite.T().TempDir()
+	blobCache := storage.NewLRUBlobCache(1 << 20)
+	resultCache := storage.NewLRUResultCache(1 << 20)
+	var changes []option.VersionChange
+	ops := option.NewOptions(sc, -1)
+	ops.BlobCache = blobCache
+	ops.ResultCache = resultCache
+	ops.OnVersionChange = func(change option.VersionChange) {
+		changes = append(changes, change)
+	}
+	reader, err := storage.Open("file://"+dir, *ops)
+	suite.Require().NoError(err)
+	writer, err := storage.Open("file://"+dir, *option.NewOptions(sc, -1))
+	suite.Require().NoError(err)
+
+	change, err := reader.Refresh()
+	suite.Require().NoError(err)
+	suite.Equal(option.VersionChange{}, change)
+	suite.Require().NoError(commitErr(writer.Write(createRecordReader(sc, []int64{1, 2}), option.NewWriteOption())))
+	suite.Require().NoError(commitErr(writer.WriteBlob([]byte("blob"), "blob", false)))
+	change, err = reader.Refresh()
+	suite.Require().NoError(err)
+	suite.Equal(option.VersionChange{From: 0, To: 2, AddedFragments: []int64{1}}, change)
+	suite.Equal([]option.VersionChange{change}, changes)
+	pks, err := readPks(reader)
+	suite.Require().NoError(err)
+	suite.ElementsMatch([]int64{1, 2}, pks)
+	_, err = reader.ReadBlob("blob", make([]byte, 4))
+	suite.Require().NoError(err)
+	suite.NotZero(blobCache.Size())
+	suite.NotZero(resultCache.Size())
+
+	// the cached results of version 2 and the replaced blob are dropped
+	suite.Require().NoError(commitErr(writer.DeleteKeys([]int64{1}, 1)))
+	suite.Require().NoError(versionErr(writer.Compact(&option.CompactOptions{PurgeDeletes: true})))
+	suite.Require().NoError(commitErr(writer.WriteBlob([]byte("replaced"), "blob", true)))
+	change, err = reader.Refresh()
+	suite.Require().NoError(err)
+	suite.Equal(option.VersionChange{From: 2, To: 5, AddedFragments: []int64{1}, RemovedFragments: []int64{1}, RemovedBlobs: []string{"blob"}}, change)
+	suite.Len(changes, 2)
+	suite.Zero(blobCache.Size())
+	suite.Zero(resultCache.Size())
+	pks, err = readPks(reader)
+	suite.Require().NoError(err)
+	suite.Equal([]int64{2}, pks)
+	suite.Require().NoError(commitErr(reader.Write(createRecordReader(sc, []int64{3}), option.NewWriteOption())))
+	suite.Equal(int64(6), reader.GetCurrentVersion())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	suite.ErrorIs(writer.Watch(ctx, time.Millisecond), context.DeadlineExceeded)
+	suite.Equal(int64(6), writer.GetCurrentVersion())
+}
+
+func (suite *SpaceTestSuite) TestConsistencyToken() {
+	sc := createSchema()
+	suite.Require().NoError(sc.Validate())
+	dir := suite.T().TempDir()
+	writer, err := storage.Open("file://"+dir, *option.NewOptions(sc, -1))
+	suite.Require().NoError(err)
+	reader, err := storage.Open("file://"+dir, *option.NewOptions(sc, -1))
+	suite.Require().NoError(err)
+
+	suite.Require().NoError(commitErr(writer.Write(createRecordReader(sc, []int64{1, 2}), option.NewWriteOption())))
+	token, err := writer.ConsistencyToken()
+	suite.Require().NoError(err)
+	suite.Equal(int64(1), token.Version)
+	suite.NotEmpty(token.ETag)
+
+	readOption := option.NewReadOptions()
+	readOption.AtLeast = &token
+	_, err = reader.Read(readOption)
+	suite.ErrorIs(err, storage.ErrStaleReplica)
+	suite.ErrorIs(err, errors.ErrUnavailable)
+
+	_, err = reader.Refresh()
+	suite.Require().NoError(err)
+	rec, err := reader.Read(readOption)
+	suite.Require().NoError(err)
+	var pks []int64
+	for rec.Next() {
+		pks = append(pks, rec.Record().Column(0).(*array.Int64).Int64Values()...)
+	}
+	suite.Require().NoError(rec.Err())
+	rec.Release()
+	suite.ElementsMatch([]int64{1, 2}, pks)
+
+	// a token of another manifest with the same version
+	other := token
+	other.ETag = "other"
+	readOption.AtLeast = &other
+	_, err = reader.Read(readOption)
+	suite.ErrorIs(err, storage.ErrStaleReplica)
+}
+
+func (suite *SpaceTestSuite) TestWritePartitioned() {
+	sc := createSchema()
+	suite.Require().NoError(sc.Validate())
+	dir := suite.T().TempDir()
+	space, err := storage.Open("file://"+dir, *option.NewOptions(sc, -1))
+	suite.Require().NoError(err)
+
+	result, err := space.WritePartitioned(map[storage.PartitionKey]array.RecordReader{
+		"b": createRecordReader(sc, []int64{3}),
+		"a": createRecordReader(sc, []int64{1, 2}),
+		"c": createRecordReader(sc, nil),
+	}, option.NewWriteOption())
+	suite.Require().NoError(err)
+	suite.Equal(int64(1), result.Version)
+	suite.Equal([]int64{1, 2}, result.FragmentIDs)
+	suite.Equal(int64(3), result.Rows)
+
+	// later writes take ids after those of the partitions
+	result, err = space.Write(createRecordReader(sc, []int64{4}), option.NewWriteOption())
+	suite.Require().NoError(err)
+	suite.Equal(int64(2), result.Version)
+	suite.Equal([]int64{3}, result.FragmentIDs)
+
+	reopened, err := storage.Open("file://"+dir, *option.NewOptions(nil, -1))
+	suite.Require().NoError(err)
+	tasks, err := reopened.PlanScan(option.NewReadOptions(), 1)
+	suite.Require().NoError(err)
+	var ids []int64
+	for _, task := range tasks {
+		for _, f := range task.Fragments {
+			ids = append(ids, f.Scalar.ID)
+		}
+	}
+	suite.Equal([]int64{1, 2, 3}, ids)
+	pks, err := readPks(reopened)
+	suite.Require().NoError(err)
+	suite.ElementsMatch([]int64{1, 2, 3, 4}, pks)
+}
+
+func (suite *SpaceTestSuite) TestCommitQueue() {
+	sc := createSchema()
+	suite.Require().NoError(sc.Validate())
+	ops := option.NewOptions(sc, -1)
+	ops.CommitQueue = &option.CommitQueueOptions{MaxWrites: 4}
+	space, err := storage.Open("file://"+suite.T().TempDir(), *ops)
+	suite.Require().NoError(err)
+
+	const writers = 16
+	results := make([]storage.CommitResult, writers)
+	errs := make([]error, writers)
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = space.Write(createRecordReader(sc, []int64{int64(i)}), option.NewWriteOption())
+		}(i)
+	}
+	wg.Wait()
+
+	fragments, writes := make(map[int64]bool), make(map[int64]int)
+	for i := range results {
+		suite.Require().NoError(errs[i])
+		suite.Len(results[i].FragmentIDs, 1)
+		fragments[results[i].FragmentIDs[0]] = true
+		writes[results[i].Version]++
+	}
+	suite.Len(fragments, writers)
+	suite.Equal(int64(len(writes)), space.GetCurrentVersion())
+	for _, n := range writes {
+		suite.LessOrEqual(n, 4)
+	}
+	pks, err := readPks(space)
+	suite.Require().NoError(err)
+	suite.Len(pks, writers)
+}
+
+func (suite *SpaceTestSuite) TestCommitQueueDelay() {
+	sc := createSchema()
+	suite.Require().NoError(sc.Validate())
+	ops := option.NewOptions(sc, -1)
+	// the commit waits for all the writers
+	ops.CommitQueue = &option.CommitQueueOptions{MaxWrites: 8, MaxDelay: time.Minute}
+	space, err := storage.Open("file://"+suite.T().TempDir(), *ops)
+	suite.Require().NoError(err)
+
+	const writers = 8
+	errs := make([]error, writers)
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = commitErr(space.Write(createRecordReader(sc, []int64{int64(i)}), option.NewWriteOption()))
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		suite.Require().NoError(err)
+	}
+	suite.Equal(int64(1), space.GetCurrentVersion())
+
+	// a write alone is committed once the delay expires
+	ops.CommitQueue = &option.CommitQueueOptions{MaxDelay: 50 * time.Millisecond}
+	space, err = storage.Open("file://"+suite.T().TempDir(), *ops)
+	suite.Require().NoError(err)
+	start := time.Now()
+	suite.Require().NoError(commitErr(space.Write(createRecordReader(sc, []int64{1}), option.NewWriteOption())))
+	suite.GreaterOrEqual(time.Since(start), 50*time.Millisecond)
+	suite.Equal(int64(1), space.GetCurrentVersion())
+}
+
+func (suite *SpaceTestSuite) TestRetryOptions() {
+	sc := createSchema()
+	suite.Require().NoError(sc.Validate())
+	space, err := storage.Open("file://"+suite.T().TempDir(), *option.NewOptions(sc, -1))
+	suite.Require().NoError(err)
+
+	writeOption := option.NewWriteOption()
+	writeOption.Retry = &option.RetryOptions{Timeout: time.Nanosecond}
+	_, err = space.Write(createRecordReader(sc, []int64{1}), writeOption)
+	suite.ErrorIs(err, context.DeadlineExceeded)
+	suite.Equal(int64(0), space.GetCurrentVersion())
+
+	writeOption.Retry = &option.RetryOptions{Timeout: time.Minute, MaxAttempts: 3, Backoff: time.Millisecond}
+	suite.Require().NoError(commitErr(space.Write(createRecordReader(sc, []int64{1, 2}), writeOption)))
+	suite.Require().NoError(commitErr(space.DeleteKeys([]int64{1}, 1)))
+	suite.Require().NoError(versionErr(space.Compact(&option.CompactOptions{PurgeDeletes: true, Retry: writeOption.Retry})))
+
+	readOption := option.NewReadOptions()
+	readOption.Retry = &option.RetryOptions{Timeout: time.Nanosecond}
+	err = func() error {
+		reader, err := space.Read(readOption)
+		if err != nil {
+			return err
+		}
+		defer reader.Release()
+		for reader.Next() {
+		}
+		return reader.Err()
+	}()
+	suite.ErrorIs(err, context.DeadlineExceeded)
+	pks, err := readPks(space)
+	suite.Require().NoError(err)
+	suite.Equal([]int64{2}, pks)
+}
+
+func (suite *SpaceTestSuite) TestFileNaming() {
+	sc := createSchema()
+	suite.Require().NoError(sc.Validate())
+	ops := option.NewOptions(sc, -1)
+	ops.Naming = &option.NamingOptions{Strategy: option.NamingTimeSorted, Prefix: "writer-1-"}
+	space, err := storage.Open("file://"+suite.T().TempDir(), *ops)
+	suite.Require().NoError(err)
+
+	start := time.Now().Truncate(time.Millisecond)
+	first, err := space.Write(createRecordReader(sc, []int64{1}), option.NewWriteOption())
+	suite.Require().NoError(err)
+	time.Sleep(2 * time.Millisecond)
+	second, err := space.Write(createRecordReader(sc, []int64{2}), option.NewWriteOption())
+	suite.Require().NoError(err)
+	for _, file := range append(first.Files, second.Files...) {
+		suite.True(strings.HasPrefix(filepath.Base(file), "writer-1-"), file)
+		written, ok := utils.TimeOfFile(file)
+		suite.True(ok)
+		suite.False(written.Before(start))
+		suite.False(written.After(time.Now()))
+	}
+	// the names sort by the time the files were written
+	suite.Less(filepath.Base(first.Files[0]), filepath.Base(second.Files[0]))
+
+	_, ok := utils.TimeOfFile(utils.GetNewParquetFilePath("scalar"))
+	suite.False(ok)
+}
+
+func (suite *SpaceTestSuite) TestPathLayout() {
+	sc := createSchema()
+	suite.Require().NoError(sc.Validate())
+	dir := suite.T().TempDir()
+	ops := option.NewOptions(sc, -1)
+	ops.Layout = layout.SchemeHash
+	space, err := storage.Open("file://"+dir, *ops)
+	suite.Require().NoError(err)
+	suite.Equal(layout.Layout{Version: 1, Scheme: layout.SchemeHash}, space.Layout())
+
+	hashed, err := space.Write(createRecordReader(sc, []int64{1}), option.NewWriteOption())
+	suite.Require().NoError(err)
+	suite.Require().NoError(commitErr(space.DeleteKeys([]int64{1}, 1)))
+	scalarDir := utils.GetScalarDataDir(dir)
+	suite.Len(filepath.Base(filepath.Dir(hashed.Files[0])), 2)
+	suite.Equal(scalarDir, filepath.Dir(filepath.Dir(hashed.Files[0])))
+
+	// an existing space keeps its layout, later files are placed with the new one
+	ops.Layout = layout.SchemeFlat
+	space, err = storage.Open("file://"+dir, *ops)
+	suite.Require().NoError(err)
+	suite.Equal(layout.SchemeHash, space.Layout().Scheme)
+	suite.Require().NoError(space.SetLayout(layout.SchemeDate))
+	suite.Equal(layout.Layout{Version: 2, Scheme: layout.SchemeDate}, space.Layout())
+	dated, err := space.Write(createRecordReader(sc, []int64{2, 3}), option.NewWriteOption())
+	suite.Require().NoError(err)
+	suite.Equal(filepath.Join(scalarDir, time.Now().UTC().Format("2006/01/02")), filepath.Dir(dated.Files[0]))
+	suite.ErrorIs(space.SetLayout(layout.Scheme(42)), layout.ErrUnknownScheme)
+
+	reopened, err := storage.Open("file://"+dir, *option.NewOptions(nil, -1))
+	suite.Require().NoError(err)
+	suite.Equal(layout.Layout{Version: 2, Scheme: layout.SchemeDate}, reopened.Layout())
+	pks, err := readPks(reopened)
+	suite.Require().NoError(err)
+	suite.ElementsMatch([]int64{2, 3}, pks)
+
+	// files of failed writes in the subdirectories are purged too
+	orphan := filepath.Join(filepath.Dir(hashed.Files[0]), "orphan.parquet")
+	suite.Require().NoError(os.WriteFile(orphan, []byte("orphan"), 0o644))
+	suite.Require().NoError(storage.SoftDrop("file://" + dir))
+	suite.Require().NoError(storage.Vacuum("file://"+dir, &option.VacuumOptions{DropGracePeriod: 0}))
+	_, err = os.Stat(dir)
+	suite.True(os.IsNotExist(err))
+}
+
+func (suite *SpaceTestSuite) TestRelativePaths() {
+	sc := createSchema()
+	suite.Require().NoError(sc.Validate())
+	src := filepath.Join(suite.T().TempDir(), "src")
+	space, err := storage.Open("file://"+src, *option.NewOptions(sc, -1))
+	suite.Require().NoError(err)
+	_, err = space.Write(createRecordReader(sc, []int64{1, 2, 3}), option.NewWriteOption())
+	suite.Require().NoError(err)
+	suite.Require().NoError(commitErr(space.DeleteKeys([]int64{2}, 2)))
+	_, err = space.WriteBlob([]byte("content"), "blob", false)
+	suite.Require().NoError(err)
+
+	// the copy resolves the files in its own directory once the source is gone
+	dst := filepath.Join(suite.T().TempDir(), "dst")
+	suite.Require().NoError(filepath.WalkDir(src, func(path string, d iofs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		suite.Require().NoError(os.MkdirAll(filepath.Dir(filepath.Join(dst, rel)), 0o755))
+		return os.WriteFile(filepath.Join(dst, rel), content, 0o644)
+	}))
+	suite.Require().NoError(space.Close())
+	suite.Require().NoError(os.RemoveAll(src))
+
+	copied, err := storage.Open("file://"+dst, *option.NewOptions(nil, -1))
+	suite.Require().NoError(err)
+	m, err := manifest.ParseFromFile(fs.NewLocalFs(), utils.GetManifestFilePath(dst, copied.GetCurrentVersion()))
+	suite.Require().NoError(err)
+	for _, f := range append(m.GetScalarFragments(), m.GetDeleteFragments()...) {
+		for _, file := range f.Files() {
+			suite.True(strings.HasPrefix(file, dst+"/"), file)
+		}
+	}
+	pks, err := readPks(copied)
+	suite.Require().NoError(err)
+	suite.ElementsMatch([]int64{1, 3}, pks)
+	output := make([]byte, 7)
+	n, err := copied.ReadBlob("blob", output)
+	suite.Require().NoError(err)
+	suite.Equal([]byte("content"), output[:n])
+}
+
+func (suite *SpaceTestSuite) TestConfineToRoot() {
+	sc := createSchema()
+	suite.Require().NoError(sc.Validate())
+	root := suite.T().TempDir()
+	dirA, dirB := filepath.Join(root, "tenant-a"), filepath.Join(root, "tenant-b")
+	ops := option.NewOptions(sc, -1)
+	ops.ConfineToRoot = true
+	a, err := storage.Open("file://"+dirA, *ops)
+	suite.Require().NoError(err)
+	_, err = a.Write(createRecordReader(sc, []int64{1}), option.NewWriteOption())
+	suite.Require().NoError(err)
+	b, err := storage.Open("file://"+dirB, *option.NewOptions(sc, -1))
+	suite.Require().NoError(err)
+	_, err = b.Write(createRecordReader(sc, []int64{2}), option.NewWriteOption())
+	suite.Require().NoError(err)
+
+	// a forged version of tenant a referencing the files of tenant b
+	f, err := fs.BuildFileSystem("file://" + root)
+	suite.Require().NoError(err)
+	forged, err := manifest.ParseFromFile(f, utils.GetManifestFilePath(dirA, a.GetCurrentVersion()))
+	suite.Require().NoError(err)
+	other, err := manifest.ParseFromFile(f, utils.GetManifestFilePath(dirB, b.GetCurrentVersion()))
+	suite.Require().NoError(err)
+	forged = forged.Copy()
+	forged.SetVersion(a.GetCurrentVersion() + 1)
+	forged.SetScalarFragments(other.GetScalarFragments())
+	forged.SetVectorFragments(other.GetVectorFragments())
+	file, err := f.OpenFile(utils.GetManifestFilePath(dirA, forged.Version()))
+	suite.Require().NoError(err)
+	suite.Require().NoError(manifest.WriteManifestFile(forged, file))
+	suite.Require().NoError(file.Close())
+
+	_, err = a.Refresh()
+	suite.ErrorIs(err, storage.ErrFileOutsideSpace)
+	pks, err := readPks(a)
+	suite.Require().NoError(err)
+	suite.Equal([]int64{1}, pks)
+	_, err = storage.Open("file://"+dirA, *ops)
+	suite.ErrorIs(err, storage.ErrFileOutsideSpace)
+	// spaces not confined read the files wherever they are
+	unconfined, err := storage.Open("file://"+dirA, *option.NewOptions(nil, -1))
+	suite.Require().NoError(err)
+	pks, err = readPks(unconfined)
+	suite.Require().NoError(err)
+	suite.Equal([]int64{2}, pks)
+}
+
+func (suite *SpaceTestSuite) TestLegacyManifestDir() {
+	sc := createSchema()
+	suite.Require().NoError(sc.Validate())
+	dir := suite.T().TempDir()
+	space, err := storage.Open("file://"+dir, *option.NewOptions(sc, -1))
+	suite.Require().NoError(err)
+	_, err = space.Write(createRecordReader(sc, []int64{1, 2}), option.NewWriteOption())
+	suite.Require().NoError(err)
+	suite.Require().NoError(space.Close())
+
+	// early releases saved the manifests in the manifest directory nested in itself
+	legacy := utils.GetLegacyManifestDir(dir)
+	suite.Require().NoError(os.MkdirAll(legacy, 0o755))
+	entries, err := os.ReadDir(utils.GetManifestDir(dir))
+	suite.Require().NoError(err)
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			suite.Require().NoError(os.Rename(filepath.Join(utils.GetManifestDir(dir), entry.Name()), filepath.Join(legacy, entry.Name())))
+		}
+	}
+	suite.Require().NoError(os.Remove(utils.GetLatestFilePath(dir)))
+
+	space, err = storage.Open("file://"+dir, *option.NewOptions(nil, -1))
+	suite.Require().NoError(err)
+	suite.Equal(int64(1), space.GetCurrentVersion())
+	pks, err := readPks(space)
+	suite.Require().NoError(err)
+	suite.ElementsMatch([]int64{1, 2}, pks)
+	old, err := storage.Open("file://"+dir, *option.NewOptions(nil, 0))
+	suite.Require().NoError(err)
+	suite.Equal(int64(0), old.GetCurrentVersion())
+}
+
+// dataFiles returns the data and delete files in the space at dir.
+func dataFiles(t *testing.T, dir string) []string {
+	var files []string
+	for _, data := range []string{utils.GetScalarDataDir(dir), utils.GetVectorDataDir(dir), utils.GetDeleteDataDir(dir)} {
+		err := filepath.WalkDir(data, func(path string, d iofs.DirEntry, err error) error {
+			if err == nil && !d.IsDir() {
+				files = append(files, path)
+			}
+			return err
+		})
+		if err != nil && !os.IsNotExist(err) {
+			t.Fatal(err)
+		}
+	}
+	return files
+}
+
+// failingReader fails with err once its records are read.
+type failingReader struct {
+	array.RecordReader
+	err error
+}
+
+func (r *failingReader) Err() error { return r.err }
+
+func (suite *SpaceTestSuite) TestWriteStreamError() {
+	sc := createSchema()
+	suite.Require().NoError(sc.Validate())
+	dir := suite.T().TempDir()
+	space, err := storage.Open("file://"+dir, *option.NewOptions(sc, -1))
+	suite.Require().NoError(err)
+
+	streamErr := errors.New("stream broken")
+	_, err = space.Write(&failingReader{RecordReader: createRecordReader(sc, []int64{1, 2}), err: streamErr}, option.NewWriteOption())
+	suite.ErrorIs(err, streamErr)
+	_, err = space.Delete(&failingReader{RecordReader: createDeleteReader(sc, []int64{1}, []int64{1}), err: streamErr})
+	suite.ErrorIs(err, streamErr)
+	suite.Equal(int64(0), space.GetCurrentVersion())
+	suite.Empty(dataFiles(suite.T(), dir))
+}
+
+func (suite *SpaceTestSuite) TestColumnStatsAccess() {
+	sc := createSchema()
+	suite.Require().NoError(sc.Validate())
+	options := option.NewOptions(sc, -1)
+	options.Masking = func(identity auth.Identity) map[string]option.MaskRule {
+		if identity.User == "admin" {
+			return nil
+		}
+		return map[string]option.MaskRule{"vs_field": {Type: option.MaskNull}}
+	}
+	options.Authorizer = auth.AuthorizerFunc(func(ctx context.Context, op auth.Operation, path string, identity auth.Identity) error {
+		if identity.User == "" {
+			return fmt.Errorf("anonymous not allowed")
+		}
+		return nil
+	})
+	space, err := storage.Open("file://"+suite.T().TempDir(), *options)
+	suite.Require().NoError(err)
+	admin := auth.WithIdentity(context.Background(), auth.Identity{User: "admin"})
+	guest := auth.WithIdentity(context.Background(), auth.Identity{User: "guest"})
+	suite.Require().NoError(commitErr(space.WriteContext(admin, createRecordReader(sc, []int64{1, 2}), option.NewWriteOption())))
+	_, err = space.AnalyzeContext(admin, option.NewAnalyzeOptions(), "pk_field", "vs_field")
+	suite.Require().NoError(err)
+
+	_, ok, err := space.ColumnStatsContext(guest, "pk_field")
+	suite.Require().NoError(err)
+	suite.True(ok)
+	_, _, err = space.ColumnStatsContext(guest, "vs_field")
+	suite.ErrorIs(err, errors.ErrPermissionDenied)
+	_, _, err = space.ColumnStats("pk_field")
+	suite.ErrorIs(err, errors.ErrPermissionDenied)
+	suite.Require().NoError(space.Close())
+	_, _, err = space.ColumnStatsContext(admin, "pk_field")
+	suite.ErrorIs(err, storage.ErrSpaceClosed)
+}
@@ -0,0 +1,151 @@
+package storage
+
+import (
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/google/uuid"
+	"github.com/milvus-io/milvus-storage/go/common/log"
+	"github.com/milvus-io/milvus-storage/go/io/format"
+	"github.com/milvus-io/milvus-storage/go/io/format/parquet"
+	"github.com/milvus-io/milvus-storage/go/io/fs"
+	"github.com/milvus-io/milvus-storage/go/storage/options/option"
+)
+
+// stagingProcess names the staging directories of the process, Open removes those of other
+// processes.
+var stagingProcess = uuid.New().String()
+
+// spaceStagingDir returns the directory holding the staged files of every process writing the
+// space at path.
+func spaceStagingDir(dir string, path string) string {
+	h := fnv.New64a()
+	h.Write([]byte(path))
+	return filepath.Join(dir, fmt.Sprintf("%016x", h.Sum64()))
+}
+
+// cleanStaging removes the files staged for the space at path by other processes, they
+// crashed before uploading them.
+func cleanStaging(dir string, path string) error {
+	spaceDir := spaceStagingDir(dir, path)
+	entries, err := os.ReadDir(spaceDir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("clean staging directory %s: %w", spaceDir, err)
+	}
+	for _, entry := range entries {
+		if entry.Name() == stagingProcess {
+			continue
+		}
+		log.Info("remove staged files of crashed write", log.String("path", filepath.Join(spaceDir, entry.Name())))
+		if err = os.RemoveAll(filepath.Join(spaceDir, entry.Name())); err != nil {
+			return fmt.Errorf("clean staging directory %s: %w", spaceDir, err)
+		}
+	}
+	return nil
+}
+
+// stagingDir returns the staging directory of a write with opt, empty if it is not staged.
+func (s *Space) stagingDir(opt *option.WriteOptions) string {
+	if opt.StagingDir != "" {
+		return opt.StagingDir
+	}
+	return s.staging
+}
+
+// spillOptions returns a copy of opt staging its files in the temporary directory of the
+// system, for batches over the memory budget of writes without a staging directory.
+func spillOptions(opt *option.WriteOptions) *option.WriteOptions {
+	spilled := *opt
+	spilled.StagingDir = os.TempDir()
+	return &spilled
+}
+
+// splitRecord returns rec in slices of about budget bytes, rec itself if it fits or budget is
+// zero. The slices must be released.
+func splitRecord(rec arrow.Record, budget int64) []arrow.Record {
+	size := recordBytes(rec)
+	if budget <= 0 || size <= budget {
+		rec.Retain()
+		return []arrow.Record{rec}
+	}
+	rows := rec.NumRows() * budget / size
+	if rows < 1 {
+		rows = 1
+	}
+	parts := make([]arrow.Record, 0, (rec.NumRows()+rows-1)/rows)
+	for start := int64(0); start < rec.NumRows(); start += rows {
+		end := start + rows
+		if end > rec.NumRows() {
+			end = rec.NumRows()
+		}
+		parts = append(parts, rec.NewSlice(start, end))
+	}
+	return parts
+}
+
+// recordBytes returns the size of the buffers of rec, including the parts outside of rec if
+// it is a slice.
+func recordBytes(rec arrow.Record) int64 {
+	var size int64
+	for _, column := range rec.Columns() {
+		size += dataBytes(column.Data())
+	}
+	return size
+}
+
+func dataBytes(data arrow.ArrayData) int64 {
+	var size int64
+	for _, buf := range data.Buffers() {
+		if buf != nil {
+			size += int64(buf.Len())
+		}
+	}
+	for _, child := range data.Children() {
+		size += dataBytes(child)
+	}
+	return size
+}
+
+// stagedWriter encodes a file in the staging directory and uploads it when it is closed.
+type stagedWriter struct {
+	*parquet.FileWriter
+	localPath string
+	fs        fs.Fs
+	path      string
+}
+
+// newStagedWriter returns a writer of the file at filePath encoding it in dir first.
+func (s *Space) newStagedWriter(f fs.Fs, schema *arrow.Schema, dir string, filePath string, opts ...parquet.WriterOption) (format.Writer, error) {
+	localPath := filepath.Join(spaceStagingDir(dir, s.path), stagingProcess, filepath.Base(filePath))
+	writer, err := parquet.NewFileWriter(schema, fs.NewLocalFs(), localPath, s.snapshot().GetSchema().Options().StorageProfiles, s.writeMemory, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &stagedWriter{FileWriter: writer, localPath: localPath, fs: f, path: filePath}, nil
+}
+
+func (w *stagedWriter) Close() error {
+	defer os.Remove(w.localPath)
+	if err := w.FileWriter.Close(); err != nil {
+		return err
+	}
+	return fs.Upload(w.fs, w.localPath, w.path)
+}
+
+// abortWriter closes the writer of a failed write, if any, without uploading a staged file.
+func abortWriter(writer format.Writer) {
+	switch w := writer.(type) {
+	case nil:
+	case *stagedWriter:
+		w.FileWriter.Close()
+		os.Remove(w.localPath)
+	default:
+		w.Close()
+	}
+}
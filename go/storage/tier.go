@@ -0,0 +1,161 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/milvus-io/milvus-storage/go/common/utils"
+	"github.com/milvus-io/milvus-storage/go/file/fragment"
+	"github.com/milvus-io/milvus-storage/go/io/fs"
+	"github.com/milvus-io/milvus-storage/go/storage/auth"
+	"github.com/milvus-io/milvus-storage/go/storage/manifest"
+	"github.com/milvus-io/milvus-storage/go/storage/options/option"
+)
+
+// MigrateCold moves the fragments older than options.OlderThan to the cold tier, see
+// MigrateColdContext.
+func (s *Space) MigrateCold(options *option.TierOptions) (int, error) {
+	return s.MigrateColdContext(context.Background(), options)
+}
+
+// MigrateColdContext copies the files of the data fragments written at least
+// options.OlderThan ago to the cold tier and commits a new version referencing them there. It
+// returns the number of fragments moved. The cold tier is a directory of the space on the same
+// file system, so that object stores can keep it in a cheaper storage class, e.g. by a
+// lifecycle rule on its prefix. Files of older versions are kept since those versions can
+// still be opened. It fails with fs.ErrStatNotSupported on file systems that do not tell when
+// files were written. ctx carries the caller identity, it requires auth.OpAdmin.
+func (s *Space) MigrateColdContext(ctx context.Context, options *option.TierOptions) (int, error) {
+	if err := s.authorize(ctx, auth.OpAdmin); err != nil {
+		return 0, err
+	}
+	now := time.Now()
+	return s.moveFragments(ctx, "migrate to cold tier", utils.GetColdDataDir(s.path), func(f fragment.Fragment) (bool, error) {
+		for _, path := range f.Files() {
+			info, err := fs.Stat(s.fs, path)
+			if err != nil {
+				return false, fmt.Errorf("migrate to cold tier: %w", err)
+			}
+			if now.Sub(info.ModTime) < options.OlderThan {
+				return false, nil
+			}
+		}
+		return true, nil
+	})
+}
+
+// WarmUp moves fragments back from the cold tier, see WarmUpContext.
+func (s *Space) WarmUp(fragmentIDs ...int64) (int, error) {
+	return s.WarmUpContext(context.Background(), fragmentIDs...)
+}
+
+// WarmUpContext copies the files of the fragments fragmentIDs in the cold tier back to the data
+// directories of the space and commits a new version referencing them there, all the cold
+// fragments if fragmentIDs is empty. It returns the number of fragments moved. ctx carries the
+// caller identity, it requires auth.OpAdmin.
+func (s *Space) WarmUpContext(ctx context.Context, fragmentIDs ...int64) (int, error) {
+	if err := s.authorize(ctx, auth.OpAdmin); err != nil {
+		return 0, err
+	}
+	ids := make(map[int64]bool, len(fragmentIDs))
+	for _, id := range fragmentIDs {
+		ids[id] = true
+	}
+	return s.moveFragments(ctx, "warm up", s.path, func(f fragment.Fragment) (bool, error) {
+		return len(ids) == 0 || ids[f.FragmentId()], nil
+	})
+}
+
+// moveFragments copies the files of the data fragments chosen by choose that are not in the
+// data directories under root there, keeping their names, and commits the fragments with the
+// copies. The vector fragment of a pair is moved with its scalar fragment. Pinned fragments
+// and fragments of transactions in progress are left as they are.
+func (s *Space) moveFragments(ctx context.Context, op string, root string, choose func(f fragment.Fragment) (bool, error)) (int, error) {
+	m := s.snapshot()
+	if err := m.ValidateDataFragments(); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	pending, err := s.pendingTxns(m)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	scalarDir, vectorDir := utils.GetScalarDataDir(root), utils.GetVectorDataDir(root)
+
+	var newFiles []string
+	move := func(f fragment.Fragment, dir string) (*fragment.Fragment, error) {
+		moved := fragment.NewFragment(f.FragmentId())
+		moved.SetTxn(f.Txn())
+		for j, path := range f.Files() {
+			dst := filepath.Join(dir, filepath.Base(path))
+			if dst != path {
+				if err := s.fs.Copy(path, dst); err != nil {
+					return nil, fmt.Errorf("%s: copy %s: %w", op, path, err)
+				}
+				newFiles = append(newFiles, dst)
+			}
+			moved.AddFileWithStats(dst, f.FileStats()[j])
+		}
+		return moved, nil
+	}
+
+	scalarFragments := append(fragment.FragmentVector(nil), m.GetScalarFragments()...)
+	vectorFragments := append(fragment.FragmentVector(nil), m.GetVectorFragments()...)
+	var count int
+	for i := range scalarFragments {
+		scalar, vector := scalarFragments[i], vectorFragments[i]
+		if pending[scalar.Txn()] || m.IsPinned(scalar.FragmentId()) || inDir(scalar.Files(), scalarDir) && inDir(vector.Files(), vectorDir) {
+			continue
+		}
+		ok, err := choose(scalar)
+		if err != nil {
+			return 0, err
+		}
+		if !ok {
+			continue
+		}
+		movedScalar, err := move(scalar, scalarDir)
+		if err != nil {
+			return 0, err
+		}
+		movedVector, err := move(vector, vectorDir)
+		if err != nil {
+			return 0, err
+		}
+		scalarFragments[i], vectorFragments[i] = *movedScalar, *movedVector
+		count++
+	}
+	if count == 0 {
+		return 0, nil
+	}
+
+	record := &option.AuditRecord{Operation: auth.OpAdmin, Files: newFiles}
+	err = s.commit(ctx, record, func(latest *manifest.Manifest, version int64) error {
+		if !hasPrefix(latest.GetScalarFragments(), m.GetScalarFragments()) ||
+			!hasPrefix(latest.GetVectorFragments(), m.GetVectorFragments()) ||
+			!samePins(latest, m) {
+			return fmt.Errorf("%s of version %d: %w", op, m.Version(), ErrManifestConflict)
+		}
+		// fragments committed after the snapshot are kept as they are
+		latest.SetScalarFragments(append(scalarFragments, latest.GetScalarFragments()[len(scalarFragments):]...))
+		latest.SetVectorFragments(append(vectorFragments, latest.GetVectorFragments()[len(vectorFragments):]...))
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// inDir reports whether all files are in directory dir.
+func inDir(files []string, dir string) bool {
+	for _, file := range files {
+		// files may be in subdirectories of a layout
+		if !strings.HasPrefix(file, dir+string(filepath.Separator)) {
+			return false
+		}
+	}
+	return true
+}
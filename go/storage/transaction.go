@@ -0,0 +1,281 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/google/uuid"
+	"github.com/milvus-io/milvus-storage/go/common/errors"
+	"github.com/milvus-io/milvus-storage/go/common/log"
+	"github.com/milvus-io/milvus-storage/go/file/fragment"
+	"github.com/milvus-io/milvus-storage/go/io/fs"
+	"github.com/milvus-io/milvus-storage/go/storage/auth"
+	"github.com/milvus-io/milvus-storage/go/storage/manifest"
+	"github.com/milvus-io/milvus-storage/go/storage/options/option"
+)
+
+var (
+	ErrTransactionDone    = errors.New("transaction already committed or aborted")
+	ErrTransactionPending = errors.NewWithKind(errors.ErrConflict, "transaction pending")
+	// ErrTxnMarkerDir is returned by writes of a transaction to a space whose TxnMarkerDir is
+	// not the marker directory of the transaction, and by reads of spaces holding fragments of
+	// transactions without TxnMarkerDir.
+	ErrTxnMarkerDir = errors.New("transaction marker directory not set")
+)
+
+const txnMarkerSuffix = ".txn"
+
+// Transaction writes to several spaces, e.g. the shards of a collection, so that the writes
+// become visible in all of them at once or in none. Every write is committed to its space
+// right away but its fragment stays invisible until Commit creates the marker of the
+// transaction, a single file in a directory shared by the spaces. Readers of every space
+// check the marker, so the writes appear together once it exists. A transaction that is
+// aborted, or never committed, leaves no visible rows.
+type Transaction struct {
+	// id names the marker in its directory, it is stored in the fragments of the transaction
+	id   string
+	dir  string
+	fs   fs.Fs
+	path string
+
+	// mu is held for reading by writes and for writing by Commit and Abort, which wait for
+	// the writes in progress.
+	mu         sync.RWMutex
+	done       bool
+	spacesLock sync.Mutex
+	spaces     []*Space
+}
+
+// NewTransaction starts a transaction whose marker is created in the directory at uri. The
+// spaces written must be opened with the path of uri as option.Options.TxnMarkerDir, their
+// readers check the marker through the file system of the space. Only the name of the marker
+// is stored in the manifests, never uri and its credentials.
+func NewTransaction(uri string) (*Transaction, error) {
+	f, dir, err := buildFs(uri)
+	if err != nil {
+		return nil, fmt.Errorf("new transaction: %w", err)
+	}
+	id := uuid.New().String() + txnMarkerSuffix
+	return &Transaction{
+		id:   id,
+		dir:  filepath.Clean(dir),
+		fs:   f,
+		path: filepath.Join(dir, id),
+	}, nil
+}
+
+// ID returns the name of the marker of the transaction in its directory.
+func (t *Transaction) ID() string {
+	return t.id
+}
+
+func (t *Transaction) Write(space *Space, reader array.RecordReader, options *option.WriteOptions) error {
+	return t.WriteContext(context.Background(), space, reader, options)
+}
+
+// WriteContext writes the rows of reader to space as part of the transaction, ctx carries the
+// caller identity checked by the authorizer of space. Writes may run concurrently.
+func (t *Transaction) WriteContext(ctx context.Context, space *Space, reader array.RecordReader, options *option.WriteOptions) error {
+	if err := space.authorize(ctx, auth.OpWrite); err != nil {
+		return err
+	}
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if t.done {
+		return ErrTransactionDone
+	}
+	if space.txnMarkerDir != t.dir {
+		return fmt.Errorf("write transaction %s to %s: %w", t.id, space.path, ErrTxnMarkerDir)
+	}
+	if _, err := space.writeFragment(ctx, reader, options, t.id); err != nil {
+		return err
+	}
+	t.spacesLock.Lock()
+	defer t.spacesLock.Unlock()
+	for _, written := range t.spaces {
+		if written == space {
+			return nil
+		}
+	}
+	t.spaces = append(t.spaces, space)
+	return nil
+}
+
+func (t *Transaction) Commit() error {
+	return t.CommitContext(context.Background())
+}
+
+// CommitContext makes the writes of the transaction visible in every space by creating its
+// marker. The spaces are then updated so that their readers stop checking the marker, a
+// failure to do so is logged, the writes stay visible. ctx carries the caller identity.
+func (t *Transaction) CommitContext(ctx context.Context) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.done {
+		return ErrTransactionDone
+	}
+	// the marker appears at once, readers never see a partially written one
+	tmpPath := t.path + ".tmp"
+	if err := fs.WriteFile(t.fs, tmpPath, []byte(t.id)); err != nil {
+		return fmt.Errorf("commit transaction %s: %w", t.id, err)
+	}
+	if err := t.fs.Rename(tmpPath, t.path); err != nil {
+		return fmt.Errorf("commit transaction %s: %w", t.id, err)
+	}
+	t.done = true
+	t.close()
+	log.Info("commit transaction", log.String("txn", t.id), log.Int("spaces", len(t.spaces)))
+
+	for _, space := range t.spaces {
+		err := space.updateTxnFragments(ctx, t.id, func(f *fragment.Fragment) bool {
+			f.SetTxn("")
+			return true
+		})
+		if err != nil {
+			log.Warn("finish transaction failed", log.String("txn", t.id), log.String("path", space.path), log.String("err", err.Error()))
+		}
+	}
+	return nil
+}
+
+// close closes the file system of the marker once the transaction is done.
+func (t *Transaction) close() {
+	if err := t.fs.Close(); err != nil {
+		log.Warn("close transaction file system failed", log.String("txn", t.id), log.String("err", err.Error()))
+	}
+}
+
+func (t *Transaction) Abort() error {
+	return t.AbortContext(context.Background())
+}
+
+// AbortContext removes the fragments written by the transaction from every space, their rows
+// were never visible. ctx carries the caller identity.
+func (t *Transaction) AbortContext(ctx context.Context) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.done {
+		return ErrTransactionDone
+	}
+	t.done = true
+	t.close()
+	var firstErr error
+	for _, space := range t.spaces {
+		err := space.updateTxnFragments(ctx, t.id, func(f *fragment.Fragment) bool { return false })
+		if err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("abort transaction %s: %w", t.id, err)
+		}
+	}
+	return firstErr
+}
+
+// txnMarkerDir returns the marker directory of op, cleaned to compare it with the directory of
+// transactions.
+func txnMarkerDir(op option.Options) string {
+	if op.TxnMarkerDir == "" {
+		return ""
+	}
+	return filepath.Clean(op.TxnMarkerDir)
+}
+
+// updateTxnFragments commits a version where update is applied to the data fragments of
+// transaction txn, those for which it returns false are removed.
+func (s *Space) updateTxnFragments(ctx context.Context, txn string, update func(f *fragment.Fragment) bool) error {
+	record := &option.AuditRecord{Operation: auth.OpWrite}
+	return s.commit(ctx, record, func(m *manifest.Manifest, version int64) error {
+		keep := func(fragments fragment.FragmentVector) fragment.FragmentVector {
+			kept := make(fragment.FragmentVector, 0, len(fragments))
+			for _, f := range fragments {
+				if f.Txn() != txn || update(&f) {
+					kept = append(kept, f)
+				}
+			}
+			return kept
+		}
+		m.SetScalarFragments(keep(m.GetScalarFragments()))
+		m.SetVectorFragments(keep(m.GetVectorFragments()))
+		return nil
+	})
+}
+
+// pendingTxns returns the transactions of the fragments of m whose marker does not exist.
+// Committed transactions are remembered, their markers are never removed.
+func (s *Space) pendingTxns(m *manifest.Manifest) (map[string]bool, error) {
+	var pending map[string]bool
+	for _, f := range m.GetScalarFragments() {
+		txn := f.Txn()
+		if txn == "" || pending[txn] {
+			continue
+		}
+		s.txnLock.Lock()
+		committed := s.committedTxns[txn]
+		s.txnLock.Unlock()
+		if committed {
+			continue
+		}
+		committed, err := s.txnCommitted(txn)
+		if err != nil {
+			return nil, err
+		}
+		if !committed {
+			if pending == nil {
+				pending = make(map[string]bool)
+			}
+			pending[txn] = true
+			continue
+		}
+		s.txnLock.Lock()
+		if s.committedTxns == nil {
+			s.committedTxns = make(map[string]bool)
+		}
+		s.committedTxns[txn] = true
+		s.txnLock.Unlock()
+	}
+	return pending, nil
+}
+
+// txnCommitted reports whether the marker of transaction txn exists in the marker directory
+// of the space.
+func (s *Space) txnCommitted(txn string) (bool, error) {
+	if s.txnMarkerDir == "" {
+		return false, fmt.Errorf("check transaction %s: %w", txn, ErrTxnMarkerDir)
+	}
+	exist, err := s.fs.Exist(filepath.Join(s.txnMarkerDir, filepath.Base(txn)))
+	if err != nil {
+		return false, fmt.Errorf("check transaction %s: %w", txn, err)
+	}
+	return exist, nil
+}
+
+// visible returns m without the data fragments of pending transactions.
+func (s *Space) visible(m *manifest.Manifest) (*manifest.Manifest, error) {
+	pending, err := s.pendingTxns(m)
+	if err != nil {
+		return nil, err
+	}
+	if len(pending) == 0 {
+		return m, nil
+	}
+	committed := func(fragments fragment.FragmentVector) fragment.FragmentVector {
+		kept := make(fragment.FragmentVector, 0, len(fragments))
+		for _, f := range fragments {
+			if !pending[f.Txn()] {
+				kept = append(kept, f)
+			}
+		}
+		return kept
+	}
+	copied := m.Copy()
+	copied.SetScalarFragments(committed(m.GetScalarFragments()))
+	copied.SetVectorFragments(committed(m.GetVectorFragments()))
+	return copied, nil
+}
+
+// readSnapshot returns the current manifest as seen by readers, without the fragments of
+// pending transactions. It fails if the markers of the transactions cannot be checked.
+func (s *Space) readSnapshot() (*manifest.Manifest, error) {
+	return s.visible(s.snapshot())
+}
@@ -0,0 +1,128 @@
+package storage
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/milvus-io/milvus-storage/go/common/errors"
+	"github.com/milvus-io/milvus-storage/go/storage/options/option"
+)
+
+// ErrInvalidVector is matched by every VectorError.
+var ErrInvalidVector = errors.New("invalid vector")
+
+// VectorError reports the first invalid vector of a write, nothing of the write is committed.
+type VectorError struct {
+	Column string
+	// Row is the index of the row in the stream written, counting the rows of every batch.
+	Row    int64
+	Reason string
+}
+
+func (e *VectorError) Error() string {
+	return fmt.Sprintf("vector column %s, row %d: %s", e.Column, e.Row, e.Reason)
+}
+
+func (e *VectorError) Unwrap() error {
+	return ErrInvalidVector
+}
+
+// validateVectors checks the vector column column of rec, whose first row is row firstRow of the
+// write. The schema only declares the dimension, the buffers of a batch built or decoded
+// without validation may be shorter, and its values may be null or not finite. Vectors may only
+// be null if the field is nullable, their values are not checked.
+func validateVectors(rec arrow.Record, column string, firstRow int64, options *option.WriteOptions) error {
+	indices := rec.Schema().FieldIndices(column)
+	if len(indices) == 0 {
+		return nil
+	}
+	col := rec.Column(indices[0])
+	invalid := func(row int, reason string, args ...any) error {
+		return &VectorError{Column: column, Row: firstRow + int64(row), Reason: fmt.Sprintf(reason, args...)}
+	}
+	if !rec.Schema().Field(indices[0]).Nullable {
+		for i := 0; i < col.Len(); i++ {
+			if col.IsNull(i) {
+				return invalid(i, "vector is null")
+			}
+		}
+	}
+
+	switch arr := col.(type) {
+	case *array.FixedSizeBinary:
+		width := arr.DataType().(*arrow.FixedSizeBinaryType).ByteWidth
+		values := arr.Data().Buffers()[1]
+		if available := valuesAvailable(values.Len(), arr.Data().Offset(), width); available < arr.Len() {
+			return invalid(available, "%d bytes missing from a vector of %d bytes", width*(arr.Len()-available), width)
+		}
+	case *array.FixedSizeList:
+		dim := int(arr.DataType().(*arrow.FixedSizeListType).Len())
+		values := arr.ListValues()
+		if available := valuesAvailable(values.Len(), arr.Data().Offset(), dim); available < arr.Len() {
+			return invalid(available, "vector has fewer than %d dimensions", dim)
+		}
+		start := arr.Data().Offset() * dim
+		for i := 0; i < arr.Len(); i++ {
+			if arr.IsNull(i) {
+				continue
+			}
+			if err := validateValues(values, start+i*dim, start+(i+1)*dim, options); err != nil {
+				return invalid(i, "%s", err)
+			}
+		}
+	case *array.List:
+		values := arr.ListValues()
+		for i := 0; i < arr.Len(); i++ {
+			if arr.IsNull(i) {
+				continue
+			}
+			start, end := arr.ValueOffsets(i)
+			if end > int64(values.Len()) {
+				return invalid(i, "vector ends at value %d of %d", end, values.Len())
+			}
+			if err := validateValues(values, int(start), int(end), options); err != nil {
+				return invalid(i, "%s", err)
+			}
+		}
+	}
+	return nil
+}
+
+// validateValues checks the values of a vector, those from start to end excluded.
+func validateValues(values arrow.Array, start int, end int, options *option.WriteOptions) error {
+	for j := start; j < end; j++ {
+		if values.IsNull(j) {
+			return fmt.Errorf("dimension %d is null", j-start)
+		}
+		if options.RejectNonFinite && !finite(values, j) {
+			return fmt.Errorf("dimension %d is not finite", j-start)
+		}
+	}
+	return nil
+}
+
+// valuesAvailable returns the number of vectors of size width fully held by length values,
+// counting from vector offset.
+func valuesAvailable(length int, offset int, width int) int {
+	if width == 0 {
+		return math.MaxInt
+	}
+	return length/width - offset
+}
+
+func finite(values arrow.Array, i int) bool {
+	var v float64
+	switch values := values.(type) {
+	case *array.Float16:
+		v = float64(values.Value(i).Float32())
+	case *array.Float32:
+		v = float64(values.Value(i))
+	case *array.Float64:
+		v = values.Value(i)
+	default:
+		return true
+	}
+	return !math.IsNaN(v) && !math.IsInf(v, 0)
+}